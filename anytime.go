@@ -0,0 +1,133 @@
+package eaopt
+
+import (
+	"math"
+	"sync"
+)
+
+// AnytimeWindow is the number of most recent generations from which
+// GA.Anytime estimates the rate of improvement.
+const AnytimeWindow = 10
+
+// anytimeState contains the snapshot returned by GA.Anytime. It is guarded by
+// a lock so that Anytime can be called while the GA is being evolved.
+type anytimeState struct {
+	mutex      sync.Mutex
+	best       Individual
+	trajectory []float64
+	remaining  uint // Number of generations left when the snapshot was taken
+}
+
+// reset empties the trajectory.
+func (as *anytimeState) reset() {
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	as.best = Individual{}
+	as.trajectory = nil
+	as.remaining = 0
+}
+
+// recordAnytime takes a snapshot of the GA's best Individual and appends its fitness
+// to the trajectory.
+func (ga *GA) recordAnytime() {
+	if len(ga.HallOfFame) == 0 {
+		return
+	}
+	var best = ga.HallOfFame[0]
+	if best.Genome != nil {
+		best.Genome = best.Genome.Clone()
+	}
+	best.Metadata = copyMetadata(best.Metadata)
+	ga.anytime.mutex.Lock()
+	defer ga.anytime.mutex.Unlock()
+	ga.anytime.best = best
+	ga.anytime.trajectory = append(ga.anytime.trajectory, best.Fitness)
+	ga.anytime.remaining = 0
+	if ga.Generations < ga.NGenerations {
+		ga.anytime.remaining = ga.NGenerations - ga.Generations
+	}
+}
+
+// An AnytimeResult is a snapshot of the best solution a GA has found so far,
+// along with an estimate of how much it is likely to improve.
+type AnytimeResult struct {
+	Best       Individual // Copy of the best Individual ever encountered
+	Trajectory []float64  // Best fitness after the initialization and after each generation
+	Stagnation uint       // Number of generations since the best fitness last improved
+	// Improvement of the best fitness per generation, estimated with the
+	// least squares slope of the last AnytimeWindow values of Trajectory,
+	// along with the standard error of the estimate. RateStdErr is +Inf if
+	// there are less than 3 values.
+	Rate       float64
+	RateStdErr float64
+	// Improvement expected until NGenerations is reached if the Rate holds.
+	// It is an optimistic estimate because improvements usually slow down.
+	ExpectedImprovement float64
+}
+
+// Anytime returns a snapshot of the best solution found so far. Callers can
+// use it to decide whether continuing the evolution is worthwhile, for
+// instance by stopping once ExpectedImprovement is too low. It is safe to call
+// concurrently with Minimize. The trajectory isn't persisted when the GA is
+// marshaled to JSON; it restarts when the GA is initialized.
+func (ga *GA) Anytime() AnytimeResult {
+	if ga.anytime == nil {
+		return AnytimeResult{RateStdErr: math.Inf(1)}
+	}
+	ga.anytime.mutex.Lock()
+	defer ga.anytime.mutex.Unlock()
+	var res = AnytimeResult{
+		Best:       ga.anytime.best,
+		Trajectory: copyFloat64s(ga.anytime.trajectory),
+		RateStdErr: math.Inf(1),
+	}
+	if res.Best.Genome != nil {
+		res.Best.Genome = res.Best.Genome.Clone()
+	}
+	res.Best.Metadata = copyMetadata(res.Best.Metadata)
+	var traj = res.Trajectory
+	for i := len(traj) - 1; i > 0 && traj[i] >= traj[i-1]; i-- {
+		res.Stagnation++
+	}
+	if len(traj) > AnytimeWindow {
+		traj = traj[len(traj)-AnytimeWindow:]
+	}
+	if len(traj) >= 2 {
+		var slope, stdErr = leastSquaresSlope(traj)
+		res.Rate = -slope
+		if len(traj) >= 3 {
+			res.RateStdErr = stdErr
+		}
+	}
+	if res.Rate > 0 {
+		res.ExpectedImprovement = res.Rate * float64(ga.anytime.remaining)
+	}
+	return res
+}
+
+// leastSquaresSlope returns the slope of the least squares line fitting y
+// against 0, 1, ..., len(y)-1, along with its standard error.
+func leastSquaresSlope(y []float64) (float64, float64) {
+	var (
+		n      = float64(len(y))
+		mx     = (n - 1) / 2
+		my     = meanFloat64s(y)
+		sxy    float64
+		sxx    float64
+		sse    float64
+		stdErr = math.Inf(1)
+	)
+	for i, yi := range y {
+		sxy += (float64(i) - mx) * (yi - my)
+		sxx += (float64(i) - mx) * (float64(i) - mx)
+	}
+	var slope = sxy / sxx
+	if n > 2 {
+		for i, yi := range y {
+			var r = yi - my - slope*(float64(i)-mx)
+			sse += r * r
+		}
+		stdErr = math.Sqrt(sse / (n - 2) / sxx)
+	}
+	return slope, stdErr
+}
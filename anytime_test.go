@@ -0,0 +1,114 @@
+package eaopt
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestLeastSquaresSlope(t *testing.T) {
+	var slope, stdErr = leastSquaresSlope([]float64{5, 3, 1, -1})
+	if slope != -2 || stdErr != 0 {
+		t.Errorf("Expected a slope of -2 with no error, got %f ± %f", slope, stdErr)
+	}
+	slope, stdErr = leastSquaresSlope([]float64{1, 2, 1, 2})
+	if math.Abs(slope-0.2) > 1e-12 || stdErr <= 0 {
+		t.Errorf("Expected a slope of 0.2 with some error, got %f ± %f", slope, stdErr)
+	}
+	if _, stdErr = leastSquaresSlope([]float64{1, 2}); !math.IsInf(stdErr, 1) {
+		t.Errorf("Expected an infinite error, got %f", stdErr)
+	}
+}
+
+func TestAnytime(t *testing.T) {
+	var ga, err = NewDefaultGAConfig().NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := ga.Anytime(); res.Best.Genome != nil || len(res.Trajectory) != 0 {
+		t.Errorf("Expected an empty result before initialization, got %v", res)
+	}
+	ga.NGenerations = 20
+	ga.Callback = func(ga *GA) {
+		var res = ga.Anytime()
+		if len(res.Trajectory) != int(ga.Generations)+1 {
+			t.Errorf("Expected %d fitnesses, got %d", ga.Generations+1, len(res.Trajectory))
+		}
+		if res.Best.Fitness != ga.HallOfFame[0].Fitness || res.Trajectory[len(res.Trajectory)-1] != res.Best.Fitness {
+			t.Errorf("Expected the best fitness %f, got %f", ga.HallOfFame[0].Fitness, res.Best.Fitness)
+		}
+		// The snapshot doesn't share the hall of fame's Genome
+		res.Best.Genome.(Vector)[0] = math.NaN()
+		if math.IsNaN(ga.HallOfFame[0].Genome.(Vector)[0]) {
+			t.Error("Anytime should return a copy of the best Genome")
+		}
+		if res.Rate < 0 || res.ExpectedImprovement < 0 {
+			t.Errorf("The best fitness can't get worse, got a rate of %f", res.Rate)
+		}
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var res = ga.Anytime()
+	if res.ExpectedImprovement != 0 {
+		t.Errorf("Expected no improvement once NGenerations is reached, got %f", res.ExpectedImprovement)
+	}
+	for i := 1; i < len(res.Trajectory); i++ {
+		if res.Trajectory[i] > res.Trajectory[i-1] {
+			t.Errorf("The trajectory should be decreasing, got %v", res.Trajectory)
+		}
+	}
+	// The trajectory restarts when the Populations are reinitialized
+	ga.Populations = nil
+	ga.NGenerations = 2
+	ga.Callback = nil
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(ga.Anytime().Trajectory); n != 3 {
+		t.Errorf("Expected 3 fitnesses, got %d", n)
+	}
+}
+
+func TestAnytimeStagnation(t *testing.T) {
+	var ga = &GA{anytime: new(anytimeState)}
+	ga.anytime.trajectory = []float64{10, 8, 6, 6, 6}
+	ga.anytime.remaining = 5
+	var res = ga.Anytime()
+	if res.Stagnation != 2 {
+		t.Errorf("Expected a stagnation of 2, got %d", res.Stagnation)
+	}
+	if math.Abs(res.Rate-1) > 1e-12 || math.Abs(res.ExpectedImprovement-5) > 1e-12 {
+		t.Errorf("Expected a rate of 1 and an expected improvement of 5, got %f and %f",
+			res.Rate, res.ExpectedImprovement)
+	}
+}
+
+func TestAnytimeConcurrent(t *testing.T) {
+	var ga, err = NewDefaultGAConfig().NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				ga.Anytime()
+			}
+		}
+	}()
+	err = ga.Minimize(NewVector)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
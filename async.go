@@ -0,0 +1,176 @@
+package eaopt
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A mailbox holds the migrants sent to a Population until it is scheduled to
+// migrate, see AsyncMigrator.
+type mailbox struct {
+	mutex    sync.Mutex
+	migrants Individuals
+}
+
+// post adds migrants to the mailbox.
+func (mb *mailbox) post(migrants Individuals) {
+	mb.mutex.Lock()
+	mb.migrants = append(mb.migrants, migrants...)
+	mb.mutex.Unlock()
+}
+
+// take empties the mailbox and returns the migrants it contained.
+func (mb *mailbox) take() Individuals {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+	var migrants = mb.migrants
+	mb.migrants = nil
+	return migrants
+}
+
+// logsPopulation returns true if the statistics of the i-th Population should
+// be logged at the given generation. Aggregated statistics can't be computed
+// while the Populations evolve asynchronously, hence each selected Population
+// is logged on its own.
+func (ga *GA) logsPopulation(i int, generation uint) bool {
+	if ga.Logger == nil && ga.SLogger == nil {
+		return false
+	}
+	var opts = ga.LogOptions
+	if opts == nil {
+		return true
+	}
+	if !opts.due(generation, ga.NGenerations) {
+		return false
+	}
+	if len(opts.Populations) == 0 {
+		return true
+	}
+	for _, j := range opts.Populations {
+		if int(j) == i {
+			return true
+		}
+	}
+	return false
+}
+
+// runAsync evolves each Population for NGenerations generations in its own
+// goroutine, see GAConfig.AsyncMigration. The Populations only synchronize to
+// exchange migrants through mailboxes and to update the hall of fame.
+func (ga *GA) runAsync() error {
+	if ga.done() {
+		return nil
+	}
+	if err := ga.immigrate(); err != nil {
+		return err
+	}
+	var (
+		start       = time.Now()
+		n           = len(ga.Populations)
+		amig, _     = ga.Migrator.(AsyncMigrator)
+		mailboxes   = make([]mailbox, n)
+		hofMutex    sync.Mutex
+		completed   = make([]uint, n) // Number of generations completed by each Population
+		previous    = ga.best()
+		generations = ga.Generations
+		queue       = ga.newEvalQueue() // Shared by the Populations
+	)
+	var f = func(pop *Population) error {
+		var (
+			i     = ga.populationIndex(pop)
+			model = ga.populationModel(pop)
+		)
+		for completed[i] < ga.NGenerations {
+			if ga.MaxEvaluations > 0 && ga.Evaluations() >= ga.MaxEvaluations {
+				return nil
+			}
+			var (
+				genStart   = time.Now()
+				deadline   = ga.deadline(genStart)
+				generation = generations + completed[i] + 1
+				err        error
+			)
+			if err = ga.carryOver(pop, queue, deadline); err != nil {
+				return err
+			}
+			if amig != nil && n > 1 && ga.migrationDue(generation) {
+				if migrants := ga.dedupMigrants(*pop, mailboxes[i].take()); len(migrants) > 0 {
+					if err = ga.evaluateMigrants(migrants, queue.evaluate); err != nil {
+						return err
+					}
+					amig.Immigrate(pop, migrants, pop.RNG)
+				}
+				var migrants, dest = amig.Emigrate(*pop, i, n, pop.RNG)
+				mailboxes[dest].post(migrants)
+			}
+			var fresh []bool
+			if ga.Speciator != nil {
+				_, err = pop.speciateEvolveMerge(ga.Speciator, model)
+			} else {
+				err = model.Apply(pop)
+			}
+			if err != nil {
+				return err
+			}
+			if ga.Novelty != nil {
+				fresh = unevaluated(pop.Individuals)
+			}
+			if ga.RealTime != nil {
+				err = ga.evaluateInTime(pop.Individuals, queue, deadline)
+			} else {
+				err = queue.evaluate(pop.Individuals, ga.ParallelEval)
+			}
+			if err != nil {
+				return err
+			}
+			if fresh != nil {
+				if err = ga.scoreNovelty(pop.Individuals, fresh, pop.RNG); err != nil {
+					return err
+				}
+			}
+			pop.Individuals.SortByFitness()
+			pop.Age += time.Since(genStart)
+			pop.Generations++
+			completed[i]++
+			if ga.logsPopulation(i, generation) {
+				ga.logPopulation(*pop)
+			}
+			hofMutex.Lock()
+			ga.updateHallOfFame(settled(pop.Individuals), pop.RNG)
+			hofMutex.Unlock()
+		}
+		return nil
+	}
+	var err = ga.Populations.Apply(f)
+	queue.close()
+	for _, c := range completed {
+		if generations+c > ga.Generations {
+			ga.Generations = generations + c
+		}
+	}
+	ga.Age += time.Since(start)
+	if err != nil {
+		return err
+	}
+	if err = ga.eval.takeInvalid(); err != nil {
+		return errors.Wrapf(err, "generation %d", ga.Generations)
+	}
+	if best := ga.best(); best.ID != previous.ID {
+		ga.Events.emitNewBest(NewBestEvent{GA: ga, Best: best, Previous: previous.Fitness})
+	}
+
+	ga.recordAnytime()
+	ga.recordHistory()
+	ga.recordFront()
+	ga.recordDiagnostics(nil)
+
+	// Execute the callback if it has been set
+	if ga.Callback != nil {
+		ga.Callback(ga)
+	}
+	ga.Events.emitGenerationEnd(GenerationEndEvent{GA: ga, Generation: ga.Generations})
+
+	return nil
+}
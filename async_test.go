@@ -0,0 +1,114 @@
+package eaopt
+
+import (
+	"testing"
+)
+
+func TestMigratorSchedule(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 9
+	conf.Migrator = MigRing{NMigrants: 2, Frequency: 3}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var migrations []uint
+	ga.Events.OnMigration(func(e MigrationEvent) { migrations = append(migrations, e.Generation) })
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(migrations) != 3 || migrations[0] != 3 || migrations[2] != 9 {
+		t.Errorf("Expected migrations at generations 3, 6 and 9, got %v", migrations)
+	}
+	// The deprecated MigFrequency takes precedence
+	conf.MigFrequency = 2
+	ga, _ = conf.NewGA()
+	migrations = nil
+	ga.Events.OnMigration(func(e MigrationEvent) { migrations = append(migrations, e.Generation) })
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(migrations) != 4 || migrations[0] != 2 {
+		t.Errorf("Expected migrations every 2 generations, got %v", migrations)
+	}
+}
+
+func TestAsyncMigration(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.NGenerations = 12
+	conf.HofSize = 3
+	conf.Migrator = MigRing{NMigrants: 2, Frequency: 2}
+	conf.AsyncMigration = true
+	// The Populations evolve at different speeds. Mutation only models keep
+	// the lineage of every Individual, hence migrants can't be selected out.
+	conf.Models = []Model{ModMutationOnly{}, ModMutationOnly{Strict: true}, ModMutationOnly{}}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var ends []uint
+	ga.Events.OnGenerationEnd(func(e GenerationEndEvent) { ends = append(ends, e.Generation) })
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for _, pop := range ga.Populations {
+		for i := range pop.Individuals {
+			pop.Individuals[i].Metadata = map[string]interface{}{"origin": pop.ID}
+		}
+	}
+	if err = ga.Run(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if ga.Generations != conf.NGenerations || len(ends) != 2 || ends[1] != conf.NGenerations {
+		t.Errorf("Expected %d generations, got %d and %v", conf.NGenerations, ga.Generations, ends)
+	}
+	var migrated bool
+	for _, pop := range ga.Populations {
+		if pop.Generations != conf.NGenerations || len(pop.Individuals) != int(conf.PopSize) {
+			t.Errorf("Population %s evolved for %d generations with %d individuals", pop.ID, pop.Generations,
+				len(pop.Individuals))
+		}
+		for _, indi := range pop.Individuals {
+			if indi.Metadata["origin"] != pop.ID {
+				migrated = true
+			}
+		}
+		// The hall of fame is at least as good as every Population
+		if pop.Individuals[0].Fitness < ga.HallOfFame[0].Fitness {
+			t.Errorf("The hall of fame wasn't updated: %f < %f", pop.Individuals[0].Fitness, ga.HallOfFame[0].Fitness)
+		}
+	}
+	if !migrated {
+		t.Error("No Individual migrated")
+	}
+	// The budget stops every Population
+	conf.MaxEvaluations = 200
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if ga.Generations >= conf.NGenerations {
+		t.Errorf("Expected the budget to stop the GA, got %d generations", ga.Generations)
+	}
+}
+
+func TestAsyncMigrationErrors(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.AsyncMigration = true
+	conf.HofInjection = &HofInjection{Frequency: 1, NIndividuals: 1}
+	if _, err := conf.NewGA(); err == nil {
+		t.Error("Expected an error with HofInjection")
+	}
+	conf.HofInjection = nil
+	conf.Callback = func(ga *GA) {}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(ga.Warnings) != 1 || ga.Warnings[0].Field != "Callback" {
+		t.Errorf("Expected a Callback warning, got %v", ga.Warnings)
+	}
+}
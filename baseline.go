@@ -0,0 +1,183 @@
+package eaopt
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// A sampledPoint is a point drawn by a RandomSearch or a LatinHypercube.
+type sampledPoint struct {
+	x []float64
+	s *sampler
+}
+
+// Evaluate the point by computing the value of the function at its position.
+func (p *sampledPoint) Evaluate() (float64, error) { return p.s.f(p.x), nil }
+
+// Mutate replaces the point with a newly sampled one.
+func (p *sampledPoint) Mutate(rng *rand.Rand) { p.x = p.s.next(rng) }
+
+// Crossover doesn't do anything.
+func (p *sampledPoint) Crossover(q Genome, rng *rand.Rand) {}
+
+// Clone returns a deep copy of the point.
+func (p sampledPoint) Clone() Genome {
+	return &sampledPoint{x: copyFloat64s(p.x), s: p.s}
+}
+
+// A sampler hands out points which are sampled in batches of batchSize, one
+// batch per generation. A lock is used because the initial population may be
+// generated in parallel.
+type sampler struct {
+	f            func([]float64) float64
+	lower, upper []float64
+	batchSize    uint
+	sample       FloatInitializer
+	batch        [][]float64
+	mutex        sync.Mutex
+}
+
+// next returns the next point of the current batch, a new batch is sampled if
+// the current one is exhausted.
+func (s *sampler) next(rng *rand.Rand) []float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.batch) == 0 {
+		s.batch = s.sample(s.batchSize, s.lower, s.upper, rng)
+	}
+	var x = s.batch[0]
+	s.batch = s.batch[1:]
+	return x
+}
+
+// newPoint returns a point that has a pointer to the sampler.
+func (s *sampler) newPoint(rng *rand.Rand) Genome {
+	return &sampledPoint{x: s.next(rng), s: s}
+}
+
+// samplingModel replaces every individual with a newly sampled point.
+type samplingModel struct{}
+
+// Apply samplingModel.
+func (mod samplingModel) Apply(pop *Population) error {
+	for i := range pop.Individuals {
+		pop.Individuals[i].Mutate(pop.RNG)
+	}
+	return nil
+}
+
+// Validate samplingModel fields.
+func (mod samplingModel) Validate() error {
+	return nil
+}
+
+// newSamplingGA returns a GA which samples nPoints points at each of the nSteps
+// generations.
+func newSamplingGA(nPoints, nSteps uint, min, max float64, parallel bool, rng *rand.Rand) (*GA, error) {
+	// Check inputs
+	if nPoints < 1 {
+		return nil, errors.New("nPoints should be at least 1")
+	}
+	if min >= max {
+		return nil, errors.New("min should be stricly inferior to max")
+	}
+	if rng == nil {
+		rng = newRand()
+	}
+	return GAConfig{
+		NPops:        1,
+		PopSize:      nPoints,
+		NGenerations: nSteps,
+		HofSize:      1,
+		Model:        samplingModel{},
+		ParallelEval: parallel,
+		RNG:          rand.New(rand.NewSource(rng.Int63())),
+	}.NewGA()
+}
+
+// minimizeBySampling runs ga with points drawn by sample.
+func minimizeBySampling(ga *GA, f func([]float64) float64, nDims uint, min, max float64,
+	sample FloatInitializer) ([]float64, float64, error) {
+	var s = &sampler{
+		f:         f,
+		lower:     repeatFloat64(min, nDims),
+		upper:     repeatFloat64(max, nDims),
+		batchSize: ga.PopSize,
+		sample:    sample,
+	}
+	if err := ga.Minimize(s.newPoint); err != nil {
+		return nil, 0, err
+	}
+	// Return the best obtained vector along with the associated function value
+	var best = ga.HallOfFame[0]
+	return copyFloat64s(best.Genome.(*sampledPoint).x), best.Fitness, nil
+}
+
+// RandomSearch samples points uniformly between Min and Max and keeps the best
+// one. It is the baseline any other Optimizer should beat with the same number
+// of evaluations.
+type RandomSearch struct {
+	Min, Max float64
+	GA       *GA
+}
+
+// NewRandomSearch instantiates and returns a RandomSearch instance after having
+// checked for input errors. nPoints points are sampled at each of the nSteps
+// steps.
+func NewRandomSearch(nPoints, nSteps uint, min, max float64, parallel bool, rng *rand.Rand) (*RandomSearch, error) {
+	var ga, err = newSamplingGA(nPoints, nSteps, min, max, parallel, rng)
+	if err != nil {
+		return nil, err
+	}
+	return &RandomSearch{Min: min, Max: max, GA: ga}, nil
+}
+
+// NewDefaultRandomSearch calls NewRandomSearch with default values.
+func NewDefaultRandomSearch() (*RandomSearch, error) {
+	return NewRandomSearch(40, 30, -5, 5, false, nil)
+}
+
+// Minimize finds the minimum of a given real-valued function.
+func (rs *RandomSearch) Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error) {
+	return minimizeBySampling(rs.GA, f, nDims, rs.Min, rs.Max, InitUnifPoints)
+}
+
+// Config returns the configuration of the underlying GA.
+func (rs *RandomSearch) Config() *GAConfig {
+	return &rs.GA.GAConfig
+}
+
+// LatinHypercube is a RandomSearch whose points are sampled with
+// InitLatinHypercube, each generation being a Latin hypercube of PopSize
+// points.
+type LatinHypercube struct {
+	Min, Max float64
+	GA       *GA
+}
+
+// NewLatinHypercube instantiates and returns a LatinHypercube instance after
+// having checked for input errors. A Latin hypercube of nPoints points is
+// sampled at each of the nSteps steps.
+func NewLatinHypercube(nPoints, nSteps uint, min, max float64, parallel bool, rng *rand.Rand) (*LatinHypercube, error) {
+	var ga, err = newSamplingGA(nPoints, nSteps, min, max, parallel, rng)
+	if err != nil {
+		return nil, err
+	}
+	return &LatinHypercube{Min: min, Max: max, GA: ga}, nil
+}
+
+// NewDefaultLatinHypercube calls NewLatinHypercube with default values.
+func NewDefaultLatinHypercube() (*LatinHypercube, error) {
+	return NewLatinHypercube(40, 30, -5, 5, false, nil)
+}
+
+// Minimize finds the minimum of a given real-valued function.
+func (lhs *LatinHypercube) Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error) {
+	return minimizeBySampling(lhs.GA, f, nDims, lhs.Min, lhs.Max, InitLatinHypercube)
+}
+
+// Config returns the configuration of the underlying GA.
+func (lhs *LatinHypercube) Config() *GAConfig {
+	return &lhs.GA.GAConfig
+}
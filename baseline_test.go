@@ -0,0 +1,66 @@
+package eaopt
+
+import (
+	"testing"
+)
+
+func TestBaselines(t *testing.T) {
+	var sphere = func(x []float64) (y float64) {
+		for _, xi := range x {
+			y += xi * xi
+		}
+		return
+	}
+	rs, err := NewRandomSearch(10, 5, -2, 3, false, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lhs, err := NewLatinHypercube(10, 5, -2, 3, true, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, opt := range map[string]Optimizer{"random": rs, "lhs": lhs} {
+		var hypercubes = true
+		opt.Config().Callback = func(ga *GA) {
+			// Check whether each generation is a Latin hypercube
+			var strata = make(map[int]bool)
+			for _, indi := range ga.Populations[0].Individuals {
+				strata[int((indi.Genome.(*sampledPoint).x[0]+2)/5*10)] = true
+			}
+			hypercubes = hypercubes && len(strata) == 10
+		}
+		x, y, err := opt.Minimize(sphere, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(x) != 2 || y != sphere(x) {
+			t.Errorf("%s: unexpected minimum %f at %v", name, y, x)
+		}
+		for _, xi := range x {
+			if xi < -2 || xi > 3 {
+				t.Errorf("%s: out of bound value %f", name, xi)
+			}
+		}
+		if name == "lhs" && !hypercubes {
+			t.Errorf("Every generation should be a Latin hypercube")
+		}
+	}
+	if rs.GA.Evaluations() != 60 {
+		t.Errorf("Expected 60 evaluations, got %d", rs.GA.Evaluations())
+	}
+}
+
+func TestNewBaselinesErrors(t *testing.T) {
+	if _, err := NewRandomSearch(0, 10, -5, 5, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewLatinHypercube(10, 10, 5, -5, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewDefaultRandomSearch(); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewDefaultLatinHypercube(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,419 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// A Kernel returns the covariance between two points of a Gaussian process
+// given the distance r between them, expressed in length scales. Kernels have
+// to return 1 when r is 0.
+type Kernel func(r float64) float64
+
+// KernelRBF is the squared exponential kernel, which produces very smooth
+// functions.
+func KernelRBF(r float64) float64 {
+	return math.Exp(-r * r / 2)
+}
+
+// KernelMatern32 is the Matérn kernel with ν = 3/2, which produces functions
+// that are once differentiable.
+func KernelMatern32(r float64) float64 {
+	var s = math.Sqrt(3) * r
+	return (1 + s) * math.Exp(-s)
+}
+
+// KernelMatern52 is the Matérn kernel with ν = 5/2, which produces functions
+// that are twice differentiable. It is the usual choice for Bayesian
+// optimization.
+func KernelMatern52(r float64) float64 {
+	var s = math.Sqrt(5) * r
+	return (1 + s + s*s/3) * math.Exp(-s)
+}
+
+// gpLengthScales are the length scales among which the one maximizing the
+// marginal likelihood is chosen when BayesOpt.LengthScale is 0.
+var gpLengthScales = []float64{0.05, 0.1, 0.2, 0.35, 0.5, 0.75, 1, 1.5, 2}
+
+// A gaussianProcess regresses observations y at points x, which are scaled to
+// the unit hypercube. The observations are standardized so that the kernel's
+// variance of 1 suits them.
+type gaussianProcess struct {
+	kernel      Kernel
+	lengthScale float64
+	noise       float64
+	x           [][]float64
+	mean, std   float64
+	l           [][]float64 // Cholesky factor of the covariance matrix
+	alpha       []float64   // Covariance matrix times alpha equals the standardized y
+}
+
+// fitGaussianProcess fits a gaussianProcess to the observations. If
+// lengthScale is 0 then it is chosen among gpLengthScales by maximizing the
+// marginal likelihood.
+func fitGaussianProcess(kernel Kernel, lengthScale, noise float64, x [][]float64,
+	y []float64) (*gaussianProcess, error) {
+	if len(x) == 0 {
+		return nil, errors.New("at least one observation is needed")
+	}
+	var (
+		mean = meanFloat64s(y)
+		std  = math.Sqrt(varianceFloat64s(y))
+		z    = make([]float64, len(y))
+	)
+	if std == 0 || math.IsNaN(std) {
+		std = 1
+	}
+	for i, yi := range y {
+		z[i] = (yi - mean) / std
+	}
+	var lengthScales = []float64{lengthScale}
+	if lengthScale == 0 {
+		lengthScales = gpLengthScales
+	}
+	var (
+		best    *gaussianProcess
+		bestLML = math.Inf(-1)
+	)
+	for _, ls := range lengthScales {
+		var gp = &gaussianProcess{
+			kernel:      kernel,
+			lengthScale: ls,
+			noise:       noise,
+			x:           x,
+			mean:        mean,
+			std:         std,
+		}
+		var lml, err = gp.factorize(z)
+		if err != nil {
+			continue
+		}
+		if lml > bestLML {
+			best, bestLML = gp, lml
+		}
+	}
+	if best == nil {
+		return nil, errors.New("the covariance matrix isn't positive definite")
+	}
+	return best, nil
+}
+
+// cov returns the covariance between two points.
+func (gp *gaussianProcess) cov(a, b []float64) float64 {
+	var d float64
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return gp.kernel(math.Sqrt(d) / gp.lengthScale)
+}
+
+// factorize computes the Cholesky factor of the covariance matrix and solves
+// for the standardized observations z. The log marginal likelihood, up to a
+// constant, is returned.
+func (gp *gaussianProcess) factorize(z []float64) (float64, error) {
+	var (
+		n = len(gp.x)
+		k = make([][]float64, n)
+	)
+	for i := range k {
+		k[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			k[i][j] = gp.cov(gp.x[i], gp.x[j])
+		}
+		k[i][i] += gp.noise
+	}
+	var l, err = cholesky(k)
+	if err != nil {
+		return 0, err
+	}
+	gp.l = l
+	gp.alpha = solveUpper(l, solveLower(l, z))
+	var lml float64
+	for i := range z {
+		lml -= z[i]*gp.alpha[i]/2 + math.Log(l[i][i])
+	}
+	return lml, nil
+}
+
+// predict returns the mean and the standard deviation of the process at x.
+func (gp *gaussianProcess) predict(x []float64) (float64, float64) {
+	var k = make([]float64, len(gp.x))
+	for i, xi := range gp.x {
+		k[i] = gp.cov(x, xi)
+	}
+	var (
+		mu  float64
+		v   = solveLower(gp.l, k)
+		vv2 = 1.0
+	)
+	for i := range k {
+		mu += k[i] * gp.alpha[i]
+		vv2 -= v[i] * v[i]
+	}
+	return gp.mean + mu*gp.std, math.Sqrt(math.Max(vv2, 0)) * gp.std
+}
+
+// cholesky returns the lower triangular matrix L such that L L^T = a. Only the
+// lower triangle of a is read.
+func cholesky(a [][]float64) ([][]float64, error) {
+	var l = make([][]float64, len(a))
+	for i := range a {
+		l[i] = make([]float64, i+1)
+		for j := 0; j <= i; j++ {
+			var s = a[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				if s <= 0 {
+					return nil, errors.New("the matrix isn't positive definite")
+				}
+				l[i][i] = math.Sqrt(s)
+			} else {
+				l[i][j] = s / l[j][j]
+			}
+		}
+	}
+	return l, nil
+}
+
+// solveLower solves L x = b by forward substitution.
+func solveLower(l [][]float64, b []float64) []float64 {
+	var x = make([]float64, len(b))
+	for i := range b {
+		var s = b[i]
+		for k := 0; k < i; k++ {
+			s -= l[i][k] * x[k]
+		}
+		x[i] = s / l[i][i]
+	}
+	return x
+}
+
+// solveUpper solves L^T x = b by backward substitution.
+func solveUpper(l [][]float64, b []float64) []float64 {
+	var x = make([]float64, len(b))
+	for i := len(b) - 1; i >= 0; i-- {
+		var s = b[i]
+		for k := i + 1; k < len(b); k++ {
+			s -= l[k][i] * x[k]
+		}
+		x[i] = s / l[i][i]
+	}
+	return x
+}
+
+// expectedImprovement returns the expected amount by which a point whose value
+// follows a normal distribution of mean mu and standard deviation sigma
+// improves upon best, minus the exploration margin xi.
+func expectedImprovement(mu, sigma, best, xi float64) float64 {
+	var imp = best - mu - xi
+	if sigma == 0 {
+		return math.Max(imp, 0)
+	}
+	var z = imp / sigma
+	return imp*math.Erfc(-z/math.Sqrt2)/2 + sigma*math.Exp(-z*z/2)/math.Sqrt(2*math.Pi)
+}
+
+// BayesOpt implements Bayesian optimization, which is suited to low
+// dimensional objectives that are expensive to evaluate. A Gaussian process
+// with the given Kernel is fitted to all the points evaluated so far and the
+// next points are the ones maximizing the expected improvement. The first
+// generation is a Latin hypercube. The GA's PopSize is the number of points
+// proposed at each generation; the points of a batch are chosen one after the
+// other by pretending that the previous ones were evaluated to their
+// predicted means.
+type BayesOpt struct {
+	Min, Max    float64 // Boundaries of the search space
+	Kernel      Kernel
+	LengthScale float64 // Relative to Max - Min; chosen at each generation if 0
+	Noise       float64 // Variance of the observation noise
+	Xi          float64 // Exploration margin of the expected improvement
+	NCandidates uint    // Number of points sampled to maximize the expected improvement
+	NDims       uint
+	F           func([]float64) float64
+	GA          *GA
+
+	// Evaluated points scaled to the unit hypercube and their values
+	xs [][]float64
+	ys []float64
+}
+
+// NewBayesOpt instantiates and returns a BayesOpt instance after having
+// checked for input errors. batchSize points are proposed at each of the
+// nSteps steps.
+func NewBayesOpt(batchSize, nSteps uint, min, max float64, kernel Kernel, parallel bool,
+	rng *rand.Rand) (*BayesOpt, error) {
+	// Check inputs
+	if batchSize < 1 {
+		return nil, errors.New("batchSize should be at least 1")
+	}
+	if min >= max {
+		return nil, errors.New("min should be stricly inferior to max")
+	}
+	if kernel == nil {
+		return nil, errors.New("kernel cannot be nil")
+	}
+	if rng == nil {
+		rng = newRand()
+	}
+	var bo = &BayesOpt{
+		Min:         min,
+		Max:         max,
+		Kernel:      kernel,
+		Noise:       1e-6,
+		Xi:          0.01,
+		NCandidates: 1000,
+	}
+	// Instantiate a GA
+	var ga, err = GAConfig{
+		NPops:        1,
+		PopSize:      batchSize,
+		NGenerations: nSteps,
+		HofSize:      1,
+		Model:        bayesModel{bo},
+		ParallelEval: parallel,
+		RNG:          rand.New(rand.NewSource(rng.Int63())),
+	}.NewGA()
+	if err != nil {
+		return nil, err
+	}
+	bo.GA = ga
+	return bo, nil
+}
+
+// NewDefaultBayesOpt calls NewBayesOpt with default values.
+func NewDefaultBayesOpt() (*BayesOpt, error) {
+	return NewBayesOpt(4, 20, -5, 5, KernelMatern52, false, nil)
+}
+
+// observe records the value y of the point x.
+func (bo *BayesOpt) observe(x []float64, y float64) {
+	var u = make([]float64, len(x))
+	for i, xi := range x {
+		u[i] = (xi - bo.Min) / (bo.Max - bo.Min)
+	}
+	bo.xs = append(bo.xs, u)
+	bo.ys = append(bo.ys, y)
+}
+
+// propose returns n points maximizing the expected improvement.
+func (bo *BayesOpt) propose(n int, rng *rand.Rand) ([][]float64, error) {
+	var (
+		xs     = bo.xs
+		ys     = bo.ys
+		points = make([][]float64, n)
+		gp     *gaussianProcess
+		err    error
+	)
+	for p := range points {
+		if p == 0 {
+			gp, err = fitGaussianProcess(bo.Kernel, bo.LengthScale, bo.Noise, xs, ys)
+		} else {
+			// Keep the length scale chosen for the first point of the batch
+			gp, err = fitGaussianProcess(bo.Kernel, gp.lengthScale, bo.Noise, xs, ys)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// minFloat64s isn't used because it reorders ys
+		var b = 0
+		for i, y := range ys {
+			if y < ys[b] {
+				b = i
+			}
+		}
+		var (
+			u     []float64
+			maxEI = -1.0
+			xi    = bo.Xi * gp.std
+			nDims = int(bo.NDims)
+			cand  = make([]float64, nDims)
+		)
+		for c := uint(0); c < bo.NCandidates; c++ {
+			// Half of the candidates are sampled uniformly, the other half
+			// around the best point
+			for i := range cand {
+				if c%2 == 0 {
+					cand[i] = rng.Float64()
+				} else {
+					cand[i] = math.Min(math.Max(xs[b][i]+0.05*rng.NormFloat64(), 0), 1)
+				}
+			}
+			var mu, sigma = gp.predict(cand)
+			if ei := expectedImprovement(mu, sigma, ys[b], xi); ei > maxEI {
+				maxEI = ei
+				u = copyFloat64s(cand)
+			}
+		}
+		var mu, _ = gp.predict(u)
+		xs = append(xs[:len(xs):len(xs)], u)
+		ys = append(ys[:len(ys):len(ys)], mu)
+		points[p] = make([]float64, nDims)
+		for i, ui := range u {
+			points[p][i] = bo.Min + ui*(bo.Max-bo.Min)
+		}
+	}
+	return points, nil
+}
+
+// Minimize finds the minimum of a given real-valued function.
+func (bo *BayesOpt) Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error) {
+	// Set the function to minimize so that the model can access it
+	bo.F = f
+	bo.NDims = nDims
+	bo.xs, bo.ys = nil, nil
+	var s = &sampler{
+		f:         f,
+		lower:     repeatFloat64(bo.Min, nDims),
+		upper:     repeatFloat64(bo.Max, nDims),
+		batchSize: bo.GA.PopSize,
+		sample:    InitLatinHypercube,
+	}
+	// Run the genetic algorithm
+	if err := bo.GA.Minimize(s.newPoint); err != nil {
+		return nil, 0, err
+	}
+	// Return the best obtained vector along with the associated function value
+	var best = bo.GA.HallOfFame[0]
+	return copyFloat64s(best.Genome.(*sampledPoint).x), best.Fitness, nil
+}
+
+// Config returns the configuration of the underlying GA.
+func (bo *BayesOpt) Config() *GAConfig {
+	return &bo.GA.GAConfig
+}
+
+// bayesModel records the points evaluated during the last generation and
+// replaces them with the points proposed by a BayesOpt.
+type bayesModel struct {
+	bo *BayesOpt
+}
+
+// Apply bayesModel.
+func (mod bayesModel) Apply(pop *Population) error {
+	for _, indi := range pop.Individuals {
+		// Points whose evaluation timed out carry no information
+		if !math.IsInf(indi.Fitness, 0) && !math.IsNaN(indi.Fitness) {
+			mod.bo.observe(indi.Genome.(*sampledPoint).x, indi.Fitness)
+		}
+	}
+	var points, err = mod.bo.propose(len(pop.Individuals), pop.RNG)
+	if err != nil {
+		return err
+	}
+	for i, x := range points {
+		pop.Individuals[i].Genome.(*sampledPoint).x = x
+		pop.Individuals[i].Evaluated = false
+	}
+	return nil
+}
+
+// Validate bayesModel fields.
+func (mod bayesModel) Validate() error {
+	if mod.bo == nil {
+		return errors.New("the BayesOpt cannot be nil")
+	}
+	return nil
+}
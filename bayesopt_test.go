@@ -0,0 +1,131 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKernels(t *testing.T) {
+	for _, kernel := range []Kernel{KernelRBF, KernelMatern32, KernelMatern52} {
+		if kernel(0) != 1 {
+			t.Errorf("Expected 1, got %f", kernel(0))
+		}
+		// Covariance decreases with distance
+		for r := 0.0; r < 5; r += 0.5 {
+			if kernel(r+0.5) >= kernel(r) {
+				t.Errorf("Expected a decreasing kernel, got k(%f) = %f and k(%f) = %f",
+					r, kernel(r), r+0.5, kernel(r+0.5))
+			}
+		}
+	}
+}
+
+func TestCholesky(t *testing.T) {
+	var a = [][]float64{{4, 2, 0.4}, {2, 5, 1}, {0.4, 1, 3}}
+	var l, err = cholesky(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range a {
+		for j := 0; j <= i; j++ {
+			var s float64
+			for k := 0; k <= j; k++ {
+				s += l[i][k] * l[j][k]
+			}
+			if math.Abs(s-a[i][j]) > 1e-12 {
+				t.Errorf("Expected %f at (%d, %d), got %f", a[i][j], i, j, s)
+			}
+		}
+	}
+	// Solve A x = b
+	var x = solveUpper(l, solveLower(l, []float64{1, 2, 3}))
+	for i := range a {
+		var s float64
+		for j := range a {
+			s += a[i][j] * x[j]
+		}
+		if math.Abs(s-float64(i+1)) > 1e-12 {
+			t.Errorf("Expected %d, got %f", i+1, s)
+		}
+	}
+	if _, err := cholesky([][]float64{{1, 2}, {2, 1}}); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestGaussianProcess(t *testing.T) {
+	var (
+		x = [][]float64{{0.1}, {0.3}, {0.5}, {0.9}}
+		y = []float64{1, 3, 2, -1}
+	)
+	var gp, err = fitGaussianProcess(KernelMatern52, 0, 1e-8, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The process interpolates the observations
+	for i := range x {
+		var mu, sigma = gp.predict(x[i])
+		if math.Abs(mu-y[i]) > 1e-3 || sigma > 1e-2 {
+			t.Errorf("Expected %f with no uncertainty at %v, got %f ± %f", y[i], x[i], mu, sigma)
+		}
+	}
+	// The uncertainty grows away from the observations
+	var _, s1 = gp.predict([]float64{0.6})
+	var _, s2 = gp.predict([]float64{0.7})
+	if s1 <= 0 || s2 <= s1 {
+		t.Errorf("Expected increasing uncertainties, got %f and %f", s1, s2)
+	}
+	if _, err := fitGaussianProcess(KernelRBF, 0, 0, nil, nil); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestExpectedImprovement(t *testing.T) {
+	if ei := expectedImprovement(1, 0, 2, 0); ei != 1 {
+		t.Errorf("Expected 1, got %f", ei)
+	}
+	if ei := expectedImprovement(3, 0, 2, 0); ei != 0 {
+		t.Errorf("Expected 0, got %f", ei)
+	}
+	// With mu equal to best, EI is sigma times the standard normal density at 0
+	if ei := expectedImprovement(2, 1, 2, 0); math.Abs(ei-0.398942) > 1e-6 {
+		t.Errorf("Expected 0.398942, got %f", ei)
+	}
+	// More uncertainty means more expected improvement
+	if expectedImprovement(3, 2, 2, 0) <= expectedImprovement(3, 1, 2, 0) {
+		t.Error("Expected EI to increase with sigma")
+	}
+}
+
+func TestBayesOptMinimize(t *testing.T) {
+	var bo, err = NewBayesOpt(3, 10, -5, 5, KernelMatern52, false, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f = func(x []float64) float64 { return Sphere([]float64{x[0] - 1, x[1] + 2}) }
+	x, y, err := bo.Minimize(f, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y != f(x) || y > 0.1 {
+		t.Errorf("Expected a minimum close to 0, got %f at %v", y, x)
+	}
+	if bo.GA.Evaluations() != 33 || len(bo.xs) != 30 {
+		t.Errorf("Expected 33 evaluations and 30 observations, got %d and %d", bo.GA.Evaluations(), len(bo.xs))
+	}
+}
+
+func TestNewBayesOptErrors(t *testing.T) {
+	if _, err := NewBayesOpt(0, 10, -5, 5, KernelRBF, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewBayesOpt(1, 10, 5, -5, KernelRBF, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewBayesOpt(1, 10, -5, 5, nil, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewDefaultBayesOpt(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,6 +1,7 @@
 package eaopt
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -19,3 +20,109 @@ func BenchmarkIndividualsEvaluateParallel(b *testing.B) {
 		indis.Evaluate(true)
 	}
 }
+
+func benchmarkGA(b *testing.B, popSize uint, parallelEval bool) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var conf = NewDefaultGAConfig()
+		conf.PopSize = popSize
+		conf.NGenerations = 10
+		conf.ParallelEval = parallelEval
+		conf.RNG = newRand()
+		var ga, err = conf.NewGA()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err = ga.Minimize(NewVector); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerational(b *testing.B) {
+	for _, popSize := range []uint{10, 100, 1000} {
+		for _, parallel := range []bool{false, true} {
+			b.Run(fmt.Sprintf("PopSize=%d/ParallelEval=%t", popSize, parallel), func(b *testing.B) {
+				benchmarkGA(b, popSize, parallel)
+			})
+		}
+	}
+}
+
+func BenchmarkSelTournament(b *testing.B) {
+	var (
+		rng   = newRand()
+		indis = newIndividuals(100, false, NewVector, rng)
+		sel   = SelTournament{NContestants: 3}
+	)
+	indis.Evaluate(false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sel.Apply(2, indis, rng)
+	}
+}
+
+func BenchmarkCrossUniformFloat64(b *testing.B) {
+	var (
+		rng = newRand()
+		p1  = InitUnifFloat64(100, -10, 10, rng)
+		p2  = InitUnifFloat64(100, -10, 10, rng)
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CrossUniformFloat64(p1, p2, rng)
+	}
+}
+
+func BenchmarkMutNormalFloat64(b *testing.B) {
+	var (
+		rng    = newRand()
+		genome = InitUnifFloat64(100, -10, 10, rng)
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MutNormalFloat64(genome, 0.5, rng)
+	}
+}
+
+func BenchmarkSortByFitness(b *testing.B) {
+	var indis = newIndividuals(1000, false, NewVector, newRand())
+	indis.Evaluate(false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		indis.SortByFitness()
+	}
+}
+
+// TestHotPathAllocs makes sure the operators which are called for every
+// Individual at every generation don't allocate.
+func TestHotPathAllocs(t *testing.T) {
+	var (
+		rng   = newRand()
+		p1    = InitUnifFloat64(100, -10, 10, rng)
+		p2    = InitUnifFloat64(100, -10, 10, rng)
+		indis = newIndividuals(100, false, NewVector, rng)
+	)
+	var testCases = []struct {
+		name string
+		f    func()
+	}{
+		{"CrossUniformFloat64", func() { CrossUniformFloat64(p1, p2, rng) }},
+		{"MutNormalFloat64", func() { MutNormalFloat64(p1, 0.5, rng) }},
+		{"Individuals.Evaluate", func() {
+			for i := range indis {
+				indis[i].Evaluated = false
+			}
+			indis.Evaluate(false)
+		}},
+	}
+	for _, tc := range testCases {
+		if allocs := testing.AllocsPerRun(100, tc.f); allocs != 0 {
+			t.Errorf("%s: expected 0 allocations, got %f", tc.name, allocs)
+		}
+	}
+}
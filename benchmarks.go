@@ -0,0 +1,70 @@
+package eaopt
+
+import "math"
+
+// Benchmark functions commonly used to compare optimizers on real-valued
+// problems. Each of them reaches a minimum of 0 at the origin, apart from
+// Rosenbrock which reaches 0 at (1, ..., 1) and DropWave which reaches -1 at
+// the origin.
+
+// Sphere computes the sum of the squares of x.
+func Sphere(x []float64) (y float64) {
+	for _, xi := range x {
+		y += xi * xi
+	}
+	return
+}
+
+// Rastrigin is a highly multimodal function with regularly distributed local
+// minima, usually evaluated on [-5.12, 5.12]^n.
+func Rastrigin(x []float64) float64 {
+	var y = 10 * float64(len(x))
+	for _, xi := range x {
+		y += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return y
+}
+
+// Rosenbrock is a unimodal function whose minimum lies in a narrow curved
+// valley, usually evaluated on [-5, 10]^n.
+func Rosenbrock(x []float64) (y float64) {
+	for i := 0; i < len(x)-1; i++ {
+		y += 100*math.Pow(x[i+1]-x[i]*x[i], 2) + math.Pow(1-x[i], 2)
+	}
+	return
+}
+
+// Ackley is a multimodal function with a nearly flat outer region, usually
+// evaluated on [-32.768, 32.768]^n.
+func Ackley(x []float64) float64 {
+	var (
+		n      = float64(len(x))
+		sumSq  float64
+		sumCos float64
+	)
+	for _, xi := range x {
+		sumSq += xi * xi
+		sumCos += math.Cos(2 * math.Pi * xi)
+	}
+	return -20*math.Exp(-0.2*math.Sqrt(sumSq/n)) - math.Exp(sumCos/n) + 20 + math.E
+}
+
+// DropWave is a two-dimensional multimodal function, usually evaluated on
+// [-5.12, 5.12]^2. Only the first two elements of x are used.
+func DropWave(x []float64) float64 {
+	var (
+		numerator   = 1 + math.Cos(12*math.Sqrt(x[0]*x[0]+x[1]*x[1]))
+		denominator = 0.5*(x[0]*x[0]+x[1]*x[1]) + 2
+	)
+	return -numerator / denominator
+}
+
+// Benchmarks maps the names of the benchmark functions to the functions
+// themselves.
+var Benchmarks = map[string]func([]float64) float64{
+	"sphere":     Sphere,
+	"rastrigin":  Rastrigin,
+	"rosenbrock": Rosenbrock,
+	"ackley":     Ackley,
+	"dropwave":   DropWave,
+}
@@ -0,0 +1,35 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBenchmarksOptimum(t *testing.T) {
+	var testCases = []struct {
+		name string
+		x    []float64
+		y    float64
+	}{
+		{"sphere", []float64{0, 0, 0}, 0},
+		{"rastrigin", []float64{0, 0, 0}, 0},
+		{"rosenbrock", []float64{1, 1, 1}, 0},
+		{"ackley", []float64{0, 0, 0}, 0},
+		{"dropwave", []float64{0, 0}, -1},
+	}
+	for _, tc := range testCases {
+		var f, ok = Benchmarks[tc.name]
+		if !ok {
+			t.Fatalf("Benchmark %s is missing", tc.name)
+		}
+		if y := f(tc.x); math.Abs(y-tc.y) > 1e-12 {
+			t.Errorf("%s: expected %f, got %f", tc.name, tc.y, y)
+		}
+		// The optimum should be lower than a nearby point
+		var x = copyFloat64s(tc.x)
+		x[0] += 0.1
+		if f(x) <= tc.y {
+			t.Errorf("%s: expected %f to be a minimum", tc.name, tc.y)
+		}
+	}
+}
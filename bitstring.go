@@ -0,0 +1,241 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// GrayEncode returns the reflected binary Gray code of n. Consecutive integers
+// have Gray codes which differ by a single bit, hence a single bit flip can
+// always move a variable to a neighbouring value, which isn't the case with
+// the standard binary encoding (eg. 7 = 0111 and 8 = 1000).
+func GrayEncode(n uint64) uint64 {
+	return n ^ (n >> 1)
+}
+
+// GrayDecode returns the integer whose Gray code is g.
+func GrayDecode(g uint64) uint64 {
+	var n = g
+	for shift := uint(1); shift < 64; shift <<= 1 {
+		n ^= n >> shift
+	}
+	return n
+}
+
+// EncodeGray writes the Gray code of n into bits, the most significant bit
+// first. The bits of n that don't fit are dropped.
+func EncodeGray(n uint64, bits []bool) {
+	var g = GrayEncode(n)
+	for i := range bits {
+		bits[len(bits)-1-i] = g&(1<<uint(i)) != 0
+	}
+}
+
+// DecodeGray returns the integer whose Gray code is written in bits, the most
+// significant bit first. bits can't be longer than 64.
+func DecodeGray(bits []bool) uint64 {
+	var g uint64
+	for _, b := range bits {
+		g <<= 1
+		if b {
+			g |= 1
+		}
+	}
+	return GrayDecode(g)
+}
+
+// A BitField is a segment of Bits bits of a BitString which encodes a variable
+// with Gray code. The variable is discretized into 2^Bits evenly spaced values
+// from Min to Max included.
+type BitField struct {
+	Bits     uint
+	Min, Max float64
+}
+
+// IntBitField returns a BitField encoding the integers from min to
+// min + 2^bits - 1 exactly.
+func IntBitField(min int, bits uint) BitField {
+	return BitField{
+		Bits: bits,
+		Min:  float64(min),
+		Max:  float64(min) + float64(uint64(1)<<bits-1),
+	}
+}
+
+// levels returns the highest integer the BitField can encode.
+func (f BitField) levels() uint64 {
+	return uint64(1)<<f.Bits - 1
+}
+
+// Encode writes the value closest to x that the BitField can represent into
+// bits, which has to contain Bits bits. x is clipped between Min and Max.
+func (f BitField) Encode(x float64, bits []bool) {
+	var level uint64
+	if f.Max > f.Min {
+		var r = math.Min(math.Max((x-f.Min)/(f.Max-f.Min), 0), 1)
+		level = uint64(math.Round(r * float64(f.levels())))
+	}
+	EncodeGray(level, bits)
+}
+
+// Decode returns the value encoded in bits, which has to contain Bits bits.
+func (f BitField) Decode(bits []bool) float64 {
+	return f.Min + float64(DecodeGray(bits))/float64(f.levels())*(f.Max-f.Min)
+}
+
+// MutFlipBits flips each bit with probability rate.
+func MutFlipBits(bits []bool, rate float64, rng *rand.Rand) {
+	for i := range bits {
+		if rng.Float64() < rate {
+			bits[i] = !bits[i]
+		}
+	}
+}
+
+// fieldsLinkage returns a Linkage where each group contains the bits of a
+// field.
+func fieldsLinkage(fields []BitField) Linkage {
+	var (
+		linkage = make(Linkage, len(fields))
+		start   = 0
+	)
+	for i, f := range fields {
+		linkage[i] = make([]int, f.Bits)
+		for j := range linkage[i] {
+			linkage[i][j] = start + j
+		}
+		start += int(f.Bits)
+	}
+	return linkage
+}
+
+// CrossUniformBitFields swaps each field between s1 and s2 with probability
+// 0.5. The fields are never cut, hence each variable of the offsprings is
+// inherited from one of the parents.
+func CrossUniformBitFields(s1 []bool, s2 []bool, fields []BitField, rng *rand.Rand) {
+	for i, swap := range fieldsLinkage(fields).Mask(len(s1), rng) {
+		if swap {
+			s1[i], s2[i] = s2[i], s1[i]
+		}
+	}
+}
+
+// A BitString is a vector of bits made of the Gray-coded fields of the
+// BitProblem it belongs to.
+type BitString struct {
+	Bits    []bool
+	Problem *BitProblem
+}
+
+// Evaluate the BitString by decoding it and calling the BitProblem's objective
+// function.
+func (b *BitString) Evaluate() (float64, error) {
+	return b.Problem.F(b.Problem.Decode(b.Bits))
+}
+
+// Mutate the BitString by flipping each bit with probability MutRate.
+func (b *BitString) Mutate(rng *rand.Rand) {
+	MutFlipBits(b.Bits, b.Problem.MutRate, rng)
+}
+
+// Crossover applies uniform crossover at the level of the fields.
+func (b *BitString) Crossover(q Genome, rng *rand.Rand) {
+	CrossUniformBitFields(b.Bits, q.(*BitString).Bits, b.Problem.Fields, rng)
+}
+
+// Clone returns a deep copy of the BitString.
+func (b BitString) Clone() Genome {
+	var bits = make([]bool, len(b.Bits))
+	copy(bits, b.Bits)
+	return &BitString{
+		Bits:    bits,
+		Problem: b.Problem,
+	}
+}
+
+// BitProblem describes a problem whose variables are encoded in BitStrings
+// with Gray code. Its NewGenome method can be handed to GA.Minimize.
+type BitProblem struct {
+	Fields  []BitField
+	MutRate float64 // Probability of flipping each bit
+	F       func([]float64) (float64, error)
+}
+
+// Validate BitProblem fields.
+func (p BitProblem) Validate() error {
+	if len(p.Fields) == 0 {
+		return errors.New("at least one field has to be provided")
+	}
+	for i, f := range p.Fields {
+		if f.Bits < 1 || f.Bits > 63 {
+			return fmt.Errorf("field %d should have between 1 and 63 bits", i)
+		}
+		if f.Min > f.Max {
+			return fmt.Errorf("field %d should have Min lower or equal to Max", i)
+		}
+	}
+	if p.MutRate < 0 || p.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	if p.F == nil {
+		return errors.New("F cannot be nil")
+	}
+	return nil
+}
+
+// NBits returns the number of bits of the BitStrings.
+func (p BitProblem) NBits() int {
+	var n uint
+	for _, f := range p.Fields {
+		n += f.Bits
+	}
+	return int(n)
+}
+
+// Decode returns the value of each field of bits.
+func (p BitProblem) Decode(bits []bool) []float64 {
+	var (
+		values = make([]float64, len(p.Fields))
+		start  uint
+	)
+	for i, f := range p.Fields {
+		values[i] = f.Decode(bits[start : start+f.Bits])
+		start += f.Bits
+	}
+	return values
+}
+
+// Encode returns the bits encoding values, which contains a value per field.
+func (p BitProblem) Encode(values []float64) []bool {
+	var (
+		bits  = make([]bool, p.NBits())
+		start uint
+	)
+	for i, f := range p.Fields {
+		f.Encode(values[i], bits[start:start+f.Bits])
+		start += f.Bits
+	}
+	return bits
+}
+
+// NewGenome returns a BitString with random bits.
+func (p *BitProblem) NewGenome(rng *rand.Rand) Genome {
+	var bits = make([]bool, p.NBits())
+	for i := range bits {
+		bits[i] = rng.Float64() < 0.5
+	}
+	return &BitString{
+		Bits:    bits,
+		Problem: p,
+	}
+}
+
+// Reset samples new Bits in place as NewGenome would, so that a GenomePool can
+// reuse the BitString.
+func (b *BitString) Reset(rng *rand.Rand) {
+	for i := range b.Bits {
+		b.Bits[i] = rng.Float64() < 0.5
+	}
+}
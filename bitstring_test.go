@@ -0,0 +1,121 @@
+package eaopt
+
+import (
+	"math"
+	"math/bits"
+	"reflect"
+	"testing"
+)
+
+func TestGrayCode(t *testing.T) {
+	for n := uint64(0); n < 1024; n++ {
+		if GrayDecode(GrayEncode(n)) != n {
+			t.Errorf("Expected %d, got %d", n, GrayDecode(GrayEncode(n)))
+		}
+		// Consecutive integers differ by a single bit
+		if d := bits.OnesCount64(GrayEncode(n) ^ GrayEncode(n+1)); d != 1 {
+			t.Errorf("Expected the codes of %d and %d to differ by 1 bit, got %d", n, n+1, d)
+		}
+	}
+	var b = make([]bool, 4)
+	EncodeGray(7, b)
+	if !reflect.DeepEqual(b, []bool{false, true, false, false}) {
+		t.Errorf("Expected 0100, got %v", b)
+	}
+	if DecodeGray(b) != 7 {
+		t.Errorf("Expected 7, got %d", DecodeGray(b))
+	}
+}
+
+func TestBitField(t *testing.T) {
+	var (
+		f = BitField{Bits: 8, Min: -1, Max: 1}
+		b = make([]bool, 8)
+	)
+	for _, x := range []float64{-1, -0.5, 0, 0.3, 1} {
+		f.Encode(x, b)
+		if math.Abs(f.Decode(b)-x) > 1/255.0 {
+			t.Errorf("Expected %f, got %f", x, f.Decode(b))
+		}
+	}
+	// Values are clipped
+	f.Encode(42, b)
+	if f.Decode(b) != 1 {
+		t.Errorf("Expected 1, got %f", f.Decode(b))
+	}
+	var g = IntBitField(-3, 3)
+	b = make([]bool, 3)
+	for i := -3; i <= 4; i++ {
+		g.Encode(float64(i), b)
+		if g.Decode(b) != float64(i) {
+			t.Errorf("Expected %d, got %f", i, g.Decode(b))
+		}
+	}
+}
+
+func TestCrossUniformBitFields(t *testing.T) {
+	var (
+		rng    = newRand()
+		fields = []BitField{{Bits: 3}, {Bits: 2}, {Bits: 4}}
+	)
+	for i := 0; i < 20; i++ {
+		var s1, s2 = make([]bool, 9), []bool{true, true, true, true, true, true, true, true, true}
+		CrossUniformBitFields(s1, s2, fields, rng)
+		// Fields are inherited as a whole
+		for _, field := range [][]bool{s1[:3], s1[3:5], s1[5:]} {
+			for _, b := range field {
+				if b != field[0] {
+					t.Errorf("A field was cut: %v", s1)
+				}
+			}
+		}
+		for j := range s1 {
+			if s1[j] == s2[j] {
+				t.Errorf("Bits should be swapped, got %v and %v", s1, s2)
+			}
+		}
+	}
+}
+
+func TestBitProblem(t *testing.T) {
+	var p = &BitProblem{
+		Fields:  []BitField{{Bits: 10, Min: -5, Max: 5}, IntBitField(0, 4)},
+		MutRate: 0.05,
+		F: func(x []float64) (float64, error) {
+			return (x[0]-1)*(x[0]-1) + (x[1]-3)*(x[1]-3), nil
+		},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if p.NBits() != 14 {
+		t.Errorf("Expected 14 bits, got %d", p.NBits())
+	}
+	if x := p.Decode(p.Encode([]float64{1, 3})); math.Abs(x[0]-1) > 0.01 || x[1] != 3 {
+		t.Errorf("Expected [1 3], got %v", x)
+	}
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 30
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatal(err)
+	}
+	if ga.HallOfFame[0].Fitness > 0.01 {
+		t.Errorf("Expected a fitness close to 0, got %f", ga.HallOfFame[0].Fitness)
+	}
+	for _, q := range []BitProblem{
+		{MutRate: 0.1, F: p.F},
+		{Fields: []BitField{{Bits: 0}}, MutRate: 0.1, F: p.F},
+		{Fields: []BitField{{Bits: 64}}, MutRate: 0.1, F: p.F},
+		{Fields: []BitField{{Bits: 2, Min: 1, Max: 0}}, MutRate: 0.1, F: p.F},
+		{Fields: []BitField{{Bits: 2}}, MutRate: 2, F: p.F},
+		{Fields: []BitField{{Bits: 2}}, MutRate: 0.1},
+	} {
+		if err := q.Validate(); err == nil {
+			t.Errorf("Expected an error with %v", q)
+		}
+	}
+}
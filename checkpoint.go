@@ -0,0 +1,254 @@
+package eaopt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CheckpointOptions determine how checkpoints, such as the JSON encoding of a
+// GA, are stored. If Gzip is true then checkpoints are compressed with gzip at
+// the given Level, 0 meaning gzip.DefaultCompression. If Zstd is true then
+// checkpoints are compressed with zstd instead, which is faster and compresses
+// better, Level being a zstd level between 1 and 22 and 0 meaning the default
+// level. If Key isn't nil then checkpoints are encrypted and authenticated
+// with AES-GCM, Key being an AES key of 16, 24 or 32 bytes. Compression
+// happens before encryption because encrypted data doesn't compress.
+type CheckpointOptions struct {
+	Gzip  bool
+	Zstd  bool
+	Level int
+	Key   []byte
+}
+
+// Validate CheckpointOptions fields.
+func (opts CheckpointOptions) Validate() error {
+	if opts.Gzip && opts.Zstd {
+		return errors.New("Gzip and Zstd can't both be true")
+	}
+	if opts.Zstd {
+		if opts.Level < 0 || opts.Level > 22 {
+			return errors.New("Level should be between 0 and 22")
+		}
+	} else if opts.Level < gzip.HuffmanOnly || opts.Level > gzip.BestCompression {
+		return fmt.Errorf("Level should be between %d and %d", gzip.HuffmanOnly, gzip.BestCompression)
+	}
+	if opts.Key != nil {
+		if _, err := aes.NewCipher(opts.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkpointMagic starts every compressed or encrypted checkpoint, it is
+// followed by a byte of flags.
+var checkpointMagic = []byte("EAOPTCK1")
+
+const (
+	checkpointGzip byte = 1 << iota
+	checkpointAESGCM
+	checkpointZstd
+)
+
+// SealCheckpoint compresses and encrypts data according to opts. The result
+// starts with a header which records how data was transformed, hence
+// OpenCheckpoint only needs the Key. data is returned as it is if opts
+// neither compresses nor encrypts.
+func SealCheckpoint(data []byte, opts CheckpointOptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	var flags byte
+	if opts.Gzip {
+		flags |= checkpointGzip
+		var level = opts.Level
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		// The level was validated, hence NewWriterLevel can't fail
+		var (
+			buf  bytes.Buffer
+			w, _ = gzip.NewWriterLevel(&buf, level)
+		)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+	if opts.Zstd {
+		flags |= checkpointZstd
+		var level = zstd.SpeedDefault
+		if opts.Level > 0 {
+			level = zstd.EncoderLevelFromZstd(opts.Level)
+		}
+		var w, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+		if err != nil {
+			return nil, err
+		}
+		data = w.EncodeAll(data, nil)
+		w.Close()
+	}
+	if opts.Key != nil {
+		flags |= checkpointAESGCM
+	}
+	if flags == 0 {
+		return data, nil
+	}
+	var header = append(append([]byte(nil), checkpointMagic...), flags)
+	if opts.Key == nil {
+		return append(header, data...), nil
+	}
+	var gcm, err = newGCM(opts.Key)
+	if err != nil {
+		return nil, err
+	}
+	var nonce = make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	// The header is authenticated so that the flags can't be tampered with
+	var sealed = append(append([]byte(nil), header...), nonce...)
+	return gcm.Seal(sealed, nonce, data, header), nil
+}
+
+// OpenCheckpoint reverses SealCheckpoint. Data without the header written by
+// SealCheckpoint is returned as it is, which allows reading checkpoints that
+// were neither compressed nor encrypted. Only the Key of opts is used; an
+// error is returned if the checkpoint is encrypted and Key is nil or wrong, or
+// if the checkpoint has been modified.
+func OpenCheckpoint(data []byte, opts CheckpointOptions) ([]byte, error) {
+	if !bytes.HasPrefix(data, checkpointMagic) {
+		return data, nil
+	}
+	if len(data) == len(checkpointMagic) {
+		return nil, errors.New("truncated checkpoint")
+	}
+	var (
+		header = data[:len(checkpointMagic)+1]
+		flags  = header[len(header)-1]
+	)
+	if flags&^(checkpointGzip|checkpointAESGCM|checkpointZstd) != 0 {
+		return nil, fmt.Errorf("unknown checkpoint flags %#x", flags)
+	}
+	data = data[len(header):]
+	if flags&checkpointAESGCM != 0 {
+		if opts.Key == nil {
+			return nil, errors.New("the checkpoint is encrypted and Key is nil")
+		}
+		var gcm, err = newGCM(opts.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < gcm.NonceSize() {
+			return nil, errors.New("truncated checkpoint")
+		}
+		if data, err = gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], header); err != nil {
+			return nil, errors.New("the checkpoint can't be decrypted, the key is wrong or the " +
+				"checkpoint has been modified")
+		}
+	}
+	if flags&checkpointGzip != 0 {
+		var r, err = gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			return nil, err
+		}
+	}
+	if flags&checkpointZstd != 0 {
+		var r, err = zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		if data, err = r.DecodeAll(data, nil); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// newGCM returns an AES-GCM cipher using key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	var block, err = aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteCheckpoint writes the JSON encoding of the GA to w, compressed and
+// encrypted according to opts.
+func (ga *GA) WriteCheckpoint(w io.Writer, opts CheckpointOptions) error {
+	var data, err = json.Marshal(ga)
+	if err != nil {
+		return err
+	}
+	if data, err = SealCheckpoint(data, opts); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ExportState returns a checkpoint of the GA as a string, for storages which
+// only hold strings such as the local storage of a browser. The checkpoint is
+// the JSON encoding of the GA if opts neither compresses nor encrypts, else it
+// is encoded in base64.
+func (ga *GA) ExportState(opts CheckpointOptions) (string, error) {
+	var data, err = json.Marshal(ga)
+	if err != nil {
+		return "", err
+	}
+	if data, err = SealCheckpoint(data, opts); err != nil {
+		return "", err
+	}
+	if bytes.HasPrefix(data, checkpointMagic) {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return string(data), nil
+}
+
+// ImportState restores the GA from a string returned by ExportState.
+func (ga *GA) ImportState(state string, opts CheckpointOptions) error {
+	var (
+		data = []byte(state)
+		err  error
+	)
+	if !json.Valid(data) {
+		if data, err = base64.StdEncoding.DecodeString(state); err != nil {
+			return fmt.Errorf("the state is neither JSON nor base64: %w", err)
+		}
+	}
+	if data, err = OpenCheckpoint(data, opts); err != nil {
+		return err
+	}
+	return ga.UnmarshalJSON(data)
+}
+
+// ReadCheckpoint restores the GA from a checkpoint written by WriteCheckpoint
+// or from its plain JSON encoding, see UnmarshalJSON.
+func (ga *GA) ReadCheckpoint(r io.Reader, opts CheckpointOptions) error {
+	var data, err = io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if data, err = OpenCheckpoint(data, opts); err != nil {
+		return err
+	}
+	return ga.UnmarshalJSON(data)
+}
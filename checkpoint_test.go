@@ -0,0 +1,161 @@
+package eaopt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestSealCheckpoint(t *testing.T) {
+	var (
+		data = bytes.Repeat([]byte(`{"genome":[1,2,3,4],"fitness":10}`), 100)
+		key  = bytes.Repeat([]byte{7}, 32)
+	)
+	for _, opts := range []CheckpointOptions{
+		{},
+		{Gzip: true},
+		{Gzip: true, Level: gzip.BestCompression},
+		{Key: key},
+		{Gzip: true, Key: key[:16]},
+		{Zstd: true},
+		{Zstd: true, Level: 19, Key: key},
+	} {
+		var sealed, err = SealCheckpoint(data, opts)
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if (opts.Gzip || opts.Zstd) && len(sealed) >= len(data) {
+			t.Errorf("The checkpoint wasn't compressed: %d bytes", len(sealed))
+		}
+		if opts.Key != nil && bytes.Contains(sealed, []byte("genome")) {
+			t.Error("The checkpoint wasn't encrypted")
+		}
+		opened, err := OpenCheckpoint(sealed, CheckpointOptions{Key: opts.Key})
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if !bytes.Equal(opened, data) {
+			t.Errorf("Wrong data for %+v", opts)
+		}
+	}
+}
+
+func TestOpenCheckpointErrors(t *testing.T) {
+	var (
+		data      = []byte(`{"a":1}`)
+		key       = bytes.Repeat([]byte{7}, 32)
+		sealed, _ = SealCheckpoint(data, CheckpointOptions{Gzip: true, Key: key})
+	)
+	if _, err := OpenCheckpoint(sealed, CheckpointOptions{}); err == nil {
+		t.Error("Expected an error without the key")
+	}
+	if _, err := OpenCheckpoint(sealed, CheckpointOptions{Key: bytes.Repeat([]byte{8}, 32)}); err == nil {
+		t.Error("Expected an error with the wrong key")
+	}
+	var tampered = append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := OpenCheckpoint(tampered, CheckpointOptions{Key: key}); err == nil {
+		t.Error("Expected an error with a modified checkpoint")
+	}
+	// Dropping the gzip flag invalidates the authentication
+	tampered = append([]byte(nil), sealed...)
+	tampered[len(checkpointMagic)] &^= checkpointGzip
+	if _, err := OpenCheckpoint(tampered, CheckpointOptions{Key: key}); err == nil {
+		t.Error("Expected an error with modified flags")
+	}
+	if _, err := OpenCheckpoint(checkpointMagic, CheckpointOptions{}); err == nil {
+		t.Error("Expected an error with a truncated checkpoint")
+	}
+	if _, err := SealCheckpoint(data, CheckpointOptions{Key: []byte("short")}); err == nil {
+		t.Error("Expected an error with an invalid key")
+	}
+	if _, err := SealCheckpoint(data, CheckpointOptions{Gzip: true, Level: 42}); err == nil {
+		t.Error("Expected an error with an invalid level")
+	}
+	if _, err := SealCheckpoint(data, CheckpointOptions{Zstd: true, Level: 23}); err == nil {
+		t.Error("Expected an error with an invalid zstd level")
+	}
+	if _, err := SealCheckpoint(data, CheckpointOptions{Gzip: true, Zstd: true}); err == nil {
+		t.Error("Expected an error with both gzip and zstd")
+	}
+	// Flags written by a newer version of eaopt are rejected
+	if _, err := OpenCheckpoint(append(append([]byte(nil), checkpointMagic...), 1<<7), CheckpointOptions{}); err == nil {
+		t.Error("Expected an error with unknown flags")
+	}
+}
+
+func TestGACheckpoint(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 3
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var (
+		buf  bytes.Buffer
+		opts = CheckpointOptions{Gzip: true, Key: bytes.Repeat([]byte{1}, 24)}
+	)
+	if err = ga.WriteCheckpoint(&buf, opts); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var resumed *GA
+	if resumed, err = conf.NewGA(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = resumed.ReadCheckpoint(&buf, opts); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if resumed.Generations != 3 || len(resumed.Populations) != int(conf.NPops) ||
+		resumed.HallOfFame[0].Fitness != ga.HallOfFame[0].Fitness {
+		t.Error("The GA wasn't restored")
+	}
+	// Plain JSON checkpoints can be read as well
+	buf.Reset()
+	if err = ga.WriteCheckpoint(&buf, CheckpointOptions{}); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if buf.Bytes()[0] != '{' {
+		t.Error("Expected plain JSON")
+	}
+	resumed, _ = conf.NewGA()
+	if err = resumed.ReadCheckpoint(&buf, opts); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+}
+
+func TestGAExportState(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 3
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for _, opts := range []CheckpointOptions{{}, {Gzip: true, Key: bytes.Repeat([]byte{1}, 16)}} {
+		var state string
+		if state, err = ga.ExportState(opts); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if sealed := state[0] != '{'; sealed != (opts.Key != nil) {
+			t.Errorf("Expected a sealed state to be %t, got %q", opts.Key != nil, state[:10])
+		}
+		var resumed, _ = conf.NewGA()
+		if err = resumed.ImportState(state, opts); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if resumed.Generations != 3 || resumed.HallOfFame[0].Fitness != ga.HallOfFame[0].Fitness {
+			t.Error("The GA wasn't restored")
+		}
+	}
+	var resumed, _ = conf.NewGA()
+	if err = resumed.ImportState("not a state", CheckpointOptions{}); err == nil {
+		t.Error("Expected an error")
+	}
+}
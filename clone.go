@@ -0,0 +1,330 @@
+package eaopt
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DeepCopy returns a deep copy of v which can be used to implement a Genome's
+// Clone method. Pointers, slices, maps and interfaces are followed and copied,
+// cycles included, whereas functions and channels are shared. Unexported
+// fields are copied as they are, hence an error is returned if one of them
+// holds a non-nil pointer, slice, map or interface because the copy would
+// silently share it with v. Genomes which hold a reference to a shared context,
+// for instance the optimizer that created them, should copy their own fields
+// by hand instead.
+func DeepCopy(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var c, err = deepCopy(reflect.ValueOf(v), make(map[uintptr]reflect.Value))
+	if err != nil {
+		return nil, err
+	}
+	return c.Interface(), nil
+}
+
+// deepCopy returns a deep copy of v. copies contains the copies of the
+// pointers that have already been encountered.
+func deepCopy(v reflect.Value, copies map[uintptr]reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		if c, ok := copies[v.Pointer()]; ok {
+			return c, nil
+		}
+		var c = reflect.New(v.Type().Elem())
+		copies[v.Pointer()] = c
+		var e, err = deepCopy(v.Elem(), copies)
+		if err != nil {
+			return v, err
+		}
+		c.Elem().Set(e)
+		return c, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		var e, err = deepCopy(v.Elem(), copies)
+		if err != nil {
+			return v, err
+		}
+		var c = reflect.New(v.Type()).Elem()
+		c.Set(e)
+		return c, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		var c = reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+		for i := 0; i < v.Len(); i++ {
+			var e, err = deepCopy(v.Index(i), copies)
+			if err != nil {
+				return v, err
+			}
+			c.Index(i).Set(e)
+		}
+		return c, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		var (
+			c    = reflect.MakeMapWithSize(v.Type(), v.Len())
+			iter = v.MapRange()
+		)
+		for iter.Next() {
+			var k, err = deepCopy(iter.Key(), copies)
+			if err != nil {
+				return v, err
+			}
+			e, err := deepCopy(iter.Value(), copies)
+			if err != nil {
+				return v, err
+			}
+			c.SetMapIndex(k, e)
+		}
+		return c, nil
+	case reflect.Array:
+		var c = reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			var e, err = deepCopy(v.Index(i), copies)
+			if err != nil {
+				return v, err
+			}
+			c.Index(i).Set(e)
+		}
+		return c, nil
+	case reflect.Struct:
+		var c = reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			var field = v.Type().Field(i)
+			if !holdsReferences(field.Type) {
+				continue
+			}
+			if !field.IsExported() {
+				if v.Field(i).IsZero() {
+					continue
+				}
+				return v, fmt.Errorf("can't copy the unexported field %s of %s", field.Name, v.Type())
+			}
+			var e, err = deepCopy(v.Field(i), copies)
+			if err != nil {
+				return v, err
+			}
+			c.Field(i).Set(e)
+		}
+		return c, nil
+	default:
+		return v, nil
+	}
+}
+
+// holdsReferences returns true if values of type t can refer to memory that
+// a shallow copy would share. Functions, channels and strings are immutable or
+// meant to be shared and aren't considered.
+func holdsReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return holdsReferences(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if holdsReferences(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckClone detects aliasing bugs in Clone methods. It clones g twice,
+// mutates and crosses over the clones and checks that g didn't change. Memory
+// that g shares with other, a Genome created independently, is considered as
+// a shared context and is ignored; this is for instance the case of the
+// function being minimized or of the optimizer that created the Genomes. g and
+// other aren't modified if Clone is correct.
+func CheckClone(g, other Genome, rng *rand.Rand) error {
+	var (
+		shared = make(map[uintptr]bool)
+		mine   = make(map[uintptr]bool)
+		theirs = make(map[uintptr]bool)
+	)
+	reachable(reflect.ValueOf(g), mine)
+	reachable(reflect.ValueOf(other), theirs)
+	for p := range mine {
+		if theirs[p] {
+			shared[p] = true
+		}
+	}
+	var (
+		before = fingerprint(g, shared)
+		a, b   = g.Clone(), g.Clone()
+	)
+	for i := 0; i < 3; i++ {
+		a.Mutate(rng)
+		b.Mutate(rng)
+	}
+	a.Crossover(b, rng)
+	if fingerprint(g, shared) != before {
+		return fmt.Errorf("modifying a clone of a %T modified the original, its Clone method "+
+			"has to copy the pointers, slices and maps it holds", g)
+	}
+	return nil
+}
+
+// reachable adds the addresses of the pointers, maps and slices reachable
+// from v to seen.
+func reachable(v reflect.Value, seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		reachable(v.Elem(), seen)
+	case reflect.Interface:
+		if !v.IsNil() {
+			reachable(v.Elem(), seen)
+		}
+	case reflect.Slice:
+		if v.Cap() > 0 {
+			seen[v.Pointer()] = true
+		}
+		for i := 0; i < v.Len(); i++ {
+			reachable(v.Index(i), seen)
+		}
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		var iter = v.MapRange()
+		for iter.Next() {
+			reachable(iter.Key(), seen)
+			reachable(iter.Value(), seen)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			reachable(v.Index(i), seen)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			reachable(v.Field(i), seen)
+		}
+	}
+}
+
+// fingerprint returns a representation of everything reachable from v, except
+// that the shared pointers, maps and slices are represented by their address.
+// Two fingerprints of the same value are equal if and only if the value
+// didn't change in between.
+func fingerprint(v interface{}, shared map[uintptr]bool) string {
+	var sb strings.Builder
+	writeFingerprint(&sb, reflect.ValueOf(v), shared, make(map[uintptr]int))
+	return sb.String()
+}
+
+// writeFingerprint writes the fingerprint of v to sb. visited contains the
+// index of the pointers that have already been encountered.
+func writeFingerprint(sb *strings.Builder, v reflect.Value, shared map[uintptr]bool, visited map[uintptr]int) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		sb.WriteString("nil")
+	case reflect.Ptr:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		var p = v.Pointer()
+		if shared[p] {
+			fmt.Fprintf(sb, "&%x", p)
+			return
+		}
+		if i, ok := visited[p]; ok {
+			fmt.Fprintf(sb, "@%d", i)
+			return
+		}
+		visited[p] = len(visited)
+		sb.WriteString("*")
+		writeFingerprint(sb, v.Elem(), shared, visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		fmt.Fprintf(sb, "%s(", v.Elem().Type())
+		writeFingerprint(sb, v.Elem(), shared, visited)
+		sb.WriteString(")")
+	case reflect.Slice:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		if v.Cap() > 0 && shared[v.Pointer()] {
+			fmt.Fprintf(sb, "&%x[%d]", v.Pointer(), v.Len())
+			return
+		}
+		fallthrough
+	case reflect.Array:
+		sb.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			writeFingerprint(sb, v.Index(i), shared, visited)
+			sb.WriteString(",")
+		}
+		sb.WriteString("]")
+	case reflect.Map:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		if shared[v.Pointer()] {
+			fmt.Fprintf(sb, "&%x", v.Pointer())
+			return
+		}
+		// Map iteration order is random, hence the entries are sorted
+		var (
+			entries = make([]string, 0, v.Len())
+			iter    = v.MapRange()
+		)
+		for iter.Next() {
+			var entry strings.Builder
+			writeFingerprint(&entry, iter.Key(), shared, visited)
+			entry.WriteString(":")
+			writeFingerprint(&entry, iter.Value(), shared, visited)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		fmt.Fprintf(sb, "map%v", entries)
+	case reflect.Struct:
+		sb.WriteString("{")
+		for i := 0; i < v.NumField(); i++ {
+			writeFingerprint(sb, v.Field(i), shared, visited)
+			sb.WriteString(",")
+		}
+		sb.WriteString("}")
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		fmt.Fprintf(sb, "&%x", v.Pointer())
+	case reflect.Bool:
+		fmt.Fprintf(sb, "%t", v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprintf(sb, "%d", v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprintf(sb, "%d", v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprintf(sb, "%x", math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		var c = v.Complex()
+		fmt.Fprintf(sb, "%x,%x", math.Float64bits(real(c)), math.Float64bits(imag(c)))
+	case reflect.String:
+		fmt.Fprintf(sb, "%q", v.String())
+	}
+}
@@ -0,0 +1,124 @@
+package eaopt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// A shallowVector's Clone method forgets to copy the underlying slice.
+type shallowVector struct {
+	Values []float64
+}
+
+func (sv *shallowVector) Evaluate() (float64, error) { return Vector(sv.Values).Evaluate() }
+func (sv *shallowVector) Mutate(rng *rand.Rand)      { MutNormalFloat64(sv.Values, 0.5, rng) }
+func (sv *shallowVector) Crossover(y Genome, rng *rand.Rand) {
+	CrossUniformFloat64(sv.Values, y.(*shallowVector).Values, rng)
+}
+func (sv *shallowVector) Clone() Genome { return &shallowVector{Values: sv.Values} }
+
+func newShallowVector(rng *rand.Rand) Genome {
+	return &shallowVector{Values: InitUnifFloat64(4, -10, 10, rng)}
+}
+
+// A contextVector shares a counter with the other contextVectors, which is
+// legitimate.
+type contextVector struct {
+	x       []float64
+	counter *int
+}
+
+func (cv *contextVector) Evaluate() (float64, error) { return Vector(cv.x).Evaluate() }
+func (cv *contextVector) Mutate(rng *rand.Rand) {
+	*cv.counter++
+	MutNormalFloat64(cv.x, 0.5, rng)
+}
+func (cv *contextVector) Crossover(y Genome, rng *rand.Rand) {}
+func (cv *contextVector) Clone() Genome {
+	return &contextVector{x: copyFloat64s(cv.x), counter: cv.counter}
+}
+
+func TestCheckClone(t *testing.T) {
+	var (
+		rng     = newRand()
+		counter int
+		newCV   = func(rng *rand.Rand) Genome {
+			return &contextVector{x: InitUnifFloat64(4, -10, 10, rng), counter: &counter}
+		}
+	)
+	if err := CheckClone(NewVector(rng), NewVector(rng), rng); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if err := CheckClone(newCV(rng), newCV(rng), rng); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if counter == 0 {
+		t.Error("The clones weren't mutated")
+	}
+	if err := CheckClone(newShallowVector(rng), newShallowVector(rng), rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestGACheckClones(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.CheckClones = true
+	conf.NGenerations = 2
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(newShallowVector); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+type deepCopyNode struct {
+	Values   []float64
+	Labels   map[string]int
+	Next     *deepCopyNode
+	Fitness  float64
+	Genome   Genome
+	private  int
+	children []*deepCopyNode
+}
+
+func TestDeepCopy(t *testing.T) {
+	var node = &deepCopyNode{
+		Values:  []float64{1, 2},
+		Labels:  map[string]int{"a": 1},
+		Fitness: 3,
+		Genome:  Vector{4, 5},
+		private: 6,
+	}
+	node.Next = node
+	var c, err = DeepCopy(node)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var cn = c.(*deepCopyNode)
+	if cn == node || cn.Next != cn {
+		t.Error("The cycle wasn't preserved")
+	}
+	if cn.Fitness != 3 || cn.private != 6 {
+		t.Errorf("Values weren't copied: %v", cn)
+	}
+	cn.Values[0] = 10
+	cn.Labels["a"] = 10
+	cn.Genome.(Vector)[0] = 10
+	if node.Values[0] != 1 || node.Labels["a"] != 1 || node.Genome.(Vector)[0] != 4 {
+		t.Error("The copy shares memory with the original")
+	}
+	// Unexported fields holding references can't be copied
+	node.children = []*deepCopyNode{node}
+	if _, err = DeepCopy(node); err == nil {
+		t.Error("Expected an error")
+	}
+	if c, err = DeepCopy(nil); c != nil || err != nil {
+		t.Errorf("Expected nil, nil, got %v, %v", c, err)
+	}
+}
@@ -0,0 +1,339 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// ClusterOptions configure the clustering of a GA's Individuals that is
+// included in its GenerationStats, see ClusterIndividuals. Clustering is done
+// every Every generations, 0 is the same as 1.
+//
+// If Metric is nil then the Genomes are clustered with k-means, which requires
+// turning them into vectors of floats: Vectorize is used if it isn't nil,
+// otherwise the Genomes should be slices of floats, such as a Vector. If Metric
+// isn't nil then the Genomes are clustered with k-medoids, which works with
+// any kind of Genome but requires computing the distance between every pair of
+// Individuals.
+type ClusterOptions struct {
+	K             uint                   // Number of clusters
+	MaxIterations uint                   // 0 means until the clusters don't change
+	Every         uint                   // Frequency at which the Individuals are clustered
+	Metric        Metric                 // Optional, enables k-medoids
+	Vectorize     func(Genome) []float64 // Optional, converts a Genome for k-means
+}
+
+// Validate ClusterOptions fields.
+func (co ClusterOptions) Validate() error {
+	if co.K == 0 {
+		return errors.New("K should be positive")
+	}
+	return nil
+}
+
+// due returns true if the Individuals should be clustered at the given
+// generation.
+func (co ClusterOptions) due(generation uint) bool {
+	return co.Every <= 1 || generation%co.Every == 0
+}
+
+// A Cluster is a group of similar Individuals. With k-means, Centroid is the
+// mean of the Individuals' vectors; with k-medoids, Medoid is the ID of the
+// Individual closest to the others. Spread is the average distance between the
+// Individuals and the Centroid or Medoid, Best is the lowest fitness.
+type Cluster struct {
+	Size     int       `json:"size"`
+	Centroid []float64 `json:"centroid,omitempty"`
+	Medoid   string    `json:"medoid,omitempty"`
+	Spread   float64   `json:"spread"`
+	Best     float64   `json:"best"`
+}
+
+// MarshalJSON encodes a Cluster, a non-finite Best is encoded as null.
+func (c Cluster) MarshalJSON() ([]byte, error) {
+	type alias Cluster
+	return json.Marshal(struct {
+		alias
+		Best *float64 `json:"best"`
+	}{alias(c), jsonFloat64(c.Best)})
+}
+
+// A ClusterReport describes how Individuals are spread over the search space.
+// Clusters are sorted by decreasing Size, empty clusters are omitted. A
+// population that has collapsed has a single large cluster with a small
+// Spread, or several clusters whose Centroids are close to each other.
+// Generation is the GA generation at which the report was made.
+type ClusterReport struct {
+	Generation uint      `json:"generation"`
+	Clusters   []Cluster `json:"clusters"`
+}
+
+// ClusterIndividuals partitions indis into at most opts.K clusters. The first
+// center is the best Individual and each following one is the Individual
+// furthest from the centers chosen so far, hence the result is deterministic
+// and distinct Individuals are preferred even when most of them are
+// duplicates. indis isn't modified.
+func ClusterIndividuals(indis Individuals, opts ClusterOptions) (ClusterReport, error) {
+	if err := opts.Validate(); err != nil {
+		return ClusterReport{}, err
+	}
+	if len(indis) == 0 {
+		return ClusterReport{}, nil
+	}
+	if opts.Metric != nil {
+		return kMedoids(indis, opts), nil
+	}
+	var vectors = make([][]float64, len(indis))
+	for i, indi := range indis {
+		var v, err = vectorize(indi.Genome, opts.Vectorize)
+		if err != nil {
+			return ClusterReport{}, err
+		}
+		if i > 0 && len(v) != len(vectors[0]) {
+			return ClusterReport{}, fmt.Errorf("genomes have %d and %d values, k-means requires "+
+				"a fixed number of values", len(vectors[0]), len(v))
+		}
+		vectors[i] = v
+	}
+	return kMeans(indis, vectors, opts), nil
+}
+
+// Clusters clusters the Individuals of all the GA's Populations according to
+// the GA's ClusterOptions.
+func (ga *GA) Clusters() (ClusterReport, error) {
+	if ga.Clustering == nil {
+		return ClusterReport{}, errors.New("Clustering is nil")
+	}
+	var indis Individuals
+	for _, pop := range ga.Populations {
+		indis = append(indis, pop.Individuals...)
+	}
+	var report, err = ClusterIndividuals(indis, *ga.Clustering)
+	report.Generation = ga.Generations
+	return report, err
+}
+
+// vectorize converts a Genome into a vector of floats with f if it isn't nil,
+// otherwise the Genome has to be a slice of floats.
+func vectorize(genome Genome, f func(Genome) []float64) ([]float64, error) {
+	if f != nil {
+		return f(genome), nil
+	}
+	var v = reflect.ValueOf(genome)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice || (v.Type().Elem().Kind() != reflect.Float64 &&
+		v.Type().Elem().Kind() != reflect.Float32) {
+		return nil, fmt.Errorf("can't use k-means on a %T, provide Vectorize or a Metric", genome)
+	}
+	var x = make([]float64, v.Len())
+	for i := range x {
+		x[i] = v.Index(i).Float()
+	}
+	return x, nil
+}
+
+// euclidean returns the Euclidean distance between two vectors.
+func euclidean(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(s)
+}
+
+// farthestFirst returns the indexes of at most k centers among n points, the
+// first one being the best point and each following one the point furthest
+// from the centers chosen so far. Points at distance 0 from every center
+// aren't chosen.
+func farthestFirst(n, k int, best int, dist func(i, j int) float64) []int {
+	var (
+		centers = []int{best}
+		nearest = make([]float64, n)
+	)
+	for i := range nearest {
+		nearest[i] = dist(i, best)
+	}
+	for len(centers) < k {
+		var next, max = -1, 0.
+		for i, d := range nearest {
+			if d > max {
+				next, max = i, d
+			}
+		}
+		if next == -1 {
+			break
+		}
+		centers = append(centers, next)
+		for i := range nearest {
+			nearest[i] = math.Min(nearest[i], dist(i, next))
+		}
+	}
+	return centers
+}
+
+// bestIndex returns the index of the best Individual.
+func bestIndex(indis Individuals) int {
+	var best int
+	for i, indi := range indis {
+		if indi.Better(indis[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// kMeans clusters the Individuals with Lloyd's algorithm given their vectors.
+func kMeans(indis Individuals, vectors [][]float64, opts ClusterOptions) ClusterReport {
+	var (
+		seeds = farthestFirst(len(vectors), int(opts.K), bestIndex(indis), func(i, j int) float64 {
+			return euclidean(vectors[i], vectors[j])
+		})
+		centroids = make([][]float64, len(seeds))
+		assign    = make([]int, len(vectors))
+	)
+	for c, i := range seeds {
+		centroids[c] = copyFloat64s(vectors[i])
+	}
+	for iter := uint(0); opts.MaxIterations == 0 || iter < opts.MaxIterations; iter++ {
+		// Assign each vector to the closest centroid
+		var changed = iter == 0
+		for i, v := range vectors {
+			if closest := closestVector(v, centroids); closest != assign[i] {
+				assign[i], changed = closest, true
+			}
+		}
+		if !changed {
+			break
+		}
+		// Move each centroid to the mean of its vectors, a centroid without
+		// vectors stays where it is
+		var (
+			sums   = make([][]float64, len(centroids))
+			counts = make([]int, len(centroids))
+		)
+		for c := range sums {
+			sums[c] = make([]float64, len(vectors[0]))
+		}
+		for i, v := range vectors {
+			counts[assign[i]]++
+			for k, x := range v {
+				sums[assign[i]][k] += x
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				continue
+			}
+			for k := range sums[c] {
+				centroids[c][k] = sums[c][k] / float64(counts[c])
+			}
+		}
+	}
+	var clusters = make([]Cluster, len(centroids))
+	for c := range clusters {
+		clusters[c] = Cluster{Centroid: centroids[c], Best: math.Inf(1)}
+	}
+	for i, v := range vectors {
+		var c = &clusters[assign[i]]
+		c.Size++
+		c.Spread += euclidean(v, centroids[assign[i]])
+		c.Best = math.Min(c.Best, indis[i].Fitness)
+	}
+	return newClusterReport(clusters)
+}
+
+// closestVector returns the index of the vector closest to v.
+func closestVector(v []float64, vectors [][]float64) int {
+	var closest, min = 0, math.Inf(1)
+	for i, w := range vectors {
+		if d := euclidean(v, w); d < min {
+			closest, min = i, d
+		}
+	}
+	return closest
+}
+
+// kMedoids clusters the Individuals around medoids with opts.Metric. The
+// distances between every pair of Individuals are computed once.
+func kMedoids(indis Individuals, opts ClusterOptions) ClusterReport {
+	var dist = make([][]float64, len(indis))
+	for i := range indis {
+		dist[i] = make([]float64, len(indis))
+		for j := 0; j < i; j++ {
+			dist[i][j] = opts.Metric(indis[i], indis[j])
+			dist[j][i] = dist[i][j]
+		}
+	}
+	var (
+		medoids = farthestFirst(len(indis), int(opts.K), bestIndex(indis), func(i, j int) float64 {
+			return dist[i][j]
+		})
+		members [][]int
+	)
+	for iter := uint(0); opts.MaxIterations == 0 || iter < opts.MaxIterations; iter++ {
+		// Assign each Individual to the closest medoid
+		members = make([][]int, len(medoids))
+		for i := range indis {
+			var closest, min = 0, math.Inf(1)
+			for c, m := range medoids {
+				if dist[i][m] < min {
+					closest, min = c, dist[i][m]
+				}
+			}
+			members[closest] = append(members[closest], i)
+		}
+		// Replace each medoid by the member which is the closest to the others
+		var changed bool
+		for c, m := range members {
+			var best, min = medoids[c], math.Inf(1)
+			for _, i := range m {
+				var total float64
+				for _, j := range m {
+					total += dist[i][j]
+				}
+				if total < min {
+					best, min = i, total
+				}
+			}
+			if best != medoids[c] {
+				medoids[c], changed = best, true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	var clusters = make([]Cluster, len(medoids))
+	for c, m := range members {
+		clusters[c] = Cluster{Medoid: indis[medoids[c]].ID, Best: math.Inf(1)}
+		for _, i := range m {
+			clusters[c].Size++
+			clusters[c].Spread += dist[i][medoids[c]]
+			clusters[c].Best = math.Min(clusters[c].Best, indis[i].Fitness)
+		}
+	}
+	return newClusterReport(clusters)
+}
+
+// newClusterReport averages the Spread of the clusters, drops the empty ones
+// and sorts the others by decreasing Size.
+func newClusterReport(clusters []Cluster) ClusterReport {
+	var report ClusterReport
+	for _, c := range clusters {
+		if c.Size == 0 {
+			continue
+		}
+		c.Spread /= float64(c.Size)
+		report.Clusters = append(report.Clusters, c)
+	}
+	sort.SliceStable(report.Clusters, func(i, j int) bool {
+		return report.Clusters[i].Size > report.Clusters[j].Size
+	})
+	return report
+}
@@ -0,0 +1,134 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+// twoBlobs returns Individuals whose Vectors are grouped around (0, 0) and
+// (100, 100), with 6 and 3 Individuals respectively.
+func twoBlobs() Individuals {
+	var indis Individuals
+	for i, x := range [][]float64{
+		{0, 0}, {1, 0}, {0, 1}, {1, 1}, {-1, 0}, {0, -1},
+		{100, 100}, {101, 100}, {100, 101},
+	} {
+		var v = Vector(x)
+		var f, _ = v.Evaluate()
+		indis = append(indis, Individual{Genome: v, Fitness: f, ID: string(rune('a' + i))})
+	}
+	return indis
+}
+
+func TestClusterIndividualsKMeans(t *testing.T) {
+	var report, err = ClusterIndividuals(twoBlobs(), ClusterOptions{K: 2})
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(report.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d", len(report.Clusters))
+	}
+	var big, small = report.Clusters[0], report.Clusters[1]
+	if big.Size != 6 || small.Size != 3 {
+		t.Errorf("Expected sizes 6 and 3, got %d and %d", big.Size, small.Size)
+	}
+	if math.Abs(big.Centroid[0]-1./6) > 1e-9 || math.Abs(small.Centroid[0]-100-1./3) > 1e-9 {
+		t.Errorf("Wrong centroids: %v and %v", big.Centroid, small.Centroid)
+	}
+	if big.Best != -1 || small.Best != 200 {
+		t.Errorf("Wrong best fitnesses: %f and %f", big.Best, small.Best)
+	}
+	if big.Spread > 2 || small.Spread > 2 {
+		t.Errorf("Spreads are too large: %f and %f", big.Spread, small.Spread)
+	}
+}
+
+func TestClusterIndividualsKMedoids(t *testing.T) {
+	var metric = func(a, b Individual) float64 {
+		return euclidean(a.Genome.(Vector), b.Genome.(Vector))
+	}
+	var report, err = ClusterIndividuals(twoBlobs(), ClusterOptions{K: 2, Metric: metric})
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(report.Clusters) != 2 || report.Clusters[0].Size != 6 || report.Clusters[1].Size != 3 {
+		t.Fatalf("Wrong clusters: %v", report.Clusters)
+	}
+	if report.Clusters[0].Medoid != "a" || report.Clusters[1].Medoid != "g" {
+		t.Errorf("Wrong medoids: %s and %s", report.Clusters[0].Medoid, report.Clusters[1].Medoid)
+	}
+}
+
+func TestClusterIndividualsCollapsed(t *testing.T) {
+	var indis = make(Individuals, 5)
+	for i := range indis {
+		indis[i] = Individual{Genome: Vector{3, 3}, Fitness: 6}
+	}
+	var report, err = ClusterIndividuals(indis, ClusterOptions{K: 3})
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(report.Clusters) != 1 || report.Clusters[0].Size != 5 || report.Clusters[0].Spread != 0 {
+		t.Errorf("Expected a single cluster, got %v", report.Clusters)
+	}
+}
+
+func TestClusterIndividualsErrors(t *testing.T) {
+	var rng = newRand()
+	if _, err := ClusterIndividuals(twoBlobs(), ClusterOptions{}); err == nil {
+		t.Error("Expected an error")
+	}
+	var indis = Individuals{NewIndividual(NewErrorGenome(rng), rng)}
+	if _, err := ClusterIndividuals(indis, ClusterOptions{K: 2}); err == nil {
+		t.Error("Expected an error")
+	}
+	var ragged = Individuals{{Genome: Vector{1}}, {Genome: Vector{1, 2}}}
+	if _, err := ClusterIndividuals(ragged, ClusterOptions{K: 2}); err == nil {
+		t.Error("Expected an error")
+	}
+	var vectorize = func(g Genome) []float64 { return []float64{float64(len(g.(Vector)))} }
+	if _, err := ClusterIndividuals(ragged, ClusterOptions{K: 2, Vectorize: vectorize}); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
+
+func TestGAStatsClusters(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 4
+	conf.Clustering = &ClusterOptions{K: 3, Every: 2}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var reported []uint
+	ga.Callback = func(ga *GA) {
+		var stats = ga.Stats()
+		if stats.Clusters == nil {
+			return
+		}
+		reported = append(reported, stats.Clusters.Generation)
+		var total int
+		for _, c := range stats.Clusters.Clusters {
+			total += c.Size
+		}
+		if total != int(conf.NPops*conf.PopSize) {
+			t.Errorf("Expected %d clustered individuals, got %d", conf.NPops*conf.PopSize, total)
+		}
+		var b, err = json.Marshal(stats)
+		if err != nil || !strings.Contains(string(b), `"centroid"`) {
+			t.Errorf("Clusters weren't encoded: %v %s", err, b)
+		}
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(reported) != 3 || reported[0] != 0 || reported[1] != 2 || reported[2] != 4 {
+		t.Errorf("Expected reports at generations 0, 2 and 4, got %v", reported)
+	}
+	conf.Clustering = &ClusterOptions{}
+	if _, err = conf.NewGA(); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,340 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// A cmaesPoint is a point sampled from the search distribution of a CMAES.
+type cmaesPoint struct {
+	x   []float64
+	cma *CMAES
+}
+
+// Evaluate the point by computing the value of the function at its position.
+func (p *cmaesPoint) Evaluate() (float64, error) { return p.cma.F(p.x), nil }
+
+// Mutate samples a new position from the search distribution.
+func (p *cmaesPoint) Mutate(rng *rand.Rand) { p.cma.sample(p.x, rng) }
+
+// Crossover doesn't do anything.
+func (p *cmaesPoint) Crossover(q Genome, rng *rand.Rand) {}
+
+// Clone returns a deep copy of the point.
+func (p cmaesPoint) Clone() Genome {
+	return &cmaesPoint{x: copyFloat64s(p.x), cma: p.cma}
+}
+
+// CMAES implements the covariance matrix adaptation evolution strategy. At each
+// generation the population is sampled from a multivariate normal
+// distribution whose mean, step size Sigma and covariance matrix are adapted
+// from the best half of the previous population.
+// Reference: https://arxiv.org/abs/1604.00772
+type CMAES struct {
+	Min, Max float64 // Boundaries for the initial mean
+	Sigma    float64 // Step size, initialized to 0.3 * (Max - Min)
+	Mean     []float64
+	NDims    uint
+	F        func([]float64) float64
+	GA       *GA
+
+	// Strategy parameters
+	weights                       []float64
+	mueff, cc, cs, c1, cmu, damps float64
+	chiN                          float64
+	// Evolution paths, covariance matrix C = B diag(D^2) B^T and C^-1/2
+	pc, ps      []float64
+	c, b        [][]float64
+	d           []float64
+	invSqrtC    [][]float64
+	generations int
+}
+
+// NewCMAES instantiates and returns a CMAES instance after having checked for
+// input errors. popSize is the number of points sampled at each generation.
+func NewCMAES(popSize, nSteps uint, min, max float64, parallel bool, rng *rand.Rand) (*CMAES, error) {
+	// Check inputs
+	if popSize < 4 {
+		return nil, errors.New("popSize should be at least 4")
+	}
+	if min >= max {
+		return nil, errors.New("min should be stricly inferior to max")
+	}
+	if rng == nil {
+		rng = newRand()
+	}
+	var cma = &CMAES{Min: min, Max: max}
+	// Instantiate a GA
+	var ga, err = GAConfig{
+		NPops:        1,
+		PopSize:      popSize,
+		NGenerations: nSteps,
+		HofSize:      1,
+		Model:        cmaesModel{cma},
+		ParallelEval: parallel,
+		RNG:          rand.New(rand.NewSource(rng.Int63())),
+	}.NewGA()
+	if err != nil {
+		return nil, err
+	}
+	cma.GA = ga
+	return cma, nil
+}
+
+// NewDefaultCMAES calls NewCMAES with default values.
+func NewDefaultCMAES() (*CMAES, error) {
+	return NewCMAES(20, 30, -5, 5, false, nil)
+}
+
+// init sets the strategy parameters and the initial search distribution.
+func (cma *CMAES) init(rng *rand.Rand) {
+	var (
+		n      = float64(cma.NDims)
+		lambda = int(cma.GA.PopSize)
+		mu     = lambda / 2
+	)
+	cma.weights = RankWeights(mu)
+	cma.mueff = 0
+	for _, w := range cma.weights {
+		cma.mueff += w * w
+	}
+	cma.mueff = 1 / cma.mueff
+	cma.cc = (4 + cma.mueff/n) / (n + 4 + 2*cma.mueff/n)
+	cma.cs = (cma.mueff + 2) / (n + cma.mueff + 5)
+	cma.c1 = 2 / ((n+1.3)*(n+1.3) + cma.mueff)
+	cma.cmu = math.Min(1-cma.c1, 2*(cma.mueff-2+1/cma.mueff)/((n+2)*(n+2)+cma.mueff))
+	cma.damps = 1 + 2*math.Max(0, math.Sqrt((cma.mueff-1)/(n+1))-1) + cma.cs
+	cma.chiN = math.Sqrt(n) * (1 - 1/(4*n) + 1/(21*n*n))
+	cma.Mean = InitUnifFloat64(cma.NDims, cma.Min, cma.Max, rng)
+	cma.Sigma = 0.3 * (cma.Max - cma.Min)
+	cma.pc = make([]float64, cma.NDims)
+	cma.ps = make([]float64, cma.NDims)
+	cma.c = identity(int(cma.NDims))
+	cma.b = identity(int(cma.NDims))
+	cma.invSqrtC = identity(int(cma.NDims))
+	cma.d = make([]float64, cma.NDims)
+	for i := range cma.d {
+		cma.d[i] = 1
+	}
+	cma.generations = 0
+}
+
+// newPoint returns a point sampled from the search distribution.
+func (cma *CMAES) newPoint(rng *rand.Rand) Genome {
+	var p = &cmaesPoint{x: make([]float64, cma.NDims), cma: cma}
+	p.Mutate(rng)
+	return p
+}
+
+// sample writes Mean + Sigma * B * D * z into x where z is a standard normal
+// vector.
+func (cma *CMAES) sample(x []float64, rng *rand.Rand) {
+	var z = make([]float64, len(x))
+	for i := range z {
+		z[i] = cma.d[i] * rng.NormFloat64()
+	}
+	for i := range x {
+		x[i] = cma.Mean[i]
+		for j, zj := range z {
+			x[i] += cma.Sigma * cma.b[i][j] * zj
+		}
+	}
+}
+
+// update adapts the search distribution to indis, which are sorted by
+// increasing fitness.
+func (cma *CMAES) update(indis Individuals) {
+	var (
+		n     = int(cma.NDims)
+		mu    = len(cma.weights)
+		old   = copyFloat64s(cma.Mean)
+		steps = make([][]float64, mu)
+	)
+	cma.generations++
+	// Move the mean towards the best points
+	for i := range cma.Mean {
+		cma.Mean[i] = 0
+	}
+	for k := 0; k < mu; k++ {
+		var x = indis[k].Genome.(*cmaesPoint).x
+		steps[k] = make([]float64, n)
+		for i := range x {
+			cma.Mean[i] += cma.weights[k] * x[i]
+			steps[k][i] = (x[i] - old[i]) / cma.Sigma
+		}
+	}
+	var y = make([]float64, n)
+	for i := range y {
+		y[i] = (cma.Mean[i] - old[i]) / cma.Sigma
+	}
+	// Update the evolution paths
+	var (
+		csn   = math.Sqrt(cma.cs * (2 - cma.cs) * cma.mueff)
+		psLen float64
+	)
+	for i := range cma.ps {
+		var z float64
+		for j := range y {
+			z += cma.invSqrtC[i][j] * y[j]
+		}
+		cma.ps[i] = (1-cma.cs)*cma.ps[i] + csn*z
+		psLen += cma.ps[i] * cma.ps[i]
+	}
+	psLen = math.Sqrt(psLen)
+	var hsig = 0.0
+	if psLen/math.Sqrt(1-math.Pow(1-cma.cs, 2*float64(cma.generations)))/cma.chiN < 1.4+2/float64(n+1) {
+		hsig = 1
+	}
+	var ccn = math.Sqrt(cma.cc * (2 - cma.cc) * cma.mueff)
+	for i := range cma.pc {
+		cma.pc[i] = (1-cma.cc)*cma.pc[i] + hsig*ccn*y[i]
+	}
+	// Adapt the covariance matrix with the rank-one and the rank-mu updates
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			var rankMu float64
+			for k, step := range steps {
+				rankMu += cma.weights[k] * step[i] * step[j]
+			}
+			var cij = (1-cma.c1-cma.cmu)*cma.c[i][j] +
+				cma.c1*(cma.pc[i]*cma.pc[j]+(1-hsig)*cma.cc*(2-cma.cc)*cma.c[i][j]) +
+				cma.cmu*rankMu
+			cma.c[i][j], cma.c[j][i] = cij, cij
+		}
+	}
+	// Adapt the step size
+	cma.Sigma *= math.Exp((cma.cs / cma.damps) * (psLen/cma.chiN - 1))
+	// Decompose C = B diag(D^2) B^T
+	var values, vectors = symmetricEigen(cma.c)
+	for i, v := range values {
+		cma.d[i] = math.Sqrt(math.Max(v, 1e-20))
+	}
+	cma.b = vectors
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			var s float64
+			for k := 0; k < n; k++ {
+				s += cma.b[i][k] * cma.b[j][k] / cma.d[k]
+			}
+			cma.invSqrtC[i][j] = s
+		}
+	}
+}
+
+// Minimize finds the minimum of a given real-valued function.
+func (cma *CMAES) Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error) {
+	// Set the function to minimize so that the points can access it
+	cma.F = f
+	cma.NDims = nDims
+	cma.init(cma.GA.RNG)
+	// Run the genetic algorithm
+	var err = cma.GA.Minimize(cma.newPoint)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Return the best obtained vector along with the associated function value
+	var best = cma.GA.HallOfFame[0]
+	return best.Genome.(*cmaesPoint).x, best.Fitness, nil
+}
+
+// Config returns the configuration of the underlying GA.
+func (cma *CMAES) Config() *GAConfig {
+	return &cma.GA.GAConfig
+}
+
+// cmaesModel updates the search distribution of a CMAES and samples a new
+// population from it.
+type cmaesModel struct {
+	cma *CMAES
+}
+
+// Apply cmaesModel.
+func (mod cmaesModel) Apply(pop *Population) error {
+	var indis = pop.Individuals.Clone(pop.RNG)
+	sort.Slice(indis, func(i, j int) bool { return indis[i].Fitness < indis[j].Fitness })
+	mod.cma.update(indis)
+	for i := range pop.Individuals {
+		pop.Individuals[i].Mutate(pop.RNG)
+	}
+	return nil
+}
+
+// Validate cmaesModel fields.
+func (mod cmaesModel) Validate() error {
+	if mod.cma == nil {
+		return errors.New("the CMAES cannot be nil")
+	}
+	return nil
+}
+
+// identity returns the n by n identity matrix.
+func identity(n int) [][]float64 {
+	var m = make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		m[i][i] = 1
+	}
+	return m
+}
+
+// symmetricEigen returns the eigenvalues of the symmetric matrix a along with
+// the eigenvectors, stored as the columns of the second matrix. It uses the
+// cyclic Jacobi method, which is accurate and simple for the small matrices
+// CMAES deals with. a isn't modified.
+func symmetricEigen(a [][]float64) ([]float64, [][]float64) {
+	var (
+		n = len(a)
+		m = make([][]float64, n)
+		v = identity(n)
+	)
+	for i := range a {
+		m[i] = copyFloat64s(a[i])
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		var off float64
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += m[i][j] * m[i][j]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				// Rotate rows and columns p and q to cancel m[p][q]
+				var (
+					theta = (m[q][q] - m[p][p]) / (2 * m[p][q])
+					t     = math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+					c     = 1 / math.Sqrt(t*t+1)
+					s     = t * c
+				)
+				for k := 0; k < n; k++ {
+					var mkp, mkq = m[k][p], m[k][q]
+					m[k][p] = c*mkp - s*mkq
+					m[k][q] = s*mkp + c*mkq
+				}
+				for k := 0; k < n; k++ {
+					var mpk, mqk = m[p][k], m[q][k]
+					m[p][k] = c*mpk - s*mqk
+					m[q][k] = s*mpk + c*mqk
+				}
+				for k := 0; k < n; k++ {
+					var vkp, vkq = v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	var values = make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	return values, v
+}
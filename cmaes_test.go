@@ -0,0 +1,72 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSymmetricEigen(t *testing.T) {
+	var (
+		rng = newRand()
+		n   = 5
+		a   = make([][]float64, n)
+	)
+	for i := range a {
+		a[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			a[i][j] = rng.NormFloat64()
+			a[j][i] = a[i][j]
+		}
+	}
+	var values, vectors = symmetricEigen(a)
+	// A = V diag(values) V^T
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			var s float64
+			for k := 0; k < n; k++ {
+				s += vectors[i][k] * values[k] * vectors[j][k]
+			}
+			if math.Abs(s-a[i][j]) > 1e-9 {
+				t.Fatalf("Expected %f at (%d, %d), got %f", a[i][j], i, j, s)
+			}
+		}
+	}
+}
+
+func TestCMAESMinimize(t *testing.T) {
+	var cma, err = NewCMAES(12, 150, -5, 5, false, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An ill-conditioned ellipsoid, which requires adapting the covariance
+	var ellipsoid = func(x []float64) (y float64) {
+		for i, xi := range x {
+			y += math.Pow(1000, float64(i)/float64(len(x)-1)) * (xi - 1) * (xi - 1)
+		}
+		return
+	}
+	x, y, err := cma.Minimize(ellipsoid, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y > 1e-6 || y != ellipsoid(x) {
+		t.Errorf("Expected a minimum close to 0, got %g at %v", y, x)
+	}
+	if cma.Sigma <= 0 || cma.Sigma >= 3 {
+		t.Errorf("The step size should have decreased, got %f", cma.Sigma)
+	}
+}
+
+func TestNewCMAESErrors(t *testing.T) {
+	if _, err := NewCMAES(3, 10, -5, 5, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewCMAES(10, 10, 5, -5, false, nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if err := (cmaesModel{}).Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+}
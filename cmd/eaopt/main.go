@@ -0,0 +1,131 @@
+// Command eaopt runs optimizers from a JSON or YAML run specification, which
+// makes it easy to run reproducible experiments and to compare operators.
+//
+// Usage:
+//
+//	eaopt run spec.json      run a spec and write the statistics as CSV
+//	eaopt run spec.yaml      same with a YAML spec, which has the same fields
+//	eaopt benchmarks         list the built-in benchmark functions
+//
+// A spec looks as follows, every field apart from benchmark (or plugin) is
+// optional:
+//
+//	{
+//	  "optimizer": "ga",
+//	  "benchmark": "rastrigin",
+//	  "dims": 10, "min": -5.12, "max": 5.12,
+//	  "seed": 42,
+//	  "budget": {"n_pops": 2, "pop_size": 50, "n_generations": 200},
+//	  "model": {
+//	    "type": "generational",
+//	    "selector": {"type": "tournament", "n_contestants": 3},
+//	    "mut_rate": 0.5, "cross_rate": 0.7
+//	  },
+//	  "mutation": {"type": "normal", "rate": 0.8},
+//	  "crossover": {"type": "uniform"},
+//	  "output": "stats.csv"
+//	}
+//
+// Instead of a benchmark, a Go plugin exporting a
+// "func Objective(x []float64) float64" can be provided with "plugin".
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/matthewmcneely/eaopt"
+)
+
+const usage = `usage:
+  eaopt run <spec.json>   run a spec and write the statistics as CSV
+  eaopt run <spec.yaml>   same with a YAML spec
+  eaopt benchmarks        list the built-in benchmark functions
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "run":
+		if len(os.Args) != 3 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = run(os.Args[2], os.Stdout)
+	case "benchmarks":
+		var names []string
+		for name := range eaopt.Benchmarks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "eaopt:", err)
+		os.Exit(1)
+	}
+}
+
+// csvHeader lists the columns of the statistics file.
+var csvHeader = []string{"generation", "pop_id", "min", "max", "avg", "std", "best"}
+
+// run executes a spec file and writes one CSV row per population and per
+// generation. The best solution is reported on stderr once the run is over.
+func run(path string, stdout io.Writer) error {
+	var spec, err = loadSpec(path)
+	if err != nil {
+		return err
+	}
+	var out = stdout
+	if spec.Output != "-" {
+		var f, err = os.Create(spec.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	var w = csv.NewWriter(out)
+	w.Write(csvHeader)
+	x, y, err := spec.run(func(ga *eaopt.GA) {
+		writeStats(w, ga.Stats())
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	if err = w.Error(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "best: %v, value: %g\n", x, y)
+	return nil
+}
+
+// writeStats writes the rows of a generation.
+func writeStats(w *csv.Writer, stats eaopt.GenerationStats) {
+	var format = func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+	for _, pop := range stats.Populations {
+		w.Write([]string{
+			strconv.FormatUint(uint64(stats.Generation), 10),
+			pop.ID,
+			format(pop.Min),
+			format(pop.Max),
+			format(pop.Avg),
+			format(pop.Std),
+			format(stats.Best),
+		})
+	}
+}
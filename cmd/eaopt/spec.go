@@ -0,0 +1,531 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/matthewmcneely/eaopt"
+	"gopkg.in/yaml.v3"
+)
+
+// A Spec describes a reproducible run. It is read from a JSON or YAML file.
+type Spec struct {
+	Optimizer string        `json:"optimizer"`  // ga, pso, de, cmaes, oes, bayes, random or lhs
+	Benchmark string        `json:"benchmark"`  // Name of a built-in benchmark function
+	Plugin    string        `json:"plugin"`     // Path to a Go plugin exporting an Objective function
+	Command   []string      `json:"command"`    // Program implementing the eaopt subprocess protocol, with its arguments
+	BatchSize uint          `json:"batch_size"` // Number of inputs sent at once to the command
+	Dims      uint          `json:"dims"`
+	Min       float64       `json:"min"`
+	Max       float64       `json:"max"`
+	Seed      int64         `json:"seed"`
+	Budget    BudgetSpec    `json:"budget"`
+	Model     ModelSpec     `json:"model"`
+	Mutation  MutationSpec  `json:"mutation"`
+	Crossover CrossoverSpec `json:"crossover"`
+	Init      string        `json:"init"` // Initialization of the ga optimizer: uniform, lhs or sobol
+	Params    ParamsSpec    `json:"params"`
+	Output    string        `json:"output"` // CSV file where the statistics are written, "-" for stdout
+}
+
+// BudgetSpec contains the size of the run.
+type BudgetSpec struct {
+	NPops          uint   `json:"n_pops"`
+	PopSize        uint   `json:"pop_size"`
+	NGenerations   uint   `json:"n_generations"`
+	MaxEvaluations uint64 `json:"max_evaluations"` // 0 means no limit
+}
+
+// SelectorSpec describes a selection operator.
+type SelectorSpec struct {
+	Type         string          `json:"type"` // tournament, roulette or elitism
+	NContestants uint            `json:"n_contestants"`
+	Operator     json.RawMessage `json:"operator"` // Any eaopt Selector, see eaopt.UnmarshalOperator; overrides the other fields
+}
+
+// ModelSpec describes a GA model.
+type ModelSpec struct {
+	Type        string       `json:"type"` // generational, steady_state, down_to_size, ring or mutation_only
+	Selector    SelectorSpec `json:"selector"`
+	SelectorB   SelectorSpec `json:"selector_b"`
+	MutRate     float64      `json:"mut_rate"`
+	CrossRate   float64      `json:"cross_rate"`
+	KeepBest    bool         `json:"keep_best"`
+	NOffsprings uint         `json:"n_offsprings"`
+	Strict      bool         `json:"strict"`
+	// Any eaopt Model, see eaopt.UnmarshalOperator; overrides the other fields
+	Operator json.RawMessage `json:"operator"`
+}
+
+// MutationSpec describes how vectors are mutated by the ga optimizer.
+type MutationSpec struct {
+	Type string  `json:"type"` // normal
+	Rate float64 `json:"rate"`
+}
+
+// CrossoverSpec describes how vectors are crossed over by the ga optimizer.
+type CrossoverSpec struct {
+	Type    string `json:"type"` // uniform or gnx
+	NPoints uint   `json:"n_points"`
+}
+
+// ParamsSpec contains the parameters of the pso, de and oes optimizers.
+type ParamsSpec struct {
+	W            float64 `json:"w"`
+	CRate        float64 `json:"c_rate"`
+	DWeight      float64 `json:"d_weight"`
+	Sigma        float64 `json:"sigma"`
+	LearningRate float64 `json:"learning_rate"`
+}
+
+// defaultSpec returns the values used for the fields that a spec file omits.
+func defaultSpec() Spec {
+	return Spec{
+		Optimizer: "ga",
+		Dims:      2,
+		Min:       -5,
+		Max:       5,
+		Budget:    BudgetSpec{NPops: 1, PopSize: 30, NGenerations: 50},
+		Model: ModelSpec{
+			Type:      "generational",
+			Selector:  SelectorSpec{Type: "tournament", NContestants: 3},
+			MutRate:   0.5,
+			CrossRate: 0.7,
+		},
+		Mutation:  MutationSpec{Type: "normal", Rate: 0.8},
+		Crossover: CrossoverSpec{Type: "uniform"},
+		Init:      "uniform",
+		Params: ParamsSpec{
+			W:            0.5,
+			CRate:        0.5,
+			DWeight:      0.2,
+			Sigma:        1,
+			LearningRate: 0.1,
+		},
+		BatchSize: 1,
+		Output:    "-",
+	}
+}
+
+// readSpec decodes a JSON spec on top of the default values. Unknown fields
+// are rejected so that typos don't go unnoticed.
+func readSpec(r io.Reader) (Spec, error) {
+	var (
+		spec = defaultSpec()
+		dec  = json.NewDecoder(r)
+	)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return spec, err
+	}
+	return spec, spec.Validate()
+}
+
+// readYAMLSpec decodes a YAML spec. The document is converted to JSON so that
+// it uses the same field names and goes through the same checks as a JSON
+// spec.
+func readYAMLSpec(r io.Reader) (Spec, error) {
+	var doc interface{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return defaultSpec(), err
+	}
+	var b, err = json.Marshal(doc)
+	if err != nil {
+		return defaultSpec(), fmt.Errorf("YAML spec can't be converted to JSON: %w", err)
+	}
+	return readSpec(bytes.NewReader(b))
+}
+
+// loadSpec reads a spec file, which is decoded as YAML if its extension is
+// .yaml or .yml and as JSON otherwise.
+func loadSpec(path string) (Spec, error) {
+	var f, err = os.Open(path)
+	if err != nil {
+		return Spec{}, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return readYAMLSpec(f)
+	}
+	return readSpec(f)
+}
+
+// Validate checks the fields which are not checked by the optimizers
+// themselves.
+func (spec Spec) Validate() error {
+	var n int
+	for _, set := range []bool{spec.Benchmark != "", spec.Plugin != "", len(spec.Command) > 0} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of benchmark, plugin and command has to be provided")
+	}
+	if spec.Benchmark != "" {
+		if _, ok := eaopt.Benchmarks[spec.Benchmark]; !ok {
+			return fmt.Errorf("unknown benchmark %q", spec.Benchmark)
+		}
+	}
+	if spec.Dims == 0 {
+		return errors.New("dims has to be strictly higher than 0")
+	}
+	if spec.Min >= spec.Max {
+		return errors.New("min should be strictly inferior to max")
+	}
+	return nil
+}
+
+// objective returns the function to minimize along with a function that
+// releases the resources it uses.
+func (spec Spec) objective() (func([]float64) (float64, error), func() error, error) {
+	var noop = func() error { return nil }
+	if spec.Benchmark != "" {
+		return pure(eaopt.Benchmarks[spec.Benchmark]), noop, nil
+	}
+	if len(spec.Command) > 0 {
+		var se, err = eaopt.NewSubprocessEvaluator(exec.Command(spec.Command[0], spec.Command[1:]...),
+			spec.BatchSize, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(x []float64) (float64, error) { return se.Evaluate(x) }, se.Close, nil
+	}
+	var p, err = plugin.Open(spec.Plugin)
+	if err != nil {
+		return nil, nil, err
+	}
+	sym, err := p.Lookup("Objective")
+	if err != nil {
+		return nil, nil, err
+	}
+	switch f := sym.(type) {
+	case func([]float64) float64:
+		return pure(f), noop, nil
+	case *func([]float64) float64:
+		return pure(*f), noop, nil
+	}
+	return nil, nil, errors.New("plugin symbol Objective should be a func([]float64) float64")
+}
+
+// pure wraps a function that can't fail.
+func pure(f func([]float64) float64) func([]float64) (float64, error) {
+	return func(x []float64) (float64, error) { return f(x), nil }
+}
+
+// fallible adapts an objective that can fail to the optimizers which don't
+// handle errors. Failed evaluations are given the largest finite value, so that
+// the optimizers still keep track of a best solution, and the first error stops
+// the run.
+type fallible struct {
+	f     func([]float64) (float64, error)
+	mutex sync.Mutex
+	err   error
+}
+
+func (fa *fallible) eval(x []float64) float64 {
+	var y, err = fa.f(x)
+	if err != nil {
+		fa.mutex.Lock()
+		if fa.err == nil {
+			fa.err = err
+		}
+		fa.mutex.Unlock()
+		return math.MaxFloat64
+	}
+	return y
+}
+
+// minimize runs an optimizer whose GA is ga and returns the first evaluation
+// error, if any.
+func (fa *fallible) minimize(ga *eaopt.GA,
+	minimize func(f func([]float64) float64) ([]float64, float64, error)) ([]float64, float64, error) {
+	ga.EarlyStop = func(ga *eaopt.GA) bool {
+		fa.mutex.Lock()
+		defer fa.mutex.Unlock()
+		return fa.err != nil
+	}
+	var x, y, err = minimize(fa.eval)
+	if err == nil {
+		err = fa.err
+	}
+	return x, y, err
+}
+
+// selector builds a Selector from its spec.
+func (s SelectorSpec) selector() (eaopt.Selector, error) {
+	if s.Operator != nil {
+		return eaopt.UnmarshalOperator[eaopt.Selector](s.Operator)
+	}
+	switch s.Type {
+	case "tournament":
+		return eaopt.SelTournament{NContestants: s.NContestants}, nil
+	case "roulette":
+		return eaopt.SelRoulette{}, nil
+	case "elitism":
+		return eaopt.SelElitism{}, nil
+	}
+	return nil, fmt.Errorf("unknown selector %q", s.Type)
+}
+
+// model builds a Model from its spec.
+func (m ModelSpec) model() (eaopt.Model, error) {
+	if m.Operator != nil {
+		return eaopt.UnmarshalOperator[eaopt.Model](m.Operator)
+	}
+	switch m.Type {
+	case "mutation_only":
+		return eaopt.ModMutationOnly{Strict: m.Strict}, nil
+	}
+	var sel, err = m.Selector.selector()
+	if err != nil {
+		return nil, err
+	}
+	switch m.Type {
+	case "generational":
+		return eaopt.ModGenerational{Selector: sel, MutRate: m.MutRate, CrossRate: m.CrossRate}, nil
+	case "steady_state":
+		return eaopt.ModSteadyState{Selector: sel, KeepBest: m.KeepBest, MutRate: m.MutRate, CrossRate: m.CrossRate}, nil
+	case "ring":
+		return eaopt.ModRing{Selector: sel, MutRate: m.MutRate}, nil
+	case "down_to_size":
+		var selB, err = m.SelectorB.selector()
+		if err != nil {
+			return nil, err
+		}
+		return eaopt.ModDownToSize{
+			NOffsprings: m.NOffsprings,
+			SelectorA:   sel,
+			SelectorB:   selB,
+			MutRate:     m.MutRate,
+			CrossRate:   m.CrossRate,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown model %q", m.Type)
+}
+
+// validate checks the mutation's type and rate.
+func (m MutationSpec) validate() error {
+	if m.Type != "normal" {
+		return fmt.Errorf("unknown mutation %q", m.Type)
+	}
+	if m.Rate < 0 || m.Rate > 1 {
+		return errors.New("the mutation rate should be in [0, 1]")
+	}
+	return nil
+}
+
+// validate checks the crossover's type and, for vectors of dims values, its
+// number of points.
+func (c CrossoverSpec) validate(dims uint) error {
+	switch c.Type {
+	case "uniform":
+	case "gnx":
+		if c.NPoints == 0 || c.NPoints >= dims {
+			return errors.New("n_points should be between 1 and dims - 1")
+		}
+	default:
+		return fmt.Errorf("unknown crossover %q", c.Type)
+	}
+	return nil
+}
+
+// vector is the genome used by the ga optimizer.
+type vector struct {
+	x    []float64
+	f    func([]float64) (float64, error)
+	spec *Spec
+}
+
+func (v *vector) Evaluate() (float64, error) { return v.f(v.x) }
+
+func (v *vector) Mutate(rng *rand.Rand) {
+	eaopt.MutNormalFloat64(v.x, v.spec.Mutation.Rate, rng)
+}
+
+func (v *vector) Crossover(q eaopt.Genome, rng *rand.Rand) {
+	var w = q.(*vector)
+	switch v.spec.Crossover.Type {
+	case "gnx":
+		eaopt.CrossGNXFloat64(v.x, w.x, v.spec.Crossover.NPoints, rng)
+	default:
+		eaopt.CrossUniformFloat64(v.x, w.x, rng)
+	}
+}
+
+func (v *vector) Clone() eaopt.Genome {
+	return &vector{x: append([]float64{}, v.x...), f: v.f, spec: v.spec}
+}
+
+// MarshalJSON encodes the vector's values.
+func (v *vector) MarshalJSON() ([]byte, error) { return json.Marshal(v.x) }
+
+// run executes the spec and calls record with the GA at each generation. It
+// returns the best solution found along with its value.
+func (spec Spec) run(record func(ga *eaopt.GA)) (x []float64, y float64, err error) {
+	f, release, err := spec.objective()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if e := release(); err == nil {
+			err = e
+		}
+	}()
+	x, y, err = spec.optimize(f, record)
+	return
+}
+
+// optimize minimizes f with the spec's optimizer.
+func (spec Spec) optimize(f func([]float64) (float64, error), record func(ga *eaopt.GA)) ([]float64, float64, error) {
+	var rng = rand.New(rand.NewSource(spec.Seed))
+	switch spec.Optimizer {
+	case "ga":
+		if err := spec.Crossover.validate(spec.Dims); err != nil {
+			return nil, 0, err
+		}
+		if err := spec.Mutation.validate(); err != nil {
+			return nil, 0, err
+		}
+		var init, ok = map[string]eaopt.FloatInitializer{
+			"uniform": eaopt.InitUnifPoints,
+			"lhs":     eaopt.InitLatinHypercube,
+			"sobol":   eaopt.InitSobol,
+		}[spec.Init]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown init %q", spec.Init)
+		}
+		model, err := spec.Model.model()
+		if err != nil {
+			return nil, 0, err
+		}
+		ga, err := eaopt.GAConfig{
+			NPops:        spec.Budget.NPops,
+			PopSize:      spec.Budget.PopSize,
+			NGenerations: spec.Budget.NGenerations,
+			HofSize:      1,
+			Model:        model,
+			ParallelEval: spec.BatchSize > 1, // Gives the command batches to evaluate
+			Callback:     record,
+			RNG:          rng,
+
+			MaxEvaluations:   spec.Budget.MaxEvaluations,
+			PopulationIDFunc: eaopt.SequentialPopulationID,
+		}.NewGA()
+		if err != nil {
+			return nil, 0, err
+		}
+		// Each population is initialized with a batch of pop_size vectors
+		var (
+			lower, upper = make([]float64, spec.Dims), make([]float64, spec.Dims)
+			batch        [][]float64
+		)
+		for i := range lower {
+			lower[i], upper[i] = spec.Min, spec.Max
+		}
+		err = ga.Minimize(func(rng *rand.Rand) eaopt.Genome {
+			if len(batch) == 0 {
+				batch = init(spec.Budget.PopSize, lower, upper, rng)
+			}
+			var x = batch[0]
+			batch = batch[1:]
+			return &vector{x: x, f: f, spec: &spec}
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		return ga.HallOfFame[0].Genome.(*vector).x, ga.HallOfFame[0].Fitness, nil
+	case "pso":
+		pso, err := eaopt.NewSPSO(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Min, spec.Max,
+			spec.Params.W, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		pso.GA.Callback = record
+		pso.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		return (&fallible{f: f}).minimize(pso.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return pso.Minimize(g, spec.Dims)
+		})
+	case "de":
+		de, err := eaopt.NewDiffEvo(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Min, spec.Max,
+			spec.Params.CRate, spec.Params.DWeight, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		de.GA.Callback = record
+		de.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		return (&fallible{f: f}).minimize(de.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return de.Minimize(g, spec.Dims)
+		})
+	case "cmaes":
+		cma, err := eaopt.NewCMAES(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Min, spec.Max, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		cma.GA.Callback = record
+		cma.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		return (&fallible{f: f}).minimize(cma.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return cma.Minimize(g, spec.Dims)
+		})
+	case "random":
+		rs, err := eaopt.NewRandomSearch(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Min, spec.Max, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		rs.GA.Callback = record
+		rs.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		return (&fallible{f: f}).minimize(rs.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return rs.Minimize(g, spec.Dims)
+		})
+	case "lhs":
+		lhs, err := eaopt.NewLatinHypercube(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Min, spec.Max, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		lhs.GA.Callback = record
+		lhs.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		return (&fallible{f: f}).minimize(lhs.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return lhs.Minimize(g, spec.Dims)
+		})
+	case "bayes":
+		bo, err := eaopt.NewBayesOpt(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Min, spec.Max,
+			eaopt.KernelMatern52, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		bo.GA.Callback = record
+		bo.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		return (&fallible{f: f}).minimize(bo.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return bo.Minimize(g, spec.Dims)
+		})
+	case "oes":
+		oes, err := eaopt.NewOES(spec.Budget.PopSize, spec.Budget.NGenerations, spec.Params.Sigma,
+			spec.Params.LearningRate, false, rng)
+		if err != nil {
+			return nil, 0, err
+		}
+		oes.GA.MaxEvaluations = spec.Budget.MaxEvaluations
+		var update = oes.GA.Callback
+		oes.GA.Callback = func(ga *eaopt.GA) {
+			update(ga)
+			record(ga)
+		}
+		var x0 = eaopt.InitUnifFloat64(spec.Dims, spec.Min, spec.Max, rng)
+		return (&fallible{f: f}).minimize(oes.GA, func(g func([]float64) float64) ([]float64, float64, error) {
+			return oes.Minimize(g, x0)
+		})
+	}
+	return nil, 0, fmt.Errorf("unknown optimizer %q", spec.Optimizer)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matthewmcneely/eaopt"
+)
+
+func TestReadSpec(t *testing.T) {
+	var testCases = []struct {
+		spec string
+		err  bool
+	}{
+		{`{"benchmark": "sphere"}`, false},
+		{`{"benchmark": "sphere", "dims": 5, "min": -1, "max": 1}`, false},
+		{`{}`, true},
+		{`{"benchmark": "nope"}`, true},
+		{`{"benchmark": "sphere", "plugin": "f.so"}`, true},
+		{`{"benchmark": "sphere", "command": ["python3", "f.py"]}`, true},
+		{`{"command": ["python3", "f.py"]}`, false},
+		{`{"benchmark": "sphere", "dims": 0}`, true},
+		{`{"benchmark": "sphere", "min": 1, "max": 1}`, true},
+		{`{"benchmark": "sphere", "typo": 1}`, true},
+	}
+	for i, tc := range testCases {
+		var _, err = readSpec(strings.NewReader(tc.spec))
+		if (err != nil) != tc.err {
+			t.Errorf("Error in test case number %d: %v", i, err)
+		}
+	}
+}
+
+func TestLoadSpecYAML(t *testing.T) {
+	var testCases = []struct {
+		spec string
+		err  bool
+	}{
+		{"benchmark: sphere", false},
+		{"benchmark: sphere\ndims: 5\nmin: -1\nmax: 1\nmodel: {type: steady_state, keep_best: true}", false},
+		{"", true},
+		{"benchmark: nope", true},
+		{"benchmark: sphere\ntypo: 1", true},
+		{"benchmark: [sphere", true},
+		{"benchmark: sphere\ndims: many", true},
+		{"benchmark: sphere\nparams: {1: 2}", true},
+	}
+	var dir = t.TempDir()
+	for i, tc := range testCases {
+		var path = filepath.Join(dir, fmt.Sprintf("spec%d.yml", i))
+		if err := os.WriteFile(path, []byte(tc.spec), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		var _, err = loadSpec(path)
+		if (err != nil) != tc.err {
+			t.Errorf("Error in test case number %d: %v", i, err)
+		}
+	}
+	// YAML and JSON specs are equivalent
+	var path = filepath.Join(dir, "spec.yaml")
+	os.WriteFile(path, []byte("benchmark: ackley\nseed: 7\nbudget:\n  pop_size: 10\nmodel:\n  selector:\n    type: roulette\n"), 0o600)
+	fromYAML, err := loadSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := readSpec(strings.NewReader(`{"benchmark": "ackley", "seed": 7, "budget": {"pop_size": 10}, "model": {"selector": {"type": "roulette"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("Expected %+v, got %+v", fromJSON, fromYAML)
+	}
+	if _, err = loadSpec(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestSpecRun(t *testing.T) {
+	var testCases = []string{
+		`{"benchmark": "sphere", "budget": {"n_pops": 2, "n_generations": 10}}`,
+		`{"benchmark": "sphere", "crossover": {"type": "gnx", "n_points": 1}}`,
+		`{"benchmark": "sphere", "init": "lhs", "budget": {"n_pops": 2}}`,
+		`{"benchmark": "sphere", "init": "sobol"}`,
+		`{"benchmark": "rastrigin", "model": {"type": "steady_state", "selector": {"type": "roulette"}, "mut_rate": 0.5, "cross_rate": 0.5}}`,
+		`{"benchmark": "sphere", "model": {"type": "down_to_size", "n_offsprings": 10, "selector": {"type": "tournament", "n_contestants": 2}, "selector_b": {"type": "elitism"}, "mut_rate": 0.5, "cross_rate": 0.5}}`,
+		`{"benchmark": "sphere", "model": {"type": "mutation_only", "strict": true}}`,
+		`{"benchmark": "sphere", "model": {"selector": {"operator": {"type": "eaopt.SelBoltzmann", "params": {"Temperature": 2}}}}}`,
+		`{"benchmark": "sphere", "model": {"operator": {"type": "eaopt.ModRing", "params": {"Selector": {"type": "eaopt.SelTournament", "params": {"NContestants": 2}}, "MutRate": 0.5}}}}`,
+		`{"benchmark": "sphere", "optimizer": "pso"}`,
+		`{"benchmark": "sphere", "optimizer": "de"}`,
+		`{"benchmark": "sphere", "optimizer": "oes"}`,
+		`{"benchmark": "sphere", "optimizer": "cmaes"}`,
+		`{"benchmark": "sphere", "optimizer": "random"}`,
+		`{"benchmark": "sphere", "optimizer": "lhs"}`,
+		`{"benchmark": "sphere", "optimizer": "bayes", "budget": {"pop_size": 2, "n_generations": 5}}`,
+	}
+	for i, tc := range testCases {
+		var spec, err = readSpec(strings.NewReader(tc))
+		if err != nil {
+			t.Fatalf("Error in test case number %d: %v", i, err)
+		}
+		var (
+			calls int
+			x     []float64
+			y     float64
+		)
+		x, y, err = spec.run(func(ga *eaopt.GA) { calls++ })
+		if err != nil {
+			t.Errorf("Error in test case number %d: %v", i, err)
+			continue
+		}
+		// The callback is also called once the populations are initialized
+		if calls != int(spec.Budget.NGenerations)+1 {
+			t.Errorf("Expected %d callbacks, got %d", spec.Budget.NGenerations+1, calls)
+		}
+		if uint(len(x)) != spec.Dims {
+			t.Errorf("Expected a solution of length %d, got %d", spec.Dims, len(x))
+		}
+		if y != eaopt.Benchmarks[spec.Benchmark](x) {
+			t.Errorf("Expected %f, got %f", eaopt.Benchmarks[spec.Benchmark](x), y)
+		}
+	}
+}
+
+func TestSpecRunErrors(t *testing.T) {
+	var testCases = []string{
+		`{"benchmark": "sphere", "optimizer": "nope"}`,
+		`{"benchmark": "sphere", "init": "nope"}`,
+		`{"benchmark": "sphere", "model": {"type": "nope"}}`,
+		`{"benchmark": "sphere", "model": {"type": "generational", "selector": {"type": "nope"}}}`,
+		`{"benchmark": "sphere", "crossover": {"type": "nope"}}`,
+		`{"benchmark": "sphere", "crossover": {"type": "gnx", "n_points": 2}}`,
+		`{"benchmark": "sphere", "mutation": {"type": "nope"}}`,
+		`{"benchmark": "sphere", "mutation": {"type": "normal", "rate": 2}}`,
+		`{"benchmark": "sphere", "model": {"operator": {"type": "eaopt.ModRing", "params": {"MutRate": 0.5}}}}`,
+		`{"benchmark": "sphere", "model": {"operator": {"type": "eaopt.SelElitism"}}}`,
+		`{"benchmark": "sphere", "model": {"selector": {"operator": {"type": "eaopt.SelTournament"}}}}`,
+		`{"benchmark": "sphere", "budget": {"pop_size": 0}}`,
+	}
+	for i, tc := range testCases {
+		var spec, err = readSpec(strings.NewReader(tc))
+		if err != nil {
+			t.Fatalf("Error in test case number %d: %v", i, err)
+		}
+		if _, _, err = spec.run(func(ga *eaopt.GA) {}); err == nil {
+			t.Errorf("Expected an error in test case number %d", i)
+		}
+	}
+}
+
+func TestSpecRunReproducible(t *testing.T) {
+	var spec, err = readSpec(strings.NewReader(`{"benchmark": "ackley", "seed": 7, "dims": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _, y1, _ = spec.run(func(ga *eaopt.GA) {})
+	var _, y2, _ = spec.run(func(ga *eaopt.GA) {})
+	if y1 != y2 {
+		t.Errorf("Expected the same result with the same seed, got %f and %f", y1, y2)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "spec.json")
+		spec = `{"benchmark": "sphere", "budget": {"n_pops": 2, "n_generations": 5}}`
+	)
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run(path, &out); err != nil {
+		t.Fatal(err)
+	}
+	var records, err = csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1+2*6 {
+		t.Fatalf("Expected %d rows, got %d", 1+2*6, len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("Wrong header: %v", records[0])
+	}
+}
+
+// TestObjectiveHelper is not a real test, it is run as a command by
+// TestSpecRunCommand and evaluates the sphere function.
+func TestObjectiveHelper(t *testing.T) {
+	var mode = os.Getenv("EAOPT_OBJECTIVE_HELPER")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+	var scanner = bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     uint64      `json:"id"`
+			Inputs [][]float64 `json:"inputs"`
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+		if mode == "fail" {
+			fmt.Printf(`{"id": %d, "error": "simulation failed"}`+"\n", req.ID)
+			continue
+		}
+		var fitnesses = make([]float64, len(req.Inputs))
+		for i, x := range req.Inputs {
+			fitnesses[i] = eaopt.Sphere(x)
+		}
+		json.NewEncoder(os.Stdout).Encode(map[string]interface{}{"id": req.ID, "fitnesses": fitnesses})
+	}
+}
+
+func TestSpecRunCommand(t *testing.T) {
+	var command, _ = json.Marshal([]string{os.Args[0], "-test.run=^TestObjectiveHelper$"})
+	for _, optimizer := range []string{"ga", "pso", "de", "cmaes", "oes", "random", "lhs"} {
+		for _, mode := range []string{"sphere", "fail"} {
+			t.Setenv("EAOPT_OBJECTIVE_HELPER", mode)
+			var spec, err = readSpec(strings.NewReader(fmt.Sprintf(
+				`{"optimizer": %q, "command": %s, "batch_size": 4, "budget": {"n_generations": 5}}`,
+				optimizer, command,
+			)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			x, y, err := spec.run(func(ga *eaopt.GA) {})
+			if mode == "fail" {
+				if err == nil {
+					t.Errorf("Expected an error with optimizer %s", optimizer)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("Error with optimizer %s: %v", optimizer, err)
+				continue
+			}
+			if y != eaopt.Sphere(x) {
+				t.Errorf("Expected %f, got %f", eaopt.Sphere(x), y)
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// DifferentialGrouping decomposes the variables of f into groups of
+// interacting variables. Two variables interact if the change in f caused by
+// moving the first one from min to max depends on the value of the second one
+// by more than epsilon. Each group is optimized as a whole by
+// CoopCoevolution, variables that don't belong to any group are separable.
+// Detecting the groups requires between nDims and nDims * (nDims + 1)
+// evaluations of f; the fewer variables interact, the more evaluations are
+// needed.
+// Reference: https://doi.org/10.1109/TEVC.2013.2281543
+func DifferentialGrouping(f func([]float64) float64, nDims uint, min, max, epsilon float64) Linkage {
+	var (
+		remaining = make([]int, nDims)
+		mid       = (min + max) / 2
+		linkage   Linkage
+	)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	for len(remaining) > 0 {
+		var (
+			i     = remaining[0]
+			group = []int{i}
+			rest  []int
+			p1    = repeatFloat64(min, nDims)
+			p2    = repeatFloat64(min, nDims)
+		)
+		p2[i] = max
+		var delta1 = f(p1) - f(p2)
+		for _, j := range remaining[1:] {
+			p1[j], p2[j] = mid, mid
+			var delta2 = f(p1) - f(p2)
+			p1[j], p2[j] = min, min
+			if math.Abs(delta1-delta2) > epsilon {
+				group = append(group, j)
+			} else {
+				rest = append(rest, j)
+			}
+		}
+		if len(group) > 1 {
+			linkage = append(linkage, group)
+		}
+		remaining = rest
+	}
+	return linkage
+}
+
+// A ccComponent is the part of a solution made of the variables of one group
+// of a CoopCoevolution.
+type ccComponent struct {
+	x     []float64
+	group int
+	cc    *CoopCoevolution
+}
+
+// Evaluate the ccComponent by plugging its values into the context vector.
+func (c *ccComponent) Evaluate() (float64, error) {
+	var x = copyFloat64s(c.cc.Context)
+	for k, i := range c.cc.components[c.group] {
+		x[i] = c.x[k]
+	}
+	return c.cc.F(x), nil
+}
+
+// Mutate the ccComponent with the differential evolution operator applied to
+// the subpopulation of its group.
+func (c *ccComponent) Mutate(rng *rand.Rand) {
+	var (
+		indis = c.cc.GAs[c.group].Populations[0].Individuals
+		idxs  = randomInts(3, 0, len(indis), rng)
+		a     = indis[idxs[0]].Genome.(*ccComponent).x
+		b     = indis[idxs[1]].Genome.(*ccComponent).x
+		d     = indis[idxs[2]].Genome.(*ccComponent).x
+		force = rng.Intn(len(c.x))
+	)
+	for k := range c.x {
+		if k == force || rng.Float64() < c.cc.CRate {
+			c.x[k] = math.Min(math.Max(a[k]+c.cc.DWeight*(b[k]-d[k]), c.cc.Min), c.cc.Max)
+		}
+	}
+}
+
+// Crossover doesn't do anything.
+func (c *ccComponent) Crossover(q Genome, rng *rand.Rand) {}
+
+// Clone returns a deep copy of a ccComponent.
+func (c ccComponent) Clone() Genome {
+	return &ccComponent{x: copyFloat64s(c.x), group: c.group, cc: c.cc}
+}
+
+// CoopCoevolution implements cooperative coevolution for large-scale
+// continuous problems. The variables are split into groups, each group being
+// optimized in turn by its own subpopulation with differential evolution
+// during a cycle. A partial solution is evaluated by plugging it into the
+// context vector, which is the best complete solution found so far.
+//
+// If Groups is nil then the interacting variables are grouped with
+// DifferentialGrouping, using Epsilon as a threshold. The separable variables
+// are then split into groups of at most GroupSize variables.
+// Reference: https://doi.org/10.1109/TEVC.2013.2281543
+type CoopCoevolution struct {
+	Min, Max  float64 // Boundaries of the search space
+	CRate     float64 // Crossover rate
+	DWeight   float64 // Differential weight
+	Epsilon   float64 // Interaction threshold of DifferentialGrouping
+	GroupSize uint    // Maximum size of the groups of separable variables
+	NCycles   uint    // Number of times each group is optimized
+	Groups    Linkage // Groups of interacting variables
+	Context   []float64
+	Fitness   float64 // Value of F at Context
+	NDims     uint
+	F         func([]float64) float64
+	GAs       []*GA // GA of each group
+
+	conf       GAConfig // Configuration of the GA of each group for one cycle
+	components [][]int  // Variables of each GA
+}
+
+// NewCoopCoevolution instantiates and returns a CoopCoevolution instance
+// after having checked for input errors. Each group is optimized by a
+// subpopulation of popSize individuals during nSteps generations per cycle.
+func NewCoopCoevolution(popSize, nCycles, nSteps uint, min, max float64, parallel bool,
+	rng *rand.Rand) (*CoopCoevolution, error) {
+	// Check inputs
+	if popSize < 4 {
+		return nil, errors.New("popSize should be at least 4")
+	}
+	if nCycles == 0 {
+		return nil, errors.New("nCycles should be positive")
+	}
+	if min >= max {
+		return nil, errors.New("min should be stricly inferior to max")
+	}
+	if rng == nil {
+		rng = newRand()
+	}
+	var conf = GAConfig{
+		NPops:        1,
+		PopSize:      popSize,
+		NGenerations: nSteps,
+		HofSize:      1,
+		Model:        ModMutationOnly{Strict: true},
+		ParallelEval: parallel,
+		RNG:          rand.New(rand.NewSource(rng.Int63())),
+	}
+	// Check the configuration of the GAs
+	if _, err := conf.NewGA(); err != nil {
+		return nil, err
+	}
+	return &CoopCoevolution{
+		Min:       min,
+		Max:       max,
+		CRate:     0.5,
+		DWeight:   0.5,
+		Epsilon:   1e-3,
+		GroupSize: 20,
+		NCycles:   nCycles,
+		conf:      conf,
+	}, nil
+}
+
+// NewDefaultCoopCoevolution calls NewCoopCoevolution with default values.
+func NewDefaultCoopCoevolution() (*CoopCoevolution, error) {
+	return NewCoopCoevolution(20, 10, 20, -5, 5, false, nil)
+}
+
+// decompose sets the variables of each GA: the groups of interacting
+// variables followed by the separable variables, GroupSize at a time.
+func (cc *CoopCoevolution) decompose() error {
+	if cc.Groups == nil {
+		cc.Groups = DifferentialGrouping(cc.F, cc.NDims, cc.Min, cc.Max, cc.Epsilon)
+	}
+	if err := cc.Groups.Validate(int(cc.NDims)); err != nil {
+		return err
+	}
+	var grouped = make([]bool, cc.NDims)
+	cc.components = nil
+	for _, group := range cc.Groups {
+		cc.components = append(cc.components, append([]int(nil), group...))
+		for _, i := range group {
+			grouped[i] = true
+		}
+	}
+	var separable []int
+	for i, g := range grouped {
+		if g {
+			continue
+		}
+		separable = append(separable, i)
+		if uint(len(separable)) == cc.GroupSize {
+			cc.components = append(cc.components, separable)
+			separable = nil
+		}
+	}
+	if len(separable) > 0 {
+		cc.components = append(cc.components, separable)
+	}
+	return nil
+}
+
+// newComponent returns a function which generates random ccComponents for the
+// given group.
+func (cc *CoopCoevolution) newComponent(group int) func(rng *rand.Rand) Genome {
+	return func(rng *rand.Rand) Genome {
+		var n = uint(len(cc.components[group]))
+		return &ccComponent{x: InitUnifFloat64(n, cc.Min, cc.Max, rng), group: group, cc: cc}
+	}
+}
+
+// Minimize finds the minimum of a given real-valued function.
+func (cc *CoopCoevolution) Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error) {
+	if cc.GroupSize == 0 {
+		return nil, 0, errors.New("GroupSize should be positive")
+	}
+	cc.F = f
+	cc.NDims = nDims
+	if err := cc.decompose(); err != nil {
+		return nil, 0, err
+	}
+	var rng = cc.conf.RNG
+	if rng == nil {
+		rng = newRand()
+	}
+	cc.Context = InitUnifFloat64(nDims, cc.Min, cc.Max, rng)
+	cc.Fitness = f(cc.Context)
+	cc.GAs = make([]*GA, len(cc.components))
+	for cycle := uint(0); cycle < cc.NCycles; cycle++ {
+		for k := range cc.components {
+			var err = cc.optimize(k, rng)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	return copyFloat64s(cc.Context), cc.Fitness, nil
+}
+
+// optimize evolves the GA of a group for one cycle and updates the context
+// vector if a better partial solution was found.
+func (cc *CoopCoevolution) optimize(k int, rng *rand.Rand) error {
+	var ga = cc.GAs[k]
+	if ga == nil {
+		var conf = cc.conf
+		conf.RNG = rand.New(rand.NewSource(rng.Int63()))
+		var err error
+		if ga, err = conf.NewGA(); err != nil {
+			return err
+		}
+		cc.GAs[k] = ga
+		if err = ga.init(cc.newComponent(k)); err != nil {
+			return err
+		}
+	} else if err := ga.reevaluate(); err != nil {
+		// The context vector has changed since the last cycle
+		return err
+	}
+	for i := uint(0); i < ga.NGenerations && !ga.done(); i++ {
+		if err := ga.evolve(); err != nil {
+			return err
+		}
+	}
+	var best = ga.HallOfFame[0]
+	if best.Fitness < cc.Fitness {
+		for j, i := range cc.components[k] {
+			cc.Context[i] = best.Genome.(*ccComponent).x[j]
+		}
+		cc.Fitness = best.Fitness
+	}
+	return nil
+}
+
+// Config returns the configuration of the GA of each group, which runs for
+// one cycle.
+func (cc *CoopCoevolution) Config() *GAConfig {
+	return &cc.conf
+}
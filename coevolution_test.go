@@ -0,0 +1,87 @@
+package eaopt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// partiallySeparable is made of a block of 2 interacting variables, a block
+// of 3 interacting variables and separable variables.
+func partiallySeparable(x []float64) float64 {
+	var y = x[0]*x[0] + (x[0]-x[1])*(x[0]-x[1]) +
+		(x[2]+x[3]+x[4])*(x[2]+x[3]+x[4]) + x[2]*x[2] + x[3]*x[3] + x[4]*x[4]
+	for _, xi := range x[5:] {
+		y += xi * xi
+	}
+	return y
+}
+
+func TestDifferentialGrouping(t *testing.T) {
+	var (
+		linkage = DifferentialGrouping(partiallySeparable, 8, -5, 5, 1e-6)
+		want    = Linkage{{0, 1}, {2, 3, 4}}
+	)
+	if !reflect.DeepEqual(linkage, want) {
+		t.Errorf("Expected %v, got %v", want, linkage)
+	}
+}
+
+func TestCoopCoevolutionDecompose(t *testing.T) {
+	var cc, err = NewCoopCoevolution(10, 1, 1, -5, 5, false, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc.F = partiallySeparable
+	cc.NDims = 12
+	cc.GroupSize = 3
+	if err = cc.decompose(); err != nil {
+		t.Fatal(err)
+	}
+	var want = [][]int{{0, 1}, {2, 3, 4}, {5, 6, 7}, {8, 9, 10}, {11}}
+	if !reflect.DeepEqual(cc.components, want) {
+		t.Errorf("Expected %v, got %v", want, cc.components)
+	}
+	cc.Groups = Linkage{{0, 20}}
+	if err = cc.decompose(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestCoopCoevolutionMinimize(t *testing.T) {
+	var cc, err = NewCoopCoevolution(20, 20, 10, -5, 5, false, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y, err := cc.Minimize(partiallySeparable, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y > 1e-3 || y != partiallySeparable(x) {
+		t.Errorf("Expected a minimum close to 0, got %g", y)
+	}
+	if len(cc.GAs) != 2+5 {
+		t.Errorf("Expected 7 groups, got %d", len(cc.GAs))
+	}
+}
+
+func TestNewCoopCoevolutionErrors(t *testing.T) {
+	var rng = newRand()
+	if _, err := NewCoopCoevolution(3, 1, 1, -5, 5, false, rng); err == nil {
+		t.Error("Expected an error for popSize")
+	}
+	if _, err := NewCoopCoevolution(10, 0, 1, -5, 5, false, rng); err == nil {
+		t.Error("Expected an error for nCycles")
+	}
+	if _, err := NewCoopCoevolution(10, 1, 1, 5, -5, false, rng); err == nil {
+		t.Error("Expected an error for min and max")
+	}
+	var cc, err = NewDefaultCoopCoevolution()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	cc.GroupSize = 0
+	if _, _, err = cc.Minimize(partiallySeparable, 10); err == nil {
+		t.Error("Expected an error for GroupSize")
+	}
+	var _ Optimizer = cc
+}
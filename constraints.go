@@ -0,0 +1,72 @@
+package eaopt
+
+import "math"
+
+// A ConstraintHandler returns by how much a Genome violates the constraints
+// of the problem, 0 if the Genome is feasible. When GAConfig.ConstraintHandler
+// is set, the violation of each Individual is stored in its Violation field
+// and Individuals are compared with Deb's feasibility rules instead of their
+// fitness alone, see Individual.Better. This avoids folding penalties, whose
+// weights are hard to tune, into the fitness.
+// Reference: https://doi.org/10.1016/S0045-7825(99)00389-8
+type ConstraintHandler func(genome Genome) float64
+
+// SumViolations returns the total violation of constraints of the form
+// g(x) <= 0 given the values of g(x). Equality constraints h(x) = 0 are
+// usually written as |h(x)| - epsilon <= 0 for a small tolerance epsilon.
+func SumViolations(values ...float64) float64 {
+	var sum float64
+	for _, v := range values {
+		if v > 0 {
+			sum += v
+		}
+	}
+	return sum
+}
+
+// Better returns true if indi is better than other according to Deb's
+// feasibility rules: a feasible Individual beats an infeasible one, two
+// feasible Individuals are compared by fitness and two infeasible Individuals
+// by violation, then by fitness. Without a ConstraintHandler every Individual
+// is feasible, hence Better compares the fitnesses.
+func (indi Individual) Better(other Individual) bool {
+	var feasible, otherFeasible = indi.Violation <= 0, other.Violation <= 0
+	switch {
+	case feasible && otherFeasible:
+		return indi.Fitness < other.Fitness
+	case feasible != otherFeasible:
+		return feasible
+	case indi.Violation != other.Violation:
+		return indi.Violation < other.Violation
+	default:
+		return indi.Fitness < other.Fitness
+	}
+}
+
+// constrainedFitnesses returns the fitnesses of the Individuals, where the
+// fitness of the infeasible Individuals is replaced by the worst fitness of
+// the feasible Individuals plus their violation, as proposed by Deb. Ordering
+// Individuals by these fitnesses is consistent with Better, which makes it
+// possible to apply feasibility rules to fitness proportionate selection.
+func (indis Individuals) constrainedFitnesses() []float64 {
+	var (
+		fitnesses = indis.getFitnesses()
+		worst     = math.Inf(-1)
+		feasible  bool
+	)
+	for _, indi := range indis {
+		if indi.Violation <= 0 {
+			worst = math.Max(worst, indi.Fitness)
+			feasible = true
+		}
+	}
+	if !feasible {
+		worst = 0
+	}
+	for i, indi := range indis {
+		if indi.Violation > 0 {
+			fitnesses[i] = worst + indi.Violation
+		}
+	}
+	return fitnesses
+}
@@ -0,0 +1,125 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSumViolations(t *testing.T) {
+	if v := SumViolations(-1, 2, 0, 0.5); v != 2.5 {
+		t.Errorf("Expected 2.5, got %f", v)
+	}
+	if v := SumViolations(); v != 0 {
+		t.Errorf("Expected 0, got %f", v)
+	}
+}
+
+func TestIndividualBetter(t *testing.T) {
+	var testCases = []struct {
+		a, b   Individual
+		better bool
+	}{
+		{Individual{Fitness: 1}, Individual{Fitness: 2}, true},
+		{Individual{Fitness: 2}, Individual{Fitness: 1}, false},
+		{Individual{Fitness: 5}, Individual{Fitness: 1, Violation: 0.1}, true},
+		{Individual{Fitness: 1, Violation: 0.1}, Individual{Fitness: 5}, false},
+		{Individual{Fitness: 5, Violation: 1}, Individual{Fitness: 1, Violation: 2}, true},
+		{Individual{Fitness: 1, Violation: 1}, Individual{Fitness: 5, Violation: 1}, true},
+		{Individual{Fitness: 1}, Individual{Fitness: 1}, false},
+	}
+	for i, tc := range testCases {
+		if b := tc.a.Better(tc.b); b != tc.better {
+			t.Errorf("Test case %d: expected %t, got %t", i, tc.better, b)
+		}
+	}
+}
+
+func TestConstrainedFitnesses(t *testing.T) {
+	var indis = Individuals{
+		{Fitness: 3},
+		{Fitness: -10, Violation: 2},
+		{Fitness: 1},
+		{Fitness: -20, Violation: 0.5},
+	}
+	var (
+		fitnesses = indis.constrainedFitnesses()
+		expected  = []float64{3, 5, 1, 3.5}
+	)
+	for i := range expected {
+		if fitnesses[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, fitnesses)
+			break
+		}
+	}
+	// Without feasible Individuals the violations are used as they are
+	fitnesses = indis[1:2].constrainedFitnesses()
+	if fitnesses[0] != 2 {
+		t.Errorf("Expected 2, got %f", fitnesses[0])
+	}
+}
+
+func TestSelTournamentFeasibility(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = Individuals{
+			{Fitness: -100, Violation: 1, Evaluated: true},
+			{Fitness: 10, Evaluated: true},
+			{Fitness: -50, Violation: 0.5, Evaluated: true},
+		}
+	)
+	var selected, indexes, err = SelTournament{NContestants: 3}.Apply(1, indis, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexes[0] != 1 || selected[0].Fitness != 10 {
+		t.Errorf("Expected the feasible individual to win, got %v", selected[0])
+	}
+	selected, indexes, err = SelCostTournament{NContestants: 3, CostWeight: 1}.Apply(1, indis, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexes[0] != 1 {
+		t.Errorf("Expected the feasible individual to win, got %v", selected[0])
+	}
+	indis.SortByFitness()
+	if indis[0].Fitness != 10 {
+		t.Errorf("Expected the feasible individual first, got %v", indis[0])
+	}
+}
+
+func TestGAConstraintHandler(t *testing.T) {
+	// Minimize the sum of the elements subject to them being positive
+	var (
+		conf   = NewDefaultGAConfig()
+		noNegs = func(g Genome) float64 {
+			var values []float64
+			for _, x := range g.(Vector) {
+				values = append(values, -x)
+			}
+			return SumViolations(values...)
+		}
+	)
+	conf.NGenerations = 30
+	conf.HofSize = 3
+	conf.ConstraintHandler = noNegs
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for _, indi := range ga.HallOfFame {
+		if indi.Violation != 0 || noNegs(indi.Genome) != 0 {
+			t.Errorf("Expected feasible individuals in the hall of fame, got %v", indi)
+		}
+	}
+	for i := 1; i < len(ga.HallOfFame); i++ {
+		if ga.HallOfFame[i].Better(ga.HallOfFame[i-1]) {
+			t.Errorf("The hall of fame isn't sorted: %v", ga.HallOfFame)
+		}
+	}
+	if best := ga.HallOfFame[0].Fitness; best < 0 || math.IsInf(best, 0) {
+		t.Errorf("Expected a positive best fitness, got %f", best)
+	}
+}
@@ -1,6 +1,7 @@
 package eaopt
 
 import (
+	"math"
 	"math/rand"
 	"sort"
 )
@@ -328,3 +329,239 @@ func CrossERXFloat64(s1 []float64, s2 []float64) {
 func CrossERXString(s1 []string, s2 []string) {
 	CrossERX(StringSlice(s1), StringSlice(s2))
 }
+
+// CrossMask swaps the genes of p1 and p2 at the positions where mask is true.
+// mask has to have as many values as there are genes.
+func CrossMask(p1, p2 Slice, mask []bool) {
+	for i, swap := range mask {
+		if swap {
+			var v = p1.At(i)
+			p1.Set(i, p2.At(i))
+			p2.Set(i, v)
+		}
+	}
+}
+
+// CrossLinkage is a uniform crossover that keeps the building blocks of
+// linkage together: each group of linked genes, and each gene that doesn't
+// belong to a group, is swapped between p1 and p2 as a whole with probability
+// 0.5.
+func CrossLinkage(p1, p2 Slice, linkage Linkage, rng *rand.Rand) {
+	CrossMask(p1, p2, linkage.Mask(p1.Len(), rng))
+}
+
+// CrossLinkageInt calls CrossLinkage on int slices.
+func CrossLinkageInt(s1 []int, s2 []int, linkage Linkage, rng *rand.Rand) {
+	CrossLinkage(IntSlice(s1), IntSlice(s2), linkage, rng)
+}
+
+// CrossLinkageFloat64 calls CrossLinkage on float64 slices.
+func CrossLinkageFloat64(s1 []float64, s2 []float64, linkage Linkage, rng *rand.Rand) {
+	CrossLinkage(Float64Slice(s1), Float64Slice(s2), linkage, rng)
+}
+
+// CrossLinkageString calls CrossLinkage on string slices.
+func CrossLinkageString(s1 []string, s2 []string, linkage Linkage, rng *rand.Rand) {
+	CrossLinkage(StringSlice(s1), StringSlice(s2), linkage, rng)
+}
+
+// Multi-parent crossovers
+
+// CrossGenePool (gene pool recombination) shuffles each gene between the
+// parents: after the crossover, the i-th gene of each parent is the i-th gene
+// of a parent chosen at random, without replacement. The values present at each
+// position are preserved, but gene uniqueness isn't.
+func CrossGenePool(parents []Slice, rng *rand.Rand) {
+	if len(parents) < 2 {
+		return
+	}
+	var values = make([]interface{}, len(parents))
+	for i := 0; i < parents[0].Len(); i++ {
+		for j, p := range parents {
+			values[j] = p.At(i)
+		}
+		rng.Shuffle(len(values), func(a, b int) { values[a], values[b] = values[b], values[a] })
+		for j, p := range parents {
+			p.Set(i, values[j])
+		}
+	}
+}
+
+// CrossGenePoolInt calls CrossGenePool on int slices.
+func CrossGenePoolInt(parents [][]int, rng *rand.Rand) {
+	var slices = make([]Slice, len(parents))
+	for i, p := range parents {
+		slices[i] = IntSlice(p)
+	}
+	CrossGenePool(slices, rng)
+}
+
+// CrossGenePoolFloat64 calls CrossGenePool on float64 slices.
+func CrossGenePoolFloat64(parents [][]float64, rng *rand.Rand) {
+	var slices = make([]Slice, len(parents))
+	for i, p := range parents {
+		slices[i] = Float64Slice(p)
+	}
+	CrossGenePool(slices, rng)
+}
+
+// CrossGenePoolString calls CrossGenePool on string slices.
+func CrossGenePoolString(parents [][]string, rng *rand.Rand) {
+	var slices = make([]Slice, len(parents))
+	for i, p := range parents {
+		slices[i] = StringSlice(p)
+	}
+	CrossGenePool(slices, rng)
+}
+
+// Contains the deterministic part of the diagonal crossover for testing
+// purposes.
+func diagonal(parents []Slice, indexes []int) {
+	var (
+		n      = len(parents)
+		copies = make([]Slice, n)
+	)
+	for i, p := range parents {
+		copies[i] = p.Copy()
+	}
+	// Add the first and last indexes
+	indexes = append([]int{0}, indexes...)
+	indexes = append(indexes, parents[0].Len())
+	for s := 0; s < len(indexes)-1; s++ {
+		for i, p := range parents {
+			p.Slice(indexes[s], indexes[s+1]).Replace(copies[(i+s)%n].Slice(indexes[s], indexes[s+1]))
+		}
+	}
+}
+
+// CrossDiagonal (Diagonal Crossover) generalizes n-point crossover to n
+// parents. n-1 identical crossover points are chosen on each parent's genome,
+// which is thus cut into n segments. The i-th offspring receives the s-th
+// segment of parent i+s (modulo n), hence the offsprings are built along the
+// diagonals of the parents' segments. Fewer crossover points are used if the
+// genomes are too short.
+func CrossDiagonal(parents []Slice, rng *rand.Rand) {
+	if len(parents) < 2 || parents[0].Len() < 2 {
+		return
+	}
+	var (
+		k       = minInt(len(parents)-1, parents[0].Len()-1)
+		indexes = randomInts(uint(k), 1, parents[0].Len(), rng)
+	)
+	sort.Ints(indexes)
+	diagonal(parents, indexes)
+}
+
+// CrossDiagonalInt calls CrossDiagonal on int slices.
+func CrossDiagonalInt(parents [][]int, rng *rand.Rand) {
+	var slices = make([]Slice, len(parents))
+	for i, p := range parents {
+		slices[i] = IntSlice(p)
+	}
+	CrossDiagonal(slices, rng)
+}
+
+// CrossDiagonalFloat64 calls CrossDiagonal on float64 slices.
+func CrossDiagonalFloat64(parents [][]float64, rng *rand.Rand) {
+	var slices = make([]Slice, len(parents))
+	for i, p := range parents {
+		slices[i] = Float64Slice(p)
+	}
+	CrossDiagonal(slices, rng)
+}
+
+// CrossDiagonalString calls CrossDiagonal on string slices.
+func CrossDiagonalString(parents [][]string, rng *rand.Rand) {
+	var slices = make([]Slice, len(parents))
+	for i, p := range parents {
+		slices[i] = StringSlice(p)
+	}
+	CrossDiagonal(slices, rng)
+}
+
+// CrossCentroidFloat64 returns the weighted centroid of the parents, which
+// have to have the same length. weights has one weight per parent, the weights
+// are normalized so that they sum to 1. If weights is nil then each parent has
+// the same weight. Combined with RankWeights and parents sorted by fitness the
+// centroid is pulled towards the best parents, as in evolution strategies.
+func CrossCentroidFloat64(parents [][]float64, weights []float64) []float64 {
+	if len(parents) == 0 {
+		return nil
+	}
+	var (
+		centroid = make([]float64, len(parents[0]))
+		total    float64
+	)
+	for i, p := range parents {
+		var w = 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		total += w
+		for j, x := range p {
+			centroid[j] += w * x
+		}
+	}
+	for j := range centroid {
+		centroid[j] /= total
+	}
+	return centroid
+}
+
+// RankWeights returns n positive weights which decrease logarithmically with
+// the rank and sum to 1, the first weight being the largest. These are the
+// weights used by CMA-ES to recombine the best n individuals.
+func RankWeights(n int) []float64 {
+	var (
+		weights = make([]float64, n)
+		total   float64
+	)
+	for i := range weights {
+		weights[i] = math.Log(float64(n)+0.5) - math.Log(float64(i+1))
+		total += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+// CrossCutSplice (cut-and-splice crossover) chooses a cut point on each parent
+// independently and swaps the tails, hence the offsprings' lengths generally
+// differ from their parents' lengths. Cut points are drawn again, up to 10
+// times, until both offsprings respect bounds; the parents are returned as
+// they are if none do. The parents aren't modified, the offsprings are new
+// Slices.
+func CrossCutSplice(p1, p2 Slice, bounds LengthBounds, rng *rand.Rand) (Slice, Slice) {
+	var n1, n2 = p1.Len(), p2.Len()
+	for attempt := 0; attempt < 10; attempt++ {
+		var a, b = rng.Intn(n1 + 1), rng.Intn(n2 + 1)
+		if !bounds.allows(a+n2-b) || !bounds.allows(b+n1-a) {
+			continue
+		}
+		var (
+			h1, t1 = p1.Split(a)
+			h2, t2 = p2.Split(b)
+		)
+		return concatSlices(h1, t2), concatSlices(h2, t1)
+	}
+	return p1, p2
+}
+
+// CrossCutSpliceInt calls CrossCutSplice on two int slices.
+func CrossCutSpliceInt(s1, s2 []int, bounds LengthBounds, rng *rand.Rand) ([]int, []int) {
+	var o1, o2 = CrossCutSplice(IntSlice(s1), IntSlice(s2), bounds, rng)
+	return o1.(IntSlice), o2.(IntSlice)
+}
+
+// CrossCutSpliceFloat64 calls CrossCutSplice on two float64 slices.
+func CrossCutSpliceFloat64(s1, s2 []float64, bounds LengthBounds, rng *rand.Rand) ([]float64, []float64) {
+	var o1, o2 = CrossCutSplice(Float64Slice(s1), Float64Slice(s2), bounds, rng)
+	return o1.(Float64Slice), o2.(Float64Slice)
+}
+
+// CrossCutSpliceString calls CrossCutSplice on two string slices.
+func CrossCutSpliceString(s1, s2 []string, bounds LengthBounds, rng *rand.Rand) ([]string, []string) {
+	var o1, o2 = CrossCutSplice(StringSlice(s1), StringSlice(s2), bounds, rng)
+	return o1.(StringSlice), o2.(StringSlice)
+}
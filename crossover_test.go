@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -543,3 +544,132 @@ func TestCrossERXString(t *testing.T) {
 		t.Errorf("Expected %v, got %v", o2, p2)
 	}
 }
+
+func TestCrossGenePool(t *testing.T) {
+	var (
+		rng     = newRand()
+		parents = [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10, 11, 12}}
+	)
+	CrossGenePoolInt(parents, rng)
+	// Each position keeps the same values
+	for i := range parents[0] {
+		var column = []int{parents[0][i], parents[1][i], parents[2][i]}
+		sort.Ints(column)
+		if !reflect.DeepEqual(column, []int{i + 1, i + 5, i + 9}) {
+			t.Errorf("Wrong values at position %d: %v", i, column)
+		}
+	}
+	var floats = [][]float64{{1, 2}, {3, 4}}
+	CrossGenePoolFloat64(floats, rng)
+	if floats[0][0]+floats[1][0] != 4 || floats[0][1]+floats[1][1] != 6 {
+		t.Errorf("Wrong values: %v", floats)
+	}
+	var strings = [][]string{{"a"}, {"b"}}
+	CrossGenePoolString(strings, rng)
+	if strings[0][0] == strings[1][0] {
+		t.Errorf("Wrong values: %v", strings)
+	}
+}
+
+func TestDiagonal(t *testing.T) {
+	var parents = []Slice{
+		IntSlice{1, 1, 1, 1, 1},
+		IntSlice{2, 2, 2, 2, 2},
+		IntSlice{3, 3, 3, 3, 3},
+	}
+	diagonal(parents, []int{1, 3})
+	var expected = []Slice{
+		IntSlice{1, 2, 2, 3, 3},
+		IntSlice{2, 3, 3, 1, 1},
+		IntSlice{3, 1, 1, 2, 2},
+	}
+	if !reflect.DeepEqual(parents, expected) {
+		t.Errorf("Expected %v, got %v", expected, parents)
+	}
+}
+
+func TestCrossDiagonal(t *testing.T) {
+	var (
+		rng     = newRand()
+		parents = [][]int{{1, 1, 1, 1}, {2, 2, 2, 2}, {3, 3, 3, 3}}
+	)
+	CrossDiagonalInt(parents, rng)
+	for i, p := range parents {
+		// The first segment comes from the parent itself and each offspring
+		// has genes from every parent
+		if p[0] != i+1 || p[1] == p[3] || p[3] == i+1 {
+			t.Errorf("Unexpected offspring %v", p)
+		}
+	}
+	// Short genomes use fewer crossover points
+	var floats = [][]float64{{1, 1}, {2, 2}, {3, 3}}
+	CrossDiagonalFloat64(floats, rng)
+	if !reflect.DeepEqual(floats, [][]float64{{1, 2}, {2, 3}, {3, 1}}) {
+		t.Errorf("Unexpected offsprings %v", floats)
+	}
+	var strings = [][]string{{"a"}, {"b"}}
+	CrossDiagonalString(strings, rng)
+	if !reflect.DeepEqual(strings, [][]string{{"a"}, {"b"}}) {
+		t.Errorf("Unexpected offsprings %v", strings)
+	}
+}
+
+func TestCrossCentroidFloat64(t *testing.T) {
+	var parents = [][]float64{{0, 0}, {2, 4}}
+	if c := CrossCentroidFloat64(parents, nil); !reflect.DeepEqual(c, []float64{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", c)
+	}
+	if c := CrossCentroidFloat64(parents, []float64{3, 1}); !reflect.DeepEqual(c, []float64{0.5, 1}) {
+		t.Errorf("Expected [0.5 1], got %v", c)
+	}
+	if c := CrossCentroidFloat64(nil, nil); c != nil {
+		t.Errorf("Expected nil, got %v", c)
+	}
+}
+
+func TestRankWeights(t *testing.T) {
+	var (
+		weights = RankWeights(5)
+		sum     float64
+	)
+	for i, w := range weights {
+		if w <= 0 || (i > 0 && w >= weights[i-1]) {
+			t.Errorf("Weights should be positive and decreasing, got %v", weights)
+		}
+		sum += w
+	}
+	if math.Abs(sum-1) > 1e-12 {
+		t.Errorf("Weights should sum to 1, got %f", sum)
+	}
+}
+
+func TestCrossCutSplice(t *testing.T) {
+	var rng = newRand()
+	for i := 0; i < 20; i++ {
+		var (
+			p1     = []int{1, 2, 3}
+			p2     = []int{4, 5, 6, 7, 8}
+			o1, o2 = CrossCutSpliceInt(p1, p2, LengthBounds{Min: 2, Max: 6}, rng)
+		)
+		if len(o1)+len(o2) != 8 {
+			t.Errorf("Genes were lost: %v %v", o1, o2)
+		}
+		for _, o := range [][]int{o1, o2} {
+			if len(o) < 2 || len(o) > 6 {
+				t.Errorf("Expected the offspring to stay within bounds, got %v", o)
+			}
+		}
+		if p1[0] != 1 || p1[2] != 3 || p2[0] != 4 || p2[4] != 8 {
+			t.Errorf("The parents were modified: %v %v", p1, p2)
+		}
+	}
+	// The parents are returned as they are if the bounds can't be met
+	var o1, o2 = CrossCutSpliceFloat64([]float64{1}, []float64{2}, LengthBounds{Min: 3}, rng)
+	if len(o1) != 1 || len(o2) != 1 {
+		t.Errorf("Expected the parents, got %v %v", o1, o2)
+	}
+	var s1, s2 = CrossCutSpliceString([]string{"a", "b"}, []string{"c"}, LengthBounds{}, rng)
+	if len(s1)+len(s2) != 3 {
+		t.Errorf("Genes were lost: %v %v", s1, s2)
+	}
+}
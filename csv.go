@@ -0,0 +1,257 @@
+package eaopt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// A CSVMarshaler is a Genome that knows how to encode itself as CSV fields. By
+// default Genomes are encoded with their JSON representation: each element of
+// a JSON array gets its own column, any other value is stored in a single
+// column.
+type CSVMarshaler interface {
+	MarshalCSV() ([]string, error)
+}
+
+// A CSVUnmarshaler decodes a Genome from the genome fields of a CSV record.
+type CSVUnmarshaler func(fields []string) (Genome, error)
+
+// WriteCSV writes the Individuals to w, one record per Individual. The columns
+// are "id", "fitness" and then either "genome" when the Genomes are encoded in
+// a single field or "genome_0", "genome_1", ... when they are encoded in
+// several fields. The fitness field of an Individual that hasn't been
+// evaluated is left empty. Such files can be loaded as is in tools such as
+// pandas.
+func (indis Individuals) WriteCSV(w io.Writer) error {
+	var header, records, err = indis.csvTable()
+	if err != nil {
+		return err
+	}
+	var cw = csv.NewWriter(w)
+	cw.Write(header)
+	for _, record := range records {
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvTable returns the header and the records written by WriteCSV, every
+// record having the same width as the header.
+func (indis Individuals) csvTable() ([]string, [][]string, error) {
+	var (
+		records = make([][]string, len(indis))
+		width   int
+		single  = true
+	)
+	for i, indi := range indis {
+		var fields, multi, err = genomeCSVFields(indi.Genome)
+		if err != nil {
+			return nil, nil, fmt.Errorf("individual %s: %w", indi.ID, err)
+		}
+		if multi {
+			single = false
+		}
+		var fitness string
+		if indi.Evaluated {
+			fitness = strconv.FormatFloat(indi.Fitness, 'g', -1, 64)
+		}
+		records[i] = append([]string{indi.ID, fitness}, fields...)
+		if len(fields) > width {
+			width = len(fields)
+		}
+	}
+	var header = []string{"id", "fitness"}
+	if single && width <= 1 {
+		header = append(header, "genome")
+	} else {
+		for i := 0; i < width; i++ {
+			header = append(header, "genome_"+strconv.Itoa(i))
+		}
+	}
+	for i := range records {
+		// Pad variable length Genomes so that every record has the same width
+		for len(records[i]) < len(header) {
+			records[i] = append(records[i], "")
+		}
+	}
+	return header, records, nil
+}
+
+// genomeCSVFields encodes a Genome as CSV fields. multi indicates if the
+// fields are elements of a sequence.
+func genomeCSVFields(genome Genome) (fields []string, multi bool, err error) {
+	if m, ok := genome.(CSVMarshaler); ok {
+		fields, err = m.MarshalCSV()
+		return fields, true, err
+	}
+	b, err := json.Marshal(genome)
+	if err != nil {
+		return nil, false, err
+	}
+	var elements []json.RawMessage
+	if json.Unmarshal(b, &elements) != nil {
+		return []string{string(b)}, false, nil
+	}
+	fields = make([]string, len(elements))
+	for i, e := range elements {
+		// Strings are unquoted to keep the file readable
+		var s string
+		if json.Unmarshal(e, &s) == nil {
+			fields[i] = s
+		} else {
+			fields[i] = string(e)
+		}
+	}
+	return fields, true, nil
+}
+
+// ReadIndividualsCSV reads Individuals written by Individuals.WriteCSV,
+// possibly edited in the meantime. The genome fields are decoded with
+// unmarshal. Individuals without an ID are given a random one and Individuals
+// without a fitness are marked as not evaluated, they can be evaluated with
+// Individuals.Evaluate before being injected into a Population. Trailing empty
+// genome fields, which pad variable length Genomes, are removed.
+func ReadIndividualsCSV(r io.Reader, unmarshal CSVUnmarshaler, rng *rand.Rand) (Individuals, error) {
+	if unmarshal == nil {
+		return nil, errors.New("unmarshal can't be nil")
+	}
+	var cr = csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < 3 || header[0] != "id" || header[1] != "fitness" {
+		return nil, errors.New(`CSV header should start with "id", "fitness" and contain genome columns`)
+	}
+	var indis Individuals
+	for line := 2; ; line++ {
+		var record, err = cr.Read()
+		if err == io.EOF {
+			return indis, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		indi, err := recordIndividual(record, unmarshal, rng)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		indis = append(indis, indi)
+	}
+}
+
+// recordIndividual decodes an Individual from a record made of an ID, a
+// fitness and genome fields.
+func recordIndividual(record []string, unmarshal CSVUnmarshaler, rng *rand.Rand) (Individual, error) {
+	var fields = record[2:]
+	for len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	var genome, err = unmarshal(fields)
+	if err != nil {
+		return Individual{}, err
+	}
+	var indi = NewIndividual(genome, rng)
+	if record[0] != "" {
+		indi.ID = record[0]
+	}
+	if record[1] != "" {
+		if indi.Fitness, err = strconv.ParseFloat(record[1], 64); err != nil {
+			return Individual{}, err
+		}
+		indi.Evaluated = true
+	}
+	return indi, nil
+}
+
+// JSONCSVUnmarshaler returns a CSVUnmarshaler which converts the genome fields
+// back to JSON and decodes them with a JSON unmarshaler, such as the one used
+// for GAConfig.GenomeJSONUnmarshaler. A single field containing a JSON object
+// or array is decoded as is, otherwise the fields are decoded as the elements
+// of a JSON array. Fields that aren't valid JSON values are treated as strings.
+func JSONCSVUnmarshaler(unmarshal func([]byte) (Genome, error)) CSVUnmarshaler {
+	return func(fields []string) (Genome, error) {
+		if len(fields) == 1 && json.Valid([]byte(fields[0])) {
+			if b := strings.TrimSpace(fields[0]); strings.HasPrefix(b, "[") || strings.HasPrefix(b, "{") {
+				return unmarshal([]byte(b))
+			}
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, field := range fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if json.Valid([]byte(field)) {
+				buf.WriteString(field)
+			} else {
+				var b, _ = json.Marshal(field)
+				buf.Write(b)
+			}
+		}
+		buf.WriteByte(']')
+		return unmarshal(buf.Bytes())
+	}
+}
+
+// ParseCSVFloat64s parses genome fields into a slice of float64s, which is
+// convenient for writing a CSVUnmarshaler for real-valued Genomes.
+func ParseCSVFloat64s(fields []string) ([]float64, error) {
+	var floats = make([]float64, len(fields))
+	for i, field := range fields {
+		var f, err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, err
+		}
+		floats[i] = f
+	}
+	return floats, nil
+}
+
+// WriteCSV writes the Population's Individuals to w, see Individuals.WriteCSV.
+func (pop Population) WriteCSV(w io.Writer) error {
+	return pop.Individuals.WriteCSV(w)
+}
+
+// ReadCSV replaces the Population's Individuals with the ones read from r, see
+// ReadIndividualsCSV. If unmarshal is nil then the Population's
+// JSONUnmarshaler is used through JSONCSVUnmarshaler. The Individuals that
+// haven't been evaluated are evaluated.
+func (pop *Population) ReadCSV(r io.Reader, unmarshal CSVUnmarshaler, parallel bool) error {
+	return pop.readIndividuals(unmarshal, parallel, func(unmarshal CSVUnmarshaler, rng *rand.Rand) (Individuals, error) {
+		return ReadIndividualsCSV(r, unmarshal, rng)
+	})
+}
+
+// readIndividuals replaces the Population's Individuals with the ones returned
+// by read, see Population.ReadCSV.
+func (pop *Population) readIndividuals(unmarshal CSVUnmarshaler, parallel bool,
+	read func(unmarshal CSVUnmarshaler, rng *rand.Rand) (Individuals, error)) error {
+	if unmarshal == nil {
+		if pop.JSONUnmarshaler == nil {
+			return errors.New("either unmarshal or the Population's JSONUnmarshaler has to be provided")
+		}
+		unmarshal = JSONCSVUnmarshaler(pop.JSONUnmarshaler)
+	}
+	var rng = pop.RNG
+	if rng == nil {
+		rng = newRand()
+	}
+	var indis, err = read(unmarshal, rng)
+	if err != nil {
+		return err
+	}
+	if err = indis.Evaluate(parallel); err != nil {
+		return err
+	}
+	pop.Individuals = indis
+	return nil
+}
@@ -0,0 +1,180 @@
+package eaopt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndividualsCSVRoundTrip(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newIndividuals(10, false, NewVector, rng)
+		buf   bytes.Buffer
+	)
+	indis[:5].Evaluate(false)
+	if err := indis.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var records, _ = csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	var header = []string{"id", "fitness", "genome_0", "genome_1", "genome_2", "genome_3"}
+	if !reflect.DeepEqual(records[0], header) {
+		t.Errorf("Expected header %v, got %v", header, records[0])
+	}
+	decoded, err := ReadIndividualsCSV(&buf, JSONCSVUnmarshaler(VectorJSONUnmarshaler), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(indis) {
+		t.Fatalf("Expected %d individuals, got %d", len(indis), len(decoded))
+	}
+	for i, indi := range decoded {
+		if indi.ID != indis[i].ID {
+			t.Errorf("Expected ID %s, got %s", indis[i].ID, indi.ID)
+		}
+		if indi.Evaluated != indis[i].Evaluated || indi.Fitness != indis[i].Fitness {
+			t.Errorf("Expected fitness %f (%v), got %f (%v)", indis[i].Fitness, indis[i].Evaluated,
+				indi.Fitness, indi.Evaluated)
+		}
+		if !reflect.DeepEqual(indi.Genome, indis[i].Genome) {
+			t.Errorf("Expected %v, got %v", indis[i].Genome, indi.Genome)
+		}
+	}
+}
+
+// csvStrings is a variable length string Genome with its own CSV encoding.
+type csvStrings []string
+
+func (s csvStrings) Evaluate() (float64, error)           { return float64(len(s)), nil }
+func (s csvStrings) Mutate(rng *rand.Rand)                {}
+func (s csvStrings) Crossover(q Genome, rng *rand.Rand)   {}
+func (s csvStrings) Clone() Genome                        { return append(csvStrings{}, s...) }
+func (s csvStrings) MarshalCSV() ([]string, error)        { return s, nil }
+func unmarshalCSVStrings(fields []string) (Genome, error) { return csvStrings(fields), nil }
+func unmarshalCSVError(fields []string) (Genome, error)   { return nil, errors.New("unmarshal") }
+func unmarshalCSVFloat64s(fields []string) (Genome, error) {
+	var x, err = ParseCSVFloat64s(fields)
+	return Vector(x), err
+}
+
+func TestIndividualsCSVMarshaler(t *testing.T) {
+	var (
+		indis = Individuals{
+			NewIndividual(csvStrings{"a", "b, c"}, newRand()),
+			NewIndividual(csvStrings{"d"}, newRand()),
+		}
+		buf bytes.Buffer
+	)
+	if err := indis.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded, err = ReadIndividualsCSV(&buf, unmarshalCSVStrings, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, indi := range decoded {
+		if !reflect.DeepEqual(indi.Genome, indis[i].Genome) {
+			t.Errorf("Expected %v, got %v", indis[i].Genome, indi.Genome)
+		}
+		if indi.Evaluated {
+			t.Error("Individual should not be evaluated")
+		}
+	}
+}
+
+func TestIndividualsCSVSingleColumn(t *testing.T) {
+	type object struct{ A, B int }
+	var (
+		indis = Individuals{NewIndividual(ErrorGenome{}, newRand())}
+		buf   bytes.Buffer
+	)
+	indis.WriteCSV(&buf)
+	if !strings.HasPrefix(buf.String(), "id,fitness,genome\n") {
+		t.Errorf("Expected a single genome column, got %q", buf.String())
+	}
+	var fields []string
+	JSONCSVUnmarshaler(func(b []byte) (Genome, error) {
+		var o object
+		fields = append(fields, string(b))
+		return nil, json.Unmarshal(b, &o)
+	})([]string{`{"A": 1, "B": 2}`})
+	if len(fields) != 1 || fields[0] != `{"A": 1, "B": 2}` {
+		t.Errorf("Expected the object to be decoded as is, got %v", fields)
+	}
+}
+
+func TestReadIndividualsCSV(t *testing.T) {
+	var testCases = []struct {
+		data      string
+		unmarshal CSVUnmarshaler
+		err       bool
+	}{
+		{"id,fitness,genome_0,genome_1\nabc,1.5,1,2\n,,3,4\n", unmarshalCSVFloat64s, false},
+		{"id,fitness,genome_0,genome_1\nabc,1.5,1,x\n", unmarshalCSVFloat64s, true},
+		{"id,fitness,genome_0,genome_1\nabc,x,1,2\n", unmarshalCSVFloat64s, true},
+		{"id,fitness,genome_0\nabc,1,1\n", unmarshalCSVError, true},
+		{"id,fitness,genome_0\nabc,1,1\n", nil, true},
+		{"id,genome_0\nabc,1\n", unmarshalCSVFloat64s, true},
+		{"", unmarshalCSVFloat64s, true},
+	}
+	for i, tc := range testCases {
+		var indis, err = ReadIndividualsCSV(strings.NewReader(tc.data), tc.unmarshal, newRand())
+		if (err != nil) != tc.err {
+			t.Errorf("Error in test case number %d: %v", i, err)
+		}
+		if err == nil {
+			if indis[0].ID != "abc" || indis[0].Fitness != 1.5 || !indis[0].Evaluated {
+				t.Errorf("Wrong first individual: %v", indis[0])
+			}
+			if indis[1].ID == "" || indis[1].Evaluated {
+				t.Errorf("Wrong second individual: %v", indis[1])
+			}
+			if !reflect.DeepEqual(indis[1].Genome, Vector{3, 4}) {
+				t.Errorf("Expected %v, got %v", Vector{3, 4}, indis[1].Genome)
+			}
+		}
+	}
+}
+
+func TestPopulationCSV(t *testing.T) {
+	var (
+		rng  = newRand()
+		pop  = newPopulation(10, false, NewVector, rng)
+		buf  bytes.Buffer
+		pop2 = Population{RNG: rng}
+	)
+	pop.Individuals.Evaluate(false)
+	if err := pop.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var data = buf.String()
+	// Without any unmarshaler
+	if err := pop2.ReadCSV(strings.NewReader(data), nil, false); err == nil {
+		t.Error("Expected an error")
+	}
+	// With the Population's JSON unmarshaler
+	pop2.JSONUnmarshaler = VectorJSONUnmarshaler
+	if err := pop2.ReadCSV(strings.NewReader(data), nil, false); err != nil {
+		t.Fatal(err)
+	}
+	// Evaluation durations aren't exported
+	for i := range pop.Individuals {
+		pop.Individuals[i].EvalDuration = 0
+	}
+	if !reflect.DeepEqual(pop2.Individuals, pop.Individuals) {
+		t.Errorf("Expected %v, got %v", pop.Individuals, pop2.Individuals)
+	}
+	// Edited individuals are evaluated
+	var edited = "id,fitness,genome_0,genome_1,genome_2,genome_3\n,,1,1,1,1\n"
+	if err := pop2.ReadCSV(strings.NewReader(edited), unmarshalCSVFloat64s, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(pop2.Individuals) != 1 || !pop2.Individuals[0].Evaluated || pop2.Individuals[0].Fitness != 4 {
+		t.Errorf("Expected a single evaluated individual, got %v", pop2.Individuals)
+	}
+}
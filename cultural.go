@@ -0,0 +1,178 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ModCultural turns Model into a cultural algorithm, which evolves a belief
+// space alongside the Population and uses it to steer the offsprings of
+// continuous problems. Before Model is applied, the AcceptRate best
+// Individuals of the Population, which are expected to be *FloatVectors, are
+// accepted into the Population's BeliefSpace. Once Model has produced the
+// offsprings, each offspring that hasn't been evaluated yet is influenced by
+// the beliefs with probability Influence, see BeliefSpace.Influence.
+// Offsprings evaluated by Model itself, as with ModSteadyState, are left
+// untouched. The BeliefSpace is stored in the Population's Beliefs field,
+// hence a run resumed from JSON keeps its beliefs.
+// Reference: Reynolds, "An introduction to cultural algorithms" (1994)
+type ModCultural struct {
+	Model      Model
+	AcceptRate float64 // Proportion of the Population accepted into the belief space
+	Influence  float64 // Probability of influencing each offspring
+	Sigma      float64 // Standard deviation of a step, relative to the width of the normative range
+}
+
+// Apply ModCultural.
+func (mod ModCultural) Apply(pop *Population) error {
+	if pop.Beliefs == nil {
+		pop.Beliefs = &BeliefSpace{}
+	}
+	if err := pop.Beliefs.Accept(mod.accepted(pop.Individuals)); err != nil {
+		return err
+	}
+	if err := mod.Model.Apply(pop); err != nil {
+		return err
+	}
+	if pop.Beliefs.Best == nil {
+		return nil
+	}
+	for _, indi := range pop.Individuals {
+		if indi.Evaluated || pop.RNG.Float64() >= mod.Influence {
+			continue
+		}
+		var v, ok = indi.Genome.(*FloatVector)
+		if !ok {
+			return fmt.Errorf("expected a *FloatVector, got %T", indi.Genome)
+		}
+		pop.Beliefs.Influence(v, mod.Sigma, pop.RNG)
+	}
+	return nil
+}
+
+// accepted returns the AcceptRate best Individuals of indis, at least one.
+func (mod ModCultural) accepted(indis Individuals) Individuals {
+	var sorted = append(Individuals(nil), indis...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Better(sorted[j]) })
+	var n = int(math.Ceil(mod.AcceptRate * float64(len(sorted))))
+	if n < 1 {
+		n = 1
+	}
+	return sorted[:minInt(n, len(sorted))]
+}
+
+// Validate ModCultural fields.
+func (mod ModCultural) Validate() error {
+	if mod.Model == nil {
+		return errors.New("Model cannot be nil")
+	}
+	if mod.AcceptRate <= 0 || mod.AcceptRate > 1 {
+		return errors.New("AcceptRate should be in (0, 1]")
+	}
+	if mod.Influence < 0 || mod.Influence > 1 {
+		return errors.New("Influence should be in [0, 1]")
+	}
+	if mod.Sigma <= 0 || math.IsNaN(mod.Sigma) {
+		return errors.New("Sigma should be strictly higher than 0")
+	}
+	return mod.Model.Validate()
+}
+
+// A BeliefSpace holds the knowledge a cultural algorithm extracts from the
+// Individuals it accepts. The normative knowledge is a range [Lower[i],
+// Upper[i]] per dimension which contains the promising values, along with
+// the fitnesses of the Individuals which set each bound. The situational
+// knowledge is the best vector accepted so far, Best, whose fitness is
+// BestFitness. Accepted counts the accepted Individuals.
+type BeliefSpace struct {
+	Lower        []float64 `json:"lower"`
+	Upper        []float64 `json:"upper"`
+	LowerFitness []float64 `json:"lower_fitness"`
+	UpperFitness []float64 `json:"upper_fitness"`
+	Best         []float64 `json:"best"`
+	BestFitness  float64   `json:"best_fitness"`
+	Accepted     uint64    `json:"accepted"`
+}
+
+// Accept updates the BeliefSpace with indis, whose Genomes have to be
+// *FloatVectors. Individuals which aren't evaluated, are infeasible or have a
+// non-finite fitness are skipped. A bound of the normative range moves to the
+// value of an Individual if the value lies beyond it, or if the value lies
+// within the range and the Individual is fitter than the one which set the
+// bound. The range thus widens to include good values and shrinks around
+// better ones. Best is replaced by a fitter Individual.
+func (bs *BeliefSpace) Accept(indis Individuals) error {
+	for _, indi := range indis {
+		if !indi.Evaluated || indi.Violation > 0 || math.IsInf(indi.Fitness, 0) || math.IsNaN(indi.Fitness) {
+			continue
+		}
+		var v, ok = indi.Genome.(*FloatVector)
+		if !ok {
+			return fmt.Errorf("expected a *FloatVector, got %T", indi.Genome)
+		}
+		var f = indi.Fitness
+		if bs.Best == nil {
+			bs.Lower = copyFloat64s(v.Values)
+			bs.Upper = copyFloat64s(v.Values)
+			bs.LowerFitness = make([]float64, len(v.Values))
+			bs.UpperFitness = make([]float64, len(v.Values))
+			for i := range v.Values {
+				bs.LowerFitness[i], bs.UpperFitness[i] = f, f
+			}
+			bs.Best, bs.BestFitness = copyFloat64s(v.Values), f
+			bs.Accepted++
+			continue
+		}
+		if len(v.Values) != len(bs.Best) {
+			return errors.New("FloatVectors should have the same length")
+		}
+		for i, x := range v.Values {
+			var lower, upper = bs.Lower[i], bs.Upper[i]
+			if x < lower || (x <= upper && f < bs.LowerFitness[i]) {
+				bs.Lower[i], bs.LowerFitness[i] = x, f
+			}
+			if x > upper || (x >= lower && f < bs.UpperFitness[i]) {
+				bs.Upper[i], bs.UpperFitness[i] = x, f
+			}
+		}
+		if f < bs.BestFitness {
+			copy(bs.Best, v.Values)
+			bs.BestFitness = f
+		}
+		bs.Accepted++
+	}
+	return nil
+}
+
+// Influence steers v with the BeliefSpace: the situational knowledge gives
+// the direction and the normative knowledge the step size. Each value moves
+// towards Best by a half-normal step whose standard deviation is sigma times
+// the width of the normative range, or the FloatProblem's Sigma times the
+// width of the bounds if the range has collapsed. Values equal to Best take a
+// normal step instead. Values are clipped to the FloatProblem's bounds.
+func (bs *BeliefSpace) Influence(v *FloatVector, sigma float64, rng *rand.Rand) {
+	var p = v.Problem
+	for i := range v.Values {
+		var scale = sigma * (bs.Upper[i] - bs.Lower[i])
+		if scale <= 0 {
+			scale = p.Sigma * (p.Upper[i] - p.Lower[i])
+		}
+		var step = rng.NormFloat64() * scale
+		switch {
+		case v.Values[i] < bs.Best[i]:
+			v.Values[i] += math.Abs(step)
+		case v.Values[i] > bs.Best[i]:
+			v.Values[i] -= math.Abs(step)
+		default:
+			v.Values[i] += step
+		}
+		if v.Values[i] < p.Lower[i] {
+			v.Values[i] = p.Lower[i]
+		} else if v.Values[i] > p.Upper[i] {
+			v.Values[i] = p.Upper[i]
+		}
+	}
+}
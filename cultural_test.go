@@ -0,0 +1,148 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBeliefSpaceAccept(t *testing.T) {
+	var (
+		p    = newTestFloatProblem()
+		indi = func(fitness float64, values ...float64) Individual {
+			return Individual{Genome: &FloatVector{Values: values, Problem: p}, Fitness: fitness, Evaluated: true}
+		}
+		bs BeliefSpace
+	)
+	var err = bs.Accept(Individuals{
+		indi(3, 1, 2, 3),
+		indi(2, 0, 4, 3),
+		indi(math.Inf(1), -5, -5, -5),
+		{Genome: &FloatVector{Values: []float64{5, 5, 5}, Problem: p}},
+		{Genome: &FloatVector{Values: []float64{5, 5, 5}, Problem: p}, Fitness: 0, Violation: 1, Evaluated: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = BeliefSpace{
+		Lower:        []float64{0, 2, 3},
+		Upper:        []float64{1, 4, 3},
+		LowerFitness: []float64{2, 3, 2},
+		UpperFitness: []float64{3, 2, 2},
+		Best:         []float64{0, 4, 3},
+		BestFitness:  2,
+		Accepted:     2,
+	}
+	if !reflect.DeepEqual(bs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, bs)
+	}
+	// A fitter Individual inside the range moves the bounds it beats
+	if err = bs.Accept(Individuals{indi(1, 0.5, 3, 3)}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bs.Lower, []float64{0.5, 3, 3}) || !reflect.DeepEqual(bs.Upper, []float64{0.5, 3, 3}) {
+		t.Errorf("Expected the range to shrink around [0.5 3 3], got %v and %v", bs.Lower, bs.Upper)
+	}
+	if bs.BestFitness != 1 || bs.Accepted != 3 {
+		t.Errorf("Expected the best fitness to be 1 after 3 acceptances, got %+v", bs)
+	}
+	// The encoded BeliefSpace is restored along with the Population
+	var pop = Population{ID: "pop", Beliefs: &bs}
+	var b []byte
+	if b, err = json.Marshal(pop); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Population
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Beliefs, &bs) {
+		t.Errorf("Expected %+v, got %+v", bs, decoded.Beliefs)
+	}
+	// Errors
+	if err = bs.Accept(Individuals{indi(0, 1, 2)}); err == nil {
+		t.Error("Expected an error for a FloatVector of the wrong length")
+	}
+	if err = bs.Accept(Individuals{{Genome: Vector{1, 2, 3}, Evaluated: true}}); err == nil {
+		t.Error("Expected an error for a Genome which isn't a *FloatVector")
+	}
+}
+
+func TestBeliefSpaceInfluence(t *testing.T) {
+	var (
+		p   = newTestFloatProblem()
+		rng = rand.New(rand.NewSource(42))
+		bs  = BeliefSpace{
+			Lower: []float64{-1, -1, 0},
+			Upper: []float64{1, 1, 0},
+			Best:  []float64{0, 0, 0},
+		}
+	)
+	for i := 0; i < 100; i++ {
+		var v = &FloatVector{Values: []float64{-4, 4, 0}, Problem: p}
+		bs.Influence(v, 0.5, rng)
+		if v.Values[0] < -4 || v.Values[1] > 4 {
+			t.Fatalf("Expected the values to move towards the best vector, got %v", v.Values)
+		}
+		for j, x := range v.Values {
+			if x < p.Lower[j] || x > p.Upper[j] {
+				t.Fatalf("Expected the values to be within the bounds, got %v", v.Values)
+			}
+		}
+		// The collapsed range falls back on the FloatProblem's Sigma
+		if v.Values[2] == 0 {
+			t.Fatalf("Expected the value to move, got %v", v.Values)
+		}
+	}
+}
+
+func TestModCultural(t *testing.T) {
+	var (
+		p    = newTestFloatProblem()
+		conf = NewDefaultGAConfig()
+	)
+	conf.NGenerations = 20
+	conf.NPops = 2
+	conf.Speciator = SpecFitnessInterval{K: 2}
+	conf.Model = ModCultural{
+		Model: ModGenerational{
+			Selector:  SelTournament{NContestants: 3},
+			MutRate:   0.5,
+			CrossRate: 0.7,
+		},
+		AcceptRate: 0.2,
+		Influence:  0.5,
+		Sigma:      0.5,
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatal(err)
+	}
+	for _, pop := range ga.Populations {
+		var bs = pop.Beliefs
+		if bs == nil || bs.Accepted < uint64(conf.NGenerations) {
+			t.Fatalf("Expected the species to share the beliefs, got %+v", bs)
+		}
+		if f, _ := p.F(bs.Best); f != bs.BestFitness || f < ga.HallOfFame[0].Fitness {
+			t.Errorf("Expected the best belief to be consistent with the hall of fame, got %f", f)
+		}
+		for i := range bs.Lower {
+			if bs.Lower[i] > bs.Upper[i] {
+				t.Errorf("Expected a valid normative range, got %+v", bs)
+			}
+		}
+	}
+	if ga.HallOfFame[0].Fitness > 0.5 {
+		t.Errorf("Expected the sphere to be solved, got %f", ga.HallOfFame[0].Fitness)
+	}
+	// Genomes have to be *FloatVectors
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !wasm
+
+package eaopt
+
+// See GAConfig.SingleThreaded.
+const defaultSingleThreaded = false
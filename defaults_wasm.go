@@ -0,0 +1,4 @@
+package eaopt
+
+// WebAssembly runs on a single thread, see GAConfig.SingleThreaded.
+const defaultSingleThreaded = true
@@ -0,0 +1,265 @@
+package eaopt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+)
+
+// DiagnosticsOptions enable the monitoring of the GA's convergence, see
+// GA.Diagnose. The diversity of the Individuals is the average distance
+// between each Individual and the best Individual of its Population according
+// to Metric, or the standard deviation of the fitnesses if Metric is nil.
+// Window is the number of generations without improvement of the best fitness
+// after which the GA is considered to have stagnated, 0 means 10.
+type DiagnosticsOptions struct {
+	Metric Metric
+	Window uint
+}
+
+// window returns Window or its default value.
+func (do DiagnosticsOptions) window() uint {
+	if do.Window == 0 {
+		return 10
+	}
+	return do.Window
+}
+
+// A Diagnosis summarizes the convergence of a GA and suggests changes to its
+// configuration in Hints, whose Field names the GAConfig field or the part of
+// the Model concerned.
+//
+// DiversityLoss is the fraction of the initial diversity that has been lost
+// and Collapse is the first generation at which less than 1% of it remained,
+// 0 if it never happened. SelectionIntensity is the average realized
+// selection intensity, which is the improvement of the average fitness of the
+// Individuals from one generation to the next divided by the standard
+// deviation of the fitnesses; it is the selection intensity of the Model if
+// fitness is fully heritable. SuccessRate is the fraction of the offspring
+// produced by mutation and crossover that are better than the Individual they
+// were cloned from. Stagnation is the number of generations since the best
+// fitness last improved.
+type Diagnosis struct {
+	Generations        uint      `json:"generations"`
+	DiversityLoss      float64   `json:"diversity_loss"`
+	Collapse           uint      `json:"collapse"`
+	SelectionIntensity float64   `json:"selection_intensity"`
+	SuccessRate        float64   `json:"success_rate"`
+	Stagnation         uint      `json:"stagnation"`
+	Hints              []Warning `json:"hints"`
+}
+
+// diagnosticsState accumulates the measures of each generation. It is guarded
+// by a lock so that Diagnose can be called while the GA is being evolved.
+type diagnosticsState struct {
+	mutex              sync.Mutex
+	generations        uint
+	firstDiversity     float64
+	lastDiversity      float64
+	collapse           uint
+	best               float64
+	lastImprovement    uint
+	prevAvg, prevStd   float64
+	intensity          float64
+	nIntensity         int
+	offspring, success int
+}
+
+// reset forgets the measures of the previous generations.
+func (ds *diagnosticsState) reset() {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	ds.generations = 0
+	ds.offspring, ds.success = 0, 0
+	ds.intensity, ds.nIntensity = 0, 0
+}
+
+// inheritedFitnesses returns the fitness each offspring inherited from the
+// Individual it was cloned from, which is still stored in its Fitness field
+// because it hasn't been evaluated yet. Evaluated Individuals get a NaN.
+func inheritedFitnesses(indis Individuals) []float64 {
+	var inherited = make([]float64, len(indis))
+	for i, indi := range indis {
+		inherited[i] = math.NaN()
+		if !indi.Evaluated {
+			inherited[i] = indi.Fitness
+		}
+	}
+	return inherited
+}
+
+// countSuccesses returns the number of offspring and the number of them that
+// are better than the fitness they inherited. indis has to be evaluated but
+// not sorted yet.
+func countSuccesses(indis Individuals, inherited []float64) [2]int {
+	var counts [2]int
+	for i, f := range inherited {
+		if math.IsNaN(f) {
+			continue
+		}
+		counts[0]++
+		if indis[i].Fitness < f {
+			counts[1]++
+		}
+	}
+	return counts
+}
+
+// diversity measures the diversity of the GA's Individuals, see
+// DiagnosticsOptions.
+func (ga *GA) diversity() float64 {
+	if ga.Diagnostics.Metric == nil {
+		var fitnesses []float64
+		for _, pop := range ga.Populations {
+			for _, indi := range pop.Individuals {
+				fitnesses = append(fitnesses, indi.Fitness)
+			}
+		}
+		return NewFitnessStats(fitnesses, SkipNonFinite).Std
+	}
+	var (
+		sum float64
+		n   int
+	)
+	for _, pop := range ga.Populations {
+		// The Populations are sorted, hence their first Individual is their best
+		for _, indi := range pop.Individuals[1:] {
+			sum += ga.Diagnostics.Metric(pop.Individuals[0], indi)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// recordDiagnostics accounts for the current generation if the Diagnostics
+// option is set. counts holds the number of offspring and successes of each
+// Population, see countSuccesses.
+func (ga *GA) recordDiagnostics(counts [][2]int) {
+	if ga.Diagnostics == nil || len(ga.Populations) == 0 {
+		return
+	}
+	var fitnesses []float64
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			fitnesses = append(fitnesses, indi.Fitness)
+		}
+	}
+	var (
+		fs        = NewFitnessStats(fitnesses, SkipNonFinite)
+		diversity = ga.diversity()
+		ds        = ga.diagnostics
+	)
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	for _, c := range counts {
+		ds.offspring += c[0]
+		ds.success += c[1]
+	}
+	if ds.generations == 0 {
+		ds.firstDiversity = diversity
+		ds.collapse = 0
+		ds.best = ga.best().Fitness
+		ds.lastImprovement = ga.Generations
+	} else {
+		if ds.prevStd > 0 && !math.IsNaN(fs.Avg) && !math.IsNaN(ds.prevAvg) {
+			ds.intensity += (ds.prevAvg - fs.Avg) / ds.prevStd
+			ds.nIntensity++
+		}
+		if ds.collapse == 0 && diversity < 0.01*ds.firstDiversity {
+			ds.collapse = ga.Generations
+		}
+		if best := ga.best().Fitness; best < ds.best {
+			ds.best = best
+			ds.lastImprovement = ga.Generations
+		}
+	}
+	ds.lastDiversity = diversity
+	ds.prevAvg, ds.prevStd = fs.Avg, fs.Std
+	ds.generations++
+}
+
+// Diagnose analyzes the measures recorded since the GA was initialized and
+// returns hints for configurations that converge prematurely, stagnate or
+// waste evaluations. It is empty unless the Diagnostics field of the GAConfig
+// is set. It is safe to call concurrently with Minimize. The hints are
+// heuristics, they point at what to try first rather than at what is wrong.
+func (ga *GA) Diagnose() Diagnosis {
+	if ga.Diagnostics == nil || ga.diagnostics == nil {
+		return Diagnosis{}
+	}
+	var ds = ga.diagnostics
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	var d = Diagnosis{
+		Generations: ga.Generations,
+		Collapse:    ds.collapse,
+		Stagnation:  ga.Generations - ds.lastImprovement,
+	}
+	if ds.generations == 0 {
+		return d
+	}
+	if ds.firstDiversity > 0 {
+		d.DiversityLoss = 1 - ds.lastDiversity/ds.firstDiversity
+	}
+	if ds.nIntensity > 0 {
+		d.SelectionIntensity = ds.intensity / float64(ds.nIntensity)
+	}
+	if ds.offspring > 0 {
+		d.SuccessRate = float64(ds.success) / float64(ds.offspring)
+	}
+	var (
+		hint = func(field, format string, args ...interface{}) {
+			d.Hints = append(d.Hints, Warning{Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+		stagnated = d.Stagnation >= ga.Diagnostics.window()
+	)
+	if d.Collapse > 0 && stagnated {
+		hint("MutRate", "diversity collapsed at generation %d and the best fitness hasn't improved for %d generations, increase MutRate",
+			d.Collapse, d.Stagnation)
+		if ga.PopSize < 50 {
+			hint("PopSize", "the population is too small to maintain diversity, increase PopSize")
+		}
+		if ga.NPops > 1 && ga.Migrator != nil {
+			hint("MigFrequency", "migrations may homogenize the populations, migrate less often by increasing MigFrequency")
+		}
+	}
+	if d.SelectionIntensity > 1 && d.Collapse > 0 {
+		hint("Model", "the selection intensity is %.2f, lower the selection pressure, for instance with fewer tournament contestants",
+			d.SelectionIntensity)
+	}
+	if stagnated && d.Collapse == 0 && d.DiversityLoss < 0.1 && d.SelectionIntensity < 0.05 {
+		hint("Model", "the population keeps its diversity but doesn't improve, raise the selection pressure")
+	}
+	if ds.offspring > 0 && d.SuccessRate < 0.05 {
+		hint("MutRate", "only %.1f%% of the offspring improve on their parent, mutations may be too disruptive, decrease MutRate or the mutation step",
+			100*d.SuccessRate)
+	} else if d.SuccessRate > 0.5 {
+		hint("MutRate", "%.1f%% of the offspring improve on their parent, larger mutation steps may speed up the search",
+			100*d.SuccessRate)
+	}
+	return d
+}
+
+// logDiagnosis records the hints of Diagnose with the GA's loggers.
+func (ga *GA) logDiagnosis() {
+	if ga.Diagnostics == nil || (ga.Logger == nil && ga.SLogger == nil) {
+		return
+	}
+	var d = ga.Diagnose()
+	for _, h := range d.Hints {
+		if ga.Logger != nil {
+			ga.Logger.Printf("diagnosis field=%s hint=%q", h.Field, h.Message)
+		}
+		if ga.SLogger != nil {
+			ga.SLogger.LogAttrs(context.Background(), slog.LevelWarn, "diagnosis",
+				slog.Uint64("generation", uint64(d.Generations)),
+				slog.String("field", h.Field),
+				slog.String("hint", h.Message))
+		}
+	}
+}
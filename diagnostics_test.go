@@ -0,0 +1,109 @@
+package eaopt
+
+import (
+	"bytes"
+	"log"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// A frozenVector is a Vector whose operators do nothing, hence a GA evolving
+// frozenVectors converges at once.
+type frozenVector struct{ Vector }
+
+func (fv frozenVector) Mutate(rng *rand.Rand)              {}
+func (fv frozenVector) Crossover(y Genome, rng *rand.Rand) {}
+func (fv frozenVector) Clone() Genome                      { return frozenVector{fv.Vector.Clone().(Vector)} }
+
+func newFrozenVector(rng *rand.Rand) Genome {
+	return frozenVector{NewVector(rng).(Vector)}
+}
+
+// hintFields returns the Fields of the hints of a Diagnosis.
+func hintFields(d Diagnosis) map[string]bool {
+	var fields = make(map[string]bool)
+	for _, h := range d.Hints {
+		fields[h.Field] = true
+	}
+	return fields
+}
+
+func TestDiagnosePrematureConvergence(t *testing.T) {
+	var (
+		conf = NewDefaultGAConfig()
+		buf  bytes.Buffer
+	)
+	conf.PopSize = 10
+	conf.NGenerations = 20
+	conf.Diagnostics = &DiagnosticsOptions{}
+	conf.Logger = log.New(&buf, "", 0)
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(newFrozenVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var d = ga.Diagnose()
+	if d.Collapse == 0 || d.DiversityLoss < 0.99 || d.Stagnation < 10 {
+		t.Errorf("Expected a collapse, got %+v", d)
+	}
+	if d.SuccessRate != 0 {
+		t.Errorf("Expected no successful offspring, got %f", d.SuccessRate)
+	}
+	var fields = hintFields(d)
+	if !fields["MutRate"] || !fields["PopSize"] {
+		t.Errorf("Expected MutRate and PopSize hints, got %v", d.Hints)
+	}
+	// The hints are logged at the end of the run
+	if !strings.Contains(buf.String(), "diagnosis field=PopSize") {
+		t.Errorf("The hints weren't logged:\n%s", buf.String())
+	}
+}
+
+func TestDiagnoseHealthyRun(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.PopSize = 50
+	conf.Diagnostics = &DiagnosticsOptions{Metric: l1Distance}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var d = ga.Diagnose()
+	if d.Generations != conf.NGenerations || d.Collapse != 0 || d.Stagnation > 5 {
+		t.Errorf("Expected the GA to keep improving, got %+v", d)
+	}
+	if d.SuccessRate <= 0 || d.SelectionIntensity <= 0 {
+		t.Errorf("Expected positive rates, got %+v", d)
+	}
+	if fields := hintFields(d); fields["PopSize"] {
+		t.Errorf("Unexpected hints %v", d.Hints)
+	}
+	// Nothing is recorded without the option
+	conf.Diagnostics = nil
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if d = ga.Diagnose(); d.Generations != 0 || d.Hints != nil {
+		t.Errorf("Expected an empty Diagnosis, got %+v", d)
+	}
+}
+
+func TestCountSuccesses(t *testing.T) {
+	var indis = Individuals{
+		{Fitness: 3, Evaluated: false},
+		{Fitness: 1, Evaluated: true},
+		{Fitness: 5, Evaluated: false},
+	}
+	var inherited = inheritedFitnesses(indis)
+	indis[0].Fitness = 2
+	indis[2].Fitness = 6
+	if counts := countSuccesses(indis, inherited); counts != [2]int{2, 1} {
+		t.Errorf("Expected [2 1], got %v", counts)
+	}
+}
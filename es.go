@@ -0,0 +1,109 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// A ScaledMutator is a Genome whose mutation strength can be scaled, scale 1
+// being the strength of Mutate. Genomes which don't implement it are mutated
+// round(scale) times in a row, at least once, by the single-solution
+// optimizers, see OnePlusOneES.
+type ScaledMutator interface {
+	MutateScaled(scale float64, rng *rand.Rand)
+}
+
+// mutateScaled mutates genome with a strength of scale.
+func mutateScaled(genome Genome, scale float64, rng *rand.Rand) {
+	if sm, ok := genome.(ScaledMutator); ok {
+		sm.MutateScaled(scale, rng)
+		return
+	}
+	for i := 0; i < int(math.Max(1, math.Round(scale))); i++ {
+		genome.Mutate(rng)
+	}
+}
+
+// OnePlusOneES is a (1+1) evolution strategy, the simplest of single-solution
+// optimizers: at each of the NSteps steps the current solution is cloned and
+// mutated with a strength of Scale, and the mutant replaces the current
+// solution if it is at least as good. Every Window steps the strength is
+// adapted with Rechenberg's 1/5 success rule: it is divided by Factor if more
+// than a fifth of the mutants were strictly better than their parent and
+// multiplied by Factor if less than a fifth were. The mutation machinery of
+// the Genome is reused, see ScaledMutator, hence OnePlusOneES is a cheap
+// baseline as well as a way to fine-tune the hall of fame after a GA run.
+type OnePlusOneES struct {
+	NSteps uint    // Number of mutants evaluated
+	Scale  float64 // Initial mutation strength, 1 if 0
+	Window uint    // Number of steps between two adaptations of the strength, 10 if 0
+	Factor float64 // Adaptation factor in (0, 1), 0.85 if 0
+}
+
+// Validate OnePlusOneES fields.
+func (es OnePlusOneES) Validate() error {
+	if es.NSteps == 0 {
+		return errors.New("NSteps should be higher than 0")
+	}
+	if es.Scale < 0 || math.IsNaN(es.Scale) {
+		return errors.New("Scale should be positive")
+	}
+	if es.Factor < 0 || es.Factor >= 1 || math.IsNaN(es.Factor) {
+		return errors.New("Factor should be in (0, 1)")
+	}
+	return nil
+}
+
+// Improve runs the (1+1)-ES from genome, which isn't modified, and returns the
+// best Individual found. rng may be nil.
+func (es OnePlusOneES) Improve(genome Genome, rng *rand.Rand) (Individual, error) {
+	if err := es.Validate(); err != nil {
+		return Individual{}, err
+	}
+	if rng == nil {
+		rng = newRand()
+	}
+	var (
+		scale     = es.Scale
+		window    = es.Window
+		factor    = es.Factor
+		successes uint
+		current   = NewIndividual(genome.Clone(), rng)
+	)
+	if scale == 0 {
+		scale = 1
+	}
+	if window == 0 {
+		window = 10
+	}
+	if factor == 0 {
+		factor = 0.85
+	}
+	if err := current.Evaluate(); err != nil {
+		return Individual{}, err
+	}
+	for step := uint(1); step <= es.NSteps; step++ {
+		var mutant = NewIndividual(current.Genome.Clone(), rng)
+		mutateScaled(mutant.Genome, scale, rng)
+		if err := mutant.Evaluate(); err != nil {
+			return Individual{}, err
+		}
+		if mutant.Better(current) {
+			successes++
+		}
+		if !current.Better(mutant) && !math.IsNaN(mutant.Fitness) {
+			current = mutant
+		}
+		if step%window == 0 {
+			switch rate := float64(successes) / float64(window); {
+			case rate > 0.2:
+				scale /= factor
+			case rate < 0.2:
+				scale *= factor
+			}
+			successes = 0
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,88 @@
+package eaopt
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// countingGenome counts the number of times it is mutated.
+type countingGenome struct{ mutations *int }
+
+func (cg countingGenome) Evaluate() (float64, error)         { return 0, nil }
+func (cg countingGenome) Mutate(rng *rand.Rand)              { *cg.mutations++ }
+func (cg countingGenome) Crossover(y Genome, rng *rand.Rand) {}
+func (cg countingGenome) Clone() Genome                      { return cg }
+
+func TestMutateScaled(t *testing.T) {
+	var testCases = []struct {
+		scale     float64
+		mutations int
+	}{
+		{0.2, 1},
+		{1, 1},
+		{2.6, 3},
+	}
+	for i, tc := range testCases {
+		var cg = countingGenome{new(int)}
+		mutateScaled(cg, tc.scale, newRand())
+		if *cg.mutations != tc.mutations {
+			t.Errorf("Error in test case number %d: expected %d mutations, got %d", i, tc.mutations, *cg.mutations)
+		}
+	}
+	// FloatVectors scale the standard deviation
+	var (
+		p    = newTestFloatProblem()
+		a    = &FloatVector{Values: []float64{0, 0, 0}, Problem: p}
+		b    = &FloatVector{Values: []float64{0, 0, 0}, Problem: p}
+		rngA = rand.New(rand.NewSource(42))
+		rngB = rand.New(rand.NewSource(42))
+	)
+	p.MutRate = 1
+	a.Mutate(rngA)
+	mutateScaled(b, 0.5, rngB)
+	for i := range a.Values {
+		if b.Values[i] != a.Values[i]/2 {
+			t.Errorf("Expected %v halved, got %v", a.Values, b.Values)
+		}
+	}
+}
+
+func TestOnePlusOneES(t *testing.T) {
+	var (
+		p     = newTestFloatProblem()
+		start = &FloatVector{Values: []float64{4, 4, 4}, Problem: p}
+		rng   = rand.New(rand.NewSource(42))
+	)
+	p.MutRate = 1
+	var best, err = OnePlusOneES{NSteps: 500}.Improve(start, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(start.Values, []float64{4, 4, 4}) {
+		t.Errorf("The starting Genome shouldn't be modified, got %v", start.Values)
+	}
+	if !best.Evaluated || best.Fitness > 1e-3 {
+		t.Errorf("Expected the sphere to be solved, got %v", best)
+	}
+	// Genomes which aren't ScaledMutators work too
+	if best, err = (OnePlusOneES{NSteps: 50, Scale: 2}).Improve(Vector{1, 2, 3}, rng); err != nil {
+		t.Fatal(err)
+	}
+	if best.Fitness >= 6 {
+		t.Errorf("Expected an improvement over 6, got %f", best.Fitness)
+	}
+	// Errors
+	if _, err = (OnePlusOneES{NSteps: 10}).Improve(ErrorGenome{}, rng); err == nil {
+		t.Error("Expected an error")
+	}
+	for _, es := range []OnePlusOneES{
+		{},
+		{NSteps: 1, Scale: -1},
+		{NSteps: 1, Factor: 1},
+	} {
+		if _, err = es.Improve(start, nil); err == nil {
+			t.Errorf("Expected %+v to be invalid", es)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package eaopt
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// An evalBatch holds the Individuals of a Population waiting to be evaluated
+// by an evalQueue.
+type evalBatch struct {
+	indis    Individuals
+	pending  []int     // Indexes of the Individuals left to hand out
+	deadline time.Time // Individuals aren't handed out past it, see GAConfig.RealTime
+	done     sync.WaitGroup
+	err      error // First evaluation error, guarded by the evalQueue's mutex
+}
+
+// An evalQueue evaluates the Individuals of several Populations with a single
+// set of workers, one per CPU. Each Population submits its unevaluated
+// Individuals as a batch and idle workers take the next Individual of the
+// batch with the most work left, hence workers steal work from the largest
+// batches instead of idling when the Populations differ in size or in
+// evaluation cost. The evaluation budget is enforced by Individual.Evaluate.
+type evalQueue struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	batches []*evalBatch
+	closed  bool
+}
+
+// newEvalQueue returns a running evalQueue if the GA evaluates the Individuals
+// in parallel and has several Populations, else nil. It has to be closed.
+func (ga *GA) newEvalQueue() *evalQueue {
+	if !ga.ParallelEval || len(ga.Populations) < 2 {
+		return nil
+	}
+	var q = new(evalQueue)
+	q.cond = sync.NewCond(&q.mutex)
+	for w := 0; w < runtime.GOMAXPROCS(-1); w++ {
+		go q.work()
+	}
+	return q
+}
+
+// close stops the workers once the batches have been handed out. It doesn't
+// do anything if q is nil.
+func (q *evalQueue) close() {
+	if q == nil {
+		return
+	}
+	q.mutex.Lock()
+	q.closed = true
+	q.mutex.Unlock()
+	q.cond.Broadcast()
+}
+
+// evaluate evaluates indis, either through q or, if q is nil, directly.
+func (q *evalQueue) evaluate(indis Individuals, parallel bool) error {
+	return q.evaluateBy(indis, parallel, time.Time{})
+}
+
+// evaluateBy evaluates indis like evaluate, except that no evaluation starts
+// once deadline has passed, unless it is zero.
+func (q *evalQueue) evaluateBy(indis Individuals, parallel bool, deadline time.Time) error {
+	if q == nil {
+		if deadline.IsZero() {
+			return indis.Evaluate(parallel)
+		}
+		return indis.evaluateBy(parallel, deadline)
+	}
+	var b = &evalBatch{indis: indis, deadline: deadline}
+	for i, indi := range indis {
+		if !indi.Evaluated {
+			b.pending = append(b.pending, i)
+		}
+	}
+	if len(b.pending) == 0 {
+		return nil
+	}
+	b.done.Add(len(b.pending))
+	q.mutex.Lock()
+	q.batches = append(q.batches, b)
+	q.mutex.Unlock()
+	q.cond.Broadcast()
+	b.done.Wait()
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return b.err
+}
+
+// take waits for an Individual to evaluate and returns its batch and index,
+// the index being -1 if the rest of the batch has to be skipped. The batch is
+// nil once q is closed and empty.
+func (q *evalQueue) take() (*evalBatch, int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for {
+		var largest = -1
+		for i, b := range q.batches {
+			if largest < 0 || len(b.pending) > len(q.batches[largest].pending) {
+				largest = i
+			}
+		}
+		if largest >= 0 {
+			var (
+				b = q.batches[largest]
+				k = b.pending[0]
+			)
+			b.pending = b.pending[1:]
+			if len(b.pending) == 0 {
+				q.batches = append(q.batches[:largest], q.batches[largest+1:]...)
+			}
+			if b.err != nil || (!b.deadline.IsZero() && time.Now().After(b.deadline)) {
+				return b, -1
+			}
+			return b, k
+		}
+		if q.closed {
+			return nil, 0
+		}
+		q.cond.Wait()
+	}
+}
+
+// work evaluates Individuals until q is closed.
+func (q *evalQueue) work() {
+	for {
+		var b, k = q.take()
+		if b == nil {
+			return
+		}
+		// A negative index means that a previous evaluation of the batch
+		// failed or that its deadline has passed, the rest is skipped
+		if k >= 0 {
+			if err := b.indis[k].Evaluate(); err != nil {
+				q.mutex.Lock()
+				if b.err == nil {
+					b.err = err
+				}
+				q.mutex.Unlock()
+			}
+		}
+		b.done.Done()
+	}
+}
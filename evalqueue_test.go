@@ -0,0 +1,159 @@
+package eaopt
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestEvalQueue(t *testing.T) {
+	var (
+		rng = newRand()
+		ga  = &GA{GAConfig: GAConfig{ParallelEval: true}, Populations: make(Populations, 2)}
+		q   = ga.newEvalQueue()
+	)
+	if q == nil {
+		t.Fatal("Expected a queue")
+	}
+	defer q.close()
+	// Batches of different sizes are evaluated concurrently
+	var (
+		batches = []Individuals{
+			newIndividuals(1, false, NewVector, rng),
+			newIndividuals(50, false, NewVector, rng),
+			newIndividuals(7, false, NewVector, rng),
+		}
+		wg sync.WaitGroup
+	)
+	batches[2][3].Evaluated = true
+	batches[2][3].Fitness = 42
+	for _, indis := range batches {
+		wg.Add(1)
+		go func(indis Individuals) {
+			defer wg.Done()
+			if err := q.evaluate(indis, true); err != nil {
+				t.Errorf("Expected nil, got %v", err)
+			}
+		}(indis)
+	}
+	wg.Wait()
+	for _, indis := range batches {
+		for _, indi := range indis {
+			if f, _ := indi.Genome.Evaluate(); !indi.Evaluated || (f != indi.Fitness && indi.Fitness != 42) {
+				t.Errorf("Expected a fitness of %f, got %f", f, indi.Fitness)
+			}
+		}
+	}
+	// Errors are returned to the Population that submitted the batch
+	var indis = Individuals{NewIndividual(ErrorGenome{}, rng), NewIndividual(ErrorGenome{}, rng)}
+	if err := q.evaluate(indis, true); err == nil {
+		t.Error("Expected an error")
+	}
+	// There is no queue for a single Population
+	ga.Populations = ga.Populations[:1]
+	if ga.newEvalQueue() != nil {
+		t.Error("Expected no queue")
+	}
+}
+
+func TestEvalQueueBudget(t *testing.T) {
+	// The budget is enforced whether the Populations share the workers or not
+	var testCases = []struct {
+		nPops        uint
+		parallelEval bool
+	}{
+		{3, true},
+		{3, false},
+		{1, true},
+		{1, false},
+	}
+	for i, tc := range testCases {
+		var conf = NewDefaultGAConfig()
+		conf.NPops = tc.nPops
+		conf.PopSize = 40
+		conf.ParallelEval = tc.parallelEval
+		conf.MaxEvaluations = 50 * uint64(tc.nPops)
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatalf("TC %d: expected nil, got %v", i, err)
+		}
+		if err = ga.Minimize(NewVector); err != nil {
+			t.Fatalf("TC %d: expected nil, got %v", i, err)
+		}
+		if ga.Evaluations() != conf.MaxEvaluations || ga.Generations != 1 {
+			t.Errorf("TC %d: expected %d evaluations in 1 generation, got %d in %d", i, conf.MaxEvaluations,
+				ga.Evaluations(), ga.Generations)
+		}
+		var skipped uint64
+		for _, pop := range ga.Populations {
+			for _, indi := range pop.Individuals {
+				if indi.Skipped {
+					skipped++
+					if !math.IsInf(indi.Fitness, 1) || !indi.Evaluated {
+						t.Errorf("TC %d: a skipped Individual should rank last, got %v", i, indi)
+					}
+				}
+			}
+		}
+		if skipped == 0 {
+			t.Errorf("TC %d: expected the Individuals beyond the budget to be skipped", i)
+		}
+		for _, indi := range ga.HallOfFame {
+			if indi.Skipped {
+				t.Errorf("TC %d: the hall of fame contains a skipped Individual", i)
+			}
+		}
+		// Errors stop the GA
+		ga, _ = conf.NewGA()
+		if err = ga.Minimize(NewErrorGenome); err == nil {
+			t.Errorf("TC %d: expected an error", i)
+		}
+	}
+}
+
+func TestMaxEvaluationsModels(t *testing.T) {
+	// The models evaluate the offsprings themselves, the budget still holds
+	var testCases = []Model{
+		ModGenerational{Selector: SelTournament{NContestants: 3}, MutRate: 0.5, CrossRate: 0.7},
+		ModSteadyState{Selector: SelTournament{NContestants: 3}, KeepBest: true, MutRate: 0.5, CrossRate: 0.7},
+		ModDownToSize{NOffsprings: 5, SelectorA: SelTournament{NContestants: 3}, SelectorB: SelElitism{},
+			MutRate: 0.5, CrossRate: 0.7},
+		ModRing{Selector: SelTournament{NContestants: 3}, MutRate: 0.5},
+		ModMutationOnly{Strict: true},
+		ModMutationOnly{Strict: false},
+		ModSimulatedAnnealing{T0: 10, Cooling: 0.9},
+		ModInteractive{Selector: SelTournament{NContestants: 1}, MutRate: 0.5, CrossRate: 0.5, BatchSize: 4,
+			Rank: rankByPosition},
+		ModContiguous{Model: ModGenerational{Selector: SelTournament{NContestants: 3}, MutRate: 0.5, CrossRate: 0.7}},
+		ModTempering{Temperature: 1, MutRate: 0.5, CrossRate: 0.7, NMutations: 1},
+		ModCultural{Model: ModGenerational{Selector: SelTournament{NContestants: 3}, MutRate: 0.5, CrossRate: 0.7},
+			AcceptRate: 0.2, Influence: 0.5, Sigma: 0.5},
+	}
+	for i, model := range testCases {
+		for _, parallelEval := range []bool{false, true} {
+			t.Run(fmt.Sprintf("TC %d parallel %t", i, parallelEval), func(t *testing.T) {
+				var (
+					p    = newTestFloatProblem()
+					conf = NewDefaultGAConfig()
+				)
+				conf.NPops = 1
+				conf.PopSize = 10
+				conf.NGenerations = 20
+				conf.Model = model
+				conf.ParallelEval = parallelEval
+				conf.MaxEvaluations = 25
+				var ga, err = conf.NewGA()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err = ga.Minimize(p.NewGenome); err != nil {
+					t.Fatal(err)
+				}
+				if ga.Evaluations() > conf.MaxEvaluations {
+					t.Errorf("Expected at most %d evaluations, got %d", conf.MaxEvaluations, ga.Evaluations())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package eaopt
+
+import "sync"
+
+// A GenerationEndEvent is emitted once the GA has been initialized and at the
+// end of each generation, when the Callback is called.
+type GenerationEndEvent struct {
+	GA         *GA
+	Generation uint // 0 after the initialization
+}
+
+// A NewBestEvent is emitted when the best Individual of the hall of fame
+// changes. Best is shared with the hall of fame and shouldn't be modified.
+type NewBestEvent struct {
+	GA       *GA
+	Best     Individual
+	Previous float64 // Previous best fitness, +Inf during the initialization
+}
+
+// A MigrationEvent is emitted after the Migrator has been applied.
+// Duplicates is the number of Individuals the Migrator copied into a
+// Population which already held an Individual with the same ID, see
+// GAConfig.DedupMigrants.
+type MigrationEvent struct {
+	GA         *GA
+	Generation uint
+	Duplicates int
+}
+
+// A SpeciationEvent is emitted after the Speciator has been applied. Sizes
+// contains the number of Individuals in each species. Population is the index
+// of the Population that was speciated, or -1 with GlobalSpeciation.
+type SpeciationEvent struct {
+	GA         *GA
+	Generation uint
+	Population int
+	Sizes      []int
+}
+
+// A RestartEvent is emitted when the GA is initialized by Minimize or Init.
+// Resumed is true if the Populations were restored, for instance from JSON,
+// instead of being generated.
+type RestartEvent struct {
+	GA      *GA
+	Resumed bool
+}
+
+// A subscription is a handler registered with Events.
+type subscription struct {
+	id      uint64
+	handler interface{}
+}
+
+// Events delivers the lifecycle events of a GA to any number of subscribers.
+// Handlers are called synchronously, in the order in which they subscribed,
+// from the goroutine evolving the GA; they can subscribe and unsubscribe
+// handlers themselves. Each On method returns a function which cancels the
+// subscription.
+type Events struct {
+	mutex  sync.Mutex
+	nextID uint64
+	subs   map[string][]subscription
+}
+
+// subscribe registers handler for the given kind of events.
+func (ev *Events) subscribe(kind string, handler interface{}) func() {
+	ev.mutex.Lock()
+	defer ev.mutex.Unlock()
+	if ev.subs == nil {
+		ev.subs = make(map[string][]subscription)
+	}
+	ev.nextID++
+	var id = ev.nextID
+	ev.subs[kind] = append(ev.subs[kind], subscription{id: id, handler: handler})
+	return func() {
+		ev.mutex.Lock()
+		defer ev.mutex.Unlock()
+		var subs = ev.subs[kind]
+		for i, sub := range subs {
+			if sub.id == id {
+				ev.subs[kind] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// handlers returns a copy of the handlers subscribed to the given kind of
+// events so that they can be called without holding the lock. It returns nil
+// if ev is nil.
+func (ev *Events) handlers(kind string) []interface{} {
+	if ev == nil {
+		return nil
+	}
+	ev.mutex.Lock()
+	defer ev.mutex.Unlock()
+	var handlers = make([]interface{}, len(ev.subs[kind]))
+	for i, sub := range ev.subs[kind] {
+		handlers[i] = sub.handler
+	}
+	return handlers
+}
+
+// OnGenerationEnd subscribes f to GenerationEndEvents.
+func (ev *Events) OnGenerationEnd(f func(GenerationEndEvent)) func() {
+	return ev.subscribe("generation_end", f)
+}
+
+// OnNewBest subscribes f to NewBestEvents.
+func (ev *Events) OnNewBest(f func(NewBestEvent)) func() {
+	return ev.subscribe("new_best", f)
+}
+
+// OnMigration subscribes f to MigrationEvents.
+func (ev *Events) OnMigration(f func(MigrationEvent)) func() {
+	return ev.subscribe("migration", f)
+}
+
+// OnSpeciation subscribes f to SpeciationEvents.
+func (ev *Events) OnSpeciation(f func(SpeciationEvent)) func() {
+	return ev.subscribe("speciation", f)
+}
+
+// OnRestart subscribes f to RestartEvents.
+func (ev *Events) OnRestart(f func(RestartEvent)) func() {
+	return ev.subscribe("restart", f)
+}
+
+// The emit methods call the handlers subscribed to each kind of events, they
+// don't do anything if ev is nil.
+
+func (ev *Events) emitGenerationEnd(e GenerationEndEvent) {
+	for _, h := range ev.handlers("generation_end") {
+		h.(func(GenerationEndEvent))(e)
+	}
+}
+
+func (ev *Events) emitNewBest(e NewBestEvent) {
+	for _, h := range ev.handlers("new_best") {
+		h.(func(NewBestEvent))(e)
+	}
+}
+
+func (ev *Events) emitMigration(e MigrationEvent) {
+	for _, h := range ev.handlers("migration") {
+		h.(func(MigrationEvent))(e)
+	}
+}
+
+func (ev *Events) emitSpeciation(e SpeciationEvent) {
+	for _, h := range ev.handlers("speciation") {
+		h.(func(SpeciationEvent))(e)
+	}
+}
+
+func (ev *Events) emitRestart(e RestartEvent) {
+	for _, h := range ev.handlers("restart") {
+		h.(func(RestartEvent))(e)
+	}
+}
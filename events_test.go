@@ -0,0 +1,105 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEventsSubscribers(t *testing.T) {
+	var (
+		ev     = new(Events)
+		counts [2]int
+	)
+	var cancel = ev.OnGenerationEnd(func(e GenerationEndEvent) { counts[0]++ })
+	ev.OnGenerationEnd(func(e GenerationEndEvent) { counts[1]++ })
+	ev.emitGenerationEnd(GenerationEndEvent{})
+	cancel()
+	ev.emitGenerationEnd(GenerationEndEvent{})
+	if counts != [2]int{1, 2} {
+		t.Errorf("Expected [1 2], got %v", counts)
+	}
+	// Emitting on nil Events doesn't do anything
+	var nilEvents *Events
+	nilEvents.emitNewBest(NewBestEvent{})
+}
+
+func TestEventsUnsubscribeWhileEmitting(t *testing.T) {
+	var (
+		ev     = new(Events)
+		calls  int
+		cancel func()
+	)
+	cancel = ev.OnMigration(func(e MigrationEvent) {
+		calls++
+		cancel()
+	})
+	ev.emitMigration(MigrationEvent{})
+	ev.emitMigration(MigrationEvent{})
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestGAEvents(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 6
+	conf.Migrator = MigRing{NMigrants: 2}
+	conf.MigFrequency = 3
+	conf.Speciator = SpecFitnessInterval{K: 2}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var (
+		generations []uint
+		bests       []float64
+		migrations  int
+		speciations = make(map[int]int)
+		restarts    []bool
+	)
+	ga.Events.OnGenerationEnd(func(e GenerationEndEvent) { generations = append(generations, e.Generation) })
+	ga.Events.OnNewBest(func(e NewBestEvent) {
+		if e.Best.Fitness >= e.Previous {
+			t.Errorf("%f isn't better than %f", e.Best.Fitness, e.Previous)
+		}
+		bests = append(bests, e.Best.Fitness)
+	})
+	ga.Events.OnMigration(func(e MigrationEvent) { migrations++ })
+	ga.Events.OnSpeciation(func(e SpeciationEvent) {
+		if len(e.Sizes) != 2 || e.Sizes[0]+e.Sizes[1] != int(conf.PopSize) {
+			t.Errorf("Unexpected species sizes %v", e.Sizes)
+		}
+		speciations[e.Population]++
+	})
+	ga.Events.OnRestart(func(e RestartEvent) { restarts = append(restarts, e.Resumed) })
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(generations) != 7 || generations[0] != 0 || generations[6] != 6 {
+		t.Errorf("Unexpected generations %v", generations)
+	}
+	if len(bests) == 0 || bests[len(bests)-1] != ga.HallOfFame[0].Fitness {
+		t.Errorf("Unexpected bests %v", bests)
+	}
+	if migrations != 2 {
+		t.Errorf("Expected 2 migrations, got %d", migrations)
+	}
+	if speciations[0] != 6 || speciations[1] != 6 {
+		t.Errorf("Expected 6 speciations per population, got %v", speciations)
+	}
+	// Resuming the GA emits a RestartEvent but no NewBestEvent
+	var n = len(bests)
+	if err = ga.init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(restarts) != 2 || restarts[0] || !restarts[1] {
+		t.Errorf("Expected [false true], got %v", restarts)
+	}
+	if len(bests) != n {
+		t.Error("Resuming the GA shouldn't emit a NewBestEvent")
+	}
+	if math.IsInf(ga.HallOfFame[0].Fitness, 1) {
+		t.Error("The hall of fame should have been kept")
+	}
+}
@@ -0,0 +1,86 @@
+// Command dashboard minimizes the Drop-Wave function with a slowed down GA and
+// streams each generation's statistics to a browser through a WSPublisher.
+// Run it and open http://localhost:8080 to watch the convergence live.
+package main
+
+import (
+	_ "embed"
+	"log"
+	m "math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/matthewmcneely/eaopt"
+)
+
+//go:embed index.html
+var index []byte
+
+// A Vector contains float64s.
+type Vector []float64
+
+// Evaluate a Vector with the Drop-Wave function.
+func (X Vector) Evaluate() (float64, error) {
+	var (
+		numerator   = 1 + m.Cos(12*m.Sqrt(m.Pow(X[0], 2)+m.Pow(X[1], 2)))
+		denominator = 0.5*(m.Pow(X[0], 2)+m.Pow(X[1], 2)) + 2
+	)
+	return -numerator / denominator, nil
+}
+
+// Mutate a Vector by resampling each element from a normal distribution.
+func (X Vector) Mutate(rng *rand.Rand) {
+	eaopt.MutNormalFloat64(X, 0.8, rng)
+}
+
+// Crossover a Vector with another Vector by applying uniform crossover.
+func (X Vector) Crossover(Y eaopt.Genome, rng *rand.Rand) {
+	eaopt.CrossUniformFloat64(X, Y.(Vector), rng)
+}
+
+// Clone a Vector to produce a new one that points to a different slice.
+func (X Vector) Clone() eaopt.Genome {
+	var Y = make(Vector, len(X))
+	copy(Y, X)
+	return Y
+}
+
+// VectorFactory returns a random vector.
+func VectorFactory(rng *rand.Rand) eaopt.Genome {
+	return Vector(eaopt.InitUnifFloat64(2, -10, 10, rng))
+}
+
+func main() {
+	var pub = eaopt.NewWSPublisher()
+	http.Handle("/ws", pub)
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(index)
+	})
+	go func() {
+		log.Fatal(http.ListenAndServe(":8080", nil))
+	}()
+
+	var conf = eaopt.NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 300
+	var ga, err = conf.NewGA()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Slow down the GA so that the convergence can be seen
+	ga.Callback = func(ga *eaopt.GA) {
+		pub.Callback(ga)
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Give some time to open the browser
+	log.Println("open http://localhost:8080, starting in 5 seconds")
+	time.Sleep(5 * time.Second)
+	if err = ga.Minimize(VectorFactory); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("best fitness: %f, best genome: %v", ga.HallOfFame[0].Fitness, ga.HallOfFame[0].Genome)
+	// Keep serving so the final state stays visible
+	select {}
+}
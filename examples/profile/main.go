@@ -0,0 +1,93 @@
+// Command profile runs a GA on the Rastrigin function and writes CPU and heap
+// profiles which can be inspected with go tool pprof. It is meant to measure
+// the cost of selection, crossover and evaluation at a given population size.
+//
+//	go run ./examples/profile -popsize 1000 -cpuprofile cpu.out -memprofile mem.out
+//	go tool pprof cpu.out
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/matthewmcneely/eaopt"
+)
+
+var (
+	cpuProfile  = flag.String("cpuprofile", "", "write a CPU profile to this file")
+	memProfile  = flag.String("memprofile", "", "write a heap profile to this file")
+	popSize     = flag.Uint("popsize", 1000, "number of individuals per population")
+	nPops       = flag.Uint("npops", 1, "number of populations")
+	generations = flag.Uint("generations", 100, "number of generations")
+	dimensions  = flag.Int("dimensions", 10, "number of dimensions of the Rastrigin function")
+	parallel    = flag.Bool("parallel", false, "evaluate individuals in parallel")
+	contiguous  = flag.Bool("contiguous", false, "store each population in a single matrix")
+	seed        = flag.Int64("seed", 42, "seed of the random number generator")
+)
+
+func main() {
+	flag.Parse()
+
+	var problem = &eaopt.FloatProblem{
+		Lower:   make([]float64, *dimensions),
+		Upper:   make([]float64, *dimensions),
+		MutRate: 0.2,
+		Sigma:   0.1,
+		F: func(x []float64) (float64, error) {
+			return eaopt.Rastrigin(x), nil
+		},
+	}
+	for i := range problem.Lower {
+		problem.Lower[i], problem.Upper[i] = -5.12, 5.12
+	}
+
+	var conf = eaopt.NewDefaultGAConfig()
+	conf.NPops = *nPops
+	conf.PopSize = *popSize
+	conf.NGenerations = *generations
+	conf.ParallelEval = *parallel
+	conf.RNG = rand.New(rand.NewSource(*seed))
+	if *contiguous {
+		conf.Model = eaopt.ModContiguous{Model: conf.Model}
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *cpuProfile != "" {
+		var f, err = os.Create(*cpuProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err = pprof.StartCPUProfile(f); err != nil {
+			log.Fatal(err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var start = time.Now()
+	if err = ga.Minimize(problem.NewGenome); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Best fitness %f found in %s\n", ga.HallOfFame[0].Fitness, time.Since(start))
+
+	if *memProfile != "" {
+		var f, err = os.Create(*memProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		runtime.GC()
+		if err = pprof.WriteHeapProfile(f); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// A FloatMatrix stores Rows vectors of Cols float64s contiguously in Data, in
+// row-major order. A single allocation is friendlier to the CPU cache than one
+// slice per vector and the matrix can be handed as is to BLAS routines or GPU
+// batch evaluators.
+type FloatMatrix struct {
+	Data []float64
+	Rows int
+	Cols int
+}
+
+// NewFloatMatrix returns a FloatMatrix of rows vectors of cols zeros.
+func NewFloatMatrix(rows, cols int) FloatMatrix {
+	return FloatMatrix{
+		Data: make([]float64, rows*cols),
+		Rows: rows,
+		Cols: cols,
+	}
+}
+
+// Row returns a view on the i-th row of the FloatMatrix. Its capacity is
+// limited to the row so that appending to it doesn't overwrite the next row.
+func (m FloatMatrix) Row(i int) []float64 {
+	return m.Data[i*m.Cols : (i+1)*m.Cols : (i+1)*m.Cols]
+}
+
+// A FloatVector is a vector of float64s where each position is bounded. The
+// bounds and the operator settings are shared through the FloatProblem the
+// FloatVector belongs to. Values may be a view on a row of a FloatMatrix, see
+// PackFloatVectors.
+type FloatVector struct {
+	Values  []float64
+	Problem *FloatProblem
+}
+
+// Evaluate the FloatVector with the FloatProblem's objective function. The
+// integer dimensions, if any, are rounded beforehand.
+func (v *FloatVector) Evaluate() (float64, error) {
+	return v.Problem.evaluate(v.Values)
+}
+
+// Floats returns the Values, which implements the FloatGenome interface.
+func (v *FloatVector) Floats() []float64 {
+	return v.Values
+}
+
+// Bounds returns the bounds of the FloatProblem.
+func (v *FloatVector) Bounds() (lower, upper []float64) {
+	return v.Problem.Lower, v.Problem.Upper
+}
+
+// Rounded returns a copy of the Values whose integer dimensions are rounded,
+// see IntegerDims.Report, which is how a solution should be reported.
+func (v *FloatVector) Rounded() []float64 {
+	if v.Problem.Integers != nil {
+		return v.Problem.Integers.Report(v.Values)
+	}
+	return copyFloat64s(v.Values)
+}
+
+// Mutate the FloatVector. Each gene is mutated with probability MutRate by
+// adding normally distributed noise with a standard deviation of Sigma times
+// the width of its bounds. Genes are then clipped to their bounds.
+func (v *FloatVector) Mutate(rng *rand.Rand) {
+	v.MutateScaled(1, rng)
+}
+
+// MutateScaled mutates the FloatVector as Mutate does with a standard
+// deviation of scale * Sigma times the width of the bounds.
+func (v *FloatVector) MutateScaled(scale float64, rng *rand.Rand) {
+	var p = v.Problem
+	for i := range v.Values {
+		if rng.Float64() >= p.MutRate {
+			continue
+		}
+		v.Values[i] += rng.NormFloat64() * scale * p.Sigma * (p.Upper[i] - p.Lower[i])
+		if v.Values[i] < p.Lower[i] {
+			v.Values[i] = p.Lower[i]
+		} else if v.Values[i] > p.Upper[i] {
+			v.Values[i] = p.Upper[i]
+		}
+	}
+}
+
+// Crossover applies uniform crossover, which keeps each gene inside its
+// bounds.
+func (v *FloatVector) Crossover(q Genome, rng *rand.Rand) {
+	CrossUniformFloat64(v.Values, q.(*FloatVector).Values, rng)
+}
+
+// Clone returns a deep copy of the FloatVector. The copy doesn't share the
+// FloatMatrix the FloatVector may be a view on.
+func (v FloatVector) Clone() Genome {
+	var values = make([]float64, len(v.Values))
+	copy(values, v.Values)
+	return &FloatVector{
+		Values:  values,
+		Problem: v.Problem,
+	}
+}
+
+// FloatProblem describes a problem defined over bounded float64 vectors. Its
+// NewGenome method can be handed to GA.Minimize.
+type FloatProblem struct {
+	Lower   []float64
+	Upper   []float64
+	MutRate float64 // Probability of mutating each gene
+	Sigma   float64 // Standard deviation of a mutation, relative to the width of the bounds
+	F       func([]float64) (float64, error)
+	// Optional, evaluates each row of a FloatMatrix at once. It is used by
+	// ModContiguous instead of F.
+	BatchF func(m FloatMatrix) ([]float64, error)
+	// Optional, samples the initial values in batches of InitSize vectors,
+	// InitSize typically being the population size. Values are sampled
+	// uniformly if Init is nil.
+	Init     FloatInitializer
+	InitSize uint
+	// Whether to evaluate the opposite of each initial vector and to keep the
+	// best of both, which costs an extra evaluation per initial vector.
+	Opposition bool
+	// Optional, the dimensions which represent integers and are rounded
+	// before F or BatchF is called.
+	Integers *IntegerDims
+
+	batch [][]float64 // Vectors sampled with Init that haven't been used yet
+}
+
+// floatInitMutex guards the batches of the FloatProblems, whose NewGenome
+// method is called concurrently when GAConfig.ParallelInit is true.
+var floatInitMutex sync.Mutex
+
+// Validate FloatProblem fields.
+func (p FloatProblem) Validate() error {
+	if len(p.Lower) == 0 {
+		return errors.New("at least one bound has to be provided")
+	}
+	if len(p.Lower) != len(p.Upper) {
+		return errors.New("Lower and Upper should have the same length")
+	}
+	for i := range p.Lower {
+		if p.Lower[i] > p.Upper[i] {
+			return errors.New("Lower should be lower or equal to Upper")
+		}
+	}
+	if p.MutRate < 0 || p.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	if p.Sigma < 0 {
+		return errors.New("Sigma should be positive")
+	}
+	if p.F == nil {
+		return errors.New("F cannot be nil")
+	}
+	if p.Init != nil && p.InitSize == 0 {
+		return errors.New("InitSize should be positive when Init is set")
+	}
+	if p.Integers != nil {
+		return p.Integers.Validate(len(p.Lower))
+	}
+	return nil
+}
+
+// initialValues returns the next vector sampled with Init, or a vector sampled
+// uniformly if Init is nil.
+func (p *FloatProblem) initialValues(rng *rand.Rand) []float64 {
+	if p.Init == nil {
+		return InitJaggFloat64(uint(len(p.Lower)), p.Lower, p.Upper, rng)
+	}
+	floatInitMutex.Lock()
+	defer floatInitMutex.Unlock()
+	if len(p.batch) == 0 {
+		p.batch = p.Init(p.InitSize, p.Lower, p.Upper, rng)
+	}
+	var values = p.batch[0]
+	p.batch = p.batch[1:]
+	return values
+}
+
+// evaluate calls F with values whose integer dimensions are rounded.
+func (p *FloatProblem) evaluate(values []float64) (float64, error) {
+	if p.Integers != nil {
+		values = p.Integers.Round(values)
+	}
+	return p.F(values)
+}
+
+// opposed returns the opposite of values if it is better, else values.
+func (p *FloatProblem) opposed(values []float64) []float64 {
+	var opposite = InitOpposite(values, p.Lower, p.Upper)
+	var f, err = p.evaluate(values)
+	if fo, erro := p.evaluate(opposite); err == nil && erro == nil && fo < f {
+		return opposite
+	}
+	return values
+}
+
+// NewGenome returns a FloatVector whose values are sampled with Init, or
+// uniformly from their bounds if Init is nil. If Opposition is true then the
+// opposite vector is returned instead if it is better.
+func (p *FloatProblem) NewGenome(rng *rand.Rand) Genome {
+	var values = p.initialValues(rng)
+	if p.Opposition {
+		values = p.opposed(values)
+	}
+	return &FloatVector{
+		Values:  values,
+		Problem: p,
+	}
+}
+
+// Reset samples new Values in place as NewGenome would, so that a GenomePool
+// can reuse the FloatVector.
+func (v *FloatVector) Reset(rng *rand.Rand) {
+	var p = v.Problem
+	if p.Init == nil {
+		for i := range v.Values {
+			v.Values[i] = p.Lower[i] + rng.Float64()*(p.Upper[i]-p.Lower[i])
+		}
+	} else {
+		copy(v.Values, p.initialValues(rng))
+	}
+	if p.Opposition {
+		copy(v.Values, p.opposed(v.Values))
+	}
+}
+
+// PackFloatVectors copies the Values of each FloatVector in indis into a
+// single FloatMatrix, the i-th row holding the Values of the i-th Individual,
+// and makes each Values a view on its row. An error is returned if one of the
+// Genomes isn't a *FloatVector or if the FloatVectors don't have the same
+// length.
+func PackFloatVectors(indis Individuals) (FloatMatrix, error) {
+	if len(indis) == 0 {
+		return FloatMatrix{}, nil
+	}
+	var vectors = make([]*FloatVector, len(indis))
+	for i, indi := range indis {
+		var v, ok = indi.Genome.(*FloatVector)
+		if !ok {
+			return FloatMatrix{}, fmt.Errorf("expected a *FloatVector, got %T", indi.Genome)
+		}
+		if i > 0 && len(v.Values) != len(vectors[0].Values) {
+			return FloatMatrix{}, errors.New("FloatVectors should have the same length")
+		}
+		vectors[i] = v
+	}
+	var m = NewFloatMatrix(len(vectors), len(vectors[0].Values))
+	for i, v := range vectors {
+		var row = m.Row(i)
+		copy(row, v.Values)
+		v.Values = row
+	}
+	return m, nil
+}
+
+// ModContiguous applies Model and then packs the Population's FloatVectors
+// into a single FloatMatrix with PackFloatVectors, which avoids fragmenting
+// memory at large population sizes. If the FloatProblem has a BatchF then the
+// Individuals that haven't been evaluated are moved to the top of the
+// Population and evaluated in a single call. Models which evaluate offsprings
+// themselves, such as ModSteadyState, don't benefit from BatchF.
+type ModContiguous struct {
+	Model Model
+}
+
+// Apply ModContiguous.
+func (mod ModContiguous) Apply(pop *Population) error {
+	if err := mod.Model.Apply(pop); err != nil {
+		return err
+	}
+	// Move the Individuals that have to be evaluated to the top, the order of
+	// the Population doesn't matter because it is sorted after evaluation
+	var n int
+	for i := range pop.Individuals {
+		if !pop.Individuals[i].Evaluated {
+			pop.Individuals[n], pop.Individuals[i] = pop.Individuals[i], pop.Individuals[n]
+			n++
+		}
+	}
+	var m, err = PackFloatVectors(pop.Individuals)
+	if err != nil || n == 0 {
+		return err
+	}
+	var problem = pop.Individuals[0].Genome.(*FloatVector).Problem
+	if problem.BatchF == nil {
+		return nil
+	}
+	var batch = FloatMatrix{Data: m.Data[:n*m.Cols], Rows: n, Cols: m.Cols}
+	if problem.Integers != nil {
+		batch.Data = copyFloat64s(batch.Data)
+		for i := 0; i < n; i++ {
+			copy(batch.Row(i), problem.Integers.Round(batch.Row(i)))
+		}
+	}
+	fitnesses, err := problem.BatchF(batch)
+	if err != nil {
+		return err
+	}
+	if len(fitnesses) != n {
+		return fmt.Errorf("BatchF returned %d fitnesses for %d rows", len(fitnesses), n)
+	}
+	for i, fitness := range fitnesses {
+		pop.Individuals[i].Fitness = fitness
+		pop.Individuals[i].Evaluated = true
+	}
+	return nil
+}
+
+// Validate ModContiguous fields.
+func (mod ModContiguous) Validate() error {
+	if mod.Model == nil {
+		return errors.New("Model cannot be nil")
+	}
+	return mod.Model.Validate()
+}
@@ -0,0 +1,203 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func newTestFloatProblem() *FloatProblem {
+	return &FloatProblem{
+		Lower:   []float64{-5, -5, -5},
+		Upper:   []float64{5, 5, 5},
+		MutRate: 0.5,
+		Sigma:   0.1,
+		F:       func(x []float64) (float64, error) { return Sphere(x), nil },
+	}
+}
+
+func TestFloatMatrixRow(t *testing.T) {
+	var m = NewFloatMatrix(2, 3)
+	copy(m.Row(1), []float64{1, 2, 3})
+	if m.Data[3] != 1 || m.Data[5] != 3 {
+		t.Errorf("Wrong data: %v", m.Data)
+	}
+	// Appending to the first row doesn't overwrite the second one
+	_ = append(m.Row(0), 42)
+	if m.Data[3] != 1 {
+		t.Errorf("The second row has been overwritten: %v", m.Data)
+	}
+}
+
+func TestFloatVectorMutate(t *testing.T) {
+	var (
+		rng = newRand()
+		p   = newTestFloatProblem()
+	)
+	p.MutRate = 1
+	p.Sigma = 10
+	for i := 0; i < 20; i++ {
+		var v = p.NewGenome(rng).(*FloatVector)
+		v.Mutate(rng)
+		for j, x := range v.Values {
+			if x < p.Lower[j] || x > p.Upper[j] {
+				t.Fatalf("Gene %d with value %f is outside of its bounds", j, x)
+			}
+		}
+	}
+}
+
+func TestPackFloatVectors(t *testing.T) {
+	var (
+		rng   = newRand()
+		p     = newTestFloatProblem()
+		indis = newIndividuals(4, false, p.NewGenome, rng)
+		clone = indis.Clone(rng)
+	)
+	var m, err = PackFloatVectors(indis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Rows != 4 || m.Cols != 3 {
+		t.Fatalf("Expected a 4x3 matrix, got %dx%d", m.Rows, m.Cols)
+	}
+	for i, indi := range indis {
+		var values = indi.Genome.(*FloatVector).Values
+		if &values[0] != &m.Data[i*m.Cols] {
+			t.Errorf("Individual %d is not a view on its row", i)
+		}
+		if fmt.Sprint(values) != fmt.Sprint(clone[i].Genome.(*FloatVector).Values) {
+			t.Errorf("Individual %d has been modified", i)
+		}
+	}
+	// Errors
+	indis[1].Genome.(*FloatVector).Values = []float64{1}
+	if _, err = PackFloatVectors(indis); err == nil {
+		t.Error("Expected an error")
+	}
+	indis[1].Genome = Vector{1, 2, 3}
+	if _, err = PackFloatVectors(indis); err == nil {
+		t.Error("Expected an error")
+	}
+	if m, err = PackFloatVectors(nil); err != nil || m.Rows != 0 {
+		t.Errorf("Expected an empty matrix, got %v and %v", m, err)
+	}
+}
+
+func TestModContiguous(t *testing.T) {
+	var (
+		p     = newTestFloatProblem()
+		calls int
+		rows  int
+	)
+	p.BatchF = func(m FloatMatrix) ([]float64, error) {
+		calls++
+		rows += m.Rows
+		var fitnesses = make([]float64, m.Rows)
+		for i := range fitnesses {
+			fitnesses[i], _ = p.F(m.Row(i))
+		}
+		return fitnesses, nil
+	}
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 10
+	conf.Model = ModContiguous{Model: ModGenerational{
+		Selector:  SelTournament{NContestants: 3},
+		MutRate:   0.5,
+		CrossRate: 0.7,
+	}}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatal(err)
+	}
+	// Offsprings that haven't been modified keep their fitness
+	if calls != 10 || rows == 0 || rows > 10*30 {
+		t.Errorf("Expected 10 batches of at most 30 rows, got %d and %d", calls, rows)
+	}
+	for _, indi := range ga.Populations[0].Individuals {
+		if f, _ := p.F(indi.Genome.(*FloatVector).Values); f != indi.Fitness {
+			t.Errorf("Expected fitness %f, got %f", f, indi.Fitness)
+		}
+	}
+	if ga.HallOfFame[0].Fitness > ga.Populations[0].Individuals[0].Fitness {
+		t.Error("The hall of fame should contain the best Individual")
+	}
+	// Errors from BatchF are returned
+	p.BatchF = func(m FloatMatrix) ([]float64, error) { return nil, errors.New("") }
+	if err = ga.Minimize(p.NewGenome); err == nil {
+		t.Error("Expected an error")
+	}
+	p.BatchF = func(m FloatMatrix) ([]float64, error) { return []float64{0}, nil }
+	if err = ga.Minimize(p.NewGenome); err == nil {
+		t.Error("Expected an error")
+	}
+	// The inner Model's errors are returned
+	ga.Model = ModContiguous{Model: ModRuntimeError{}}
+	if err = ga.Minimize(p.NewGenome); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestFloatValidate(t *testing.T) {
+	var problems = []func(p *FloatProblem){
+		func(p *FloatProblem) { p.Lower = nil },
+		func(p *FloatProblem) { p.Upper = p.Upper[1:] },
+		func(p *FloatProblem) { p.Lower = []float64{0, 0, 10} },
+		func(p *FloatProblem) { p.MutRate = 2 },
+		func(p *FloatProblem) { p.Sigma = -1 },
+		func(p *FloatProblem) { p.F = nil },
+		func(p *FloatProblem) { p.Init = InitSobol },
+	}
+	for i, f := range problems {
+		var p = newTestFloatProblem()
+		f(p)
+		if err := p.Validate(); err == nil {
+			t.Errorf("Expected an error in test case number %d", i)
+		}
+	}
+	if err := newTestFloatProblem().Validate(); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if err := (ModContiguous{}).Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+	if err := (ModContiguous{Model: ModValidateError{}}).Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestFloatProblemInit(t *testing.T) {
+	var (
+		rng = newRand()
+		p   = newTestFloatProblem()
+	)
+	p.Init = InitLatinHypercube
+	p.InitSize = 10
+	// Each batch of InitSize vectors is a Latin hypercube
+	for batch := 0; batch < 2; batch++ {
+		var strata = make(map[int]bool)
+		for i := 0; i < 10; i++ {
+			var x = p.NewGenome(rng).(*FloatVector).Values
+			strata[int((x[0]+5)/10*10)] = true
+		}
+		if len(strata) != 10 {
+			t.Errorf("Expected 10 strata, got %d", len(strata))
+		}
+	}
+}
+
+func TestFloatProblemOpposition(t *testing.T) {
+	var p = newTestFloatProblem()
+	p.F = func(x []float64) (float64, error) { return x[0], nil }
+	p.Init = InitSobol
+	p.InitSize = 8
+	p.Opposition = true
+	for i := 0; i < 20; i++ {
+		if x := p.NewGenome(newRand()).(*FloatVector).Values; x[0] > 0 {
+			t.Errorf("Expected the opposite to be kept, got %v", x)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Hypervolume returns the volume of the objective space dominated by points
+// and bounded by reference, all objectives being minimized. Points which
+// don't strictly dominate reference on every objective, or whose number of
+// objectives differs from reference's, don't contribute. The volume is
+// computed exactly by slicing the space along the last objective, which is
+// fast for 2 or 3 objectives and small fronts.
+func Hypervolume(points [][]float64, reference []float64) float64 {
+	var kept [][]float64
+	for _, p := range points {
+		if len(p) != len(reference) || len(p) == 0 {
+			continue
+		}
+		var inside = true
+		for i := range p {
+			if !(p[i] < reference[i]) {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		return 0
+	}
+	return hypervolume(kept, reference, len(reference))
+}
+
+// hypervolume returns the hypervolume of points over their first m
+// objectives, the points being inside the reference box.
+func hypervolume(points [][]float64, reference []float64, m int) float64 {
+	if m == 1 {
+		var lowest = points[0][0]
+		for _, p := range points[1:] {
+			lowest = math.Min(lowest, p[0])
+		}
+		return reference[0] - lowest
+	}
+	var sorted = append([][]float64(nil), points...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i][m-1] < sorted[j][m-1] })
+	var volume float64
+	for i, p := range sorted {
+		// The slab between p and the next point along the last objective is
+		// dominated by the points up to p
+		var upper = reference[m-1]
+		if i+1 < len(sorted) {
+			upper = sorted[i+1][m-1]
+		}
+		if upper > p[m-1] {
+			volume += hypervolume(sorted[:i+1], reference, m-1) * (upper - p[m-1])
+		}
+	}
+	return volume
+}
+
+// FrontStopOptions stop a GA whose hall of fame is a Pareto front once the
+// front has converged, see GAConfig.FrontStop and GA.FrontStability. The
+// front is monitored over the last Window generations with two criteria. The
+// front is stalled if its hypervolume hasn't improved by more than Epsilon,
+// relatively to the hypervolume Window generations ago, and it is stable if
+// the churn of its membership, namely the proportion of the members which
+// entered or left the front, hasn't exceeded MaxChurn at any generation. The
+// GA stops once the front is both stalled and stable, or either one if Any is
+// true.
+//
+// The hypervolume is measured against Reference. If it is nil the reference
+// point is derived from the first front: each objective's worst value plus a
+// tenth of its range, or plus 1 if all members share the same value.
+// Infeasible members don't contribute to the hypervolume.
+type FrontStopOptions struct {
+	Window    uint
+	Epsilon   float64
+	MaxChurn  float64
+	Any       bool
+	Reference []float64
+}
+
+// Validate FrontStopOptions fields.
+func (fs FrontStopOptions) Validate() error {
+	if fs.Window == 0 {
+		return errors.New("Window should be higher than 0")
+	}
+	if fs.Epsilon < 0 || math.IsNaN(fs.Epsilon) {
+		return errors.New("Epsilon should be positive")
+	}
+	if fs.MaxChurn < 0 || fs.MaxChurn > 1 || math.IsNaN(fs.MaxChurn) {
+		return errors.New("MaxChurn should be in [0, 1]")
+	}
+	return nil
+}
+
+// FrontStability describes the recent evolution of the Pareto front.
+// Hypervolumes and Churns hold the hypervolume of the front and the churn of
+// its membership at each of the last generations, at most Window + 1 and
+// Window of them, in chronological order. Improvement is the relative
+// improvement of the hypervolume over the window. Stalled, Stable and
+// Converged are only true once the front has been monitored for Window
+// generations.
+type FrontStability struct {
+	Generation   uint      `json:"generation"`
+	Reference    []float64 `json:"reference"`
+	Hypervolumes []float64 `json:"hypervolumes"`
+	Churns       []float64 `json:"churns"`
+	Improvement  float64   `json:"improvement"`
+	Stalled      bool      `json:"stalled"`
+	Stable       bool      `json:"stable"`
+	Converged    bool      `json:"converged"`
+}
+
+// frontState records the evolution of the Pareto front. It is guarded by a
+// lock so that FrontStability can be called while the GA is being evolved.
+type frontState struct {
+	mutex        sync.Mutex
+	reference    []float64
+	hypervolumes []float64
+	churns       []float64
+	members      map[string]bool
+	stability    FrontStability
+}
+
+// reset forgets the recorded fronts.
+func (fs *frontState) reset() {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.reference = nil
+	fs.hypervolumes = nil
+	fs.churns = nil
+	fs.members = nil
+	fs.stability = FrontStability{}
+}
+
+// recordFront records the hypervolume and the churn of the Pareto front if the
+// FrontStop option is set.
+func (ga *GA) recordFront() {
+	if ga.FrontStop == nil || !ga.ParetoHallOfFame {
+		return
+	}
+	var (
+		opts    = *ga.FrontStop
+		fs      = ga.front
+		points  [][]float64
+		members = make(map[string]bool, len(ga.HallOfFame))
+	)
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	for _, member := range ga.HallOfFame {
+		members[member.ID] = true
+		if member.Violation <= 0 && member.Objectives != nil {
+			points = append(points, member.Objectives)
+		}
+	}
+	if fs.reference == nil {
+		if opts.Reference != nil {
+			fs.reference = copyFloat64s(opts.Reference)
+		} else if len(points) > 0 {
+			fs.reference = nadirReference(points)
+		}
+	}
+	fs.hypervolumes = append(fs.hypervolumes, Hypervolume(points, fs.reference))
+	if fs.members != nil {
+		fs.churns = append(fs.churns, churn(fs.members, members))
+	}
+	fs.members = members
+	var window = int(opts.Window)
+	if len(fs.hypervolumes) > window+1 {
+		fs.hypervolumes = fs.hypervolumes[len(fs.hypervolumes)-window-1:]
+	}
+	if len(fs.churns) > window {
+		fs.churns = fs.churns[len(fs.churns)-window:]
+	}
+	var s = FrontStability{
+		Generation:   ga.Generations,
+		Reference:    copyFloat64s(fs.reference),
+		Hypervolumes: copyFloat64s(fs.hypervolumes),
+		Churns:       copyFloat64s(fs.churns),
+	}
+	var first, last = fs.hypervolumes[0], fs.hypervolumes[len(fs.hypervolumes)-1]
+	s.Improvement = last - first
+	if first > 0 {
+		s.Improvement /= first
+	}
+	if len(fs.hypervolumes) == window+1 {
+		s.Stalled = s.Improvement <= opts.Epsilon
+		s.Stable = true
+		for _, c := range fs.churns {
+			if c > opts.MaxChurn {
+				s.Stable = false
+			}
+		}
+	}
+	if opts.Any {
+		s.Converged = s.Stalled || s.Stable
+	} else {
+		s.Converged = s.Stalled && s.Stable
+	}
+	fs.stability = s
+}
+
+// nadirReference returns the worst value of each objective plus a tenth of
+// its range, or plus 1 if the range is empty.
+func nadirReference(points [][]float64) []float64 {
+	var lower, upper = copyFloat64s(points[0]), copyFloat64s(points[0])
+	for _, p := range points[1:] {
+		for i := range p {
+			lower[i] = math.Min(lower[i], p[i])
+			upper[i] = math.Max(upper[i], p[i])
+		}
+	}
+	for i := range upper {
+		if upper[i] > lower[i] {
+			upper[i] += (upper[i] - lower[i]) / 10
+		} else {
+			upper[i]++
+		}
+	}
+	return upper
+}
+
+// churn returns the proportion of the members of either front which aren't
+// members of both fronts.
+func churn(before, after map[string]bool) float64 {
+	var common int
+	for id := range after {
+		if before[id] {
+			common++
+		}
+	}
+	var union = len(before) + len(after) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(union-common) / float64(union)
+}
+
+// frontConverged returns true if the Pareto front has converged according to
+// the FrontStop option.
+func (ga *GA) frontConverged() bool {
+	if ga.FrontStop == nil || ga.front == nil {
+		return false
+	}
+	ga.front.mutex.Lock()
+	defer ga.front.mutex.Unlock()
+	return ga.front.stability.Converged
+}
+
+// FrontStability returns the recent evolution of the Pareto front, which is
+// monitored if the FrontStop field of the GAConfig is set. It is safe to call
+// concurrently with Minimize. The monitoring isn't persisted when the GA is
+// marshaled to JSON; it restarts when the GA is initialized.
+func (ga *GA) FrontStability() FrontStability {
+	if ga.front == nil {
+		return FrontStability{}
+	}
+	ga.front.mutex.Lock()
+	defer ga.front.mutex.Unlock()
+	var s = ga.front.stability
+	s.Reference = copyFloat64s(s.Reference)
+	s.Hypervolumes = copyFloat64s(s.Hypervolumes)
+	s.Churns = copyFloat64s(s.Churns)
+	return s
+}
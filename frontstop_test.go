@@ -0,0 +1,115 @@
+package eaopt
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHypervolume(t *testing.T) {
+	var testCases = []struct {
+		points    [][]float64
+		reference []float64
+		volume    float64
+	}{
+		{nil, []float64{1, 1}, 0},
+		{[][]float64{{0.5}}, []float64{2}, 1.5},
+		{[][]float64{{0, 0}}, []float64{1, 1}, 1},
+		// Staircase of 3 points
+		{[][]float64{{1, 3}, {2, 2}, {3, 1}}, []float64{4, 4}, 6},
+		// Dominated points and points outside the reference box don't count
+		{[][]float64{{1, 3}, {2, 2}, {3, 1}, {3, 3}, {5, 0}, {0, 4}}, []float64{4, 4}, 6},
+		// Points with the wrong number of objectives are skipped
+		{[][]float64{{1, 1}, {0, 0, 0}}, []float64{2, 2}, 1},
+		{[][]float64{{0, 0, 0}}, []float64{1, 2, 3}, 6},
+		// Two overlapping boxes of volume 1 * 2 * 2 sharing 1 * 1 * 2
+		{[][]float64{{0, 1, 0}, {1, 0, 0}}, []float64{2, 2, 2}, 6},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			if volume := Hypervolume(tc.points, tc.reference); math.Abs(volume-tc.volume) > 1e-12 {
+				t.Errorf("Expected %f, got %f", tc.volume, volume)
+			}
+		})
+	}
+}
+
+func TestChurn(t *testing.T) {
+	var set = func(ids ...string) map[string]bool {
+		var s = make(map[string]bool)
+		for _, id := range ids {
+			s[id] = true
+		}
+		return s
+	}
+	var testCases = []struct {
+		before, after map[string]bool
+		churn         float64
+	}{
+		{set(), set(), 0},
+		{set("a", "b"), set("a", "b"), 0},
+		{set("a", "b"), set("c", "d"), 1},
+		{set("a", "b", "c"), set("a", "b", "d"), 0.5},
+	}
+	for i, tc := range testCases {
+		if c := churn(tc.before, tc.after); c != tc.churn {
+			t.Errorf("TC %d: expected %f, got %f", i, tc.churn, c)
+		}
+	}
+}
+
+func TestGAFrontStop(t *testing.T) {
+	for _, either := range []bool{false, true} {
+		var conf = NewDefaultGAConfig()
+		conf.NGenerations = 1000
+		conf.HofSize = 10
+		conf.ParetoHallOfFame = true
+		conf.FrontStop = &FrontStopOptions{Window: 5, Epsilon: 0.01, MaxChurn: 0.2, Any: either}
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ga.Minimize(newBiObjectiveVector); err != nil {
+			t.Fatal(err)
+		}
+		if ga.Generations >= conf.NGenerations {
+			t.Fatalf("Any %t: expected the GA to stop early", either)
+		}
+		var s = ga.FrontStability()
+		if !s.Converged || s.Generation != ga.Generations {
+			t.Errorf("Any %t: expected a converged front, got %+v", either, s)
+		}
+		if either && !s.Stalled && !s.Stable || !either && !(s.Stalled && s.Stable) {
+			t.Errorf("Any %t: inconsistent criteria %+v", either, s)
+		}
+		if len(s.Hypervolumes) != 6 || len(s.Churns) != 5 || len(s.Reference) != 2 {
+			t.Errorf("Any %t: expected a window of 5 generations, got %+v", either, s)
+		}
+		for _, hv := range s.Hypervolumes {
+			if hv <= 0 {
+				t.Errorf("Any %t: expected positive hypervolumes, got %v", either, s.Hypervolumes)
+			}
+		}
+	}
+}
+
+func TestFrontStopOptionsValidate(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.ParetoHallOfFame = true
+	for _, fs := range []FrontStopOptions{
+		{},
+		{Window: 1, Epsilon: -1},
+		{Window: 1, MaxChurn: 1.5},
+		{Window: 1, Epsilon: math.NaN()},
+	} {
+		conf.FrontStop = &fs
+		if _, err := conf.NewGA(); err == nil {
+			t.Errorf("Expected an error for %+v", fs)
+		}
+	}
+	conf.FrontStop = &FrontStopOptions{Window: 1}
+	conf.ParetoHallOfFame = false
+	if _, err := conf.NewGA(); err == nil {
+		t.Error("Expected an error without ParetoHallOfFame")
+	}
+}
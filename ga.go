@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"sort"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -22,6 +23,52 @@ type GA struct {
 	Age         time.Duration `json:"duration"`           // Duration during which the GA has been evolved
 	Generations uint          `json:"generations"`        // Number of generations the GA has been evolved
 	RNGSeed     string        `json:"rng_seed,omitempty"` // If evaluation of genomes relies on an initial seed, store for repopulation
+
+	// Progress of each Population, used to find struggling Populations when
+	// injecting the hall of fame
+	injectionBests []float64
+	injectionStale []uint
+
+	eval *evalContext // Shared by the Individuals, see Evaluations and EvalTime
+
+	anytime *anytimeState // See Anytime
+
+	history *bestHistory // See BestHistory
+
+	front *frontState // See FrontStability
+
+	diagnostics *diagnosticsState // See Diagnose
+
+	novelty *noveltyState // See NoveltyArchive and BestObjective
+
+	immigrants *immigrants // Genomes waiting to be injected, see Inject
+
+	duplicates *atomic.Uint64 // See DuplicateMigrants
+
+	polished []PolishResult // See Polished
+
+	finished  bool  // Whether the current run has been finished, see Finish
+	finishErr error // Error of the polishing performed by Finish
+
+	sensitivityReport *SensitivityReport // Last report included in the GenerationStats
+
+	// Subscriptions to the GA's lifecycle events. Unlike Callback, Events can
+	// deliver each event to several subscribers.
+	Events *Events `json:"-"`
+
+	// Warnings about the configuration, see GAConfig.Validate, completed when
+	// the GA is initialized with what the first evaluations reveal.
+	Warnings []Warning `json:"-"`
+}
+
+// cheapEvaluation is the average evaluation time under which evaluating
+// Individuals in parallel costs more than it saves.
+const cheapEvaluation = 5 * time.Microsecond
+
+// isPlaceholder returns true if indi fills an empty slot of the hall of fame,
+// which any Individual should take, infeasible ones included.
+func isPlaceholder(indi Individual) bool {
+	return indi.Genome == nil && math.IsInf(indi.Fitness, 1)
 }
 
 // Find the best current Individual in each population and then compare the best
@@ -33,7 +80,7 @@ func updateHallOfFame(hof Individuals, indis Individuals, rng *rand.Rand) {
 	for _, indi := range indis[:minInt(k, len(indis))] {
 		// Find if and where the Individual should fit in the hall of fame
 		var (
-			f = func(i int) bool { return indi.Fitness < hof[i].Fitness }
+			f = func(i int) bool { return indi.Better(hof[i]) || isPlaceholder(hof[i]) }
 			i = sort.Search(k, f)
 		)
 		if i < k {
@@ -46,47 +93,149 @@ func updateHallOfFame(hof Individuals, indis Individuals, rng *rand.Rand) {
 }
 
 func (ga *GA) init(newGenome func(rng *rand.Rand) Genome) error {
-	var err error
+	var (
+		err     error
+		resumed = len(ga.Populations) > 0
+	)
+	ga.finished = false
 
 	// Create the initial Populations (if not read from storage).
-	if len(ga.Populations) == 0 {
+	if !resumed {
 		// Reset counters
 		ga.Generations = 0
 		ga.Age = 0
+		ga.eval = nil
+		if ga.anytime != nil {
+			ga.anytime.reset()
+		}
+		if ga.history != nil {
+			ga.history.reset()
+		}
+		if ga.front != nil {
+			ga.front.reset()
+		}
+		if ga.diagnostics != nil {
+			ga.diagnostics.reset()
+		}
+		if ga.novelty != nil {
+			ga.novelty.reset()
+		}
+		if ga.duplicates != nil {
+			ga.duplicates.Store(0)
+		}
 		ga.Populations = make(Populations, ga.NPops)
+		var ids = make(set)
 		for i := range ga.Populations {
 			ga.Populations[i] = newPopulation(ga.PopSize, ga.ParallelInit, newGenome, ga.RNG)
+			if ga.PopulationIDFunc != nil {
+				var id = ga.PopulationIDFunc(uint(i), ga.Populations[i].RNG)
+				if ids[id] {
+					return errors.Errorf("PopulationIDFunc returned %q twice", id)
+				}
+				ids[id] = true
+				ga.Populations[i].ID = id
+			}
 		}
 	}
-	for i := range ga.Populations {
-		// Evaluate and sort
-		err = ga.Populations[i].Individuals.Evaluate(ga.ParallelEval)
-		if err != nil {
+	if ga.CheckClones {
+		if err = ga.checkClones(newGenome); err != nil {
 			return err
 		}
-		ga.Populations[i].Individuals.SortByFitness()
-		// Log current statistics if a logger has been provided
-		if ga.Logger != nil {
-			ga.Populations[i].Log(ga.Logger)
+	}
+	ga.shareEvalContext()
+	ga.assignIDs(resumed)
+	if ga.ValidateGenomes && !resumed {
+		for _, pop := range ga.Populations {
+			for _, indi := range pop.Individuals {
+				ga.eval.checkGenome("newGenome", indi.Genome)
+			}
+		}
+		if err = ga.eval.takeInvalid(); err != nil {
+			return err
+		}
+	}
+	// GAs which weren't created with NewGA don't have an anytimeState yet
+	if ga.anytime == nil {
+		ga.anytime = new(anytimeState)
+	}
+	if ga.history == nil {
+		ga.history = new(bestHistory)
+	}
+	if ga.front == nil {
+		ga.front = new(frontState)
+	}
+	if ga.diagnostics == nil {
+		ga.diagnostics = new(diagnosticsState)
+	}
+	if ga.novelty == nil {
+		ga.novelty = new(noveltyState)
+	}
+	if ga.duplicates == nil {
+		ga.duplicates = new(atomic.Uint64)
+	}
+	if ga.Events == nil {
+		ga.Events = new(Events)
+	}
+	if ga.immigrants == nil {
+		ga.immigrants = new(immigrants)
+	}
+	var fresh = make([][]bool, len(ga.Populations))
+	if ga.Novelty != nil {
+		for i := range ga.Populations {
+			fresh[i] = unevaluated(ga.Populations[i].Individuals)
 		}
+	}
+	// Evaluate and sort
+	if err = ga.evaluatePopulations(); err != nil {
+		return err
+	}
+	for i := range ga.Populations {
+		if fresh[i] != nil {
+			err = ga.scoreNovelty(ga.Populations[i].Individuals, fresh[i], ga.Populations[i].RNG)
+			if err != nil {
+				return err
+			}
+		}
+		ga.Populations[i].Individuals.SortByFitness()
+		ga.Populations[i].StatsPolicy = ga.StatsPolicy
 		ga.Populations[i].JSONUnmarshaler = ga.GenomeJSONUnmarshaler
 	}
+	ga.logPopulations()
+	ga.warnCheapEvaluations()
 
 	// Initialize the hall of fame
+	if ga.ParetoHallOfFame {
+		if _, ok := ga.Populations[0].Individuals[0].Genome.(MultiObjective); !ok {
+			return errNotMultiObjective
+		}
+	}
 	if len(ga.HallOfFame) == 0 {
 		ga.HallOfFame = make(Individuals, ga.HofSize)
 		for i := range ga.HallOfFame {
 			ga.HallOfFame[i] = Individual{Fitness: math.Inf(1)}
 		}
+		// A Pareto front only contains actual Individuals
+		if ga.ParetoHallOfFame {
+			ga.HallOfFame = ga.HallOfFame[:0]
+		}
 		for _, pop := range ga.Populations {
-			updateHallOfFame(ga.HallOfFame, pop.Individuals, pop.RNG)
+			ga.updateHallOfFame(settled(pop.Individuals), pop.RNG)
 		}
+		ga.Events.emitRestart(RestartEvent{GA: ga, Resumed: resumed})
+		ga.Events.emitNewBest(NewBestEvent{GA: ga, Best: ga.best(), Previous: math.Inf(1)})
 	} else {
+		// Novelty scores depend on the Populations of the time, hence they
+		// can't be checked by evaluating the hall of fame again
+		if ga.Novelty != nil {
+			for i := range ga.HallOfFame {
+				ga.HallOfFame[i].Evaluated = true
+			}
+		}
 		fitnessPrior := 0.0
 		for _, indi := range ga.HallOfFame {
 			fitnessPrior += indi.Fitness
 		}
-		err = ga.HallOfFame.Evaluate(ga.ParallelEval)
+		err = ga.HallOfFame.evaluate(ga.ParallelEval)
 		if err != nil {
 			return err
 		}
@@ -97,74 +246,415 @@ func (ga *GA) init(newGenome func(rng *rand.Rand) Genome) error {
 		if fitnessPost != fitnessPrior {
 			return errors.Errorf("fitness of hall of fame prior/post mismatch: %v to %v", fitnessPrior, fitnessPost)
 		}
+		ga.Events.emitRestart(RestartEvent{GA: ga, Resumed: resumed})
 	}
 
+	ga.recordAnytime()
+	ga.recordHistory()
+	ga.recordFront()
+	ga.recordDiagnostics(nil)
+
 	// Execute the callback if it has been set
 	if ga.Callback != nil {
 		ga.Callback(ga)
 	}
+	ga.Events.emitGenerationEnd(GenerationEndEvent{GA: ga, Generation: ga.Generations})
 
 	return nil
 }
 
+// warnCheapEvaluations adds a Warning if ParallelEval is true although
+// evaluations are too cheap to benefit from it.
+func (ga *GA) warnCheapEvaluations() {
+	if !ga.ParallelEval || ga.Evaluations() == 0 {
+		return
+	}
+	for _, w := range ga.Warnings {
+		if w.Field == "ParallelEval" {
+			return
+		}
+	}
+	if ga.EvalTime()/time.Duration(ga.Evaluations()) < cheapEvaluation {
+		ga.Warnings = append(ga.Warnings, Warning{
+			Field:   "ParallelEval",
+			Message: "evaluations are too cheap to be worth running in parallel",
+		})
+	}
+}
+
+// checkClones applies CheckClone to the Genome of the first Individual. The
+// second Individual is used as the independent Genome if there is one, else a
+// new Genome is generated. The mutations are driven by a dedicated random
+// number generator so that checking doesn't change the course of the GA.
+func (ga *GA) checkClones(newGenome func(rng *rand.Rand) Genome) error {
+	var (
+		indis = ga.Populations[0].Individuals
+		rng   = rand.New(rand.NewSource(1))
+		other Genome
+	)
+	if len(indis) > 1 {
+		other = indis[1].Genome
+	} else {
+		other = newGenome(rng)
+	}
+	return CheckClone(indis[0].Genome, other, rng)
+}
+
+// shareEvalContext makes the GA's Individuals share the accounting of the
+// evaluations, the EvalTimeout, the genome validation settings and the
+// IDGenerator. The Individuals generated later on inherit
+// it because they are cloned from existing ones.
+func (ga *GA) shareEvalContext() {
+	if ga.eval == nil {
+		ga.eval = new(evalContext)
+	}
+	ga.eval.timeout = ga.EvalTimeout
+	ga.eval.maxEvaluations = ga.MaxEvaluations
+	ga.eval.constraints = ga.ConstraintHandler
+	ga.eval.validate = ga.ValidateGenomes
+	ga.eval.validator = ga.GenomeValidator
+	ga.eval.ids = ga.IDGenerator
+	for i := range ga.Populations {
+		for j := range ga.Populations[i].Individuals {
+			ga.Populations[i].Individuals[j].eval = ga.eval
+		}
+	}
+	for i := range ga.HallOfFame {
+		ga.HallOfFame[i].eval = ga.eval
+	}
+}
+
+// Evaluations returns the number of times the Genomes of the GA's Individuals
+// have been evaluated since the Populations were initialized. It is safe to
+// call concurrently, for instance from a Callback when ParallelEval is true.
+// The count isn't persisted when the GA is marshaled to JSON.
+func (ga *GA) Evaluations() uint64 {
+	if ga.eval == nil {
+		return 0
+	}
+	return ga.eval.count.Load()
+}
+
+// EvalTime returns the total time spent evaluating Genomes since the
+// Populations were initialized. When ParallelEval is true it can exceed the
+// GA's Age. As with Evaluations, it isn't persisted.
+func (ga *GA) EvalTime() time.Duration {
+	if ga.eval == nil {
+		return 0
+	}
+	return time.Duration(ga.eval.elapsed.Load())
+}
+
+// evaluatePopulations evaluates the Individuals of every Population, through
+// an evalQueue if they are evaluated in parallel, else one Population after
+// the other.
+func (ga *GA) evaluatePopulations() error {
+	var q = ga.newEvalQueue()
+	if q == nil {
+		for i := range ga.Populations {
+			if err := ga.Populations[i].Individuals.Evaluate(ga.ParallelEval); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	defer q.close()
+	return ga.applyPopulations(func(pop *Population) error {
+		return q.evaluate(pop.Individuals, ga.ParallelEval)
+	})
+}
+
+// done returns true if the GA should stop before evolving the next
+// generation, either because EarlyStop says so, because the evaluation
+// budget has been spent or because the Pareto front has converged.
+func (ga *GA) done() bool {
+	if ga.MaxEvaluations > 0 && ga.Evaluations() >= ga.MaxEvaluations {
+		return true
+	}
+	if ga.frontConverged() {
+		return true
+	}
+	return ga.EarlyStop != nil && ga.EarlyStop(ga)
+}
+
+// reevaluate evaluates the Individuals again, hall of fame included, after
+// something they depend on has changed, and sorts them by fitness.
+func (ga *GA) reevaluate() error {
+	for i := range ga.Populations {
+		var indis = ga.Populations[i].Individuals
+		for j := range indis {
+			indis[j].Evaluated = false
+		}
+		if err := indis.Evaluate(ga.ParallelEval); err != nil {
+			return err
+		}
+		indis.SortByFitness()
+	}
+	for i := range ga.HallOfFame {
+		if ga.HallOfFame[i].Genome == nil {
+			continue
+		}
+		ga.HallOfFame[i].Evaluated = false
+		if err := ga.HallOfFame[i].evaluate(); err != nil {
+			return err
+		}
+	}
+	var hof = ga.HallOfFame
+	sort.SliceStable(hof, func(i, j int) bool { return hof[i].Better(hof[j]) })
+	return nil
+}
+
+// logPopulations records the statistics of the Populations, or of the ones
+// selected by LogOptions, if a logger has been provided.
+func (ga *GA) logPopulations() {
+	if ga.Logger == nil && ga.SLogger == nil {
+		return
+	}
+	var opts = ga.LogOptions
+	if opts == nil {
+		for _, pop := range ga.Populations {
+			ga.logPopulation(pop)
+		}
+		return
+	}
+	if !opts.due(ga.Generations, ga.NGenerations) {
+		return
+	}
+	var pops = opts.selected(ga.Populations)
+	if opts.Aggregate {
+		ga.logPopulation(aggregatePopulations(pops, ga.Generations, ga.Age, ga.StatsPolicy))
+		return
+	}
+	for _, pop := range pops {
+		ga.logPopulation(pop)
+	}
+}
+
+// Log a Population's current statistics if a logger has been provided.
+func (ga *GA) logPopulation(pop Population) {
+	if ga.Logger != nil {
+		if ga.LogOptions != nil && ga.LogOptions.Quartiles {
+			pop.LogQuartiles(ga.Logger)
+		} else {
+			pop.Log(ga.Logger)
+		}
+	}
+	if ga.SLogger != nil {
+		pop.SLog(ga.SLogger, ga.LogLevel)
+	}
+}
+
+// populationIndex returns the index of pop, one of the GA's Populations.
+func (ga *GA) populationIndex(pop *Population) int {
+	for i := range ga.Populations {
+		if &ga.Populations[i] == pop {
+			return i
+		}
+	}
+	return -1
+}
+
+// populationModel returns the Model with which pop, one of the GA's
+// Populations, is evolved.
+func (ga *GA) populationModel(pop *Population) Model {
+	for i := range ga.Models {
+		if &ga.Populations[i] == pop {
+			return ga.Models[i]
+		}
+	}
+	return ga.Model
+}
+
+// migrationDue returns true if the Migrator is scheduled to be applied at the
+// given generation. The deprecated MigFrequency takes precedence over the
+// Migrator's Schedule if it is set.
+func (ga *GA) migrationDue(generation uint) bool {
+	if ga.Migrator == nil {
+		return false
+	}
+	if ga.MigFrequency > 0 {
+		return generation%ga.MigFrequency == 0
+	}
+	return ga.Migrator.Schedule(generation)
+}
+
 // Evolve a GA's Populations in parallel.
 func (ga *GA) evolve() error {
 	var start = time.Now()
 	ga.Generations++
 
+	// Insert the injected Genomes before anything else so that they take part
+	// in the generation
+	if err := ga.immigrate(); err != nil {
+		return err
+	}
+
 	// Migrate the individuals between the populations if there are at least 2
-	// Populations and that there is a migrator and that the migration frequency
-	// divides the generation count
-	if len(ga.Populations) > 1 && ga.Migrator != nil && ga.Generations%ga.MigFrequency == 0 {
+	// Populations and that there is a migrator scheduled for this generation.
+	// Migrators that take species into account are applied once the global
+	// species are known.
+	var (
+		migrate         = len(ga.Populations) > 1 && ga.migrationDue(ga.Generations)
+		global          = ga.Speciator != nil && ga.GlobalSpeciation
+		smig, bySpecies = ga.Migrator.(SpeciesMigrator)
+		species         [][]int
+	)
+	if migrate && !(global && bySpecies) {
+		var before = ga.Populations.individuals()
 		ga.Migrator.Apply(ga.Populations, ga.RNG)
+		ga.Events.emitMigration(MigrationEvent{GA: ga, Generation: ga.Generations, Duplicates: ga.dedupMigrated(before)})
+		if err := ga.settleMigrants(before); err != nil {
+			return err
+		}
+	}
+	if global {
+		var err error
+		if species, err = ga.speciateGlobally(); err != nil {
+			return err
+		}
+		ga.Events.emitSpeciation(SpeciationEvent{
+			GA:         ga,
+			Generation: ga.Generations,
+			Population: -1,
+			Sizes:      countSpecies(species),
+		})
+		if migrate && bySpecies {
+			var before = ga.Populations.individuals()
+			smig.ApplySpecies(ga.Populations, species, ga.RNG)
+			ga.Events.emitMigration(MigrationEvent{GA: ga, Generation: ga.Generations, Duplicates: ga.dedupMigrated(before)})
+			if err = ga.settleMigrants(before); err != nil {
+				return err
+			}
+		}
 	}
 
+	// Sizes of the species of each Population, with per Population speciation
+	var sizes = make([][]int, len(ga.Populations))
+
+	// Number of offspring and of successful offspring of each Population, see
+	// Diagnose
+	var successes = make([][2]int, len(ga.Populations))
+
+	// Which Individuals of each Population are evaluated during this
+	// generation, with novelty search
+	var fresh = make([][]bool, len(ga.Populations))
+
+	// The Populations share the evaluation workers
+	var queue = ga.newEvalQueue()
+
+	// Evaluations don't start past the deadline in real-time mode
+	var deadline = ga.deadline(start)
+
 	var f = func(pop *Population) error {
-		var err error
-		// Apply speciation if a positive number of species has been specified
-		if ga.Speciator != nil {
-			err = pop.speciateEvolveMerge(ga.Speciator, ga.Model)
+		var (
+			model = ga.populationModel(pop)
+			err   error
+		)
+		// Evaluate the Individuals the previous generation didn't have time for
+		if err = ga.carryOver(pop, queue, deadline); err != nil {
+			return err
+		}
+		// Evolve the population's share of each global species
+		if global {
+			err = pop.evolveSpecies(speciesOf(pop.Individuals, species[ga.populationIndex(pop)]), model)
+			if err != nil {
+				return err
+			}
+		} else if ga.Speciator != nil {
+			// Apply speciation if a positive number of species has been specified
+			sizes[ga.populationIndex(pop)], err = pop.speciateEvolveMerge(ga.Speciator, model)
 			if err != nil {
 				return err
 			}
 		} else {
 			// Else apply the evolution model to the entire population
-			err = ga.Model.Apply(pop)
+			err = model.Apply(pop)
 			if err != nil {
 				return err
 			}
 		}
+		var inherited []float64
+		if ga.Diagnostics != nil {
+			inherited = inheritedFitnesses(pop.Individuals)
+		}
+		if ga.Novelty != nil {
+			fresh[ga.populationIndex(pop)] = unevaluated(pop.Individuals)
+		}
 		// Evaluate and sort
-		err = pop.Individuals.Evaluate(ga.ParallelEval)
+		if ga.RealTime != nil {
+			err = ga.evaluateInTime(pop.Individuals, queue, deadline)
+		} else {
+			err = queue.evaluate(pop.Individuals, ga.ParallelEval)
+		}
 		if err != nil {
 			return err
 		}
-		pop.Individuals.SortByFitness()
+		if inherited != nil {
+			successes[ga.populationIndex(pop)] = countSuccesses(pop.Individuals, inherited)
+		}
+		// With novelty search the Individuals are sorted once they are scored
+		if ga.Novelty == nil {
+			pop.Individuals.SortByFitness()
+		}
 		// Record time spent evolving
 		pop.Age += time.Since(start)
 		pop.Generations++
-		// Log current statistics if a logger has been provided
-		if ga.Logger != nil {
-			pop.Log(ga.Logger)
-		}
 		return err
 	}
 
-	var err = ga.Populations.Apply(f)
+	var err = ga.applyPopulations(f)
+	queue.close()
 	if err != nil {
 		return err
 	}
+	// The Populations share the novelty archive, hence they are scored one
+	// after the other for the sake of reproducibility
+	if ga.Novelty != nil {
+		for i := range ga.Populations {
+			var pop = &ga.Populations[i]
+			if err = ga.scoreNovelty(pop.Individuals, fresh[i], pop.RNG); err != nil {
+				return err
+			}
+			pop.Individuals.SortByFitness()
+		}
+	}
+	ga.logPopulations()
+	if err = ga.eval.takeInvalid(); err != nil {
+		return errors.Wrapf(err, "generation %d", ga.Generations)
+	}
+	for i := range sizes {
+		if sizes[i] != nil {
+			ga.Events.emitSpeciation(SpeciationEvent{GA: ga, Generation: ga.Generations, Population: i, Sizes: sizes[i]})
+		}
+	}
 	// Update HallOfFame
+	var previous = ga.best()
 	for _, pop := range ga.Populations {
-		updateHallOfFame(ga.HallOfFame, pop.Individuals, pop.RNG)
+		ga.updateHallOfFame(settled(pop.Individuals), pop.RNG)
+	}
+	if best := ga.best(); best.ID != previous.ID {
+		ga.Events.emitNewBest(NewBestEvent{GA: ga, Best: best, Previous: previous.Fitness})
+	}
+
+	// Reinject the hall of fame into struggling Populations
+	if ga.HofInjection != nil {
+		if err = ga.injectHallOfFame(); err != nil {
+			return err
+		}
 	}
 
 	ga.Age += time.Since(start)
 
+	ga.recordAnytime()
+	ga.recordHistory()
+	ga.recordFront()
+	ga.recordDiagnostics(successes)
+
 	// Execute the callback if it has been set
 	if ga.Callback != nil {
 		ga.Callback(ga)
 	}
+	ga.Events.emitGenerationEnd(GenerationEndEvent{GA: ga, Generation: ga.Generations})
 
 	return nil
 }
@@ -182,20 +672,43 @@ func (ga *GA) Init(newGenome func(rng *rand.Rand) Genome) error {
 }
 
 func (ga *GA) Run() error {
+	ga.finished = false
+	if ga.AsyncMigration {
+		if err := ga.runAsync(); err != nil {
+			return err
+		}
+		return ga.Finish()
+	}
 	for i := uint(0); i < ga.NGenerations; i++ {
 		// Check for early stopping
-		if ga.EarlyStop != nil && ga.EarlyStop(ga) {
-			return nil
+		if ga.done() {
+			break
 		}
 		if err := ga.evolve(); err != nil {
 			return err
 		}
 	}
-	return nil
+	return ga.Finish()
+}
+
+// Finish ends a run by logging the diagnosis and polishing the hall of fame if
+// GAConfig.Polish is set, as Minimize and Run do after the last generation. It
+// is meant for runs driven with Step, StepN calls it once Done returns true.
+// It only finishes a run once, however many times it is called, until Step
+// evolves the GA further, and returns the error of the polishing.
+func (ga *GA) Finish() error {
+	if !ga.finished {
+		ga.finished = true
+		ga.logDiagnosis()
+		ga.finishErr = ga.polish()
+	}
+	return ga.finishErr
 }
 
 // Minimize evolves the GA's Populations following the given evolutionary
-// method. The GA's hall of fame is updated after each generation.
+// method. The GA's hall of fame is updated after each generation, and
+// polished once the last generation has been evolved if GAConfig.Polish is
+// set.
 func (ga *GA) Minimize(newGenome func(rng *rand.Rand) Genome) error {
 	// Initialize the GA
 	var err = ga.init(newGenome)
@@ -203,27 +716,47 @@ func (ga *GA) Minimize(newGenome func(rng *rand.Rand) Genome) error {
 		return err
 	}
 
+	if ga.AsyncMigration {
+		if err = ga.runAsync(); err != nil {
+			return err
+		}
+		return ga.Finish()
+	}
+
 	// Go through the generations
 	for i := uint(0); i < ga.NGenerations; i++ {
 		// Check for early stopping
-		if ga.EarlyStop != nil && ga.EarlyStop(ga) {
-			return nil
+		if ga.done() {
+			break
 		}
 		if err := ga.evolve(); err != nil {
 			return err
 		}
 	}
-	return nil
+	return ga.Finish()
 }
 
-func (pop *Population) speciateEvolveMerge(spec Speciator, model Model) error {
-	var (
-		species, err = spec.Apply(pop.Individuals, pop.RNG)
-		pops         = make([]Population, len(species))
-	)
+// speciateEvolveMerge splits pop into species with spec, evolves each species
+// with model and merges them back. The size of each species is returned.
+func (pop *Population) speciateEvolveMerge(spec Speciator, model Model) ([]int, error) {
+	var species, err = spec.Apply(pop.Individuals, pop.RNG)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var sizes = make([]int, len(species))
+	for i, specie := range species {
+		sizes[i] = len(specie)
 	}
+	return sizes, pop.evolveSpecies(species, model)
+}
+
+// evolveSpecies applies model to each species separately and merges the
+// species back into pop. The species have to partition pop's Individuals.
+func (pop *Population) evolveSpecies(species []Individuals, model Model) error {
+	var (
+		pops = make([]Population, len(species))
+		err  error
+	)
 	// Create a subpopulation from each specie so that the evolution Model can
 	// be applied to it.
 	for i, specie := range species {
@@ -232,12 +765,16 @@ func (pop *Population) speciateEvolveMerge(spec Speciator, model Model) error {
 			Age:         pop.Age,
 			Generations: pop.Generations,
 			ID:          randString(len(pop.ID), pop.RNG),
+			SAState:     pop.SAState,
+			Beliefs:     pop.Beliefs,
 			RNG:         pop.RNG,
 		}
 		err = model.Apply(&pops[i])
 		if err != nil {
 			return err
 		}
+		pop.SAState = pops[i].SAState
+		pop.Beliefs = pops[i].Beliefs
 	}
 	// Merge each species back into the original population
 	var i int
@@ -248,7 +785,84 @@ func (pop *Population) speciateEvolveMerge(spec Speciator, model Model) error {
 	return nil
 }
 
-// UnmarshalJSON decodes a GA represented as JSON.
+// speciateGlobally applies the Speciator to the Individuals of all the
+// Populations at once, sorted by increasing fitness as they would be within a
+// Population. The species of the j-th Individual of the i-th Population is
+// stored at index [i][j] of the returned slice. The Individuals are matched
+// with their species through their IDs.
+func (ga *GA) speciateGlobally() ([][]int, error) {
+	type location struct{ pop, indi int }
+	var (
+		all    Individuals
+		where  = make(map[string][]location)
+		labels = make([][]int, len(ga.Populations))
+	)
+	for i, pop := range ga.Populations {
+		labels[i] = make([]int, len(pop.Individuals))
+		for j, indi := range pop.Individuals {
+			all = append(all, indi)
+			where[indi.ID] = append(where[indi.ID], location{i, j})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Better(all[j]) })
+	var species, err = ga.Speciator.Apply(all, ga.RNG)
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	for s, specie := range species {
+		for _, indi := range specie {
+			var locs = where[indi.ID]
+			if len(locs) == 0 {
+				return nil, errors.Errorf("the Speciator returned an unknown individual %s", indi.ID)
+			}
+			labels[locs[0].pop][locs[0].indi] = s
+			where[indi.ID] = locs[1:]
+			n++
+		}
+	}
+	if n != len(all) {
+		return nil, errors.Errorf("the Speciator returned %d individuals out of %d", n, len(all))
+	}
+	return labels, nil
+}
+
+// countSpecies returns the number of Individuals in each global species.
+func countSpecies(labels [][]int) []int {
+	var sizes []int
+	for _, pop := range labels {
+		for _, s := range pop {
+			for len(sizes) <= s {
+				sizes = append(sizes, 0)
+			}
+			sizes[s]++
+		}
+	}
+	return sizes
+}
+
+// speciesOf groups indis according to their species, empty species are
+// omitted.
+func speciesOf(indis Individuals, labels []int) []Individuals {
+	var (
+		groups = make(map[int]Individuals)
+		order  []int
+	)
+	for i, indi := range indis {
+		if _, ok := groups[labels[i]]; !ok {
+			order = append(order, labels[i])
+		}
+		groups[labels[i]] = append(groups[labels[i]], indi)
+	}
+	var species = make([]Individuals, len(order))
+	for i, s := range order {
+		species[i] = groups[s]
+	}
+	return species
+}
+
+// UnmarshalJSON decodes a GA represented as JSON. GAs written by older
+// versions of eaopt are migrated to the current SchemaVersion.
 func (ga *GA) UnmarshalJSON(data []byte) error {
 
 	gaMap := make(map[string]interface{})
@@ -290,30 +904,24 @@ func (ga *GA) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	var individuals []interface{}
 	hafJSON, err := json.Marshal(gaMap["hall_of_fame"])
 	if err != nil {
 		return errors.Wrap(err, "error marshaling hall of fame")
 	}
-	ga.HallOfFame = make(Individuals, 0)
-	err = json.Unmarshal(hafJSON, &individuals)
+	// The Populations migrate themselves, the hall of fame is migrated here
+	var doc map[string]json.RawMessage
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	version, err := schemaVersionOf(doc)
 	if err != nil {
 		return err
 	}
-	for _, v := range individuals {
-		val, err := json.Marshal(v.(map[string]interface{})["genome"])
-		if err != nil {
-			return err
-		}
-		genome, err := ga.GenomeJSONUnmarshaler(val)
-		if err != nil {
-			return err
-		}
-		ga.HallOfFame = append(ga.HallOfFame, Individual{
-			Genome:  genome,
-			Fitness: v.(map[string]interface{})["fitness"].(float64),
-			ID:      v.(map[string]interface{})["id"].(string),
-		})
+	if hafJSON, err = migrateIndividuals(hafJSON, version); err != nil {
+		return err
+	}
+	if err = ga.UnmarshalHallOfFame(hafJSON); err != nil {
+		return err
 	}
 
 	log.Println("Setting RNG Seed to", seed)
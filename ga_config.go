@@ -3,6 +3,7 @@ package eaopt
 import (
 	"errors"
 	"log"
+	"log/slog"
 	"math/rand"
 	"time"
 )
@@ -17,28 +18,138 @@ type GAConfig struct {
 	Model        Model
 
 	// Optional fields
-	ParallelInit bool // Whether to initialize Populations in parallel or not
-	ParallelEval bool // Whether to evaluate Individuals in parallel or not
-	Migrator     Migrator
-	MigFrequency uint // Frequency at which migrations occur
-	Speciator    Speciator
-	Logger       *log.Logger
-	Callback     func(ga *GA)
-	EarlyStop    func(ga *GA) bool
-	RNG          *rand.Rand
+	Models              []Model // Model of each Population, overrides Model if not empty
+	ParallelInit        bool    // Whether to initialize Populations in parallel or not
+	ParallelEval        bool    // Whether to evaluate Individuals in parallel or not
+	Migrator            Migrator
+	MigFrequency        uint // Deprecated: if positive, migrations occur every MigFrequency generations instead of following Migrator.Schedule
+	Speciator           Speciator
+	HofInjection        *HofInjection // Periodically copies the hall of fame into struggling Populations
+	Logger              *log.Logger
+	SLogger             *slog.Logger        // Structured alternative to Logger
+	LogLevel            slog.Level          // Level at which SLogger records population statistics
+	LogOptions          *LogOptions         // Which population statistics Logger and SLogger record, and how often
+	StatsPolicy         NonFinitePolicy     // How population statistics treat NaN and infinite fitnesses
+	Clustering          *ClusterOptions     // Clusters the Individuals in the GenerationStats, see GA.Clusters
+	History             *HistoryOptions     // Records the best Individual of each generation, see GA.BestHistory
+	Diagnostics         *DiagnosticsOptions // Monitors the convergence, see GA.Diagnose
+	Novelty             *NoveltyOptions     // Selects Individuals for the novelty of their behavior
+	Polish              *PolishOptions      // Polishes the hall of fame after the last generation, see GA.Polished
+	SensitivityAnalysis *SensitivityOptions // Analyzes the best Genome in the GenerationStats, see GA.Sensitivity
+	GenomePool          *GenomePool         // Receives the Genomes discarded by Inject and Restart
+	Callback            func(ga *GA)        // Called at the end of each generation, see also GA.Events
+	EarlyStop           func(ga *GA) bool
+	// Evaluation budget, the GA stops at the end of the generation during which
+	// it is spent. 0 means no limit. It is enforced by Individual.Evaluate,
+	// whichever model or Population evaluates: the Individuals left once it is
+	// spent aren't evaluated and are marked as Skipped, hence the search doesn't
+	// exceed it. The polishing, which has its own budget, the sensitivity
+	// analysis and the evaluations checking the hall of fame aren't limited.
+	MaxEvaluations uint64
+	// Evaluations that take longer are given up and the Individual receives an
+	// infinite fitness. 0 means no limit. Genomes are cloned before each
+	// evaluation when it is set.
+	EvalTimeout time.Duration
+	RNG         *rand.Rand
+
+	// Optional, gives each generation a wall-clock budget, the Individuals
+	// which couldn't be evaluated in time are evaluated later, see
+	// RealTimeOptions.
+	RealTime *RealTimeOptions
+
+	// Whether the Speciator is applied to the Individuals of all the
+	// Populations jointly instead of each Population separately. Each
+	// Population then evolves its share of every global species and a
+	// SpeciesMigrator only exchanges Individuals of the same species.
+	GlobalSpeciation bool
+
+	// Whether the Populations evolve independently instead of in lockstep,
+	// each in its own goroutine, so that fast Populations don't wait for slow
+	// ones. Migrants are then exchanged asynchronously, which requires an
+	// AsyncMigrator. The hall of fame is updated as the Populations progress,
+	// whereas the Callback, the GenerationEnd event and the GA-wide records,
+	// such as the History, happen once all the Populations have completed
+	// their NGenerations generations; EarlyStop is only checked before.
+	AsyncMigration bool
+
+	// Whether the GA evolves the Populations one after the other on the
+	// calling goroutine instead of spawning goroutines, which suits
+	// environments without threads such as WebAssembly, where it is the
+	// default. ParallelInit, ParallelEval, AsyncMigration and EvalTimeout,
+	// which require goroutines, can't be used. See also GA.StepN.
+	SingleThreaded bool
+
+	// Whether to check that the Genomes' Clone method doesn't share memory with
+	// the original when the GA is initialized, see CheckClone. Aliasing bugs
+	// otherwise go unnoticed and lead to a bizarre convergence.
+	CheckClones bool
+
+	// Whether to validate the Genomes produced by mutation and crossover,
+	// which is slow but helps finding buggy operators. Validatable Genomes are
+	// checked with their Validate method and GenomeValidator, if it isn't nil,
+	// is called on every Genome. The GA aborts with an error identifying the
+	// operator as soon as an invalid Genome is produced.
+	ValidateGenomes bool
+	GenomeValidator func(Genome) error
+
+	// Whether the hall of fame holds the Pareto front of the Individuals
+	// encountered instead of the HofSize best ones, which requires the
+	// Genomes to implement MultiObjective. Individuals are compared with
+	// Deb's feasibility rules, hence the front only contains feasible
+	// Individuals once there are some. It holds at most HofSize Individuals,
+	// the most crowded ones being dropped, and is sorted by fitness.
+	ParetoHallOfFame bool
+	// Optional, stops the GA once the Pareto front has converged, which
+	// requires ParetoHallOfFame, see GA.FrontStability.
+	FrontStop *FrontStopOptions
+
+	// Optional, measures the constraint violation of the Genomes. Individuals
+	// are then compared with Deb's feasibility rules, by the selectors and the
+	// hall of fame alike, see Individual.Better.
+	ConstraintHandler ConstraintHandler
+
+	// Optional, generates the ID of the i-th Population. IDs have to be unique,
+	// random 3 character IDs are used if nil.
+	PopulationIDFunc func(i uint, rng *rand.Rand) string
+
+	// Optional, generates the IDs of the Individuals, random 6 character IDs
+	// are used if nil. Individuals keep their ID when they migrate, hence
+	// the GA can tell when a migrant arrives in a Population which already
+	// holds it, see GA.DuplicateMigrants. If DedupMigrants is true such
+	// duplicates are discarded: asynchronous migrants are dropped and, with
+	// lockstep migrations, a duplicate is replaced by the Individual it
+	// overwrote, if that Individual isn't anywhere else.
+	IDGenerator   IDGenerator
+	DedupMigrants bool
+
+	// Whether migrants are evaluated again once they have arrived in their
+	// destination Population. Migrants carry their fitness, violation,
+	// objectives and metadata along, hence by default they are only evaluated
+	// if they haven't been yet. Re-evaluating them is worth its cost when the
+	// objective is noisy or depends on the Population.
+	ReevaluateMigrants bool
 
 	// Optional, unmarshal function for your Genome. Needed to support deserializing
 	// a GA and its population(s) from JSON.
 	GenomeJSONUnmarshaler func([]byte) (Genome, error)
 }
 
-// NewGA returns a pointer to a GA instance and checks for configuration
-// errors.
-func (conf GAConfig) NewGA() (*GA, error) {
-	// Check for default values
-	if conf.RNG == nil {
-		conf.RNG = rand.New(rand.NewSource(time.Now().UnixNano()))
-	}
+// A Warning points out a GAConfig setting which is valid but most likely
+// pointless or wasteful.
+type Warning struct {
+	Field   string // Name of the GAConfig field concerned
+	Message string
+}
+
+// String returns the field followed by the message.
+func (w Warning) String() string {
+	return w.Field + ": " + w.Message
+}
+
+// Validate checks the configuration for errors, which NewGA reports as well,
+// and returns warnings about settings which are valid but most likely
+// mistakes.
+func (conf GAConfig) Validate() ([]Warning, error) {
 	// Check the configuration is valid
 	if conf.NPops == 0 {
 		return nil, errors.New("NPops has to be strictly higher than 0")
@@ -52,18 +163,56 @@ func (conf GAConfig) NewGA() (*GA, error) {
 	if conf.HofSize == 0 {
 		return nil, errors.New("HofSize has to be strictly higher than 0")
 	}
-	if conf.Model == nil {
+	if conf.Model == nil && len(conf.Models) == 0 {
 		return nil, errors.New("model has to be provided")
 	}
-	if modelErr := conf.Model.Validate(); modelErr != nil {
-		return nil, modelErr
+	if conf.Model != nil {
+		if modelErr := conf.Model.Validate(); modelErr != nil {
+			return nil, modelErr
+		}
+	}
+	if len(conf.Models) > 0 {
+		if uint(len(conf.Models)) != conf.NPops {
+			return nil, errors.New("Models should contain one model per Population")
+		}
+		for _, model := range conf.Models {
+			if model == nil {
+				return nil, errors.New("Models cannot contain nil models")
+			}
+			if modelErr := model.Validate(); modelErr != nil {
+				return nil, modelErr
+			}
+		}
 	}
 	if conf.Migrator != nil {
 		if migErr := conf.Migrator.Validate(); migErr != nil {
 			return nil, migErr
 		}
-		if conf.MigFrequency == 0 {
-			return nil, errors.New("MigFrequency should be higher than 0")
+	}
+	if mt, ok := conf.Migrator.(MigTempering); ok && uint(len(mt.Temperatures)) != conf.NPops {
+		return nil, errors.New("MigTempering should have one temperature per Population")
+	}
+	if conf.AsyncMigration {
+		if _, ok := conf.Migrator.(AsyncMigrator); conf.Migrator != nil && !ok {
+			return nil, errors.New("AsyncMigration requires the Migrator to be an AsyncMigrator")
+		}
+		if conf.GlobalSpeciation {
+			return nil, errors.New("AsyncMigration can't be used with GlobalSpeciation")
+		}
+		if conf.HofInjection != nil {
+			return nil, errors.New("AsyncMigration can't be used with HofInjection")
+		}
+	}
+	if conf.SingleThreaded {
+		switch {
+		case conf.ParallelInit:
+			return nil, errors.New("SingleThreaded can't be used with ParallelInit")
+		case conf.ParallelEval:
+			return nil, errors.New("SingleThreaded can't be used with ParallelEval")
+		case conf.AsyncMigration:
+			return nil, errors.New("SingleThreaded can't be used with AsyncMigration")
+		case conf.EvalTimeout > 0:
+			return nil, errors.New("SingleThreaded can't be used with EvalTimeout")
 		}
 	}
 	if conf.Speciator != nil {
@@ -71,14 +220,146 @@ func (conf GAConfig) NewGA() (*GA, error) {
 			return nil, specErr
 		}
 	}
+	if conf.GlobalSpeciation && conf.Speciator == nil {
+		return nil, errors.New("GlobalSpeciation requires a Speciator")
+	}
+	if conf.FrontStop != nil {
+		if fsErr := conf.FrontStop.Validate(); fsErr != nil {
+			return nil, fsErr
+		}
+		if !conf.ParetoHallOfFame {
+			return nil, errors.New("FrontStop requires ParetoHallOfFame")
+		}
+	}
+	if conf.RealTime != nil {
+		if rtErr := conf.RealTime.Validate(); rtErr != nil {
+			return nil, rtErr
+		}
+		if conf.Novelty != nil {
+			return nil, errors.New("RealTime can't be used with Novelty")
+		}
+	}
+	if conf.HofInjection != nil {
+		if hiErr := conf.HofInjection.Validate(conf.PopSize, conf.HofSize); hiErr != nil {
+			return nil, hiErr
+		}
+	}
+	if conf.LogOptions != nil {
+		if loErr := conf.LogOptions.Validate(conf.NPops); loErr != nil {
+			return nil, loErr
+		}
+	}
+	if conf.Clustering != nil {
+		if clErr := conf.Clustering.Validate(); clErr != nil {
+			return nil, clErr
+		}
+	}
+	if conf.History != nil {
+		if hiErr := conf.History.Validate(); hiErr != nil {
+			return nil, hiErr
+		}
+	}
+	if conf.Novelty != nil {
+		if noErr := conf.Novelty.Validate(); noErr != nil {
+			return nil, noErr
+		}
+	}
+	if conf.Polish != nil {
+		if poErr := conf.Polish.Validate(); poErr != nil {
+			return nil, poErr
+		}
+	}
+	if conf.SensitivityAnalysis != nil {
+		if seErr := conf.SensitivityAnalysis.Validate(); seErr != nil {
+			return nil, seErr
+		}
+	}
+	return conf.warnings(), nil
+}
+
+// warnings returns the Warnings of a valid configuration.
+func (conf GAConfig) warnings() []Warning {
+	var (
+		warnings []Warning
+		warn     = func(field, message string) {
+			warnings = append(warnings, Warning{Field: field, Message: message})
+		}
+	)
+	if conf.Model != nil && len(conf.Models) > 0 {
+		warn("Model", "Models is set, hence Model is ignored")
+	}
+	if conf.Migrator != nil && conf.NPops == 1 {
+		warn("Migrator", "there is a single Population, hence no migration can occur")
+	}
+	if conf.Migrator == nil && conf.MigFrequency > 0 {
+		warn("MigFrequency", "Migrator isn't set, hence no migration occurs")
+	}
+	if conf.Migrator != nil && conf.MigFrequency > conf.NGenerations {
+		warn("MigFrequency", "MigFrequency exceeds NGenerations, hence no migration occurs")
+	}
+	if conf.AsyncMigration && conf.Callback != nil {
+		warn("Callback", "with AsyncMigration the Callback is only called once all the Populations are done")
+	}
+	if conf.AsyncMigration && conf.EarlyStop != nil {
+		warn("EarlyStop", "with AsyncMigration EarlyStop is only checked before the Populations are evolved")
+	}
+	if conf.GlobalSpeciation && conf.NPops == 1 {
+		warn("GlobalSpeciation", "there is a single Population, hence it makes no difference")
+	}
+	if conf.ParallelInit && conf.NPops == 1 {
+		warn("ParallelInit", "there is a single Population, hence it is initialized sequentially")
+	}
+	if conf.HofSize > conf.PopSize {
+		warn("HofSize", "HofSize exceeds PopSize, hence the hall of fame takes several generations to fill up")
+	}
+	if conf.LogOptions != nil && conf.Logger == nil && conf.SLogger == nil {
+		warn("LogOptions", "neither Logger nor SLogger is set, hence nothing is logged")
+	}
+	if conf.MaxEvaluations > 0 && conf.MaxEvaluations <= uint64(conf.NPops*conf.PopSize) {
+		warn("MaxEvaluations", "the initial Populations use up the evaluation budget")
+	}
+	return warnings
+}
+
+// NewGA returns a pointer to a GA instance and checks for configuration
+// errors. The warnings returned by Validate are stored in the GA's Warnings.
+func (conf GAConfig) NewGA() (*GA, error) {
+	// Check for default values
+	if conf.RNG == nil {
+		conf.RNG = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	var warnings, err = conf.Validate()
+	if err != nil {
+		return nil, err
+	}
 	// Initialize the GA
-	ga := &GA{GAConfig: conf}
+	ga := &GA{
+		GAConfig:    conf,
+		anytime:     new(anytimeState),
+		history:     new(bestHistory),
+		front:       new(frontState),
+		diagnostics: new(diagnosticsState),
+		novelty:     new(noveltyState),
+		Events:      new(Events),
+		Warnings:    warnings,
+		immigrants:  new(immigrants),
+	}
 	// As a special case (and grotesque hack), point ModSimulatedAnnealing
 	// to the GA
 	if msa, ok := conf.Model.(ModSimulatedAnnealing); ok {
 		msa.GA = ga
 		ga.GAConfig.Model = msa
 	}
+	if len(conf.Models) > 0 {
+		ga.GAConfig.Models = make([]Model, len(conf.Models))
+		for i, model := range conf.Models {
+			if msa, ok := model.(ModSimulatedAnnealing); ok {
+				msa.GA = ga
+				model = msa
+			}
+			ga.GAConfig.Models[i] = model
+		}
+	}
 	// Return the GA
 	return ga, nil
 }
@@ -97,6 +378,7 @@ func NewDefaultGAConfig() GAConfig {
 			MutRate:   0.5,
 			CrossRate: 0.7,
 		},
-		ParallelEval: false,
+		ParallelEval:   false,
+		SingleThreaded: defaultSingleThreaded,
 	}
 }
@@ -27,9 +27,34 @@ func TestNewGAErrors(t *testing.T) {
 		{func() GAConfig { c := NewDefaultGAConfig(); c.HofSize = 0; return c }()},
 		{func() GAConfig { c := NewDefaultGAConfig(); c.Model = nil; return c }()},
 		{func() GAConfig { c := NewDefaultGAConfig(); c.Model = ModValidateError{}; return c }()},
-		{func() GAConfig { c := NewDefaultGAConfig(); c.Migrator = MigRing{0}; return c }()},
-		{func() GAConfig { c := NewDefaultGAConfig(); c.Migrator = MigRing{1}; c.MigFrequency = 0; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.Migrator = MigRing{NMigrants: 0}; return c }()},
+		{func() GAConfig {
+			c := NewDefaultGAConfig()
+			c.Speciator = SpecFitnessInterval{2}
+			c.GlobalSpeciation = true
+			c.AsyncMigration = true
+			return c
+		}()},
 		{func() GAConfig { c := NewDefaultGAConfig(); c.Speciator = SpecValidateError{}; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.GlobalSpeciation = true; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.Models = []Model{ModIdentity{}, ModIdentity{}}; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.Models = []Model{nil}; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.Models = []Model{ModValidateError{}}; return c }()},
+		{func() GAConfig {
+			c := NewDefaultGAConfig()
+			c.HofInjection = &HofInjection{Frequency: 1, NIndividuals: 2}
+			return c
+		}()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.SingleThreaded = true; c.ParallelEval = true; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.SingleThreaded = true; c.ParallelInit = true; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.SingleThreaded = true; c.AsyncMigration = true; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.SingleThreaded = true; c.EvalTimeout = 1; return c }()},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.Polish = &PolishOptions{}; return c }()},
+		{func() GAConfig {
+			c := NewDefaultGAConfig()
+			c.Polish = &PolishOptions{Polisher: PolishPatternSearch{Shrink: 1}, Budget: 10}
+			return c
+		}()},
 	}
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
@@ -47,3 +72,79 @@ func TestNewDefaultGAConfig(t *testing.T) {
 		t.Errorf("Expected nil, got %v", err)
 	}
 }
+
+func TestGAConfigWarnings(t *testing.T) {
+	var testCases = []struct {
+		conf   GAConfig
+		fields []string
+	}{
+		{NewDefaultGAConfig(), nil},
+		{func() GAConfig {
+			c := NewDefaultGAConfig()
+			c.Migrator = MigRing{NMigrants: 1}
+			c.MigFrequency = 100
+			return c
+		}(),
+			[]string{"Migrator", "MigFrequency"}},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.MigFrequency = 5; return c }(), []string{"MigFrequency"}},
+		{func() GAConfig {
+			c := NewDefaultGAConfig()
+			c.NPops = 2
+			c.Models = []Model{ModIdentity{}, ModIdentity{}}
+			return c
+		}(), []string{"Model"}},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.ParallelInit = true; return c }(), []string{"ParallelInit"}},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.HofSize = 50; return c }(), []string{"HofSize"}},
+		{func() GAConfig { c := NewDefaultGAConfig(); c.MaxEvaluations = 30; return c }(), []string{"MaxEvaluations"}},
+		{func() GAConfig {
+			c := NewDefaultGAConfig()
+			c.Speciator = SpecFitnessInterval{K: 2}
+			c.GlobalSpeciation = true
+			return c
+		}(), []string{"GlobalSpeciation"}},
+	}
+	for i, tc := range testCases {
+		var warnings, err = tc.conf.Validate()
+		if err != nil {
+			t.Fatalf("TC %d: expected nil, got %v", i, err)
+		}
+		var fields []string
+		for _, w := range warnings {
+			fields = append(fields, w.Field)
+		}
+		if fmt.Sprint(fields) != fmt.Sprint(tc.fields) {
+			t.Errorf("TC %d: expected warnings about %v, got %v", i, tc.fields, warnings)
+		}
+	}
+	// NewGA reports the same errors and stores the warnings
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 0
+	if _, err := conf.Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+	conf = NewDefaultGAConfig()
+	conf.ParallelInit = true
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ga.Warnings) != 1 || ga.Warnings[0].String() != "ParallelInit: there is a single Population, hence it is initialized sequentially" {
+		t.Errorf("Unexpected warnings %v", ga.Warnings)
+	}
+}
+
+func TestGAWarnsCheapParallelEval(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.ParallelEval = true
+	conf.NGenerations = 1
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	if len(ga.Warnings) != 1 || ga.Warnings[0].Field != "ParallelEval" {
+		t.Errorf("Expected a warning about ParallelEval, got %v", ga.Warnings)
+	}
+}
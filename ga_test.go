@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"math"
 	"math/rand"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestUpdateHallOfFame(t *testing.T) {
@@ -192,7 +196,7 @@ func TestInitResetCounters(t *testing.T) {
 func TestEvolveWithMigrator(t *testing.T) {
 	var conf = NewDefaultGAConfig()
 	conf.NPops = 2
-	conf.Migrator = MigRing{3}
+	conf.Migrator = MigRing{NMigrants: 3}
 	conf.MigFrequency = 1
 	var ga, err = conf.NewGA()
 	if err != nil {
@@ -206,6 +210,40 @@ func TestEvolveWithMigrator(t *testing.T) {
 	}
 }
 
+func TestMigrationDue(t *testing.T) {
+	var testCases = []struct {
+		migrator     Migrator
+		migFrequency uint
+		generations  []uint // Generations 1 to 6 at which migrations occur
+	}{
+		{nil, 0, nil},
+		{nil, 2, nil},
+		// A MigFrequency of 0 used to be rejected, the Migrator now decides
+		{MigRing{NMigrants: 1}, 0, []uint{1, 2, 3, 4, 5, 6}},
+		{MigRing{NMigrants: 1, Frequency: 3}, 0, []uint{3, 6}},
+		{MigRing{NMigrants: 1, Frequency: 3}, 2, []uint{2, 4, 6}},
+	}
+	for i, tc := range testCases {
+		var conf = NewDefaultGAConfig()
+		conf.NPops = 2
+		conf.Migrator = tc.migrator
+		conf.MigFrequency = tc.migFrequency
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatalf("TC %d: expected nil, got %v", i, err)
+		}
+		var generations []uint
+		for g := uint(1); g <= 6; g++ {
+			if ga.migrationDue(g) {
+				generations = append(generations, g)
+			}
+		}
+		if !reflect.DeepEqual(generations, tc.generations) {
+			t.Errorf("TC %d: expected %v, got %v", i, tc.generations, generations)
+		}
+	}
+}
+
 func TestEvolveWithSpeciator(t *testing.T) {
 	var ga, err = NewDefaultGAConfig().NewGA()
 	if err != nil {
@@ -220,6 +258,76 @@ func TestEvolveWithSpeciator(t *testing.T) {
 	}
 }
 
+func TestEvolveWithGlobalSpeciation(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.Model = ModMutationOnly{Strict: true}
+	conf.Speciator = SpecFitnessInterval{3}
+	conf.GlobalSpeciation = true
+	conf.Migrator = MigSpecies{NMigrants: 3}
+	conf.MigFrequency = 1
+	conf.NGenerations = 5
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	for _, pop := range ga.Populations {
+		if len(pop.Individuals) != int(conf.PopSize) {
+			t.Errorf("Expected %d individuals, got %d", conf.PopSize, len(pop.Individuals))
+		}
+	}
+}
+
+func TestSpeciateGlobally(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.Speciator = SpecFitnessInterval{2}
+	conf.GlobalSpeciation = true
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	species, err := ga.speciateGlobally()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	// The global species split the Individuals of all the Populations around
+	// the median fitness
+	var worst0, best1 = math.Inf(-1), math.Inf(1)
+	var counts [2]int
+	for i, pop := range ga.Populations {
+		for j, indi := range pop.Individuals {
+			counts[species[i][j]]++
+			if species[i][j] == 0 {
+				worst0 = math.Max(worst0, indi.Fitness)
+			} else {
+				best1 = math.Min(best1, indi.Fitness)
+			}
+		}
+	}
+	if counts[0] != 45 || counts[1] != 45 {
+		t.Errorf("Expected 45 individuals per species, got %v", counts)
+	}
+	if worst0 > best1 {
+		t.Errorf("Species overlap: %f > %f", worst0, best1)
+	}
+	// Each Population evolves its share of each species
+	var groups = speciesOf(ga.Populations[0].Individuals, species[0])
+	var n int
+	for _, g := range groups {
+		n += len(g)
+	}
+	if n != len(ga.Populations[0].Individuals) {
+		t.Errorf("Expected %d individuals, got %d", len(ga.Populations[0].Individuals), n)
+	}
+}
+
 func TestGALog(t *testing.T) {
 	t.Skip("Skipping log test")
 	var ga, err = NewDefaultGAConfig().NewGA()
@@ -241,6 +349,302 @@ func TestGALog(t *testing.T) {
 	}
 }
 
+func TestGASLog(t *testing.T) {
+	var (
+		conf = NewDefaultGAConfig()
+		b    bytes.Buffer
+	)
+	conf.NPops = 2
+	conf.NGenerations = 3
+	conf.SLogger = slog.New(slog.NewJSONHandler(&b, nil))
+	conf.LogLevel = slog.LevelWarn
+	var ga, _ = conf.NewGA()
+	if err := ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var lines = strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2*4 {
+		t.Fatalf("Expected %d lines, got %d", 2*4, len(lines))
+	}
+	var record struct {
+		Level      string
+		PopID      string `json:"pop_id"`
+		Generation uint
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Level != "WARN" || record.Generation != 3 || record.PopID == "" {
+		t.Errorf("Wrong record: %s", lines[len(lines)-1])
+	}
+}
+
+func TestGALogOptions(t *testing.T) {
+	var testCases = []struct {
+		opts  LogOptions
+		lines []string // Population and generation of each line
+	}{
+		{LogOptions{Every: 2}, []string{"0 0", "1 0", "0 2", "1 2", "0 3", "1 3"}},
+		{LogOptions{Populations: []uint{1}}, []string{"1 0", "1 1", "1 2", "1 3"}},
+		{LogOptions{Every: 5, Aggregate: true}, []string{"all 0", "all 3"}},
+	}
+	for i, tc := range testCases {
+		var (
+			conf = NewDefaultGAConfig()
+			b    bytes.Buffer
+		)
+		conf.NPops = 2
+		conf.NGenerations = 3
+		conf.PopulationIDFunc = SequentialPopulationID
+		conf.SLogger = slog.New(slog.NewJSONHandler(&b, nil))
+		conf.LogOptions = &tc.opts
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ga.Minimize(NewVector); err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+			var record struct {
+				PopID      string `json:"pop_id"`
+				Generation uint
+			}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, fmt.Sprintf("%s %d", record.PopID, record.Generation))
+		}
+		if !reflect.DeepEqual(lines, tc.lines) {
+			t.Errorf("TC %d: expected %v, got %v", i, tc.lines, lines)
+		}
+	}
+	var conf = NewDefaultGAConfig()
+	conf.LogOptions = &LogOptions{Populations: []uint{1}}
+	if _, err := conf.NewGA(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestGALogOptionsQuartiles(t *testing.T) {
+	for i, quartiles := range []bool{false, true} {
+		var (
+			conf = NewDefaultGAConfig()
+			b    bytes.Buffer
+		)
+		conf.NGenerations = 1
+		conf.Logger = log.New(&b, "", 0)
+		conf.LogOptions = &LogOptions{Quartiles: quartiles}
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ga.Minimize(NewVector); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+			if strings.Contains(line, " median=") != quartiles {
+				t.Errorf("TC %d: unexpected line %q", i, line)
+			}
+		}
+	}
+}
+
+func TestAggregatePopulations(t *testing.T) {
+	var (
+		rng  = newRand()
+		pops = Populations{newPopulation(3, false, NewVector, rng), newPopulation(2, false, NewVector, rng)}
+		all  = aggregatePopulations(pops, 4, time.Second, SkipNonFinite)
+	)
+	if len(all.Individuals) != 5 || all.ID != "all" || all.Generations != 4 || all.StatsPolicy != SkipNonFinite {
+		t.Errorf("Wrong aggregate %+v", all)
+	}
+}
+
+func TestGAPopulationIDFunc(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.NGenerations = 2
+	conf.PopulationIDFunc = SequentialPopulationID
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, _ = conf.NewGA()
+	if err := ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for i, pop := range ga.Populations {
+		if pop.ID != fmt.Sprint(i) {
+			t.Errorf("Expected %d, got %s", i, pop.ID)
+		}
+	}
+	// The IDs are kept when resuming
+	var b, _ = json.Marshal(ga)
+	ga2, _ := conf.NewGA()
+	if err := ga2.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := ga2.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for i, pop := range ga2.Populations {
+		if pop.ID != fmt.Sprint(i) {
+			t.Errorf("Expected %d, got %s", i, pop.ID)
+		}
+	}
+	// Duplicate IDs
+	conf.PopulationIDFunc = func(i uint, rng *rand.Rand) string { return "pop" }
+	ga, _ = conf.NewGA()
+	if err := ga.Minimize(NewVector); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestGAModels(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.NGenerations = 10
+	conf.Model = nil
+	conf.Models = []Model{
+		ModIdentity{},
+		ModGenerational{Selector: SelTournament{NContestants: 3}, MutRate: 0.5, CrossRate: 0.7},
+		ModSimulatedAnnealing{Accept: func(gen, nGen uint, e0, e1 float64) float64 { return 0 }},
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ga.Models[2].(ModSimulatedAnnealing).GA != ga {
+		t.Error("ModSimulatedAnnealing should point to the GA")
+	}
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var initial = append(Individuals{}, ga.Populations[0].Individuals...)
+	if err = ga.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// Only the first Population is left untouched
+	for i, indi := range ga.Populations[0].Individuals {
+		if indi.ID != initial[i].ID {
+			t.Errorf("The first Population should not have evolved")
+		}
+	}
+	if ga.Populations[1].Individuals[0].Fitness >= ga.Populations[0].Individuals[0].Fitness {
+		t.Errorf("The second Population should have evolved")
+	}
+	// Errors are returned
+	conf.Models[1] = ModRuntimeError{}
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+// countedVector counts the calls to its Evaluate method.
+type countedVector struct {
+	Vector
+	calls *atomic.Int64
+}
+
+func (cv countedVector) Evaluate() (float64, error) {
+	cv.calls.Add(1)
+	return cv.Vector.Evaluate()
+}
+
+func (cv countedVector) Crossover(y Genome, rng *rand.Rand) {
+	cv.Vector.Crossover(y.(countedVector).Vector, rng)
+}
+
+func (cv countedVector) Clone() Genome {
+	return countedVector{cv.Vector.Clone().(Vector), cv.calls}
+}
+
+func TestGAEvaluations(t *testing.T) {
+	var (
+		calls     atomic.Int64
+		newGenome = func(rng *rand.Rand) Genome {
+			return countedVector{NewVector(rng).(Vector), &calls}
+		}
+		conf = NewDefaultGAConfig()
+	)
+	conf.NPops = 2
+	conf.NGenerations = 10
+	conf.ParallelEval = true
+	conf.Migrator = MigRing{NMigrants: 2}
+	conf.MigFrequency = 3
+	var ga, _ = conf.NewGA()
+	if ga.Evaluations() != 0 {
+		t.Errorf("Expected 0, got %d", ga.Evaluations())
+	}
+	if err := ga.Minimize(newGenome); err != nil {
+		t.Fatal(err)
+	}
+	if ga.Evaluations() != uint64(calls.Load()) || ga.Evaluations() < 2*30 {
+		t.Errorf("Expected %d, got %d", calls.Load(), ga.Evaluations())
+	}
+	// The count is reset along with the Populations
+	ga.Populations = nil
+	calls.Store(0)
+	ga.NGenerations = 1
+	if err := ga.Minimize(newGenome); err != nil {
+		t.Fatal(err)
+	}
+	if ga.Evaluations() != uint64(calls.Load()) {
+		t.Errorf("Expected %d, got %d", calls.Load(), ga.Evaluations())
+	}
+}
+
+func TestGAMetadata(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 10
+	conf.Migrator = MigRing{NMigrants: 2}
+	conf.MigFrequency = 2
+	var ga, _ = conf.NewGA()
+	if err := ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for _, pop := range ga.Populations {
+		for i := range pop.Individuals {
+			pop.Individuals[i].Metadata = map[string]interface{}{"origin": pop.ID}
+		}
+	}
+	if err := ga.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// Offsprings inherit the Metadata of their parents, including through
+	// selection and migration
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			if _, ok := indi.Metadata["origin"]; !ok {
+				t.Fatalf("Metadata has been lost: %v", indi)
+			}
+		}
+	}
+}
+
+func TestGAMaxEvaluations(t *testing.T) {
+	var (
+		conf     = NewDefaultGAConfig()
+		previous uint64
+	)
+	conf.NGenerations = 1000
+	conf.MaxEvaluations = 100
+	conf.Callback = func(ga *GA) {
+		if previous >= 100 {
+			t.Errorf("The GA should have stopped after %d evaluations", previous)
+		}
+		previous = ga.Evaluations()
+	}
+	var ga, _ = conf.NewGA()
+	if err := ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	if ga.Evaluations() < 100 || ga.Generations == conf.NGenerations {
+		t.Errorf("Expected to stop early, got %d evaluations in %d generations", ga.Evaluations(), ga.Generations)
+	}
+}
+
 func TestSpeciateEvolveMerge(t *testing.T) {
 	var (
 		rng       = newRand()
@@ -303,7 +707,7 @@ func TestSpeciateEvolveMerge(t *testing.T) {
 	)
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
-			var err = tc.pop.speciateEvolveMerge(tc.speciator, tc.model)
+			var _, err = tc.pop.speciateEvolveMerge(tc.speciator, tc.model)
 			if (err == nil) != (tc.err == nil) {
 				t.Errorf("Wrong error in test case number %d", i)
 			}
@@ -461,6 +865,13 @@ func TestGAJSONMarshaling(t *testing.T) {
 	}
 	ga2.HallOfFame.Evaluate(true)
 
+	// The evaluation context isn't marshaled and the durations of the new
+	// evaluations differ
+	for i := range ga1.HallOfFame {
+		ga1.HallOfFame[i].eval = nil
+		ga1.HallOfFame[i].EvalDuration = 0
+		ga2.HallOfFame[i].EvalDuration = 0
+	}
 	if !reflect.DeepEqual(ga1.HallOfFame, ga2.HallOfFame) {
 		t.Fatal("Expected HAFs to be equal")
 	}
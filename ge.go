@@ -0,0 +1,119 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// A GEGenome is a genome used for grammatical evolution. It is made of integer
+// codons which are mapped to a phenotype with the Grammar of the GE it belongs
+// to.
+type GEGenome struct {
+	Codons []int
+	GE     *GE
+}
+
+// Phenotype maps the codons of the GEGenome to a DerivationTree.
+func (g *GEGenome) Phenotype() (*DerivationTree, error) {
+	return g.GE.Grammar.Map(g.Codons, g.GE.MaxWraps)
+}
+
+// Evaluate the GEGenome by mapping it to a phenotype and calling the GE's
+// objective function on it. Genomes which can't be mapped within the allowed
+// number of wraps are given an infinite fitness.
+func (g *GEGenome) Evaluate() (float64, error) {
+	var tree, err = g.Phenotype()
+	if err == errIncompleteMapping {
+		return math.Inf(1), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return g.GE.F(tree)
+}
+
+// Mutate the GEGenome by replacing each codon with a random codon with
+// probability MutRate.
+func (g *GEGenome) Mutate(rng *rand.Rand) {
+	for i := range g.Codons {
+		if rng.Float64() < g.GE.MutRate {
+			g.Codons[i] = rng.Intn(g.GE.CodonMax)
+		}
+	}
+}
+
+// Crossover applies one-point crossover to the codons of two GEGenomes.
+func (g *GEGenome) Crossover(q Genome, rng *rand.Rand) {
+	if len(g.Codons) < 2 {
+		return
+	}
+	CrossGNXInt(g.Codons, q.(*GEGenome).Codons, 1, rng)
+}
+
+// Clone returns a deep copy of the GEGenome.
+func (g GEGenome) Clone() Genome {
+	var codons = make([]int, len(g.Codons))
+	copy(codons, g.Codons)
+	return &GEGenome{
+		Codons: codons,
+		GE:     g.GE,
+	}
+}
+
+// GE contains the settings shared by the GEGenomes of a grammatical evolution
+// run. Its NewGenome method can be handed to GA.Minimize so that grammatical
+// evolution runs on top of any Model.
+// Reference: https://doi.org/10.1109/4235.942529
+type GE struct {
+	Grammar  *Grammar
+	NCodons  uint    // Number of codons in each genome
+	CodonMax int     // Codons are sampled in [0, CodonMax)
+	MaxWraps uint    // Number of times the codons can be reused
+	MutRate  float64 // Probability of replacing each codon during mutation
+	F        func(phenotype *DerivationTree) (float64, error)
+}
+
+// NewGE instantiates and returns a GE instance after having checked for input
+// errors.
+func NewGE(grammar *Grammar, nCodons uint, codonMax int, maxWraps uint, mutRate float64,
+	f func(phenotype *DerivationTree) (float64, error)) (*GE, error) {
+	if grammar == nil {
+		return nil, errors.New("grammar cannot be nil")
+	}
+	if err := grammar.Validate(); err != nil {
+		return nil, err
+	}
+	if nCodons == 0 {
+		return nil, errors.New("nCodons should be higher than 0")
+	}
+	if codonMax < 2 {
+		return nil, errors.New("codonMax should be at least 2")
+	}
+	if mutRate < 0 || mutRate > 1 {
+		return nil, errInvalidMutRate
+	}
+	if f == nil {
+		return nil, errors.New("f cannot be nil")
+	}
+	return &GE{
+		Grammar:  grammar,
+		NCodons:  nCodons,
+		CodonMax: codonMax,
+		MaxWraps: maxWraps,
+		MutRate:  mutRate,
+		F:        f,
+	}, nil
+}
+
+// NewGenome returns a GEGenome with random codons.
+func (ge *GE) NewGenome(rng *rand.Rand) Genome {
+	var codons = make([]int, ge.NCodons)
+	for i := range codons {
+		codons[i] = rng.Intn(ge.CodonMax)
+	}
+	return &GEGenome{
+		Codons: codons,
+		GE:     ge,
+	}
+}
@@ -0,0 +1,79 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+// countX is a GE objective that rewards expressions with many x terminals.
+func countX(tree *DerivationTree) (float64, error) {
+	var n float64
+	for _, r := range tree.String() {
+		if r == 'x' {
+			n--
+		}
+	}
+	return n, nil
+}
+
+func TestNewGEErrors(t *testing.T) {
+	var g, _ = ParseGrammar(testBNF)
+	var testCases = []func() (*GE, error){
+		func() (*GE, error) { return NewGE(nil, 10, 256, 2, 0.1, countX) },
+		func() (*GE, error) { return NewGE(&Grammar{}, 10, 256, 2, 0.1, countX) },
+		func() (*GE, error) { return NewGE(g, 0, 256, 2, 0.1, countX) },
+		func() (*GE, error) { return NewGE(g, 10, 1, 2, 0.1, countX) },
+		func() (*GE, error) { return NewGE(g, 10, 256, 2, 1.1, countX) },
+		func() (*GE, error) { return NewGE(g, 10, 256, 2, 0.1, nil) },
+	}
+	for _, tc := range testCases {
+		if _, err := tc(); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	}
+}
+
+func TestGEGenome(t *testing.T) {
+	var (
+		rng     = newRand()
+		g, _    = ParseGrammar(testBNF)
+		ge, err = NewGE(g, 10, 256, 2, 1, countX)
+	)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var (
+		a     = ge.NewGenome(rng).(*GEGenome)
+		clone = a.Clone().(*GEGenome)
+	)
+	if len(a.Codons) != 10 {
+		t.Errorf("Expected 10, got %d", len(a.Codons))
+	}
+	clone.Mutate(rng)
+	for _, c := range clone.Codons {
+		if c < 0 || c >= 256 {
+			t.Errorf("Codon %d out of bounds", c)
+		}
+	}
+	var b = ge.NewGenome(rng)
+	clone.Crossover(b, rng)
+	// Unmappable genomes get the worst fitness
+	var bad = &GEGenome{Codons: []int{0}, GE: ge}
+	if f, err := bad.Evaluate(); err != nil || !math.IsInf(f, 1) {
+		t.Errorf("Expected +Inf and nil, got %f and %v", f, err)
+	}
+}
+
+func TestGEMinimize(t *testing.T) {
+	var (
+		g, _  = ParseGrammar(testBNF)
+		ge, _ = NewGE(g, 20, 256, 2, 0.1, countX)
+		ga, _ = NewDefaultGAConfig().NewGA()
+	)
+	if err := ga.Minimize(ge.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if ga.HallOfFame[0].Fitness >= 0 {
+		t.Errorf("Expected a negative fitness, got %f", ga.HallOfFame[0].Fitness)
+	}
+}
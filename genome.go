@@ -11,3 +11,30 @@ type Genome interface {
 	Crossover(genome Genome, rng *rand.Rand)
 	Clone() Genome
 }
+
+// A Breeder is a Genome whose crossover produces new Genomes instead of
+// modifying the parents in place. Breed is given the mates of the Breeder and
+// can return any number of offsprings, which makes it possible to implement
+// operators that yield a single offspring, such as headless-chicken crossover,
+// or that combine more than two parents. When a Genome is a Breeder the models
+// call Breed instead of Crossover, on the fittest parent with the other
+// parents as mates sorted by increasing fitness.
+type Breeder interface {
+	Breed(mates []Genome, rng *rand.Rand) []Genome
+}
+
+// A MultiFidelity Genome can be evaluated with a budget, for instance a number
+// of training epochs or of simulation steps. Evaluations with a low budget are
+// cheap approximations of the ones with a high budget, which Hyperband and
+// SuccessiveHalving rely on to discard bad candidates early.
+type MultiFidelity interface {
+	EvaluateAt(budget float64) (float64, error)
+}
+
+// A Validatable Genome can check its own invariants, for instance that a
+// permutation doesn't contain duplicates or that a tree isn't too deep. When
+// GAConfig.ValidateGenomes is true, Validate is called on each Genome produced
+// by mutation or crossover, which helps finding buggy operators.
+type Validatable interface {
+	Validate() error
+}
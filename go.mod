@@ -5,7 +5,9 @@ go 1.22
 toolchain go1.22.5
 
 require (
+	github.com/klauspost/compress v1.18.0
 	github.com/pkg/errors v0.9.1
 	github.com/tsenart/kth v0.0.0-20241130134941-217ea3d4d3dc
 	golang.org/x/sync v0.0.0-20201020160332-67f06af15bc9
+	gopkg.in/yaml.v3 v3.0.1
 )
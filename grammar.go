@@ -0,0 +1,244 @@
+package eaopt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errIncompleteMapping = errors.New("ran out of codons before the derivation was complete")
+
+// A Production is one of the alternatives of a grammar rule, it is a sequence
+// of terminal and non-terminal symbols. Non-terminal symbols are written
+// between angle brackets, for example "<expr>".
+type Production []string
+
+// A Grammar is a context-free grammar in Backus-Naur form. Rules map each
+// non-terminal to its alternative productions. Start is the non-terminal
+// from which every derivation begins.
+type Grammar struct {
+	Start string
+	Rules map[string][]Production
+}
+
+// isNonTerminal checks if a symbol is a non-terminal.
+func isNonTerminal(symbol string) bool {
+	return len(symbol) > 2 && symbol[0] == '<' && symbol[len(symbol)-1] == '>'
+}
+
+// ParseGrammar reads a grammar written in BNF. Each rule has the form
+//
+//	<expr> ::= <expr> <op> <expr> | <var>
+//
+// and alternatives may continue on the following lines as long as these start
+// with a "|". Symbols are separated by whitespace, terminals may be quoted in
+// order to contain whitespace. Lines starting with "#" are ignored. The first
+// rule defines the start symbol.
+func ParseGrammar(bnf string) (*Grammar, error) {
+	var (
+		grammar = &Grammar{Rules: make(map[string][]Production)}
+		scanner = bufio.NewScanner(strings.NewReader(bnf))
+		lhs     string
+		lineNo  int
+	)
+	for scanner.Scan() {
+		lineNo++
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rhs string
+		if strings.HasPrefix(line, "|") {
+			if lhs == "" {
+				return nil, fmt.Errorf("line %d: alternative without a rule", lineNo)
+			}
+			rhs = line[1:]
+		} else {
+			var parts = strings.SplitN(line, "::=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("line %d: expected \"::=\"", lineNo)
+			}
+			lhs = strings.TrimSpace(parts[0])
+			if !isNonTerminal(lhs) {
+				return nil, fmt.Errorf("line %d: %q is not a non-terminal", lineNo, lhs)
+			}
+			if grammar.Start == "" {
+				grammar.Start = lhs
+			}
+			rhs = parts[1]
+		}
+		for _, alt := range splitAlternatives(rhs) {
+			var prod, err = tokenizeProduction(alt)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			grammar.Rules[lhs] = append(grammar.Rules[lhs], prod)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := grammar.Validate(); err != nil {
+		return nil, err
+	}
+	return grammar, nil
+}
+
+// splitAlternatives splits the right hand side of a rule on the "|" characters
+// that are not quoted.
+func splitAlternatives(rhs string) []string {
+	var (
+		alts   []string
+		quoted bool
+		start  int
+	)
+	for i, r := range rhs {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '|' && !quoted:
+			alts = append(alts, rhs[start:i])
+			start = i + 1
+		}
+	}
+	return append(alts, rhs[start:])
+}
+
+// tokenizeProduction splits an alternative into symbols.
+func tokenizeProduction(alt string) (Production, error) {
+	var (
+		prod Production
+		rest = strings.TrimSpace(alt)
+	)
+	for rest != "" {
+		if rest[0] == '"' {
+			var end = strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, errors.New("unterminated quoted terminal")
+			}
+			var terminal, err = strconv.Unquote(rest[:end+2])
+			if err != nil {
+				return nil, err
+			}
+			prod = append(prod, terminal)
+			rest = strings.TrimSpace(rest[end+2:])
+			continue
+		}
+		var end = strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		prod = append(prod, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	if len(prod) == 0 {
+		return nil, errors.New("empty alternative")
+	}
+	return prod, nil
+}
+
+// Validate checks that the start symbol has a rule and that every non-terminal
+// used in a production is defined.
+func (g Grammar) Validate() error {
+	if _, ok := g.Rules[g.Start]; !ok {
+		return fmt.Errorf("start symbol %q has no rule", g.Start)
+	}
+	for lhs, prods := range g.Rules {
+		for _, prod := range prods {
+			for _, symbol := range prod {
+				if !isNonTerminal(symbol) {
+					continue
+				}
+				if _, ok := g.Rules[symbol]; !ok {
+					return fmt.Errorf("non-terminal %s used in %s is not defined", symbol, lhs)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// A DerivationTree is the result of mapping a sequence of codons with a
+// Grammar. Leaves are terminals, internal nodes are non-terminals.
+type DerivationTree struct {
+	Symbol   string
+	Children []*DerivationTree
+}
+
+// String concatenates the terminals of the tree from left to right.
+func (tree *DerivationTree) String() string {
+	var sb strings.Builder
+	tree.write(&sb)
+	return sb.String()
+}
+
+func (tree *DerivationTree) write(sb *strings.Builder) {
+	if len(tree.Children) == 0 && !isNonTerminal(tree.Symbol) {
+		sb.WriteString(tree.Symbol)
+		return
+	}
+	for _, child := range tree.Children {
+		child.write(sb)
+	}
+}
+
+// A grammarMapper keeps track of the codons consumed during a derivation.
+type grammarMapper struct {
+	grammar  *Grammar
+	codons   []int
+	i        int
+	wraps    uint
+	maxWraps uint
+}
+
+func (m *grammarMapper) expand(symbol string) (*DerivationTree, error) {
+	var node = &DerivationTree{Symbol: symbol}
+	if !isNonTerminal(symbol) {
+		return node, nil
+	}
+	var prods = m.grammar.Rules[symbol]
+	if len(prods) == 0 {
+		return nil, fmt.Errorf("non-terminal %s is not defined", symbol)
+	}
+	// Rules with a single production don't consume a codon
+	var choice int
+	if len(prods) > 1 {
+		if m.i == len(m.codons) {
+			if m.wraps == m.maxWraps || len(m.codons) == 0 {
+				return nil, errIncompleteMapping
+			}
+			m.i = 0
+			m.wraps++
+		}
+		choice = m.codons[m.i] % len(prods)
+		if choice < 0 {
+			choice += len(prods)
+		}
+		m.i++
+	}
+	for _, s := range prods[choice] {
+		var child, err = m.expand(s)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// Map derives a DerivationTree from a sequence of codons. The leftmost
+// non-terminal is always expanded first, the production is chosen by taking
+// the current codon modulo the number of alternatives. If the codons are
+// exhausted before the derivation is complete then the codons are reused from
+// the start, this is called wrapping. An error is returned if the derivation
+// is still incomplete after maxWraps wraps.
+func (g *Grammar) Map(codons []int, maxWraps uint) (*DerivationTree, error) {
+	var m = &grammarMapper{
+		grammar:  g,
+		codons:   codons,
+		maxWraps: maxWraps,
+	}
+	return m.expand(g.Start)
+}
@@ -0,0 +1,78 @@
+package eaopt
+
+import (
+	"fmt"
+	"testing"
+)
+
+const testBNF = `
+# Simple arithmetic expressions
+<expr> ::= <expr> <op> <expr> | <var>
+<op>   ::= "+" | "-"
+     | "*"
+<var>  ::= x | y
+`
+
+func TestParseGrammar(t *testing.T) {
+	var g, err = ParseGrammar(testBNF)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if g.Start != "<expr>" {
+		t.Errorf("Expected <expr>, got %s", g.Start)
+	}
+	for symbol, n := range map[string]int{"<expr>": 2, "<op>": 3, "<var>": 2} {
+		if len(g.Rules[symbol]) != n {
+			t.Errorf("Expected %d productions for %s, got %d", n, symbol, len(g.Rules[symbol]))
+		}
+	}
+}
+
+func TestParseGrammarErrors(t *testing.T) {
+	var testCases = []string{
+		"",
+		"| x",
+		"<a> x",
+		"a ::= x",
+		"<a> ::= <b>",
+		"<a> ::= \"x",
+		"<a> ::= x | ",
+	}
+	for i, bnf := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			if _, err := ParseGrammar(bnf); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGrammarMap(t *testing.T) {
+	var g, _ = ParseGrammar(testBNF)
+	var testCases = []struct {
+		codons   []int
+		maxWraps uint
+		out      string
+		err      error
+	}{
+		{[]int{1, 0}, 0, "x", nil},
+		{[]int{1, 3}, 0, "y", nil},
+		{[]int{0, 1, 0, 2, 1, 1}, 0, "x*y", nil},
+		{[]int{0, 1, 0, 1}, 0, "", errIncompleteMapping},
+		{[]int{0, 1, 0, 1, 1}, 1, "x-x", nil},
+		{[]int{0}, 3, "", errIncompleteMapping},
+		{[]int{}, 3, "", errIncompleteMapping},
+		{[]int{-1, -2}, 0, "x", nil},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var tree, err = g.Map(tc.codons, tc.maxWraps)
+			if err != tc.err {
+				t.Fatalf("Expected %v, got %v", tc.err, err)
+			}
+			if err == nil && tree.String() != tc.out {
+				t.Errorf("Expected %s, got %s", tc.out, tree.String())
+			}
+		})
+	}
+}
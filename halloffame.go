@@ -0,0 +1,228 @@
+package eaopt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// errNilGenomeJSONUnmarshaler is returned when JSON encoded Genomes have to be
+// decoded without a GenomeJSONUnmarshaler.
+var errNilGenomeJSONUnmarshaler = errors.New("GenomeJSONUnmarshaler has to be provided")
+
+// unmarshalIndividualsJSON decodes JSON encoded Individuals, their Genomes are
+// decoded with unmarshal. The Individuals are not marked as evaluated so that
+// their fitness can be checked by evaluating them again, a null violation is
+// decoded as +Inf.
+func unmarshalIndividualsJSON(data []byte, unmarshal func([]byte) (Genome, error)) (Individuals, error) {
+	var decoded []struct {
+		Genome       json.RawMessage        `json:"genome"`
+		Fitness      *float64               `json:"fitness"`
+		Violation    nullableFloat64        `json:"violation"`
+		ID           string                 `json:"id"`
+		Metadata     map[string]interface{} `json:"metadata"`
+		EvalDuration time.Duration          `json:"eval_duration"`
+		Pending      bool                   `json:"pending"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	var indis = make(Individuals, len(decoded))
+	for i, d := range decoded {
+		indis[i] = Individual{
+			Fitness:      math.Inf(1),
+			Violation:    float64(d.Violation),
+			ID:           d.ID,
+			Metadata:     d.Metadata,
+			EvalDuration: d.EvalDuration,
+			pending:      d.Pending,
+		}
+		if d.Fitness != nil {
+			indis[i].Fitness = *d.Fitness
+		}
+		// Placeholders of a hall of fame that isn't full yet don't have a Genome
+		if len(d.Genome) == 0 || string(d.Genome) == "null" {
+			indis[i].Evaluated = true
+			continue
+		}
+		var genome, err = unmarshal(d.Genome)
+		if err != nil {
+			return nil, err
+		}
+		indis[i].Genome = genome
+	}
+	return indis, nil
+}
+
+// MarshalHallOfFame encodes the GA's hall of fame to JSON, independently of the
+// Populations. Placeholders for Individuals that haven't been found yet, which
+// have an infinite fitness, are encoded with a null fitness.
+func (ga *GA) MarshalHallOfFame() ([]byte, error) {
+	return json.Marshal(ga.HallOfFame)
+}
+
+// UnmarshalHallOfFame replaces the GA's hall of fame with the one encoded by
+// MarshalHallOfFame. The Genomes are decoded with the GA's
+// GenomeJSONUnmarshaler. The hall of fame is truncated or padded to HofSize.
+// As with a GA resumed from JSON, the Individuals are evaluated again by Init
+// which checks their fitnesses haven't changed.
+func (ga *GA) UnmarshalHallOfFame(data []byte) error {
+	if ga.GenomeJSONUnmarshaler == nil {
+		return errNilGenomeJSONUnmarshaler
+	}
+	var hof, err = unmarshalIndividualsJSON(data, ga.GenomeJSONUnmarshaler)
+	if err != nil {
+		return err
+	}
+	ga.setHallOfFame(hof)
+	return nil
+}
+
+// MarshalHallOfFameBinary encodes the GA's hall of fame with encoding/gob,
+// which is more compact than JSON. The concrete types of the Genomes have to
+// be registered with gob.Register.
+func (ga *GA) MarshalHallOfFameBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ga.HallOfFame); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalHallOfFameBinary replaces the GA's hall of fame with the one
+// encoded by MarshalHallOfFameBinary. See UnmarshalHallOfFame.
+func (ga *GA) UnmarshalHallOfFameBinary(data []byte) error {
+	var hof Individuals
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&hof); err != nil {
+		return err
+	}
+	for i := range hof {
+		hof[i].Evaluated = hof[i].Genome == nil
+	}
+	ga.setHallOfFame(hof)
+	return nil
+}
+
+// best returns the best member of the hall of fame, or a placeholder with an
+// infinite fitness if the hall of fame is empty, which is the case of a Pareto
+// front as long as no Individual has Objectives, for instance because every
+// evaluation timed out.
+func (ga *GA) best() Individual {
+	if len(ga.HallOfFame) == 0 {
+		return Individual{Fitness: math.Inf(1)}
+	}
+	return ga.HallOfFame[0]
+}
+
+// setHallOfFame replaces the hall of fame and makes sure it contains HofSize
+// Individuals, or at most HofSize with ParetoHallOfFame. An empty hall of fame
+// is left empty so that Init builds it from the Populations.
+func (ga *GA) setHallOfFame(hof Individuals) {
+	if len(hof) > 0 && ga.HofSize > 0 {
+		if uint(len(hof)) > ga.HofSize {
+			hof = hof[:ga.HofSize]
+		}
+		for !ga.ParetoHallOfFame && uint(len(hof)) < ga.HofSize {
+			hof = append(hof, Individual{Fitness: math.Inf(1), Evaluated: true})
+		}
+	}
+	ga.HallOfFame = hof
+}
+
+// HofInjection periodically reinjects copies of the hall of fame into the
+// Populations which are struggling, which is a common elitist reseeding trick.
+// Every Frequency generations the NIndividuals worst Individuals of each
+// struggling Population are replaced with clones of the NIndividuals best
+// Individuals of the hall of fame. Each clone is mutated with probability
+// MutRate. A Population is struggling if its best fitness hasn't improved
+// during the last Stagnation generations; if Stagnation is 0 then every
+// Population receives the hall of fame.
+type HofInjection struct {
+	Frequency    uint    `json:"frequency"`
+	NIndividuals uint    `json:"n_individuals"`
+	MutRate      float64 `json:"mut_rate"`
+	Stagnation   uint    `json:"stagnation"`
+}
+
+// Validate HofInjection fields against the GA's configuration.
+func (hi HofInjection) Validate(popSize, hofSize uint) error {
+	if hi.Frequency == 0 {
+		return errors.New("Frequency should be higher than 0")
+	}
+	if hi.NIndividuals == 0 || hi.NIndividuals > hofSize || hi.NIndividuals > popSize {
+		return errors.New("NIndividuals should be between 1 and min(HofSize, PopSize)")
+	}
+	if hi.MutRate < 0 || hi.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	return nil
+}
+
+// injectHallOfFame records the progress of each Population and applies the
+// GA's HofInjection if the generation count is a multiple of its frequency.
+// The Populations are expected to be sorted.
+func (ga *GA) injectHallOfFame() error {
+	var hi = ga.HofInjection
+	if len(ga.injectionBests) != len(ga.Populations) {
+		ga.injectionBests = make([]float64, len(ga.Populations))
+		ga.injectionStale = make([]uint, len(ga.Populations))
+		for i := range ga.injectionBests {
+			ga.injectionBests[i] = math.Inf(1)
+		}
+	}
+	for i, pop := range ga.Populations {
+		if best := pop.Individuals[0].Fitness; best < ga.injectionBests[i] {
+			ga.injectionBests[i] = best
+			ga.injectionStale[i] = 0
+		} else {
+			ga.injectionStale[i]++
+		}
+	}
+	if ga.Generations%hi.Frequency != 0 {
+		return nil
+	}
+	var struggling = make(map[*Population]bool)
+	for i := range ga.Populations {
+		if hi.Stagnation == 0 || ga.injectionStale[i] >= hi.Stagnation {
+			struggling[&ga.Populations[i]] = true
+		}
+	}
+	var f = func(pop *Population) error {
+		if !struggling[pop] {
+			return nil
+		}
+		// SortByFitness only guarantees that the best Individual comes first,
+		// the worst Individuals are found with a full sort
+		sort.Slice(pop.Individuals, func(i, j int) bool {
+			return pop.Individuals[i].Better(pop.Individuals[j])
+		})
+		var (
+			n     = len(pop.Individuals)
+			start = n
+		)
+		for _, indi := range ga.HallOfFame[:minInt(int(hi.NIndividuals), len(ga.HallOfFame))] {
+			// Placeholders of a hall of fame that isn't full yet are skipped
+			if indi.Genome == nil {
+				continue
+			}
+			start--
+			pop.Individuals[start] = indi.Clone(pop.RNG)
+			if pop.RNG.Float64() < hi.MutRate {
+				pop.Individuals[start].Mutate(pop.RNG)
+			}
+		}
+		if start == n {
+			return nil
+		}
+		if err := pop.Individuals[start:].Evaluate(ga.ParallelEval); err != nil {
+			return err
+		}
+		pop.Individuals.SortByFitness()
+		return nil
+	}
+	return ga.applyPopulations(f)
+}
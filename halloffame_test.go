@@ -0,0 +1,183 @@
+package eaopt
+
+import (
+	"encoding/gob"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	gob.Register(Vector{})
+}
+
+func newHallOfFameGA(t *testing.T, hofSize uint) *GA {
+	var conf = NewDefaultGAConfig()
+	conf.HofSize = hofSize
+	conf.NGenerations = 5
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ga
+}
+
+func TestHallOfFameRoundTrip(t *testing.T) {
+	var ga = newHallOfFameGA(t, 3)
+	if err := ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	// Add a placeholder to make sure infinite fitnesses are handled
+	ga.HallOfFame[2] = Individual{Fitness: math.Inf(1)}
+	ga.HallOfFame[0].Metadata = map[string]interface{}{"origin": "seed"}
+	var codecs = []struct {
+		marshal   func() ([]byte, error)
+		unmarshal func(ga *GA, data []byte) error
+	}{
+		{ga.MarshalHallOfFame, (*GA).UnmarshalHallOfFame},
+		{ga.MarshalHallOfFameBinary, (*GA).UnmarshalHallOfFameBinary},
+	}
+	for i, codec := range codecs {
+		var data, err = codec.marshal()
+		if err != nil {
+			t.Fatalf("Error in codec number %d: %v", i, err)
+		}
+		var ga2 = newHallOfFameGA(t, 3)
+		if err = codec.unmarshal(ga2, data); err != nil {
+			t.Fatalf("Error in codec number %d: %v", i, err)
+		}
+		for j, indi := range ga2.HallOfFame {
+			var expected = ga.HallOfFame[j]
+			if indi.ID != expected.ID || indi.Fitness != expected.Fitness ||
+				!reflect.DeepEqual(indi.Genome, expected.Genome) ||
+				!reflect.DeepEqual(indi.Metadata, expected.Metadata) {
+				t.Errorf("Error in codec number %d: expected %v, got %v", i, expected, indi)
+			}
+			if indi.Evaluated != (indi.Genome == nil) {
+				t.Errorf("Error in codec number %d: only placeholders should be evaluated", i)
+			}
+		}
+		// The hall of fame survives the resume and is checked by Init
+		ga2.NGenerations = 1
+		if err = ga2.Minimize(NewVector); err != nil {
+			t.Fatalf("Error in codec number %d: %v", i, err)
+		}
+		if ga2.HallOfFame[0].Fitness > ga.HallOfFame[0].Fitness {
+			t.Errorf("Error in codec number %d: the hall of fame has been reset", i)
+		}
+	}
+}
+
+func TestHallOfFameResize(t *testing.T) {
+	var ga = newHallOfFameGA(t, 3)
+	ga.Minimize(NewVector)
+	var data, _ = ga.MarshalHallOfFame()
+	for _, size := range []uint{1, 5} {
+		var ga2 = newHallOfFameGA(t, size)
+		if err := ga2.UnmarshalHallOfFame(data); err != nil {
+			t.Fatal(err)
+		}
+		if uint(len(ga2.HallOfFame)) != size {
+			t.Errorf("Expected %d individuals, got %d", size, len(ga2.HallOfFame))
+		}
+		if ga2.HallOfFame[0].ID != ga.HallOfFame[0].ID {
+			t.Errorf("Expected %s, got %s", ga.HallOfFame[0].ID, ga2.HallOfFame[0].ID)
+		}
+	}
+	// An empty hall of fame is left empty
+	var ga2 = newHallOfFameGA(t, 3)
+	if err := ga2.UnmarshalHallOfFame([]byte("[]")); err != nil || len(ga2.HallOfFame) != 0 {
+		t.Errorf("Expected an empty hall of fame, got %v and %v", ga2.HallOfFame, err)
+	}
+}
+
+func TestHallOfFameErrors(t *testing.T) {
+	var ga = newHallOfFameGA(t, 1)
+	ga.GenomeJSONUnmarshaler = nil
+	if err := ga.UnmarshalHallOfFame([]byte("[]")); err != errNilGenomeJSONUnmarshaler {
+		t.Errorf("Expected errNilGenomeJSONUnmarshaler, got %v", err)
+	}
+	ga.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	for _, data := range []string{"{", `[{"genome": "a", "fitness": 1}]`} {
+		if err := ga.UnmarshalHallOfFame([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+	if err := ga.UnmarshalHallOfFameBinary([]byte("nope")); err == nil {
+		t.Error("Expected an error")
+	}
+	// Unregistered Genome types can't be encoded
+	ga.HallOfFame = Individuals{{Genome: ErrorGenome{}}}
+	if _, err := ga.MarshalHallOfFameBinary(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestHofInjection(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.HofSize = 2
+	conf.NGenerations = 10
+	conf.Model = ModIdentity{}
+	conf.HofInjection = &HofInjection{Frequency: 5, NIndividuals: 2}
+	conf.Callback = func(ga *GA) {
+		if ga.Generations == 0 || ga.Generations%5 != 0 {
+			return
+		}
+		// Every Population should contain the best Individual ever found
+		for _, pop := range ga.Populations {
+			if pop.Individuals[0].Fitness != ga.HallOfFame[0].Fitness {
+				t.Errorf("Expected %f, got %f", ga.HallOfFame[0].Fitness, pop.Individuals[0].Fitness)
+			}
+		}
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	// Only stagnating Populations receive the hall of fame, with ModIdentity
+	// every Population stagnates except the one that contains the best
+	// Individual
+	var injections int
+	conf.HofInjection = &HofInjection{Frequency: 1, NIndividuals: 1, Stagnation: 3}
+	conf.Callback = func(ga *GA) {
+		for _, pop := range ga.Populations {
+			if pop.Individuals[0].Fitness == ga.HallOfFame[0].Fitness {
+				injections++
+			}
+		}
+	}
+	if ga, err = conf.NewGA(); err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	// One Population holds the best Individual from the start, the two others
+	// receive it once they haven't improved for 3 generations, which is at the
+	// 4th generation because progress is recorded from the 1st generation on
+	if injections != 11+2*7 {
+		t.Errorf("Expected %d, got %d", 11+2*7, injections)
+	}
+}
+
+func TestHofInjectionValidate(t *testing.T) {
+	var testCases = []HofInjection{
+		{Frequency: 0, NIndividuals: 1},
+		{Frequency: 1, NIndividuals: 0},
+		{Frequency: 1, NIndividuals: 3},
+		{Frequency: 1, NIndividuals: 1, MutRate: 2},
+	}
+	for i, tc := range testCases {
+		var conf = NewDefaultGAConfig()
+		conf.HofSize = 2
+		conf.HofInjection = &tc
+		if _, err := conf.NewGA(); err == nil {
+			t.Errorf("Expected an error in test case number %d", i)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// HarmonySearch is a single-solution optimizer which keeps a memory of
+// MemorySize harmonies, namely Genomes, and improvises a new harmony at each
+// of the NSteps steps. The improvisation is expressed with the Genome's
+// operators: a random harmony of the memory is cloned and, with probability
+// HMCR, crossed over with a clone of another harmony of the memory, otherwise
+// with a new random Genome, which respectively correspond to memory and
+// random consideration. The result is then mutated, which is the pitch
+// adjustment, with probability PAR. The new harmony replaces the worst one of
+// the memory if it is better. Like OnePlusOneES, HarmonySearch is a cheap
+// baseline and can fine-tune the hall of fame after a GA run by seeding the
+// memory with it.
+// Reference: https://doi.org/10.1177/003754970107600201
+type HarmonySearch struct {
+	MemorySize uint    // Number of harmonies in memory
+	NSteps     uint    // Number of improvisations
+	HMCR       float64 // Harmony memory considering rate
+	PAR        float64 // Pitch adjusting rate
+}
+
+// NewDefaultHarmonySearch returns a HarmonySearch with the usual settings.
+func NewDefaultHarmonySearch() HarmonySearch {
+	return HarmonySearch{
+		MemorySize: 10,
+		NSteps:     1000,
+		HMCR:       0.9,
+		PAR:        0.3,
+	}
+}
+
+// Validate HarmonySearch fields.
+func (hs HarmonySearch) Validate() error {
+	if hs.MemorySize == 0 {
+		return errors.New("MemorySize should be higher than 0")
+	}
+	if hs.NSteps == 0 {
+		return errors.New("NSteps should be higher than 0")
+	}
+	if hs.HMCR < 0 || hs.HMCR > 1 {
+		return errors.New("HMCR should be in [0, 1]")
+	}
+	if hs.PAR < 0 || hs.PAR > 1 {
+		return errors.New("PAR should be in [0, 1]")
+	}
+	return nil
+}
+
+// Minimize runs the HarmonySearch and returns the best Individual found. The
+// memory is filled with clones of the seeds, at most MemorySize of them, and
+// then with Genomes generated by newGenome. The seeds aren't modified. rng may
+// be nil.
+func (hs HarmonySearch) Minimize(newGenome func(rng *rand.Rand) Genome, rng *rand.Rand, seeds ...Genome) (Individual, error) {
+	if err := hs.Validate(); err != nil {
+		return Individual{}, err
+	}
+	if rng == nil {
+		rng = newRand()
+	}
+	var memory = make(Individuals, hs.MemorySize)
+	for i := range memory {
+		if i < len(seeds) {
+			memory[i] = NewIndividual(seeds[i].Clone(), rng)
+		} else {
+			memory[i] = NewIndividual(newGenome(rng), rng)
+		}
+		if err := memory[i].Evaluate(); err != nil {
+			return Individual{}, err
+		}
+	}
+	for step := uint(0); step < hs.NSteps; step++ {
+		var harmony = NewIndividual(memory[rng.Intn(len(memory))].Genome.Clone(), rng)
+		if rng.Float64() < hs.HMCR {
+			harmony.Genome.Crossover(memory[rng.Intn(len(memory))].Genome.Clone(), rng)
+		} else {
+			harmony.Genome.Crossover(newGenome(rng), rng)
+		}
+		if rng.Float64() < hs.PAR {
+			harmony.Genome.Mutate(rng)
+		}
+		if err := harmony.Evaluate(); err != nil {
+			return Individual{}, err
+		}
+		// Harmonies with a NaN fitness are the first to go
+		var worst = 0
+		for i := range memory {
+			if math.IsNaN(memory[i].Fitness) {
+				worst = i
+				break
+			}
+			if memory[worst].Better(memory[i]) {
+				worst = i
+			}
+		}
+		if harmony.Better(memory[worst]) || (math.IsNaN(memory[worst].Fitness) && !math.IsNaN(harmony.Fitness)) {
+			memory[worst] = harmony
+		}
+	}
+	var best = 0
+	for i := range memory {
+		if memory[i].Better(memory[best]) || math.IsNaN(memory[best].Fitness) {
+			best = i
+		}
+	}
+	return memory[best], nil
+}
@@ -0,0 +1,48 @@
+package eaopt
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestHarmonySearch(t *testing.T) {
+	var (
+		p   = newTestFloatProblem()
+		rng = rand.New(rand.NewSource(42))
+		hs  = NewDefaultHarmonySearch()
+	)
+	var best, err = hs.Minimize(p.NewGenome, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !best.Evaluated || best.Fitness > 0.1 {
+		t.Errorf("Expected the sphere to be solved, got %v", best)
+	}
+	// Seeding the memory with a good solution can't make things worse
+	var seed = &FloatVector{Values: []float64{0.01, 0, 0}, Problem: p}
+	hs.NSteps = 10
+	if best, err = hs.Minimize(p.NewGenome, rng, seed); err != nil {
+		t.Fatal(err)
+	}
+	if best.Fitness > 1e-4 {
+		t.Errorf("Expected at most 1e-4, got %f", best.Fitness)
+	}
+	if !reflect.DeepEqual(seed.Values, []float64{0.01, 0, 0}) {
+		t.Errorf("The seeds shouldn't be modified, got %v", seed.Values)
+	}
+	// Errors
+	if _, err = hs.Minimize(NewErrorGenome, rng); err == nil {
+		t.Error("Expected an error")
+	}
+	for _, invalid := range []HarmonySearch{
+		{NSteps: 1, HMCR: 0.9, PAR: 0.3},
+		{MemorySize: 1, HMCR: 0.9, PAR: 0.3},
+		{MemorySize: 1, NSteps: 1, HMCR: 1.1, PAR: 0.3},
+		{MemorySize: 1, NSteps: 1, HMCR: 0.9, PAR: -0.1},
+	} {
+		if _, err = invalid.Minimize(p.NewGenome, nil); err == nil {
+			t.Errorf("Expected %+v to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package eaopt
+
+import (
+	"errors"
+	"sync"
+)
+
+// HistoryOptions configure the recording of the best Individual of each
+// generation, see GA.BestHistory. The best Individual is recorded after the
+// initialization and then every Every generations, 0 being the same as 1.
+//
+// Recording Genomes can take a lot of memory, hence they are only kept if
+// KeepGenomes is true; otherwise only the fitness, the ID and the metadata of
+// the Individuals are kept. Encode, if it isn't nil, stores a compact encoding
+// of each Genome instead, for instance a hash or a few summary values.
+//
+// If MaxEntries is positive then the history holds at most MaxEntries entries.
+// Once it is full, the oldest entry is dropped to make room for a new one
+// unless Downsample is true, in which case every other entry is dropped and
+// the recording frequency is halved, which keeps a coarser view of the whole
+// run.
+type HistoryOptions struct {
+	Every       uint
+	MaxEntries  uint
+	Downsample  bool
+	KeepGenomes bool
+	Encode      func(Genome) ([]byte, error)
+}
+
+// Validate HistoryOptions fields.
+func (ho HistoryOptions) Validate() error {
+	if ho.Downsample && ho.MaxEntries < 2 {
+		return errors.New("Downsample requires MaxEntries to be at least 2")
+	}
+	return nil
+}
+
+// A HistoryEntry records the best Individual of the Populations at the given
+// generation. Encoding is the output of HistoryOptions.Encode.
+type HistoryEntry struct {
+	Generation uint       `json:"generation"`
+	Best       Individual `json:"best"`
+	Encoding   []byte     `json:"encoding,omitempty"`
+}
+
+// bestHistory contains the entries returned by GA.BestHistory. It is guarded
+// by a lock so that BestHistory can be called while the GA is being evolved.
+type bestHistory struct {
+	mutex   sync.Mutex
+	entries []HistoryEntry
+	stride  uint // Number of generations between two entries
+	err     error
+}
+
+// reset empties the history.
+func (bh *bestHistory) reset() {
+	bh.mutex.Lock()
+	defer bh.mutex.Unlock()
+	bh.entries = nil
+	bh.stride = 0
+	bh.err = nil
+}
+
+// recordHistory appends the best Individual of the current generation to the
+// history if the History option is set and the generation is due.
+func (ga *GA) recordHistory() {
+	if ga.History == nil || len(ga.Populations) == 0 {
+		return
+	}
+	ga.history.mutex.Lock()
+	defer ga.history.mutex.Unlock()
+	if ga.history.stride == 0 {
+		ga.history.stride = ga.History.Every
+		if ga.history.stride == 0 {
+			ga.history.stride = 1
+		}
+	}
+	if ga.Generations%ga.history.stride != 0 {
+		return
+	}
+	// The Populations are sorted, hence their first Individual is their best
+	var best = ga.Populations[0].Individuals[0]
+	for _, pop := range ga.Populations[1:] {
+		if pop.Individuals[0].Better(best) {
+			best = pop.Individuals[0]
+		}
+	}
+	var entry = HistoryEntry{Generation: ga.Generations, Best: best}
+	entry.Best.Genome = nil
+	entry.Best.Metadata = copyMetadata(best.Metadata)
+	entry.Best.eval = nil
+	if ga.History.KeepGenomes {
+		entry.Best.Genome = best.Genome.Clone()
+	}
+	if ga.History.Encode != nil {
+		var err error
+		if entry.Encoding, err = ga.History.Encode(best.Genome); err != nil && ga.history.err == nil {
+			ga.history.err = err
+		}
+	}
+	var max = int(ga.History.MaxEntries)
+	if max > 0 && len(ga.history.entries) == max {
+		if ga.History.Downsample {
+			var kept = ga.history.entries[:0]
+			for i, e := range ga.history.entries {
+				if i%2 == 0 {
+					kept = append(kept, e)
+				}
+			}
+			ga.history.entries = kept
+			ga.history.stride *= 2
+			// The new stride may skip the current generation
+			if ga.Generations%ga.history.stride != 0 {
+				return
+			}
+		} else {
+			ga.history.entries = append(ga.history.entries[:0], ga.history.entries[1:]...)
+		}
+	}
+	ga.history.entries = append(ga.history.entries, entry)
+}
+
+// BestHistory returns a copy of the recorded history of the best Individual of
+// each generation, in chronological order, along with the first error
+// returned by HistoryOptions.Encode. It is empty unless the History field of
+// the GAConfig is set. It is safe to call concurrently with Minimize. Like the
+// trajectory returned by Anytime, the history isn't persisted when the GA is
+// marshaled to JSON; it restarts when the GA is initialized.
+func (ga *GA) BestHistory() ([]HistoryEntry, error) {
+	if ga.history == nil {
+		return nil, nil
+	}
+	ga.history.mutex.Lock()
+	defer ga.history.mutex.Unlock()
+	var entries = make([]HistoryEntry, len(ga.history.entries))
+	for i, e := range ga.history.entries {
+		entries[i] = e
+		if e.Best.Genome != nil {
+			entries[i].Best.Genome = e.Best.Genome.Clone()
+		}
+		entries[i].Best.Metadata = copyMetadata(e.Best.Metadata)
+		entries[i].Encoding = append([]byte(nil), e.Encoding...)
+	}
+	return entries, ga.history.err
+}
@@ -0,0 +1,113 @@
+package eaopt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBestHistory(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 10
+	conf.History = &HistoryOptions{Every: 2}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var bests []float64
+	ga.Callback = func(ga *GA) {
+		var best = ga.Populations[0].Individuals[0].Fitness
+		if f := ga.Populations[1].Individuals[0].Fitness; f < best {
+			best = f
+		}
+		bests = append(bests, best)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	history, err := ga.BestHistory()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(history) != 6 {
+		t.Fatalf("Expected 6 entries, got %d", len(history))
+	}
+	for i, entry := range history {
+		if entry.Generation != uint(2*i) {
+			t.Errorf("Expected generation %d, got %d", 2*i, entry.Generation)
+		}
+		if entry.Best.Fitness != bests[2*i] {
+			t.Errorf("Expected fitness %f, got %f", bests[2*i], entry.Best.Fitness)
+		}
+		if entry.Best.Genome != nil {
+			t.Error("Genomes shouldn't be kept")
+		}
+	}
+}
+
+func TestBestHistoryBounded(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 20
+	conf.History = &HistoryOptions{MaxEntries: 4, KeepGenomes: true}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var history, _ = ga.BestHistory()
+	if len(history) != 4 || history[0].Generation != 17 || history[3].Generation != 20 {
+		t.Errorf("Expected the last 4 generations, got %v", history)
+	}
+	for _, entry := range history {
+		if fit, _ := entry.Best.Genome.Evaluate(); fit != entry.Best.Fitness {
+			t.Errorf("The genome doesn't match the fitness")
+		}
+	}
+	// Downsampling keeps entries spanning the whole run
+	conf.History = &HistoryOptions{MaxEntries: 4, Downsample: true}
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	history, _ = ga.BestHistory()
+	var generations []uint
+	for _, entry := range history {
+		generations = append(generations, entry.Generation)
+	}
+	if len(generations) != 3 || generations[0] != 0 || generations[1] != 8 || generations[2] != 16 {
+		t.Errorf("Expected generations 0, 8 and 16, got %v", generations)
+	}
+	conf.History = &HistoryOptions{MaxEntries: 1, Downsample: true}
+	if _, err = conf.NewGA(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestBestHistoryEncode(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 3
+	var calls int
+	conf.History = &HistoryOptions{Encode: func(g Genome) ([]byte, error) {
+		calls++
+		if calls == 3 {
+			return nil, errors.New("oops")
+		}
+		return []byte{byte(len(g.(Vector)))}, nil
+	}}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	history, err := ga.BestHistory()
+	if err == nil {
+		t.Error("Expected an error")
+	}
+	if len(history) != 4 || len(history[0].Encoding) != 1 || history[0].Encoding[0] != 4 {
+		t.Errorf("Wrong encodings: %v", history)
+	}
+}
@@ -0,0 +1,196 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// evaluateAt evaluates each Individual with the given budget, their Genomes
+// have to implement MultiFidelity.
+func (indis Individuals) evaluateAt(budget float64, parallel bool) error {
+	var eval = func(i int) error {
+		var mf, ok = indis[i].Genome.(MultiFidelity)
+		if !ok {
+			return fmt.Errorf("%T doesn't implement MultiFidelity", indis[i].Genome)
+		}
+		var fitness, err = mf.EvaluateAt(budget)
+		if err != nil {
+			return err
+		}
+		indis[i].Fitness = fitness
+		indis[i].Evaluated = true
+		return nil
+	}
+	if !parallel {
+		for i := range indis {
+			if err := eval(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var g errgroup.Group
+	for i := range indis {
+		i := i // https://golang.org/doc/faq#closures_and_goroutines
+		g.Go(func() error { return eval(i) })
+	}
+	return g.Wait()
+}
+
+// SuccessiveHalving evaluates indis with a budget of minBudget, keeps the best
+// 1/eta of them, multiplies the budget by eta and repeats until the budget
+// reaches maxBudget. The survivors of the last round, which were evaluated
+// with maxBudget, are returned sorted by increasing fitness along with the
+// total budget spent. The Genomes of indis have to implement MultiFidelity.
+func SuccessiveHalving(indis Individuals, minBudget, maxBudget, eta float64, parallel bool) (Individuals, float64, error) {
+	if len(indis) == 0 {
+		return nil, 0, errors.New("at least one Individual is needed")
+	}
+	if minBudget <= 0 || minBudget > maxBudget {
+		return nil, 0, errors.New("minBudget should be positive and lower than maxBudget")
+	}
+	if eta <= 1 {
+		return nil, 0, errors.New("eta should be higher than 1")
+	}
+	var (
+		budget = minBudget
+		spent  float64
+	)
+	// Work on a copy so that the caller's slice isn't reordered
+	indis = append(Individuals{}, indis...)
+	for {
+		if err := indis.evaluateAt(budget, parallel); err != nil {
+			return nil, spent, err
+		}
+		spent += budget * float64(len(indis))
+		sort.SliceStable(indis, func(i, j int) bool { return indis[i].Fitness < indis[j].Fitness })
+		if budget >= maxBudget {
+			return indis, spent, nil
+		}
+		var keep = int(float64(len(indis)) / eta)
+		if keep < 1 {
+			keep = 1
+		}
+		indis = indis[:keep]
+		budget = math.Min(budget*eta, maxBudget)
+	}
+}
+
+// Hyperband allocates evaluation budgets to randomly sampled candidates by
+// running SuccessiveHalving several times, from brackets that sample many
+// candidates and start with MinBudget to brackets that sample few of them and
+// evaluate them with MaxBudget right away. This hedges against low budget
+// evaluations being misleading. Eta is the factor by which the number of
+// candidates is divided at each round of SuccessiveHalving, 3 is a common
+// choice.
+// Reference: https://arxiv.org/abs/1603.06560
+type Hyperband struct {
+	MinBudget, MaxBudget float64
+	Eta                  float64
+	Parallel             bool
+}
+
+// brackets returns the number of candidates and the initial budget of each
+// bracket.
+func (hb Hyperband) brackets() ([]int, []float64) {
+	var (
+		sMax     = int(math.Floor(math.Log(hb.MaxBudget/hb.MinBudget)/math.Log(hb.Eta) + 1e-9))
+		sizes    = make([]int, sMax+1)
+		budgets  = make([]float64, sMax+1)
+		nBracket = float64(sMax + 1)
+	)
+	for i := range sizes {
+		var s = float64(sMax - i)
+		sizes[i] = int(math.Ceil(nBracket / (s + 1) * math.Pow(hb.Eta, s)))
+		budgets[i] = hb.MaxBudget * math.Pow(hb.Eta, -s)
+	}
+	return sizes, budgets
+}
+
+// Run samples candidates with newGenome, whose Genomes have to implement
+// MultiFidelity, and runs each bracket. The best candidate evaluated with
+// MaxBudget is returned along with the total budget spent.
+func (hb Hyperband) Run(newGenome func(rng *rand.Rand) Genome, rng *rand.Rand) (Individual, float64, error) {
+	if err := hb.Validate(); err != nil {
+		return Individual{}, 0, err
+	}
+	var (
+		sizes, budgets = hb.brackets()
+		best           = Individual{Fitness: math.Inf(1)}
+		spent          float64
+	)
+	for i, size := range sizes {
+		var survivors, s, err = SuccessiveHalving(newIndividuals(uint(size), false, newGenome, rng),
+			budgets[i], hb.MaxBudget, hb.Eta, hb.Parallel)
+		spent += s
+		if err != nil {
+			return best, spent, err
+		}
+		if survivors[0].Fitness < best.Fitness || best.Genome == nil {
+			best = survivors[0]
+		}
+	}
+	return best, spent, nil
+}
+
+// Validate Hyperband fields.
+func (hb Hyperband) Validate() error {
+	if hb.MinBudget <= 0 {
+		return errors.New("MinBudget should be positive")
+	}
+	if hb.MaxBudget < hb.MinBudget {
+		return errors.New("MaxBudget should be higher than MinBudget")
+	}
+	if hb.Eta <= 1 {
+		return errors.New("Eta should be higher than 1")
+	}
+	return nil
+}
+
+// GACandidate is a MultiFidelity Genome which evaluates a GAConfig, which
+// makes it possible to tune the hyperparameters of a GA with Hyperband.
+// EvaluateAt runs a GA for budget generations and returns the best fitness it
+// finds. Seed makes the evaluations reproducible, so that the runs with
+// different budgets are comparable.
+type GACandidate struct {
+	Conf      GAConfig
+	NewGenome func(rng *rand.Rand) Genome
+	Seed      int64
+}
+
+// EvaluateAt runs the GA for budget generations, rounded to the nearest
+// integer.
+func (c *GACandidate) EvaluateAt(budget float64) (float64, error) {
+	var conf = c.Conf
+	conf.NGenerations = uint(math.Round(budget))
+	conf.RNG = rand.New(rand.NewSource(c.Seed))
+	var ga, err = conf.NewGA()
+	if err != nil {
+		return 0, err
+	}
+	if err = ga.Minimize(c.NewGenome); err != nil {
+		return 0, err
+	}
+	return ga.HallOfFame[0].Fitness, nil
+}
+
+// Evaluate runs the GA for Conf.NGenerations generations.
+func (c *GACandidate) Evaluate() (float64, error) {
+	return c.EvaluateAt(float64(c.Conf.NGenerations))
+}
+
+// Mutate doesn't do anything.
+func (c *GACandidate) Mutate(rng *rand.Rand) {}
+
+// Crossover doesn't do anything.
+func (c *GACandidate) Crossover(q Genome, rng *rand.Rand) {}
+
+// Clone returns a copy of the GACandidate.
+func (c GACandidate) Clone() Genome {
+	return &c
+}
@@ -0,0 +1,138 @@
+package eaopt
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+// A fidelityVector is a Vector whose evaluations with a low budget are noisy.
+type fidelityVector struct {
+	Vector
+	noise float64
+	spent *atomic.Int64
+}
+
+func (v *fidelityVector) EvaluateAt(budget float64) (float64, error) {
+	v.spent.Add(int64(budget))
+	var f, _ = v.Vector.Evaluate()
+	return f + v.noise/budget, nil
+}
+
+func (v fidelityVector) Clone() Genome {
+	return &fidelityVector{Vector: v.Vector.Clone().(Vector), noise: v.noise, spent: v.spent}
+}
+
+func newFidelityVector(spent *atomic.Int64) func(rng *rand.Rand) Genome {
+	return func(rng *rand.Rand) Genome {
+		return &fidelityVector{
+			Vector: InitUnifFloat64(2, -1, 1, rng),
+			noise:  rng.Float64(),
+			spent:  spent,
+		}
+	}
+}
+
+func TestSuccessiveHalving(t *testing.T) {
+	var (
+		rng   = newRand()
+		spent atomic.Int64
+		indis = newIndividuals(9, false, newFidelityVector(&spent), rng)
+	)
+	var survivors, s, err = SuccessiveHalving(indis, 1, 9, 3, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 9 candidates with a budget of 1, 3 with 3 and 1 with 9
+	if len(survivors) != 1 || s != 27 || spent.Load() != 27 {
+		t.Errorf("Expected 1 survivor and a budget of 27, got %d and %f (%d)", len(survivors), s, spent.Load())
+	}
+	var f, _ = survivors[0].Genome.(*fidelityVector).EvaluateAt(9)
+	if survivors[0].Fitness != f {
+		t.Errorf("Expected the survivor to be evaluated with the maximum budget")
+	}
+	// The Individuals are copied before being evaluated
+	for _, indi := range indis {
+		if indi.Evaluated {
+			t.Error("The Individuals passed to SuccessiveHalving shouldn't be modified")
+		}
+	}
+	if _, _, err := SuccessiveHalving(Individuals{NewIndividual(NewVector(rng), rng)}, 1, 2, 2, false); err == nil {
+		t.Error("Expected an error because Vector doesn't implement MultiFidelity")
+	}
+	for _, tc := range []struct{ min, max, eta float64 }{{0, 1, 2}, {2, 1, 2}, {1, 2, 1}} {
+		if _, _, err := SuccessiveHalving(indis, tc.min, tc.max, tc.eta, false); err == nil {
+			t.Errorf("Expected an error with %v", tc)
+		}
+	}
+}
+
+func TestHyperbandBrackets(t *testing.T) {
+	var sizes, budgets = Hyperband{MinBudget: 1, MaxBudget: 81, Eta: 3}.brackets()
+	var (
+		expSizes   = []int{81, 34, 15, 8, 5}
+		expBudgets = []float64{1, 3, 9, 27, 81}
+	)
+	for i := range expSizes {
+		if sizes[i] != expSizes[i] || math.Abs(budgets[i]-expBudgets[i]) > 1e-9 {
+			t.Errorf("Expected bracket %d to have %d candidates and a budget of %f, got %d and %f",
+				i, expSizes[i], expBudgets[i], sizes[i], budgets[i])
+		}
+	}
+}
+
+func TestHyperbandRun(t *testing.T) {
+	var (
+		spent atomic.Int64
+		hb    = Hyperband{MinBudget: 1, MaxBudget: 9, Eta: 3}
+	)
+	var best, s, err = hb.Run(newFidelityVector(&spent), newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 brackets of 27 each: 9x1 + 3x3 + 1x9, 5x3 + 1x9 and 3x9
+	if s != 27+24+27 || spent.Load() != 27+24+27 {
+		t.Errorf("Expected a budget of 78, got %f", s)
+	}
+	var f, _ = best.Genome.(*fidelityVector).EvaluateAt(9)
+	if best.Fitness != f {
+		t.Errorf("Expected the best candidate to be evaluated with the maximum budget")
+	}
+	if _, _, err := (Hyperband{MinBudget: 1, MaxBudget: 9, Eta: 1}).Run(newFidelityVector(&spent), newRand()); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestGACandidate(t *testing.T) {
+	var (
+		conf = NewDefaultGAConfig()
+		c    = &GACandidate{Conf: conf, NewGenome: NewVector, Seed: 42}
+	)
+	conf.NGenerations = 5
+	c.Conf = conf
+	var (
+		f1, _ = c.EvaluateAt(1)
+		f5, _ = c.Evaluate()
+		f5b   float64
+		err   error
+	)
+	if f5b, err = c.Clone().(MultiFidelity).EvaluateAt(5); err != nil {
+		t.Fatal(err)
+	}
+	if f5 > f1 || f5 != f5b {
+		t.Errorf("Expected reproducible and improving evaluations, got %f, %f and %f", f1, f5, f5b)
+	}
+}
+
+func TestHyperbandValidate(t *testing.T) {
+	for _, hb := range []Hyperband{
+		{MinBudget: 0, MaxBudget: 9, Eta: 3},
+		{MinBudget: 10, MaxBudget: 9, Eta: 3},
+		{MinBudget: 1, MaxBudget: 9, Eta: 0.5},
+	} {
+		if err := hb.Validate(); err == nil {
+			t.Errorf("Expected an error with %v", hb)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package eaopt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// An IDGenerator generates the IDs of the Individuals of a GA, see
+// GAConfig.IDGenerator. NewID is called concurrently when the Populations
+// are initialized or evolved in parallel.
+type IDGenerator interface {
+	NewID(genome Genome, rng *rand.Rand) string
+}
+
+// RandomIDs generates random IDs of Length letters, 6 if it is 0, which is
+// what the GA does if it doesn't have an IDGenerator.
+type RandomIDs struct {
+	Length uint
+}
+
+// NewID returns a random ID.
+func (ids RandomIDs) NewID(genome Genome, rng *rand.Rand) string {
+	if ids.Length == 0 {
+		return randString(6, rng)
+	}
+	return randString(int(ids.Length), rng)
+}
+
+// SequentialIDs generates the IDs Prefix + "0", Prefix + "1", etc. When a GA
+// is resumed, for instance from a checkpoint, the generator skips the
+// numbers used by the restored Individuals, hence IDs aren't reused across
+// checkpoints. SequentialIDs are created with NewSequentialIDs.
+type SequentialIDs struct {
+	Prefix string
+	next   atomic.Uint64
+}
+
+// NewSequentialIDs returns SequentialIDs whose IDs start with prefix.
+func NewSequentialIDs(prefix string) *SequentialIDs {
+	return &SequentialIDs{Prefix: prefix}
+}
+
+// NewID returns the next ID.
+func (ids *SequentialIDs) NewID(genome Genome, rng *rand.Rand) string {
+	return ids.Prefix + strconv.FormatUint(ids.next.Add(1)-1, 10)
+}
+
+// Resume makes sure the IDs generated from now on differ from the given
+// ones.
+func (ids *SequentialIDs) Resume(used ...string) {
+	for _, id := range used {
+		if !strings.HasPrefix(id, ids.Prefix) {
+			continue
+		}
+		var n, err = strconv.ParseUint(strings.TrimPrefix(id, ids.Prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		for {
+			var next = ids.next.Load()
+			if n < next || ids.next.CompareAndSwap(next, n+1) {
+				break
+			}
+		}
+	}
+}
+
+// UUIDs generates random version 4 UUIDs, which are unique across GAs and
+// processes for all practical purposes. The bits are drawn from the given
+// random number generator, hence seeded GAs generate the same UUIDs.
+type UUIDs struct{}
+
+// NewID returns a random UUID.
+func (ids UUIDs) NewID(genome Genome, rng *rand.Rand) string {
+	var b [16]byte
+	rng.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ContentHashIDs derives the ID of an Individual from its Genome, namely the
+// first Length hexadecimal digits, 16 if it is 0, of the SHA-256 hash of the
+// Genome's JSON encoding, or of its %v representation if it can't be encoded.
+// Individuals with the same Genome thus share the same ID, whichever way they
+// were obtained. Because mutation and crossover modify Genomes in place, the
+// GA assigns the ID again each time an Individual is evaluated.
+type ContentHashIDs struct {
+	Length uint
+}
+
+// NewID returns the hash of genome.
+func (ids ContentHashIDs) NewID(genome Genome, rng *rand.Rand) string {
+	var b, err = json.Marshal(genome)
+	if err != nil {
+		b = []byte(fmt.Sprintf("%v", genome))
+	}
+	var (
+		sum = sha256.Sum256(b)
+		id  = hex.EncodeToString(sum[:])
+		n   = 16
+	)
+	if ids.Length > 0 {
+		n = minInt(int(ids.Length), len(id))
+	}
+	return id[:n]
+}
+
+// newID returns a new ID for an Individual whose Genome is genome, using the
+// GA's IDGenerator if there is one. It doesn't need ctx to be non-nil.
+func (ctx *evalContext) newID(genome Genome, rng *rand.Rand) string {
+	if ctx == nil || ctx.ids == nil {
+		return randString(6, rng)
+	}
+	return ctx.ids.NewID(genome, rng)
+}
+
+// hashesContent returns true if the IDs depend on the content of the Genomes,
+// in which case they are assigned again when the Individuals are evaluated.
+func (ctx *evalContext) hashesContent() bool {
+	if ctx == nil {
+		return false
+	}
+	_, ok := ctx.ids.(ContentHashIDs)
+	return ok
+}
+
+// assignIDs gives the GA's Individuals IDs generated by its IDGenerator, if
+// it has one, once the Populations have been generated. If the Populations
+// were resumed, the IDs are kept and the IDGenerator is told about them
+// instead, if it is a SequentialIDs.
+func (ga *GA) assignIDs(resumed bool) {
+	if ga.IDGenerator == nil {
+		return
+	}
+	if resumed {
+		if seq, ok := ga.IDGenerator.(*SequentialIDs); ok {
+			for _, pop := range ga.Populations {
+				for _, indi := range pop.Individuals {
+					seq.Resume(indi.ID)
+				}
+			}
+			for _, indi := range ga.HallOfFame {
+				seq.Resume(indi.ID)
+			}
+		}
+		return
+	}
+	for i := range ga.Populations {
+		var pop = &ga.Populations[i]
+		for j := range pop.Individuals {
+			pop.Individuals[j].ID = ga.IDGenerator.NewID(pop.Individuals[j].Genome, pop.RNG)
+		}
+	}
+}
+
+// countIDs returns the number of Individuals of indis with each ID.
+func countIDs(indis Individuals) map[string]int {
+	var counts = make(map[string]int, len(indis))
+	for _, indi := range indis {
+		counts[indi.ID]++
+	}
+	return counts
+}
+
+// dedupMigrated looks for the Individuals the Migrator copied into a
+// Population which already held an Individual with the same ID, before is
+// the state of the Populations before the migration. The number of such
+// duplicates is returned. If replace is true each duplicate is replaced by
+// one of the Individuals the migration overwrote in its Population and which
+// no longer exists anywhere, as long as there are some.
+func dedupMigrated(pops Populations, before []Individuals, replace bool) int {
+	var present = make(map[string]bool)
+	for _, pop := range pops {
+		for _, indi := range pop.Individuals {
+			present[indi.ID] = true
+		}
+	}
+	var duplicates int
+	for i, pop := range pops {
+		var (
+			was    = countIDs(before[i])
+			is     = countIDs(pop.Individuals)
+			extra  = make(map[string]int)
+			lost   Individuals
+			nExtra int
+		)
+		for id, n := range is {
+			var allowed = was[id]
+			if allowed == 0 {
+				allowed = 1
+			}
+			if n > allowed {
+				extra[id] = n - allowed
+				nExtra += n - allowed
+			}
+		}
+		if nExtra == 0 {
+			continue
+		}
+		duplicates += nExtra
+		if !replace {
+			continue
+		}
+		for _, indi := range before[i] {
+			if !present[indi.ID] {
+				lost = append(lost, indi)
+				present[indi.ID] = true
+			}
+		}
+		// The last copies are the duplicates
+		for j := len(pop.Individuals) - 1; j >= 0 && len(lost) > 0; j-- {
+			var id = pop.Individuals[j].ID
+			if extra[id] > 0 {
+				extra[id]--
+				pop.Individuals[j] = lost[0]
+				lost = lost[1:]
+			}
+		}
+	}
+	return duplicates
+}
+
+// dedupMigrants removes the migrants whose ID is already present in pop or
+// among the previous migrants, and returns the remaining migrants along with
+// the number of migrants removed.
+func dedupMigrants(pop Population, migrants Individuals) (Individuals, int) {
+	var (
+		seen = make(map[string]bool, len(pop.Individuals)+len(migrants))
+		kept = migrants[:0:0]
+	)
+	for _, indi := range pop.Individuals {
+		seen[indi.ID] = true
+	}
+	for _, indi := range migrants {
+		if !seen[indi.ID] {
+			seen[indi.ID] = true
+			kept = append(kept, indi)
+		}
+	}
+	return kept, len(migrants) - len(kept)
+}
+
+// individuals returns a shallow copy of the Individuals of each Population.
+func (pops Populations) individuals() []Individuals {
+	var indis = make([]Individuals, len(pops))
+	for i, pop := range pops {
+		indis[i] = append(Individuals(nil), pop.Individuals...)
+	}
+	return indis
+}
+
+// dedupMigrated counts the duplicates a lockstep migration introduced, and
+// removes them if DedupMigrants is true. before holds the Individuals each
+// Population had before the migration.
+func (ga *GA) dedupMigrated(before []Individuals) int {
+	var n = dedupMigrated(ga.Populations, before, ga.DedupMigrants)
+	ga.duplicates.Add(uint64(n))
+	return n
+}
+
+// dedupMigrants counts the asynchronous migrants which are already present in
+// pop, and removes them if DedupMigrants is true.
+func (ga *GA) dedupMigrants(pop Population, migrants Individuals) Individuals {
+	if len(migrants) == 0 {
+		return migrants
+	}
+	var kept, n = dedupMigrants(pop, migrants)
+	ga.duplicates.Add(uint64(n))
+	if ga.DedupMigrants {
+		return kept
+	}
+	return migrants
+}
+
+// DuplicateMigrants returns the number of migrants that arrived in a
+// Population which already held an Individual with the same ID since the
+// Populations were initialized. Whether they were kept depends on
+// GAConfig.DedupMigrants. It is safe to call concurrently.
+func (ga *GA) DuplicateMigrants() uint64 {
+	if ga.duplicates == nil {
+		return 0
+	}
+	return ga.duplicates.Load()
+}
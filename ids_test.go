@@ -0,0 +1,248 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSequentialIDs(t *testing.T) {
+	var ids = NewSequentialIDs("x")
+	for i := 0; i < 3; i++ {
+		if id := ids.NewID(nil, nil); id != "x"+strconv.Itoa(i) {
+			t.Errorf("Expected x%d, got %s", i, id)
+		}
+	}
+	ids.Resume("x1", "x41", "y99", "xyz", "x7")
+	if id := ids.NewID(nil, nil); id != "x42" {
+		t.Errorf("Expected x42, got %s", id)
+	}
+}
+
+func TestUUIDs(t *testing.T) {
+	var (
+		re = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+		a  = UUIDs{}.NewID(nil, rand.New(rand.NewSource(1)))
+		b  = UUIDs{}.NewID(nil, rand.New(rand.NewSource(1)))
+	)
+	if !re.MatchString(a) {
+		t.Errorf("Expected a version 4 UUID, got %s", a)
+	}
+	if a != b {
+		t.Errorf("Expected seeded UUIDs to be equal, got %s and %s", a, b)
+	}
+}
+
+func TestContentHashIDs(t *testing.T) {
+	var (
+		ids = ContentHashIDs{}
+		v   = Vector{1, 2, 3}
+		id  = ids.NewID(v, nil)
+	)
+	if len(id) != 16 {
+		t.Errorf("Expected 16 digits, got %s", id)
+	}
+	if other := ids.NewID(v.Clone(), nil); other != id {
+		t.Errorf("Expected %s, got %s", id, other)
+	}
+	if other := ids.NewID(Vector{1, 2, 4}, nil); other == id {
+		t.Errorf("Expected different Genomes to have different IDs, got %s", id)
+	}
+	if id = (ContentHashIDs{Length: 100}).NewID(v, nil); len(id) != 64 {
+		t.Errorf("Expected 64 digits, got %s", id)
+	}
+}
+
+func TestGAIDGenerator(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 5
+	conf.IDGenerator = NewSequentialIDs("indi-")
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var max = -1
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			if !strings.HasPrefix(indi.ID, "indi-") {
+				t.Fatalf("Expected a sequential ID, got %s", indi.ID)
+			}
+			var n, _ = strconv.Atoi(strings.TrimPrefix(indi.ID, "indi-"))
+			if n > max {
+				max = n
+			}
+		}
+	}
+	// The IDs survive a checkpoint and aren't reused after it
+	var b []byte
+	if b, err = json.Marshal(ga); err != nil {
+		t.Fatal(err)
+	}
+	conf.IDGenerator = NewSequentialIDs("indi-")
+	resumed, err := conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = resumed.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Populations[0].Individuals[0].ID != ga.Populations[0].Individuals[0].ID {
+		t.Errorf("Expected the IDs to be restored")
+	}
+	if err = resumed.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var id = conf.IDGenerator.NewID(nil, nil)
+	if n, _ := strconv.Atoi(strings.TrimPrefix(id, "indi-")); n <= max {
+		t.Errorf("Expected the IDs to go beyond %d, got %s", max, id)
+	}
+	// Content based IDs match the final Genomes
+	conf.IDGenerator = ContentHashIDs{}
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			if id := (ContentHashIDs{}).NewID(indi.Genome, nil); indi.ID != id {
+				t.Fatalf("Expected %s, got %s", id, indi.ID)
+			}
+		}
+	}
+}
+
+// migDuplicate sends two copies of a random Individual to the next
+// Population.
+type migDuplicate struct{ MigRing }
+
+func (mig migDuplicate) Apply(pops Populations, rng *rand.Rand) {
+	for i := range pops[:len(pops)-1] {
+		var indi = pops[i].Individuals[rng.Intn(len(pops[i].Individuals))]
+		pops[i+1].Individuals[0] = indi.Copy()
+		pops[i+1].Individuals[1] = indi.Copy()
+	}
+}
+
+func (mig migDuplicate) Emigrate(pop Population, i, n int, rng *rand.Rand) (Individuals, int) {
+	var indi = pop.Individuals[rng.Intn(len(pop.Individuals))]
+	return Individuals{indi.Copy(), indi.Copy()}, (i + 1) % n
+}
+
+func uniqueIDs(indis Individuals) bool {
+	var seen = make(map[string]bool)
+	for _, indi := range indis {
+		if seen[indi.ID] {
+			return false
+		}
+		seen[indi.ID] = true
+	}
+	return true
+}
+
+func TestGADedupMigrants(t *testing.T) {
+	for _, dedup := range []bool{false, true} {
+		var conf = NewDefaultGAConfig()
+		conf.NPops = 2
+		conf.NGenerations = 10
+		conf.Migrator = migDuplicate{MigRing{NMigrants: 1}}
+		conf.DedupMigrants = dedup
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		var duplicates int
+		ga.Events.OnMigration(func(e MigrationEvent) {
+			duplicates += e.Duplicates
+			if unique := uniqueIDs(e.GA.Populations[1].Individuals); unique != dedup {
+				t.Errorf("DedupMigrants %t: expected unique IDs to be %t", dedup, dedup)
+			}
+		})
+		if err = ga.Minimize(NewVector); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if duplicates != 10 || ga.DuplicateMigrants() != 10 {
+			t.Errorf("Expected 10 duplicates, got %d and %d", duplicates, ga.DuplicateMigrants())
+		}
+	}
+}
+
+// migDuplicateAsync records the migrants it receives.
+type migDuplicateAsync struct {
+	migDuplicate
+	mutex    *sync.Mutex
+	received *[]Individuals
+}
+
+func (mig migDuplicateAsync) Immigrate(pop *Population, migrants Individuals, rng *rand.Rand) {
+	mig.mutex.Lock()
+	*mig.received = append(*mig.received, migrants)
+	mig.mutex.Unlock()
+	mig.MigRing.Immigrate(pop, migrants, rng)
+}
+
+func TestGADedupMigrantsAsync(t *testing.T) {
+	var (
+		conf     = NewDefaultGAConfig()
+		received []Individuals
+	)
+	conf.NPops = 2
+	conf.NGenerations = 10
+	conf.AsyncMigration = true
+	conf.DedupMigrants = true
+	conf.Migrator = migDuplicateAsync{migDuplicate{MigRing{NMigrants: 1}}, new(sync.Mutex), &received}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(received) == 0 {
+		t.Fatal("Expected migrants")
+	}
+	// Each Population posts two copies of a migrant per generation
+	var n int
+	for _, migrants := range received {
+		if !uniqueIDs(migrants) {
+			t.Errorf("Expected the duplicate migrants to be dropped, got %v", migrants)
+		}
+		n += len(migrants)
+	}
+	if ga.DuplicateMigrants() < uint64(n) {
+		t.Errorf("Expected at least %d duplicates, got %d", n, ga.DuplicateMigrants())
+	}
+}
+
+func TestDedupMigrated(t *testing.T) {
+	var (
+		a, b, c, d = Individual{ID: "a"}, Individual{ID: "b"}, Individual{ID: "c"}, Individual{ID: "d"}
+		pops       = Populations{
+			{Individuals: Individuals{a, b}},
+			{Individuals: Individuals{c, d}},
+		}
+		before = pops.individuals()
+	)
+	// a is copied over c and d
+	pops[1].Individuals[0], pops[1].Individuals[1] = a, a
+	if n := dedupMigrated(pops, before, true); n != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", n)
+	}
+	if pops[1].Individuals[0].ID != "a" || pops[1].Individuals[1].ID != "c" {
+		t.Errorf("Expected [a c], got %v", pops[1].Individuals)
+	}
+	// Duplicates which were already there don't count
+	pops = Populations{{Individuals: Individuals{a, a, b}}}
+	before = pops.individuals()
+	pops[0].Individuals[2] = a
+	if n := dedupMigrated(pops, before, false); n != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", n)
+	}
+}
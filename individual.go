@@ -1,17 +1,102 @@
 package eaopt
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // An Individual wraps a Genome and contains the fitness assigned to the Genome.
+// Metadata can be used to attach arbitrary information to an Individual, such
+// as its provenance or the amount by which it violates constraints. It is
+// copied when the Individual is cloned, hence offsprings inherit the Metadata
+// of their parents, and it is included in the JSON representation of the
+// Individual. EvalDuration is the time the evaluation of the Genome took.
+// Violation is computed along with the fitness if the GA has a
+// ConstraintHandler, it is 0 for feasible Individuals. With novelty search,
+// Objective is the value returned by the Genome's Evaluate method and Novelty
+// the novelty of the Individual, see GAConfig.Novelty. Objectives holds the
+// objective values of MultiObjective Genomes, see GAConfig.ParetoHallOfFame.
+// Skipped is true if the evaluation was skipped because the evaluation budget
+// was spent, see GAConfig.MaxEvaluations; the Fitness of such an Individual is
+// +Inf so that it ranks last.
 type Individual struct {
-	Genome    Genome  `json:"genome"`
-	Fitness   float64 `json:"fitness"`
-	Evaluated bool    `json:"-"`
-	ID        string  `json:"id"`
+	Genome       Genome                 `json:"genome"`
+	Fitness      float64                `json:"fitness"`
+	Violation    float64                `json:"violation,omitempty"`
+	Objective    float64                `json:"objective,omitempty"`
+	Novelty      float64                `json:"novelty,omitempty"`
+	Objectives   []float64              `json:"objectives,omitempty"`
+	Evaluated    bool                   `json:"-"`
+	ID           string                 `json:"id"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	EvalDuration time.Duration          `json:"eval_duration,omitempty"`
+	Skipped      bool                   `json:"skipped,omitempty"`
+
+	eval *evalContext // Shared by the Individuals of a GA
+
+	pending bool // Fitness is an estimate until it is evaluated, see GAConfig.RealTime
+}
+
+// An evalContext is shared by the Individuals of a GA. It accounts for the
+// evaluations and holds the evaluation settings.
+type evalContext struct {
+	count   atomic.Uint64 // Number of calls to Evaluate
+	elapsed atomic.Int64  // Total duration of the evaluations
+	timeout time.Duration // See GAConfig.EvalTimeout
+
+	maxEvaluations uint64        // See GAConfig.MaxEvaluations
+	started        atomic.Uint64 // Number of evaluations started within the budget
+
+	constraints ConstraintHandler // See GAConfig.ConstraintHandler
+	ids         IDGenerator       // See GAConfig.IDGenerator
+
+	// Genome validation, see GAConfig.ValidateGenomes
+	validate  bool
+	validator func(Genome) error
+	mutex     sync.Mutex
+	invalid   error // First invalid Genome encountered
+}
+
+// checkGenome validates genome, which was produced by op, if genome
+// validation is enabled. The first failure is recorded so that the GA can
+// abort, it doesn't do anything if ctx is nil.
+func (ctx *evalContext) checkGenome(op string, genome Genome) {
+	if ctx == nil || !ctx.validate {
+		return
+	}
+	var err error
+	if v, ok := genome.(Validatable); ok {
+		err = v.Validate()
+	}
+	if err == nil && ctx.validator != nil {
+		err = ctx.validator(genome)
+	}
+	if err == nil {
+		return
+	}
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+	if ctx.invalid == nil {
+		ctx.invalid = fmt.Errorf("%s produced an invalid %T: %w", op, genome, err)
+	}
+}
+
+// takeInvalid returns and forgets the first invalid Genome encountered.
+func (ctx *evalContext) takeInvalid() error {
+	if ctx == nil {
+		return nil
+	}
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+	var err = ctx.invalid
+	ctx.invalid = nil
+	return err
 }
 
 // NewIndividual returns a fresh individual.
@@ -34,36 +119,185 @@ func (indi Individual) String() string {
 }
 
 // Clone an individual to produce a new individual with a different pointer and
-// a different ID.
+// a different ID. The Metadata map is copied but its values are shared.
 func (indi Individual) Clone(rng *rand.Rand) Individual {
 	var clone = Individual{
 		Fitness:   indi.Fitness,
+		Violation: indi.Violation,
+		Objective: indi.Objective,
+		Novelty:   indi.Novelty,
 		Evaluated: indi.Evaluated,
-		ID:        randString(6, rng),
+		Skipped:   indi.Skipped,
+		pending:   indi.pending,
+
+		Metadata:     copyMetadata(indi.Metadata),
+		EvalDuration: indi.EvalDuration,
+
+		eval: indi.eval,
 	}
 	if indi.Genome == nil {
 		clone.Genome = nil
 	} else {
 		clone.Genome = indi.Genome.Clone()
 	}
+	if indi.Objectives != nil {
+		clone.Objectives = copyFloat64s(indi.Objectives)
+	}
+	clone.ID = indi.eval.newID(clone.Genome, rng)
 	return clone
 }
 
+// Copy returns a deep copy of an Individual which, unlike Clone, keeps the
+// ID. It is meant for moving an Individual rather than reproducing it, for
+// instance when migrants are sent to another Population, so that the
+// Individual can be recognized wherever it ends up.
+func (indi Individual) Copy() Individual {
+	var c = indi
+	c.Metadata = copyMetadata(indi.Metadata)
+	if indi.Objectives != nil {
+		c.Objectives = copyFloat64s(indi.Objectives)
+	}
+	if indi.Genome != nil {
+		c.Genome = indi.Genome.Clone()
+	}
+	return c
+}
+
+// copyMetadata returns a shallow copy of an Individual's Metadata.
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	var c = make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		c[k] = v
+	}
+	return c
+}
+
+// reserve returns true if an evaluation fits in the evaluation budget, in
+// which case it counts as started. It always returns true if ctx is nil or if
+// there is no budget.
+func (ctx *evalContext) reserve() bool {
+	if ctx == nil || ctx.maxEvaluations == 0 {
+		return true
+	}
+	for {
+		var n = ctx.started.Load()
+		if n >= ctx.maxEvaluations {
+			return false
+		}
+		if ctx.started.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
 // Evaluate the fitness of an individual. Don't evaluate individuals that have
-// already been evaluated.
+// already been evaluated. If the evaluation budget of the GA is spent the
+// Individual is marked as Skipped instead, see GAConfig.MaxEvaluations.
 func (indi *Individual) Evaluate() error {
 	if indi.Evaluated {
 		return nil
 	}
-	var fitness, err = indi.Genome.Evaluate()
+	if !indi.eval.reserve() {
+		skipEvaluation(indi)
+		return nil
+	}
+	return indi.evaluate()
+}
+
+// evaluate evaluates indi whether or not the evaluation budget is spent, which
+// is meant for the evaluations that aren't part of the search, such as the
+// ones of the polishing, which has its own budget.
+func (indi *Individual) evaluate() error {
+	if indi.Evaluated {
+		return nil
+	}
+	var (
+		start     = time.Now()
+		evaluated = indi.Genome
+		fitness   float64
+		err       error
+	)
+	if indi.eval != nil && indi.eval.timeout > 0 {
+		evaluated, fitness, err = evaluateWithTimeout(indi.Genome, indi.eval.timeout)
+	} else {
+		fitness, err = indi.Genome.Evaluate()
+	}
+	indi.EvalDuration = time.Since(start)
+	if indi.eval != nil {
+		indi.eval.count.Add(1)
+		indi.eval.elapsed.Add(int64(indi.EvalDuration))
+	}
 	if err != nil {
 		return err
 	}
 	indi.Fitness = fitness
+	indi.Violation = 0
+	if indi.eval != nil && indi.eval.constraints != nil {
+		indi.Violation = indi.eval.constraints(indi.Genome)
+	}
+	indi.Objectives = nil
+	if mo, ok := evaluated.(MultiObjective); ok {
+		indi.Objectives = copyFloat64s(mo.Objectives())
+	}
+	if indi.eval.hashesContent() {
+		indi.ID = indi.eval.newID(indi.Genome, nil)
+	}
 	indi.Evaluated = true
+	indi.Skipped = false
+	indi.pending = false
 	return nil
 }
 
+// skipEvaluation marks indi as skipped without evaluating it, it then ranks
+// last.
+func skipEvaluation(indi *Individual) {
+	rankLast(indi)
+	indi.Evaluated = true
+	indi.Skipped = true
+}
+
+// rankLast gives indi an infinite fitness, and an infinite violation if the GA
+// has a ConstraintHandler, so that it ranks after every evaluated Individual.
+func rankLast(indi *Individual) {
+	indi.Fitness = math.Inf(1)
+	indi.Violation = 0
+	if indi.eval != nil && indi.eval.constraints != nil {
+		indi.Violation = math.Inf(1)
+	}
+}
+
+// evaluateWithTimeout evaluates a copy of genome and gives up after timeout,
+// in which case the fitness is +Inf. Genome.Evaluate can't be interrupted, hence
+// the abandoned evaluation keeps running in the background; evaluating a copy
+// ensures it doesn't race with the modifications of the original Genome. The
+// evaluated copy is returned so that the Objectives of a MultiObjective Genome
+// can be read from it, it is nil if the evaluation was abandoned.
+func evaluateWithTimeout(genome Genome, timeout time.Duration) (Genome, float64, error) {
+	type result struct {
+		fitness float64
+		err     error
+	}
+	var (
+		clone = genome.Clone()
+		done  = make(chan result, 1)
+		timer = time.NewTimer(timeout)
+	)
+	defer timer.Stop()
+	go func() {
+		var fitness, err = clone.Evaluate()
+		done <- result{fitness, err}
+	}()
+	select {
+	case r := <-done:
+		return clone, r.fitness, r.err
+	case <-timer.C:
+		return nil, math.Inf(1), nil
+	}
+}
+
 // GetFitness returns the fitness of an Individual after making sure it has been
 // evaluated.
 func (indi *Individual) GetFitness() float64 {
@@ -75,6 +309,7 @@ func (indi *Individual) GetFitness() float64 {
 func (indi *Individual) Mutate(rng *rand.Rand) {
 	indi.Genome.Mutate(rng)
 	indi.Evaluated = false
+	indi.eval.checkGenome("mutation", indi.Genome)
 }
 
 // Crossover an individual by calling the Crossover method of its Genome.
@@ -82,6 +317,53 @@ func (indi *Individual) Crossover(mate Individual, rng *rand.Rand) {
 	indi.Genome.Crossover(mate.Genome, rng)
 	indi.Evaluated = false
 	mate.Evaluated = false
+	indi.eval.checkGenome("crossover", indi.Genome)
+	indi.eval.checkGenome("crossover", mate.Genome)
+}
+
+// breed produces the offsprings of indi, whose Genome has to be a Breeder, and
+// its mates. The offsprings are new Individuals which inherit the Metadata of
+// indi.
+func (indi Individual) breed(mates Individuals, rng *rand.Rand) (Individuals, error) {
+	var genomes = make([]Genome, len(mates))
+	for i, mate := range mates {
+		genomes[i] = mate.Genome
+	}
+	var children = indi.Genome.(Breeder).Breed(genomes, rng)
+	if len(children) == 0 {
+		return nil, errors.New("Breed didn't return any offspring")
+	}
+	var offsprings = make(Individuals, len(children))
+	for i, child := range children {
+		offsprings[i] = Individual{
+			Genome:   child,
+			Fitness:  math.Inf(1),
+			ID:       indi.eval.newID(child, rng),
+			Metadata: copyMetadata(indi.Metadata),
+			eval:     indi.eval,
+		}
+		indi.eval.checkGenome("Breed", child)
+	}
+	return offsprings, nil
+}
+
+// crossover recombines the parents and returns the offsprings. If the Genomes
+// are Breeders then the parents are sorted from best to worst and the best
+// one breeds with the others, else the two parents are crossed over in place
+// and returned as the two offsprings. Only Breeders can recombine more than two
+// parents.
+func crossover(parents Individuals, rng *rand.Rand) (Individuals, error) {
+	if _, ok := parents[0].Genome.(Breeder); ok {
+		sort.SliceStable(parents, func(i, j int) bool { return parents[i].Better(parents[j]) })
+		return parents[0].breed(parents[1:], rng)
+	}
+	if len(parents) != 2 {
+		return nil, fmt.Errorf("%T has to be a Breeder to recombine %d parents", parents[0].Genome, len(parents))
+	}
+	parents[0].Crossover(parents[1], rng)
+	// Crossover receives a copy of the mate, its flag has to be reset here
+	parents[1].Evaluated = false
+	return parents, nil
 }
 
 // IdxOfClosest returns the index of the closest individual from a slice of
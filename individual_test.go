@@ -1,8 +1,13 @@
 package eaopt
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestIndividualString(t *testing.T) {
@@ -50,6 +55,22 @@ func TestCloneIndividual(t *testing.T) {
 	}
 }
 
+func TestCloneIndividualMetadata(t *testing.T) {
+	var (
+		rng  = newRand()
+		indi = NewIndividual(NewVector(rng), rng)
+	)
+	if indi.Clone(rng).Metadata != nil {
+		t.Error("Metadata should be nil")
+	}
+	indi.Metadata = map[string]interface{}{"origin": "seed", "violation": 1.5}
+	var clone = indi.Clone(rng)
+	clone.Metadata["origin"] = "clone"
+	if indi.Metadata["origin"] != "seed" || clone.Metadata["violation"] != 1.5 {
+		t.Errorf("Metadata was not copied: %v and %v", indi.Metadata, clone.Metadata)
+	}
+}
+
 func TestEvaluateIndividual(t *testing.T) {
 	var (
 		rng    = newRand()
@@ -81,6 +102,61 @@ func TestEvaluateIndividualWithError(t *testing.T) {
 	}
 }
 
+// A slowVector is a Vector whose evaluation takes a given duration.
+type slowVector struct {
+	Vector
+	Delay time.Duration
+}
+
+func (s slowVector) Evaluate() (float64, error) {
+	time.Sleep(s.Delay)
+	return s.Vector.Evaluate()
+}
+
+func (s slowVector) Crossover(q Genome, rng *rand.Rand) {
+	s.Vector.Crossover(q.(slowVector).Vector, rng)
+}
+
+func (s slowVector) Clone() Genome {
+	return slowVector{Vector: s.Vector.Clone().(Vector), Delay: s.Delay}
+}
+
+func TestEvaluateIndividualTimeout(t *testing.T) {
+	var (
+		rng  = newRand()
+		indi = NewIndividual(slowVector{Vector: Vector{1, 2}, Delay: 20 * time.Millisecond}, rng)
+	)
+	if err := indi.Evaluate(); err != nil {
+		t.Fatal(err)
+	}
+	if indi.Fitness != 3 || indi.EvalDuration < 20*time.Millisecond {
+		t.Errorf("Expected a fitness of 3 in at least 20ms, got %f in %v", indi.Fitness, indi.EvalDuration)
+	}
+	// The duration and the context are inherited by the clones
+	indi.eval = &evalContext{timeout: time.Millisecond}
+	var clone = indi.Clone(rng)
+	if clone.EvalDuration != indi.EvalDuration || clone.eval != indi.eval {
+		t.Error("The clone should share the evaluation context and duration")
+	}
+	// Evaluations which take too long are given up
+	clone.Evaluated = false
+	if err := clone.Evaluate(); err != nil {
+		t.Fatal(err)
+	}
+	if !clone.Evaluated || !math.IsInf(clone.Fitness, 1) || clone.EvalDuration >= 20*time.Millisecond {
+		t.Errorf("Expected an infinite fitness, got %f in %v", clone.Fitness, clone.EvalDuration)
+	}
+	if indi.eval.count.Load() != 1 || indi.eval.elapsed.Load() != int64(clone.EvalDuration) {
+		t.Error("The evaluation should have been accounted for")
+	}
+	// Errors are returned as usual
+	var faulty = NewIndividual(NewErrorGenome(rng), rng)
+	faulty.eval = indi.eval
+	if err := faulty.Evaluate(); err == nil {
+		t.Error("An error should have been raised")
+	}
+}
+
 func TestMutateIndividual(t *testing.T) {
 	var (
 		rng    = newRand()
@@ -110,3 +186,51 @@ func TestCrossoverIndividual(t *testing.T) {
 		t.Error("Offsprings shouldn't share pointers with parents")
 	}
 }
+
+// A boundedVector is invalid once one of its values leaves [-100, 100].
+type boundedVector struct{ Vector }
+
+func (bv boundedVector) Crossover(y Genome, rng *rand.Rand) {
+	bv.Vector.Crossover(y.(boundedVector).Vector, rng)
+}
+func (bv boundedVector) Clone() Genome { return boundedVector{bv.Vector.Clone().(Vector)} }
+func (bv boundedVector) Validate() error {
+	for _, x := range bv.Vector {
+		if math.Abs(x) > 100 {
+			return fmt.Errorf("%f is out of bounds", x)
+		}
+	}
+	return nil
+}
+
+func TestValidateGenomes(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.ValidateGenomes = true
+	conf.NGenerations = 5
+	// Vectors are mutated proportionally to their values, hence they quickly
+	// leave the bounds
+	var newGenome = func(rng *rand.Rand) Genome {
+		return boundedVector{Vector{99.9999, 99.9999, 99.9999, 99.9999}}
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	err = ga.Minimize(newGenome)
+	if err == nil || !strings.Contains(err.Error(), "produced an invalid eaopt.boundedVector") {
+		t.Errorf("Expected an invalid genome error, got %v", err)
+	}
+	// Without validation the GA runs
+	conf.ValidateGenomes = false
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(newGenome); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	// GenomeValidator checks any Genome, including the initial ones
+	conf.ValidateGenomes = true
+	conf.GenomeValidator = func(g Genome) error { return errors.New("invalid") }
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err == nil || !strings.Contains(err.Error(), "newGenome") {
+		t.Errorf("Expected an error about newGenome, got %v", err)
+	}
+}
@@ -1,11 +1,11 @@
 package eaopt
 
 import (
-	"math"
 	"math/rand"
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/tsenart/kth"
 	"golang.org/x/sync/errgroup"
@@ -68,10 +68,21 @@ func newIndividuals(n uint, parallel bool, newGenome func(rng *rand.Rand) Genome
 
 // Evaluate each Individual in a slice.
 func (indis Individuals) Evaluate(parallel bool) error {
+	return indis.evaluateWith(parallel, (*Individual).Evaluate)
+}
+
+// evaluate evaluates each Individual in a slice whether or not the evaluation
+// budget is spent, see Individual.evaluate.
+func (indis Individuals) evaluate(parallel bool) error {
+	return indis.evaluateWith(parallel, (*Individual).evaluate)
+}
+
+// evaluateWith evaluates each Individual in a slice with evaluate.
+func (indis Individuals) evaluateWith(parallel bool, evaluate func(indi *Individual) error) error {
 	if !parallel {
 		var err error
 		for i := range indis {
-			err = indis[i].Evaluate()
+			err = evaluate(&indis[i])
 			if err != nil {
 				return err
 			}
@@ -90,7 +101,7 @@ func (indis Individuals) Evaluate(parallel bool) error {
 		a := a // https://golang.org/doc/faq#closures_and_goroutines
 		var b = minInt(a+chunkSize, n)
 		g.Go(func() error {
-			return indis[a:b].Evaluate(false)
+			return indis[a:b].evaluateWith(false, evaluate)
 		})
 	}
 
@@ -106,9 +117,10 @@ func (indis Individuals) Mutate(mutRate float64, rng *rand.Rand) {
 	}
 }
 
-// SortByFitness ascendingly sorts individuals by fitness.
+// SortByFitness ascendingly sorts individuals by fitness, or following Deb's
+// feasibility rules if they have violations, see Individual.Better.
 func (indis Individuals) SortByFitness() {
-	kth.PDQSelectFunc(indis, 1, func(a, b Individual) bool { return a.Fitness < b.Fitness })
+	kth.PDQSelectFunc(indis, 1, func(a, b Individual) bool { return a.Better(b) })
 }
 
 // SortByDistanceToMedoid sorts Individuals according to their distance to the
@@ -133,23 +145,68 @@ func (indis Individuals) getFitnesses() []float64 {
 	return fitnesses
 }
 
-// FitMin returns the best fitness of a slice of individuals.
+// FitMin returns the best fitness of a slice of individuals. NaN fitnesses
+// are ignored, see FitStats.
 func (indis Individuals) FitMin() float64 {
-	return minFloat64s(indis.getFitnesses())
+	return indis.fitSummary(SkipNaN).Min
 }
 
-// FitMax returns the worst fitness of a slice of individuals.
+// FitMax returns the worst fitness of a slice of individuals. NaN fitnesses
+// are ignored.
 func (indis Individuals) FitMax() float64 {
-	return maxFloat64s(indis.getFitnesses())
+	return indis.fitSummary(SkipNaN).Max
 }
 
-// FitAvg returns the average fitness of a slice of individuals.
+// FitAvg returns the average fitness of a slice of individuals. NaN fitnesses
+// are ignored.
 func (indis Individuals) FitAvg() float64 {
-	return meanFloat64s(indis.getFitnesses())
+	return indis.fitSummary(SkipNaN).Avg
 }
 
 // FitStd returns the standard deviation of the fitness of a slice of
-// individuals.
+// individuals. NaN fitnesses are ignored.
 func (indis Individuals) FitStd() float64 {
-	return math.Sqrt(varianceFloat64s(indis.getFitnesses()))
+	return indis.fitSummary(SkipNaN).Std
+}
+
+// FitStats returns the statistics of the fitnesses of a slice of individuals,
+// quartiles included. policy determines how NaN and infinite fitnesses are
+// treated.
+func (indis Individuals) FitStats(policy NonFinitePolicy) FitnessStats {
+	return NewFitnessStats(indis.getFitnesses(), policy)
+}
+
+// fitSummary is like FitStats but doesn't compute the quartiles, which
+// requires sorting the fitnesses.
+func (indis Individuals) fitSummary(policy NonFinitePolicy) FitnessStats {
+	var acc = newFitnessAccumulator(policy)
+	for _, indi := range indis {
+		acc.add(indi.Fitness)
+	}
+	return acc.stats()
+}
+
+// EvalTimeAvg returns the average evaluation duration of a slice of
+// individuals.
+func (indis Individuals) EvalTimeAvg() time.Duration {
+	if len(indis) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, indi := range indis {
+		total += indi.EvalDuration
+	}
+	return total / time.Duration(len(indis))
+}
+
+// EvalTimeMax returns the longest evaluation duration of a slice of
+// individuals.
+func (indis Individuals) EvalTimeMax() time.Duration {
+	var max time.Duration
+	for _, indi := range indis {
+		if indi.EvalDuration > max {
+			max = indi.EvalDuration
+		}
+	}
+	return max
 }
@@ -50,3 +50,48 @@ func InitUniqueString(n uint, corpus []string, rng *rand.Rand) (strings []string
 	}
 	return
 }
+
+// A FloatInitializer samples n vectors whose i-th value lies between lower[i]
+// and upper[i]. InitUnifPoints, InitLatinHypercube and InitSobol are
+// FloatInitializers.
+type FloatInitializer func(n uint, lower, upper []float64, rng *rand.Rand) [][]float64
+
+// InitUnifPoints samples n vectors with InitJaggFloat64.
+func InitUnifPoints(n uint, lower, upper []float64, rng *rand.Rand) (points [][]float64) {
+	points = make([][]float64, n)
+	for i := range points {
+		points[i] = InitJaggFloat64(uint(len(lower)), lower, upper, rng)
+	}
+	return
+}
+
+// InitLatinHypercube samples n points of len(lower) dimensions such that each
+// dimension's range [lower[d], upper[d]) is split into n strata of equal width
+// which each contain exactly one point. The points thus cover the search space
+// more evenly than when they are sampled independently, which makes them good
+// initial values for float genomes.
+func InitLatinHypercube(n uint, lower, upper []float64, rng *rand.Rand) (points [][]float64) {
+	points = make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, len(lower))
+	}
+	for d := range lower {
+		var width = (upper[d] - lower[d]) / float64(n)
+		for i, stratum := range rng.Perm(int(n)) {
+			points[i][d] = lower[d] + (float64(stratum)+rng.Float64())*width
+		}
+	}
+	return
+}
+
+// InitOpposite returns the opposite of x, which is its mirror image with
+// respect to the center of the bounds: lower + upper - x. Opposition-based
+// learning evaluates both a random vector and its opposite and keeps the best
+// of them, since the opposite is as likely to be closer to the optimum.
+func InitOpposite(x, lower, upper []float64) []float64 {
+	var opposite = make([]float64, len(x))
+	for i := range x {
+		opposite[i] = lower[i] + upper[i] - x[i]
+	}
+	return opposite
+}
@@ -154,3 +154,40 @@ func TestInitUniqueString(t *testing.T) {
 		}
 	}
 }
+
+func TestInitLatinHypercube(t *testing.T) {
+	var (
+		rng          = newRand()
+		lower, upper = []float64{-1, 0, 10}, []float64{1, 5, 20}
+	)
+	for _, n := range []uint{0, 1, 7} {
+		var points = InitLatinHypercube(n, lower, upper, rng)
+		if len(points) != int(n) {
+			t.Fatalf("Expected %d points, got %d", n, len(points))
+		}
+		// Each stratum of each dimension should contain exactly one point
+		for d := range lower {
+			var strata = make(map[int]bool)
+			for _, p := range points {
+				if p[d] < lower[d] || p[d] >= upper[d] {
+					t.Errorf("Out of bound value %f", p[d])
+				}
+				strata[int((p[d]-lower[d])/(upper[d]-lower[d])*float64(n))] = true
+			}
+			if len(strata) != int(n) {
+				t.Errorf("Expected %d strata in dimension %d, got %d", n, d, len(strata))
+			}
+		}
+	}
+}
+
+func TestInitOpposite(t *testing.T) {
+	var x = InitOpposite([]float64{1, -2}, []float64{0, -5}, []float64{10, 5})
+	if x[0] != 9 || x[1] != 2 {
+		t.Errorf("Expected [9 2], got %v", x)
+	}
+	var points = InitUnifPoints(5, []float64{0, 1}, []float64{1, 2}, newRand())
+	if len(points) != 5 || len(points[0]) != 2 {
+		t.Errorf("Expected 5 points of 2 values, got %v", points)
+	}
+}
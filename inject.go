@@ -0,0 +1,92 @@
+package eaopt
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// immigrants holds the Genomes passed to GA.Inject until the next generation
+// boundary.
+type immigrants struct {
+	mutex   sync.Mutex
+	genomes []Genome
+	next    int // Index of the Population receiving the next Genome
+}
+
+// Inject queues genomes, for instance solutions found by a heuristic or by
+// another optimizer, for insertion into the Populations at the start of the
+// next generation. It is safe to call from any goroutine while the GA is
+// running, for GAs created with NewGA. Each Genome is evaluated and replaces
+// the worst Individual of a Population, the Populations taking turns, and the
+// Genome of the replaced Individual goes to the GenomePool, if the GA has one.
+// The Genomes belong to the GA once injected and shouldn't be modified.
+func (ga *GA) Inject(genomes ...Genome) {
+	if ga.immigrants == nil {
+		ga.immigrants = new(immigrants)
+	}
+	ga.immigrants.mutex.Lock()
+	ga.immigrants.genomes = append(ga.immigrants.genomes, genomes...)
+	ga.immigrants.mutex.Unlock()
+}
+
+// immigrate inserts the injected Genomes into the Populations, which are then
+// sorted, and updates the hall of fame.
+func (ga *GA) immigrate() error {
+	if ga.immigrants == nil {
+		return nil
+	}
+	var q = ga.immigrants
+	q.mutex.Lock()
+	var genomes = q.genomes
+	q.genomes = nil
+	q.mutex.Unlock()
+	if len(genomes) == 0 {
+		return nil
+	}
+	var indis = make(Individuals, len(genomes))
+	for i, genome := range genomes {
+		indis[i] = NewIndividual(genome, ga.RNG)
+		indis[i].eval = ga.eval
+		if ga.IDGenerator != nil {
+			indis[i].ID = ga.eval.newID(genome, ga.RNG)
+		}
+		ga.eval.checkGenome("Inject", genome)
+	}
+	if err := ga.eval.takeInvalid(); err != nil {
+		return err
+	}
+	if err := indis.Evaluate(ga.ParallelEval); err != nil {
+		return errors.Wrap(err, "evaluating injected genomes")
+	}
+	var (
+		previous = ga.best()
+		touched  = make([]bool, len(ga.Populations))
+	)
+	for _, indi := range indis {
+		var (
+			k     = q.next % len(ga.Populations)
+			pop   = &ga.Populations[k]
+			worst = 0
+		)
+		q.next++
+		for j := range pop.Individuals {
+			if pop.Individuals[worst].Better(pop.Individuals[j]) {
+				worst = j
+			}
+		}
+		ga.recycle(pop.Individuals[worst])
+		pop.Individuals[worst] = indi
+		touched[k] = true
+		ga.updateHallOfFame(Individuals{indi}, pop.RNG)
+	}
+	for k := range touched {
+		if touched[k] {
+			ga.Populations[k].Individuals.SortByFitness()
+		}
+	}
+	if best := ga.best(); best.ID != previous.ID {
+		ga.Events.emitNewBest(NewBestEvent{GA: ga, Best: best, Previous: previous.Fitness})
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package eaopt
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGAInject(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.PopSize = 10
+	conf.NGenerations = 3
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		good    = Vector{-100, -100, -100, 0}
+		newBest bool
+	)
+	ga.Events.OnNewBest(func(e NewBestEvent) { newBest = newBest || e.Best.Fitness <= -300 })
+	ga.Inject(good, Vector{1, 1, 1, 1})
+	if err = ga.evolve(); err != nil {
+		t.Fatal(err)
+	}
+	if ga.HallOfFame[0].Fitness > -300 || !newBest {
+		t.Errorf("Expected the injected genome to enter the hall of fame, got %v", ga.HallOfFame[0])
+	}
+	// Nothing is left in the queue
+	var best = ga.HallOfFame[0]
+	if err = ga.immigrate(); err != nil {
+		t.Fatal(err)
+	}
+	if ga.HallOfFame[0].ID != best.ID {
+		t.Error("The hall of fame shouldn't have changed")
+	}
+}
+
+func TestGAInjectPopulations(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.PopSize = 5
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	ga.Inject(Vector{-50, 0, 0, 0}, Vector{-60, 0, 0, 0}, Vector{-70, 0, 0, 0})
+	if err = ga.immigrate(); err != nil {
+		t.Fatal(err)
+	}
+	// The Populations take turns and stay sorted
+	for i, want := range []float64{-70, -60} {
+		var pop = ga.Populations[i]
+		if pop.Individuals[0].Fitness != want {
+			t.Errorf("Expected %f at the top of population %d, got %f", want, i, pop.Individuals[0].Fitness)
+		}
+		if len(pop.Individuals) != 5 {
+			t.Errorf("Expected 5 individuals, got %d", len(pop.Individuals))
+		}
+	}
+	if ga.Evaluations() != 2*5+3 {
+		t.Errorf("Expected %d evaluations, got %d", 2*5+3, ga.Evaluations())
+	}
+}
+
+func TestGAInjectConcurrently(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 20
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			ga.Inject(Vector{float64(-i), 0, 0, 0})
+		}
+	}()
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+}
+
+func TestGAInjectInvalid(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.ValidateGenomes = true
+	conf.GenomeValidator = func(g Genome) error {
+		if len(g.(Vector)) == 0 {
+			return errors.New("empty vector")
+		}
+		return nil
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	ga.Inject(Vector{})
+	if err = ga.evolve(); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,136 @@
+package eaopt
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// A Rounding determines how a float64 is turned into an integer.
+type Rounding int
+
+const (
+	// RoundNearest rounds half away from zero.
+	RoundNearest Rounding = iota
+	// RoundFloor rounds towards minus infinity, which suits dimensions whose
+	// bounds are [min, max+1) so that every integer gets the same share of the
+	// search space.
+	RoundFloor
+	// RoundStochastic rounds up with a probability equal to the fractional
+	// part, hence the expected value of the rounded number is the number
+	// itself, which keeps the landscape continuous on average.
+	RoundStochastic
+)
+
+// String returns the name of the Rounding.
+func (r Rounding) String() string {
+	switch r {
+	case RoundNearest:
+		return "nearest"
+	case RoundFloor:
+		return "floor"
+	case RoundStochastic:
+		return "stochastic"
+	}
+	return fmt.Sprintf("Rounding(%d)", int(r))
+}
+
+// RoundFloat64s rounds the given dimensions of x in place. The random number
+// generator is only used by RoundStochastic.
+func RoundFloat64s(x []float64, dims []int, rounding Rounding, rng *rand.Rand) {
+	for _, d := range dims {
+		switch rounding {
+		case RoundNearest:
+			x[d] = math.Round(x[d])
+		case RoundFloor:
+			x[d] = math.Floor(x[d])
+		case RoundStochastic:
+			var floor = math.Floor(x[d])
+			if rng.Float64() < x[d]-floor {
+				floor++
+			}
+			x[d] = floor
+		}
+	}
+}
+
+// IntegerDims marks the dimensions of a float genome which represent integers
+// in mixed-integer problems. The genome itself stays continuous, so that the
+// float operators apply unchanged, and the integer dimensions are rounded
+// before each evaluation. Setting the Integers field of a FloatProblem does so
+// for FloatVectors, whereas Wrap does so for the optimizers that minimize a
+// func([]float64) float64. IntegerDims are created with NewIntegerDims and
+// are safe for concurrent use.
+type IntegerDims struct {
+	Dims     []int
+	Rounding Rounding
+
+	mutex sync.Mutex
+	rng   *rand.Rand // Only used by RoundStochastic
+}
+
+// NewIntegerDims returns IntegerDims which round the given dimensions with
+// rounding. The random number generator is only used by RoundStochastic; it
+// can be nil, in which case a random one is created.
+func NewIntegerDims(rounding Rounding, rng *rand.Rand, dims ...int) *IntegerDims {
+	if rng == nil {
+		rng = newRand()
+	}
+	return &IntegerDims{Dims: dims, Rounding: rounding, rng: rng}
+}
+
+// Validate checks the IntegerDims against a genome with nDims dimensions.
+func (id *IntegerDims) Validate(nDims int) error {
+	if id.Rounding < RoundNearest || id.Rounding > RoundStochastic {
+		return fmt.Errorf("unknown %v", id.Rounding)
+	}
+	var seen = make(map[int]bool)
+	for _, d := range id.Dims {
+		if d < 0 || d >= nDims {
+			return fmt.Errorf("integer dimension %d is out of range [0, %d)", d, nDims)
+		}
+		if seen[d] {
+			return fmt.Errorf("integer dimension %d is listed twice", d)
+		}
+		seen[d] = true
+	}
+	return nil
+}
+
+// Round returns a copy of x whose integer dimensions are rounded, which is
+// what the objective function receives.
+func (id *IntegerDims) Round(x []float64) []float64 {
+	var rounded = copyFloat64s(x)
+	if id.Rounding == RoundStochastic {
+		id.mutex.Lock()
+		defer id.mutex.Unlock()
+		if id.rng == nil {
+			id.rng = newRand()
+		}
+	}
+	RoundFloat64s(rounded, id.Dims, id.Rounding, id.rng)
+	return rounded
+}
+
+// Report returns a copy of x whose integer dimensions are rounded for
+// reporting a solution. It is the same as Round except with RoundStochastic,
+// whose random outcome isn't reproducible, in which case the dimensions are
+// rounded to the nearest integer.
+func (id *IntegerDims) Report(x []float64) []float64 {
+	if id.Rounding == RoundStochastic {
+		var rounded = copyFloat64s(x)
+		RoundFloat64s(rounded, id.Dims, RoundNearest, nil)
+		return rounded
+	}
+	return id.Round(x)
+}
+
+// Wrap returns an objective function which rounds the integer dimensions of
+// its input before calling f. The solutions returned by the optimizers still
+// have to be rounded with Report.
+func (id *IntegerDims) Wrap(f func([]float64) float64) func([]float64) float64 {
+	return func(x []float64) float64 {
+		return f(id.Round(x))
+	}
+}
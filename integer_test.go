@@ -0,0 +1,126 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRoundFloat64s(t *testing.T) {
+	var rng = newRand()
+	for _, tc := range []struct {
+		rounding Rounding
+		expected []float64
+	}{
+		{RoundNearest, []float64{2, 1.5, -3, 0.25}},
+		{RoundFloor, []float64{1, 1.5, -3, 0.25}},
+	} {
+		var x = []float64{1.5, 1.5, -2.6, 0.25}
+		RoundFloat64s(x, []int{0, 2}, tc.rounding, rng)
+		for i := range x {
+			if x[i] != tc.expected[i] {
+				t.Errorf("%v: expected %v, got %v", tc.rounding, tc.expected, x)
+				break
+			}
+		}
+	}
+	// Stochastic rounding is unbiased
+	var sum float64
+	for i := 0; i < 10000; i++ {
+		var x = []float64{2.3}
+		RoundFloat64s(x, []int{0}, RoundStochastic, rng)
+		if x[0] != 2 && x[0] != 3 {
+			t.Fatalf("Expected 2 or 3, got %f", x[0])
+		}
+		sum += x[0]
+	}
+	if mean := sum / 10000; math.Abs(mean-2.3) > 0.05 {
+		t.Errorf("Expected a mean of 2.3, got %f", mean)
+	}
+}
+
+func TestIntegerDims(t *testing.T) {
+	var id = NewIntegerDims(RoundStochastic, newRand(), 1)
+	if err := id.Validate(2); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var x = []float64{0.4, 0.4}
+	if r := id.Report(x); r[0] != 0.4 || r[1] != 0 || x[1] != 0.4 {
+		t.Errorf("Expected [0.4 0], got %v", r)
+	}
+	var f = id.Wrap(func(x []float64) float64 { return x[1] })
+	if y := f(x); y != 0 && y != 1 {
+		t.Errorf("Expected 0 or 1, got %f", y)
+	}
+	for _, invalid := range []*IntegerDims{
+		NewIntegerDims(RoundNearest, nil, 2),
+		NewIntegerDims(RoundNearest, nil, -1),
+		NewIntegerDims(RoundNearest, nil, 0, 0),
+		NewIntegerDims(RoundStochastic+1, nil, 0),
+	} {
+		if err := invalid.Validate(2); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestFloatProblemIntegers(t *testing.T) {
+	var p = &FloatProblem{
+		Lower:    []float64{-10, -1},
+		Upper:    []float64{10, 1},
+		MutRate:  0.5,
+		Sigma:    0.1,
+		Integers: NewIntegerDims(RoundNearest, nil, 0),
+		F: func(x []float64) (float64, error) {
+			if x[0] != math.Round(x[0]) {
+				return 0, errors.New("x[0] should be an integer")
+			}
+			return (x[0]-2.3)*(x[0]-2.3) + x[1]*x[1], nil
+		},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 30
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if x := ga.HallOfFame[0].Genome.(*FloatVector).Rounded(); x[0] != 2 {
+		t.Errorf("Expected x[0] = 2, got %v", x)
+	}
+	// BatchF receives rounded rows but the Values stay continuous
+	p.BatchF = func(m FloatMatrix) ([]float64, error) {
+		var fitnesses = make([]float64, m.Rows)
+		for i := range fitnesses {
+			var err error
+			if fitnesses[i], err = p.F(m.Row(i)); err != nil {
+				return nil, err
+			}
+		}
+		return fitnesses, nil
+	}
+	conf.Model = ModContiguous{Model: conf.Model}
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var continuous bool
+	for _, indi := range ga.Populations[0].Individuals {
+		var v = indi.Genome.(*FloatVector)
+		if v.Values[0] != math.Round(v.Values[0]) {
+			continuous = true
+		}
+	}
+	if !continuous {
+		t.Error("Expected the genomes to stay continuous")
+	}
+	p.Integers = NewIntegerDims(RoundNearest, nil, 2)
+	if err = p.Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,131 @@
+package eaopt
+
+import (
+	"errors"
+	"time"
+)
+
+// A Ranking is the answer to a batch of candidates submitted to an
+// InteractiveRanker. Scores maps positions in the batch to a score where lower
+// is better, just like a fitness. A Ranking may be partial: positions that are
+// absent from Scores are left unranked.
+type Ranking struct {
+	Scores map[int]float64
+	Err    error
+}
+
+// An InteractiveRanker receives a batch of candidate Genomes and returns a
+// channel on which a Ranking will eventually be delivered. The ranking may be
+// computed asynchronously, for example by waiting for a user to click on a web
+// page. The channel should deliver at most one Ranking.
+type InteractiveRanker func(batch []Genome) <-chan Ranking
+
+// ModInteractive implements interactive evolution, where the fitness of the
+// offsprings is decided by a human (or any external process) instead of the
+// Evaluate method of each Genome. The offsprings are produced as in
+// ModGenerational and are then handed to Rank in batches of BatchSize. If a
+// Ranking doesn't arrive within Timeout (a Timeout of 0 means waiting
+// indefinitely) or if a candidate is left unranked then the candidate keeps the
+// fitness it inherited from its parent. In every case the offsprings are
+// flagged as evaluated so that Evaluate is never called on them.
+type ModInteractive struct {
+	Selector  Selector
+	MutRate   float64
+	CrossRate float64
+	BatchSize uint
+	Rank      InteractiveRanker
+	Timeout   time.Duration
+}
+
+// Apply ModInteractive.
+func (mod ModInteractive) Apply(pop *Population) error {
+	var offsprings, err = generateOffsprings(
+		uint(len(pop.Individuals)),
+		2,
+		pop.Individuals,
+		mod.Selector,
+		nil,
+		mod.CrossRate,
+		pop.RNG,
+	)
+	if err != nil {
+		return err
+	}
+	// Apply mutation to the offsprings
+	if mod.MutRate > 0 {
+		offsprings.Mutate(mod.MutRate, pop.RNG)
+	}
+	// Submit the offsprings batch by batch
+	for a := 0; a < len(offsprings); a += int(mod.BatchSize) {
+		var b = minInt(a+int(mod.BatchSize), len(offsprings))
+		if err = mod.rankBatch(offsprings[a:b]); err != nil {
+			return err
+		}
+	}
+	// Replace the old population with the new one
+	copy(pop.Individuals, offsprings)
+	return nil
+}
+
+// rankBatch hands a batch of Individuals to the ranker and waits for the
+// answer.
+func (mod ModInteractive) rankBatch(batch Individuals) error {
+	var genomes = make([]Genome, len(batch))
+	for i, indi := range batch {
+		genomes[i] = indi.Genome
+	}
+	var (
+		ranking Ranking
+		ok      bool
+		ch      = mod.Rank(genomes)
+	)
+	if mod.Timeout > 0 {
+		select {
+		case ranking, ok = <-ch:
+		case <-time.After(mod.Timeout):
+		}
+	} else {
+		ranking, ok = <-ch
+	}
+	if ok && ranking.Err != nil {
+		return ranking.Err
+	}
+	for i := range batch {
+		if score, ranked := ranking.Scores[i]; ranked {
+			batch[i].Fitness = score
+		}
+		batch[i].Evaluated = true
+	}
+	return nil
+}
+
+// Validate ModInteractive fields.
+func (mod ModInteractive) Validate() error {
+	// Check the selection method presence
+	if mod.Selector == nil {
+		return errNilSelector
+	}
+	// Check the selection method parameters
+	var errSelector = mod.Selector.Validate()
+	if errSelector != nil {
+		return errSelector
+	}
+	// Check the mutation rate
+	if mod.MutRate < 0 || mod.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	// Check the crossover rate
+	if mod.CrossRate < 0 || mod.CrossRate > 1 {
+		return errInvalidCrossRate
+	}
+	if mod.BatchSize == 0 {
+		return errors.New("BatchSize has to be strictly higher than 0")
+	}
+	if mod.Rank == nil {
+		return errors.New("a Rank function must be provided to ModInteractive")
+	}
+	if mod.Timeout < 0 {
+		return errors.New("Timeout cannot be negative")
+	}
+	return nil
+}
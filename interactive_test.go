@@ -0,0 +1,147 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// rankByPosition ranks every candidate of a batch with its position.
+func rankByPosition(batch []Genome) <-chan Ranking {
+	var ch = make(chan Ranking, 1)
+	go func() {
+		var scores = make(map[int]float64)
+		for i := range batch {
+			scores[i] = float64(i)
+		}
+		ch <- Ranking{Scores: scores}
+	}()
+	return ch
+}
+
+func TestModInteractiveApply(t *testing.T) {
+	var (
+		rng     = newRand()
+		pop     = newPopulation(10, false, NewVector, rng)
+		batches int
+		mod     = ModInteractive{
+			Selector:  SelTournament{1},
+			MutRate:   0.5,
+			CrossRate: 0.5,
+			BatchSize: 4,
+			Rank: func(batch []Genome) <-chan Ranking {
+				batches++
+				return rankByPosition(batch)
+			},
+		}
+	)
+	if err := mod.Apply(&pop); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if batches != 3 {
+		t.Errorf("Expected 3, got %d", batches)
+	}
+	for i, indi := range pop.Individuals {
+		if !indi.Evaluated {
+			t.Error("Individual should be flagged as evaluated")
+		}
+		if indi.Fitness != float64(i%4) {
+			t.Errorf("Expected %f, got %f", float64(i%4), indi.Fitness)
+		}
+	}
+}
+
+func TestModInteractivePartialRanking(t *testing.T) {
+	var (
+		rng = newRand()
+		pop = newPopulation(4, false, NewVector, rng)
+		mod = ModInteractive{
+			Selector:  SelElitism{},
+			BatchSize: 4,
+			Rank: func(batch []Genome) <-chan Ranking {
+				var ch = make(chan Ranking, 1)
+				ch <- Ranking{Scores: map[int]float64{0: -42}}
+				return ch
+			},
+		}
+	)
+	pop.Individuals.Evaluate(false)
+	pop.Individuals.SortByFitness()
+	var inherited = pop.Individuals[0].Fitness
+	if err := mod.Apply(&pop); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if pop.Individuals[0].Fitness != -42 {
+		t.Errorf("Expected -42, got %f", pop.Individuals[0].Fitness)
+	}
+	// The third offspring is a copy of the best Individual and is left unranked
+	if pop.Individuals[2].Fitness != inherited {
+		t.Errorf("Expected %f, got %f", inherited, pop.Individuals[2].Fitness)
+	}
+}
+
+func TestModInteractiveTimeout(t *testing.T) {
+	var (
+		rng = newRand()
+		pop = newPopulation(4, false, NewVector, rng)
+		mod = ModInteractive{
+			Selector:  SelTournament{1},
+			BatchSize: 2,
+			Timeout:   time.Millisecond,
+			Rank: func(batch []Genome) <-chan Ranking {
+				return make(chan Ranking)
+			},
+		}
+	)
+	if err := mod.Apply(&pop); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	for _, indi := range pop.Individuals {
+		if !indi.Evaluated {
+			t.Error("Individual should be flagged as evaluated")
+		}
+	}
+}
+
+func TestModInteractiveRankError(t *testing.T) {
+	var (
+		rng = newRand()
+		pop = newPopulation(4, false, NewVector, rng)
+		mod = ModInteractive{
+			Selector:  SelTournament{1},
+			BatchSize: 2,
+			Rank: func(batch []Genome) <-chan Ranking {
+				var ch = make(chan Ranking, 1)
+				ch <- Ranking{Err: errors.New("user went away")}
+				return ch
+			},
+		}
+	)
+	if err := mod.Apply(&pop); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestModInteractiveValidate(t *testing.T) {
+	var testCases = []struct {
+		mod ModInteractive
+		err bool
+	}{
+		{ModInteractive{Selector: SelTournament{1}, BatchSize: 1, Rank: rankByPosition}, false},
+		{ModInteractive{Selector: nil, BatchSize: 1, Rank: rankByPosition}, true},
+		{ModInteractive{Selector: SelTournament{0}, BatchSize: 1, Rank: rankByPosition}, true},
+		{ModInteractive{Selector: SelTournament{1}, MutRate: -1, BatchSize: 1, Rank: rankByPosition}, true},
+		{ModInteractive{Selector: SelTournament{1}, CrossRate: 2, BatchSize: 1, Rank: rankByPosition}, true},
+		{ModInteractive{Selector: SelTournament{1}, BatchSize: 0, Rank: rankByPosition}, true},
+		{ModInteractive{Selector: SelTournament{1}, BatchSize: 1}, true},
+		{ModInteractive{Selector: SelTournament{1}, BatchSize: 1, Rank: rankByPosition, Timeout: -1}, true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			if err := tc.mod.Validate(); (err != nil) != tc.err {
+				t.Errorf("Expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package eaopt
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// An IntDomain is an inclusive range of integers.
+type IntDomain struct {
+	Min, Max int
+}
+
+// clamp v inside the domain.
+func (d IntDomain) clamp(v int) int {
+	if v < d.Min {
+		return d.Min
+	}
+	if v > d.Max {
+		return d.Max
+	}
+	return v
+}
+
+// sample an integer uniformly from the domain.
+func (d IntDomain) sample(rng *rand.Rand) int {
+	return d.Min + rng.Intn(d.Max-d.Min+1)
+}
+
+// InitUnifInt generates random ints where the i-th int is sampled uniformly
+// from the i-th domain.
+func InitUnifInt(domains []IntDomain, rng *rand.Rand) []int {
+	var ints = make([]int, len(domains))
+	for i, d := range domains {
+		ints[i] = d.sample(rng)
+	}
+	return ints
+}
+
+// MutUniformInt replaces each gene with a random value sampled from its
+// domain with probability rate.
+func MutUniformInt(genome []int, domains []IntDomain, rate float64, rng *rand.Rand) {
+	for i := range genome {
+		if rng.Float64() < rate {
+			genome[i] = domains[i].sample(rng)
+		}
+	}
+}
+
+// MutCreepInt adds or subtracts a random step between 1 and maxStep to each
+// gene with probability rate. The result is clamped inside the gene's domain.
+// Creep mutation is well suited for ordinal variables where nearby values have
+// similar meanings.
+func MutCreepInt(genome []int, domains []IntDomain, rate float64, maxStep int, rng *rand.Rand) {
+	for i := range genome {
+		if rng.Float64() < rate {
+			var step = 1 + rng.Intn(maxStep)
+			if rng.Float64() < 0.5 {
+				step = -step
+			}
+			genome[i] = domains[i].clamp(genome[i] + step)
+		}
+	}
+}
+
+// MutMinConflictsInt is a conflict-directed mutation for constraint
+// satisfaction problems. conflicts returns the number of violated constraints
+// each position is involved in. A conflicting position is picked at random and
+// is assigned the value of its domain that minimizes its number of conflicts,
+// ties being broken at random. Nothing happens if there are no conflicts.
+func MutMinConflictsInt(genome []int, domains []IntDomain, conflicts func([]int) []int, rng *rand.Rand) {
+	var candidates []int
+	for i, c := range conflicts(genome) {
+		if c > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	var (
+		i    = candidates[rng.Intn(len(candidates))]
+		best []int
+		min  = -1
+	)
+	for v := domains[i].Min; v <= domains[i].Max; v++ {
+		genome[i] = v
+		var c = conflicts(genome)[i]
+		if min == -1 || c < min {
+			min, best = c, []int{v}
+		} else if c == min {
+			best = append(best, v)
+		}
+	}
+	genome[i] = best[rng.Intn(len(best))]
+}
+
+// CrossUniformInt swaps each pair of genes between two int slices with
+// probability 0.5.
+func CrossUniformInt(s1 []int, s2 []int, rng *rand.Rand) {
+	for i := range s1 {
+		if rng.Float64() < 0.5 {
+			s1[i], s2[i] = s2[i], s1[i]
+		}
+	}
+}
+
+// An IntVector is a vector of integers where each position has its own
+// domain. The domains and the operator settings are shared through the
+// IntProblem the IntVector belongs to.
+type IntVector struct {
+	Values  []int
+	Problem *IntProblem
+}
+
+// Evaluate the IntVector with the IntProblem's objective function.
+func (v *IntVector) Evaluate() (float64, error) {
+	return v.Problem.F(v.Values)
+}
+
+// Mutate the IntVector. If the IntProblem has a Conflicts function then a
+// min-conflicts mutation is applied with probability ConflictRate. Otherwise
+// each gene is mutated with probability MutRate, either with a creep mutation
+// (with probability CreepRate) or with a uniform mutation.
+func (v *IntVector) Mutate(rng *rand.Rand) {
+	var p = v.Problem
+	if p.Conflicts != nil && rng.Float64() < p.ConflictRate {
+		MutMinConflictsInt(v.Values, p.Domains, p.Conflicts, rng)
+		return
+	}
+	for i := range v.Values {
+		if rng.Float64() >= p.MutRate {
+			continue
+		}
+		if rng.Float64() < p.CreepRate {
+			MutCreepInt(v.Values[i:i+1], p.Domains[i:i+1], 1, p.CreepSize, rng)
+		} else {
+			v.Values[i] = p.Domains[i].sample(rng)
+		}
+	}
+}
+
+// Crossover applies uniform crossover, which keeps each gene inside its
+// domain.
+func (v *IntVector) Crossover(q Genome, rng *rand.Rand) {
+	CrossUniformInt(v.Values, q.(*IntVector).Values, rng)
+}
+
+// Clone returns a deep copy of the IntVector.
+func (v IntVector) Clone() Genome {
+	var values = make([]int, len(v.Values))
+	copy(values, v.Values)
+	return &IntVector{
+		Values:  values,
+		Problem: v.Problem,
+	}
+}
+
+// IntProblem describes a problem defined over integer vectors. Its NewGenome
+// method can be handed to GA.Minimize.
+type IntProblem struct {
+	Domains      []IntDomain
+	MutRate      float64           // Probability of mutating each gene
+	CreepRate    float64           // Probability of a mutation being a creep mutation
+	CreepSize    int               // Maximum step of a creep mutation
+	Conflicts    func([]int) []int // Optional, number of conflicts of each position
+	ConflictRate float64           // Probability of applying a min-conflicts mutation
+	F            func([]int) (float64, error)
+}
+
+// Validate IntProblem fields.
+func (p IntProblem) Validate() error {
+	if len(p.Domains) == 0 {
+		return errors.New("at least one domain has to be provided")
+	}
+	for _, d := range p.Domains {
+		if d.Min > d.Max {
+			return errors.New("domains should have Min lower or equal to Max")
+		}
+	}
+	if p.MutRate < 0 || p.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	if p.CreepRate < 0 || p.CreepRate > 1 {
+		return errors.New("CreepRate should be between 0 and 1")
+	}
+	if p.CreepRate > 0 && p.CreepSize < 1 {
+		return errors.New("CreepSize should be at least 1")
+	}
+	if p.ConflictRate < 0 || p.ConflictRate > 1 {
+		return errors.New("ConflictRate should be between 0 and 1")
+	}
+	if p.ConflictRate > 0 && p.Conflicts == nil {
+		return errors.New("a Conflicts function is required when ConflictRate is positive")
+	}
+	if p.F == nil {
+		return errors.New("F cannot be nil")
+	}
+	return nil
+}
+
+// NewGenome returns an IntVector with values sampled uniformly from their
+// domains.
+func (p *IntProblem) NewGenome(rng *rand.Rand) Genome {
+	return &IntVector{
+		Values:  InitUnifInt(p.Domains, rng),
+		Problem: p,
+	}
+}
+
+// Reset samples new Values in place as NewGenome would, so that a GenomePool
+// can reuse the IntVector.
+func (v *IntVector) Reset(rng *rand.Rand) {
+	for i, d := range v.Problem.Domains {
+		v.Values[i] = d.sample(rng)
+	}
+}
+
+// GraphColoring describes a graph coloring problem: adjacent nodes should not
+// share the same color.
+type GraphColoring struct {
+	NNodes  int
+	NColors int
+	Edges   [][2]int
+}
+
+// Conflicts returns, for each node, the number of neighbours sharing its
+// color.
+func (gc GraphColoring) Conflicts(colors []int) []int {
+	var conflicts = make([]int, gc.NNodes)
+	for _, e := range gc.Edges {
+		if colors[e[0]] == colors[e[1]] {
+			conflicts[e[0]]++
+			conflicts[e[1]]++
+		}
+	}
+	return conflicts
+}
+
+// Evaluate returns the number of edges whose nodes share the same color.
+func (gc GraphColoring) Evaluate(colors []int) (float64, error) {
+	var n float64
+	for _, e := range gc.Edges {
+		if colors[e[0]] == colors[e[1]] {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Problem returns an IntProblem for the graph coloring problem that relies on
+// min-conflicts mutations.
+func (gc GraphColoring) Problem() *IntProblem {
+	var domains = make([]IntDomain, gc.NNodes)
+	for i := range domains {
+		domains[i] = IntDomain{0, gc.NColors - 1}
+	}
+	return &IntProblem{
+		Domains:      domains,
+		MutRate:      1 / float64(gc.NNodes),
+		Conflicts:    gc.Conflicts,
+		ConflictRate: 0.5,
+		F:            gc.Evaluate,
+	}
+}
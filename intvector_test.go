@@ -0,0 +1,116 @@
+package eaopt
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var testDomains = []IntDomain{{0, 3}, {-5, 5}, {10, 10}}
+
+func TestMutUniformInt(t *testing.T) {
+	var rng = newRand()
+	for i := 0; i < 20; i++ {
+		var genome = InitUnifInt(testDomains, rng)
+		MutUniformInt(genome, testDomains, 1, rng)
+		for j, v := range genome {
+			if v < testDomains[j].Min || v > testDomains[j].Max {
+				t.Fatalf("Gene %d with value %d is outside of its domain", j, v)
+			}
+		}
+	}
+}
+
+func TestMutCreepInt(t *testing.T) {
+	var rng = newRand()
+	for i := 0; i < 20; i++ {
+		var (
+			genome = []int{2, 0, 10}
+			before = append([]int{}, genome...)
+		)
+		MutCreepInt(genome, testDomains, 1, 2, rng)
+		for j, v := range genome {
+			if v < testDomains[j].Min || v > testDomains[j].Max {
+				t.Fatalf("Gene %d with value %d is outside of its domain", j, v)
+			}
+			if d := v - before[j]; d > 2 || d < -2 {
+				t.Fatalf("Gene %d moved by %d", j, d)
+			}
+		}
+	}
+}
+
+func TestMutMinConflictsInt(t *testing.T) {
+	var (
+		rng     = newRand()
+		gc      = GraphColoring{NNodes: 2, NColors: 2, Edges: [][2]int{{0, 1}}}
+		domains = gc.Problem().Domains
+		colors  = []int{0, 0}
+	)
+	MutMinConflictsInt(colors, domains, gc.Conflicts, rng)
+	if n, _ := gc.Evaluate(colors); n != 0 {
+		t.Errorf("Expected 0 conflicts, got %f with %v", n, colors)
+	}
+	// Nothing happens without conflicts
+	var solved = append([]int{}, colors...)
+	MutMinConflictsInt(colors, domains, gc.Conflicts, rng)
+	if !reflect.DeepEqual(colors, solved) {
+		t.Errorf("Expected %v, got %v", solved, colors)
+	}
+}
+
+func TestIntProblemValidate(t *testing.T) {
+	var newProblem = func() *IntProblem {
+		return &IntProblem{
+			Domains: testDomains,
+			MutRate: 0.1,
+			F:       func([]int) (float64, error) { return 0, nil },
+		}
+	}
+	var testCases = []func(p *IntProblem){
+		func(p *IntProblem) { p.Domains = nil },
+		func(p *IntProblem) { p.Domains = []IntDomain{{1, 0}} },
+		func(p *IntProblem) { p.MutRate = -1 },
+		func(p *IntProblem) { p.CreepRate = 2 },
+		func(p *IntProblem) { p.CreepRate = 0.5 },
+		func(p *IntProblem) { p.ConflictRate = 2 },
+		func(p *IntProblem) { p.ConflictRate = 0.5 },
+		func(p *IntProblem) { p.F = nil },
+	}
+	if err := newProblem().Validate(); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var p = newProblem()
+			tc(p)
+			if err := p.Validate(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGraphColoringMinimize(t *testing.T) {
+	var (
+		// A 5-cycle needs 3 colors
+		gc = GraphColoring{
+			NNodes:  5,
+			NColors: 3,
+			Edges:   [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, 0}},
+		}
+		problem = gc.Problem()
+		ga, _   = NewDefaultGAConfig().NewGA()
+	)
+	problem.CreepRate = 0.5
+	problem.CreepSize = 1
+	if err := problem.Validate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err := ga.Minimize(problem.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if ga.HallOfFame[0].Fitness != 0 {
+		t.Errorf("Expected 0, got %f", ga.HallOfFame[0].Fitness)
+	}
+}
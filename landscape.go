@@ -0,0 +1,174 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// FitnessDistanceCorrelation returns the Pearson correlation between the
+// fitnesses of indis and their distances to optimum according to metric. The
+// Individuals are evaluated if need be. When minimizing, a value close to 1
+// indicates that fitness guides the search towards the optimum, a value close
+// to -1 that the problem is deceptive and a value close to 0 that fitness
+// tells little about the distance to the optimum.
+func FitnessDistanceCorrelation(indis Individuals, optimum Individual, metric Metric) (float64, error) {
+	if len(indis) < 2 {
+		return 0, errors.New("at least 2 individuals are needed")
+	}
+	if err := indis.Evaluate(false); err != nil {
+		return 0, err
+	}
+	var (
+		fitnesses = indis.getFitnesses()
+		distances = make([]float64, len(indis))
+	)
+	for i, indi := range indis {
+		distances[i] = metric(indi, optimum)
+	}
+	return pearson(fitnesses, distances)
+}
+
+// pearson returns the Pearson correlation between x and y, which have to have
+// the same length.
+func pearson(x, y []float64) (float64, error) {
+	var (
+		mx, my      = meanFloat64s(x), meanFloat64s(y)
+		cov, vx, vy float64
+	)
+	for i := range x {
+		cov += (x[i] - mx) * (y[i] - my)
+		vx += (x[i] - mx) * (x[i] - mx)
+		vy += (y[i] - my) * (y[i] - my)
+	}
+	if vx == 0 || vy == 0 {
+		return 0, errors.New("the correlation is undefined for constant values")
+	}
+	return cov / math.Sqrt(vx*vy), nil
+}
+
+// RandomWalk performs a random walk of the given number of steps through the
+// fitness landscape, starting from start. Each step mutates a copy of the
+// current Genome, hence the neighbourhood explored is the one defined by the
+// Genome's Mutate method. The fitnesses of the steps+1 visited Genomes are
+// returned in order. start isn't modified.
+func RandomWalk(start Genome, steps uint, rng *rand.Rand) ([]float64, error) {
+	var (
+		genome       = start.Clone()
+		fitnesses    = make([]float64, steps+1)
+		fitness, err = genome.Evaluate()
+	)
+	if err != nil {
+		return nil, err
+	}
+	fitnesses[0] = fitness
+	for i := uint(1); i <= steps; i++ {
+		genome = genome.Clone()
+		genome.Mutate(rng)
+		if fitnesses[i], err = genome.Evaluate(); err != nil {
+			return nil, err
+		}
+	}
+	return fitnesses, nil
+}
+
+// Autocorrelation returns the autocorrelation of series, typically obtained
+// with RandomWalk, at the given lag. Values close to 1 indicate a smooth
+// landscape where neighbours have similar fitnesses, values close to 0 a
+// rugged one. NaN is returned if series is constant or shorter than lag+1.
+func Autocorrelation(series []float64, lag uint) float64 {
+	if len(series) <= int(lag) {
+		return math.NaN()
+	}
+	var (
+		m        = meanFloat64s(series)
+		num, den float64
+	)
+	for i, x := range series {
+		den += (x - m) * (x - m)
+		if i+int(lag) < len(series) {
+			num += (x - m) * (series[i+int(lag)] - m)
+		}
+	}
+	if den == 0 {
+		return math.NaN()
+	}
+	return num / den
+}
+
+// CorrelationLength returns -1 / ln(|r(1)|) where r(1) is the autocorrelation
+// of series at lag 1. It estimates the distance beyond which the fitnesses of
+// a random walk stop being correlated; the shorter it is, the more rugged the
+// landscape.
+func CorrelationLength(series []float64) float64 {
+	return -1 / math.Log(math.Abs(Autocorrelation(series, 1)))
+}
+
+// InformationContent returns the entropic measure of ruggedness proposed by
+// Vassilev, Fogarty and Miller. The series is turned into a sequence of
+// symbols indicating whether each step decreases, increases or doesn't change
+// the fitness by more than epsilon. The entropy of the pairs of consecutive
+// symbols that differ is then computed with a base 6 logarithm so that it is
+// between 0 and 1. Higher values indicate more rugged landscapes.
+func InformationContent(series []float64, epsilon float64) float64 {
+	if len(series) < 3 {
+		return math.NaN()
+	}
+	var symbols = make([]int, len(series)-1)
+	for i := range symbols {
+		switch d := series[i+1] - series[i]; {
+		case d < -epsilon:
+			symbols[i] = -1
+		case d > epsilon:
+			symbols[i] = 1
+		}
+	}
+	var counts = make(map[[2]int]int)
+	for i := 1; i < len(symbols); i++ {
+		if symbols[i-1] != symbols[i] {
+			counts[[2]int{symbols[i-1], symbols[i]}]++
+		}
+	}
+	var (
+		n = float64(len(symbols) - 1)
+		h float64
+	)
+	for _, c := range counts {
+		var p = float64(c) / n
+		h -= p * math.Log(p) / math.Log(6)
+	}
+	return h
+}
+
+// A LandscapeAnalysis summarizes the ruggedness of a fitness landscape. Each
+// measure is averaged over several random walks.
+type LandscapeAnalysis struct {
+	Autocorrelation    float64 // Autocorrelation at lag 1
+	CorrelationLength  float64
+	InformationContent float64
+}
+
+// AnalyzeLandscape performs nWalks random walks of the given number of steps,
+// each one starting from a Genome generated with newGenome, and averages the
+// measures of ruggedness computed on each walk. epsilon is the sensitivity of
+// InformationContent.
+func AnalyzeLandscape(newGenome func(rng *rand.Rand) Genome, nWalks, steps uint, epsilon float64,
+	rng *rand.Rand) (LandscapeAnalysis, error) {
+	var la LandscapeAnalysis
+	if nWalks == 0 || steps < 2 {
+		return la, errors.New("at least 1 walk of 2 steps is needed")
+	}
+	for i := uint(0); i < nWalks; i++ {
+		var series, err = RandomWalk(newGenome(rng), steps, rng)
+		if err != nil {
+			return la, err
+		}
+		la.Autocorrelation += Autocorrelation(series, 1)
+		la.CorrelationLength += CorrelationLength(series)
+		la.InformationContent += InformationContent(series, epsilon)
+	}
+	la.Autocorrelation /= float64(nWalks)
+	la.CorrelationLength /= float64(nWalks)
+	la.InformationContent /= float64(nWalks)
+	return la, nil
+}
@@ -0,0 +1,132 @@
+package eaopt
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitnessDistanceCorrelation(t *testing.T) {
+	var (
+		rng     = newRand()
+		optimum = NewIndividual(Vector{0, 0}, rng)
+		indis   = Individuals{
+			NewIndividual(Vector{1, 0}, rng),
+			NewIndividual(Vector{2, 1}, rng),
+			NewIndividual(Vector{4, 4}, rng),
+		}
+	)
+	// The fitness of a Vector is the sum of its elements, which is also its
+	// L1 distance to the origin when it is positive
+	var fdc, err = FitnessDistanceCorrelation(indis, optimum, l1Distance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(fdc-1) > 1e-12 {
+		t.Errorf("Expected 1, got %f", fdc)
+	}
+	// Deceptive
+	indis = Individuals{
+		NewIndividual(Vector{-1, 0}, rng),
+		NewIndividual(Vector{-2, -1}, rng),
+		NewIndividual(Vector{-4, -4}, rng),
+	}
+	if fdc, _ = FitnessDistanceCorrelation(indis, optimum, l1Distance); math.Abs(fdc+1) > 1e-12 {
+		t.Errorf("Expected -1, got %f", fdc)
+	}
+	// Errors
+	if _, err = FitnessDistanceCorrelation(indis[:1], optimum, l1Distance); err == nil {
+		t.Error("Expected an error")
+	}
+	indis = Individuals{NewIndividual(Vector{1}, rng), NewIndividual(Vector{1}, rng)}
+	if _, err = FitnessDistanceCorrelation(indis, optimum, l1Distance); err == nil {
+		t.Error("Expected an error")
+	}
+	indis = Individuals{NewIndividual(ErrorGenome{}, rng), NewIndividual(ErrorGenome{}, rng)}
+	if _, err = FitnessDistanceCorrelation(indis, optimum, l1Distance); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestRandomWalk(t *testing.T) {
+	var (
+		rng    = newRand()
+		start  = Vector{1, 2, 3}
+		series []float64
+		err    error
+	)
+	if series, err = RandomWalk(start, 10, rng); err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 11 || series[0] != 6 {
+		t.Errorf("Expected 11 values starting with 6, got %v", series)
+	}
+	if start[0] != 1 || start[1] != 2 || start[2] != 3 {
+		t.Errorf("The starting Genome has been modified: %v", start)
+	}
+	if _, err = RandomWalk(ErrorGenome{}, 10, rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	var testCases = []struct {
+		series []float64
+		lag    uint
+		out    float64
+	}{
+		{[]float64{1, 2, 3, 4}, 1, 0.25},
+		{[]float64{1, 2, 3, 4}, 0, 1},
+		{[]float64{1, -1, 1, -1}, 1, -0.75},
+	}
+	for i, tc := range testCases {
+		if out := Autocorrelation(tc.series, tc.lag); math.Abs(out-tc.out) > 1e-12 {
+			t.Errorf("Error in test case number %d: expected %f, got %f", i, tc.out, out)
+		}
+	}
+	if !math.IsNaN(Autocorrelation([]float64{1, 1, 1}, 1)) || !math.IsNaN(Autocorrelation([]float64{1}, 1)) {
+		t.Error("Expected NaN")
+	}
+	if l := CorrelationLength([]float64{1, 2, 3, 4}); math.Abs(l-1/math.Log(4)) > 1e-12 {
+		t.Errorf("Expected %f, got %f", 1/math.Log(4), l)
+	}
+}
+
+func TestInformationContent(t *testing.T) {
+	// A monotone walk isn't rugged
+	if h := InformationContent([]float64{1, 2, 3, 4, 5}, 0); h != 0 {
+		t.Errorf("Expected 0, got %f", h)
+	}
+	// Alternating walk, the pairs of symbols are (1, -1), (-1, 1), (1, -1)
+	var (
+		h        = InformationContent([]float64{0, 1, 0, 1, 0}, 0)
+		expected = -(2.0/3*math.Log(2.0/3) + 1.0/3*math.Log(1.0/3)) / math.Log(6)
+	)
+	if math.Abs(h-expected) > 1e-12 {
+		t.Errorf("Expected %f, got %f", expected, h)
+	}
+	// Changes below epsilon are ignored
+	if h = InformationContent([]float64{0, 1, 0, 1, 0}, 2); h != 0 {
+		t.Errorf("Expected 0, got %f", h)
+	}
+	if !math.IsNaN(InformationContent([]float64{1, 2}, 0)) {
+		t.Error("Expected NaN")
+	}
+}
+
+func TestAnalyzeLandscape(t *testing.T) {
+	var la, err = AnalyzeLandscape(NewVector, 5, 50, 0, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if la.Autocorrelation < -1 || la.Autocorrelation > 1 || la.InformationContent < 0 || la.InformationContent > 1 {
+		t.Errorf("Wrong analysis: %+v", la)
+	}
+	if _, err = AnalyzeLandscape(NewVector, 0, 50, 0, newRand()); err == nil {
+		t.Error("Expected an error")
+	}
+	var newErrorGenome = func(rng *rand.Rand) Genome { return ErrorGenome{} }
+	if _, err = AnalyzeLandscape(newErrorGenome, 1, 50, 0, newRand()); err == nil {
+		t.Error("Expected an error")
+	}
+}
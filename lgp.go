@@ -0,0 +1,302 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// An LGPOperator is an instruction of a linear genetic programming register
+// machine. Unary operators (Arity equal to 1) ignore their second argument.
+type LGPOperator struct {
+	Name  string
+	Arity int
+	F     func(a, b float64) float64
+}
+
+// LGPArithmetic is a basic instruction set made of the four arithmetic
+// operators. Division is protected and returns 1 when dividing by 0.
+var LGPArithmetic = []LGPOperator{
+	{Name: "+", Arity: 2, F: func(a, b float64) float64 { return a + b }},
+	{Name: "-", Arity: 2, F: func(a, b float64) float64 { return a - b }},
+	{Name: "*", Arity: 2, F: func(a, b float64) float64 { return a * b }},
+	{Name: "/", Arity: 2, F: func(a, b float64) float64 {
+		if b == 0 {
+			return 1
+		}
+		return a / b
+	}},
+}
+
+// An LGPInstruction computes Dst = Op(Src[0], Src[1]). Dst is always a
+// calculation register. Sources index, in order, the calculation registers,
+// the input registers and the constants of the LGP.
+type LGPInstruction struct {
+	Op  int
+	Dst int
+	Src [2]int
+}
+
+// An LGPGenome is a linear program evolved with linear genetic programming.
+// The output of the program is the content of the first calculation register.
+type LGPGenome struct {
+	Program []LGPInstruction
+	LGP     *LGP
+}
+
+// Run the program on the given inputs and return the value of the output
+// register. Calculation registers are initialized with the inputs in a
+// cyclic fashion.
+func (g *LGPGenome) Run(inputs []float64) float64 {
+	var (
+		lgp       = g.LGP
+		nCalc     = int(lgp.NRegisters)
+		nInputs   = int(lgp.NInputs)
+		registers = make([]float64, nCalc+nInputs+len(lgp.Constants))
+	)
+	if len(inputs) > 0 {
+		for i := 0; i < nCalc; i++ {
+			registers[i] = inputs[i%len(inputs)]
+		}
+	}
+	copy(registers[nCalc:nCalc+nInputs], inputs)
+	copy(registers[nCalc+nInputs:], lgp.Constants)
+	for _, ins := range g.Program {
+		registers[ins.Dst] = lgp.Operators[ins.Op].F(registers[ins.Src[0]], registers[ins.Src[1]])
+	}
+	return registers[0]
+}
+
+// effectiveRegisters returns the calculation registers that have an influence
+// on the output just before the instruction at position i is executed.
+func (g *LGPGenome) effectiveRegisters(i int) map[int]bool {
+	var effective = map[int]bool{0: true}
+	for j := len(g.Program) - 1; j >= i; j-- {
+		g.markEffective(g.Program[j], effective)
+	}
+	return effective
+}
+
+func (g *LGPGenome) markEffective(ins LGPInstruction, effective map[int]bool) bool {
+	if !effective[ins.Dst] {
+		return false
+	}
+	delete(effective, ins.Dst)
+	for k := 0; k < g.LGP.Operators[ins.Op].Arity; k++ {
+		if ins.Src[k] < int(g.LGP.NRegisters) {
+			effective[ins.Src[k]] = true
+		}
+	}
+	return true
+}
+
+// Effective indicates which instructions of the program have an influence on
+// the output register. The remaining instructions are structural introns.
+func (g *LGPGenome) Effective() []bool {
+	var (
+		flags     = make([]bool, len(g.Program))
+		effective = map[int]bool{0: true}
+	)
+	for j := len(g.Program) - 1; j >= 0; j-- {
+		flags[j] = g.markEffective(g.Program[j], effective)
+	}
+	return flags
+}
+
+// randomEffective returns the position of a random effective instruction, or
+// a random position if no instruction is effective.
+func (g *LGPGenome) randomEffective(rng *rand.Rand) int {
+	var idxs []int
+	for i, ok := range g.Effective() {
+		if ok {
+			idxs = append(idxs, i)
+		}
+	}
+	if len(idxs) == 0 {
+		return rng.Intn(len(g.Program))
+	}
+	return idxs[rng.Intn(len(idxs))]
+}
+
+// Evaluate the LGPGenome with the LGP's objective function.
+func (g *LGPGenome) Evaluate() (float64, error) {
+	return g.LGP.F(g)
+}
+
+// Mutate the LGPGenome. With probability MacroRate an effective instruction is
+// either inserted or deleted, otherwise a micro mutation changes the
+// operator, the destination or a source of an effective instruction.
+func (g *LGPGenome) Mutate(rng *rand.Rand) {
+	var lgp = g.LGP
+	if rng.Float64() < lgp.MacroRate {
+		var n = uint(len(g.Program))
+		if n < lgp.MaxLen && (n <= lgp.MinLen || rng.Float64() < 0.5) {
+			// Insert an instruction whose destination is an effective register
+			var (
+				i         = rng.Intn(len(g.Program) + 1)
+				ins       = lgp.randomInstruction(rng)
+				effective []int
+			)
+			for r := range g.effectiveRegisters(i) {
+				effective = append(effective, r)
+			}
+			// Sort the registers so that the outcome only depends on rng
+			sort.Ints(effective)
+			if len(effective) > 0 {
+				ins.Dst = effective[rng.Intn(len(effective))]
+			}
+			g.Program = append(g.Program, LGPInstruction{})
+			copy(g.Program[i+1:], g.Program[i:])
+			g.Program[i] = ins
+		} else if n > lgp.MinLen {
+			var i = g.randomEffective(rng)
+			g.Program = append(g.Program[:i], g.Program[i+1:]...)
+		}
+		return
+	}
+	if len(g.Program) == 0 {
+		return
+	}
+	var ins = &g.Program[g.randomEffective(rng)]
+	switch rng.Intn(3) {
+	case 0:
+		ins.Op = rng.Intn(len(lgp.Operators))
+	case 1:
+		ins.Dst = rng.Intn(int(lgp.NRegisters))
+	default:
+		ins.Src[rng.Intn(2)] = rng.Intn(lgp.nOperands())
+	}
+}
+
+// Crossover applies two-point linear crossover: a segment of each program is
+// swapped with a segment of the other program. The segments may have
+// different lengths but the offsprings' lengths stay within [MinLen, MaxLen].
+func (g *LGPGenome) Crossover(q Genome, rng *rand.Rand) {
+	var (
+		h   = q.(*LGPGenome)
+		lgp = g.LGP
+		n1  = len(g.Program)
+		n2  = len(h.Program)
+	)
+	if n1 == 0 || n2 == 0 {
+		return
+	}
+	// Try a few times to find segments that respect the length bounds
+	for attempt := 0; attempt < 10; attempt++ {
+		var (
+			a1 = rng.Intn(n1)
+			a2 = rng.Intn(n2)
+			l1 = 1 + rng.Intn(n1-a1)
+			l2 = 1 + rng.Intn(n2-a2)
+			m1 = uint(n1 - l1 + l2)
+			m2 = uint(n2 - l2 + l1)
+		)
+		if m1 < lgp.MinLen || m1 > lgp.MaxLen || m2 < lgp.MinLen || m2 > lgp.MaxLen {
+			continue
+		}
+		var o1 = make([]LGPInstruction, 0, m1)
+		o1 = append(o1, g.Program[:a1]...)
+		o1 = append(o1, h.Program[a2:a2+l2]...)
+		o1 = append(o1, g.Program[a1+l1:]...)
+		var o2 = make([]LGPInstruction, 0, m2)
+		o2 = append(o2, h.Program[:a2]...)
+		o2 = append(o2, g.Program[a1:a1+l1]...)
+		o2 = append(o2, h.Program[a2+l2:]...)
+		g.Program, h.Program = o1, o2
+		return
+	}
+}
+
+// Clone returns a deep copy of the LGPGenome.
+func (g LGPGenome) Clone() Genome {
+	var program = make([]LGPInstruction, len(g.Program))
+	copy(program, g.Program)
+	return &LGPGenome{
+		Program: program,
+		LGP:     g.LGP,
+	}
+}
+
+// LGP contains the settings shared by the LGPGenomes of a linear genetic
+// programming run. Its NewGenome method can be handed to GA.Minimize.
+// Reference: Brameier, M. and Banzhaf, W., Linear Genetic Programming, 2007.
+type LGP struct {
+	Operators  []LGPOperator
+	NRegisters uint      // Number of calculation registers, the first one is the output
+	NInputs    uint      // Number of read-only input registers
+	Constants  []float64 // Read-only constant registers
+	MinLen     uint      // Minimum number of instructions
+	MaxLen     uint      // Maximum number of instructions
+	MacroRate  float64   // Probability of applying a macro mutation instead of a micro mutation
+	F          func(genome *LGPGenome) (float64, error)
+}
+
+func (lgp LGP) nOperands() int {
+	return int(lgp.NRegisters+lgp.NInputs) + len(lgp.Constants)
+}
+
+func (lgp LGP) randomInstruction(rng *rand.Rand) LGPInstruction {
+	return LGPInstruction{
+		Op:  rng.Intn(len(lgp.Operators)),
+		Dst: rng.Intn(int(lgp.NRegisters)),
+		Src: [2]int{rng.Intn(lgp.nOperands()), rng.Intn(lgp.nOperands())},
+	}
+}
+
+// NewGenome returns an LGPGenome with a random program whose length is
+// uniformly sampled in [MinLen, MaxLen].
+func (lgp *LGP) NewGenome(rng *rand.Rand) Genome {
+	var program = make([]LGPInstruction, int(lgp.MinLen)+rng.Intn(int(lgp.MaxLen-lgp.MinLen)+1))
+	for i := range program {
+		program[i] = lgp.randomInstruction(rng)
+	}
+	return &LGPGenome{
+		Program: program,
+		LGP:     lgp,
+	}
+}
+
+// Validate LGP fields.
+func (lgp LGP) Validate() error {
+	if len(lgp.Operators) == 0 {
+		return errors.New("at least one operator has to be provided")
+	}
+	for _, op := range lgp.Operators {
+		if op.Arity < 1 || op.Arity > 2 {
+			return errors.New("operators must have an arity of 1 or 2")
+		}
+		if op.F == nil {
+			return errors.New("operators must have a function")
+		}
+	}
+	if lgp.NRegisters == 0 {
+		return errors.New("NRegisters has to be strictly higher than 0")
+	}
+	if lgp.MaxLen == 0 || lgp.MinLen > lgp.MaxLen {
+		return errors.New("MaxLen has to be strictly positive and not lower than MinLen")
+	}
+	if lgp.MacroRate < 0 || lgp.MacroRate > 1 {
+		return errors.New("MacroRate should be between 0 and 1")
+	}
+	if lgp.F == nil {
+		return errors.New("F cannot be nil")
+	}
+	return nil
+}
+
+// LGPSquaredError returns an LGP objective function computing the mean
+// squared error of a program over a set of samples.
+func LGPSquaredError(X [][]float64, Y []float64) func(genome *LGPGenome) (float64, error) {
+	return func(genome *LGPGenome) (float64, error) {
+		var sse float64
+		for i, x := range X {
+			var d = genome.Run(x) - Y[i]
+			sse += d * d
+		}
+		if math.IsNaN(sse) {
+			return math.Inf(1), nil
+		}
+		return sse / float64(len(X)), nil
+	}
+}
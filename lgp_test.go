@@ -0,0 +1,119 @@
+package eaopt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestLGP() *LGP {
+	var (
+		X = [][]float64{{0}, {1}, {2}, {3}, {4}}
+		Y = []float64{1, 3, 5, 7, 9}
+	)
+	return &LGP{
+		Operators:  LGPArithmetic,
+		NRegisters: 2,
+		NInputs:    1,
+		Constants:  []float64{1, 2},
+		MinLen:     1,
+		MaxLen:     20,
+		MacroRate:  0.5,
+		F:          LGPSquaredError(X, Y),
+	}
+}
+
+func TestLGPGenomeRun(t *testing.T) {
+	var lgp = newTestLGP()
+	// r0 = x * 2; r0 = r0 + 1; r1 = r1 - r1 (intron)
+	var g = &LGPGenome{
+		Program: []LGPInstruction{
+			{Op: 2, Dst: 0, Src: [2]int{2, 4}},
+			{Op: 0, Dst: 0, Src: [2]int{0, 3}},
+			{Op: 1, Dst: 1, Src: [2]int{1, 1}},
+		},
+		LGP: lgp,
+	}
+	if out := g.Run([]float64{3}); out != 7 {
+		t.Errorf("Expected 7, got %f", out)
+	}
+	if f, _ := g.Evaluate(); f != 0 {
+		t.Errorf("Expected 0, got %f", f)
+	}
+	var effective = g.Effective()
+	if !effective[0] || !effective[1] || effective[2] {
+		t.Errorf("Expected [true true false], got %v", effective)
+	}
+}
+
+func TestLGPGenomeOperators(t *testing.T) {
+	var (
+		rng = newRand()
+		lgp = newTestLGP()
+	)
+	for i := 0; i < 100; i++ {
+		var (
+			a = lgp.NewGenome(rng).(*LGPGenome)
+			b = lgp.NewGenome(rng).(*LGPGenome)
+		)
+		a.Mutate(rng)
+		a.Crossover(b, rng)
+		for _, g := range []*LGPGenome{a, b} {
+			if n := uint(len(g.Program)); n < lgp.MinLen || n > lgp.MaxLen {
+				t.Fatalf("Program length %d is out of bounds", n)
+			}
+			for _, ins := range g.Program {
+				if ins.Dst < 0 || ins.Dst >= int(lgp.NRegisters) {
+					t.Fatalf("Destination %d is not a calculation register", ins.Dst)
+				}
+			}
+		}
+	}
+}
+
+func TestLGPGenomeClone(t *testing.T) {
+	var (
+		rng   = newRand()
+		lgp   = newTestLGP()
+		a     = lgp.NewGenome(rng).(*LGPGenome)
+		clone = a.Clone().(*LGPGenome)
+	)
+	clone.Program[0].Op = (a.Program[0].Op + 1) % len(lgp.Operators)
+	if clone.Program[0].Op == a.Program[0].Op {
+		t.Error("Clone should not share its program with the original")
+	}
+}
+
+func TestLGPValidate(t *testing.T) {
+	var testCases = []func(lgp *LGP){
+		func(lgp *LGP) { lgp.Operators = nil },
+		func(lgp *LGP) { lgp.Operators = []LGPOperator{{Name: "id", Arity: 0}} },
+		func(lgp *LGP) { lgp.Operators = []LGPOperator{{Name: "id", Arity: 1}} },
+		func(lgp *LGP) { lgp.NRegisters = 0 },
+		func(lgp *LGP) { lgp.MaxLen = 0 },
+		func(lgp *LGP) { lgp.MinLen = 30 },
+		func(lgp *LGP) { lgp.MacroRate = 2 },
+		func(lgp *LGP) { lgp.F = nil },
+	}
+	if err := newTestLGP().Validate(); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var lgp = newTestLGP()
+			tc(lgp)
+			if err := lgp.Validate(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLGPMinimize(t *testing.T) {
+	var (
+		lgp   = newTestLGP()
+		ga, _ = NewDefaultGAConfig().NewGA()
+	)
+	if err := ga.Minimize(lgp.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+}
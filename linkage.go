@@ -0,0 +1,181 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// A Linkage lists groups of gene indexes, the building blocks, that crossover
+// should keep together because the genes interact strongly with each other.
+// Genes that don't belong to any group are independent. A gene can't belong
+// to more than one group.
+type Linkage [][]int
+
+// Mask returns a crossover mask for genomes of nGenes genes where each group,
+// and each gene outside of the groups, is set to true with probability 0.5.
+func (l Linkage) Mask(nGenes int, rng *rand.Rand) []bool {
+	var (
+		mask   = make([]bool, nGenes)
+		linked = make([]bool, nGenes)
+	)
+	for _, group := range l {
+		var swap = rng.Float64() < 0.5
+		for _, g := range group {
+			mask[g] = swap
+			linked[g] = true
+		}
+	}
+	for i := range mask {
+		if !linked[i] {
+			mask[i] = rng.Float64() < 0.5
+		}
+	}
+	return mask
+}
+
+// Validate checks that the Linkage suits genomes of nGenes genes.
+func (l Linkage) Validate(nGenes int) error {
+	var seen = make([]bool, nGenes)
+	for _, group := range l {
+		for _, g := range group {
+			if g < 0 || g >= nGenes {
+				return fmt.Errorf("gene %d is out of range", g)
+			}
+			if seen[g] {
+				return fmt.Errorf("gene %d belongs to more than one group", g)
+			}
+			seen[g] = true
+		}
+	}
+	return nil
+}
+
+// linkageFromDependencies groups genes whose pairwise dependencies are at
+// least threshold. Groups are the connected components of the graph linking
+// the dependent genes; genes that end up alone aren't part of the Linkage.
+func linkageFromDependencies(deps [][]float64, threshold float64) Linkage {
+	var (
+		n      = len(deps)
+		parent = make([]int, n)
+		find   func(i int) int
+	)
+	for i := range parent {
+		parent[i] = i
+	}
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if deps[i][j] >= threshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+	var groups = make(map[int][]int)
+	for i := 0; i < n; i++ {
+		groups[find(i)] = append(groups[find(i)], i)
+	}
+	var linkage Linkage
+	for _, group := range groups {
+		if len(group) > 1 {
+			linkage = append(linkage, group)
+		}
+	}
+	// Map iteration order is random, hence the groups are sorted
+	sort.Slice(linkage, func(i, j int) bool { return linkage[i][0] < linkage[j][0] })
+	return linkage
+}
+
+// LearnLinkage detects dependent genes in a population of discrete genomes,
+// typically its fittest individuals. The dependency between two genes is
+// their mutual information normalized by the lowest of their entropies, it is
+// 1 when either gene determines the other and 0 when they are independent.
+// Genes whose dependencies are at least threshold are grouped together.
+func LearnLinkage(genomes []Slice, threshold float64) (Linkage, error) {
+	if len(genomes) < 2 {
+		return nil, errors.New("at least 2 genomes are needed")
+	}
+	var n = genomes[0].Len()
+	for _, g := range genomes {
+		if g.Len() != n {
+			return nil, errors.New("the genomes should have the same length")
+		}
+	}
+	var (
+		m       = float64(len(genomes))
+		entropy = func(counts map[interface{}]int) (h float64) {
+			for _, c := range counts {
+				var p = float64(c) / m
+				h -= p * math.Log(p)
+			}
+			return
+		}
+		entropies = make([]float64, n)
+		deps      = make([][]float64, n)
+	)
+	for i := 0; i < n; i++ {
+		var counts = make(map[interface{}]int)
+		for _, g := range genomes {
+			counts[g.At(i)]++
+		}
+		entropies[i] = entropy(counts)
+		deps[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			var h = math.Min(entropies[i], entropies[j])
+			if h == 0 {
+				continue
+			}
+			var counts = make(map[interface{}]int)
+			for _, g := range genomes {
+				counts[[2]interface{}{g.At(i), g.At(j)}]++
+			}
+			// I(X; Y) = H(X) + H(Y) - H(X, Y)
+			deps[i][j] = (entropies[i] + entropies[j] - entropy(counts)) / h
+		}
+	}
+	return linkageFromDependencies(deps, threshold), nil
+}
+
+// LearnLinkageFloat64 detects dependent genes in a population of float
+// genomes, typically its fittest individuals. The dependency between two
+// genes is the absolute value of their Pearson correlation. Genes whose
+// dependencies are at least threshold are grouped together.
+func LearnLinkageFloat64(genomes [][]float64, threshold float64) (Linkage, error) {
+	if len(genomes) < 2 {
+		return nil, errors.New("at least 2 genomes are needed")
+	}
+	var (
+		n       = len(genomes[0])
+		columns = make([][]float64, n)
+		deps    = make([][]float64, n)
+	)
+	for i := range columns {
+		columns[i] = make([]float64, len(genomes))
+		for k, g := range genomes {
+			if len(g) != n {
+				return nil, errors.New("the genomes should have the same length")
+			}
+			columns[i][k] = g[i]
+		}
+		deps[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			// Constant genes are considered independent
+			if r, err := pearson(columns[i], columns[j]); err == nil {
+				deps[i][j] = math.Abs(r)
+			}
+		}
+	}
+	return linkageFromDependencies(deps, threshold), nil
+}
@@ -0,0 +1,112 @@
+package eaopt
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLinkageMask(t *testing.T) {
+	var (
+		rng     = newRand()
+		linkage = Linkage{{0, 3, 5}, {1, 2}}
+	)
+	for i := 0; i < 20; i++ {
+		var mask = linkage.Mask(7, rng)
+		if mask[0] != mask[3] || mask[0] != mask[5] || mask[1] != mask[2] {
+			t.Errorf("Linked genes should share the same mask value, got %v", mask)
+		}
+	}
+}
+
+func TestCrossLinkage(t *testing.T) {
+	var (
+		rng     = newRand()
+		linkage = Linkage{{0, 1}, {2, 4}}
+	)
+	for i := 0; i < 20; i++ {
+		var p1, p2 = []int{0, 1, 2, 3, 4, 5}, []int{6, 7, 8, 9, 10, 11}
+		CrossLinkageInt(p1, p2, linkage, rng)
+		// Each building block comes from a single parent
+		if (p1[0] < 6) != (p1[1] < 6) || (p1[2] < 6) != (p1[4] < 6) {
+			t.Errorf("A building block was split: %v", p1)
+		}
+		for j := range p1 {
+			if p1[j]%6 != j || p2[j]%6 != j || p1[j] == p2[j] {
+				t.Errorf("Genes should be swapped between the parents, got %v and %v", p1, p2)
+			}
+		}
+	}
+	var s1, s2 = []string{"a", "b"}, []string{"c", "d"}
+	CrossMask(StringSlice(s1), StringSlice(s2), []bool{false, true})
+	if !reflect.DeepEqual(s1, []string{"a", "d"}) || !reflect.DeepEqual(s2, []string{"c", "b"}) {
+		t.Errorf("Unexpected crossover %v and %v", s1, s2)
+	}
+}
+
+func TestLinkageValidate(t *testing.T) {
+	var testCases = []struct {
+		linkage Linkage
+		err     bool
+	}{
+		{Linkage{{0, 1}, {2}}, false},
+		{nil, false},
+		{Linkage{{0, 3}}, true},
+		{Linkage{{-1, 0}}, true},
+		{Linkage{{0, 1}, {1, 2}}, true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			if err := tc.linkage.Validate(3); (err != nil) != tc.err {
+				t.Errorf("Expected error %t, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestLearnLinkage(t *testing.T) {
+	var (
+		rng     = newRand()
+		genomes = make([]Slice, 200)
+	)
+	// Genes 0 and 2 are equal, gene 3 is the negation of gene 4 and gene 1 is
+	// independent
+	for i := range genomes {
+		var a, b = rng.Intn(2), rng.Intn(2)
+		genomes[i] = IntSlice{a, rng.Intn(2), a, b, 1 - b}
+	}
+	var linkage, err = LearnLinkage(genomes, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(linkage, Linkage{{0, 2}, {3, 4}}) {
+		t.Errorf("Expected [[0 2] [3 4]], got %v", linkage)
+	}
+	if _, err := LearnLinkage(genomes[:1], 0.5); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := LearnLinkage([]Slice{IntSlice{1}, IntSlice{1, 2}}, 0.5); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestLearnLinkageFloat64(t *testing.T) {
+	var (
+		rng     = newRand()
+		genomes = make([][]float64, 100)
+	)
+	for i := range genomes {
+		var x = rng.NormFloat64()
+		genomes[i] = []float64{rng.NormFloat64(), x, 1, -2*x + 0.01*rng.NormFloat64()}
+	}
+	var linkage, err = LearnLinkageFloat64(genomes, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(linkage, Linkage{{1, 3}}) {
+		t.Errorf("Expected [[1 3]], got %v", linkage)
+	}
+	if _, err := LearnLinkageFloat64([][]float64{{1, 2}, {1}}, 0.8); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,496 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// modulePath is used to find the library's version in the build information.
+const modulePath = "github.com/matthewmcneely/eaopt"
+
+// A Manifest records everything needed to reproduce a GA run: the exact
+// configuration including the parameters of the operators, the RNG seed, the
+// version of the library and information about the runtime. It can be
+// marshaled to JSON and published alongside results. Functions, such as the
+// Callback or a Metric, can't be recorded; the fields they were assigned to are
+// listed in Unrecorded and have to be provided again when re-running.
+type Manifest struct {
+	Config     ManifestConfig `json:"config"`
+	RNGSeed    string         `json:"rng_seed,omitempty"`
+	Version    string         `json:"version"`
+	GoVersion  string         `json:"go_version"`
+	GOOS       string         `json:"goos"`
+	GOARCH     string         `json:"goarch"`
+	NumCPU     int            `json:"num_cpu"`
+	GOMAXPROCS int            `json:"gomaxprocs"`
+	Created    time.Time      `json:"created"`
+	Unrecorded []string       `json:"unrecorded,omitempty"`
+}
+
+// ManifestConfig is the serializable part of a GAConfig.
+type ManifestConfig struct {
+	NPops              uint              `json:"n_pops"`
+	PopSize            uint              `json:"pop_size"`
+	NGenerations       uint              `json:"n_generations"`
+	HofSize            uint              `json:"hof_size"`
+	Model              *Operator         `json:"model,omitempty"`
+	Models             []*Operator       `json:"models,omitempty"`
+	ParallelInit       bool              `json:"parallel_init"`
+	ParallelEval       bool              `json:"parallel_eval"`
+	Migrator           *Operator         `json:"migrator,omitempty"`
+	MigFrequency       uint              `json:"mig_frequency,omitempty"`
+	Speciator          *Operator         `json:"speciator,omitempty"`
+	GlobalSpeciation   bool              `json:"global_speciation,omitempty"`
+	HofInjection       *HofInjection     `json:"hof_injection,omitempty"`
+	MaxEvaluations     uint64            `json:"max_evaluations,omitempty"`
+	EvalTimeout        time.Duration     `json:"eval_timeout,omitempty"`
+	AsyncMigration     bool              `json:"async_migration,omitempty"`
+	Novelty            *NoveltyOptions   `json:"novelty,omitempty"`
+	DedupMigrants      bool              `json:"dedup_migrants,omitempty"`
+	SingleThreaded     bool              `json:"single_threaded,omitempty"`
+	ParetoHallOfFame   bool              `json:"pareto_hall_of_fame,omitempty"`
+	Polish             *ManifestPolish   `json:"polish,omitempty"`
+	ReevaluateMigrants bool              `json:"reevaluate_migrants,omitempty"`
+	RealTime           *RealTimeOptions  `json:"real_time,omitempty"`
+	FrontStop          *FrontStopOptions `json:"front_stop,omitempty"`
+}
+
+// ManifestPolish is the serializable representation of PolishOptions.
+type ManifestPolish struct {
+	Polisher *Operator `json:"polisher,omitempty"`
+	Budget   uint      `json:"budget"`
+	NMembers uint      `json:"n_members,omitempty"`
+}
+
+// An Operator is the serializable representation of a Model, Selector,
+// Migrator, Speciator, Schedule or Polisher. Type is the name of the operator's type as
+// registered with RegisterOperator, Params contains the JSON encoding of each
+// of its exported fields. Fields holding an operator are themselves encoded as
+// an Operator.
+type Operator struct {
+	Type   string                     `json:"type"`
+	Params map[string]json.RawMessage `json:"params,omitempty"`
+}
+
+var (
+	operatorsMutex sync.RWMutex
+	operatorTypes  = make(map[string]reflect.Type)
+)
+
+func init() {
+	for _, op := range []interface{}{
+		ModGenerational{}, ModSteadyState{}, ModDownToSize{}, ModRing{},
+		ModMutationOnly{}, ModSimulatedAnnealing{}, ModInteractive{}, ModContiguous{}, ModTempering{},
+		ModCultural{},
+		SelElitism{}, SelTournament{}, SelCostTournament{}, SelParsimonyTournament{}, SelRoulette{},
+		SelBoltzmann{},
+		MigRing{}, MigSpecies{}, MigTempering{},
+		MateAssortative{}, MateSpecies{},
+		SpecKMedoids{}, SpecFitnessInterval{}, SpecDistance{},
+		SchedLinear{}, SchedCosine{}, SchedStep{},
+		PolishNelderMead{}, PolishPatternSearch{}, Polish2Opt{},
+	} {
+		RegisterOperator(op)
+	}
+}
+
+// RegisterOperator makes the type of op known to Manifest.NewGA, which is
+// required to rebuild custom operators from a Manifest. The operators provided
+// by eaopt are registered by default.
+func RegisterOperator(op interface{}) {
+	var t = reflect.TypeOf(op)
+	operatorsMutex.Lock()
+	operatorTypes[t.String()] = t
+	operatorsMutex.Unlock()
+}
+
+// gaPointerType is skipped when encoding operators, ModSimulatedAnnealing's GA
+// field is set by NewGA.
+var gaPointerType = reflect.TypeOf(&GA{})
+
+// encodeOperator encodes op. The paths of the fields which can't be recorded
+// are added to unrecorded.
+func encodeOperator(op interface{}, path string, unrecorded *[]string) (*Operator, error) {
+	if op == nil {
+		return nil, nil
+	}
+	var (
+		v       = reflect.ValueOf(op)
+		encoded = &Operator{Type: v.Type().String()}
+	)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: operators of kind %s are not supported", path, v.Kind())
+	}
+	for i := 0; i < v.NumField(); i++ {
+		var (
+			field = v.Type().Field(i)
+			fv    = v.Field(i)
+			fpath = path + "." + field.Name
+		)
+		if field.PkgPath != "" || field.Type == gaPointerType {
+			continue
+		}
+		var (
+			raw []byte
+			err error
+		)
+		switch field.Type.Kind() {
+		case reflect.Func, reflect.Chan:
+			if !fv.IsNil() {
+				*unrecorded = append(*unrecorded, fpath)
+			}
+			continue
+		case reflect.Interface:
+			if fv.IsNil() {
+				continue
+			}
+			if fv.Elem().Kind() == reflect.Func {
+				// For instance a SchedFunc
+				*unrecorded = append(*unrecorded, fpath)
+				continue
+			}
+			var sub *Operator
+			if sub, err = encodeOperator(fv.Interface(), fpath, unrecorded); err != nil {
+				return nil, err
+			}
+			raw, err = json.Marshal(sub)
+		default:
+			raw, err = json.Marshal(fv.Interface())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fpath, err)
+		}
+		if encoded.Params == nil {
+			encoded.Params = make(map[string]json.RawMessage)
+		}
+		encoded.Params[field.Name] = raw
+	}
+	return encoded, nil
+}
+
+// decode rebuilds an operator from its representation.
+func (op *Operator) decode() (interface{}, error) {
+	operatorsMutex.RLock()
+	var t, ok = operatorTypes[op.Type]
+	operatorsMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown operator type %s, it has to be registered with RegisterOperator", op.Type)
+	}
+	var (
+		ptr = reflect.New(t)
+		v   = ptr.Elem()
+	)
+	if t.Kind() == reflect.Ptr {
+		v.Set(reflect.New(t.Elem()))
+		v = v.Elem()
+	}
+	for name, raw := range op.Params {
+		var fv = v.FieldByName(name)
+		if !fv.IsValid() || !fv.CanSet() {
+			return nil, fmt.Errorf("%s has no field %s", op.Type, name)
+		}
+		if fv.Kind() == reflect.Interface {
+			var sub Operator
+			if err := json.Unmarshal(raw, &sub); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", op.Type, name, err)
+			}
+			var decoded, err = sub.decode()
+			if err != nil {
+				return nil, err
+			}
+			var dv = reflect.ValueOf(decoded)
+			if !dv.Type().AssignableTo(fv.Type()) {
+				return nil, fmt.Errorf("%s.%s: %s is not a %s", op.Type, name, sub.Type, fv.Type())
+			}
+			fv.Set(dv)
+			continue
+		}
+		if err := json.Unmarshal(raw, fv.Addr().Interface()); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", op.Type, name, err)
+		}
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// MarshalOperator returns the JSON encoding of op, a Model, Selector,
+// Migrator, Speciator, MatingRestriction or Schedule, as an Operator. Unlike
+// Manifest, which lists the fields it can't record, MarshalOperator returns an
+// error if one of op's fields holds a function, because the encoding wouldn't
+// rebuild the same operator.
+func MarshalOperator(op interface{}) ([]byte, error) {
+	if op == nil {
+		return nil, errors.New("can't marshal a nil operator")
+	}
+	var (
+		name       = reflect.TypeOf(op).String()
+		unrecorded []string
+	)
+	var encoded, err = encodeOperator(op, name, &unrecorded)
+	if err != nil {
+		return nil, err
+	}
+	if len(unrecorded) > 0 {
+		return nil, fmt.Errorf("%s can't be encoded", strings.Join(unrecorded, ", "))
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalOperator rebuilds an operator from its JSON encoding as an
+// Operator, for instance one returned by MarshalOperator or written in a
+// configuration file:
+//
+//	{"type": "eaopt.SelTournament", "params": {"NContestants": 3}}
+//
+// The operator's type has to be registered with RegisterOperator and to
+// implement T, which is usually one of Model, Selector, Migrator, Speciator,
+// MatingRestriction and Schedule. The operator is validated with its Validate
+// method, hence an invalid configuration is rejected when it is loaded rather
+// than when the GA is instantiated.
+func UnmarshalOperator[T any](data []byte) (T, error) {
+	var (
+		zero    T
+		encoded Operator
+	)
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return zero, err
+	}
+	var decoded, err = encoded.decode()
+	if err != nil {
+		return zero, err
+	}
+	var op, ok = decoded.(T)
+	if !ok {
+		return zero, fmt.Errorf("%s is not a %s", encoded.Type, reflect.TypeOf(&zero).Elem())
+	}
+	if v, ok := decoded.(interface{ Validate() error }); ok {
+		if err = v.Validate(); err != nil {
+			return zero, fmt.Errorf("%s: %w", encoded.Type, err)
+		}
+	}
+	return op, nil
+}
+
+// libraryVersion returns the version of eaopt the program was built with.
+func libraryVersion() string {
+	var info, ok = debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+// Manifest returns the GA's Manifest. An error is returned if one of the
+// operators can't be encoded.
+func (ga *GA) Manifest() (Manifest, error) {
+	var m = Manifest{
+		Config: ManifestConfig{
+			NPops:              ga.NPops,
+			PopSize:            ga.PopSize,
+			NGenerations:       ga.NGenerations,
+			HofSize:            ga.HofSize,
+			ParallelInit:       ga.ParallelInit,
+			ParallelEval:       ga.ParallelEval,
+			MigFrequency:       ga.MigFrequency,
+			GlobalSpeciation:   ga.GlobalSpeciation,
+			HofInjection:       ga.HofInjection,
+			MaxEvaluations:     ga.MaxEvaluations,
+			EvalTimeout:        ga.EvalTimeout,
+			AsyncMigration:     ga.AsyncMigration,
+			Novelty:            ga.Novelty,
+			DedupMigrants:      ga.DedupMigrants,
+			SingleThreaded:     ga.SingleThreaded,
+			ParetoHallOfFame:   ga.ParetoHallOfFame,
+			ReevaluateMigrants: ga.ReevaluateMigrants,
+			RealTime:           ga.RealTime,
+			FrontStop:          ga.FrontStop,
+		},
+		RNGSeed:    ga.RNGSeed,
+		Version:    libraryVersion(),
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Created:    time.Now(),
+	}
+	var err error
+	if m.Config.Model, err = encodeOperator(ga.Model, "Model", &m.Unrecorded); err != nil {
+		return m, err
+	}
+	for i, model := range ga.Models {
+		var op *Operator
+		if op, err = encodeOperator(model, fmt.Sprintf("Models[%d]", i), &m.Unrecorded); err != nil {
+			return m, err
+		}
+		m.Config.Models = append(m.Config.Models, op)
+	}
+	if ga.Migrator != nil {
+		if m.Config.Migrator, err = encodeOperator(ga.Migrator, "Migrator", &m.Unrecorded); err != nil {
+			return m, err
+		}
+	}
+	if ga.Speciator != nil {
+		if m.Config.Speciator, err = encodeOperator(ga.Speciator, "Speciator", &m.Unrecorded); err != nil {
+			return m, err
+		}
+	}
+	if ga.Polish != nil {
+		m.Config.Polish = &ManifestPolish{Budget: ga.Polish.Budget, NMembers: ga.Polish.NMembers}
+		if m.Config.Polish.Polisher, err = encodeOperator(ga.Polish.Polisher, "Polish.Polisher", &m.Unrecorded); err != nil {
+			return m, err
+		}
+	}
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"Logger", ga.Logger != nil},
+		{"SLogger", ga.SLogger != nil},
+		{"Callback", ga.Callback != nil},
+		{"EarlyStop", ga.EarlyStop != nil},
+		{"ConstraintHandler", ga.ConstraintHandler != nil},
+		{"GenomeJSONUnmarshaler", ga.GenomeJSONUnmarshaler != nil},
+		{"PopulationIDFunc", ga.PopulationIDFunc != nil},
+		{"IDGenerator", ga.IDGenerator != nil},
+		{"RNG", ga.RNGSeed == ""},
+	} {
+		if f.set {
+			m.Unrecorded = append(m.Unrecorded, f.name)
+		}
+	}
+	return m, nil
+}
+
+// GAConfig rebuilds the GAConfig recorded in the Manifest. The RNG is seeded
+// with the recorded seed if there is one. The fields listed in Unrecorded are
+// left empty.
+func (m Manifest) GAConfig() (GAConfig, error) {
+	var conf = GAConfig{
+		NPops:              m.Config.NPops,
+		PopSize:            m.Config.PopSize,
+		NGenerations:       m.Config.NGenerations,
+		HofSize:            m.Config.HofSize,
+		ParallelInit:       m.Config.ParallelInit,
+		ParallelEval:       m.Config.ParallelEval,
+		MigFrequency:       m.Config.MigFrequency,
+		GlobalSpeciation:   m.Config.GlobalSpeciation,
+		HofInjection:       m.Config.HofInjection,
+		MaxEvaluations:     m.Config.MaxEvaluations,
+		EvalTimeout:        m.Config.EvalTimeout,
+		AsyncMigration:     m.Config.AsyncMigration,
+		Novelty:            m.Config.Novelty,
+		DedupMigrants:      m.Config.DedupMigrants,
+		SingleThreaded:     m.Config.SingleThreaded,
+		ParetoHallOfFame:   m.Config.ParetoHallOfFame,
+		ReevaluateMigrants: m.Config.ReevaluateMigrants,
+		RealTime:           m.Config.RealTime,
+		FrontStop:          m.Config.FrontStop,
+	}
+	if m.RNGSeed != "" {
+		var seed, err = strconv.ParseInt(m.RNGSeed, 10, 64)
+		if err != nil {
+			return conf, fmt.Errorf("parsing seed from manifest: %w", err)
+		}
+		conf.RNG = rand.New(rand.NewSource(seed))
+	}
+	if m.Config.Model == nil && len(m.Config.Models) == 0 {
+		return conf, errors.New("manifest doesn't contain a model")
+	}
+	var (
+		op  interface{}
+		ok  bool
+		err error
+	)
+	if m.Config.Model != nil {
+		if op, err = m.Config.Model.decode(); err != nil {
+			return conf, err
+		}
+		if conf.Model, ok = op.(Model); !ok {
+			return conf, fmt.Errorf("%s is not a Model", m.Config.Model.Type)
+		}
+	}
+	for _, encoded := range m.Config.Models {
+		if encoded == nil {
+			return conf, errors.New("manifest contains a null model")
+		}
+		if op, err = encoded.decode(); err != nil {
+			return conf, err
+		}
+		var model Model
+		if model, ok = op.(Model); !ok {
+			return conf, fmt.Errorf("%s is not a Model", encoded.Type)
+		}
+		conf.Models = append(conf.Models, model)
+	}
+	if m.Config.Migrator != nil {
+		if op, err = m.Config.Migrator.decode(); err != nil {
+			return conf, err
+		}
+		if conf.Migrator, ok = op.(Migrator); !ok {
+			return conf, fmt.Errorf("%s is not a Migrator", m.Config.Migrator.Type)
+		}
+	}
+	if m.Config.Speciator != nil {
+		if op, err = m.Config.Speciator.decode(); err != nil {
+			return conf, err
+		}
+		if conf.Speciator, ok = op.(Speciator); !ok {
+			return conf, fmt.Errorf("%s is not a Speciator", m.Config.Speciator.Type)
+		}
+	}
+	if m.Config.Polish != nil {
+		conf.Polish = &PolishOptions{Budget: m.Config.Polish.Budget, NMembers: m.Config.Polish.NMembers}
+		if m.Config.Polish.Polisher != nil {
+			if op, err = m.Config.Polish.Polisher.decode(); err != nil {
+				return conf, err
+			}
+			if conf.Polish.Polisher, ok = op.(Polisher); !ok {
+				return conf, fmt.Errorf("%s is not a Polisher", m.Config.Polish.Polisher.Type)
+			}
+		}
+	}
+	return conf, nil
+}
+
+// NewGA rebuilds the GA described by the Manifest. patch is called with the
+// GAConfig before the GA is instantiated, it can be used to provide the fields
+// listed in Unrecorded; it may be nil. The GA keeps the recorded seed so that
+// calling Init doesn't generate a new one.
+func (m Manifest) NewGA(patch func(conf *GAConfig)) (*GA, error) {
+	var conf, err = m.GAConfig()
+	if err != nil {
+		return nil, err
+	}
+	if patch != nil {
+		patch(&conf)
+	}
+	ga, err := conf.NewGA()
+	if err != nil {
+		return nil, err
+	}
+	ga.RNGSeed = m.RNGSeed
+	return ga, nil
+}
@@ -0,0 +1,391 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.Model = ModDownToSize{
+		NOffsprings: 5,
+		SelectorA:   SelTournament{NContestants: 3},
+		SelectorB:   SelElitism{},
+		MutRate:     0.5,
+		CrossRate:   0.7,
+		MutSchedule: SchedCosine{Start: 0.9, End: 0.1, NGenerations: 20},
+	}
+	conf.Migrator = MigRing{NMigrants: 2}
+	conf.MigFrequency = 3
+	conf.Speciator = SpecKMedoids{K: 2, MinPerCluster: 1, Metric: l1Distance, MaxIterations: 10}
+	conf.Callback = func(ga *GA) {}
+	conf.MaxEvaluations = 500
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga.RNGSeed = "42"
+	m, err := ga.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GoVersion != runtime.Version() || m.NumCPU != runtime.NumCPU() || m.Version == "" {
+		t.Errorf("Wrong runtime information: %+v", m)
+	}
+	var unrecorded = []string{"Speciator.Metric", "Callback"}
+	if !reflect.DeepEqual(m.Unrecorded, unrecorded) {
+		t.Errorf("Expected %v, got %v", unrecorded, m.Unrecorded)
+	}
+	// Go through JSON
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Manifest
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	ga2, err := decoded.NewGA(func(conf *GAConfig) {
+		var spec = conf.Speciator.(SpecKMedoids)
+		spec.Metric = l1Distance
+		conf.Speciator = spec
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ga2.Model, ga.Model) || !reflect.DeepEqual(ga2.Migrator, ga.Migrator) {
+		t.Errorf("Expected %v and %v, got %v and %v", ga.Model, ga.Migrator, ga2.Model, ga2.Migrator)
+	}
+	if ga2.Speciator.(SpecKMedoids).K != 2 || ga2.MigFrequency != 3 || ga2.NPops != 2 || ga2.MaxEvaluations != 500 {
+		t.Errorf("Wrong config: %+v", ga2.GAConfig)
+	}
+	if ga2.RNGSeed != "42" {
+		t.Errorf("Expected seed 42, got %s", ga2.RNGSeed)
+	}
+}
+
+func TestManifestConfigFields(t *testing.T) {
+	var testCases = []struct {
+		set func(conf *GAConfig)
+		get func(conf GAConfig) interface{}
+	}{
+		{
+			func(conf *GAConfig) {
+				conf.NPops = 2
+				conf.Migrator = MigRing{NMigrants: 1}
+				conf.AsyncMigration = true
+			},
+			func(conf GAConfig) interface{} { return conf.AsyncMigration },
+		},
+		{
+			func(conf *GAConfig) {
+				conf.Novelty = &NoveltyOptions{Mode: NoveltyWeighted, K: 3, ArchiveProb: 0.1, ArchiveSize: 10, Weight: 0.5}
+			},
+			func(conf GAConfig) interface{} { return conf.Novelty },
+		},
+		{
+			func(conf *GAConfig) { conf.NPops = 2; conf.Migrator = MigRing{NMigrants: 1}; conf.DedupMigrants = true },
+			func(conf GAConfig) interface{} { return conf.DedupMigrants },
+		},
+		{
+			func(conf *GAConfig) { conf.SingleThreaded = true },
+			func(conf GAConfig) interface{} { return conf.SingleThreaded },
+		},
+		{
+			func(conf *GAConfig) { conf.ParetoHallOfFame = true },
+			func(conf GAConfig) interface{} { return conf.ParetoHallOfFame },
+		},
+		{
+			func(conf *GAConfig) {
+				conf.Polish = &PolishOptions{Polisher: PolishPatternSearch{Step: 0.2, Shrink: 0.4}, Budget: 50, NMembers: 1}
+			},
+			func(conf GAConfig) interface{} { return conf.Polish },
+		},
+		{
+			func(conf *GAConfig) { conf.Polish = &PolishOptions{Budget: 50} },
+			func(conf GAConfig) interface{} { return conf.Polish },
+		},
+		{
+			func(conf *GAConfig) {
+				conf.NPops = 2
+				conf.Migrator = MigRing{NMigrants: 1}
+				conf.ReevaluateMigrants = true
+			},
+			func(conf GAConfig) interface{} { return conf.ReevaluateMigrants },
+		},
+		{
+			func(conf *GAConfig) { conf.RealTime = &RealTimeOptions{Budget: time.Second, Pending: InheritFitness} },
+			func(conf GAConfig) interface{} { return conf.RealTime },
+		},
+		{
+			func(conf *GAConfig) {
+				conf.ParetoHallOfFame = true
+				conf.FrontStop = &FrontStopOptions{Window: 5, Epsilon: 0.01, MaxChurn: 0.2, Reference: []float64{10, 10}}
+			},
+			func(conf GAConfig) interface{} { return conf.FrontStop },
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var conf = NewDefaultGAConfig()
+			tc.set(&conf)
+			var ga, err = conf.NewGA()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := ga.Manifest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Manifest
+			if err = json.Unmarshal(b, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			ga2, err := decoded.NewGA(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := tc.get(ga2.GAConfig), tc.get(conf); !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestManifestModels(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.Models = []Model{
+		ModMutationOnly{Strict: true},
+		ModSteadyState{Selector: SelElitism{}, KeepBest: true, MutRate: 0.3, CrossRate: 0.2},
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ga.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(m)
+	var decoded Manifest
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	ga2, err := decoded.NewGA(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ga2.Models, ga.Models) {
+		t.Errorf("Expected %v, got %v", ga.Models, ga2.Models)
+	}
+	decoded.Config.Models = []*Operator{nil, nil}
+	if _, err = decoded.NewGA(nil); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestManifestReproducible(t *testing.T) {
+	var ga, _ = NewDefaultGAConfig().NewGA()
+	ga.NGenerations = 10
+	if err := ga.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	if err := ga.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var m, err = ga.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Unrecorded) != 0 {
+		t.Errorf("Expected nothing to be unrecorded, got %v", m.Unrecorded)
+	}
+	ga2, err := m.NewGA(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga2.Init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	if err = ga2.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if ga2.HallOfFame[0].Fitness != ga.HallOfFame[0].Fitness {
+		t.Errorf("Expected %f, got %f", ga.HallOfFame[0].Fitness, ga2.HallOfFame[0].Fitness)
+	}
+}
+
+// manifestModel is a custom Model with a nested Selector and a pointer
+// receiver.
+type manifestModel struct {
+	Selector Selector
+	Rate     float64
+	private  int
+}
+
+func (mod *manifestModel) Apply(pop *Population) error { return nil }
+func (mod *manifestModel) Validate() error             { return nil }
+
+// restoreOperatorTypes restores the registered operator types once the test
+// is over, hence operators registered by the test don't leak into other tests.
+func restoreOperatorTypes(t *testing.T) {
+	operatorsMutex.Lock()
+	var saved = make(map[string]reflect.Type, len(operatorTypes))
+	for name, typ := range operatorTypes {
+		saved[name] = typ
+	}
+	operatorsMutex.Unlock()
+	t.Cleanup(func() {
+		operatorsMutex.Lock()
+		operatorTypes = saved
+		operatorsMutex.Unlock()
+	})
+}
+
+func TestManifestCustomOperator(t *testing.T) {
+	restoreOperatorTypes(t)
+	var conf = NewDefaultGAConfig()
+	conf.Model = &manifestModel{Selector: SelRoulette{}, Rate: 0.3, private: 1}
+	conf.RNG = rand.New(rand.NewSource(1))
+	var ga, _ = conf.NewGA()
+	var m, err = ga.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Unrecorded, []string{"RNG"}) {
+		t.Errorf("Expected the RNG to be unrecorded, got %v", m.Unrecorded)
+	}
+	if _, err = m.GAConfig(); err == nil {
+		t.Error("Expected an error for an unregistered operator")
+	}
+	RegisterOperator(&manifestModel{})
+	conf, err = m.GAConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mod = conf.Model.(*manifestModel)
+	if mod.Rate != 0.3 || mod.Selector != (SelRoulette{}) || mod.private != 0 {
+		t.Errorf("Wrong model: %+v", mod)
+	}
+}
+
+func TestManifestErrors(t *testing.T) {
+	var testCases = []Manifest{
+		{},
+		{Config: ManifestConfig{Model: &Operator{Type: "eaopt.SelRoulette"}}},
+		{Config: ManifestConfig{Model: &Operator{Type: "eaopt.ModRing", Params: map[string]json.RawMessage{
+			"Nope": json.RawMessage("1"),
+		}}}},
+		{Config: ManifestConfig{Model: &Operator{Type: "eaopt.ModRing", Params: map[string]json.RawMessage{
+			"MutRate": json.RawMessage(`"a"`),
+		}}}},
+		{Config: ManifestConfig{Model: &Operator{Type: "eaopt.ModRing", Params: map[string]json.RawMessage{
+			"Selector": json.RawMessage(`{"type": "eaopt.MigRing"}`),
+		}}}},
+		{Config: ManifestConfig{Model: &Operator{Type: "eaopt.ModMutationOnly"}}, RNGSeed: "abc"},
+	}
+	for i, m := range testCases {
+		if _, err := m.GAConfig(); err == nil {
+			t.Errorf("Expected an error in test case number %d", i)
+		}
+	}
+}
+
+func TestOperatorRoundTrip(t *testing.T) {
+	var testCases = []interface{}{
+		ModGenerational{
+			Selector:    SelTournament{NContestants: 3},
+			MutRate:     0.5,
+			CrossRate:   0.7,
+			MutSchedule: SchedLinear{Start: 0.9, End: 0.1, NGenerations: 10},
+			NParents:    3,
+		},
+		ModSteadyState{Selector: SelRoulette{}, KeepBest: true, MutRate: 0.2, CrossRate: 0.3},
+		ModDownToSize{
+			NOffsprings:   5,
+			SelectorA:     SelCostTournament{NContestants: 2, CostWeight: 0.5},
+			SelectorB:     SelElitism{},
+			MutRate:       0.5,
+			CrossRate:     0.7,
+			CrossSchedule: SchedStep{Start: 1, Factor: 0.5, Every: 10},
+		},
+		ModRing{Selector: SelBoltzmann{Temperature: 2}, MutRate: 0.4},
+		ModMutationOnly{Strict: true},
+		ModSimulatedAnnealing{T0: 10, Cooling: 0.9},
+		ModContiguous{Model: ModMutationOnly{}},
+		ModTempering{Temperature: 1, MutRate: 0.5, CrossRate: 0.5, NMutations: 2},
+		ModCultural{Model: ModRing{Selector: SelElitism{}, MutRate: 0.1}, AcceptRate: 0.2, Influence: 0.5, Sigma: 0.1},
+		SelParsimonyTournament{NContestants: 4, Coefficient: 0.01},
+		MigRing{NMigrants: 2, Frequency: 3},
+		MigSpecies{NMigrants: 1},
+		MigTempering{Temperatures: []float64{1, 2, 4}, NSwaps: 1},
+		SpecFitnessInterval{K: 3},
+		SchedCosine{Start: 1, End: 0, NGenerations: 5},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var b, err = MarshalOperator(tc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			op, err := UnmarshalOperator[interface{}](b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(op, tc) {
+				t.Errorf("Expected %+v, got %+v", tc, op)
+			}
+		})
+	}
+}
+
+func TestUnmarshalOperator(t *testing.T) {
+	var sel, err = UnmarshalOperator[Selector]([]byte(`{"type": "eaopt.SelTournament", "params": {"NContestants": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel != (SelTournament{NContestants: 3}) {
+		t.Errorf("Expected a tournament of 3 contestants, got %+v", sel)
+	}
+	model, err := UnmarshalOperator[Model]([]byte(`{"type": "eaopt.ModGenerational", "params": {
+		"Selector": {"type": "eaopt.SelElitism"}, "MutRate": 0.5, "CrossRate": 0.5}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod, ok := model.(ModGenerational); !ok || mod.Selector != (SelElitism{}) || mod.MutRate != 0.5 {
+		t.Errorf("Wrong model: %+v", model)
+	}
+	// Errors
+	for i, data := range []string{
+		`nope`,
+		`{"type": "eaopt.Nope"}`,
+		`{"type": "eaopt.ModRing", "params": {"MutRate": 0.5, "Selector": {"type": "eaopt.SelElitism"}}}`,
+		`{"type": "eaopt.SelTournament", "params": {"NContestants": 0}}`,
+		`{"type": "eaopt.SelTournament", "params": {"Nope": 1}}`,
+		`{"type": "eaopt.SelTournament", "params": {"NContestants": -1}}`,
+	} {
+		if _, err = UnmarshalOperator[Selector]([]byte(data)); err == nil {
+			t.Errorf("Expected an error in test case number %d", i)
+		}
+	}
+	if _, err = MarshalOperator(nil); err == nil {
+		t.Error("Expected an error for a nil operator")
+	}
+	if _, err = MarshalOperator(SpecKMedoids{K: 2, Metric: l1Distance}); err == nil {
+		t.Error("Expected an error for an operator holding a function")
+	}
+	if _, err = MarshalOperator(SchedFunc(func(uint) float64 { return 1 })); err == nil {
+		t.Error("Expected an error for a function")
+	}
+}
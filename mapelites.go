@@ -0,0 +1,562 @@
+package eaopt
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// A Tessellation divides the behavior space of MAP-Elites into cells, each
+// cell holding at most one elite. Cell returns the index of the cell
+// containing a behavior descriptor of Dims values and Center returns the
+// center of a cell.
+type Tessellation interface {
+	NCells() int
+	Dims() int
+	Cell(descriptor []float64) int
+	Center(cell int) []float64
+}
+
+// A GridTessellation divides the box between Lower and Upper into a regular
+// grid with Bins[i] cells along the i-th dimension. Descriptors outside the
+// box belong to the closest border cell. Cells are numbered in row-major
+// order, the last dimension varying the fastest.
+type GridTessellation struct {
+	Lower, Upper []float64
+	Bins         []uint
+}
+
+// Validate GridTessellation fields.
+func (gt GridTessellation) Validate() error {
+	if len(gt.Bins) == 0 {
+		return errors.New("at least one dimension has to be provided")
+	}
+	if len(gt.Lower) != len(gt.Bins) || len(gt.Upper) != len(gt.Bins) {
+		return errors.New("Lower, Upper and Bins should have the same length")
+	}
+	for i, b := range gt.Bins {
+		if b == 0 {
+			return errors.New("Bins should be positive")
+		}
+		if gt.Lower[i] >= gt.Upper[i] {
+			return errors.New("Lower should be lower than Upper")
+		}
+	}
+	return nil
+}
+
+// NCells returns the number of cells of the grid.
+func (gt GridTessellation) NCells() int {
+	var n = 1
+	for _, b := range gt.Bins {
+		n *= int(b)
+	}
+	return n
+}
+
+// Dims returns the number of dimensions of the grid.
+func (gt GridTessellation) Dims() int {
+	return len(gt.Bins)
+}
+
+// Cell returns the index of the cell containing descriptor.
+func (gt GridTessellation) Cell(descriptor []float64) int {
+	var cell int
+	for i, b := range gt.Bins {
+		var k = int(math.Floor((descriptor[i] - gt.Lower[i]) / (gt.Upper[i] - gt.Lower[i]) * float64(b)))
+		if k < 0 {
+			k = 0
+		} else if k >= int(b) {
+			k = int(b) - 1
+		}
+		cell = cell*int(b) + k
+	}
+	return cell
+}
+
+// Center returns the center of a cell.
+func (gt GridTessellation) Center(cell int) []float64 {
+	var center = make([]float64, len(gt.Bins))
+	for i := len(gt.Bins) - 1; i >= 0; i-- {
+		var (
+			b     = int(gt.Bins[i])
+			width = (gt.Upper[i] - gt.Lower[i]) / float64(b)
+		)
+		center[i] = gt.Lower[i] + (float64(cell%b)+0.5)*width
+		cell /= b
+	}
+	return center
+}
+
+// A CVTTessellation divides the behavior space into the Voronoi cells of
+// Centroids: each descriptor belongs to the cell of its closest centroid.
+// Unlike grids, centroidal Voronoi tessellations keep the number of cells
+// manageable in high dimensional behavior spaces. The Centroids can be saved
+// along with a MAPArchive to restore the same tessellation later on.
+// Reference: https://arxiv.org/abs/1610.05729
+type CVTTessellation struct {
+	Centroids [][]float64
+}
+
+// NewCVTTessellation computes nCells centroids covering the box between lower
+// and upper by running Lloyd's algorithm on nSamples points sampled uniformly
+// in the box. nSamples is usually much larger than nCells.
+func NewCVTTessellation(nCells uint, lower, upper []float64, nSamples uint, rng *rand.Rand) (CVTTessellation, error) {
+	if nCells == 0 || nSamples < nCells {
+		return CVTTessellation{}, errors.New("nSamples should be at least nCells, which should be positive")
+	}
+	if len(lower) == 0 || len(lower) != len(upper) {
+		return CVTTessellation{}, errors.New("lower and upper should have the same positive length")
+	}
+	var (
+		samples   = InitUnifPoints(nSamples, lower, upper, rng)
+		centroids = make([][]float64, nCells)
+		assigned  = make([]int, nSamples)
+	)
+	for i := range centroids {
+		centroids[i] = copyFloat64s(samples[i])
+	}
+	for iter := 0; iter < 100; iter++ {
+		var moved bool
+		for i, s := range samples {
+			var c = closestVector(s, centroids)
+			if iter == 0 || c != assigned[i] {
+				moved = true
+			}
+			assigned[i] = c
+		}
+		if !moved {
+			break
+		}
+		var (
+			sums   = make([][]float64, nCells)
+			counts = make([]int, nCells)
+		)
+		for i := range sums {
+			sums[i] = make([]float64, len(lower))
+		}
+		for i, s := range samples {
+			counts[assigned[i]]++
+			for j, x := range s {
+				sums[assigned[i]][j] += x
+			}
+		}
+		// Centroids without samples stay where they are
+		for i := range centroids {
+			if counts[i] == 0 {
+				continue
+			}
+			for j := range centroids[i] {
+				centroids[i][j] = sums[i][j] / float64(counts[i])
+			}
+		}
+	}
+	return CVTTessellation{Centroids: centroids}, nil
+}
+
+// NCells returns the number of centroids.
+func (cvt CVTTessellation) NCells() int {
+	return len(cvt.Centroids)
+}
+
+// Dims returns the number of dimensions of the centroids.
+func (cvt CVTTessellation) Dims() int {
+	if len(cvt.Centroids) == 0 {
+		return 0
+	}
+	return len(cvt.Centroids[0])
+}
+
+// Cell returns the index of the centroid closest to descriptor.
+func (cvt CVTTessellation) Cell(descriptor []float64) int {
+	return closestVector(descriptor, cvt.Centroids)
+}
+
+// Center returns the centroid of a cell.
+func (cvt CVTTessellation) Center(cell int) []float64 {
+	return copyFloat64s(cvt.Centroids[cell])
+}
+
+// A MAPArchive holds the elite of each cell of a Tessellation, which is the
+// best Individual whose behavior descriptor falls into the cell according to
+// Individual.Better. The Tessellation isn't encoded in JSON, hence it has to
+// be set before decoding an archive, along with JSONUnmarshaler which decodes
+// the Genomes.
+type MAPArchive struct {
+	Tessellation    Tessellation                 `json:"-"`
+	JSONUnmarshaler func([]byte) (Genome, error) `json:"-"`
+
+	elites      []Individual // Empty cells have a nil Genome
+	descriptors [][]float64
+	filled      []int // Indexes of the cells that have an elite, in order of discovery
+}
+
+// NewMAPArchive returns an empty MAPArchive over a Tessellation.
+func NewMAPArchive(tess Tessellation) *MAPArchive {
+	return &MAPArchive{
+		Tessellation: tess,
+		elites:       make([]Individual, tess.NCells()),
+		descriptors:  make([][]float64, tess.NCells()),
+	}
+}
+
+// Add inserts an evaluated Individual into the cell of its descriptor if the
+// cell is empty or if the Individual is better than the cell's elite. It
+// returns true if the Individual was inserted, in which case it belongs to the
+// archive.
+func (a *MAPArchive) Add(indi Individual, descriptor []float64) bool {
+	var cell = a.Tessellation.Cell(descriptor)
+	if a.elites[cell].Genome != nil && !indi.Better(a.elites[cell]) {
+		return false
+	}
+	if a.elites[cell].Genome == nil {
+		a.filled = append(a.filled, cell)
+	}
+	a.elites[cell] = indi
+	a.descriptors[cell] = copyFloat64s(descriptor)
+	return true
+}
+
+// Elite returns the elite of a cell and its descriptor. The boolean is false
+// if the cell is empty.
+func (a *MAPArchive) Elite(cell int) (Individual, []float64, bool) {
+	if a.elites[cell].Genome == nil {
+		return Individual{}, nil, false
+	}
+	return a.elites[cell], a.descriptors[cell], true
+}
+
+// Elites returns the elites sorted from best to worst, like a hall of fame
+// covering every niche of the behavior space. The Genomes aren't copied.
+func (a *MAPArchive) Elites() Individuals {
+	var elites = make(Individuals, len(a.filled))
+	for i, cell := range a.filled {
+		elites[i] = a.elites[cell]
+	}
+	sort.SliceStable(elites, func(i, j int) bool { return elites[i].Better(elites[j]) })
+	return elites
+}
+
+// Len returns the number of cells that have an elite.
+func (a *MAPArchive) Len() int {
+	return len(a.filled)
+}
+
+// Coverage returns the fraction of the cells that have an elite.
+func (a *MAPArchive) Coverage() float64 {
+	return float64(len(a.filled)) / float64(len(a.elites))
+}
+
+// QDScore returns the sum over the elites of offset minus their fitness, which
+// rewards both the number and the quality of the elites. offset should be an
+// upper bound of the fitnesses so that every elite contributes positively.
+func (a *MAPArchive) QDScore(offset float64) float64 {
+	var score float64
+	for _, cell := range a.filled {
+		score += offset - a.elites[cell].Fitness
+	}
+	return score
+}
+
+// Illumination returns the fitness of the elite of each cell, NaN for the
+// empty cells. For a GridTessellation it can be reshaped into a heatmap
+// following the order of the cells.
+func (a *MAPArchive) Illumination() []float64 {
+	var fitnesses = make([]float64, len(a.elites))
+	for i, elite := range a.elites {
+		fitnesses[i] = math.NaN()
+		if elite.Genome != nil {
+			fitnesses[i] = elite.Fitness
+		}
+	}
+	return fitnesses
+}
+
+// sample returns a random elite. The archive shouldn't be empty.
+func (a *MAPArchive) sample(rng *rand.Rand) Individual {
+	return a.elites[a.filled[rng.Intn(len(a.filled))]]
+}
+
+// WriteCSV writes the illumination map of the archive to w, one record per
+// cell with an elite. The columns are "cell", the coordinates of the cell's
+// center "center_0", "center_1", ..., the elite's descriptor "descriptor_0",
+// "descriptor_1", ..., "id" and "fitness", which is enough to plot the map
+// with tools such as pandas.
+func (a *MAPArchive) WriteCSV(w io.Writer) error {
+	var (
+		dims   = a.Tessellation.Dims()
+		header = []string{"cell"}
+		cw     = csv.NewWriter(w)
+		format = func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+	)
+	for _, prefix := range []string{"center_", "descriptor_"} {
+		for i := 0; i < dims; i++ {
+			header = append(header, prefix+strconv.Itoa(i))
+		}
+	}
+	cw.Write(append(header, "id", "fitness"))
+	for cell, elite := range a.elites {
+		if elite.Genome == nil {
+			continue
+		}
+		var record = []string{strconv.Itoa(cell)}
+		for _, x := range a.Tessellation.Center(cell) {
+			record = append(record, format(x))
+		}
+		for _, x := range a.descriptors[cell] {
+			record = append(record, format(x))
+		}
+		cw.Write(append(record, elite.ID, format(elite.Fitness)))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// mapArchiveJSON is the JSON encoding of a MAPArchive, the i-th elite belongs
+// to the i-th cell and has the i-th descriptor.
+type mapArchiveJSON struct {
+	SchemaVersion uint            `json:"schema_version"`
+	NCells        int             `json:"n_cells"`
+	Cells         []int           `json:"cells"`
+	Descriptors   [][]float64     `json:"descriptors"`
+	Elites        json.RawMessage `json:"elites"`
+}
+
+// MarshalJSON encodes the elites of the archive along with their cells and
+// descriptors.
+func (a *MAPArchive) MarshalJSON() ([]byte, error) {
+	var (
+		doc    = mapArchiveJSON{SchemaVersion: SchemaVersion, NCells: len(a.elites)}
+		elites = make(Individuals, len(a.filled))
+		err    error
+	)
+	for i, cell := range a.filled {
+		doc.Cells = append(doc.Cells, cell)
+		doc.Descriptors = append(doc.Descriptors, a.descriptors[cell])
+		elites[i] = a.elites[cell]
+	}
+	if doc.Elites, err = json.Marshal(elites); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes an archive encoded with MarshalJSON. The Tessellation
+// and the JSONUnmarshaler have to be set and the Tessellation must have as
+// many cells as the encoded archive.
+func (a *MAPArchive) UnmarshalJSON(data []byte) error {
+	if a.Tessellation == nil || a.JSONUnmarshaler == nil {
+		return errors.New("the Tessellation and the JSONUnmarshaler have to be set")
+	}
+	var doc mapArchiveJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.NCells != a.Tessellation.NCells() {
+		return fmt.Errorf("the archive has %d cells but the Tessellation has %d", doc.NCells, a.Tessellation.NCells())
+	}
+	if doc.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("schema version %d is newer than %d", doc.SchemaVersion, SchemaVersion)
+	}
+	var elites, err = unmarshalIndividualsJSON(doc.Elites, a.JSONUnmarshaler)
+	if err != nil {
+		return err
+	}
+	if len(elites) != len(doc.Cells) || len(doc.Descriptors) != len(doc.Cells) {
+		return errors.New("the numbers of cells, descriptors and elites don't match")
+	}
+	a.elites = make([]Individual, doc.NCells)
+	a.descriptors = make([][]float64, doc.NCells)
+	a.filled = nil
+	for i, cell := range doc.Cells {
+		if cell < 0 || cell >= doc.NCells || elites[i].Genome == nil {
+			return fmt.Errorf("invalid elite for cell %d", cell)
+		}
+		elites[i].Evaluated = true
+		a.elites[cell] = elites[i]
+		a.descriptors[cell] = doc.Descriptors[i]
+		a.filled = append(a.filled, cell)
+	}
+	return nil
+}
+
+// An Emitter generates new Genomes from the elites of a MAPArchive, which
+// isn't empty. The Genomes are evaluated and added to the archive by
+// MAPElites.
+type Emitter interface {
+	Emit(archive *MAPArchive, n uint, rng *rand.Rand) ([]Genome, error)
+}
+
+// EmitMutation mutates copies of random elites, which is the emitter of the
+// original MAP-Elites algorithm.
+type EmitMutation struct{}
+
+// Emit n mutated elites.
+func (em EmitMutation) Emit(archive *MAPArchive, n uint, rng *rand.Rand) ([]Genome, error) {
+	var genomes = make([]Genome, n)
+	for i := range genomes {
+		genomes[i] = archive.sample(rng).Genome.Clone()
+		genomes[i].Mutate(rng)
+	}
+	return genomes, nil
+}
+
+// EmitCrossover crosses copies of pairs of random elites, each offspring then
+// being mutated with probability MutRate. Elites from different niches often
+// combine into Genomes that reach new niches.
+type EmitCrossover struct {
+	MutRate float64
+}
+
+// Emit n offsprings of random elites.
+func (ec EmitCrossover) Emit(archive *MAPArchive, n uint, rng *rand.Rand) ([]Genome, error) {
+	var genomes = make([]Genome, 0, n+1)
+	for uint(len(genomes)) < n {
+		var (
+			g1 = archive.sample(rng).Genome.Clone()
+			g2 = archive.sample(rng).Genome.Clone()
+		)
+		g1.Crossover(g2, rng)
+		for _, g := range []Genome{g1, g2} {
+			if rng.Float64() < ec.MutRate {
+				g.Mutate(rng)
+			}
+		}
+		genomes = append(genomes, g1, g2)
+	}
+	return genomes[:n], nil
+}
+
+// EmitIsoLine applies the Iso+LineDD variation operator to FloatVectors: a
+// random elite x receives isotropic gaussian noise of standard deviation
+// Sigma1 plus a step of standard deviation Sigma2 along the line towards
+// another random elite y, that is x + Sigma1 N(0, I) + Sigma2 N(0, 1) (y - x).
+// Sigma1 is relative to the width of the bounds of each gene and the result
+// is clipped to the bounds. Because the elites of different niches are
+// correlated, the line direction makes good use of their common structure.
+// Reference: https://arxiv.org/abs/1804.03906
+type EmitIsoLine struct {
+	Sigma1, Sigma2 float64
+}
+
+// Emit n Iso+LineDD offsprings.
+func (el EmitIsoLine) Emit(archive *MAPArchive, n uint, rng *rand.Rand) ([]Genome, error) {
+	var genomes = make([]Genome, n)
+	for i := range genomes {
+		var (
+			x, ok1 = archive.sample(rng).Genome.(*FloatVector)
+			y, ok2 = archive.sample(rng).Genome.(*FloatVector)
+		)
+		if !ok1 || !ok2 {
+			return nil, errors.New("EmitIsoLine requires the Genomes to be FloatVectors")
+		}
+		var (
+			o    = x.Clone().(*FloatVector)
+			p    = o.Problem
+			line = rng.NormFloat64() * el.Sigma2
+		)
+		for j := range o.Values {
+			o.Values[j] += rng.NormFloat64()*el.Sigma1*(p.Upper[j]-p.Lower[j]) + line*(y.Values[j]-x.Values[j])
+			o.Values[j] = math.Min(math.Max(o.Values[j], p.Lower[j]), p.Upper[j])
+		}
+		genomes[i] = o
+	}
+	return genomes, nil
+}
+
+// MAPElites (Multi-dimensional Archive of Phenotypic Elites) is a
+// quality-diversity algorithm: instead of a single optimum it looks for the
+// best Genome of each niche of a behavior space, which the Descriptor maps
+// Genomes to. The niches are the cells of a MAPArchive.
+//
+// NInit random Genomes are evaluated first, then at each of the NIterations
+// iterations an Emitter generates BatchSize Genomes from the elites. The
+// Emitters take turns, EmitMutation is used if there are none. Genomes are
+// evaluated in parallel if Parallel is true, whereas the Descriptor is called
+// sequentially.
+// Reference: https://arxiv.org/abs/1504.04909
+type MAPElites struct {
+	Descriptor  func(Genome) []float64
+	Emitters    []Emitter
+	NInit       uint
+	BatchSize   uint
+	NIterations uint
+	Parallel    bool
+}
+
+// Validate MAPElites fields.
+func (me MAPElites) Validate() error {
+	if me.Descriptor == nil {
+		return errors.New("Descriptor cannot be nil")
+	}
+	if me.NInit == 0 {
+		return errors.New("NInit should be positive")
+	}
+	if me.BatchSize == 0 {
+		return errors.New("BatchSize should be positive")
+	}
+	for _, em := range me.Emitters {
+		if em == nil {
+			return errors.New("Emitters cannot contain nil emitters")
+		}
+	}
+	return nil
+}
+
+// add evaluates the Individuals and adds them to the archive.
+func (me MAPElites) add(archive *MAPArchive, indis Individuals) error {
+	if err := indis.Evaluate(me.Parallel); err != nil {
+		return err
+	}
+	for _, indi := range indis {
+		var descriptor = me.Descriptor(indi.Genome)
+		if len(descriptor) != archive.Tessellation.Dims() {
+			return fmt.Errorf("the Descriptor returned %d values but the Tessellation has %d dimensions",
+				len(descriptor), archive.Tessellation.Dims())
+		}
+		archive.Add(indi, descriptor)
+	}
+	return nil
+}
+
+// Run fills the archive with the elites found. If the archive already
+// contains elites, for instance because it was decoded from JSON, the random
+// initialization is skipped and the search resumes from them; newGenome may
+// then be nil.
+func (me MAPElites) Run(archive *MAPArchive, newGenome func(rng *rand.Rand) Genome, rng *rand.Rand) error {
+	if err := me.Validate(); err != nil {
+		return err
+	}
+	if archive.Len() == 0 {
+		if newGenome == nil {
+			return errors.New("newGenome is required to fill an empty archive")
+		}
+		if err := me.add(archive, newIndividuals(me.NInit, false, newGenome, rng)); err != nil {
+			return err
+		}
+	}
+	var emitters = me.Emitters
+	if len(emitters) == 0 {
+		emitters = []Emitter{EmitMutation{}}
+	}
+	for i := uint(0); i < me.NIterations; i++ {
+		var genomes, err = emitters[int(i)%len(emitters)].Emit(archive, me.BatchSize, rng)
+		if err != nil {
+			return err
+		}
+		var indis = make(Individuals, len(genomes))
+		for j, g := range genomes {
+			indis[j] = NewIndividual(g, rng)
+		}
+		if err = me.add(archive, indis); err != nil {
+			return err
+		}
+	}
+	return nil
+}
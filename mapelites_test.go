@@ -0,0 +1,209 @@
+package eaopt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// describeFloatVector describes a FloatVector by its first two values.
+func describeFloatVector(g Genome) []float64 {
+	return copyFloat64s(g.(*FloatVector).Values[:2])
+}
+
+func TestGridTessellation(t *testing.T) {
+	var gt = GridTessellation{Lower: []float64{0, -1}, Upper: []float64{1, 1}, Bins: []uint{2, 4}}
+	if err := gt.Validate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if gt.NCells() != 8 || gt.Dims() != 2 {
+		t.Errorf("Expected 8 cells in 2 dimensions, got %d and %d", gt.NCells(), gt.Dims())
+	}
+	for cell := 0; cell < gt.NCells(); cell++ {
+		if c := gt.Cell(gt.Center(cell)); c != cell {
+			t.Errorf("The center of cell %d is in cell %d", cell, c)
+		}
+	}
+	if gt.Cell([]float64{0.9, -0.9}) != 4 || gt.Cell([]float64{-10, 10}) != 3 {
+		t.Error("Wrong cells")
+	}
+	for _, invalid := range []GridTessellation{
+		{},
+		{Lower: []float64{0}, Upper: []float64{1}, Bins: []uint{2, 2}},
+		{Lower: []float64{0}, Upper: []float64{1}, Bins: []uint{0}},
+		{Lower: []float64{1}, Upper: []float64{1}, Bins: []uint{2}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestCVTTessellation(t *testing.T) {
+	var cvt, err = NewCVTTessellation(20, []float64{0, 0}, []float64{1, 1}, 1000, newRand())
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if cvt.NCells() != 20 || cvt.Dims() != 2 {
+		t.Errorf("Expected 20 cells in 2 dimensions, got %d and %d", cvt.NCells(), cvt.Dims())
+	}
+	for cell := 0; cell < cvt.NCells(); cell++ {
+		if c := cvt.Cell(cvt.Center(cell)); c != cell {
+			t.Errorf("The centroid of cell %d is in cell %d", cell, c)
+		}
+	}
+	if _, err = NewCVTTessellation(20, []float64{0}, []float64{1}, 10, newRand()); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestMAPElites(t *testing.T) {
+	var (
+		rng     = newRand()
+		problem = newTestFloatProblem()
+		grid    = GridTessellation{Lower: problem.Lower[:2], Upper: problem.Upper[:2], Bins: []uint{10, 10}}
+		archive = NewMAPArchive(grid)
+		me      = MAPElites{
+			Descriptor:  describeFloatVector,
+			Emitters:    []Emitter{EmitMutation{}, EmitCrossover{MutRate: 0.5}, EmitIsoLine{Sigma1: 0.01, Sigma2: 0.2}},
+			NInit:       50,
+			BatchSize:   50,
+			NIterations: 60,
+		}
+	)
+	if err := me.Run(archive, problem.NewGenome, rng); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if archive.Coverage() < 0.9 {
+		t.Errorf("Expected most cells to be filled, got %f", archive.Coverage())
+	}
+	// Each elite lies in its cell and is the only Individual of its cell
+	for cell := 0; cell < grid.NCells(); cell++ {
+		var elite, descriptor, ok = archive.Elite(cell)
+		if !ok {
+			continue
+		}
+		if grid.Cell(descriptor) != cell || grid.Cell(describeFloatVector(elite.Genome)) != cell {
+			t.Errorf("The elite of cell %d is out of its cell", cell)
+		}
+		if f, _ := elite.Genome.Evaluate(); f != elite.Fitness {
+			t.Errorf("Expected %f, got %f", f, elite.Fitness)
+		}
+	}
+	var elites = archive.Elites()
+	if len(elites) != archive.Len() || elites[0].Fitness > 0.5 || elites[0].Fitness > elites[len(elites)-1].Fitness {
+		t.Errorf("Wrong elites, the best has a fitness of %f", elites[0].Fitness)
+	}
+	var illumination = archive.Illumination()
+	var nans int
+	for _, f := range illumination {
+		if math.IsNaN(f) {
+			nans++
+		}
+	}
+	if len(illumination) != 100 || nans != 100-archive.Len() {
+		t.Errorf("Wrong illumination map with %d empty cells", nans)
+	}
+	if score := archive.QDScore(50); score <= 0 || score > 50*float64(archive.Len()) {
+		t.Errorf("Unexpected QD score %f", score)
+	}
+	// The search can be resumed
+	var best = elites[0].Fitness
+	me.NIterations = 5
+	if err := me.Run(archive, nil, rng); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if archive.Elites()[0].Fitness > best {
+		t.Error("The archive got worse")
+	}
+}
+
+func TestMAPArchiveSerialization(t *testing.T) {
+	var (
+		rng      = newRand()
+		grid     = GridTessellation{Lower: []float64{-10, -10}, Upper: []float64{10, 10}, Bins: []uint{4, 4}}
+		archive  = NewMAPArchive(grid)
+		describe = func(g Genome) []float64 { return copyFloat64s(g.(Vector)[:2]) }
+		me       = MAPElites{Descriptor: describe, NInit: 20, BatchSize: 10, NIterations: 5}
+	)
+	if err := me.Run(archive, NewVector, rng); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var data, err = json.Marshal(archive)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var decoded = NewMAPArchive(grid)
+	if err = json.Unmarshal(data, decoded); err == nil {
+		t.Error("Expected an error without JSONUnmarshaler")
+	}
+	decoded.JSONUnmarshaler = VectorJSONUnmarshaler
+	if err = json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if decoded.Len() != archive.Len() {
+		t.Fatalf("Expected %d elites, got %d", archive.Len(), decoded.Len())
+	}
+	for cell := 0; cell < grid.NCells(); cell++ {
+		var e1, d1, ok1 = archive.Elite(cell)
+		var e2, d2, ok2 = decoded.Elite(cell)
+		if ok1 != ok2 || (ok1 && (e1.Fitness != e2.Fitness || e1.ID != e2.ID || d1[0] != d2[0])) {
+			t.Errorf("Cell %d wasn't restored", cell)
+		}
+	}
+	// The Tessellation has to match
+	var other = NewMAPArchive(GridTessellation{Lower: grid.Lower, Upper: grid.Upper, Bins: []uint{2, 2}})
+	other.JSONUnmarshaler = decoded.JSONUnmarshaler
+	if err = json.Unmarshal(data, other); err == nil {
+		t.Error("Expected an error with a different Tessellation")
+	}
+	// Illumination map export
+	var buf bytes.Buffer
+	if err = archive.WriteCSV(&buf); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var header = []string{"cell", "center_0", "center_1", "descriptor_0", "descriptor_1", "id", "fitness"}
+	if len(records) != archive.Len()+1 || len(records[0]) != len(header) || records[0][3] != header[3] {
+		t.Errorf("Wrong CSV %v", records)
+	}
+}
+
+func TestMAPElitesErrors(t *testing.T) {
+	var (
+		rng     = newRand()
+		problem = newTestFloatProblem()
+		grid    = GridTessellation{Lower: []float64{0}, Upper: []float64{1}, Bins: []uint{4}}
+	)
+	for _, me := range []MAPElites{
+		{NInit: 1, BatchSize: 1},
+		{Descriptor: describeFloatVector, BatchSize: 1},
+		{Descriptor: describeFloatVector, NInit: 1},
+		{Descriptor: describeFloatVector, NInit: 1, BatchSize: 1, Emitters: []Emitter{nil}},
+	} {
+		if err := me.Run(NewMAPArchive(grid), problem.NewGenome, rng); err == nil {
+			t.Errorf("Expected an error for %+v", me)
+		}
+	}
+	// The descriptor doesn't match the Tessellation
+	var me = MAPElites{Descriptor: describeFloatVector, NInit: 1, BatchSize: 1}
+	if err := me.Run(NewMAPArchive(grid), problem.NewGenome, rng); err == nil {
+		t.Error("Expected an error")
+	}
+	// EmitIsoLine requires FloatVectors
+	me = MAPElites{
+		Descriptor:  func(g Genome) []float64 { return []float64{0} },
+		Emitters:    []Emitter{EmitIsoLine{Sigma1: 0.1}},
+		NInit:       2,
+		BatchSize:   1,
+		NIterations: 1,
+	}
+	if err := me.Run(NewMAPArchive(grid), NewVector, rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,165 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// MatingRestriction chooses the mates of a parent, which reduces lethal
+// crossovers between distant solutions. Apply is given the index of the parent
+// in indis and returns n mates along with their indexes. The mates are drawn
+// from candidates sampled with sel, the parent itself excluded.
+type MatingRestriction interface {
+	Apply(n uint, parent int, indis Individuals, sel Selector, rng *rand.Rand) (mates Individuals, indexes []int, err error)
+	Validate() error
+}
+
+// sampleMateCandidates samples nCandidates individuals with sel and discards
+// the ones that are the parent. The distance between each candidate and the
+// parent is returned along with the candidates.
+func sampleMateCandidates(nCandidates uint, parent int, indis Individuals, sel Selector, metric Metric,
+	rng *rand.Rand) (Individuals, []int, []float64, error) {
+	// Some selectors reorder indis, hence the parent is retrieved beforehand
+	var (
+		p                        = indis[parent]
+		candidates, indexes, err = sel.Apply(nCandidates, indis, rng)
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		distances = make([]float64, 0, len(candidates))
+		n         = 0
+	)
+	for i := range candidates {
+		if indexes[i] == parent {
+			continue
+		}
+		candidates[n], indexes[n] = candidates[i], indexes[i]
+		distances = append(distances, metric(p, candidates[n]))
+		n++
+	}
+	candidates, indexes = candidates[:n], indexes[:n]
+	// Sort the candidates by increasing distance to the parent
+	var order = make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return distances[order[a]] < distances[order[b]] })
+	var (
+		sortedCandidates = make(Individuals, n)
+		sortedIndexes    = make([]int, n)
+		sortedDistances  = make([]float64, n)
+	)
+	for i, o := range order {
+		sortedCandidates[i], sortedIndexes[i], sortedDistances[i] = candidates[o], indexes[o], distances[o]
+	}
+	return sortedCandidates, sortedIndexes, sortedDistances, nil
+}
+
+// MateAssortative implements assortative mating. NCandidates candidates are
+// sampled and the mates are the closest ones to the parent according to Metric
+// if Positive is true, the farthest ones otherwise. Positive assortative mating
+// favours exploitation inside niches whereas negative assortative mating
+// maintains diversity.
+type MateAssortative struct {
+	Metric      Metric
+	Positive    bool
+	NCandidates uint
+}
+
+// Apply MateAssortative.
+func (mate MateAssortative) Apply(n uint, parent int, indis Individuals, sel Selector,
+	rng *rand.Rand) (Individuals, []int, error) {
+	var candidates, indexes, _, err = sampleMateCandidates(mate.NCandidates, parent, indis, sel, mate.Metric, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(candidates) < int(n) {
+		return nil, nil, fmt.Errorf("found %d candidates for %d mates", len(candidates), n)
+	}
+	if mate.Positive {
+		return candidates[:n], indexes[:n], nil
+	}
+	return candidates[len(candidates)-int(n):], indexes[len(indexes)-int(n):], nil
+}
+
+// Validate MateAssortative fields.
+func (mate MateAssortative) Validate() error {
+	if mate.Metric == nil {
+		return errors.New("Metric cannot be nil")
+	}
+	if mate.NCandidates < 1 {
+		return errors.New("NCandidates should be higher than 0")
+	}
+	return nil
+}
+
+// MateSpecies restricts mating to the parent's species, defined as the
+// individuals which are at most Radius away from it according to Metric. Mates
+// are drawn at random among the NCandidates sampled candidates belonging to the
+// species. If there are too few of them then the closest candidates outside of
+// the species are used instead. Note that GAConfig.Speciator already restricts
+// mating to species that are identified once per generation; MateSpecies
+// identifies each parent's species on the fly.
+type MateSpecies struct {
+	Metric      Metric
+	Radius      float64
+	NCandidates uint
+}
+
+// Apply MateSpecies.
+func (mate MateSpecies) Apply(n uint, parent int, indis Individuals, sel Selector,
+	rng *rand.Rand) (Individuals, []int, error) {
+	var candidates, indexes, distances, err = sampleMateCandidates(mate.NCandidates, parent, indis, sel, mate.Metric, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(candidates) < int(n) {
+		return nil, nil, fmt.Errorf("found %d candidates for %d mates", len(candidates), n)
+	}
+	// Shuffle the candidates inside the species
+	var k = 0
+	for k < len(distances) && distances[k] <= mate.Radius {
+		k++
+	}
+	rng.Shuffle(k, func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+	})
+	return candidates[:n], indexes[:n], nil
+}
+
+// Validate MateSpecies fields.
+func (mate MateSpecies) Validate() error {
+	if mate.Metric == nil {
+		return errors.New("Metric cannot be nil")
+	}
+	if mate.Radius < 0 {
+		return errors.New("Radius should be positive")
+	}
+	if mate.NCandidates < 1 {
+		return errors.New("NCandidates should be higher than 0")
+	}
+	return nil
+}
+
+// selectParents selects nParents parents with sel. If mating isn't nil then
+// only the first parent is selected with sel and mating chooses its mates.
+func selectParents(nParents uint, indis Individuals, sel Selector, mating MatingRestriction,
+	rng *rand.Rand) (Individuals, []int, error) {
+	if mating == nil {
+		return sel.Apply(nParents, indis, rng)
+	}
+	var parent, indexes, err = sel.Apply(1, indis, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	mates, mateIndexes, err := mating.Apply(nParents-1, indexes[0], indis, sel, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(parent, mates...), append(indexes, mateIndexes...), nil
+}
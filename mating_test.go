@@ -0,0 +1,147 @@
+package eaopt
+
+import (
+	"testing"
+)
+
+func newMatingIndividuals() Individuals {
+	var (
+		rng   = newRand()
+		indis = Individuals{
+			NewIndividual(Vector{0}, rng),
+			NewIndividual(Vector{1}, rng),
+			NewIndividual(Vector{2}, rng),
+			NewIndividual(Vector{10}, rng),
+		}
+	)
+	indis.Evaluate(false)
+	return indis
+}
+
+func TestMateAssortative(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newMatingIndividuals()
+		sel   = SelTournament{NContestants: 1}
+	)
+	var mates, indexes, err = MateAssortative{Metric: l1Distance, Positive: true, NCandidates: 4}.Apply(2, 0, indis, sel, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mates[0].Fitness != 1 || mates[1].Fitness != 2 || indexes[0] != 1 || indexes[1] != 2 {
+		t.Errorf("Expected the closest individuals, got %v and %v", mates, indexes)
+	}
+	mates, indexes, err = MateAssortative{Metric: l1Distance, NCandidates: 4}.Apply(1, 0, indis, sel, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mates[0].Fitness != 10 || indexes[0] != 3 {
+		t.Errorf("Expected the farthest individual, got %v and %v", mates, indexes)
+	}
+	// The parent can't be its own mate
+	if _, _, err = (MateAssortative{Metric: l1Distance, NCandidates: 4}).Apply(4, 0, indis, sel, rng); err == nil {
+		t.Error("Expected an error")
+	}
+	// Selection errors are returned
+	if _, _, err = (MateAssortative{Metric: l1Distance, NCandidates: 5}).Apply(1, 0, indis, sel, rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestMateSpecies(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newMatingIndividuals()
+		sel   = SelTournament{NContestants: 1}
+		mate  = MateSpecies{Metric: l1Distance, Radius: 2, NCandidates: 4}
+		seen  = make(map[int]bool)
+	)
+	for i := 0; i < 50; i++ {
+		var mates, indexes, err = mate.Apply(1, 0, indis, sel, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mates[0].Fitness > 2 {
+			t.Errorf("Mate %v is not in the species of the parent", mates[0].Genome)
+		}
+		seen[indexes[0]] = true
+	}
+	if !seen[1] || !seen[2] {
+		t.Error("Mates should be drawn at random inside the species")
+	}
+	// The closest individuals outside the species complete the mates
+	var mates, _, err = mate.Apply(3, 0, indis, sel, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mates[2].Fitness != 10 {
+		t.Errorf("Expected the individual outside the species last, got %v", mates)
+	}
+	if _, _, err = mate.Apply(4, 0, indis, sel, rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestSelectParents(t *testing.T) {
+	var (
+		rng    = newRand()
+		indis  = newIndividuals(20, false, NewVector, rng)
+		mating = MateAssortative{Metric: l1Distance, Positive: true, NCandidates: 5}
+	)
+	indis.Evaluate(false)
+	var selected, indexes, err = selectParents(3, indis, SelTournament{2}, mating, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 3 || len(indexes) != 3 {
+		t.Fatalf("Expected 3 parents, got %d", len(selected))
+	}
+	for i := range selected {
+		if selected[i].Fitness != indis[indexes[i]].Fitness {
+			t.Errorf("Index %d doesn't match the selected parent", indexes[i])
+		}
+	}
+	if l1Distance(selected[0], selected[1]) > l1Distance(selected[0], selected[2]) {
+		t.Error("The mates should be sorted by distance")
+	}
+	// The mating restrictions are used by the models
+	for _, model := range []Model{
+		ModGenerational{Selector: SelTournament{2}, CrossRate: 1, Mating: mating},
+		ModSteadyState{Selector: SelTournament{2}, CrossRate: 1, KeepBest: true, Mating: mating},
+		ModDownToSize{NOffsprings: 5, SelectorA: SelTournament{2}, SelectorB: SelElitism{}, CrossRate: 1,
+			Mating: MateSpecies{Metric: l1Distance, Radius: 5, NCandidates: 5}},
+	} {
+		var pop = newPopulation(20, false, NewVector, rng)
+		pop.Individuals.Evaluate(false)
+		if err = model.Apply(&pop); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestMatingValidate(t *testing.T) {
+	var invalid = []interface{ Validate() error }{
+		MateAssortative{NCandidates: 2},
+		MateAssortative{Metric: l1Distance},
+		MateSpecies{Radius: 1, NCandidates: 2},
+		MateSpecies{Metric: l1Distance, Radius: -1, NCandidates: 2},
+		MateSpecies{Metric: l1Distance, Radius: 1},
+		ModGenerational{Selector: SelTournament{2}, Mating: MateAssortative{}},
+		ModSteadyState{Selector: SelTournament{2}, Mating: MateAssortative{}},
+		ModDownToSize{NOffsprings: 5, SelectorA: SelTournament{2}, SelectorB: SelElitism{}, Mating: MateAssortative{}},
+	}
+	for i, v := range invalid {
+		if err := v.Validate(); err == nil {
+			t.Errorf("Expected an error in test case number %d", i)
+		}
+	}
+	var valid = []interface{ Validate() error }{
+		MateAssortative{Metric: l1Distance, NCandidates: 2},
+		MateSpecies{Metric: l1Distance, Radius: 1, NCandidates: 2},
+	}
+	for _, v := range valid {
+		if err := v.Validate(); err != nil {
+			t.Errorf("Expected nil, got %v", err)
+		}
+	}
+}
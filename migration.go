@@ -5,13 +5,42 @@ import (
 	"math/rand"
 )
 
+// Migrators move Individuals as a whole, hence migrants keep their fitness,
+// violation, objectives and metadata and aren't evaluated again in their
+// destination Population unless GAConfig.ReevaluateMigrants is set. Migrants
+// which haven't been evaluated, for instance because a Migrator creates new
+// Individuals, are evaluated as soon as they arrive.
+
 // Migrator applies crossover to the GA level, as such it doesn't
 // require an independent random number generator and can use the global one.
+// Schedule returns true if the Populations should migrate at the given
+// generation, which lets each Migrator decide how often migrations occur.
 type Migrator interface {
 	Apply(pops Populations, rng *rand.Rand)
+	Schedule(generation uint) bool
 	Validate() error
 }
 
+// An AsyncMigrator is a Migrator which can exchange Individuals between
+// Populations that don't evolve in lockstep, see GAConfig.AsyncMigration.
+// When the i-th of n Populations reaches a generation at which it is
+// scheduled to migrate, Emigrate returns copies of the Individuals it sends
+// along with the index of the Population they are sent to. Migrants wait
+// until their destination is itself scheduled to migrate, at which point
+// Immigrate inserts them into it. Both methods only use the given Population
+// and random number generator, hence Populations can migrate concurrently.
+type AsyncMigrator interface {
+	Migrator
+	Emigrate(pop Population, i, n int, rng *rand.Rand) (Individuals, int)
+	Immigrate(pop *Population, migrants Individuals, rng *rand.Rand)
+}
+
+// scheduleEvery returns true if generation is a multiple of frequency, 0
+// being the same as 1.
+func scheduleEvery(frequency, generation uint) bool {
+	return frequency <= 1 || generation%frequency == 0
+}
+
 // MigRing migration exchanges individuals between consecutive Populations in a
 // random fashion. One by one, each population exchanges NMigrants individuals
 // at random with the next population. NMigrants should be not higher than the
@@ -19,6 +48,7 @@ type Migrator interface {
 // migrate and it will be as if nothing happened.
 type MigRing struct {
 	NMigrants uint // Number of migrants per exchange between Populations
+	Frequency uint // Number of generations between migrations, 0 means every generation
 }
 
 // Apply MigRing.
@@ -30,6 +60,35 @@ func (mig MigRing) Apply(pops Populations, rng *rand.Rand) {
 	}
 }
 
+// Schedule MigRing every Frequency generations.
+func (mig MigRing) Schedule(generation uint) bool {
+	return scheduleEvery(mig.Frequency, generation)
+}
+
+// Emigrate copies NMigrants random Individuals of the i-th Population and
+// sends them to the next Population, the last Population sending them to the
+// first one.
+func (mig MigRing) Emigrate(pop Population, i, n int, rng *rand.Rand) (Individuals, int) {
+	var (
+		ks       = randomInts(uint(minInt(int(mig.NMigrants), len(pop.Individuals))), 0, len(pop.Individuals), rng)
+		migrants = make(Individuals, len(ks))
+	)
+	for j, k := range ks {
+		migrants[j] = pop.Individuals[k].Copy()
+	}
+	return migrants, (i + 1) % n
+}
+
+// Immigrate replaces random Individuals of pop with the migrants, which are
+// already evaluated, and sorts pop again.
+func (mig MigRing) Immigrate(pop *Population, migrants Individuals, rng *rand.Rand) {
+	var n = minInt(len(migrants), len(pop.Individuals))
+	for j, k := range randomInts(uint(n), 0, len(pop.Individuals), rng) {
+		pop.Individuals[k] = migrants[j]
+	}
+	pop.Individuals.SortByFitness()
+}
+
 // Validate MigRing fields.
 func (mig MigRing) Validate() error {
 	if mig.NMigrants == 0 {
@@ -37,3 +96,110 @@ func (mig MigRing) Validate() error {
 	}
 	return nil
 }
+
+// A SpeciesMigrator is a Migrator which takes the global species into account
+// when GAConfig.GlobalSpeciation is set. species[i][j] is the species of the
+// j-th Individual of the i-th Population; ApplySpecies has to keep it valid,
+// hence Individuals may only be exchanged with members of their species.
+type SpeciesMigrator interface {
+	Migrator
+	ApplySpecies(pops Populations, species [][]int, rng *rand.Rand)
+}
+
+// MigSpecies exchanges individuals between consecutive Populations like
+// MigRing, except that with global speciation each migrant replaces a random
+// member of its own species in the next Population. The Populations sharing a
+// niche thus exchange genes without the niches overwriting each other.
+// Migrants whose species is absent from the next Population stay where they
+// are. Without global speciation MigSpecies behaves like MigRing.
+type MigSpecies struct {
+	NMigrants uint // Number of migrants per exchange between Populations
+	Frequency uint // Number of generations between migrations, 0 means every generation
+}
+
+// Apply MigSpecies.
+func (mig MigSpecies) Apply(pops Populations, rng *rand.Rand) {
+	MigRing(mig).Apply(pops, rng)
+}
+
+// ApplySpecies applies MigSpecies with the given species.
+func (mig MigSpecies) ApplySpecies(pops Populations, species [][]int, rng *rand.Rand) {
+	for i := 0; i < len(pops)-1; i++ {
+		for _, k := range randomInts(mig.NMigrants, 0, len(pops[i].Individuals), rng) {
+			var mates []int
+			for l, s := range species[i+1] {
+				if s == species[i][k] {
+					mates = append(mates, l)
+				}
+			}
+			if len(mates) == 0 {
+				continue
+			}
+			var l = mates[rng.Intn(len(mates))]
+			pops[i].Individuals[k], pops[i+1].Individuals[l] = pops[i+1].Individuals[l], pops[i].Individuals[k]
+		}
+	}
+}
+
+// Schedule MigSpecies every Frequency generations.
+func (mig MigSpecies) Schedule(generation uint) bool {
+	return MigRing(mig).Schedule(generation)
+}
+
+// Emigrate behaves like MigRing, species are only known with synchronous
+// migrations.
+func (mig MigSpecies) Emigrate(pop Population, i, n int, rng *rand.Rand) (Individuals, int) {
+	return MigRing(mig).Emigrate(pop, i, n, rng)
+}
+
+// Immigrate behaves like MigRing.
+func (mig MigSpecies) Immigrate(pop *Population, migrants Individuals, rng *rand.Rand) {
+	MigRing(mig).Immigrate(pop, migrants, rng)
+}
+
+// Validate MigSpecies fields.
+func (mig MigSpecies) Validate() error {
+	return MigRing(mig).Validate()
+}
+
+// settleMigrants evaluates the Individuals a lockstep migration brought into
+// each Population. before holds the Individuals each Population had before the
+// migration, migrants being recognized by their ID.
+func (ga *GA) settleMigrants(before []Individuals) error {
+	for i := range ga.Populations {
+		var (
+			indis    = ga.Populations[i].Individuals
+			was      = countIDs(before[i])
+			migrants Individuals
+			ks       []int
+		)
+		for k, indi := range indis {
+			if was[indi.ID] == 0 {
+				migrants = append(migrants, indi)
+				ks = append(ks, k)
+			}
+		}
+		if err := ga.evaluateMigrants(migrants, Individuals.Evaluate); err != nil {
+			return err
+		}
+		for j, k := range ks {
+			indis[k] = migrants[j]
+		}
+	}
+	return nil
+}
+
+// evaluateMigrants evaluates the migrants with evaluate, which skips the
+// Individuals that are already evaluated, after having marked them as not
+// evaluated if ReevaluateMigrants is set.
+func (ga *GA) evaluateMigrants(migrants Individuals, evaluate func(Individuals, bool) error) error {
+	if len(migrants) == 0 {
+		return nil
+	}
+	if ga.ReevaluateMigrants {
+		for i := range migrants {
+			migrants[i].Evaluated = false
+		}
+	}
+	return evaluate(migrants, ga.ParallelEval)
+}
@@ -9,6 +9,9 @@ func TestMigSizes(t *testing.T) {
 			MigRing{
 				NMigrants: 5,
 			},
+			MigSpecies{
+				NMigrants: 5,
+			},
 		}
 	)
 	for _, migrator := range migrators {
@@ -41,7 +44,7 @@ func TestMigSizes(t *testing.T) {
 }
 
 func TestMigRingValidate(t *testing.T) {
-	var mig = MigRing{1}
+	var mig = MigRing{NMigrants: 1}
 	if err := mig.Validate(); err != nil {
 		t.Error("Validation should not have raised error")
 	}
@@ -51,3 +54,186 @@ func TestMigRingValidate(t *testing.T) {
 		t.Error("Validation should raised error")
 	}
 }
+
+func TestMigSpeciesApplySpecies(t *testing.T) {
+	var (
+		rng     = newRand()
+		pops    = make(Populations, 3)
+		species = make([][]int, len(pops))
+	)
+	for i := range pops {
+		pops[i] = newPopulation(10, false, NewVector, rng)
+		species[i] = make([]int, len(pops[i].Individuals))
+		for j := range species[i] {
+			species[i][j] = j % 2
+		}
+	}
+	// The last Population only contains Individuals of species 0
+	for j := range species[2] {
+		species[2][j] = 0
+	}
+	var ids = make([]map[string]bool, len(pops))
+	for i, pop := range pops {
+		ids[i] = make(map[string]bool)
+		for _, indi := range pop.Individuals {
+			ids[i][indi.ID] = true
+		}
+	}
+	var speciesOfID = make(map[string]int)
+	for i, pop := range pops {
+		for j, indi := range pop.Individuals {
+			speciesOfID[indi.ID] = species[i][j]
+		}
+	}
+	MigSpecies{NMigrants: 10}.ApplySpecies(pops, species, rng)
+	var migrated bool
+	for i, pop := range pops {
+		for j, indi := range pop.Individuals {
+			if speciesOfID[indi.ID] != species[i][j] {
+				t.Errorf("Individual %s of species %d ended up in species %d", indi.ID, speciesOfID[indi.ID], species[i][j])
+			}
+			if !ids[i][indi.ID] {
+				migrated = true
+			}
+		}
+	}
+	if !migrated {
+		t.Error("No Individual migrated")
+	}
+}
+
+func TestMigSpeciesValidate(t *testing.T) {
+	if err := (MigSpecies{NMigrants: 1}).Validate(); err != nil {
+		t.Error("Validation should not have raised error")
+	}
+	if err := (MigSpecies{NMigrants: 0}).Validate(); err == nil {
+		t.Error("Validation should raised error")
+	}
+}
+
+func TestMigRingSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		mig        Migrator
+		generation uint
+		due        bool
+	}{
+		{MigRing{NMigrants: 1}, 7, true},
+		{MigRing{NMigrants: 1, Frequency: 1}, 7, true},
+		{MigRing{NMigrants: 1, Frequency: 3}, 7, false},
+		{MigRing{NMigrants: 1, Frequency: 3}, 9, true},
+		{MigSpecies{NMigrants: 1, Frequency: 2}, 3, false},
+		{MigSpecies{NMigrants: 1, Frequency: 2}, 4, true},
+	} {
+		if due := tc.mig.Schedule(tc.generation); due != tc.due {
+			t.Errorf("Expected %v for %+v at generation %d, got %v", tc.due, tc.mig, tc.generation, due)
+		}
+	}
+}
+
+func TestMigRingEmigrateImmigrate(t *testing.T) {
+	var (
+		rng  = newRand()
+		mig  = MigRing{NMigrants: 3}
+		pops = make(Populations, 2)
+	)
+	for i := range pops {
+		pops[i] = newPopulation(10, false, NewVector, rng)
+		pops[i].Individuals.Evaluate(false)
+		pops[i].Individuals.SortByFitness()
+	}
+	var migrants, dest = mig.Emigrate(pops[1], 1, 2, rng)
+	if dest != 0 || len(migrants) != 3 {
+		t.Fatalf("Expected 3 migrants sent to 0, got %d sent to %d", len(migrants), dest)
+	}
+	// The migrants are copies
+	migrants[0].Genome.(Vector)[0] = 1e6
+	for _, indi := range pops[1].Individuals {
+		if indi.Genome.(Vector)[0] == 1e6 {
+			t.Error("The migrants share their Genome with the emigrants")
+		}
+	}
+	migrants[0].Genome.(Vector)[0] = -1e6
+	migrants[0].Fitness = -1e6
+	mig.Immigrate(&pops[0], migrants, rng)
+	if len(pops[0].Individuals) != 10 || pops[0].Individuals[0].Fitness != -1e6 {
+		t.Error("The migrants weren't inserted and sorted")
+	}
+}
+
+func TestGASettleMigrants(t *testing.T) {
+	for _, reevaluate := range []bool{false, true} {
+		var conf = NewDefaultGAConfig()
+		conf.NPops = 2
+		conf.PopSize = 10
+		conf.Migrator = MigRing{NMigrants: 3}
+		conf.ReevaluateMigrants = reevaluate
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ga.Init(NewVector); err != nil {
+			t.Fatal(err)
+		}
+		var (
+			evals  = ga.Evaluations()
+			before = ga.Populations.individuals()
+		)
+		ga.Migrator.Apply(ga.Populations, ga.RNG)
+		// A migrant which hasn't been evaluated is evaluated in any case, it
+		// takes the place of an Individual which didn't migrate
+		var fresh = NewIndividual(NewVector(ga.RNG), ga.RNG)
+		fresh.eval = ga.eval
+		fresh.Metadata = map[string]interface{}{"origin": "elsewhere"}
+		var k = 0
+		for countIDs(before[1])[ga.Populations[1].Individuals[k].ID] == 0 {
+			k++
+		}
+		ga.Populations[1].Individuals[k] = fresh
+		if err = ga.settleMigrants(before); err != nil {
+			t.Fatal(err)
+		}
+		// MigRing swaps 3 pairs of Individuals
+		var expected = evals + 1
+		if reevaluate {
+			expected += 6
+		}
+		if ga.Evaluations() != expected {
+			t.Errorf("ReevaluateMigrants %t: expected %d evaluations, got %d", reevaluate, expected, ga.Evaluations())
+		}
+		for _, pop := range ga.Populations {
+			for _, indi := range pop.Individuals {
+				if f, _ := indi.Genome.Evaluate(); !indi.Evaluated || f != indi.Fitness {
+					t.Errorf("Expected evaluated migrants, got %+v", indi)
+				}
+			}
+		}
+		if settled := ga.Populations[1].Individuals[k]; settled.ID != fresh.ID || settled.Metadata["origin"] != "elsewhere" {
+			t.Errorf("Expected the migrant to keep its ID and metadata, got %+v", settled)
+		}
+	}
+}
+
+func TestGAReevaluateMigrants(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		var conf = NewDefaultGAConfig()
+		conf.NPops = 2
+		conf.NGenerations = 5
+		conf.Migrator = MigRing{NMigrants: 5}
+		conf.AsyncMigration = async
+		conf.ReevaluateMigrants = true
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ga.Minimize(NewVector); err != nil {
+			t.Errorf("AsyncMigration %t: expected nil, got %v", async, err)
+		}
+		for _, pop := range ga.Populations {
+			for _, indi := range pop.Individuals {
+				if f, _ := indi.Genome.Evaluate(); !indi.Evaluated || f != indi.Fitness {
+					t.Errorf("AsyncMigration %t: expected evaluated Individuals, got %+v", async, indi)
+				}
+			}
+		}
+	}
+}
@@ -2,47 +2,56 @@ package eaopt
 
 import (
 	"errors"
+	"math"
 	"math/rand"
+	"sort"
 )
 
 var (
 	errNilSelector      = errors.New("selector cannot be nil")
 	errInvalidMutRate   = errors.New("mutRate should be between 0 and 1")
 	errInvalidCrossRate = errors.New("crossRate should be between 0 and 1")
+	errInvalidNParents  = errors.New("NParents should be 0 or higher than 1")
 )
 
-// Two parents are selected from a pool of individuals, crossover is then
-// applied to generate two offsprings. The selection and crossover process is
-// repeated until n offsprings have been generated. If n is uneven then the
-// second offspring of the last crossover is discarded.
-func generateOffsprings(n uint, indis Individuals, sel Selector, crossRate float64,
-	rng *rand.Rand) (Individuals, error) {
+// nParents parents are selected from a pool of individuals, with mating
+// choosing the mates of the first parent if it isn't nil, crossover is then
+// applied to generate offsprings, as many as there are parents unless the
+// Genomes are Breeders. The selection and crossover process is repeated until n
+// offsprings have been generated. Surplus offsprings of the last crossover are
+// discarded.
+func generateOffsprings(n, nParents uint, indis Individuals, sel Selector, mating MatingRestriction,
+	crossRate float64, rng *rand.Rand) (Individuals, error) {
 	var (
 		offsprings = make(Individuals, n)
 		i          = 0
 	)
 	for i < len(offsprings) {
-		// Select 2 parents
-		var selected, _, err = sel.Apply(2, indis, rng)
+		// Select the parents
+		var selected, _, err = selectParents(nParents, indis, sel, mating, rng)
 		if err != nil {
 			return nil, err
 		}
-		// Generate 2 offsprings from the parents
+		// Generate offsprings from the parents
 		if rng.Float64() < crossRate {
-			selected[0].Crossover(selected[1], rng)
-		}
-		if i < len(offsprings) {
-			offsprings[i] = selected[0]
-			i++
-		}
-		if i < len(offsprings) {
-			offsprings[i] = selected[1]
-			i++
+			if selected, err = crossover(selected, rng); err != nil {
+				return nil, err
+			}
 		}
+		i += copy(offsprings[i:], selected)
 	}
 	return offsprings, nil
 }
 
+// defaultNParents returns the number of parents to recombine at once, which is
+// 2 unless specified otherwise.
+func defaultNParents(n uint) uint {
+	if n == 0 {
+		return 2
+	}
+	return n
+}
+
 // A Model specifies a protocol for applying genetic operators to a
 // population at generation i in order for it obtain better individuals at
 // generation i+1.
@@ -51,29 +60,43 @@ type Model interface {
 	Validate() error
 }
 
-// ModGenerational implements the generational model.
+// ModGenerational implements the generational model. NParents is the number
+// of parents recombined at once, 2 if it is 0; more than 2 parents require the
+// Genomes to be Breeders. If Mating isn't nil then it chooses the mates of
+// each parent selected with Selector. If MutSchedule or CrossSchedule isn't
+// nil then it overrides MutRate or CrossRate.
 type ModGenerational struct {
-	Selector  Selector
-	MutRate   float64
-	CrossRate float64
+	Selector      Selector
+	MutRate       float64
+	CrossRate     float64
+	MutSchedule   Schedule
+	CrossSchedule Schedule
+	NParents      uint
+	Mating        MatingRestriction
 }
 
 // Apply ModGenerational.
 func (mod ModGenerational) Apply(pop *Population) error {
+	var (
+		mutRate   = scheduledRate(mod.MutRate, mod.MutSchedule, pop.Generations)
+		crossRate = scheduledRate(mod.CrossRate, mod.CrossSchedule, pop.Generations)
+	)
 	// Generate as many offsprings as there are of individuals in the current population
 	var offsprings, err = generateOffsprings(
 		uint(len(pop.Individuals)),
+		defaultNParents(mod.NParents),
 		pop.Individuals,
 		mod.Selector,
-		mod.CrossRate,
+		mod.Mating,
+		crossRate,
 		pop.RNG,
 	)
 	if err != nil {
 		return err
 	}
 	// Apply mutation to the offsprings
-	if mod.MutRate > 0 {
-		offsprings.Mutate(mod.MutRate, pop.RNG)
+	if mutRate > 0 {
+		offsprings.Mutate(mutRate, pop.RNG)
 	}
 	// Replace the old population with the new one
 	copy(pop.Individuals, offsprings)
@@ -99,54 +122,81 @@ func (mod ModGenerational) Validate() error {
 	if mod.CrossRate < 0 || mod.CrossRate > 1 {
 		return errInvalidCrossRate
 	}
+	// Check the schedules
+	if err := validateSchedules(mod.MutSchedule, mod.CrossSchedule); err != nil {
+		return err
+	}
+	// Check the number of parents
+	if mod.NParents == 1 {
+		return errInvalidNParents
+	}
+	// Check the mating restriction parameters
+	if mod.Mating != nil {
+		if err := mod.Mating.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ModSteadyState implements the steady state model.
+// ModSteadyState implements the steady state model. NParents individuals,
+// 2 if it is 0, are selected and replaced by their offsprings. If KeepBest is
+// true then they are replaced by the best individuals among the parents and
+// the offsprings instead. If Mating isn't nil then it chooses the mates of the
+// individual selected with Selector. If MutSchedule or CrossSchedule isn't nil
+// then it overrides MutRate or CrossRate.
 type ModSteadyState struct {
-	Selector  Selector
-	KeepBest  bool
-	MutRate   float64
-	CrossRate float64
+	Selector      Selector
+	KeepBest      bool
+	MutRate       float64
+	CrossRate     float64
+	MutSchedule   Schedule
+	CrossSchedule Schedule
+	NParents      uint
+	Mating        MatingRestriction
 }
 
 // Apply ModSteadyState.
 func (mod ModSteadyState) Apply(pop *Population) error {
-	var selected, indexes, err = mod.Selector.Apply(2, pop.Individuals, pop.RNG)
+	var selected, indexes, err = selectParents(defaultNParents(mod.NParents), pop.Individuals, mod.Selector,
+		mod.Mating, pop.RNG)
 	if err != nil {
 		return err
 	}
-	var offsprings = selected.Clone(pop.RNG)
-	if pop.RNG.Float64() < mod.CrossRate {
-		offsprings[0].Crossover(offsprings[1], pop.RNG)
+	var (
+		offsprings = selected.Clone(pop.RNG)
+		mutRate    = scheduledRate(mod.MutRate, mod.MutSchedule, pop.Generations)
+	)
+	if pop.RNG.Float64() < scheduledRate(mod.CrossRate, mod.CrossSchedule, pop.Generations) {
+		if offsprings, err = crossover(offsprings, pop.RNG); err != nil {
+			return err
+		}
 	}
 	// Apply mutation to the offsprings
-	if mod.MutRate > 0 {
-		if pop.RNG.Float64() < mod.MutRate {
-			offsprings[0].Mutate(pop.RNG)
-		}
-		if pop.RNG.Float64() < mod.MutRate {
-			offsprings[1].Mutate(pop.RNG)
+	if mutRate > 0 {
+		for i := range offsprings {
+			if pop.RNG.Float64() < mutRate {
+				offsprings[i].Mutate(pop.RNG)
+			}
 		}
 	}
 	if mod.KeepBest {
 		// Replace the chosen individuals with the best individuals
-		err = offsprings[0].Evaluate()
+		err = offsprings.Evaluate(false)
 		if err != nil {
 			return err
 		}
-		err = offsprings[1].Evaluate()
-		if err != nil {
-			return err
+		var indis = append(selected, offsprings...)
+		sort.SliceStable(indis, func(i, j int) bool { return indis[i].Better(indis[j]) })
+		for i, idx := range indexes {
+			pop.Individuals[idx] = indis[i]
 		}
-		var indis = Individuals{selected[0], selected[1], offsprings[0], offsprings[1]}
-		indis.SortByFitness()
-		pop.Individuals[indexes[0]] = indis[0]
-		pop.Individuals[indexes[1]] = indis[1]
 	} else {
-		// Replace the chosen parents with the offsprings
-		pop.Individuals[indexes[0]] = offsprings[0]
-		pop.Individuals[indexes[1]] = offsprings[1]
+		// Replace the chosen parents with the offsprings, parents are kept if
+		// there are fewer offsprings
+		for i := 0; i < len(offsprings) && i < len(indexes); i++ {
+			pop.Individuals[indexes[i]] = offsprings[i]
+		}
 	}
 	return nil
 }
@@ -170,33 +220,60 @@ func (mod ModSteadyState) Validate() error {
 	if mod.CrossRate < 0 || mod.CrossRate > 1 {
 		return errInvalidCrossRate
 	}
+	// Check the schedules
+	if err := validateSchedules(mod.MutSchedule, mod.CrossSchedule); err != nil {
+		return err
+	}
+	// Check the number of parents
+	if mod.NParents == 1 {
+		return errInvalidNParents
+	}
+	// Check the mating restriction parameters
+	if mod.Mating != nil {
+		if err := mod.Mating.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ModDownToSize implements the select down to size model.
+// ModDownToSize implements the select down to size model. NParents is the
+// number of parents recombined at once, 2 if it is 0. If Mating isn't nil then
+// it chooses the mates of each parent selected with SelectorA. If MutSchedule
+// or CrossSchedule isn't nil then it overrides MutRate or CrossRate.
 type ModDownToSize struct {
-	NOffsprings uint
-	SelectorA   Selector
-	SelectorB   Selector
-	MutRate     float64
-	CrossRate   float64
+	NOffsprings   uint
+	SelectorA     Selector
+	SelectorB     Selector
+	MutRate       float64
+	CrossRate     float64
+	MutSchedule   Schedule
+	CrossSchedule Schedule
+	NParents      uint
+	Mating        MatingRestriction
 }
 
 // Apply ModDownToSize.
 func (mod ModDownToSize) Apply(pop *Population) error {
+	var (
+		mutRate   = scheduledRate(mod.MutRate, mod.MutSchedule, pop.Generations)
+		crossRate = scheduledRate(mod.CrossRate, mod.CrossSchedule, pop.Generations)
+	)
 	var offsprings, err = generateOffsprings(
 		mod.NOffsprings,
+		defaultNParents(mod.NParents),
 		pop.Individuals,
 		mod.SelectorA,
-		mod.CrossRate,
+		mod.Mating,
+		crossRate,
 		pop.RNG,
 	)
 	if err != nil {
 		return err
 	}
 	// Apply mutation to the offsprings
-	if mod.MutRate > 0 {
-		offsprings.Mutate(mod.MutRate, pop.RNG)
+	if mutRate > 0 {
+		offsprings.Mutate(mutRate, pop.RNG)
 	}
 	err = offsprings.Evaluate(false)
 	if err != nil {
@@ -239,43 +316,57 @@ func (mod ModDownToSize) Validate() error {
 	if mod.MutRate < 0 || mod.MutRate > 1 {
 		return errInvalidMutRate
 	}
+	// Check the schedules
+	if err := validateSchedules(mod.MutSchedule, mod.CrossSchedule); err != nil {
+		return err
+	}
+	// Check the number of parents
+	if mod.NParents == 1 {
+		return errInvalidNParents
+	}
+	// Check the mating restriction parameters
+	if mod.Mating != nil {
+		if err := mod.Mating.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ModRing implements the island ring model.
+// ModRing implements the island ring model. If MutSchedule isn't nil then it
+// overrides MutRate.
 type ModRing struct {
-	Selector Selector
-	MutRate  float64
+	Selector    Selector
+	MutRate     float64
+	MutSchedule Schedule
 }
 
 // Apply ModRing.
 func (mod ModRing) Apply(pop *Population) error {
+	var mutRate = scheduledRate(mod.MutRate, mod.MutSchedule, pop.Generations)
 	for i := range pop.Individuals {
-		var (
-			indi      = pop.Individuals[i].Clone(pop.RNG)
-			neighbour = pop.Individuals[(i+1)%len(pop.Individuals)]
+		var offsprings, err = crossover(
+			Individuals{pop.Individuals[i].Clone(pop.RNG), pop.Individuals[(i+1)%len(pop.Individuals)]},
+			pop.RNG,
 		)
-		indi.Crossover(neighbour, pop.RNG)
-		// Apply mutation to the offsprings
-		if mod.MutRate > 0 {
-			if pop.RNG.Float64() < mod.MutRate {
-				indi.Mutate(pop.RNG)
-			}
-			if pop.RNG.Float64() < mod.MutRate {
-				neighbour.Mutate(pop.RNG)
-			}
-		}
-		err := indi.Evaluate()
 		if err != nil {
 			return err
 		}
-		err = neighbour.Evaluate()
+		// Apply mutation to the offsprings
+		if mutRate > 0 {
+			for j := range offsprings {
+				if pop.RNG.Float64() < mutRate {
+					offsprings[j].Mutate(pop.RNG)
+				}
+			}
+		}
+		err = offsprings.Evaluate(false)
 		if err != nil {
 			return err
 		}
 		// Select an individual out of the original individual and the
 		// offsprings
-		indis := Individuals{pop.Individuals[i], indi, neighbour}
+		indis := append(Individuals{pop.Individuals[i]}, offsprings...)
 		selected, _, err := mod.Selector.Apply(1, indis, pop.RNG)
 		if err != nil {
 			return err
@@ -300,7 +391,8 @@ func (mod ModRing) Validate() error {
 	if mod.MutRate < 0 || mod.MutRate > 1 {
 		return errInvalidMutRate
 	}
-	return nil
+	// Check the schedule
+	return validateSchedules(mod.MutSchedule)
 }
 
 // ModMutationOnly implements the mutation only model. Each generation, all the
@@ -319,7 +411,7 @@ func (mod ModMutationOnly) Apply(pop *Population) error {
 		if err != nil {
 			return err
 		}
-		if !mod.Strict || (mod.Strict && mutant.Fitness < indi.Fitness) {
+		if !mod.Strict || (mod.Strict && mutant.Better(indi)) {
 			pop.Individuals[i] = mutant
 		}
 	}
@@ -341,13 +433,31 @@ type SAAcceptance func(gen, nGen uint, e0, e1 float64) float64
 // parent.  If the mutation is favorable it always replaces its parent.  If the
 // mutation is unfavorable, it is more likely to replace its parent earlier
 // than later in the evolution.
+//
+// The probability of accepting an unfavorable mutation is given by Accept. If
+// Accept is nil then the Metropolis criterion exp(-(e1-e0)/T) is used instead,
+// the temperature T starting at T0 and being multiplied by Cooling after each
+// generation. The temperature and the acceptance statistics are stored in the
+// Population's SAState, hence a run resumed from JSON carries on with the
+// cooling schedule.
 type ModSimulatedAnnealing struct {
-	GA     *GA          // Pointer to the encompassing GA, set by NewGA and needed to gauge progress toward completion
-	Accept SAAcceptance // Badness acceptance function
+	GA      *GA          // Pointer to the encompassing GA, set by NewGA and needed to gauge progress toward completion
+	Accept  SAAcceptance // Badness acceptance function
+	T0      float64      // Initial temperature, used if Accept is nil
+	Cooling float64      // Geometric cooling factor, used if Accept is nil
 }
 
 // Apply ModSimulatedAnnealing.
 func (mod ModSimulatedAnnealing) Apply(pop *Population) error {
+	// Cool down once per generation, the state is shared by the species of the
+	// Population
+	if pop.SAState == nil {
+		pop.SAState = &SAState{Temperature: mod.T0, Generation: pop.Generations}
+	}
+	var state = pop.SAState
+	for ; state.Generation < pop.Generations; state.Generation++ {
+		state.Temperature *= mod.Cooling
+	}
 	for i, indi := range pop.Individuals {
 		// Mutate the individual.
 		var mutant = indi.Clone(pop.RNG)
@@ -356,29 +466,73 @@ func (mod ModSimulatedAnnealing) Apply(pop *Population) error {
 		if err != nil {
 			return err
 		}
+		state.Proposed++
 
 		// Decide whether to keep the original or its mutation
 		prob := 1.0
-		if mutant.Fitness > indi.Fitness && mod.GA != nil {
-			prob = mod.Accept(mod.GA.Generations,
-				mod.GA.GAConfig.NGenerations,
-				indi.Fitness,
-				mutant.Fitness)
+		if mutant.Fitness > indi.Fitness {
+			if mod.Accept == nil {
+				prob = metropolis(indi.Fitness, mutant.Fitness, state.Temperature)
+			} else if mod.GA != nil {
+				prob = mod.Accept(mod.GA.Generations,
+					mod.GA.GAConfig.NGenerations,
+					indi.Fitness,
+					mutant.Fitness)
+			}
 		}
 		if prob > pop.RNG.Float64() {
 			pop.Individuals[i] = mutant
+			state.Accepted++
+			if mutant.Fitness > indi.Fitness {
+				state.Uphill++
+			}
 		}
 	}
 	return nil
 }
 
+// metropolis returns the probability of accepting an energy increase from e0
+// to e1 at temperature t.
+func metropolis(e0, e1, t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Exp(-(e1 - e0) / t)
+}
+
 // Validate ModSimulatedAnnealing fields.
 func (mod ModSimulatedAnnealing) Validate() error {
 	// Ideally, we would check that GA is not nil.  Unfortunately, Validate
 	// may be called before NewGA has a chance to initialize that field so
-	// all we can check is the Accept field.
-	if mod.Accept == nil {
-		return errors.New("an Accept function must be provided to ModSimulatedAnnealing")
+	// all we can check is the Accept field or the cooling schedule.
+	if mod.Accept != nil {
+		return nil
+	}
+	if mod.T0 <= 0 {
+		return errors.New("an Accept function or a positive T0 must be provided to ModSimulatedAnnealing")
+	}
+	if mod.Cooling <= 0 || mod.Cooling > 1 {
+		return errors.New("Cooling should be in (0, 1]")
 	}
 	return nil
 }
+
+// SAState is the state of the cooling schedule of a Population evolved with
+// ModSimulatedAnnealing. Generation is the Population generation the
+// Temperature corresponds to.
+type SAState struct {
+	Temperature float64 `json:"temperature"`
+	Generation  uint    `json:"generation"`
+	Proposed    uint64  `json:"proposed"` // Number of mutants evaluated
+	Accepted    uint64  `json:"accepted"` // Number of mutants which replaced their parent
+	Uphill      uint64  `json:"uphill"`   // Number of accepted mutants worse than their parent
+}
+
+// AcceptanceRate returns the proportion of mutants which replaced their
+// parent.
+func (s SAState) AcceptanceRate() float64 {
+	if s.Proposed == 0 {
+		return 0
+	}
+	return float64(s.Accepted) / float64(s.Proposed)
+}
@@ -1,7 +1,9 @@
 package eaopt
 
 import (
+	"encoding/json"
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -53,6 +55,8 @@ var (
 				return math.Exp(-3.0 * t)
 			},
 		},
+		ModSimulatedAnnealing{T0: 10, Cooling: 0.9},
+		ModTempering{Temperature: 1, MutRate: 0.5, CrossRate: 0.5, NMutations: 2},
 	}
 	// Invalid models
 	invalidModels = []Model{
@@ -141,6 +145,15 @@ var (
 			MutRate:  -1,
 		},
 		ModSimulatedAnnealing{},
+		ModSimulatedAnnealing{T0: 1},
+		ModSimulatedAnnealing{T0: 1, Cooling: 1.5},
+		ModTempering{},
+		ModTempering{Temperature: 1, MutRate: 2},
+		ModCultural{AcceptRate: 0.2, Influence: 0.5, Sigma: 0.5},
+		ModCultural{Model: ModIdentity{}, Influence: 0.5, Sigma: 0.5},
+		ModCultural{Model: ModIdentity{}, AcceptRate: 0.2, Influence: 1.5, Sigma: 0.5},
+		ModCultural{Model: ModIdentity{}, AcceptRate: 0.2, Influence: 0.5},
+		ModCultural{Model: ModTempering{}, AcceptRate: 0.2, Influence: 0.5, Sigma: 0.5},
 	}
 )
 
@@ -152,11 +165,19 @@ func TestGenerateOffsprings(t *testing.T) {
 		indis = newIndividuals(20, false, NewVector, rng)
 	)
 	for _, n := range []uint{0, 1, 3, 10} {
-		var offsprings, _ = generateOffsprings(n, indis, SelTournament{1}, 1.0, rng)
+		var offsprings, _ = generateOffsprings(n, 2, indis, SelTournament{1}, nil, 1.0, rng)
 		if len(offsprings) != int(n) {
 			t.Error("GenerateOffsprings didn't produce the expected number of offsprings")
 		}
 	}
+	// Both offsprings of a crossover have to be evaluated again
+	indis.Evaluate(false)
+	var offsprings, _ = generateOffsprings(10, 2, indis, SelTournament{1}, nil, 1.0, rng)
+	for _, offspring := range offsprings {
+		if offspring.Evaluated {
+			t.Error("Offsprings should not be marked as evaluated")
+		}
+	}
 }
 
 // TestModelsValidate checks that each model's Validate method doesn't return
@@ -193,3 +214,231 @@ func TestModelsConstantSize(t *testing.T) {
 		}
 	}
 }
+
+// A breederVector is a Vector whose crossover averages itself with its mates
+// into a given number of offsprings.
+type breederVector struct {
+	Vector
+	NOffsprings int
+}
+
+func (b breederVector) Breed(mates []Genome, rng *rand.Rand) []Genome {
+	var offsprings = make([]Genome, b.NOffsprings)
+	for i := range offsprings {
+		var child = b.Clone().(breederVector)
+		for _, mate := range mates {
+			for j := range child.Vector {
+				child.Vector[j] = (child.Vector[j] + mate.(breederVector).Vector[j]) / 2
+			}
+		}
+		offsprings[i] = child
+	}
+	return offsprings
+}
+
+func (b breederVector) Clone() Genome {
+	return breederVector{Vector: b.Vector.Clone().(Vector), NOffsprings: b.NOffsprings}
+}
+
+func TestModelsBreeder(t *testing.T) {
+	var rng = newRand()
+	for _, n := range []int{1, 2, 3} {
+		var newGenome = func(rng *rand.Rand) Genome {
+			return breederVector{Vector: NewVector(rng).(Vector), NOffsprings: n}
+		}
+		// generateOffsprings discards the surplus offsprings
+		var (
+			indis           = newIndividuals(20, false, newGenome, rng)
+			offsprings, err = generateOffsprings(5, 2, indis, SelTournament{1}, nil, 1, rng)
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(offsprings) != 5 {
+			t.Errorf("Expected 5 offsprings, got %d", len(offsprings))
+		}
+		for _, offspring := range offsprings {
+			if offspring.Evaluated {
+				t.Error("Offsprings should not be marked as evaluated")
+			}
+		}
+		// The models keep the population size constant
+		for _, model := range []Model{
+			ModGenerational{Selector: SelTournament{2}, CrossRate: 1},
+			ModSteadyState{Selector: SelTournament{2}, CrossRate: 1},
+			ModSteadyState{Selector: SelTournament{2}, CrossRate: 1, KeepBest: true},
+			ModDownToSize{NOffsprings: 5, SelectorA: SelTournament{2}, SelectorB: SelElitism{}},
+			ModRing{Selector: SelTournament{2}},
+		} {
+			var pop = newPopulation(10, false, newGenome, rng)
+			pop.Individuals.Evaluate(false)
+			for i := 0; i < 3; i++ {
+				if err = model.Apply(&pop); err != nil {
+					t.Fatal(err)
+				}
+				if len(pop.Individuals) != 10 {
+					t.Errorf("Expected 10 individuals, got %d", len(pop.Individuals))
+				}
+			}
+		}
+	}
+}
+
+func TestBreedMetadata(t *testing.T) {
+	var (
+		rng  = newRand()
+		a    = NewIndividual(breederVector{Vector: Vector{0, 0}, NOffsprings: 2}, rng)
+		b    = NewIndividual(breederVector{Vector: Vector{2, 4}, NOffsprings: 2}, rng)
+		offs Individuals
+		err  error
+	)
+	a.Metadata = map[string]interface{}{"origin": "a"}
+	if offs, err = crossover(Individuals{a, b}, rng); err != nil {
+		t.Fatal(err)
+	}
+	for _, off := range offs {
+		if off.Metadata["origin"] != "a" {
+			t.Errorf("Expected the metadata of the first parent, got %v", off.Metadata)
+		}
+		if v := off.Genome.(breederVector).Vector; v[0] != 1 || v[1] != 2 {
+			t.Errorf("Expected [1 2], got %v", v)
+		}
+		if off.ID == a.ID || off.ID == b.ID {
+			t.Error("Offsprings should have a new ID")
+		}
+	}
+	// The parents are left untouched
+	if a.Genome.(breederVector).Vector[0] != 0 {
+		t.Error("The parent has been modified")
+	}
+	// Breeding nothing is an error
+	a.Genome = breederVector{Vector: Vector{0, 0}}
+	if _, err = crossover(Individuals{a, b}, rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestModelsNParents(t *testing.T) {
+	var (
+		rng       = newRand()
+		newGenome = func(rng *rand.Rand) Genome {
+			return breederVector{Vector: NewVector(rng).(Vector), NOffsprings: 1}
+		}
+	)
+	for _, model := range []Model{
+		ModGenerational{Selector: SelTournament{2}, CrossRate: 1, NParents: 4},
+		ModSteadyState{Selector: SelTournament{2}, CrossRate: 1, NParents: 4},
+		ModSteadyState{Selector: SelTournament{2}, CrossRate: 1, NParents: 4, KeepBest: true},
+		ModDownToSize{NOffsprings: 5, SelectorA: SelTournament{2}, SelectorB: SelElitism{}, CrossRate: 1, NParents: 4},
+	} {
+		if err := model.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		var pop = newPopulation(10, false, newGenome, rng)
+		pop.Individuals.Evaluate(false)
+		if err := model.Apply(&pop); err != nil {
+			t.Fatal(err)
+		}
+		if len(pop.Individuals) != 10 {
+			t.Errorf("Expected 10 individuals, got %d", len(pop.Individuals))
+		}
+		// Genomes which aren't Breeders can only recombine 2 parents
+		pop = newPopulation(10, false, NewVector, rng)
+		pop.Individuals.Evaluate(false)
+		if err := model.Apply(&pop); err == nil {
+			t.Error("Expected an error")
+		}
+	}
+	// The best parent breeds with the others
+	var (
+		parents = newIndividuals(3, false, newGenome, rng)
+		best    = parents[2]
+	)
+	parents[2].Fitness = -1
+	var offsprings, err = crossover(parents, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offsprings[0].Genome.(breederVector).Vector[0] == best.Genome.(breederVector).Vector[0] {
+		t.Error("The offspring should differ from the best parent")
+	}
+	if parents[0].ID != best.ID {
+		t.Error("The parents should be sorted by fitness")
+	}
+	// NParents can't be 1
+	for _, model := range []Model{
+		ModGenerational{Selector: SelTournament{2}, NParents: 1},
+		ModSteadyState{Selector: SelTournament{2}, NParents: 1},
+		ModDownToSize{NOffsprings: 5, SelectorA: SelTournament{2}, SelectorB: SelElitism{}, NParents: 1},
+	} {
+		if err := model.Validate(); err == nil {
+			t.Error("Expected an error")
+		}
+	}
+}
+
+func TestSimulatedAnnealingState(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 4
+	conf.NPops = 2
+	conf.Model = ModSimulatedAnnealing{T0: 8, Cooling: 0.5}
+	conf.Speciator = SpecFitnessInterval{K: 2}
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga1, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga1.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for _, pop := range ga1.Populations {
+		// The Population has been cooled down after each of the first 3
+		// generations, once even though it is split into species
+		var state = pop.SAState
+		if state.Temperature != 1 || state.Generation != 3 {
+			t.Errorf("Expected a temperature of 1 at generation 3, got %f at %d", state.Temperature, state.Generation)
+		}
+		if state.Proposed != 4*uint64(conf.PopSize) || state.Accepted > state.Proposed || state.Uphill > state.Accepted {
+			t.Errorf("Inconsistent statistics %+v", *state)
+		}
+		if r := state.AcceptanceRate(); r != float64(state.Accepted)/float64(state.Proposed) {
+			t.Errorf("Wrong acceptance rate %f", r)
+		}
+	}
+	// A resumed run carries on with the cooling schedule
+	var b []byte
+	if b, err = json.Marshal(ga1); err != nil {
+		t.Fatal(err)
+	}
+	ga2, err := conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga2.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	ga2.NGenerations = 2
+	if err = ga2.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	for i, pop := range ga2.Populations {
+		if pop.SAState.Temperature != 0.25 || pop.SAState.Proposed != 6*uint64(conf.PopSize) {
+			t.Errorf("Expected the schedule to be resumed, got %+v", *pop.SAState)
+		}
+		if pop.SAState.Accepted < ga1.Populations[i].SAState.Accepted {
+			t.Error("The statistics should be resumed")
+		}
+	}
+	if (SAState{}).AcceptanceRate() != 0 {
+		t.Error("Expected 0")
+	}
+}
+
+func TestMetropolis(t *testing.T) {
+	if p := metropolis(1, 2, 1); math.Abs(p-math.Exp(-1)) > 1e-12 {
+		t.Errorf("Expected %f, got %f", math.Exp(-1), p)
+	}
+	if p := metropolis(1, 2, 0); p != 0 {
+		t.Errorf("Expected 0, got %f", p)
+	}
+}
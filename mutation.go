@@ -85,3 +85,105 @@ func MutSpliceFloat64(s []float64, rng *rand.Rand) {
 func MutSpliceString(s []string, rng *rand.Rand) {
 	MutSplice(StringSlice(s), rng)
 }
+
+// Mutations for variable-length slices, they return a new Slice and leave the
+// genome untouched
+
+// MutInsert inserts the genes returned by newGene, usually a single one, at a
+// random position. The genome is returned as it is if the insertion would
+// make it longer than bounds.Max.
+func MutInsert(genome Slice, newGene func(rng *rand.Rand) Slice, bounds LengthBounds, rng *rand.Rand) Slice {
+	var gene = newGene(rng)
+	if bounds.Max > 0 && genome.Len()+gene.Len() > int(bounds.Max) {
+		return genome
+	}
+	var head, tail = genome.Split(rng.Intn(genome.Len() + 1))
+	return concatSlices(head, gene, tail)
+}
+
+// MutInsertInt calls MutInsert on an int slice.
+func MutInsertInt(s []int, newGene func(rng *rand.Rand) int, bounds LengthBounds, rng *rand.Rand) []int {
+	return MutInsert(IntSlice(s), func(rng *rand.Rand) Slice {
+		return IntSlice{newGene(rng)}
+	}, bounds, rng).(IntSlice)
+}
+
+// MutInsertFloat64 calls MutInsert on a float64 slice.
+func MutInsertFloat64(s []float64, newGene func(rng *rand.Rand) float64, bounds LengthBounds,
+	rng *rand.Rand) []float64 {
+	return MutInsert(Float64Slice(s), func(rng *rand.Rand) Slice {
+		return Float64Slice{newGene(rng)}
+	}, bounds, rng).(Float64Slice)
+}
+
+// MutInsertString calls MutInsert on a string slice.
+func MutInsertString(s []string, newGene func(rng *rand.Rand) string, bounds LengthBounds,
+	rng *rand.Rand) []string {
+	return MutInsert(StringSlice(s), func(rng *rand.Rand) Slice {
+		return StringSlice{newGene(rng)}
+	}, bounds, rng).(StringSlice)
+}
+
+// MutDelete removes a gene at random. The genome is returned as it is if it
+// doesn't have more than bounds.Min genes.
+func MutDelete(genome Slice, bounds LengthBounds, rng *rand.Rand) Slice {
+	var n = genome.Len()
+	if n == 0 || n <= int(bounds.Min) {
+		return genome
+	}
+	var i = rng.Intn(n)
+	return concatSlices(genome.Slice(0, i), genome.Slice(i+1, n))
+}
+
+// MutDeleteInt calls MutDelete on an int slice.
+func MutDeleteInt(s []int, bounds LengthBounds, rng *rand.Rand) []int {
+	return MutDelete(IntSlice(s), bounds, rng).(IntSlice)
+}
+
+// MutDeleteFloat64 calls MutDelete on a float64 slice.
+func MutDeleteFloat64(s []float64, bounds LengthBounds, rng *rand.Rand) []float64 {
+	return MutDelete(Float64Slice(s), bounds, rng).(Float64Slice)
+}
+
+// MutDeleteString calls MutDelete on a string slice.
+func MutDeleteString(s []string, bounds LengthBounds, rng *rand.Rand) []string {
+	return MutDelete(StringSlice(s), bounds, rng).(StringSlice)
+}
+
+// MutDuplicateSegment copies a random segment of the genome and inserts the
+// copy at a random position. The segment is short enough for the genome to
+// stay within bounds.Max, the genome is returned as it is if it is empty or
+// already has bounds.Max genes.
+func MutDuplicateSegment(genome Slice, bounds LengthBounds, rng *rand.Rand) Slice {
+	var (
+		n      = genome.Len()
+		maxLen = n
+	)
+	if bounds.Max > 0 && int(bounds.Max)-n < maxLen {
+		maxLen = int(bounds.Max) - n
+	}
+	if maxLen < 1 {
+		return genome
+	}
+	var (
+		l          = 1 + rng.Intn(maxLen)
+		a          = rng.Intn(n - l + 1)
+		head, tail = genome.Split(rng.Intn(n + 1))
+	)
+	return concatSlices(head, genome.Slice(a, a+l), tail)
+}
+
+// MutDuplicateSegmentInt calls MutDuplicateSegment on an int slice.
+func MutDuplicateSegmentInt(s []int, bounds LengthBounds, rng *rand.Rand) []int {
+	return MutDuplicateSegment(IntSlice(s), bounds, rng).(IntSlice)
+}
+
+// MutDuplicateSegmentFloat64 calls MutDuplicateSegment on a float64 slice.
+func MutDuplicateSegmentFloat64(s []float64, bounds LengthBounds, rng *rand.Rand) []float64 {
+	return MutDuplicateSegment(Float64Slice(s), bounds, rng).(Float64Slice)
+}
+
+// MutDuplicateSegmentString calls MutDuplicateSegment on a string slice.
+func MutDuplicateSegmentString(s []string, bounds LengthBounds, rng *rand.Rand) []string {
+	return MutDuplicateSegment(StringSlice(s), bounds, rng).(StringSlice)
+}
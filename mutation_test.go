@@ -1,6 +1,7 @@
 package eaopt
 
 import (
+	"math/rand"
 	"testing"
 )
 
@@ -229,3 +230,78 @@ func TestMutSpliceString(t *testing.T) {
 		}
 	}
 }
+
+func TestMutInsert(t *testing.T) {
+	var (
+		rng    = newRand()
+		genome = []float64{1, 2, 3}
+		gene   = func(rng *rand.Rand) float64 { return 42 }
+	)
+	var mutated = MutInsertFloat64(genome, gene, LengthBounds{}, rng)
+	if len(mutated) != 4 || !sliceContainsFloat64(42, mutated) {
+		t.Errorf("Expected 42 to be inserted, got %v", mutated)
+	}
+	if len(genome) != 3 || genome[0] != 1 || genome[1] != 2 || genome[2] != 3 {
+		t.Errorf("The genome was modified: %v", genome)
+	}
+	if mutated = MutInsertFloat64(genome, gene, LengthBounds{Max: 3}, rng); len(mutated) != 3 {
+		t.Errorf("Expected the genome to stay within bounds, got %v", mutated)
+	}
+	if ints := MutInsertInt(nil, func(rng *rand.Rand) int { return 1 }, LengthBounds{}, rng); len(ints) != 1 {
+		t.Errorf("Expected an insertion into an empty genome, got %v", ints)
+	}
+	if strs := MutInsertString([]string{"a"}, func(rng *rand.Rand) string { return "b" }, LengthBounds{}, rng); len(strs) != 2 {
+		t.Errorf("Expected 2 genes, got %v", strs)
+	}
+}
+
+func TestMutDelete(t *testing.T) {
+	var (
+		rng    = newRand()
+		genome = []int{1, 2, 3}
+	)
+	var mutated = MutDeleteInt(genome, LengthBounds{}, rng)
+	if len(mutated) != 2 {
+		t.Errorf("Expected 2 genes, got %v", mutated)
+	}
+	for _, x := range mutated {
+		if !sliceContainsInt(x, genome) {
+			t.Errorf("Unexpected gene %d", x)
+		}
+	}
+	if genome[0] != 1 || genome[1] != 2 || genome[2] != 3 {
+		t.Errorf("The genome was modified: %v", genome)
+	}
+	if mutated = MutDeleteInt(genome, LengthBounds{Min: 3}, rng); len(mutated) != 3 {
+		t.Errorf("Expected the genome to stay within bounds, got %v", mutated)
+	}
+	if floats := MutDeleteFloat64(nil, LengthBounds{}, rng); len(floats) != 0 {
+		t.Errorf("Expected an empty genome, got %v", floats)
+	}
+	if strs := MutDeleteString([]string{"a"}, LengthBounds{}, rng); len(strs) != 0 {
+		t.Errorf("Expected an empty genome, got %v", strs)
+	}
+}
+
+func TestMutDuplicateSegment(t *testing.T) {
+	var rng = newRand()
+	for i := 0; i < 20; i++ {
+		var (
+			genome  = []string{"a", "b", "c", "d"}
+			mutated = MutDuplicateSegmentString(genome, LengthBounds{Max: 6}, rng)
+		)
+		if len(mutated) < 5 || len(mutated) > 6 {
+			t.Errorf("Expected 5 or 6 genes, got %v", mutated)
+		}
+		if genome[0] != "a" || genome[3] != "d" {
+			t.Errorf("The genome was modified: %v", genome)
+		}
+	}
+	if ints := MutDuplicateSegmentInt([]int{1, 2}, LengthBounds{Max: 2}, rng); len(ints) != 2 {
+		t.Errorf("Expected the genome to stay within bounds, got %v", ints)
+	}
+	if floats := MutDuplicateSegmentFloat64([]float64{1}, LengthBounds{}, rng); len(floats) != 2 ||
+		floats[0] != 1 || floats[1] != 1 {
+		t.Errorf("Expected the gene to be duplicated, got %v", floats)
+	}
+}
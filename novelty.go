@@ -0,0 +1,333 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// A BehaviorDescriptor is a Genome that can describe its behavior, for
+// instance the final position of a robot controlled by the Genome, as a vector
+// of values. Novelty search rewards Individuals whose behavior differs from
+// the behaviors encountered so far, see GAConfig.Novelty.
+type BehaviorDescriptor interface {
+	Behavior() []float64
+}
+
+// A NoveltyMode determines how the novelty of an Individual and the fitness
+// returned by its Genome are combined into the fitness used for selection.
+type NoveltyMode int
+
+const (
+	// NoveltyOnly ignores the objective, the fitness is minus the novelty.
+	NoveltyOnly NoveltyMode = iota
+	// NoveltyWeighted minimizes a weighted sum of the objective and of minus
+	// the novelty, both rescaled between 0 and 1 within each Population.
+	NoveltyWeighted
+	// NoveltyPareto ranks the Individuals by Pareto dominance on the objective
+	// and the novelty, ties being broken by crowding distance as in NSGA-II.
+	// The fitness is the rank of the front of the Individual plus a value in
+	// (0, 1] which is lower for less crowded Individuals.
+	NoveltyPareto
+)
+
+// NoveltyOptions enable novelty search. The novelty of an Individual is the
+// average Euclidean distance between its behavior and the K nearest behaviors
+// among the other Individuals of its Population and the archive, K being 15
+// if it is 0. Each newly evaluated Individual enters the archive with
+// probability ArchiveProb, 0 meaning 0.05, and the oldest behaviors are
+// discarded once the archive holds ArchiveSize behaviors, 0 meaning no limit.
+// Weight is the weight of the novelty with NoveltyWeighted, 0 meaning 0.5.
+//
+// The Genomes have to implement BehaviorDescriptor. The value returned by
+// Genome.Evaluate is stored in the Objective field of the Individuals and
+// their Fitness is replaced according to Mode, hence the hall of fame ranks
+// the Individuals by novelty. GA.BestObjective returns the Individual with
+// the best objective encountered.
+type NoveltyOptions struct {
+	Mode        NoveltyMode
+	K           uint
+	ArchiveProb float64
+	ArchiveSize uint
+	Weight      float64
+}
+
+// Validate the options.
+func (no NoveltyOptions) Validate() error {
+	if no.Mode < NoveltyOnly || no.Mode > NoveltyPareto {
+		return fmt.Errorf("unknown NoveltyMode %d", no.Mode)
+	}
+	if no.ArchiveProb < 0 || no.ArchiveProb > 1 {
+		return errors.New("ArchiveProb should be between 0 and 1")
+	}
+	if no.Weight < 0 || no.Weight > 1 {
+		return errors.New("Weight should be between 0 and 1")
+	}
+	return nil
+}
+
+// k returns K or its default value.
+func (no NoveltyOptions) k() int {
+	if no.K == 0 {
+		return 15
+	}
+	return int(no.K)
+}
+
+// archiveProb returns ArchiveProb or its default value.
+func (no NoveltyOptions) archiveProb() float64 {
+	if no.ArchiveProb == 0 {
+		return 0.05
+	}
+	return no.ArchiveProb
+}
+
+// weight returns Weight or its default value.
+func (no NoveltyOptions) weight() float64 {
+	if no.Weight == 0 {
+		return 0.5
+	}
+	return no.Weight
+}
+
+// noveltyState holds the archive of behaviors and the Individual with the best
+// objective. It is guarded by a lock because the Populations share it.
+type noveltyState struct {
+	mutex   sync.Mutex
+	archive [][]float64
+	best    Individual
+	found   bool
+}
+
+// reset empties the archive.
+func (ns *noveltyState) reset() {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.archive = nil
+	ns.best = Individual{}
+	ns.found = false
+}
+
+// NoveltyArchive returns a copy of the behaviors in the novelty archive, from
+// the oldest to the newest.
+func (ga *GA) NoveltyArchive() [][]float64 {
+	if ga.novelty == nil {
+		return nil
+	}
+	ga.novelty.mutex.Lock()
+	defer ga.novelty.mutex.Unlock()
+	var archive = make([][]float64, len(ga.novelty.archive))
+	for i, b := range ga.novelty.archive {
+		archive[i] = copyFloat64s(b)
+	}
+	return archive
+}
+
+// BestObjective returns the Individual with the best objective encountered
+// during novelty search, its Fitness being its objective. The boolean is false
+// if the Novelty option isn't set or if no Individual has been evaluated.
+func (ga *GA) BestObjective() (Individual, bool) {
+	if ga.novelty == nil {
+		return Individual{}, false
+	}
+	ga.novelty.mutex.Lock()
+	defer ga.novelty.mutex.Unlock()
+	return ga.novelty.best, ga.novelty.found
+}
+
+// unevaluated returns which Individuals haven't been evaluated yet.
+func unevaluated(indis Individuals) []bool {
+	var fresh = make([]bool, len(indis))
+	for i, indi := range indis {
+		fresh[i] = !indi.Evaluated
+	}
+	return fresh
+}
+
+// knnDistance returns the average distance between b and its k nearest
+// neighbors among others, skipping the skip-th behavior.
+func knnDistance(b []float64, others [][]float64, skip, k int) float64 {
+	var dists = make([]float64, 0, len(others))
+	for i, o := range others {
+		if i != skip {
+			dists = append(dists, euclidean(b, o))
+		}
+	}
+	if len(dists) == 0 {
+		return 0
+	}
+	sort.Float64s(dists)
+	k = minInt(k, len(dists))
+	var sum float64
+	for _, d := range dists[:k] {
+		sum += d
+	}
+	return sum / float64(k)
+}
+
+// scoreNovelty measures the novelty of the Individuals, archives some of the
+// fresh ones, which have just been evaluated, and replaces their fitness
+// according to the Novelty options. The Individuals aren't sorted.
+func (ga *GA) scoreNovelty(indis Individuals, fresh []bool, rng *rand.Rand) error {
+	if len(indis) == 0 {
+		return nil
+	}
+	var behaviors = make([][]float64, len(indis))
+	for i, indi := range indis {
+		var bd, ok = indi.Genome.(BehaviorDescriptor)
+		if !ok {
+			return fmt.Errorf("%T doesn't implement BehaviorDescriptor", indi.Genome)
+		}
+		behaviors[i] = bd.Behavior()
+		if fresh[i] {
+			indis[i].Objective = indi.Fitness
+		}
+	}
+	var (
+		opts = ga.Novelty
+		ns   = ga.novelty
+	)
+	ns.mutex.Lock()
+	var others = append(append([][]float64{}, behaviors...), ns.archive...)
+	for i := range indis {
+		indis[i].Novelty = knnDistance(behaviors[i], others, i, opts.k())
+		if !fresh[i] {
+			continue
+		}
+		if rng.Float64() < opts.archiveProb() {
+			ns.archive = append(ns.archive, copyFloat64s(behaviors[i]))
+		}
+		var candidate = indis[i]
+		candidate.Fitness = candidate.Objective
+		if !ns.found || candidate.Better(ns.best) {
+			ns.best = candidate.Clone(rng)
+			ns.found = true
+		}
+	}
+	if opts.ArchiveSize > 0 && len(ns.archive) > int(opts.ArchiveSize) {
+		ns.archive = ns.archive[len(ns.archive)-int(opts.ArchiveSize):]
+	}
+	ns.mutex.Unlock()
+
+	switch opts.Mode {
+	case NoveltyOnly:
+		for i := range indis {
+			indis[i].Fitness = -indis[i].Novelty
+		}
+	case NoveltyWeighted:
+		var (
+			objectives = rescale(indis, func(indi Individual) float64 { return indi.Objective })
+			novelties  = rescale(indis, func(indi Individual) float64 { return indi.Novelty })
+			w          = opts.weight()
+		)
+		for i := range indis {
+			indis[i].Fitness = (1-w)*objectives[i] - w*novelties[i]
+		}
+	case NoveltyPareto:
+		var points = make([][]float64, len(indis))
+		for i, indi := range indis {
+			points[i] = []float64{indi.Objective, -indi.Novelty}
+		}
+		for i, f := range paretoFitnesses(points) {
+			indis[i].Fitness = f
+		}
+	}
+	return nil
+}
+
+// rescale returns the values of the Individuals rescaled between 0 and 1, or
+// 0s if they are all equal.
+func rescale(indis Individuals, value func(Individual) float64) []float64 {
+	var (
+		values = make([]float64, len(indis))
+		lo, hi = math.Inf(1), math.Inf(-1)
+	)
+	for i, indi := range indis {
+		values[i] = value(indi)
+		lo = math.Min(lo, values[i])
+		hi = math.Max(hi, values[i])
+	}
+	for i := range values {
+		if hi > lo {
+			values[i] = (values[i] - lo) / (hi - lo)
+		} else {
+			values[i] = 0
+		}
+	}
+	return values
+}
+
+// paretoFitnesses returns a fitness for each point, all objectives being
+// minimized, which is the index of its non-dominated front plus 1 / (1 + d)
+// where d is its crowding distance within its front.
+func paretoFitnesses(points [][]float64) []float64 {
+	var (
+		fitnesses = make([]float64, len(points))
+		left      = make([]int, len(points))
+	)
+	for i := range left {
+		left[i] = i
+	}
+	for rank := 0; len(left) > 0; rank++ {
+		var front, rest []int
+		for _, i := range left {
+			var dominated bool
+			for _, j := range left {
+				if i != j && Dominates(points[j], points[i]) {
+					dominated = true
+					break
+				}
+			}
+			if dominated {
+				rest = append(rest, i)
+			} else {
+				front = append(front, i)
+			}
+		}
+		for k, d := range crowdingDistances(points, front) {
+			fitnesses[front[k]] = float64(rank) + 1/(1+d)
+		}
+		left = rest
+	}
+	return fitnesses
+}
+
+// crowdingDistances returns the crowding distance of each point of a front,
+// which is the sum over the objectives of the normalized distance between its
+// two neighbors. The extreme points have an infinite crowding distance.
+func crowdingDistances(points [][]float64, front []int) []float64 {
+	var dists = make([]float64, len(front))
+	if len(front) == 0 {
+		return dists
+	}
+	var order = make([]int, len(front))
+	for m := range points[front[0]] {
+		for k := range order {
+			order[k] = k
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return points[front[order[a]]][m] < points[front[order[b]]][m]
+		})
+		var (
+			lo    = points[front[order[0]]][m]
+			hi    = points[front[order[len(order)-1]]][m]
+			first = order[0]
+			last  = order[len(order)-1]
+		)
+		dists[first], dists[last] = math.Inf(1), math.Inf(1)
+		if hi == lo {
+			continue
+		}
+		for k := 1; k < len(order)-1; k++ {
+			var (
+				prev = points[front[order[k-1]]][m]
+				next = points[front[order[k+1]]][m]
+			)
+			dists[order[k]] += (next - prev) / (hi - lo)
+		}
+	}
+	return dists
+}
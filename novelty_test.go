@@ -0,0 +1,130 @@
+package eaopt
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// A behavioralVector is a Vector whose behavior is its first two values.
+type behavioralVector struct{ Vector }
+
+func (bv behavioralVector) Behavior() []float64 { return copyFloat64s(bv.Vector[:2]) }
+func (bv behavioralVector) Crossover(y Genome, rng *rand.Rand) {
+	bv.Vector.Crossover(y.(behavioralVector).Vector, rng)
+}
+func (bv behavioralVector) Clone() Genome { return behavioralVector{bv.Vector.Clone().(Vector)} }
+
+func newBehavioralVector(rng *rand.Rand) Genome {
+	return behavioralVector{NewVector(rng).(Vector)}
+}
+
+func TestNoveltySearch(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.PopSize = 30
+	conf.NGenerations = 30
+	conf.Novelty = &NoveltyOptions{K: 5, ArchiveProb: 0.2}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(newBehavioralVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var archive = ga.NoveltyArchive()
+	if len(archive) == 0 {
+		t.Fatal("Expected the archive to be filled")
+	}
+	// The behaviors spread beyond the initial bounds
+	var spread float64
+	for _, b := range archive {
+		spread = math.Max(spread, math.Max(math.Abs(b[0]), math.Abs(b[1])))
+	}
+	if spread <= 10 {
+		t.Errorf("Expected the behaviors to spread, got %f", spread)
+	}
+	var best, ok = ga.BestObjective()
+	if !ok {
+		t.Fatal("Expected a best Individual")
+	}
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			if f, _ := indi.Genome.Evaluate(); f != indi.Objective {
+				t.Errorf("Expected an objective of %f, got %f", f, indi.Objective)
+			}
+			if indi.Fitness != -indi.Novelty {
+				t.Errorf("Expected a fitness of %f, got %f", -indi.Novelty, indi.Fitness)
+			}
+			if indi.Objective < best.Fitness {
+				t.Errorf("%f is better than the best objective %f", indi.Objective, best.Fitness)
+			}
+		}
+	}
+	// The archive is bounded
+	conf.Novelty.ArchiveSize = 10
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(newBehavioralVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if n := len(ga.NoveltyArchive()); n != 10 {
+		t.Errorf("Expected 10 behaviors, got %d", n)
+	}
+}
+
+func TestNoveltyModes(t *testing.T) {
+	for _, mode := range []NoveltyMode{NoveltyWeighted, NoveltyPareto} {
+		var conf = NewDefaultGAConfig()
+		conf.PopSize = 30
+		conf.Novelty = &NoveltyOptions{Mode: mode}
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if err = ga.Minimize(newBehavioralVector); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		// The objective still matters
+		var best, _ = ga.BestObjective()
+		if best.Fitness > -20 {
+			t.Errorf("Mode %d: expected the objective to improve, got %f", mode, best.Fitness)
+		}
+	}
+}
+
+func TestParetoFitnesses(t *testing.T) {
+	var (
+		points    = [][]float64{{0, 0}, {1, 1}, {0, 1}, {2, -1}, {1, -0.5}}
+		fitnesses = paretoFitnesses(points)
+		expected  = []float64{0, 2, 1, 0, 1.0 / 3}
+	)
+	for i, f := range expected {
+		if math.Abs(fitnesses[i]-f) > 1e-9 {
+			t.Errorf("Expected %v, got %v", expected, fitnesses)
+			break
+		}
+	}
+}
+
+func TestNoveltyErrors(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	for _, opts := range []NoveltyOptions{
+		{Mode: NoveltyPareto + 1},
+		{ArchiveProb: 2},
+		{Mode: NoveltyWeighted, Weight: -1},
+	} {
+		conf.Novelty = &opts
+		if _, err := conf.NewGA(); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+	// The Genomes have to describe their behavior
+	conf.Novelty = &NoveltyOptions{}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,85 @@
+package eaopt
+
+// An Optimizer minimizes real-valued functions of nDims variables. It is
+// implemented by FloatGA, SPSO, DiffEvo, CMAES, CoopCoevolution, BayesOpt,
+// RandomSearch and LatinHypercube so that application code can switch between
+// algorithms. Config gives access to the configuration of the underlying GA,
+// for instance to set a Callback or an evaluation budget.
+type Optimizer interface {
+	Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error)
+	Config() *GAConfig
+}
+
+// FloatGA minimizes real-valued functions with a GA which evolves FloatVectors
+// bounded by Min and Max. MutRate and Sigma are the FloatProblem's mutation
+// parameters.
+type FloatGA struct {
+	Min, Max float64
+	MutRate  float64
+	Sigma    float64
+	GA       *GA
+}
+
+// NewFloatGA instantiates and returns a FloatGA whose GA is built from conf.
+func NewFloatGA(conf GAConfig, min, max, mutRate, sigma float64) (*FloatGA, error) {
+	var p = FloatProblem{
+		Lower:   []float64{min},
+		Upper:   []float64{max},
+		MutRate: mutRate,
+		Sigma:   sigma,
+		F:       func([]float64) (float64, error) { return 0, nil },
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		return nil, err
+	}
+	return &FloatGA{
+		Min:     min,
+		Max:     max,
+		MutRate: mutRate,
+		Sigma:   sigma,
+		GA:      ga,
+	}, nil
+}
+
+// NewDefaultFloatGA calls NewFloatGA with default values.
+func NewDefaultFloatGA() (*FloatGA, error) {
+	return NewFloatGA(NewDefaultGAConfig(), -5, 5, 0.5, 0.1)
+}
+
+// Minimize finds the minimum of a given real-valued function.
+func (fga *FloatGA) Minimize(f func([]float64) float64, nDims uint) ([]float64, float64, error) {
+	var p = &FloatProblem{
+		Lower:   repeatFloat64(fga.Min, nDims),
+		Upper:   repeatFloat64(fga.Max, nDims),
+		MutRate: fga.MutRate,
+		Sigma:   fga.Sigma,
+		F:       func(x []float64) (float64, error) { return f(x), nil },
+	}
+	if err := p.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if err := fga.GA.Minimize(p.NewGenome); err != nil {
+		return nil, 0, err
+	}
+	var best = fga.GA.HallOfFame[0]
+	return copyFloat64s(best.Genome.(*FloatVector).Values), best.Fitness, nil
+}
+
+// Config returns the configuration of the underlying GA.
+func (fga *FloatGA) Config() *GAConfig {
+	return &fga.GA.GAConfig
+}
+
+// Config returns the configuration of the underlying GA.
+func (pso *SPSO) Config() *GAConfig {
+	return &pso.GA.GAConfig
+}
+
+// Config returns the configuration of the underlying GA.
+func (de *DiffEvo) Config() *GAConfig {
+	return &de.GA.GAConfig
+}
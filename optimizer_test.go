@@ -0,0 +1,53 @@
+package eaopt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOptimizers(t *testing.T) {
+	var sphere = func(x []float64) (y float64) {
+		for _, xi := range x {
+			y += xi * xi
+		}
+		return
+	}
+	for name, newOptimizer := range map[string]func() (Optimizer, error){
+		"ga":    func() (Optimizer, error) { return NewDefaultFloatGA() },
+		"pso":   func() (Optimizer, error) { return NewDefaultSPSO() },
+		"de":    func() (Optimizer, error) { return NewDefaultDiffEvo() },
+		"cmaes": func() (Optimizer, error) { return NewDefaultCMAES() },
+	} {
+		var opt, err = newOptimizer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The configuration of the underlying GA can be changed
+		var generations uint
+		opt.Config().NGenerations = 20
+		opt.Config().RNG = newRand()
+		opt.Config().Callback = func(ga *GA) { generations = ga.Generations }
+		x, y, err := opt.Minimize(sphere, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(x) != 3 || math.Abs(sphere(x)-y) > 1e-12 || y > 1 {
+			t.Errorf("%s: unexpected minimum %f at %v", name, y, x)
+		}
+		if generations != 20 {
+			t.Errorf("%s: expected 20 generations, got %d", name, generations)
+		}
+	}
+}
+
+func TestNewFloatGAErrors(t *testing.T) {
+	if _, err := NewFloatGA(NewDefaultGAConfig(), 5, -5, 0.5, 0.1); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewFloatGA(NewDefaultGAConfig(), -5, 5, 2, 0.1); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := NewFloatGA(GAConfig{}, -5, 5, 0.5, 0.1); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,117 @@
+package eaopt
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+// A MultiObjective Genome exposes the values of the objectives it was
+// evaluated on, all of which are minimized, so that the GA can maintain the
+// Pareto front, see GAConfig.ParetoHallOfFame. Objectives is called after
+// Evaluate and typically returns values cached by Evaluate, whose fitness is
+// usually a scalarization of them, see Scalarizer.
+type MultiObjective interface {
+	Objectives() []float64
+}
+
+// errNotMultiObjective is returned when ParetoHallOfFame is set but the
+// Genomes don't implement MultiObjective.
+var errNotMultiObjective = errors.New("ParetoHallOfFame requires the Genomes to implement MultiObjective")
+
+// constrainedDominates returns true if a dominates b according to Deb's
+// feasibility rules: a feasible Individual dominates an infeasible one, an
+// infeasible Individual dominates those which violate the constraints more,
+// and feasible Individuals are compared with Dominates on their Objectives.
+func constrainedDominates(a, b Individual) bool {
+	switch {
+	case a.Violation <= 0 && b.Violation > 0:
+		return true
+	case a.Violation > 0 || b.Violation > 0:
+		return a.Violation < b.Violation
+	}
+	return Dominates(a.Objectives, b.Objectives)
+}
+
+// sameObjectives returns true if a and b have the same violation and
+// objectives.
+func sameObjectives(a, b Individual) bool {
+	if a.Violation != b.Violation || len(a.Objectives) != len(b.Objectives) {
+		return false
+	}
+	for i := range a.Objectives {
+		if a.Objectives[i] != b.Objectives[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// updateParetoFront adds clones of the Individuals of indis which aren't
+// dominated by any member of front, nor by each other, to front and removes
+// the members they dominate. Individuals whose objectives are already in
+// front are skipped, as are those which weren't evaluated. If front ends up
+// with more than max members, the most crowded ones are removed one at a
+// time. The updated front is returned sorted by fitness.
+func updateParetoFront(front, indis Individuals, max int, rng *rand.Rand) Individuals {
+	var changed bool
+	for _, indi := range indis {
+		if !indi.Evaluated || indi.Objectives == nil {
+			continue
+		}
+		var dominated bool
+		for _, member := range front {
+			if constrainedDominates(member, indi) || sameObjectives(member, indi) {
+				dominated = true
+				break
+			}
+		}
+		if dominated {
+			continue
+		}
+		var kept = front[:0]
+		for _, member := range front {
+			if !constrainedDominates(indi, member) {
+				kept = append(kept, member)
+			}
+		}
+		front = append(kept, indi.Clone(rng))
+		changed = true
+	}
+	if !changed {
+		return front
+	}
+	for max > 0 && len(front) > max {
+		var (
+			points = make([][]float64, len(front))
+			all    = make([]int, len(front))
+		)
+		for i, member := range front {
+			points[i] = member.Objectives
+			all[i] = i
+		}
+		var (
+			dists  = crowdingDistances(points, all)
+			amidst = 0
+		)
+		for i := range dists {
+			// Ties are broken by removing the worst Individual
+			if dists[i] < dists[amidst] || (dists[i] == dists[amidst] && front[amidst].Better(front[i])) {
+				amidst = i
+			}
+		}
+		front = append(front[:amidst], front[amidst+1:]...)
+	}
+	sort.SliceStable(front, func(i, j int) bool { return front[i].Better(front[j]) })
+	return front
+}
+
+// updateHallOfFame updates the GA's hall of fame with indis, which are
+// expected to be sorted unless the hall of fame is a Pareto front.
+func (ga *GA) updateHallOfFame(indis Individuals, rng *rand.Rand) {
+	if ga.ParetoHallOfFame {
+		ga.HallOfFame = updateParetoFront(ga.HallOfFame, indis, int(ga.HofSize), rng)
+		return
+	}
+	updateHallOfFame(ga.HallOfFame, indis, rng)
+}
@@ -0,0 +1,298 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// biObjectiveVector minimizes the squared distances to the origin and to
+// (2, 2, 2, 2) at the same time.
+type biObjectiveVector struct{ Vector }
+
+func (bv biObjectiveVector) Objectives() []float64 {
+	var a, b float64
+	for _, x := range bv.Vector {
+		a += x * x
+		b += (x - 2) * (x - 2)
+	}
+	return []float64{a, b}
+}
+func (bv biObjectiveVector) Evaluate() (float64, error) {
+	var objs = bv.Objectives()
+	return objs[0] + objs[1], nil
+}
+func (bv biObjectiveVector) Crossover(y Genome, rng *rand.Rand) {
+	bv.Vector.Crossover(y.(biObjectiveVector).Vector, rng)
+}
+func (bv biObjectiveVector) Clone() Genome { return biObjectiveVector{bv.Vector.Clone().(Vector)} }
+
+func newBiObjectiveVector(rng *rand.Rand) Genome {
+	return biObjectiveVector{NewVector(rng).(Vector)}
+}
+
+func biObjectiveVectorJSONUnmarshaler(data []byte) (Genome, error) {
+	var bv biObjectiveVector
+	var err = json.Unmarshal(data, &bv)
+	return bv, err
+}
+
+// checkParetoFront makes sure the members of front are mutually
+// non-dominated, feasible and sorted by fitness.
+func checkParetoFront(t *testing.T, front Individuals, max int) {
+	t.Helper()
+	if len(front) == 0 || len(front) > max {
+		t.Fatalf("Expected between 1 and %d members, got %d", max, len(front))
+	}
+	for i, a := range front {
+		if a.Violation != 0 || len(a.Objectives) != 2 {
+			t.Errorf("Expected a feasible member with 2 objectives, got %v", a)
+		}
+		for j, b := range front {
+			if i != j && constrainedDominates(a, b) {
+				t.Errorf("Expected non-dominated members, %v dominates %v", a, b)
+			}
+		}
+		if i > 0 && a.Better(front[i-1]) {
+			t.Errorf("The front isn't sorted: %v", front)
+		}
+	}
+}
+
+func TestGAParetoHallOfFame(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 20
+	conf.HofSize = 10
+	conf.ParetoHallOfFame = true
+	conf.GenomeJSONUnmarshaler = biObjectiveVectorJSONUnmarshaler
+	// The first coordinate has to be positive
+	conf.ConstraintHandler = func(g Genome) float64 {
+		return SumViolations(-g.(biObjectiveVector).Vector[0])
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(newBiObjectiveVector); err != nil {
+		t.Fatal(err)
+	}
+	checkParetoFront(t, ga.HallOfFame, 10)
+	if len(ga.HallOfFame) < 2 {
+		t.Errorf("Expected a front with several trade-offs, got %v", ga.HallOfFame)
+	}
+	// The front survives a round trip and a resume
+	var data []byte
+	if data, err = ga.MarshalHallOfFame(); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err = json.Unmarshal(data, &decoded); err != nil || decoded[0]["objectives"] == nil {
+		t.Errorf("Expected the objectives to be encoded, got %s", data)
+	}
+	ga2, _ := conf.NewGA()
+	if err = ga2.UnmarshalHallOfFame(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(ga2.HallOfFame) != len(ga.HallOfFame) {
+		t.Fatalf("Expected %d members, got %d", len(ga.HallOfFame), len(ga2.HallOfFame))
+	}
+	ga2.NGenerations = 1
+	if err = ga2.Minimize(newBiObjectiveVector); err != nil {
+		t.Fatal(err)
+	}
+	checkParetoFront(t, ga2.HallOfFame, 10)
+	for _, old := range ga.HallOfFame {
+		for _, indi := range ga2.HallOfFame {
+			if constrainedDominates(old, indi) {
+				t.Errorf("The resumed front lost %v to %v", old, indi)
+			}
+		}
+	}
+}
+
+// cachedBiObjectiveVector is a biObjectiveVector which only knows its
+// objectives once it has been evaluated.
+type cachedBiObjectiveVector struct {
+	biObjectiveVector
+	objectives *[]float64
+}
+
+func (cv cachedBiObjectiveVector) Objectives() []float64 { return *cv.objectives }
+func (cv cachedBiObjectiveVector) Evaluate() (float64, error) {
+	*cv.objectives = cv.biObjectiveVector.Objectives()
+	return cv.biObjectiveVector.Evaluate()
+}
+func (cv cachedBiObjectiveVector) Crossover(y Genome, rng *rand.Rand) {
+	cv.biObjectiveVector.Crossover(y.(cachedBiObjectiveVector).biObjectiveVector, rng)
+}
+func (cv cachedBiObjectiveVector) Clone() Genome {
+	return cachedBiObjectiveVector{cv.biObjectiveVector.Clone().(biObjectiveVector), new([]float64)}
+}
+
+func TestGAParetoHallOfFameEvalTimeout(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 10
+	conf.HofSize = 10
+	conf.ParetoHallOfFame = true
+	conf.EvalTimeout = time.Second
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(func(rng *rand.Rand) Genome {
+		return cachedBiObjectiveVector{newBiObjectiveVector(rng).(biObjectiveVector), new([]float64)}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkParetoFront(t, ga.HallOfFame, 10)
+	if len(ga.HallOfFame) < 2 {
+		t.Errorf("Expected a front with several trade-offs, got %v", ga.HallOfFame)
+	}
+}
+
+// slowBiObjectiveVector is a biObjectiveVector whose evaluation takes 50ms.
+type slowBiObjectiveVector struct{ biObjectiveVector }
+
+func (sv slowBiObjectiveVector) Evaluate() (float64, error) {
+	time.Sleep(50 * time.Millisecond)
+	return sv.biObjectiveVector.Evaluate()
+}
+func (sv slowBiObjectiveVector) Crossover(y Genome, rng *rand.Rand) {
+	sv.biObjectiveVector.Crossover(y.(slowBiObjectiveVector).biObjectiveVector, rng)
+}
+func (sv slowBiObjectiveVector) Clone() Genome {
+	return slowBiObjectiveVector{sv.biObjectiveVector.Clone().(biObjectiveVector)}
+}
+
+func TestGAParetoHallOfFameAllTimeouts(t *testing.T) {
+	var newGenome = func(rng *rand.Rand) Genome {
+		return slowBiObjectiveVector{newBiObjectiveVector(rng).(biObjectiveVector)}
+	}
+	var testCases = []struct {
+		async  bool
+		inject bool
+	}{
+		{false, false},
+		{true, false},
+		{false, true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var conf = NewDefaultGAConfig()
+			conf.NPops = 2
+			conf.PopSize = 4
+			conf.NGenerations = 2
+			conf.ParetoHallOfFame = true
+			conf.EvalTimeout = time.Millisecond
+			conf.Diagnostics = &DiagnosticsOptions{}
+			conf.Migrator = MigRing{NMigrants: 1}
+			conf.SingleThreaded = false
+			conf.AsyncMigration = tc.async
+			var ga, err = conf.NewGA()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.inject {
+				ga.Inject(newGenome(newRand()))
+			}
+			if err = ga.Minimize(newGenome); err != nil {
+				t.Fatal(err)
+			}
+			// No Individual has Objectives, hence the front stays empty
+			if len(ga.HallOfFame) != 0 {
+				t.Errorf("Expected an empty front, got %v", ga.HallOfFame)
+			}
+		})
+	}
+}
+
+func TestGAParetoHallOfFameNotMultiObjective(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.ParetoHallOfFame = true
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(NewVector); err != errNotMultiObjective {
+		t.Errorf("Expected %v, got %v", errNotMultiObjective, err)
+	}
+}
+
+func TestUpdateParetoFront(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(42))
+		indi = func(id string, violation float64, objs ...float64) Individual {
+			return Individual{
+				ID:         id,
+				Fitness:    objs[0],
+				Violation:  violation,
+				Objectives: objs,
+				Evaluated:  true,
+			}
+		}
+	)
+	var testCases = []struct {
+		front    Individuals
+		indis    Individuals
+		max      int
+		expected []float64 // first objective of each member, in order
+	}{
+		// Dominated and duplicate Individuals are skipped
+		{
+			Individuals{indi("a", 0, 1, 3), indi("b", 0, 3, 1)},
+			Individuals{indi("c", 0, 2, 4), indi("d", 0, 1, 3), indi("e", 0, 2, 2)},
+			10,
+			[]float64{1, 2, 3},
+		},
+		// Dominated members are removed
+		{
+			Individuals{indi("a", 0, 1, 3), indi("b", 0, 3, 1)},
+			Individuals{indi("c", 0, 1, 1)},
+			10,
+			[]float64{1},
+		},
+		// Feasible Individuals replace infeasible members
+		{
+			Individuals{indi("a", 2, 0, 0), indi("b", 1, 5, 5)},
+			Individuals{indi("c", 0, 9, 9)},
+			10,
+			[]float64{9},
+		},
+		// Infeasible Individuals are compared by violation
+		{
+			Individuals{indi("a", 2, 0, 0)},
+			Individuals{indi("b", 3, 0, 0), indi("c", 1, 5, 5)},
+			10,
+			[]float64{5},
+		},
+		// The most crowded members are removed
+		{
+			nil,
+			Individuals{indi("a", 0, 0, 4), indi("b", 0, 4, 0), indi("c", 0, 1, 3), indi("d", 0, 1.1, 2.9), indi("e", 0, 2, 2)},
+			4,
+			[]float64{0, 1, 2, 4},
+		},
+		// Unevaluated Individuals are ignored
+		{
+			nil,
+			Individuals{{Objectives: []float64{0, 0}}, indi("a", 0, 1, 1)},
+			10,
+			[]float64{1},
+		},
+	}
+	for i, tc := range testCases {
+		var front = updateParetoFront(tc.front, tc.indis, tc.max, rng)
+		if len(front) != len(tc.expected) {
+			t.Errorf("Error in test case number %d: expected %v, got %v", i, tc.expected, front)
+			continue
+		}
+		for j, member := range front {
+			if member.Objectives[0] != tc.expected[j] {
+				t.Errorf("Error in test case number %d: expected %v, got %v", i, tc.expected, front)
+				break
+			}
+		}
+	}
+}
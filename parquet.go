@@ -0,0 +1,963 @@
+package eaopt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The subset of the Parquet format used by WriteParquet and
+// ReadIndividualsParquet, see https://github.com/apache/parquet-format.
+const (
+	parquetMagic = "PAR1"
+
+	// Physical types
+	parquetBoolean           = 0
+	parquetInt32             = 1
+	parquetInt64             = 2
+	parquetInt96             = 3
+	parquetFloat             = 4
+	parquetDouble            = 5
+	parquetByteArray         = 6
+	parquetFixedLenByteArray = 7
+
+	// Repetition types
+	parquetRequired = 0
+	parquetOptional = 1
+	parquetRepeated = 2
+
+	// Encodings
+	parquetPlain           = 0
+	parquetPlainDictionary = 2
+	parquetRLE             = 3
+	parquetRLEDictionary   = 8
+
+	// Compression codecs
+	parquetUncompressed = 0
+	parquetSnappy       = 1
+	parquetGzip         = 2
+	parquetZstd         = 6
+
+	// Page types
+	parquetDataPage       = 0
+	parquetDictionaryPage = 2
+	parquetDataPageV2     = 3
+)
+
+// WriteParquet writes the Individuals to w as a Parquet file with the same
+// columns as WriteCSV. The fitness column holds doubles and is null for
+// Individuals that haven't been evaluated. A genome column holds 64 bit
+// integers if every field of the column is an integer, doubles if every field
+// is a number and strings otherwise; empty fields, which pad variable length
+// Genomes, are null. The file has a single row group and isn't compressed.
+func (indis Individuals) WriteParquet(w io.Writer) error {
+	var header, records, err = indis.csvTable()
+	if err != nil {
+		return err
+	}
+	var (
+		file   = []byte(parquetMagic)
+		meta   thriftWriter
+		chunks thriftWriter
+	)
+	meta.i32(1, 1)
+	meta.beginList(2, thriftStruct, len(header)+1)
+	meta.beginElement()
+	meta.binary(4, []byte("schema"))
+	meta.i32(5, int32(len(header)))
+	meta.endStruct()
+	for j, name := range header {
+		var (
+			values = make([]string, len(records))
+			col    = parquetColumn{name: name, typ: parquetByteArray, optional: j > 0}
+		)
+		for i, record := range records {
+			values[i] = record[j]
+		}
+		switch {
+		case j == 1:
+			col.typ = parquetDouble
+		case j > 1:
+			col.typ = parquetColumnType(values)
+		}
+		meta.beginElement()
+		meta.i32(1, col.typ)
+		if col.optional {
+			meta.i32(3, parquetOptional)
+		} else {
+			meta.i32(3, parquetRequired)
+		}
+		meta.binary(4, []byte(col.name))
+		if col.typ == parquetByteArray {
+			// UTF8 converted type and STRING logical type
+			meta.i32(6, 0)
+			meta.beginStruct(10)
+			meta.beginStruct(1)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.endStruct()
+		if len(records) == 0 {
+			continue
+		}
+		page, err := col.encode(values)
+		if err != nil {
+			return err
+		}
+		var pageHeader thriftWriter
+		pageHeader.i32(1, parquetDataPage)
+		pageHeader.i32(2, int32(len(page)))
+		pageHeader.i32(3, int32(len(page)))
+		pageHeader.beginStruct(5)
+		pageHeader.i32(1, int32(len(values)))
+		pageHeader.i32(2, parquetPlain)
+		pageHeader.i32(3, parquetRLE)
+		pageHeader.i32(4, parquetRLE)
+		pageHeader.endStruct()
+		pageHeader.endStruct()
+		var (
+			offset = int64(len(file))
+			size   = int64(len(pageHeader.buf) + len(page))
+		)
+		file = append(append(file, pageHeader.buf...), page...)
+		chunks.beginElement()
+		chunks.i64(2, offset)
+		chunks.beginStruct(3)
+		chunks.i32(1, col.typ)
+		chunks.beginList(2, thriftI32, 2)
+		chunks.listI32(parquetPlain)
+		chunks.listI32(parquetRLE)
+		chunks.beginList(3, thriftBinary, 1)
+		chunks.listBinary([]byte(col.name))
+		chunks.i32(4, parquetUncompressed)
+		chunks.i64(5, int64(len(values)))
+		chunks.i64(6, size)
+		chunks.i64(7, size)
+		chunks.i64(9, offset)
+		chunks.endStruct()
+		chunks.endStruct()
+	}
+	meta.i64(3, int64(len(records)))
+	if len(records) == 0 {
+		meta.beginList(4, thriftStruct, 0)
+	} else {
+		meta.beginList(4, thriftStruct, 1)
+		meta.beginElement()
+		meta.beginList(1, thriftStruct, len(header))
+		meta.buf = append(meta.buf, chunks.buf...)
+		meta.i64(2, int64(len(file)-len(parquetMagic)))
+		meta.i64(3, int64(len(records)))
+		meta.endStruct()
+	}
+	meta.binary(6, []byte("eaopt"))
+	meta.endStruct()
+	file = append(file, meta.buf...)
+	file = binary.LittleEndian.AppendUint32(file, uint32(len(meta.buf)))
+	file = append(file, parquetMagic...)
+	_, err = w.Write(file)
+	return err
+}
+
+// parquetColumn describes a column of a flat Parquet schema.
+type parquetColumn struct {
+	name      string
+	typ       int32
+	typLength int
+	optional  bool
+}
+
+// parquetColumnType returns the physical type of a genome column, columns
+// without any value holding strings.
+func parquetColumnType(values []string) int32 {
+	var typ int32 = parquetByteArray
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if typ == parquetByteArray {
+				typ = parquetInt64
+			}
+			continue
+		}
+		// Strings such as "inf" or "nan" aren't numbers for the user
+		if _, err := strconv.ParseFloat(v, 64); err != nil || strings.ContainsAny(v, "iInN") {
+			return parquetByteArray
+		}
+		typ = parquetDouble
+	}
+	return typ
+}
+
+// encode returns the content of a data page holding values, empty values
+// being null if the column is optional.
+func (col parquetColumn) encode(values []string) ([]byte, error) {
+	var page []byte
+	if col.optional {
+		var levels = make([]int, len(values))
+		for i, v := range values {
+			if v != "" {
+				levels[i] = 1
+			}
+		}
+		var encoded = encodeRLE(levels)
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(encoded)))
+		page = append(page, encoded...)
+	}
+	for _, v := range values {
+		if v == "" && col.optional {
+			continue
+		}
+		switch col.typ {
+		case parquetInt64:
+			var x, _ = strconv.ParseInt(v, 10, 64)
+			page = binary.LittleEndian.AppendUint64(page, uint64(x))
+		case parquetDouble:
+			var x, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", col.name, err)
+			}
+			page = binary.LittleEndian.AppendUint64(page, math.Float64bits(x))
+		default:
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(v)))
+			page = append(page, v...)
+		}
+	}
+	return page, nil
+}
+
+// encodeRLE encodes levels of bit width 1 as runs of the RLE/bit-packing
+// hybrid encoding.
+func encodeRLE(levels []int) []byte {
+	var encoded []byte
+	for i := 0; i < len(levels); {
+		var j = i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		encoded = binary.AppendUvarint(encoded, uint64(j-i)<<1)
+		encoded = append(encoded, byte(levels[i]))
+		i = j
+	}
+	return encoded
+}
+
+// ReadIndividualsParquet reads Individuals from a Parquet file, such as one
+// written by Individuals.WriteParquet. The file has to contain a flat schema
+// with an "id" column, a "fitness" column and either a "genome" column or
+// "genome_0", "genome_1", ... columns; other columns are ignored. Each row is decoded like a CSV record, see
+// ReadIndividualsCSV: the genome columns are converted to strings and handed
+// to unmarshal, null values being empty strings. Files compressed with
+// snappy, gzip or zstd, and using plain or dictionary encodings, are
+// supported.
+func ReadIndividualsParquet(r io.Reader, unmarshal CSVUnmarshaler, rng *rand.Rand) (Individuals, error) {
+	if unmarshal == nil {
+		return nil, errors.New("unmarshal can't be nil")
+	}
+	var data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	names, columns, err := readParquetTable(data)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		index   = make(map[string]int)
+		genomes []int
+		order   = make(map[int]int)
+	)
+	for j, name := range names {
+		index[name] = j
+		if !strings.HasPrefix(name, "genome_") {
+			continue
+		}
+		if i, err := strconv.Atoi(strings.TrimPrefix(name, "genome_")); err == nil {
+			genomes = append(genomes, j)
+			order[j] = i
+		}
+	}
+	sort.Slice(genomes, func(a, b int) bool { return order[genomes[a]] < order[genomes[b]] })
+	if j, ok := index["genome"]; ok && len(genomes) == 0 {
+		genomes = []int{j}
+	}
+	var id, hasID = index["id"]
+	var fitness, hasFitness = index["fitness"]
+	if !hasID || !hasFitness || len(genomes) == 0 {
+		return nil, errors.New(`Parquet file should contain "id", "fitness" and genome columns`)
+	}
+	var nRows int
+	if len(columns) > 0 {
+		nRows = len(columns[0])
+	}
+	var indis = make(Individuals, nRows)
+	for i := range indis {
+		var record = []string{columns[id][i], columns[fitness][i]}
+		for _, j := range genomes {
+			record = append(record, columns[j][i])
+		}
+		if indis[i], err = recordIndividual(record, unmarshal, rng); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	return indis, nil
+}
+
+// readParquetTable decodes a Parquet file with a flat schema. The values of
+// each column are formatted as strings, null values being empty strings.
+func readParquetTable(data []byte) ([]string, [][]string, error) {
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		return nil, nil, errors.New("not a Parquet file")
+	}
+	var size = int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if size > len(data)-12 {
+		return nil, nil, errors.New("Parquet footer is corrupted")
+	}
+	var reader = thriftReader{data: data[len(data)-8-size : len(data)-8]}
+	meta, err := reader.readStruct(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Parquet footer: %w", err)
+	}
+	var schema = meta.list(2)
+	if len(schema) == 0 {
+		return nil, nil, errors.New("Parquet schema is empty")
+	}
+	var (
+		cols  = make([]parquetColumn, len(schema)-1)
+		names = make([]string, len(cols))
+	)
+	if root, _ := schema[0].(thriftFields); root.int(5) != int64(len(cols)) {
+		return nil, nil, errors.New("only flat Parquet schemas are supported")
+	}
+	for j := range cols {
+		var element, _ = schema[j+1].(thriftFields)
+		if element.int(5) > 0 || element.int(3) == parquetRepeated {
+			return nil, nil, errors.New("only flat Parquet schemas are supported")
+		}
+		cols[j] = parquetColumn{
+			name:      string(element.binary(4)),
+			typ:       int32(element.int(1)),
+			typLength: int(element.int(2)),
+			optional:  element.int(3) == parquetOptional,
+		}
+		names[j] = cols[j].name
+	}
+	var (
+		columns = make([][]string, len(cols))
+		nRows   int
+	)
+	for _, rg := range meta.list(4) {
+		var (
+			group, _ = rg.(thriftFields)
+			rows     = int(group.int(3))
+			chunks   = group.list(1)
+		)
+		if len(chunks) != len(cols) || rows < 0 {
+			return nil, nil, errors.New("Parquet row group doesn't match the schema")
+		}
+		for j, chunk := range chunks {
+			var c, _ = chunk.(thriftFields)
+			var values, err = cols[j].readChunk(data, c.fields(3), rows)
+			if err != nil {
+				return nil, nil, fmt.Errorf("column %s: %w", cols[j].name, err)
+			}
+			columns[j] = append(columns[j], values...)
+		}
+		nRows += rows
+	}
+	for j := range columns {
+		if len(columns[j]) != nRows {
+			return nil, nil, fmt.Errorf("column %s: expected %d values, got %d", cols[j].name, nRows, len(columns[j]))
+		}
+	}
+	return names, columns, nil
+}
+
+// readChunk decodes the pages of a column chunk described by meta.
+func (col parquetColumn) readChunk(data []byte, meta thriftFields, rows int) ([]string, error) {
+	if meta == nil {
+		return nil, errors.New("column chunk metadata is missing")
+	}
+	var (
+		codec  = meta.int(4)
+		total  = int(meta.int(5))
+		offset = meta.int(9)
+		dict   []string
+		values []string
+	)
+	if d, ok := meta[11].(int64); ok && d > 0 && d < offset {
+		offset = d
+	}
+	for len(values) < total {
+		if offset < 0 || offset >= int64(len(data)) {
+			return nil, errors.New("page offset is out of bounds")
+		}
+		var reader = thriftReader{data: data[offset:]}
+		header, err := reader.readStruct(0)
+		if err != nil {
+			return nil, fmt.Errorf("page header: %w", err)
+		}
+		var (
+			start = int(offset) + reader.pos
+			size  = int(header.int(3))
+		)
+		if size < 0 || start+size > len(data) {
+			return nil, errors.New("page is out of bounds")
+		}
+		var body = data[start : start+size]
+		offset = int64(start + size)
+		switch header.int(1) {
+		case parquetDictionaryPage:
+			var dph = header.fields(7)
+			if body, err = decompress(codec, body); err != nil {
+				return nil, err
+			}
+			var n = int(dph.int(1))
+			if dict, _, err = col.decodePlain(body, n); err != nil {
+				return nil, err
+			}
+		case parquetDataPage:
+			var dph = header.fields(5)
+			if body, err = decompress(codec, body); err != nil {
+				return nil, err
+			}
+			var n = int(dph.int(1))
+			var levels []int
+			if col.optional {
+				if len(body) < 4 {
+					return nil, errors.New("definition levels are missing")
+				}
+				var length = int(binary.LittleEndian.Uint32(body))
+				if length > len(body)-4 {
+					return nil, errors.New("definition levels are out of bounds")
+				}
+				if levels, err = decodeRLE(body[4:4+length], 1, n); err != nil {
+					return nil, err
+				}
+				body = body[4+length:]
+			}
+			var page []string
+			if page, err = col.decodeValues(body, int(dph.int(2)), n, levels, dict); err != nil {
+				return nil, err
+			}
+			values = append(values, page...)
+		case parquetDataPageV2:
+			var (
+				dph       = header.fields(8)
+				n         = int(dph.int(1))
+				defLength = int(dph.int(5))
+				repLength = int(dph.int(6))
+				levels    []int
+			)
+			if defLength < 0 || repLength != 0 || defLength > len(body) {
+				return nil, errors.New("levels are out of bounds")
+			}
+			if col.optional {
+				if levels, err = decodeRLE(body[:defLength], 1, n); err != nil {
+					return nil, err
+				}
+			}
+			body = body[defLength:]
+			if compressed, ok := dph[7].(bool); !ok || compressed {
+				if body, err = decompress(codec, body); err != nil {
+					return nil, err
+				}
+			}
+			var page []string
+			if page, err = col.decodeValues(body, int(dph.int(4)), n, levels, dict); err != nil {
+				return nil, err
+			}
+			values = append(values, page...)
+		}
+	}
+	if len(values) != rows {
+		return nil, fmt.Errorf("expected %d values, got %d", rows, len(values))
+	}
+	return values, nil
+}
+
+// decompress decompresses a page.
+func decompress(codec int64, body []byte) ([]byte, error) {
+	switch codec {
+	case parquetUncompressed:
+		return body, nil
+	case parquetSnappy:
+		return snappy.Decode(nil, body)
+	case parquetGzip:
+		var r, err = gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	case parquetZstd:
+		var d, err = zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer d.Close()
+		return d.DecodeAll(body, nil)
+	}
+	return nil, fmt.Errorf("unsupported Parquet compression codec %d", codec)
+}
+
+// decodeValues decodes the n values of a data page, levels being nil if the
+// column is required.
+func (col parquetColumn) decodeValues(body []byte, encoding, n int, levels []int, dict []string) ([]string, error) {
+	var nonNull = n
+	if levels != nil {
+		nonNull = 0
+		for _, l := range levels {
+			nonNull += l
+		}
+	}
+	var (
+		decoded []string
+		err     error
+	)
+	switch encoding {
+	case parquetPlain:
+		decoded, _, err = col.decodePlain(body, nonNull)
+	case parquetPlainDictionary, parquetRLEDictionary:
+		if len(body) == 0 && nonNull > 0 {
+			return nil, errors.New("dictionary indexes are missing")
+		}
+		var indexes []int
+		if nonNull > 0 {
+			if indexes, err = decodeRLE(body[1:], int(body[0]), nonNull); err != nil {
+				return nil, err
+			}
+		}
+		decoded = make([]string, nonNull)
+		for i, k := range indexes {
+			if k >= len(dict) {
+				return nil, errors.New("dictionary index is out of bounds")
+			}
+			decoded[i] = dict[k]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Parquet encoding %d", encoding)
+	}
+	if err != nil || levels == nil {
+		return decoded, err
+	}
+	var values = make([]string, n)
+	for i, k := 0, 0; i < n; i++ {
+		if levels[i] == 1 {
+			values[i] = decoded[k]
+			k++
+		}
+	}
+	return values, nil
+}
+
+// decodePlain decodes n plainly encoded values and returns the number of bytes
+// read.
+func (col parquetColumn) decodePlain(body []byte, n int) ([]string, int, error) {
+	var (
+		values = make([]string, 0, minInt(n, len(body)))
+		pos    int
+		short  = errors.New("page is too short")
+	)
+	for i := 0; i < n; i++ {
+		switch col.typ {
+		case parquetBoolean:
+			if pos+i/8 >= len(body) {
+				return nil, 0, short
+			}
+			values = append(values, strconv.FormatBool(body[i/8]>>(i%8)&1 == 1))
+			continue
+		case parquetInt32, parquetFloat:
+			if pos+4 > len(body) {
+				return nil, 0, short
+			}
+			var x = binary.LittleEndian.Uint32(body[pos:])
+			if col.typ == parquetInt32 {
+				values = append(values, strconv.FormatInt(int64(int32(x)), 10))
+			} else {
+				values = append(values, strconv.FormatFloat(float64(math.Float32frombits(x)), 'g', -1, 32))
+			}
+			pos += 4
+		case parquetInt64, parquetDouble:
+			if pos+8 > len(body) {
+				return nil, 0, short
+			}
+			var x = binary.LittleEndian.Uint64(body[pos:])
+			if col.typ == parquetInt64 {
+				values = append(values, strconv.FormatInt(int64(x), 10))
+			} else {
+				values = append(values, strconv.FormatFloat(math.Float64frombits(x), 'g', -1, 64))
+			}
+			pos += 8
+		case parquetByteArray:
+			if pos+4 > len(body) {
+				return nil, 0, short
+			}
+			var length = int(binary.LittleEndian.Uint32(body[pos:]))
+			pos += 4
+			if length < 0 || length > len(body)-pos {
+				return nil, 0, short
+			}
+			values = append(values, string(body[pos:pos+length]))
+			pos += length
+		case parquetFixedLenByteArray:
+			if col.typLength < 0 || col.typLength > len(body)-pos {
+				return nil, 0, short
+			}
+			values = append(values, string(body[pos:pos+col.typLength]))
+			pos += col.typLength
+		default:
+			return nil, 0, fmt.Errorf("unsupported Parquet type %d", col.typ)
+		}
+	}
+	if col.typ == parquetBoolean {
+		pos = (n + 7) / 8
+	}
+	return values, pos, nil
+}
+
+// decodeRLE decodes n values of the RLE/bit-packing hybrid encoding.
+func decodeRLE(data []byte, bitWidth, n int) ([]int, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, errors.New("invalid bit width")
+	}
+	var (
+		values = make([]int, 0, n)
+		pos    int
+		width  = (bitWidth + 7) / 8
+	)
+	for len(values) < n {
+		var header, k = binary.Uvarint(data[pos:])
+		if k <= 0 {
+			return nil, errors.New("RLE run is truncated")
+		}
+		pos += k
+		if header&1 == 1 {
+			// Bit-packed groups of 8 values
+			var (
+				count  = int(header>>1) * 8
+				nBytes = int(header>>1) * bitWidth
+			)
+			if nBytes < 0 || pos+nBytes > len(data) {
+				return nil, errors.New("bit-packed run is truncated")
+			}
+			for i := 0; i < count && len(values) < n; i++ {
+				var v int
+				for b := 0; b < bitWidth; b++ {
+					var bit = i*bitWidth + b
+					v |= int(data[pos+bit/8]>>(bit%8)&1) << b
+				}
+				values = append(values, v)
+			}
+			pos += nBytes
+			continue
+		}
+		if pos+width > len(data) {
+			return nil, errors.New("RLE run is truncated")
+		}
+		var v int
+		for b := 0; b < width; b++ {
+			v |= int(data[pos+b]) << (8 * b)
+		}
+		pos += width
+		for count := int(header >> 1); count > 0 && len(values) < n; count-- {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// WriteParquet writes the Population's Individuals to w, see
+// Individuals.WriteParquet.
+func (pop Population) WriteParquet(w io.Writer) error {
+	return pop.Individuals.WriteParquet(w)
+}
+
+// ReadParquet replaces the Population's Individuals with the ones read from
+// r, see ReadIndividualsParquet and Population.ReadCSV.
+func (pop *Population) ReadParquet(r io.Reader, unmarshal CSVUnmarshaler, parallel bool) error {
+	return pop.readIndividuals(unmarshal, parallel, func(unmarshal CSVUnmarshaler, rng *rand.Rand) (Individuals, error) {
+		return ReadIndividualsParquet(r, unmarshal, rng)
+	})
+}
+
+// Compact protocol types of Thrift, which encodes the Parquet metadata.
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol.
+type thriftWriter struct {
+	buf    []byte
+	lastID []int16
+}
+
+// field writes the header of a field of the current struct.
+func (w *thriftWriter) field(id int16, typ byte) {
+	var last int16
+	if len(w.lastID) > 0 {
+		last = w.lastID[len(w.lastID)-1]
+		w.lastID[len(w.lastID)-1] = id
+	} else {
+		w.lastID = []int16{id}
+	}
+	if delta := id - last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+		return
+	}
+	w.buf = append(w.buf, typ)
+	w.buf = binary.AppendVarint(w.buf, int64(id))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.buf = binary.AppendVarint(w.buf, int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.buf = binary.AppendVarint(w.buf, v)
+}
+
+func (w *thriftWriter) binary(id int16, b []byte) {
+	w.field(id, thriftBinary)
+	w.listBinary(b)
+}
+
+// beginStruct starts a struct field, which is ended by endStruct.
+func (w *thriftWriter) beginStruct(id int16) {
+	w.field(id, thriftStruct)
+	w.lastID = append(w.lastID, 0)
+}
+
+// beginElement starts a struct which is an element of a list.
+func (w *thriftWriter) beginElement() {
+	if len(w.lastID) == 0 {
+		w.lastID = []int16{0}
+	}
+	w.lastID = append(w.lastID, 0)
+}
+
+// endStruct ends the current struct.
+func (w *thriftWriter) endStruct() {
+	w.buf = append(w.buf, 0)
+	w.lastID = w.lastID[:len(w.lastID)-1]
+}
+
+// beginList writes the header of a list field of n elements, which are then
+// written one by one.
+func (w *thriftWriter) beginList(id int16, elemType byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elemType)
+		return
+	}
+	w.buf = append(w.buf, 0xF0|elemType)
+	w.buf = binary.AppendUvarint(w.buf, uint64(n))
+}
+
+func (w *thriftWriter) listI32(v int32) {
+	w.buf = binary.AppendVarint(w.buf, int64(v))
+}
+
+func (w *thriftWriter) listBinary(b []byte) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+// thriftFields holds the fields of a decoded struct by ID. Integers are
+// decoded as int64s, strings as []bytes, lists as []interface{} and structs as
+// thriftFields.
+type thriftFields map[int16]interface{}
+
+func (f thriftFields) int(id int16) int64 {
+	var v, _ = f[id].(int64)
+	return v
+}
+
+func (f thriftFields) binary(id int16) []byte {
+	var v, _ = f[id].([]byte)
+	return v
+}
+
+func (f thriftFields) list(id int16) []interface{} {
+	var v, _ = f[id].([]interface{})
+	return v
+}
+
+func (f thriftFields) fields(id int16) thriftFields {
+	var v, _ = f[id].(thriftFields)
+	return v
+}
+
+// thriftReader decodes structs encoded with the Thrift compact protocol.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+// errThrift is returned when the metadata can't be decoded.
+var errThrift = errors.New("invalid Thrift encoding")
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errThrift
+	}
+	r.pos++
+	return r.data[r.pos-1], nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	var v, n = binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, errThrift
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	var v, n = binary.Varint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, errThrift
+	}
+	r.pos += n
+	return v, nil
+}
+
+// readStruct decodes a struct, depth being used to reject deeply nested
+// values.
+func (r *thriftReader) readStruct(depth int) (thriftFields, error) {
+	if depth > 32 {
+		return nil, errThrift
+	}
+	var (
+		fields = make(thriftFields)
+		id     int16
+	)
+	for {
+		var header, err = r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+		var typ = header & 0x0F
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			var v, err = r.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		switch typ {
+		case thriftTrue:
+			fields[id] = true
+		case thriftFalse:
+			fields[id] = false
+		default:
+			if fields[id], err = r.readValue(typ, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// readValue decodes a value of the given type.
+func (r *thriftReader) readValue(typ byte, depth int) (interface{}, error) {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		// Booleans in collections take a byte
+		var b, err = r.byte()
+		return b == thriftTrue, err
+	case thriftByte:
+		var b, err = r.byte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return r.varint()
+	case thriftDouble:
+		if r.pos+8 > len(r.data) {
+			return nil, errThrift
+		}
+		r.pos += 8
+		return math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos-8:])), nil
+	case thriftBinary:
+		var n, err = r.uvarint()
+		if err != nil || n > uint64(len(r.data)-r.pos) {
+			return nil, errThrift
+		}
+		r.pos += int(n)
+		return r.data[r.pos-int(n) : r.pos], nil
+	case thriftList, thriftSet:
+		var header, err = r.byte()
+		if err != nil {
+			return nil, err
+		}
+		var n = uint64(header >> 4)
+		if n == 15 {
+			if n, err = r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		// Every element takes at least a byte
+		if n > uint64(len(r.data)-r.pos) {
+			return nil, errThrift
+		}
+		var list = make([]interface{}, n)
+		for i := range list {
+			if list[i], err = r.readValue(header&0x0F, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case thriftMap:
+		var n, err = r.uvarint()
+		if err != nil || n > uint64(len(r.data)-r.pos) {
+			return nil, errThrift
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		types, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < 2*n; i++ {
+			var typ = types >> 4
+			if i%2 == 1 {
+				typ = types & 0x0F
+			}
+			if _, err = r.readValue(typ, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		return r.readStruct(depth + 1)
+	}
+	return nil, errThrift
+}
@@ -0,0 +1,455 @@
+package eaopt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestIndividualsParquetRoundTrip(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newIndividuals(10, false, NewVector, rng)
+		buf   bytes.Buffer
+	)
+	indis[:5].Evaluate(false)
+	if err := indis.WriteParquet(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var names, _, err = readParquetTable(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header = []string{"id", "fitness", "genome_0", "genome_1", "genome_2", "genome_3"}
+	if !reflect.DeepEqual(names, header) {
+		t.Errorf("Expected columns %v, got %v", header, names)
+	}
+	decoded, err := ReadIndividualsParquet(&buf, JSONCSVUnmarshaler(VectorJSONUnmarshaler), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(indis) {
+		t.Fatalf("Expected %d individuals, got %d", len(indis), len(decoded))
+	}
+	for i, indi := range decoded {
+		if indi.ID != indis[i].ID {
+			t.Errorf("Expected ID %s, got %s", indis[i].ID, indi.ID)
+		}
+		if indi.Evaluated != indis[i].Evaluated || indi.Fitness != indis[i].Fitness {
+			t.Errorf("Expected fitness %f (%v), got %f (%v)", indis[i].Fitness, indis[i].Evaluated,
+				indi.Fitness, indi.Evaluated)
+		}
+		if !reflect.DeepEqual(indi.Genome, indis[i].Genome) {
+			t.Errorf("Expected %v, got %v", indis[i].Genome, indi.Genome)
+		}
+	}
+}
+
+func TestIndividualsParquetColumnTypes(t *testing.T) {
+	var testCases = []struct {
+		indis     Individuals
+		unmarshal CSVUnmarshaler
+		types     []int32
+	}{
+		{
+			indis: Individuals{
+				NewIndividual(csvStrings{"a", "b, c"}, newRand()),
+				NewIndividual(csvStrings{"d"}, newRand()),
+			},
+			unmarshal: unmarshalCSVStrings,
+			types:     []int32{parquetByteArray, parquetDouble, parquetByteArray, parquetByteArray},
+		},
+		{
+			indis: Individuals{
+				NewIndividual(csvStrings{"1", "-2"}, newRand()),
+				NewIndividual(csvStrings{"3"}, newRand()),
+			},
+			unmarshal: unmarshalCSVStrings,
+			types:     []int32{parquetByteArray, parquetDouble, parquetInt64, parquetInt64},
+		},
+		{
+			indis: Individuals{
+				NewIndividual(csvStrings{"1", "inf"}, newRand()),
+				NewIndividual(csvStrings{"1.5", "2"}, newRand()),
+			},
+			unmarshal: unmarshalCSVStrings,
+			types:     []int32{parquetByteArray, parquetDouble, parquetDouble, parquetByteArray},
+		},
+		{
+			indis:     Individuals{NewIndividual(ErrorGenome{}, newRand())},
+			unmarshal: func(fields []string) (Genome, error) { return ErrorGenome{}, nil },
+			types:     []int32{parquetByteArray, parquetDouble, parquetByteArray},
+		},
+		{
+			indis:     Individuals{},
+			unmarshal: unmarshalCSVStrings,
+			types:     []int32{parquetByteArray, parquetDouble, parquetByteArray},
+		},
+	}
+	for i, tc := range testCases {
+		var buf bytes.Buffer
+		if err := tc.indis.WriteParquet(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var types []int32
+		for _, col := range parquetSchema(t, buf.Bytes()) {
+			types = append(types, col.typ)
+		}
+		if !reflect.DeepEqual(types, tc.types) {
+			t.Errorf("Error in test case number %d: expected types %v, got %v", i, tc.types, types)
+		}
+		var decoded, err = ReadIndividualsParquet(&buf, tc.unmarshal, newRand())
+		if err != nil {
+			t.Fatalf("Error in test case number %d: %v", i, err)
+		}
+		if len(decoded) != len(tc.indis) {
+			t.Fatalf("Error in test case number %d: expected %d individuals, got %d", i, len(tc.indis), len(decoded))
+		}
+		for j, indi := range decoded {
+			if indi.ID != tc.indis[j].ID || !reflect.DeepEqual(indi.Genome, tc.indis[j].Genome) {
+				t.Errorf("Error in test case number %d: expected %v, got %v", i, tc.indis[j], indi)
+			}
+		}
+	}
+}
+
+// parquetSchema returns the columns of a Parquet file.
+func parquetSchema(t *testing.T, data []byte) []parquetColumn {
+	var size = int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	var r = thriftReader{data: data[len(data)-8-size : len(data)-8]}
+	var meta, err = r.readStruct(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cols []parquetColumn
+	for _, e := range meta.list(2)[1:] {
+		var element = e.(thriftFields)
+		cols = append(cols, parquetColumn{name: string(element.binary(4)), typ: int32(element.int(1))})
+	}
+	return cols
+}
+
+// testParquetChunk is a column chunk of a Parquet file built by
+// buildTestParquet.
+type testParquetChunk struct {
+	col       parquetColumn
+	codec     int32
+	numValues int
+	pages     [][]byte
+}
+
+// buildTestParquet builds a Parquet file with a single row group, the way
+// other libraries do.
+func buildTestParquet(chunks []testParquetChunk, rows int) []byte {
+	var (
+		file   = []byte(parquetMagic)
+		meta   thriftWriter
+		groups thriftWriter
+	)
+	meta.i32(1, 1)
+	meta.beginList(2, thriftStruct, len(chunks)+1)
+	meta.beginElement()
+	meta.binary(4, []byte("schema"))
+	meta.i32(5, int32(len(chunks)))
+	meta.endStruct()
+	for _, chunk := range chunks {
+		meta.beginElement()
+		meta.i32(1, chunk.col.typ)
+		if chunk.col.typLength > 0 {
+			meta.i32(2, int32(chunk.col.typLength))
+		}
+		if chunk.col.optional {
+			meta.i32(3, parquetOptional)
+		} else {
+			meta.i32(3, parquetRequired)
+		}
+		meta.binary(4, []byte(chunk.col.name))
+		meta.endStruct()
+		var offset = int64(len(file))
+		for _, page := range chunk.pages {
+			file = append(file, page...)
+		}
+		groups.beginElement()
+		groups.i64(2, offset)
+		groups.beginStruct(3)
+		groups.i32(1, chunk.col.typ)
+		groups.beginList(2, thriftI32, 0)
+		groups.beginList(3, thriftBinary, 1)
+		groups.listBinary([]byte(chunk.col.name))
+		groups.i32(4, chunk.codec)
+		groups.i64(5, int64(chunk.numValues))
+		groups.i64(6, int64(len(file))-offset)
+		groups.i64(7, int64(len(file))-offset)
+		groups.i64(9, offset)
+		groups.endStruct()
+		groups.endStruct()
+	}
+	meta.i64(3, int64(rows))
+	meta.beginList(4, thriftStruct, 1)
+	meta.beginElement()
+	meta.beginList(1, thriftStruct, len(chunks))
+	meta.buf = append(meta.buf, groups.buf...)
+	meta.i64(2, 0)
+	meta.i64(3, int64(rows))
+	meta.endStruct()
+	meta.endStruct()
+	file = append(file, meta.buf...)
+	file = binary.LittleEndian.AppendUint32(file, uint32(len(meta.buf)))
+	return append(file, parquetMagic...)
+}
+
+// compressTestPage compresses a page with the given codec.
+func compressTestPage(codec int32, body []byte) []byte {
+	switch codec {
+	case parquetSnappy:
+		return snappy.Encode(nil, body)
+	case parquetGzip:
+		var buf bytes.Buffer
+		var w = gzip.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+		return buf.Bytes()
+	case parquetZstd:
+		var e, _ = zstd.NewWriter(nil)
+		defer e.Close()
+		return e.EncodeAll(body, nil)
+	}
+	return body
+}
+
+// testPage encodes a page header followed by the page.
+func testPage(pageType int32, body []byte, uncompressed int, header func(w *thriftWriter)) []byte {
+	var w thriftWriter
+	w.i32(1, pageType)
+	w.i32(2, int32(uncompressed))
+	w.i32(3, int32(len(body)))
+	header(&w)
+	w.endStruct()
+	return append(w.buf, body...)
+}
+
+func testDictionaryPage(codec int32, n int, body []byte) []byte {
+	return testPage(parquetDictionaryPage, compressTestPage(codec, body), len(body), func(w *thriftWriter) {
+		w.beginStruct(7)
+		w.i32(1, int32(n))
+		w.i32(2, parquetPlainDictionary)
+		w.endStruct()
+	})
+}
+
+func testDataPage(codec int32, n int, encoding int32, body []byte) []byte {
+	return testPage(parquetDataPage, compressTestPage(codec, body), len(body), func(w *thriftWriter) {
+		w.beginStruct(5)
+		w.i32(1, int32(n))
+		w.i32(2, encoding)
+		w.i32(3, parquetRLE)
+		w.i32(4, parquetRLE)
+		w.endStruct()
+	})
+}
+
+func testDataPageV2(codec int32, n int, encoding int32, levels, body []byte) []byte {
+	var compressed = compressTestPage(codec, body)
+	return testPage(parquetDataPageV2, append(append([]byte{}, levels...), compressed...), len(levels)+len(body),
+		func(w *thriftWriter) {
+			w.beginStruct(8)
+			w.i32(1, int32(n))
+			w.i32(2, 0)
+			w.i32(3, int32(n))
+			w.i32(4, encoding)
+			w.i32(5, int32(len(levels)))
+			w.i32(6, 0)
+			w.endStruct()
+		})
+}
+
+// plainTestValues encodes values with the PLAIN encoding.
+func plainTestValues(values ...interface{}) []byte {
+	var b []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(v)))
+			b = append(b, v...)
+		case int32:
+			b = binary.LittleEndian.AppendUint32(b, uint32(v))
+		case float32:
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+		case float64:
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+		case int64:
+			b = binary.LittleEndian.AppendUint64(b, uint64(v))
+		}
+	}
+	return b
+}
+
+// withTestLevels prefixes a page with definition levels.
+func withTestLevels(levels []int, body []byte) []byte {
+	var encoded = encodeRLE(levels)
+	return append(append(binary.LittleEndian.AppendUint32(nil, uint32(len(encoded))), encoded...), body...)
+}
+
+func TestReadIndividualsParquetEncodings(t *testing.T) {
+	var data = buildTestParquet([]testParquetChunk{
+		{
+			col:       parquetColumn{name: "__index_level_0__", typ: parquetInt64},
+			numValues: 3,
+			pages:     [][]byte{testDataPage(parquetUncompressed, 3, parquetPlain, plainTestValues(int64(0), int64(1), int64(2)))},
+		},
+		{
+			// A dictionary with bit-packed indexes
+			col:       parquetColumn{name: "genome_1", typ: parquetByteArray, optional: true},
+			codec:     parquetSnappy,
+			numValues: 3,
+			pages: [][]byte{
+				testDictionaryPage(parquetSnappy, 2, plainTestValues("x", "y")),
+				testDataPage(parquetSnappy, 3, parquetRLEDictionary,
+					withTestLevels([]int{1, 0, 1}, []byte{1, 1<<1 | 1, 0x02})),
+			},
+		},
+		{
+			col:       parquetColumn{name: "id", typ: parquetByteArray, optional: true},
+			codec:     parquetGzip,
+			numValues: 3,
+			pages: [][]byte{
+				testDataPage(parquetGzip, 2, parquetPlain, withTestLevels([]int{1, 0}, plainTestValues("abc"))),
+				testDataPage(parquetGzip, 1, parquetPlain, withTestLevels([]int{1}, plainTestValues("def"))),
+			},
+		},
+		{
+			col:       parquetColumn{name: "fitness", typ: parquetFloat, optional: true},
+			codec:     parquetZstd,
+			numValues: 3,
+			pages: [][]byte{testDataPageV2(parquetZstd, 3, parquetPlain, encodeRLE([]int{1, 1, 0}),
+				plainTestValues(float32(1.5), float32(-2)))},
+		},
+		{
+			col:       parquetColumn{name: "genome_0", typ: parquetInt32},
+			numValues: 3,
+			pages:     [][]byte{testDataPage(parquetUncompressed, 3, parquetPlain, plainTestValues(int32(1), int32(2), int32(3)))},
+		},
+	}, 3)
+	var indis, err = ReadIndividualsParquet(bytes.NewReader(data), unmarshalCSVStrings, newRand())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []struct {
+		id        string
+		fitness   float64
+		evaluated bool
+		genome    Genome
+	}{
+		{"abc", 1.5, true, csvStrings{"1", "x"}},
+		{"", -2, true, csvStrings{"2"}},
+		{"def", math.Inf(1), false, csvStrings{"3", "y"}},
+	}
+	if len(indis) != len(expected) {
+		t.Fatalf("Expected %d individuals, got %d", len(expected), len(indis))
+	}
+	for i, e := range expected {
+		if e.id != "" && indis[i].ID != e.id || e.id == "" && indis[i].ID == "" {
+			t.Errorf("Wrong ID for individual %d: %q", i, indis[i].ID)
+		}
+		if indis[i].Fitness != e.fitness || indis[i].Evaluated != e.evaluated {
+			t.Errorf("Expected fitness %f (%v), got %f (%v)", e.fitness, e.evaluated,
+				indis[i].Fitness, indis[i].Evaluated)
+		}
+		if !reflect.DeepEqual(indis[i].Genome, e.genome) {
+			t.Errorf("Expected %v, got %v", e.genome, indis[i].Genome)
+		}
+	}
+}
+
+func TestReadIndividualsParquet(t *testing.T) {
+	var (
+		valid bytes.Buffer
+		id    = testParquetChunk{
+			col:       parquetColumn{name: "id", typ: parquetByteArray},
+			numValues: 1,
+			pages:     [][]byte{testDataPage(parquetUncompressed, 1, parquetPlain, plainTestValues("abc"))},
+		}
+		fitness = testParquetChunk{
+			col:       parquetColumn{name: "fitness", typ: parquetDouble},
+			numValues: 1,
+			pages:     [][]byte{testDataPage(parquetUncompressed, 1, parquetPlain, plainTestValues(1.5))},
+		}
+		genome = testParquetChunk{
+			col:       parquetColumn{name: "genome", typ: parquetByteArray},
+			numValues: 1,
+			pages:     [][]byte{testDataPage(parquetUncompressed, 1, parquetPlain, plainTestValues("1"))},
+		}
+		truncated = genome
+		codec     = genome
+		encoding  = genome
+	)
+	Individuals{NewIndividual(Vector{1}, newRand())}.WriteParquet(&valid)
+	truncated.pages = [][]byte{testDataPage(parquetUncompressed, 1, parquetPlain, plainTestValues(int32(1)))}
+	codec.codec = 4
+	encoding.pages = [][]byte{testDataPage(parquetUncompressed, 1, 5, plainTestValues("1"))}
+	var testCases = []struct {
+		data      []byte
+		unmarshal CSVUnmarshaler
+		err       bool
+	}{
+		{buildTestParquet([]testParquetChunk{id, fitness, genome}, 1), unmarshalCSVFloat64s, false},
+		{valid.Bytes(), unmarshalCSVFloat64s, false},
+		{valid.Bytes(), nil, true},
+		{valid.Bytes(), unmarshalCSVError, true},
+		{valid.Bytes()[:valid.Len()-1], unmarshalCSVFloat64s, true},
+		{valid.Bytes()[:valid.Len()/2], unmarshalCSVFloat64s, true},
+		{[]byte(strings.Repeat(parquetMagic, 3)), unmarshalCSVFloat64s, true},
+		{buildTestParquet([]testParquetChunk{id, genome}, 1), unmarshalCSVFloat64s, true},
+		{buildTestParquet([]testParquetChunk{id, fitness, truncated}, 1), unmarshalCSVFloat64s, true},
+		{buildTestParquet([]testParquetChunk{id, fitness, codec}, 1), unmarshalCSVFloat64s, true},
+		{buildTestParquet([]testParquetChunk{id, fitness, encoding}, 1), unmarshalCSVFloat64s, true},
+		{buildTestParquet([]testParquetChunk{id, fitness, genome}, 2), unmarshalCSVFloat64s, true},
+		{nil, unmarshalCSVFloat64s, true},
+	}
+	for i, tc := range testCases {
+		var indis, err = ReadIndividualsParquet(bytes.NewReader(tc.data), tc.unmarshal, newRand())
+		if (err != nil) != tc.err {
+			t.Errorf("Error in test case number %d: %v", i, err)
+		}
+		if err == nil && (len(indis) != 1 || !reflect.DeepEqual(indis[0].Genome, Vector{1})) {
+			t.Errorf("Error in test case number %d: got %v", i, indis)
+		}
+	}
+}
+
+func TestPopulationParquet(t *testing.T) {
+	var (
+		rng  = newRand()
+		pop  = newPopulation(10, false, NewVector, rng)
+		buf  bytes.Buffer
+		pop2 = Population{RNG: rng}
+	)
+	pop.Individuals.Evaluate(false)
+	if err := pop.WriteParquet(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var data = buf.Bytes()
+	// Without any unmarshaler
+	if err := pop2.ReadParquet(bytes.NewReader(data), nil, false); err == nil {
+		t.Error("Expected an error")
+	}
+	// With the Population's JSON unmarshaler
+	pop2.JSONUnmarshaler = VectorJSONUnmarshaler
+	if err := pop2.ReadParquet(bytes.NewReader(data), nil, false); err != nil {
+		t.Fatal(err)
+	}
+	// Evaluation durations aren't exported
+	for i := range pop.Individuals {
+		pop.Individuals[i].EvalDuration = 0
+	}
+	if !reflect.DeepEqual(pop2.Individuals, pop.Individuals) {
+		t.Errorf("Expected %v, got %v", pop.Individuals, pop2.Individuals)
+	}
+}
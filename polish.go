@@ -0,0 +1,513 @@
+package eaopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// A FloatGenome is a Genome made of float64s, which the float Polishers
+// modify through the slice returned by Floats. Bounds returns the bounds of
+// each value, or nil slices if the values are unbounded. FloatVector
+// implements FloatGenome.
+type FloatGenome interface {
+	Genome
+	Floats() []float64
+	Bounds() (lower, upper []float64)
+}
+
+// A PermutationGenome is a Genome whose genes form a permutation, which
+// Polish2Opt reorders through the Slice returned by Permutation.
+type PermutationGenome interface {
+	Genome
+	Permutation() Slice
+}
+
+// A Polisher locally improves a Genome once a GA has finished, see
+// GAConfig.Polish. Polish calls evaluate at most budget times and returns the
+// best Genome found along with its fitness, which are genome and fitness if
+// no better Genome was found. genome isn't modified.
+type Polisher interface {
+	Polish(genome Genome, fitness float64, evaluate func(Genome) (float64, error), budget uint, rng *rand.Rand) (Genome, float64, error)
+}
+
+// errBudgetSpent stops a local search once its budget is spent.
+var errBudgetSpent = errors.New("budget spent")
+
+// A localSearch keeps track of the evaluations of a Polisher and of the best
+// Genome it evaluated. NaN fitnesses are treated as infinite.
+type localSearch struct {
+	evaluate    func(Genome) (float64, error)
+	budget      uint
+	spent       uint
+	best        Genome
+	bestFitness float64
+}
+
+// eval evaluates genome, errBudgetSpent is returned if the budget is spent.
+func (ls *localSearch) eval(genome Genome) (float64, error) {
+	if ls.spent >= ls.budget {
+		return 0, errBudgetSpent
+	}
+	ls.spent++
+	var fitness, err = ls.evaluate(genome)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(fitness) {
+		fitness = math.Inf(1)
+	}
+	if fitness < ls.bestFitness {
+		ls.best, ls.bestFitness = genome, fitness
+	}
+	return fitness, nil
+}
+
+// evalFloats evaluates a clone of base whose values are x, after x has been
+// clipped to the bounds of base.
+func (ls *localSearch) evalFloats(base FloatGenome, x []float64) (float64, error) {
+	var lower, upper = base.Bounds()
+	if lower != nil && upper != nil {
+		for i := range x {
+			x[i] = math.Max(lower[i], math.Min(upper[i], x[i]))
+		}
+	}
+	var genome = base.Clone()
+	copy(genome.(FloatGenome).Floats(), x)
+	return ls.eval(genome)
+}
+
+// result returns the best Genome, err is returned unless it is
+// errBudgetSpent.
+func (ls *localSearch) result(err error) (Genome, float64, error) {
+	if err != nil && err != errBudgetSpent {
+		return nil, 0, err
+	}
+	return ls.best, ls.bestFitness, nil
+}
+
+// startFloats returns the FloatGenome of genome along with the scale of each
+// dimension, which is the width of its bounds or 1 if it is unbounded.
+func startFloats(genome Genome) (FloatGenome, []float64, error) {
+	var fg, ok = genome.(FloatGenome)
+	if !ok {
+		return nil, nil, fmt.Errorf("expected a FloatGenome, got %T", genome)
+	}
+	var (
+		lower, upper = fg.Bounds()
+		scales       = make([]float64, len(fg.Floats()))
+	)
+	for i := range scales {
+		scales[i] = 1
+		if lower != nil && upper != nil {
+			scales[i] = upper[i] - lower[i]
+		}
+	}
+	return fg, scales, nil
+}
+
+// PolishNelderMead polishes FloatGenomes with the Nelder-Mead simplex method.
+// The initial simplex is made of the Genome and of one vertex per dimension,
+// offset by Step times the width of the bounds of the dimension, or by Step if
+// it is unbounded. Step is 0.1 if it is 0.
+type PolishNelderMead struct {
+	Step float64
+}
+
+// Validate PolishNelderMead fields.
+func (nm PolishNelderMead) Validate() error {
+	if nm.Step < 0 || math.IsNaN(nm.Step) {
+		return errors.New("Step should be positive")
+	}
+	return nil
+}
+
+// Polish implements the Polisher interface.
+func (nm PolishNelderMead) Polish(genome Genome, fitness float64, evaluate func(Genome) (float64, error), budget uint, rng *rand.Rand) (Genome, float64, error) {
+	var fg, scales, err = startFloats(genome)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ls = &localSearch{evaluate: evaluate, budget: budget, best: genome, bestFitness: fitness}
+	return ls.result(nm.search(ls, fg, fitness, scales))
+}
+
+// search runs the Nelder-Mead method until the budget is spent.
+func (nm PolishNelderMead) search(ls *localSearch, fg FloatGenome, fitness float64, scales []float64) error {
+	var (
+		step    = nm.Step
+		n       = len(scales)
+		simplex = make([][]float64, n+1)
+		fs      = make([]float64, n+1)
+		err     error
+	)
+	if step == 0 {
+		step = 0.1
+	}
+	if math.IsNaN(fitness) {
+		fitness = math.Inf(1)
+	}
+	simplex[0], fs[0] = copyFloat64s(fg.Floats()), fitness
+	for i := 0; i < n; i++ {
+		simplex[i+1] = copyFloat64s(simplex[0])
+		simplex[i+1][i] += step * scales[i]
+		if fs[i+1], err = ls.evalFloats(fg, simplex[i+1]); err != nil {
+			return err
+		}
+		// Go the other way if the bounds clipped the vertex onto the Genome
+		if simplex[i+1][i] == simplex[0][i] {
+			simplex[i+1][i] -= step * scales[i]
+			if fs[i+1], err = ls.evalFloats(fg, simplex[i+1]); err != nil {
+				return err
+			}
+		}
+	}
+	var (
+		order    = make([]int, n+1)
+		centroid = make([]float64, n)
+		point    = func(from []float64, coef float64) []float64 {
+			var p = make([]float64, n)
+			for i := range p {
+				p[i] = centroid[i] + coef*(from[i]-centroid[i])
+			}
+			return p
+		}
+	)
+	for {
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool { return fs[order[i]] < fs[order[j]] })
+		var (
+			best   = order[0]
+			second = order[n-1]
+			worst  = order[n]
+		)
+		for i := range centroid {
+			centroid[i] = 0
+			for _, k := range order[:n] {
+				centroid[i] += simplex[k][i] / float64(n)
+			}
+		}
+		// Reflection
+		var xr = point(simplex[worst], -1)
+		fr, err := ls.evalFloats(fg, xr)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fr < fs[best]:
+			// Expansion
+			var xe = point(simplex[worst], -2)
+			fe, err := ls.evalFloats(fg, xe)
+			if err != nil {
+				return err
+			}
+			if fe < fr {
+				simplex[worst], fs[worst] = xe, fe
+			} else {
+				simplex[worst], fs[worst] = xr, fr
+			}
+		case fr < fs[second]:
+			simplex[worst], fs[worst] = xr, fr
+		default:
+			// Outside or inside contraction
+			var xc = point(xr, 0.5)
+			if fr >= fs[worst] {
+				xc = point(simplex[worst], 0.5)
+			}
+			fc, err := ls.evalFloats(fg, xc)
+			if err != nil {
+				return err
+			}
+			if fc < math.Min(fr, fs[worst]) {
+				simplex[worst], fs[worst] = xc, fc
+				continue
+			}
+			// Shrink towards the best vertex
+			for _, k := range order[1:] {
+				for i := range simplex[k] {
+					simplex[k][i] = simplex[best][i] + 0.5*(simplex[k][i]-simplex[best][i])
+				}
+				if fs[k], err = ls.evalFloats(fg, simplex[k]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// PolishPatternSearch polishes FloatGenomes with a compass search: each value
+// is moved up and then down by the step size, the first move which improves
+// the Genome is kept. When no move improves the Genome the step size is
+// multiplied by Shrink. The initial step size is Step times the width of the
+// bounds of each dimension, or Step if it is unbounded. Step is 0.1 and
+// Shrink is 0.5 if they are 0.
+type PolishPatternSearch struct {
+	Step   float64
+	Shrink float64
+}
+
+// Validate PolishPatternSearch fields.
+func (ps PolishPatternSearch) Validate() error {
+	if ps.Step < 0 || math.IsNaN(ps.Step) {
+		return errors.New("Step should be positive")
+	}
+	if ps.Shrink < 0 || ps.Shrink >= 1 || math.IsNaN(ps.Shrink) {
+		return errors.New("Shrink should be in (0, 1)")
+	}
+	return nil
+}
+
+// Polish implements the Polisher interface.
+func (ps PolishPatternSearch) Polish(genome Genome, fitness float64, evaluate func(Genome) (float64, error), budget uint, rng *rand.Rand) (Genome, float64, error) {
+	var fg, scales, err = startFloats(genome)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ls = &localSearch{evaluate: evaluate, budget: budget, best: genome, bestFitness: fitness}
+	return ls.result(ps.search(ls, fg, fitness, scales))
+}
+
+// search runs the compass search until the budget is spent or the step size
+// vanishes.
+func (ps PolishPatternSearch) search(ls *localSearch, fg FloatGenome, fitness float64, scales []float64) error {
+	var (
+		step   = ps.Step
+		shrink = ps.Shrink
+		x      = copyFloat64s(fg.Floats())
+	)
+	if step == 0 {
+		step = 0.1
+	}
+	if shrink == 0 {
+		shrink = 0.5
+	}
+	if math.IsNaN(fitness) {
+		fitness = math.Inf(1)
+	}
+	for step > 1e-12 {
+		var improved bool
+		for i := range x {
+			for _, dir := range []float64{1, -1} {
+				var y = copyFloat64s(x)
+				y[i] += dir * step * scales[i]
+				var fy, err = ls.evalFloats(fg, y)
+				if err != nil {
+					return err
+				}
+				if fy < fitness {
+					x, fitness, improved = y, fy, true
+					break
+				}
+			}
+		}
+		if !improved {
+			step *= shrink
+		}
+	}
+	return nil
+}
+
+// Polish2Opt polishes PermutationGenomes with 2-opt: the segments of the
+// permutation are reversed one after the other and each reversal which
+// improves the Genome is kept, until no reversal improves it or the budget is
+// spent.
+type Polish2Opt struct{}
+
+// Validate Polish2Opt fields.
+func (p Polish2Opt) Validate() error {
+	return nil
+}
+
+// Polish implements the Polisher interface.
+func (p Polish2Opt) Polish(genome Genome, fitness float64, evaluate func(Genome) (float64, error), budget uint, rng *rand.Rand) (Genome, float64, error) {
+	if _, ok := genome.(PermutationGenome); !ok {
+		return nil, 0, fmt.Errorf("expected a PermutationGenome, got %T", genome)
+	}
+	var ls = &localSearch{evaluate: evaluate, budget: budget, best: genome, bestFitness: fitness}
+	return ls.result(p.search(ls, genome, fitness))
+}
+
+// search applies 2-opt moves to genome until none of them improves it.
+func (p Polish2Opt) search(ls *localSearch, genome Genome, fitness float64) error {
+	var n = genome.(PermutationGenome).Permutation().Len()
+	if math.IsNaN(fitness) {
+		fitness = math.Inf(1)
+	}
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				var candidate = genome.Clone()
+				var perm = candidate.(PermutationGenome).Permutation()
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					perm.Swap(a, b)
+				}
+				var f, err = ls.eval(candidate)
+				if err != nil {
+					return err
+				}
+				if f < fitness {
+					genome, fitness, improved = candidate, f, true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// defaultPolisher returns the Polisher suited to genome.
+func defaultPolisher(genome Genome) (Polisher, error) {
+	switch genome.(type) {
+	case FloatGenome:
+		return PolishNelderMead{}, nil
+	case PermutationGenome:
+		return Polish2Opt{}, nil
+	}
+	return nil, fmt.Errorf("no default Polisher for %T, which is neither a FloatGenome nor a PermutationGenome", genome)
+}
+
+// PolishOptions configure the polishing of the hall of fame once the last
+// generation has been evolved, see GAConfig.Polish. The NMembers best members
+// of the hall of fame, all of them if it is 0, are polished one after the
+// other with Polisher, each with a budget of Budget evaluations. If Polisher
+// is nil then FloatGenomes are polished with PolishNelderMead and
+// PermutationGenomes with Polish2Opt. Infeasible Genomes are given an
+// infinite fitness during polishing, hence feasible members stay feasible.
+type PolishOptions struct {
+	Polisher Polisher
+	Budget   uint
+	NMembers uint
+}
+
+// Validate PolishOptions fields.
+func (po PolishOptions) Validate() error {
+	if po.Budget == 0 {
+		return errors.New("Budget should be higher than 0")
+	}
+	if v, ok := po.Polisher.(Validatable); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// A PolishResult describes the polishing of a member of the hall of fame.
+// Individual is the refined member, which is the original one if polishing
+// didn't improve it, Before is its fitness before polishing and Delta is
+// Individual.Fitness - Before, hence it is negative if polishing improved the
+// member. Evaluations is the number of evaluations spent.
+type PolishResult struct {
+	Individual  Individual `json:"individual"`
+	Before      float64    `json:"before"`
+	Delta       float64    `json:"delta"`
+	Evaluations uint       `json:"evaluations"`
+}
+
+// PolishHallOfFame polishes the members of the hall of fame according to
+// opts, replaces them with their refined versions and returns the outcome for
+// each polished member, best member first. The evaluations are accounted for
+// by Evaluations. It is called by Minimize and Run if GAConfig.Polish is set,
+// and can be called after a GA has been stepped through.
+func (ga *GA) PolishHallOfFame(opts PolishOptions) ([]PolishResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if ga.RNG == nil {
+		ga.RNG = newRand()
+	}
+	var (
+		results []PolishResult
+		n       = len(ga.HallOfFame)
+	)
+	if opts.NMembers > 0 {
+		n = minInt(n, int(opts.NMembers))
+	}
+	for i, member := range ga.HallOfFame[:n] {
+		// Placeholders of a hall of fame that isn't full yet are skipped
+		if member.Genome == nil {
+			continue
+		}
+		var result, err = ga.polishMember(member, opts)
+		if err != nil {
+			return nil, err
+		}
+		ga.HallOfFame[i] = result.Individual
+		results = append(results, result)
+	}
+	if ga.ParetoHallOfFame {
+		// Refined members may dominate each other
+		var front = ga.HallOfFame
+		ga.HallOfFame = nil
+		ga.updateHallOfFame(front, ga.RNG)
+	} else {
+		sort.SliceStable(ga.HallOfFame, func(i, j int) bool { return ga.HallOfFame[i].Better(ga.HallOfFame[j]) })
+	}
+	return results, nil
+}
+
+// polishMember polishes a member of the hall of fame. The refined member is
+// the best Individual evaluated during polishing, which thus carries its
+// violation and objectives.
+func (ga *GA) polishMember(member Individual, opts PolishOptions) (PolishResult, error) {
+	var polisher = opts.Polisher
+	if polisher == nil {
+		var err error
+		if polisher, err = defaultPolisher(member.Genome); err != nil {
+			return PolishResult{}, err
+		}
+	}
+	var (
+		start    = member.Fitness
+		best     = member
+		spent    uint
+		evaluate = func(genome Genome) (float64, error) {
+			var indi = Individual{Genome: genome, Metadata: copyMetadata(member.Metadata), eval: ga.eval}
+			indi.ID = ga.eval.newID(genome, ga.RNG)
+			spent++
+			if err := indi.evaluate(); err != nil {
+				return 0, err
+			}
+			if indi.Better(best) {
+				best = indi
+			}
+			if indi.Violation > 0 {
+				return math.Inf(1), nil
+			}
+			return indi.Fitness, nil
+		}
+	)
+	if member.Violation > 0 {
+		start = math.Inf(1)
+	}
+	if _, _, err := polisher.Polish(member.Genome, start, evaluate, opts.Budget, ga.RNG); err != nil {
+		return PolishResult{}, err
+	}
+	return PolishResult{
+		Individual:  best,
+		Before:      member.Fitness,
+		Delta:       best.Fitness - member.Fitness,
+		Evaluations: spent,
+	}, nil
+}
+
+// polish polishes the hall of fame if GAConfig.Polish is set, the results are
+// available through Polished.
+func (ga *GA) polish() error {
+	ga.polished = nil
+	if ga.Polish == nil {
+		return nil
+	}
+	var results, err = ga.PolishHallOfFame(*ga.Polish)
+	ga.polished = results
+	return err
+}
+
+// Polished returns the outcome of the polishing of the hall of fame performed
+// at the end of the last run, whether it was evolved by Minimize, Run or Step,
+// see GAConfig.Polish and GA.Finish.
+func (ga *GA) Polished() []PolishResult {
+	return ga.polished
+}
@@ -0,0 +1,157 @@
+package eaopt
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// permutationGenome is a permutation whose fitness is the total displacement
+// of its elements, which is 0 once it is sorted.
+type permutationGenome []int
+
+func (pg permutationGenome) Evaluate() (float64, error) {
+	var sum float64
+	for i, x := range pg {
+		sum += math.Abs(float64(x - i))
+	}
+	return sum, nil
+}
+func (pg permutationGenome) Mutate(rng *rand.Rand) { MutPermuteInt(pg, 1, rng) }
+func (pg permutationGenome) Crossover(y Genome, rng *rand.Rand) {
+	CrossPMXInt(pg, y.(permutationGenome), rng)
+}
+func (pg permutationGenome) Clone() Genome      { return append(permutationGenome(nil), pg...) }
+func (pg permutationGenome) Permutation() Slice { return IntSlice(pg) }
+
+// countingEvaluate returns a function which evaluates Genomes and counts the
+// calls.
+func countingEvaluate(calls *uint) func(Genome) (float64, error) {
+	return func(genome Genome) (float64, error) {
+		*calls++
+		return genome.Evaluate()
+	}
+}
+
+func TestFloatPolishers(t *testing.T) {
+	var p = newTestFloatProblem()
+	for _, polisher := range []Polisher{PolishNelderMead{}, PolishPatternSearch{}} {
+		var (
+			start      = &FloatVector{Values: []float64{3.3, -2.2, 1.1}, Problem: p}
+			fitness, _ = start.Evaluate()
+			calls      uint
+		)
+		var genome, polished, err = polisher.Polish(start, fitness, countingEvaluate(&calls), 300, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls > 300 {
+			t.Errorf("%T: expected at most 300 evaluations, got %d", polisher, calls)
+		}
+		if f, _ := genome.Evaluate(); f != polished || polished > 1e-3 {
+			t.Errorf("%T: expected the sphere to be solved, got %f for %v", polisher, polished, genome)
+		}
+		if !reflect.DeepEqual(start.Values, []float64{3.3, -2.2, 1.1}) {
+			t.Errorf("%T: the Genome shouldn't be modified, got %v", polisher, start.Values)
+		}
+		// The bounds are respected
+		p.F = func(x []float64) (float64, error) { return -x[0] - x[1] - x[2], nil }
+		if genome, polished, err = polisher.Polish(start, 0, Genome.Evaluate, 300, nil); err != nil {
+			t.Fatal(err)
+		}
+		if polished != -15 || !reflect.DeepEqual(genome.(*FloatVector).Values, []float64{5, 5, 5}) {
+			t.Errorf("%T: expected the upper bounds, got %v", polisher, genome)
+		}
+		p.F = newTestFloatProblem().F
+		// Other Genomes are rejected
+		if _, _, err = polisher.Polish(Vector{1}, 1, Genome.Evaluate, 10, nil); err == nil {
+			t.Errorf("%T: expected an error", polisher)
+		}
+	}
+}
+
+func TestPolish2Opt(t *testing.T) {
+	var (
+		start = permutationGenome{0, 3, 2, 1, 4}
+		calls uint
+	)
+	var genome, fitness, err = Polish2Opt{}.Polish(start, 4, countingEvaluate(&calls), 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fitness != 0 || !reflect.DeepEqual(genome, permutationGenome{0, 1, 2, 3, 4}) {
+		t.Errorf("Expected the permutation to be sorted, got %v", genome)
+	}
+	// A pass without improvement ends the search
+	if calls != 20 {
+		t.Errorf("Expected 2 passes of 10 moves, got %d evaluations", calls)
+	}
+	// The budget is respected
+	calls = 0
+	if genome, fitness, err = (Polish2Opt{}).Polish(start, 4, countingEvaluate(&calls), 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || fitness != 4 || !reflect.DeepEqual(genome, start) {
+		t.Errorf("Expected the Genome after 2 evaluations, got %v after %d", genome, calls)
+	}
+	if _, _, err = (Polish2Opt{}).Polish(Vector{1}, 1, Genome.Evaluate, 10, nil); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestGAPolish(t *testing.T) {
+	var (
+		p     = newTestFloatProblem()
+		conf  = NewDefaultGAConfig()
+		evals uint64
+	)
+	conf.NGenerations = 5
+	conf.HofSize = 3
+	conf.Polish = &PolishOptions{Budget: 100, NMembers: 2}
+	conf.Callback = func(ga *GA) { evals = ga.Evaluations() }
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatal(err)
+	}
+	var results = ga.Polished()
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	var spent uint64
+	for _, r := range results {
+		if r.Delta > 0 || r.Delta != r.Individual.Fitness-r.Before || r.Evaluations > 100 {
+			t.Errorf("Inconsistent result %+v", r)
+		}
+		if f, _ := p.F(r.Individual.Genome.(*FloatVector).Values); f != r.Individual.Fitness {
+			t.Errorf("Expected fitness %f, got %f", f, r.Individual.Fitness)
+		}
+		if ga.HallOfFame[0].Fitness > r.Individual.Fitness {
+			t.Errorf("Expected the refined members in the hall of fame")
+		}
+		spent += uint64(r.Evaluations)
+	}
+	if results[0].Delta >= 0 {
+		t.Errorf("Expected the best member to be improved, got %+v", results[0])
+	}
+	if ga.Evaluations() != evals+spent {
+		t.Errorf("Expected %d evaluations, got %d", evals+spent, ga.Evaluations())
+	}
+	for i := 1; i < len(ga.HallOfFame); i++ {
+		if ga.HallOfFame[i].Better(ga.HallOfFame[i-1]) {
+			t.Errorf("The hall of fame isn't sorted: %v", ga.HallOfFame)
+		}
+	}
+	// Genomes without a default Polisher are rejected
+	conf.Callback = nil
+	ga, _ = conf.NewGA()
+	if err = ga.Minimize(NewVector); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err = ga.PolishHallOfFame(PolishOptions{}); err == nil {
+		t.Error("Expected an error")
+	}
+}
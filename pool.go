@@ -0,0 +1,100 @@
+package eaopt
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// A Resetter is a Genome that can be re-randomized in place, as if it had
+// just been returned by its factory, which lets a GenomePool reuse it instead
+// of allocating a new Genome. FloatVector, IntVector and BitString are
+// Resetters.
+type Resetter interface {
+	Reset(rng *rand.Rand)
+}
+
+// A GenomePool recycles the Genomes the GA discards, provided they are
+// Resetters, which reduces the pressure on the garbage collector when large
+// Genomes are created over and over, for instance by restarts or by random
+// immigrants. Its NewGenome method can be handed to GA.Minimize and GA.Init
+// in place of the factory it wraps. A GenomePool is safe for concurrent use,
+// hence it works with GAConfig.ParallelInit.
+type GenomePool struct {
+	newGenome func(rng *rand.Rand) Genome
+	mutex     sync.Mutex
+	free      []Genome
+	reused    uint64
+}
+
+// NewGenomePool returns an empty GenomePool which creates Genomes with
+// newGenome when it has none left to reuse.
+func NewGenomePool(newGenome func(rng *rand.Rand) Genome) *GenomePool {
+	return &GenomePool{newGenome: newGenome}
+}
+
+// NewGenome resets and returns a recycled Genome, or returns a new Genome if
+// the pool is empty.
+func (gp *GenomePool) NewGenome(rng *rand.Rand) Genome {
+	gp.mutex.Lock()
+	var n = len(gp.free)
+	if n == 0 {
+		gp.mutex.Unlock()
+		return gp.newGenome(rng)
+	}
+	var genome = gp.free[n-1]
+	gp.free[n-1] = nil
+	gp.free = gp.free[:n-1]
+	gp.reused++
+	gp.mutex.Unlock()
+	genome.(Resetter).Reset(rng)
+	return genome
+}
+
+// Put hands Genomes back to the pool. Genomes which aren't Resetters are
+// dropped. The Genomes shouldn't be used anymore by the caller.
+func (gp *GenomePool) Put(genomes ...Genome) {
+	gp.mutex.Lock()
+	defer gp.mutex.Unlock()
+	for _, genome := range genomes {
+		if _, ok := genome.(Resetter); ok {
+			gp.free = append(gp.free, genome)
+		}
+	}
+}
+
+// Len returns the number of Genomes waiting to be reused.
+func (gp *GenomePool) Len() int {
+	gp.mutex.Lock()
+	defer gp.mutex.Unlock()
+	return len(gp.free)
+}
+
+// Reused returns the number of Genomes NewGenome has recycled instead of
+// allocating them.
+func (gp *GenomePool) Reused() uint64 {
+	gp.mutex.Lock()
+	defer gp.mutex.Unlock()
+	return gp.reused
+}
+
+// recycle hands the Genome of indi back to the GA's GenomePool, if it has
+// one.
+func (ga *GA) recycle(indi Individual) {
+	if ga.GenomePool != nil && indi.Genome != nil {
+		ga.GenomePool.Put(indi.Genome)
+	}
+}
+
+// Restart discards the Populations, so that the next call to Init or Minimize
+// creates new ones, while the hall of fame is kept. The Genomes of the
+// discarded Individuals are handed to the GenomePool, if the GA has one, so
+// that the new Populations can reuse them by passing GenomePool.NewGenome to
+// Init or Minimize.
+func (ga *GA) Restart() {
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			ga.recycle(indi)
+		}
+	}
+	ga.Populations = nil
+}
@@ -0,0 +1,105 @@
+package eaopt
+
+import (
+	"testing"
+)
+
+func TestGenomePool(t *testing.T) {
+	var (
+		rng     = newRand()
+		problem = newTestFloatProblem()
+		pool    = NewGenomePool(problem.NewGenome)
+		g       = pool.NewGenome(rng).(*FloatVector)
+	)
+	if pool.Reused() != 0 {
+		t.Errorf("Expected a new Genome, got %d reused", pool.Reused())
+	}
+	var before = copyFloat64s(g.Values)
+	pool.Put(g, Vector{1, 2})
+	if pool.Len() != 1 {
+		t.Errorf("Expected the Vector to be dropped, got %d Genomes", pool.Len())
+	}
+	var h = pool.NewGenome(rng).(*FloatVector)
+	if h != g || pool.Reused() != 1 || pool.Len() != 0 {
+		t.Fatal("Expected the FloatVector to be reused")
+	}
+	for i, x := range h.Values {
+		if x == before[i] || x < problem.Lower[i] || x > problem.Upper[i] {
+			t.Errorf("Expected a new value within the bounds, got %v", h.Values)
+		}
+	}
+}
+
+func TestGenomeResetters(t *testing.T) {
+	var rng = newRand()
+	var iv = &IntVector{Values: make([]int, 3), Problem: &IntProblem{Domains: []IntDomain{{Min: 5, Max: 6}, {Min: 5, Max: 6}, {Min: 5, Max: 6}}}}
+	iv.Reset(rng)
+	for _, v := range iv.Values {
+		if v < 5 || v > 6 {
+			t.Errorf("Expected values in [5, 6], got %v", iv.Values)
+		}
+	}
+	var bs = &BitString{Bits: make([]bool, 64)}
+	bs.Reset(rng)
+	var ones int
+	for _, b := range bs.Bits {
+		if b {
+			ones++
+		}
+	}
+	if ones == 0 || ones == 64 {
+		t.Errorf("Expected random bits, got %d ones", ones)
+	}
+}
+
+func TestGARestart(t *testing.T) {
+	var (
+		problem = newTestFloatProblem()
+		pool    = NewGenomePool(problem.NewGenome)
+		conf    = NewDefaultGAConfig()
+	)
+	conf.NPops = 2
+	conf.PopSize = 20
+	conf.ParallelInit = true
+	conf.GenomePool = pool
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(pool.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var best = ga.HallOfFame[0]
+	ga.Restart()
+	if ga.Populations != nil || pool.Len() != 40 {
+		t.Fatalf("Expected 40 recycled Genomes, got %d", pool.Len())
+	}
+	if err = ga.Minimize(pool.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if pool.Reused() != 40 || ga.Generations != conf.NGenerations {
+		t.Errorf("Expected 40 reused Genomes and a new run, got %d and %d generations", pool.Reused(), ga.Generations)
+	}
+	if ga.HallOfFame[0].Fitness > best.Fitness {
+		t.Error("The hall of fame wasn't kept")
+	}
+	// Every Individual has its own Genome
+	var seen = make(map[*FloatVector]bool)
+	for _, pop := range ga.Populations {
+		for _, indi := range pop.Individuals {
+			var v = indi.Genome.(*FloatVector)
+			if seen[v] {
+				t.Fatal("Two Individuals share a Genome")
+			}
+			seen[v] = true
+		}
+	}
+	// Injected Genomes replace Individuals whose Genome is recycled
+	ga.Inject(pool.NewGenome(ga.RNG), pool.NewGenome(ga.RNG))
+	if err = ga.immigrate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if pool.Len() != 2 {
+		t.Errorf("Expected 2 recycled Genomes, got %d", pool.Len())
+	}
+}
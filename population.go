@@ -1,10 +1,13 @@
 package eaopt
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -12,14 +15,20 @@ import (
 
 // A Population contains individuals. Individuals mate within a population.
 // Individuals can migrate from one population to another. Each population has a
-// random number generator to bypass the global rand mutex.
+// random number generator to bypass the global rand mutex. SAState is only set
+// for Populations evolved with ModSimulatedAnnealing and Beliefs for those
+// evolved with ModCultural. StatsPolicy determines
+// how the logged statistics treat NaN and infinite fitnesses.
 type Population struct {
 	Individuals     Individuals                  `json:"indis"`
 	Age             time.Duration                `json:"age"`
 	Generations     uint                         `json:"generations"`
 	ID              string                       `json:"id"`
+	SAState         *SAState                     `json:"sa_state,omitempty"`
+	Beliefs         *BeliefSpace                 `json:"beliefs,omitempty"`
 	RNG             *rand.Rand                   `json:"-"`
 	JSONUnmarshaler func([]byte) (Genome, error) `json:"-"`
+	StatsPolicy     NonFinitePolicy              `json:"-"`
 }
 
 // Generate a new population.
@@ -35,6 +44,13 @@ func newPopulation(size uint, parallel bool, newGenome func(rng *rand.Rand) Geno
 	return pop
 }
 
+// SequentialPopulationID can be used as a GAConfig's PopulationIDFunc to name
+// the Populations "0", "1", "2", etc. Such IDs are stable across runs which
+// makes it easier to correlate the logs of multi-population runs.
+func SequentialPopulationID(i uint, rng *rand.Rand) string {
+	return strconv.FormatUint(uint64(i), 10)
+}
+
 func newPopulationsFromBytes(populationCount uint, b []byte, RNG *rand.Rand, unmarshaler func([]byte) (Genome, error)) ([]Population, error) {
 	pops := make([]Population, populationCount)
 	for i := range pops {
@@ -47,60 +63,110 @@ func newPopulationsFromBytes(populationCount uint, b []byte, RNG *rand.Rand, unm
 
 // Log a Population's current statistics with a provided log.Logger.
 func (pop Population) Log(logger *log.Logger) {
-	logger.Print(pop.stats())
+	logger.Print(pop.stats(false))
+}
+
+// LogQuartiles is like Log but also records the quartiles of the fitnesses.
+func (pop Population) LogQuartiles(logger *log.Logger) {
+	logger.Print(pop.stats(true))
+}
+
+// SLog records a Population's current statistics with a provided slog.Logger.
+// The statistics are only computed if the logger handles the level.
+func (pop Population) SLog(logger *slog.Logger, level slog.Level) {
+	var ctx = context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	var (
+		fs    = pop.Individuals.FitStats(pop.StatsPolicy)
+		attrs = []slog.Attr{
+			slog.String("pop_id", pop.ID),
+			slog.Uint64("generation", uint64(pop.Generations)),
+			slog.Float64("min", fs.Min),
+			slog.Float64("max", fs.Max),
+			slog.Float64("avg", fs.Avg),
+			slog.Float64("std", fs.Std),
+			slog.Float64("q1", fs.Q1),
+			slog.Float64("median", fs.Median),
+			slog.Float64("q3", fs.Q3),
+		}
+	)
+	if fs.NaNs > 0 {
+		attrs = append(attrs, slog.Int("nans", fs.NaNs))
+	}
+	if fs.Infs > 0 {
+		attrs = append(attrs, slog.Int("infs", fs.Infs))
+	}
+	attrs = append(attrs, slog.Duration("duration", pop.Age))
+	logger.LogAttrs(ctx, level, "population statistics", attrs...)
 }
 
 func (pop Population) String() string {
-	return pop.stats()
+	return pop.stats(false)
 }
 
-func (pop Population) stats() string {
-	return fmt.Sprintf("pop_id=%s min=%f max=%f avg=%f std=%f",
-		pop.ID,
-		pop.Individuals.FitMin(),
-		pop.Individuals.FitMax(),
-		pop.Individuals.FitAvg(),
-		pop.Individuals.FitStd(),
+func (pop Population) stats(quartiles bool) string {
+	var (
+		fs = pop.Individuals.FitStats(pop.StatsPolicy)
+		s  = fmt.Sprintf("pop_id=%s min=%f max=%f avg=%f std=%f",
+			pop.ID, fs.Min, fs.Max, fs.Avg, fs.Std)
 	)
+	if quartiles {
+		s += fmt.Sprintf(" q1=%f median=%f q3=%f", fs.Q1, fs.Median, fs.Q3)
+	}
+	// Make NaN and infinite fitnesses visible, even when they are skipped
+	if fs.NaNs > 0 {
+		s += fmt.Sprintf(" nans=%d", fs.NaNs)
+	}
+	if fs.Infs > 0 {
+		s += fmt.Sprintf(" infs=%d", fs.Infs)
+	}
+	return s
 }
 
 // UnmarshalJSON implements a JSON unmarshaler for Populations. This override
 // is required because the JSON unmarshaler has no idea how to create your
 // implementation of the Genome interface. See setup_test.go:VectorJSONUnmarshaler
 // for an example JSON unmarshaler function. See ga_test.go:TestMarshalGA for
-// an example of using this custom decoder in a GA instance.
+// an example of using this custom decoder in a GA instance. Populations
+// written by older versions of eaopt are migrated to the current
+// SchemaVersion.
 func (pop *Population) UnmarshalJSON(data []byte) error {
-
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := migratePopulation(doc); err != nil {
+		return err
+	}
 	var decoded struct {
-		Age         time.Duration
-		Generations uint
-		ID          string
-		Indis       []interface{}
+		Age         time.Duration   `json:"age"`
+		Generations uint            `json:"generations"`
+		ID          string          `json:"id"`
+		SAState     *SAState        `json:"sa_state"`
+		Beliefs     *BeliefSpace    `json:"beliefs"`
+		Indis       json.RawMessage `json:"indis"`
 	}
-	err := json.Unmarshal(data, &decoded)
+	data, err := json.Marshal(doc)
 	if err != nil {
 		return err
 	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
 
 	pop.Age = decoded.Age
 	pop.Generations = decoded.Generations
 	pop.ID = decoded.ID
-	if pop.JSONUnmarshaler != nil {
-		for _, v := range decoded.Indis {
-			val, err := json.Marshal(v.(map[string]interface{})["genome"])
-			if err != nil {
-				return err
-			}
-			genome, err := pop.JSONUnmarshaler(val)
-			if err != nil {
-				return err
-			}
-			pop.Individuals = append(pop.Individuals, Individual{
-				Genome:  genome,
-				Fitness: v.(map[string]interface{})["fitness"].(float64),
-				ID:      v.(map[string]interface{})["id"].(string),
-			})
+	pop.SAState = decoded.SAState
+	pop.Beliefs = decoded.Beliefs
+	if pop.JSONUnmarshaler != nil && len(decoded.Indis) > 0 {
+		indis, err := unmarshalIndividualsJSON(decoded.Indis, pop.JSONUnmarshaler)
+		if err != nil {
+			return err
 		}
+		pop.Individuals = append(pop.Individuals, indis...)
 	}
 	return nil
 }
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"log"
+	"log/slog"
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPopLog(t *testing.T) {
@@ -21,6 +23,45 @@ func TestPopLog(t *testing.T) {
 	if s := b.String(); s != expected {
 		t.Errorf("Expected %s, got %s", expected, s)
 	}
+	b.Reset()
+	pop.LogQuartiles(logger)
+	expected = "pop_id=KVm min=-21.342844 max=18.440761 avg=-1.404246 std=11.739691 " +
+		"q1=-11.763828 median=-1.448118 q3=7.861638\n"
+	if s := b.String(); s != expected {
+		t.Errorf("Expected %s, got %s", expected, s)
+	}
+}
+
+func TestPopSLog(t *testing.T) {
+	var (
+		pop     = newPopulation(42, false, NewVector, rand.New(rand.NewSource(42)))
+		b       bytes.Buffer
+		handler = slog.NewTextHandler(&b, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})
+		logger = slog.New(handler)
+	)
+	pop.Individuals.Evaluate(false)
+	pop.Generations = 3
+	pop.Age = time.Second
+	pop.SLog(logger, slog.LevelInfo)
+	var expected = `level=INFO msg="population statistics" pop_id=KVm generation=3 min=-21.34284403527277 ` +
+		`max=18.44076091810043 avg=-1.4042463435511898 std=11.739691098291475 q1=-11.763827718622535 ` +
+		`median=-1.448118319816786 q3=7.861638140489157 duration=1s` + "\n"
+	if s := b.String(); s != expected {
+		t.Errorf("Expected %s, got %s", expected, s)
+	}
+	// Levels that are not handled are ignored
+	b.Reset()
+	pop.SLog(logger, slog.LevelDebug)
+	if b.Len() != 0 {
+		t.Errorf("Expected nothing, got %s", b.String())
+	}
 }
 
 func TestPopJSONMarshal(t *testing.T) {
@@ -46,6 +87,26 @@ func TestPopJSONMarshal(t *testing.T) {
 	}
 }
 
+func TestPopJSONMarshalMetadata(t *testing.T) {
+	var pop1 = newPopulation(3, false, NewVector, newRand())
+	pop1.Individuals.Evaluate(false)
+	pop1.Individuals[1].Metadata = map[string]interface{}{"origin": "seed", "violation": 1.5}
+	var encoded, err = json.Marshal(pop1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pop2 = Population{JSONUnmarshaler: VectorJSONUnmarshaler}
+	if err = json.Unmarshal(encoded, &pop2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pop2.Individuals[1].Metadata, pop1.Individuals[1].Metadata) {
+		t.Errorf("Expected %v, got %v", pop1.Individuals[1].Metadata, pop2.Individuals[1].Metadata)
+	}
+	if pop2.Individuals[0].Metadata != nil {
+		t.Errorf("Expected nil, got %v", pop2.Individuals[0].Metadata)
+	}
+}
+
 func TestPopsJSONMarshal(t *testing.T) {
 	pop1 := newPopulation(3, false, NewVector, rand.New(rand.NewSource(42)))
 	_ = pop1.Individuals.Evaluate(false)
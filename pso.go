@@ -62,6 +62,11 @@ func (p *Particle) Mutate(rng *rand.Rand) {
 		ss += rX[i] * rX[i]
 	}
 	ss = math.Sqrt(ss)
+	// The random vector is null when the Particle lies on both its best
+	// position and the global best position, normalizing it would produce NaNs
+	if ss == 0 {
+		ss = 1
+	}
 	for i, xi := range p.CurrentX {
 		p.Velocity[i] = p.SPSO.W*p.Velocity[i] + rX[i]/ss - xi
 		p.CurrentX[i] += p.Velocity[i]
@@ -0,0 +1,175 @@
+package eaopt
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// A PendingPolicy determines what happens to the Individuals which couldn't
+// be evaluated before the end of a generation, see RealTimeOptions.
+type PendingPolicy uint8
+
+const (
+	// CarryOver gives pending Individuals an infinite fitness, and an infinite
+	// violation if the GA has a ConstraintHandler, and evaluates them at the
+	// start of the next generation, before the Model is applied, as far as the
+	// budget of that generation allows.
+	CarryOver PendingPolicy = iota
+	// InheritFitness lets pending Individuals keep the fitness of the parent
+	// they were cloned from, which the Model uses as an estimate. They are
+	// evaluated once they make it into a generation which has enough time
+	// left, provided that the Model keeps them.
+	InheritFitness
+)
+
+// RealTimeOptions put the GA in soft real-time mode, where each generation has
+// a wall-clock Budget, for instance the tick of a game loop or of a control
+// system. Evaluations don't start once a generation has spent its Budget and
+// the Individuals left are handled according to Pending. Evaluations which
+// have started can't be interrupted, hence a generation lasts longer than
+// Budget by up to the duration of an evaluation, which EvalTimeout can bound.
+// Pending Individuals count as evaluated for the Model, whose selectors thus
+// don't evaluate them, but they don't enter the hall of fame. The initial
+// Populations are evaluated entirely.
+type RealTimeOptions struct {
+	Budget  time.Duration
+	Pending PendingPolicy
+}
+
+// Validate RealTimeOptions fields.
+func (rt RealTimeOptions) Validate() error {
+	if rt.Budget <= 0 {
+		return errors.New("Budget should be positive")
+	}
+	if rt.Pending > InheritFitness {
+		return errors.New("Pending should be CarryOver or InheritFitness")
+	}
+	return nil
+}
+
+// deadline returns the time at which a generation which started at start has
+// spent its budget, or the zero time if the GA isn't in real-time mode.
+func (ga *GA) deadline(start time.Time) time.Time {
+	if ga.RealTime == nil {
+		return time.Time{}
+	}
+	return start.Add(ga.RealTime.Budget)
+}
+
+// evaluateInTime evaluates the Individuals which haven't been evaluated yet,
+// pending ones included, until deadline, and marks the ones left as pending.
+func (ga *GA) evaluateInTime(indis Individuals, queue *evalQueue, deadline time.Time) error {
+	for i := range indis {
+		if indis[i].pending {
+			indis[i].Evaluated = false
+		}
+	}
+	if err := queue.evaluateBy(indis, ga.ParallelEval, deadline); err != nil {
+		return err
+	}
+	for i := range indis {
+		if indis[i].Evaluated {
+			continue
+		}
+		if ga.RealTime.Pending == CarryOver {
+			rankLast(&indis[i])
+		}
+		indis[i].Evaluated = true
+		indis[i].pending = true
+	}
+	return nil
+}
+
+// carryOver evaluates the Individuals of pop which were left pending by the
+// previous generation, as long as deadline hasn't passed, and sorts pop again.
+func (ga *GA) carryOver(pop *Population, queue *evalQueue, deadline time.Time) error {
+	if ga.RealTime == nil || ga.RealTime.Pending != CarryOver || countPending(pop.Individuals) == 0 {
+		return nil
+	}
+	if err := ga.evaluateInTime(pop.Individuals, queue, deadline); err != nil {
+		return err
+	}
+	pop.Individuals.SortByFitness()
+	return nil
+}
+
+// settled returns the Individuals of indis which are neither pending nor
+// skipped, in the same order.
+func settled(indis Individuals) Individuals {
+	var unsettled int
+	for _, indi := range indis {
+		if indi.pending || indi.Skipped {
+			unsettled++
+		}
+	}
+	if unsettled == 0 {
+		return indis
+	}
+	var evaluated = make(Individuals, 0, len(indis)-unsettled)
+	for _, indi := range indis {
+		if !indi.pending && !indi.Skipped {
+			evaluated = append(evaluated, indi)
+		}
+	}
+	return evaluated
+}
+
+// Pending returns the number of Individuals of the GA's Populations whose
+// fitness is pending, which can only be positive in real-time mode, see
+// GAConfig.RealTime.
+func (ga *GA) Pending() int {
+	var n int
+	for _, pop := range ga.Populations {
+		n += countPending(pop.Individuals)
+	}
+	return n
+}
+
+// countPending counts the pending Individuals.
+func countPending(indis Individuals) int {
+	var n int
+	for _, indi := range indis {
+		if indi.pending {
+			n++
+		}
+	}
+	return n
+}
+
+// evaluateBy evaluates the Individuals in order, in parallel or not, and
+// doesn't start any evaluation once deadline has passed.
+func (indis Individuals) evaluateBy(parallel bool, deadline time.Time) error {
+	if !parallel {
+		for i := range indis {
+			if time.Now().After(deadline) {
+				return nil
+			}
+			if err := indis[i].Evaluate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var (
+		next atomic.Int64
+		g    errgroup.Group
+	)
+	for w := 0; w < minInt(runtime.GOMAXPROCS(-1), len(indis)); w++ {
+		g.Go(func() error {
+			for {
+				var i = int(next.Add(1)) - 1
+				if i >= len(indis) || time.Now().After(deadline) {
+					return nil
+				}
+				if err := indis[i].Evaluate(); err != nil {
+					return err
+				}
+			}
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,187 @@
+package eaopt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func countUnevaluated(indis Individuals) int {
+	var n int
+	for _, indi := range indis {
+		if !indi.Evaluated {
+			n++
+		}
+	}
+	return n
+}
+
+func TestIndividualsEvaluateBy(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		var indis = newIndividuals(20, false, NewVector, newRand())
+		if err := indis.evaluateBy(parallel, time.Now().Add(-time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		if n := countUnevaluated(indis); n != 20 {
+			t.Errorf("Parallel %t: expected 20 unevaluated Individuals past the deadline, got %d", parallel, n)
+		}
+		if err := indis.evaluateBy(parallel, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if n := countUnevaluated(indis); n != 0 {
+			t.Errorf("Parallel %t: expected no unevaluated Individuals, got %d", parallel, n)
+		}
+		indis[0] = NewIndividual(ErrorGenome{}, newRand())
+		if err := indis.evaluateBy(parallel, time.Now().Add(time.Hour)); err == nil {
+			t.Errorf("Parallel %t: expected an error", parallel)
+		}
+	}
+}
+
+func TestGARealTime(t *testing.T) {
+	var testCases = []struct {
+		pending      PendingPolicy
+		parallelEval bool
+		async        bool
+	}{
+		{CarryOver, false, false},
+		{InheritFitness, false, false},
+		{CarryOver, true, false},
+		{InheritFitness, true, false},
+		{CarryOver, false, true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var (
+				p    = newTestFloatProblem()
+				conf = NewDefaultGAConfig()
+			)
+			conf.NPops = 2
+			conf.NGenerations = 5
+			conf.ParallelEval = tc.parallelEval
+			conf.SingleThreaded = false
+			conf.AsyncMigration = tc.async
+			// The deadline of each generation has passed before the first
+			// evaluation, hence only the initial Populations are evaluated
+			conf.RealTime = &RealTimeOptions{Budget: time.Nanosecond, Pending: tc.pending}
+			var ga, err = conf.NewGA()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = ga.Minimize(p.NewGenome); err != nil {
+				t.Fatal(err)
+			}
+			if ga.Evaluations() != 60 {
+				t.Errorf("Expected 60 evaluations, got %d", ga.Evaluations())
+			}
+			if ga.Pending() == 0 {
+				t.Error("Expected pending Individuals")
+			}
+			// Offsprings which are unmodified clones of evaluated Individuals
+			// aren't pending
+			for _, pop := range ga.Populations {
+				for _, indi := range pop.Individuals {
+					if !indi.Evaluated {
+						t.Errorf("Expected pending Individuals to count as evaluated, got %+v", indi)
+					}
+					if f, _ := indi.Genome.Evaluate(); !indi.pending && f != indi.Fitness {
+						t.Errorf("Expected fitness %f, got %f", f, indi.Fitness)
+					}
+					if indi.pending && math.IsInf(indi.Fitness, 1) != (tc.pending == CarryOver) {
+						t.Errorf("Unexpected fitness %f for a pending Individual", indi.Fitness)
+					}
+				}
+			}
+			// The hall of fame only contains evaluated Individuals
+			if f, _ := ga.HallOfFame[0].Genome.Evaluate(); f != ga.HallOfFame[0].Fitness {
+				t.Errorf("Expected fitness %f, got %f", f, ga.HallOfFame[0].Fitness)
+			}
+			// With enough time every Individual is evaluated, the pending ones
+			// first with CarryOver
+			ga.RealTime = &RealTimeOptions{Budget: time.Hour, Pending: tc.pending}
+			ga.NGenerations = 1
+			if err = ga.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if ga.Pending() != 0 {
+				t.Errorf("Expected no pending Individuals, got %d", ga.Pending())
+			}
+		})
+	}
+}
+
+func TestGARealTimeCheckpoint(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 3
+	conf.ParallelEval = true
+	conf.SingleThreaded = false
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	conf.ConstraintHandler = func(g Genome) float64 { return SumViolations(-g.(Vector)[0]) }
+	conf.RealTime = &RealTimeOptions{Budget: time.Nanosecond, Pending: CarryOver}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	if ga.Pending() == 0 {
+		t.Fatal("Expected pending Individuals")
+	}
+	// Pending Individuals have an infinite fitness and violation
+	var countInfinite = func(ga *GA) int {
+		var n int
+		for _, pop := range ga.Populations {
+			for _, indi := range pop.Individuals {
+				if math.IsInf(indi.Violation, 1) && indi.pending {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	if countInfinite(ga) != ga.Pending() {
+		t.Fatalf("Expected %d pending Individuals with an infinite violation, got %d", ga.Pending(), countInfinite(ga))
+	}
+	for i, opts := range []CheckpointOptions{{}, {Gzip: true}} {
+		var buf bytes.Buffer
+		if err = ga.WriteCheckpoint(&buf, opts); err != nil {
+			t.Fatalf("TC %d: %v", i, err)
+		}
+		var resumed, _ = conf.NewGA()
+		if err = resumed.ReadCheckpoint(&buf, opts); err != nil {
+			t.Fatalf("TC %d: %v", i, err)
+		}
+		if resumed.Pending() != ga.Pending() || countInfinite(resumed) != ga.Pending() {
+			t.Errorf("TC %d: expected %d pending Individuals with an infinite violation, got %d and %d", i,
+				ga.Pending(), resumed.Pending(), countInfinite(resumed))
+		}
+		// The pending Individuals are evaluated once the budget allows it
+		resumed.RealTime = &RealTimeOptions{Budget: time.Hour, Pending: CarryOver}
+		resumed.NGenerations = 1
+		if err = resumed.Minimize(NewVector); err != nil {
+			t.Fatalf("TC %d: %v", i, err)
+		}
+		if resumed.Pending() != 0 {
+			t.Errorf("TC %d: expected no pending Individuals, got %d", i, resumed.Pending())
+		}
+	}
+}
+
+func TestRealTimeOptionsValidate(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	for _, rt := range []RealTimeOptions{{}, {Budget: -1}, {Budget: time.Second, Pending: 2}} {
+		conf.RealTime = &rt
+		if _, err := conf.NewGA(); err == nil {
+			t.Errorf("Expected an error for %+v", rt)
+		}
+	}
+	conf.RealTime = &RealTimeOptions{Budget: time.Second}
+	conf.Novelty = &NoveltyOptions{}
+	if _, err := conf.NewGA(); err == nil {
+		t.Error("Expected an error for RealTime with Novelty")
+	}
+}
@@ -0,0 +1,116 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+)
+
+// A RegressionTolerance bounds how much worse than a baseline a statistic may
+// get before it is deemed a regression. The allowed increase of a statistic
+// whose baseline value is b is Absolute + Relative * |b|, hence a zero
+// RegressionTolerance only accepts results at least as good as the baseline.
+type RegressionTolerance struct {
+	Absolute float64 `json:"absolute"`
+	Relative float64 `json:"relative"`
+}
+
+// Validate RegressionTolerance fields.
+func (tol RegressionTolerance) Validate() error {
+	if tol.Absolute < 0 || math.IsNaN(tol.Absolute) {
+		return errors.New("Absolute should be positive")
+	}
+	if tol.Relative < 0 || math.IsNaN(tol.Relative) {
+		return errors.New("Relative should be positive")
+	}
+	return nil
+}
+
+// allowed returns the allowed increase of a statistic whose baseline value is
+// baseline.
+func (tol RegressionTolerance) allowed(baseline float64) float64 {
+	return tol.Absolute + tol.Relative*math.Abs(baseline)
+}
+
+// A RegressionCheck compares one statistic of a convergence curve with its
+// baseline value. Delta is Value - Baseline, fitnesses being minimized a
+// positive Delta means the new runs are worse. The check passes if Delta
+// doesn't exceed Allowed.
+type RegressionCheck struct {
+	Statistic string  `json:"statistic"`
+	Baseline  float64 `json:"baseline"`
+	Value     float64 `json:"value"`
+	Delta     float64 `json:"delta"`
+	Allowed   float64 `json:"allowed"`
+	Pass      bool    `json:"pass"`
+}
+
+// A RegressionReport contains the outcome of CheckRegression. Pass is true if
+// every check passed.
+type RegressionReport struct {
+	Pass   bool              `json:"pass"`
+	Checks []RegressionCheck `json:"checks"`
+}
+
+// CheckRegression compares the convergence curve of new runs with a baseline
+// curve, typically the output of SeedRuns.Convergence stored as JSON by a
+// previous run, so that a drop in optimization quality can fail a pipeline.
+// The following statistics are checked against tol, in this order:
+//
+//   - "final_median", the median best fitness at the end of the runs
+//   - "final_q3", the third quartile of the best fitness at the end of the
+//     runs, which catches configurations that fail on some seeds
+//   - "mean_median", the median best fitness averaged over the generations,
+//     which is the normalized area under the curve and catches configurations
+//     that converge more slowly
+//
+// The statistics are computed over the generations of the baseline. If the
+// new curve is shorter, its last point is carried over to the end of the
+// baseline, which is how Convergence treats runs that stopped early.
+func CheckRegression(baseline, curve []ConvergencePoint, tol RegressionTolerance) (RegressionReport, error) {
+	if len(baseline) == 0 || len(curve) == 0 {
+		return RegressionReport{}, errors.New("both curves should be non-empty")
+	}
+	if err := tol.Validate(); err != nil {
+		return RegressionReport{}, err
+	}
+	var (
+		last  = len(baseline) - 1
+		point = func(g int) ConvergencePoint { return curve[minInt(g, len(curve)-1)] }
+		bArea float64
+		cArea float64
+		check = func(name string, b, v float64) RegressionCheck {
+			var c = RegressionCheck{
+				Statistic: name,
+				Baseline:  b,
+				Value:     v,
+				Delta:     v - b,
+				Allowed:   tol.allowed(b),
+			}
+			// Equal infinite values have a NaN Delta but aren't a regression
+			c.Pass = v <= b || c.Delta <= c.Allowed
+			return c
+		}
+	)
+	for g := range baseline {
+		bArea += baseline[g].Median
+		cArea += point(g).Median
+	}
+	var report = RegressionReport{
+		Checks: []RegressionCheck{
+			check("final_median", baseline[last].Median, point(last).Median),
+			check("final_q3", baseline[last].Q3, point(last).Q3),
+			check("mean_median", bArea/float64(len(baseline)), cArea/float64(len(baseline))),
+		},
+		Pass: true,
+	}
+	for _, c := range report.Checks {
+		report.Pass = report.Pass && c.Pass
+	}
+	return report, nil
+}
+
+// CheckRegression compares the convergence curve of the runs with a baseline
+// curve, see the CheckRegression function.
+func (sr SeedRuns) CheckRegression(baseline []ConvergencePoint, tol RegressionTolerance) (RegressionReport, error) {
+	return CheckRegression(baseline, sr.Convergence(), tol)
+}
@@ -0,0 +1,129 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCheckRegression(t *testing.T) {
+	var baseline = []ConvergencePoint{
+		{0, 10, 10, 8, 12},
+		{1, 5, 5, 4, 6},
+		{2, 2, 2, 1, 3},
+	}
+	var testCases = []struct {
+		curve  []ConvergencePoint
+		tol    RegressionTolerance
+		passes []bool
+	}{
+		// Identical curves pass with no tolerance
+		{baseline, RegressionTolerance{}, []bool{true, true, true}},
+		// A worse final quartile is caught
+		{
+			[]ConvergencePoint{{0, 10, 10, 8, 12}, {1, 5, 5, 4, 6}, {2, 2, 2, 1, 4}},
+			RegressionTolerance{Absolute: 0.5},
+			[]bool{true, false, true},
+		},
+		// ... unless it is within the relative tolerance
+		{
+			[]ConvergencePoint{{0, 10, 10, 8, 12}, {1, 5, 5, 4, 6}, {2, 2, 2, 1, 4}},
+			RegressionTolerance{Relative: 0.5},
+			[]bool{true, true, true},
+		},
+		// A shorter curve is extended with its last point
+		{
+			[]ConvergencePoint{{0, 10, 10, 8, 12}, {1, 5, 5, 4, 6}},
+			RegressionTolerance{Absolute: 0.5},
+			[]bool{false, false, false},
+		},
+		// A longer curve is cut at the end of the baseline
+		{
+			[]ConvergencePoint{{0, 9, 9, 8, 12}, {1, 5, 5, 4, 6}, {2, 2, 2, 1, 3}, {3, 0, 0, 0, 0}},
+			RegressionTolerance{},
+			[]bool{true, true, true},
+		},
+	}
+	for i, tc := range testCases {
+		var report, err = CheckRegression(baseline, tc.curve, tc.tol)
+		if err != nil {
+			t.Fatalf("Error in test case number %d: %v", i, err)
+		}
+		var pass = true
+		for j, c := range report.Checks {
+			if c.Pass != tc.passes[j] {
+				t.Errorf("Error in test case number %d: expected %s to pass=%t, got %+v", i, c.Statistic, tc.passes[j], c)
+			}
+			pass = pass && c.Pass
+		}
+		if report.Pass != pass {
+			t.Errorf("Error in test case number %d: expected pass=%t", i, pass)
+		}
+	}
+	// Deltas
+	var report, _ = CheckRegression(baseline, testCases[3].curve, RegressionTolerance{Absolute: 1, Relative: 0.5})
+	var expected = []RegressionCheck{
+		{"final_median", 2, 5, 3, 2, false},
+		{"final_q3", 3, 6, 3, 2.5, false},
+		{"mean_median", 17.0 / 3, 20.0 / 3, 1, 1 + 17.0/6, true},
+	}
+	for i, c := range report.Checks {
+		if c.Statistic != expected[i].Statistic || c.Pass != expected[i].Pass ||
+			math.Abs(c.Delta-expected[i].Delta) > 1e-9 || math.Abs(c.Allowed-expected[i].Allowed) > 1e-9 {
+			t.Errorf("Expected %+v, got %+v", expected[i], c)
+		}
+	}
+	// Infinite fitnesses
+	var inf = []ConvergencePoint{{0, math.Inf(1), math.Inf(1), math.Inf(1), math.Inf(1)}}
+	if report, _ = CheckRegression(inf, inf, RegressionTolerance{}); !report.Pass {
+		t.Errorf("Expected equal infinite curves to pass, got %+v", report)
+	}
+	if report, _ = CheckRegression(baseline[:1], inf, RegressionTolerance{Relative: 10}); report.Pass {
+		t.Errorf("Expected an infinite curve to fail, got %+v", report)
+	}
+	// Errors
+	if _, err := CheckRegression(nil, baseline, RegressionTolerance{}); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err := CheckRegression(baseline, baseline, RegressionTolerance{Relative: -1}); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestSeedRunsCheckRegression(t *testing.T) {
+	var (
+		conf  = NewDefaultGAConfig()
+		seeds = []int64{1, 2, 3, 4, 5}
+	)
+	conf.NGenerations = 10
+	var runs, err = RunSeeds(conf, NewVector, seeds, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The baseline survives a JSON round trip
+	var (
+		b        []byte
+		baseline []ConvergencePoint
+	)
+	if b, err = json.Marshal(runs.Convergence()); err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(b, &baseline); err != nil {
+		t.Fatal(err)
+	}
+	report, err := runs.CheckRegression(baseline, RegressionTolerance{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Pass {
+		t.Errorf("Expected the same runs to pass, got %+v", report)
+	}
+	// Fewer generations converge less
+	conf.NGenerations = 1
+	if runs, err = RunSeeds(conf, NewVector, seeds, true); err != nil {
+		t.Fatal(err)
+	}
+	if report, _ = runs.CheckRegression(baseline, RegressionTolerance{}); report.Pass {
+		t.Errorf("Expected a regression, got %+v", report)
+	}
+}
@@ -0,0 +1,286 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// SeedRuns contains the results of running the same GAConfig with different
+// seeds. Trajectories[i] contains the best fitness found with Seeds[i] after
+// the initialization and after each generation.
+type SeedRuns struct {
+	Seeds        []int64
+	Trajectories [][]float64
+}
+
+// RunSeeds runs conf once per seed, optionally in parallel, and records the
+// best fitness at each generation. conf's RNG is replaced by one seeded with
+// each seed, and its Callback, if any, is still called. Note that the Callback
+// is called concurrently if parallel is true.
+func RunSeeds(conf GAConfig, newGenome func(rng *rand.Rand) Genome, seeds []int64, parallel bool) (SeedRuns, error) {
+	var runs = SeedRuns{
+		Seeds:        append([]int64{}, seeds...),
+		Trajectories: make([][]float64, len(seeds)),
+	}
+	var run = func(i int) error {
+		var (
+			c        = conf
+			callback = conf.Callback
+		)
+		c.RNG = rand.New(rand.NewSource(seeds[i]))
+		c.Callback = func(ga *GA) {
+			runs.Trajectories[i] = append(runs.Trajectories[i], ga.best().Fitness)
+			if callback != nil {
+				callback(ga)
+			}
+		}
+		var ga, err = c.NewGA()
+		if err != nil {
+			return err
+		}
+		return ga.Minimize(newGenome)
+	}
+	if !parallel {
+		for i := range seeds {
+			if err := run(i); err != nil {
+				return runs, err
+			}
+		}
+		return runs, nil
+	}
+	var g errgroup.Group
+	for i := range seeds {
+		i := i // https://golang.org/doc/faq#closures_and_goroutines
+		g.Go(func() error { return run(i) })
+	}
+	return runs, g.Wait()
+}
+
+// Final returns the best fitness obtained at the end of each run.
+func (sr SeedRuns) Final() []float64 {
+	var final = make([]float64, len(sr.Trajectories))
+	for i, traj := range sr.Trajectories {
+		final[i] = math.Inf(1)
+		if len(traj) > 0 {
+			final[i] = traj[len(traj)-1]
+		}
+	}
+	return final
+}
+
+// A ConvergencePoint summarizes the best fitnesses of several runs at a given
+// generation, generation 0 being the initialization.
+type ConvergencePoint struct {
+	Generation uint    `json:"generation"`
+	Mean       float64 `json:"mean"`
+	Median     float64 `json:"median"`
+	Q1         float64 `json:"q1"` // First quartile
+	Q3         float64 `json:"q3"` // Third quartile
+}
+
+// Convergence returns the convergence curve of the runs. Runs that stopped
+// early, for instance because of an EarlyStop, keep their last best fitness
+// until the end of the longest run.
+func (sr SeedRuns) Convergence() []ConvergencePoint {
+	var n int
+	for _, traj := range sr.Trajectories {
+		if len(traj) > n {
+			n = len(traj)
+		}
+	}
+	var (
+		curve  = make([]ConvergencePoint, n)
+		values = make([]float64, 0, len(sr.Trajectories))
+	)
+	for g := range curve {
+		values = values[:0]
+		for _, traj := range sr.Trajectories {
+			if len(traj) > 0 {
+				values = append(values, traj[minInt(g, len(traj)-1)])
+			}
+		}
+		sort.Float64s(values)
+		curve[g] = ConvergencePoint{
+			Generation: uint(g),
+			Mean:       meanFloat64s(values),
+			Median:     quantile(values, 0.5),
+			Q1:         quantile(values, 0.25),
+			Q3:         quantile(values, 0.75),
+		}
+	}
+	return curve
+}
+
+// quantile returns the q-th quantile of sorted values by linearly interpolating
+// between the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	var (
+		h    = q * float64(len(sorted)-1)
+		i    = int(math.Floor(h))
+		frac = h - float64(i)
+	)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	// Interpolating would turn infinite values into NaNs
+	if frac == 0 || sorted[i] == sorted[i+1] {
+		return sorted[i]
+	}
+	return sorted[i] + frac*(sorted[i+1]-sorted[i])
+}
+
+// ranks returns the ranks of values, starting at 1, with ties given their
+// average rank. It also returns the sum of t^3 - t over the groups of t ties,
+// which is used to correct the variance of rank statistics.
+func ranks(values []float64) ([]float64, float64) {
+	var (
+		n     = len(values)
+		order = make([]int, n)
+		r     = make([]float64, n)
+		ties  float64
+	)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+	for i := 0; i < n; {
+		var j = i + 1
+		for j < n && values[order[j]] == values[order[i]] {
+			j++
+		}
+		var avg = float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			r[order[k]] = avg
+		}
+		if t := float64(j - i); t > 1 {
+			ties += t*t*t - t
+		}
+		i = j
+	}
+	return r, ties
+}
+
+// twoSidedP returns the two-sided p-value of a standard normal statistic.
+func twoSidedP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// MannWhitneyU compares two independent samples with the Mann-Whitney U test
+// (also known as the Wilcoxon rank-sum test). It returns the U statistic of x
+// and the two-sided p-value of the null hypothesis that both samples come from
+// the same distribution. The p-value uses the normal approximation with tie
+// and continuity corrections, which is reliable when each sample contains at
+// least 10 or so values.
+func MannWhitneyU(x, y []float64) (u, p float64, err error) {
+	if len(x) == 0 || len(y) == 0 {
+		return math.NaN(), math.NaN(), errors.New("both samples should be non-empty")
+	}
+	var (
+		n1       = float64(len(x))
+		n2       = float64(len(y))
+		n        = n1 + n2
+		r, ties  = ranks(append(append([]float64{}, x...), y...))
+		rankSumX = sumFloat64s(r[:len(x)])
+	)
+	u = rankSumX - n1*(n1+1)/2
+	var (
+		mean     = n1 * n2 / 2
+		variance = n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1)))
+	)
+	if variance == 0 {
+		return u, 1, nil
+	}
+	var d = math.Abs(u-mean) - 0.5
+	if d < 0 {
+		d = 0
+	}
+	return u, twoSidedP(d / math.Sqrt(variance)), nil
+}
+
+// WilcoxonSignedRank compares two paired samples, such as the results of two
+// configurations run with the same seeds, with the Wilcoxon signed-rank test.
+// It returns the sum of the ranks of the positive differences x[i] - y[i] and
+// the two-sided p-value of the null hypothesis that the differences are
+// symmetric around 0. Null differences are discarded. The p-value uses the
+// normal approximation with tie and continuity corrections.
+func WilcoxonSignedRank(x, y []float64) (w, p float64, err error) {
+	if len(x) != len(y) {
+		return math.NaN(), math.NaN(), errors.New("samples should have the same length")
+	}
+	var diffs, abs []float64
+	for i := range x {
+		if d := x[i] - y[i]; d != 0 {
+			diffs = append(diffs, d)
+			abs = append(abs, math.Abs(d))
+		}
+	}
+	if len(diffs) == 0 {
+		return 0, 1, nil
+	}
+	var r, ties = ranks(abs)
+	for i, d := range diffs {
+		if d > 0 {
+			w += r[i]
+		}
+	}
+	var (
+		n        = float64(len(diffs))
+		mean     = n * (n + 1) / 4
+		variance = n*(n+1)*(2*n+1)/24 - ties/48
+	)
+	if variance == 0 {
+		return w, 1, nil
+	}
+	var d = math.Abs(w-mean) - 0.5
+	if d < 0 {
+		d = 0
+	}
+	return w, twoSidedP(d / math.Sqrt(variance)), nil
+}
+
+// A RunsComparison contains the statistical comparison of the final best
+// fitnesses of two SeedRuns.
+type RunsComparison struct {
+	MedianA      float64 `json:"median_a"`
+	MedianB      float64 `json:"median_b"`
+	MannWhitneyU float64 `json:"mann_whitney_u"`
+	MannWhitneyP float64 `json:"mann_whitney_p"`
+	Paired       bool    `json:"paired"`     // Whether both runs used the same seeds
+	WilcoxonW    float64 `json:"wilcoxon_w"` // Only set if Paired is true
+	WilcoxonP    float64 `json:"wilcoxon_p"` // Only set if Paired is true
+}
+
+// CompareRuns compares the final best fitnesses of two SeedRuns. The
+// Mann-Whitney U test is always applied, the Wilcoxon signed-rank test is also
+// applied if both runs used the same seeds in the same order.
+func CompareRuns(a, b SeedRuns) (RunsComparison, error) {
+	var (
+		fa  = a.Final()
+		fb  = b.Final()
+		cmp RunsComparison
+		err error
+	)
+	if cmp.MannWhitneyU, cmp.MannWhitneyP, err = MannWhitneyU(fa, fb); err != nil {
+		return cmp, err
+	}
+	var sa, sb = copyFloat64s(fa), copyFloat64s(fb)
+	sort.Float64s(sa)
+	sort.Float64s(sb)
+	cmp.MedianA = quantile(sa, 0.5)
+	cmp.MedianB = quantile(sb, 0.5)
+	cmp.Paired = len(a.Seeds) == len(b.Seeds)
+	for i := 0; cmp.Paired && i < len(a.Seeds); i++ {
+		cmp.Paired = a.Seeds[i] == b.Seeds[i]
+	}
+	if cmp.Paired {
+		cmp.WilcoxonW, cmp.WilcoxonP, err = WilcoxonSignedRank(fa, fb)
+	}
+	return cmp, err
+}
@@ -0,0 +1,194 @@
+package eaopt
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRunSeeds(t *testing.T) {
+	var (
+		conf  = NewDefaultGAConfig()
+		calls int
+		seeds = []int64{1, 2, 3, 4}
+	)
+	conf.NGenerations = 10
+	conf.Callback = func(ga *GA) { calls++ }
+	var runs, err = RunSeeds(conf, NewVector, seeds, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != len(seeds)*11 {
+		t.Errorf("Expected the callback to be called %d times, got %d", len(seeds)*11, calls)
+	}
+	for _, traj := range runs.Trajectories {
+		if len(traj) != 11 {
+			t.Fatalf("Expected 11 values, got %d", len(traj))
+		}
+		for i := 1; i < len(traj); i++ {
+			if traj[i] > traj[i-1] {
+				t.Errorf("The best fitness should not increase: %v", traj)
+			}
+		}
+	}
+	// Same results in parallel
+	conf.Callback = nil
+	parallel, err := RunSeeds(conf, NewVector, seeds, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parallel, runs) {
+		t.Errorf("Expected %v, got %v", runs, parallel)
+	}
+	// Errors are returned
+	conf.Model = ModRuntimeError{}
+	for _, p := range []bool{false, true} {
+		if _, err = RunSeeds(conf, NewVector, seeds, p); err == nil {
+			t.Error("Expected an error")
+		}
+	}
+	conf.NGenerations = 0
+	if _, err = RunSeeds(conf, NewVector, seeds, false); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestSeedRunsConvergence(t *testing.T) {
+	var (
+		runs = SeedRuns{
+			Seeds: []int64{1, 2, 3, 4},
+			Trajectories: [][]float64{
+				{4, 3, 2},
+				{8, 6, 4},
+				{2, 1},
+				{6, 5, 3},
+			},
+		}
+		curve = runs.Convergence()
+		final = []float64{2, 4, 1, 3}
+	)
+	if !reflect.DeepEqual(runs.Final(), final) {
+		t.Errorf("Expected %v, got %v", final, runs.Final())
+	}
+	var expected = []ConvergencePoint{
+		{0, 5, 5, 3.5, 6.5},
+		{1, 3.75, 4, 2.5, 5.25},
+		{2, 2.5, 2.5, 1.75, 3.25},
+	}
+	if !reflect.DeepEqual(curve, expected) {
+		t.Errorf("Expected %v, got %v", expected, curve)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	var testCases = []struct {
+		sorted []float64
+		q      float64
+		out    float64
+	}{
+		{[]float64{1}, 0.5, 1},
+		{[]float64{1, 2}, 0.5, 1.5},
+		{[]float64{1, 2, 3, 4}, 0.25, 1.75},
+		{[]float64{1, 2, 3, 4}, 1, 4},
+		{[]float64{1, 2, 3, 4}, 0, 1},
+	}
+	for i, tc := range testCases {
+		if out := quantile(tc.sorted, tc.q); out != tc.out {
+			t.Errorf("Error in test case number %d: expected %f, got %f", i, tc.out, out)
+		}
+	}
+	if !math.IsNaN(quantile(nil, 0.5)) {
+		t.Error("Expected NaN")
+	}
+}
+
+func TestRanks(t *testing.T) {
+	var r, ties = ranks([]float64{3, 1, 3, 2, 3})
+	if !reflect.DeepEqual(r, []float64{4, 1, 4, 2, 4}) {
+		t.Errorf("Wrong ranks: %v", r)
+	}
+	if ties != 24 {
+		t.Errorf("Expected 24, got %f", ties)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	var u, p, err = MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != 0 || math.Abs(p-0.012185780355344818) > 1e-12 {
+		t.Errorf("Expected 0 and 0.012186, got %f and %f", u, p)
+	}
+	// Identical samples
+	if _, p, _ = MannWhitneyU([]float64{1, 1}, []float64{1, 1}); p != 1 {
+		t.Errorf("Expected 1, got %f", p)
+	}
+	if _, _, err = MannWhitneyU(nil, []float64{1}); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestWilcoxonSignedRank(t *testing.T) {
+	var (
+		x         = []float64{1.5, 2.0, 3.1, 4.2, 5.0, 6.3, 7.1, 8.4, 9}
+		y         = []float64{1.0, 2.5, 2.0, 3.0, 3.5, 4.0, 5.0, 6.0, 9}
+		w, p, err = WilcoxonSignedRank(x, y)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != 34.5 || math.Abs(p-0.02488399513396539) > 1e-12 {
+		t.Errorf("Expected 34.5 and 0.024884, got %f and %f", w, p)
+	}
+	if _, p, _ = WilcoxonSignedRank(x, x); p != 1 {
+		t.Errorf("Expected 1, got %f", p)
+	}
+	if _, _, err = WilcoxonSignedRank(x, y[1:]); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	var (
+		conf  = NewDefaultGAConfig()
+		seeds = []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	)
+	conf.NGenerations = 20
+	var good, _ = RunSeeds(conf, NewVector, seeds, true)
+	// A GA without any evolution
+	conf.Model = ModIdentity{}
+	var bad, _ = RunSeeds(conf, NewVector, seeds, true)
+	var cmp, err = CompareRuns(good, bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Paired || cmp.MedianA >= cmp.MedianB {
+		t.Errorf("Wrong comparison: %+v", cmp)
+	}
+	if cmp.MannWhitneyP > 0.05 || cmp.WilcoxonP > 0.05 {
+		t.Errorf("Expected a significant difference: %+v", cmp)
+	}
+	// Unpaired runs
+	bad.Seeds = append([]int64{}, bad.Seeds...)
+	bad.Seeds[0] = 42
+	if cmp, _ = CompareRuns(good, bad); cmp.Paired || cmp.WilcoxonP != 0 {
+		t.Errorf("Runs should not be paired: %+v", cmp)
+	}
+	if _, err = CompareRuns(good, SeedRuns{}); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestRunSeedsSeeded(t *testing.T) {
+	// The configuration's RNG should not be used
+	var conf = NewDefaultGAConfig()
+	conf.RNG = rand.New(rand.NewSource(1))
+	var a, _ = RunSeeds(conf, NewVector, []int64{42}, false)
+	conf.RNG = rand.New(rand.NewSource(2))
+	var b, _ = RunSeeds(conf, NewVector, []int64{42}, false)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("Runs with the same seed should be identical")
+	}
+}
@@ -0,0 +1,309 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// A Scalarization aggregates the values of several objectives to minimize into
+// a single value, given a weight for each objective and a reference point.
+type Scalarization func(objectives, weights, reference []float64) float64
+
+// ScalWeightedSum returns the weighted sum of the objectives. The reference
+// point is ignored. It is simple but can only reach the convex parts of a
+// Pareto front.
+func ScalWeightedSum(objectives, weights, reference []float64) float64 {
+	var s float64
+	for i, f := range objectives {
+		s += weights[i] * f
+	}
+	return s
+}
+
+// ScalTchebycheff returns the largest weighted distance between an objective
+// and the reference point, which is usually the ideal point. Every Pareto
+// optimal solution minimizes it for some weights.
+func ScalTchebycheff(objectives, weights, reference []float64) float64 {
+	var s = math.Inf(-1)
+	for i, f := range objectives {
+		s = math.Max(s, weights[i]*math.Abs(f-reference[i]))
+	}
+	return s
+}
+
+// ScalAchievement returns Wierzbicki's achievement scalarizing function, where
+// the reference point contains aspiration levels. The augmentation term,
+// weighted by rho, avoids weakly Pareto optimal solutions; rho is typically
+// small, for instance 1e-6.
+func ScalAchievement(rho float64) Scalarization {
+	return func(objectives, weights, reference []float64) float64 {
+		var (
+			max = math.Inf(-1)
+			sum float64
+		)
+		for i, f := range objectives {
+			var d = weights[i] * (f - reference[i])
+			max = math.Max(max, d)
+			sum += d
+		}
+		return max + rho*sum
+	}
+}
+
+// A Scalarizer turns the objectives of a multi-objective problem into a
+// fitness. A Genome's Evaluate method computes its objectives and returns the
+// result of Scalarize. Scalarize can be called concurrently.
+//
+// The Scalarizer keeps track of the ideal and nadir points, which contain the
+// lowest and highest value of each objective observed so far. The ideal point
+// is used as the reference point if Reference is nil, and the objectives are
+// rescaled between the ideal and nadir points if Normalize is true, which
+// makes the weights independent of the scales of the objectives.
+//
+// If AdaptRate is positive then Adapt moves the weights towards the objectives
+// on which the last generation fell the furthest from the ideal point, which
+// spreads the effort between the objectives. Note that the fitnesses change
+// when the weights or the ideal and nadir points change, hence Adapt
+// re-evaluates the GA's Individuals: Genomes should cache their objectives so
+// that re-evaluating them is cheap.
+type Scalarizer struct {
+	Method    Scalarization
+	Weights   []float64
+	Reference []float64
+	Normalize bool
+	AdaptRate float64
+
+	mutex        sync.Mutex
+	ideal, nadir []float64
+	genBest      []float64 // Lowest value of each objective since the last adaptation
+}
+
+// NewScalarizer returns a Scalarizer which uses method and weights, without
+// weight adaptation nor normalization.
+func NewScalarizer(method Scalarization, weights []float64) *Scalarizer {
+	return &Scalarizer{Method: method, Weights: copyFloat64s(weights)}
+}
+
+// Validate Scalarizer fields.
+func (sc *Scalarizer) Validate() error {
+	if sc.Method == nil {
+		return errors.New("Method cannot be nil")
+	}
+	if len(sc.Weights) == 0 {
+		return errors.New("Weights cannot be empty")
+	}
+	for _, w := range sc.Weights {
+		if w < 0 {
+			return errors.New("Weights cannot be negative")
+		}
+	}
+	if sc.Reference != nil && len(sc.Reference) != len(sc.Weights) {
+		return errors.New("Reference should have as many values as Weights")
+	}
+	if sc.AdaptRate < 0 || sc.AdaptRate > 1 {
+		return errors.New("AdaptRate should be between 0 and 1")
+	}
+	return nil
+}
+
+// Scalarize records objectives and returns their scalarization. objectives
+// should have as many values as Weights.
+func (sc *Scalarizer) Scalarize(objectives []float64) float64 {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if sc.ideal == nil {
+		sc.ideal = repeatFloat64(math.Inf(1), uint(len(objectives)))
+		sc.nadir = repeatFloat64(math.Inf(-1), uint(len(objectives)))
+		sc.genBest = repeatFloat64(math.Inf(1), uint(len(objectives)))
+	}
+	for i, f := range objectives {
+		sc.ideal[i] = math.Min(sc.ideal[i], f)
+		sc.nadir[i] = math.Max(sc.nadir[i], f)
+		sc.genBest[i] = math.Min(sc.genBest[i], f)
+	}
+	var (
+		f   = copyFloat64s(objectives)
+		ref = sc.Reference
+	)
+	if ref == nil {
+		ref = sc.ideal
+	}
+	ref = copyFloat64s(ref)
+	if sc.Normalize {
+		for i := range f {
+			var scale = sc.nadir[i] - sc.ideal[i]
+			if scale == 0 {
+				scale = 1
+			}
+			f[i] = (f[i] - sc.ideal[i]) / scale
+			ref[i] = (ref[i] - sc.ideal[i]) / scale
+		}
+	}
+	return sc.Method(f, sc.Weights, ref)
+}
+
+// Ideal returns the lowest value of each objective observed so far.
+func (sc *Scalarizer) Ideal() []float64 {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	return copyFloat64s(sc.ideal)
+}
+
+// Nadir returns the highest value of each objective observed so far.
+func (sc *Scalarizer) Nadir() []float64 {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	return copyFloat64s(sc.nadir)
+}
+
+// adaptWeights moves the weights towards the normalized gaps between the best
+// value of each objective since the last adaptation and the ideal point. The
+// weights are normalized so that they sum to 1.
+func (sc *Scalarizer) adaptWeights() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if sc.ideal == nil {
+		return
+	}
+	var (
+		gaps  = make([]float64, len(sc.Weights))
+		total float64
+	)
+	for i := range gaps {
+		if r := sc.nadir[i] - sc.ideal[i]; r > 0 {
+			gaps[i] = (sc.genBest[i] - sc.ideal[i]) / r
+		}
+		total += gaps[i]
+	}
+	var sum float64
+	for i, w := range sc.Weights {
+		if total > 0 {
+			w = (1-sc.AdaptRate)*w + sc.AdaptRate*gaps[i]/total
+		}
+		sc.Weights[i] = w
+		sum += w
+	}
+	if sum > 0 {
+		for i := range sc.Weights {
+			sc.Weights[i] /= sum
+		}
+	}
+	for i := range sc.genBest {
+		sc.genBest[i] = math.Inf(1)
+	}
+}
+
+// Adapt adapts the weights if AdaptRate is positive and re-evaluates the
+// Individuals of ga, hall of fame included, so that their fitnesses reflect
+// the current weights and normalization. It is meant to be called from the
+// GA's Callback, or from an OnGenerationEnd handler.
+func (sc *Scalarizer) Adapt(ga *GA) error {
+	if sc.AdaptRate > 0 {
+		sc.adaptWeights()
+	}
+	return ga.reevaluate()
+}
+
+// Dominates returns true if the objectives a are at least as good as b and
+// strictly better for at least one objective, all objectives being minimized.
+func Dominates(a, b []float64) bool {
+	var better bool
+	for i := range a {
+		if a[i] > b[i] {
+			return false
+		}
+		if a[i] < b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// ParetoFront returns the indexes of the points that aren't dominated by any
+// other point.
+func ParetoFront(points [][]float64) []int {
+	var front []int
+	for i, p := range points {
+		var dominated bool
+		for j, q := range points {
+			if i != j && Dominates(q, p) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, i)
+		}
+	}
+	return front
+}
+
+// SimplexWeights returns every weight vector with nObjectives non-negative
+// components which are multiples of 1/divisions and sum to 1, as proposed by
+// Das and Dennis. There are C(divisions+nObjectives-1, nObjectives-1) of them.
+func SimplexWeights(nObjectives, divisions uint) [][]float64 {
+	if nObjectives == 0 || divisions == 0 {
+		return nil
+	}
+	var (
+		weights [][]float64
+		counts  = make([]uint, nObjectives)
+		fill    func(i, left uint)
+	)
+	fill = func(i, left uint) {
+		if i == nObjectives-1 {
+			counts[i] = left
+			var w = make([]float64, nObjectives)
+			for k, c := range counts {
+				w[k] = float64(c) / float64(divisions)
+			}
+			weights = append(weights, w)
+			return
+		}
+		for c := uint(0); c <= left; c++ {
+			counts[i] = c
+			fill(i+1, left-c)
+		}
+	}
+	fill(0, divisions)
+	return weights
+}
+
+// A ParetoPoint is a solution to a multi-objective problem along with its
+// objectives.
+type ParetoPoint struct {
+	Genome     Genome
+	Objectives []float64
+}
+
+// SweepWeights calls run once per weight vector, typically obtained with
+// SimplexWeights, with a new Scalarizer using method and the weights. run
+// minimizes the scalarized problem, for instance by running a GA whose Genomes
+// use the Scalarizer, and returns the best solution found. The points which
+// aren't dominated are returned, they approximate the Pareto front.
+func SweepWeights(method Scalarization, weights [][]float64,
+	run func(sc *Scalarizer) (ParetoPoint, error)) ([]ParetoPoint, error) {
+	var points = make([]ParetoPoint, len(weights))
+	for i, w := range weights {
+		var sc = NewScalarizer(method, w)
+		if err := sc.Validate(); err != nil {
+			return nil, err
+		}
+		var p, err = run(sc)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+	}
+	var objectives = make([][]float64, len(points))
+	for i, p := range points {
+		objectives[i] = p.Objectives
+	}
+	var front = ParetoFront(objectives)
+	var nonDominated = make([]ParetoPoint, len(front))
+	for i, j := range front {
+		nonDominated[i] = points[j]
+	}
+	return nonDominated, nil
+}
@@ -0,0 +1,157 @@
+package eaopt
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestScalarizations(t *testing.T) {
+	var (
+		f   = []float64{1, 3}
+		w   = []float64{0.5, 0.25}
+		ref = []float64{0, 1}
+	)
+	if s := ScalWeightedSum(f, w, ref); s != 1.25 {
+		t.Errorf("Expected 1.25, got %f", s)
+	}
+	if s := ScalTchebycheff(f, w, ref); s != 0.5 {
+		t.Errorf("Expected 0.5, got %f", s)
+	}
+	if s := ScalAchievement(0.1)(f, w, ref); s != 0.5+0.1*1 {
+		t.Errorf("Expected 0.6, got %f", s)
+	}
+}
+
+func TestScalarizerNormalize(t *testing.T) {
+	var sc = NewScalarizer(ScalWeightedSum, []float64{1, 1})
+	sc.Normalize = true
+	sc.Scalarize([]float64{0, 0})
+	sc.Scalarize([]float64{1, 100})
+	// Both objectives are halfway between the ideal and the nadir points
+	if s := sc.Scalarize([]float64{0.5, 50}); s != 1 {
+		t.Errorf("Expected 1, got %f", s)
+	}
+	if !reflect.DeepEqual(sc.Ideal(), []float64{0, 0}) || !reflect.DeepEqual(sc.Nadir(), []float64{1, 100}) {
+		t.Errorf("Unexpected ideal %v and nadir %v", sc.Ideal(), sc.Nadir())
+	}
+}
+
+func TestScalarizerValidate(t *testing.T) {
+	var testCases = []struct {
+		sc    *Scalarizer
+		valid bool
+	}{
+		{NewScalarizer(ScalTchebycheff, []float64{1, 2}), true},
+		{NewScalarizer(nil, []float64{1, 2}), false},
+		{NewScalarizer(ScalTchebycheff, nil), false},
+		{NewScalarizer(ScalTchebycheff, []float64{-1, 2}), false},
+		{&Scalarizer{Method: ScalTchebycheff, Weights: []float64{1}, Reference: []float64{1, 2}}, false},
+		{&Scalarizer{Method: ScalTchebycheff, Weights: []float64{1}, AdaptRate: 2}, false},
+	}
+	for i, tc := range testCases {
+		if err := tc.sc.Validate(); (err == nil) != tc.valid {
+			t.Errorf("TC %d: unexpected error %v", i, err)
+		}
+	}
+}
+
+func TestScalarizerAdaptWeights(t *testing.T) {
+	var sc = NewScalarizer(ScalWeightedSum, []float64{0.5, 0.5})
+	sc.AdaptRate = 1
+	sc.Scalarize([]float64{0, 0})
+	sc.Scalarize([]float64{1, 1})
+	sc.adaptWeights()
+	// The first objective lags behind during the next generation
+	sc.Scalarize([]float64{0.9, 0.1})
+	sc.adaptWeights()
+	if math.Abs(sc.Weights[0]-0.9) > 1e-12 || math.Abs(sc.Weights[1]-0.1) > 1e-12 {
+		t.Errorf("Expected [0.9 0.1], got %v", sc.Weights)
+	}
+}
+
+// A biObjective point has two conflicting objectives: its squared distances
+// to 0 and to 2.
+type biObjective struct {
+	x          []float64
+	objectives []float64
+	sc         *Scalarizer
+}
+
+func (b *biObjective) Evaluate() (float64, error) {
+	if b.objectives == nil {
+		var d0, d2 float64
+		for _, x := range b.x {
+			d0 += x * x
+			d2 += (x - 2) * (x - 2)
+		}
+		b.objectives = []float64{d0, d2}
+	}
+	return b.sc.Scalarize(b.objectives), nil
+}
+func (b *biObjective) Mutate(rng *rand.Rand) {
+	MutNormalFloat64(b.x, 0.5, rng)
+	b.objectives = nil
+}
+func (b *biObjective) Crossover(y Genome, rng *rand.Rand) {
+	CrossUniformFloat64(b.x, y.(*biObjective).x, rng)
+	b.objectives, y.(*biObjective).objectives = nil, nil
+}
+func (b *biObjective) Clone() Genome {
+	return &biObjective{x: copyFloat64s(b.x), objectives: b.objectives, sc: b.sc}
+}
+
+func TestSweepWeights(t *testing.T) {
+	var weights = SimplexWeights(2, 4)
+	if len(weights) != 5 || !reflect.DeepEqual(weights[1], []float64{0.25, 0.75}) {
+		t.Errorf("Unexpected weights %v", weights)
+	}
+	var front, err = SweepWeights(ScalTchebycheff, weights, func(sc *Scalarizer) (ParetoPoint, error) {
+		sc.Normalize = true
+		sc.AdaptRate = 0.1
+		var conf = NewDefaultGAConfig()
+		conf.NGenerations = 20
+		conf.RNG = rand.New(rand.NewSource(42))
+		conf.Callback = func(ga *GA) {
+			if err := sc.Adapt(ga); err != nil {
+				t.Error(err)
+			}
+		}
+		var ga, err = conf.NewGA()
+		if err != nil {
+			return ParetoPoint{}, err
+		}
+		err = ga.Minimize(func(rng *rand.Rand) Genome {
+			return &biObjective{x: InitUnifFloat64(2, -1, 3, rng), sc: sc}
+		})
+		if err != nil {
+			return ParetoPoint{}, err
+		}
+		var best = ga.HallOfFame[0].Genome.(*biObjective)
+		return ParetoPoint{Genome: best, Objectives: best.objectives}, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(front) < 2 {
+		t.Errorf("Expected several non-dominated points, got %d", len(front))
+	}
+	for i, p := range front {
+		for j, q := range front {
+			if i != j && Dominates(p.Objectives, q.Objectives) {
+				t.Errorf("%v dominates %v", p.Objectives, q.Objectives)
+			}
+		}
+	}
+}
+
+func TestParetoFront(t *testing.T) {
+	var points = [][]float64{{1, 4}, {2, 2}, {3, 3}, {4, 1}, {2, 2}}
+	if front := ParetoFront(points); !reflect.DeepEqual(front, []int{0, 1, 3, 4}) {
+		t.Errorf("Expected [0 1 3 4], got %v", front)
+	}
+	if Dominates([]float64{1, 2}, []float64{1, 2}) {
+		t.Error("A point doesn't dominate itself")
+	}
+}
@@ -0,0 +1,140 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+)
+
+// A Schedule makes a rate, such as a Model's mutation or crossover rate, vary
+// across generations. Value is called with the number of generations the
+// Population has already been evolved for, hence 0 on the first generation.
+type Schedule interface {
+	Value(generation uint) float64
+	Validate() error
+}
+
+// validateRate checks that a value returned by a Schedule is a probability.
+func validateRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return errors.New("rates should be between 0 and 1")
+	}
+	return nil
+}
+
+// validateSchedules validates the Schedules which aren't nil.
+func validateSchedules(schedules ...Schedule) error {
+	for _, sched := range schedules {
+		if sched == nil {
+			continue
+		}
+		if err := sched.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduledRate returns the value of schedule at the given generation, kept
+// between 0 and 1, or rate if schedule is nil.
+func scheduledRate(rate float64, schedule Schedule, generation uint) float64 {
+	if schedule == nil {
+		return rate
+	}
+	return math.Min(math.Max(schedule.Value(generation), 0), 1)
+}
+
+// SchedLinear goes linearly from Start to End over NGenerations generations
+// and stays at End afterwards.
+type SchedLinear struct {
+	Start, End   float64
+	NGenerations uint
+}
+
+// Value of SchedLinear.
+func (sched SchedLinear) Value(generation uint) float64 {
+	if generation >= sched.NGenerations {
+		return sched.End
+	}
+	var t = float64(generation) / float64(sched.NGenerations)
+	return sched.Start + t*(sched.End-sched.Start)
+}
+
+// Validate SchedLinear fields.
+func (sched SchedLinear) Validate() error {
+	if sched.NGenerations == 0 {
+		return errors.New("NGenerations should be positive")
+	}
+	if err := validateRate(sched.Start); err != nil {
+		return err
+	}
+	return validateRate(sched.End)
+}
+
+// SchedCosine goes from Start to End over NGenerations generations following
+// half a cosine, which lingers near Start and End and moves faster in
+// between, and stays at End afterwards.
+type SchedCosine struct {
+	Start, End   float64
+	NGenerations uint
+}
+
+// Value of SchedCosine.
+func (sched SchedCosine) Value(generation uint) float64 {
+	if generation >= sched.NGenerations {
+		return sched.End
+	}
+	var t = float64(generation) / float64(sched.NGenerations)
+	return sched.End + (sched.Start-sched.End)*(1+math.Cos(math.Pi*t))/2
+}
+
+// Validate SchedCosine fields.
+func (sched SchedCosine) Validate() error {
+	if sched.NGenerations == 0 {
+		return errors.New("NGenerations should be positive")
+	}
+	if err := validateRate(sched.Start); err != nil {
+		return err
+	}
+	return validateRate(sched.End)
+}
+
+// SchedStep starts at Start and is multiplied by Factor every Every
+// generations.
+type SchedStep struct {
+	Start  float64
+	Factor float64
+	Every  uint
+}
+
+// Value of SchedStep.
+func (sched SchedStep) Value(generation uint) float64 {
+	return sched.Start * math.Pow(sched.Factor, float64(generation/sched.Every))
+}
+
+// Validate SchedStep fields.
+func (sched SchedStep) Validate() error {
+	if sched.Every == 0 {
+		return errors.New("Every should be positive")
+	}
+	if sched.Factor < 0 {
+		return errors.New("Factor cannot be negative")
+	}
+	return validateRate(sched.Start)
+}
+
+// SchedFunc turns a function into a Schedule. Its values are kept between 0
+// and 1. Note that it can't be recorded in a Manifest.
+type SchedFunc func(generation uint) float64
+
+// Value of SchedFunc.
+func (sched SchedFunc) Value(generation uint) float64 {
+	return sched(generation)
+}
+
+// Validate SchedFunc.
+func (sched SchedFunc) Validate() error {
+	if sched == nil {
+		return errors.New("SchedFunc cannot be nil")
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package eaopt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScheduleValues(t *testing.T) {
+	var testCases = []struct {
+		sched  Schedule
+		values []float64 // Values at generations 0, 1, 2, 4 and 10
+	}{
+		{SchedLinear{Start: 0.8, End: 0.2, NGenerations: 4}, []float64{0.8, 0.65, 0.5, 0.2, 0.2}},
+		{SchedCosine{Start: 0.8, End: 0.2, NGenerations: 4}, []float64{0.8, 0.2 + 0.3*(1+math.Sqrt2/2), 0.5, 0.2, 0.2}},
+		{SchedStep{Start: 0.8, Factor: 0.5, Every: 2}, []float64{0.8, 0.8, 0.4, 0.2, 0.025}},
+		{SchedFunc(func(gen uint) float64 { return float64(gen) / 10 }), []float64{0, 0.1, 0.2, 0.4, 1}},
+	}
+	for i, tc := range testCases {
+		if err := tc.sched.Validate(); err != nil {
+			t.Errorf("Test case %d: expected nil, got %v", i, err)
+		}
+		for j, gen := range []uint{0, 1, 2, 4, 10} {
+			if v := tc.sched.Value(gen); math.Abs(v-tc.values[j]) > 1e-12 {
+				t.Errorf("Test case %d: expected %f at generation %d, got %f", i, tc.values[j], gen, v)
+			}
+		}
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	for i, sched := range []Schedule{
+		SchedLinear{Start: 0.8, End: 0.2},
+		SchedLinear{Start: 1.5, End: 0.2, NGenerations: 4},
+		SchedCosine{Start: 0.8, End: -0.2, NGenerations: 4},
+		SchedStep{Start: 0.8, Factor: 0.5},
+		SchedStep{Start: 0.8, Factor: -1, Every: 2},
+		SchedFunc(nil),
+	} {
+		if err := sched.Validate(); err == nil {
+			t.Errorf("Test case %d: expected an error", i)
+		}
+	}
+	var mod = ModGenerational{
+		Selector:    SelTournament{NContestants: 3},
+		MutRate:     0.5,
+		MutSchedule: SchedStep{Start: 0.8, Factor: 0.5},
+	}
+	if err := mod.Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestScheduledRate(t *testing.T) {
+	if r := scheduledRate(0.3, nil, 5); r != 0.3 {
+		t.Errorf("Expected 0.3, got %f", r)
+	}
+	if r := scheduledRate(0.3, SchedStep{Start: 0.5, Factor: 4, Every: 1}, 1); r != 1 {
+		t.Errorf("Expected 1, got %f", r)
+	}
+}
+
+func TestModelsFollowSchedules(t *testing.T) {
+	// Variation only happens on the first generation, so that the genomes stop
+	// changing afterwards
+	var (
+		sched = SchedFunc(func(gen uint) float64 {
+			if gen == 0 {
+				return 1
+			}
+			return 0
+		})
+		models = []Model{
+			ModGenerational{Selector: SelElitism{}, MutRate: 0, MutSchedule: sched, CrossSchedule: sched},
+			ModSteadyState{Selector: SelElitism{}, MutSchedule: sched, CrossSchedule: sched},
+			ModDownToSize{NOffsprings: 5, SelectorA: SelElitism{}, SelectorB: SelElitism{},
+				MutSchedule: sched, CrossSchedule: sched},
+		}
+	)
+	for i, model := range models {
+		var pop = newPopulation(10, false, NewVector, newRand())
+		if err := pop.Individuals.Evaluate(false); err != nil {
+			t.Fatal(err)
+		}
+		var before = pop.Individuals.Clone(pop.RNG)
+		if err := model.Apply(&pop); err != nil {
+			t.Fatal(err)
+		}
+		if reflect.DeepEqual(genomesOf(pop.Individuals), genomesOf(before)) {
+			t.Errorf("Model %d: expected the genomes to change on the first generation", i)
+		}
+		pop.Generations = 1
+		if err := pop.Individuals.Evaluate(false); err != nil {
+			t.Fatal(err)
+		}
+		before = pop.Individuals.Clone(pop.RNG)
+		if err := model.Apply(&pop); err != nil {
+			t.Fatal(err)
+		}
+		for _, g := range genomesOf(pop.Individuals) {
+			if !containsGenome(genomesOf(before), g) {
+				t.Errorf("Model %d: expected no new genome after the first generation, got %v", i, g)
+				break
+			}
+		}
+	}
+}
+
+func genomesOf(indis Individuals) []Genome {
+	var genomes = make([]Genome, len(indis))
+	for i, indi := range indis {
+		genomes[i] = indi.Genome
+	}
+	return genomes
+}
+
+func containsGenome(genomes []Genome, g Genome) bool {
+	for _, h := range genomes {
+		if reflect.DeepEqual(h, g) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestManifestSchedFunc(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.Model = ModGenerational{
+		Selector:      SelTournament{NContestants: 3},
+		MutRate:       0.5,
+		CrossSchedule: SchedFunc(func(gen uint) float64 { return 0.5 }),
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga.RNGSeed = "42"
+	m, err := ga.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Unrecorded, []string{"Model.CrossSchedule"}) {
+		t.Errorf("Expected the schedule to be unrecorded, got %v", m.Unrecorded)
+	}
+}
@@ -0,0 +1,239 @@
+package eaopt
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Operators for scheduling problems. Job-shop problems are encoded with the
+// operation-based representation: a sequence in which each job appears as many
+// times as it has operations, the k-th occurrence of a job standing for its
+// k-th operation. Any such sequence decodes to a feasible schedule, as long as
+// the operators preserve the number of occurrences of each job, which is the
+// case for CrossPPX, MutShift and MutPermute. Flow-shop problems are encoded
+// with plain permutations of the jobs.
+
+// Contains the deterministic part of the PPX method for testing purposes.
+func ppx(p1, p2 Slice, mask []bool) {
+	var (
+		n       = p1.Len()
+		o1      = p1.Copy()
+		o2      = p2.Copy()
+		parents = [2]Slice{p1, p2}
+	)
+	for o, offspring := range []Slice{o1, o2} {
+		var (
+			used = [2][]bool{make([]bool, n), make([]bool, n)}
+			next = [2]int{}
+		)
+		for k := 0; k < n; k++ {
+			// The second offspring uses the complement of the mask
+			var from = 1
+			if mask[k] != (o == 1) {
+				from = 0
+			}
+			// Take the leftmost gene of the chosen parent that hasn't been used
+			for used[from][next[from]] {
+				next[from]++
+			}
+			var gene = parents[from].At(next[from])
+			used[from][next[from]] = true
+			offspring.Set(k, gene)
+			// Remove the leftmost occurrence of the gene from the other parent
+			var other = 1 - from
+			for i := next[other]; i < n; i++ {
+				if !used[other][i] && parents[other].At(i) == gene {
+					used[other][i] = true
+					break
+				}
+			}
+		}
+	}
+	p1.Replace(o1)
+	p2.Replace(o2)
+}
+
+// CrossPPX (Precedence Preserving Crossover). A random mask decides, gene by
+// gene, from which parent the offspring takes its next gene. The leftmost
+// unused gene of that parent is appended to the offspring and its leftmost
+// occurrence is removed from the other parent. The relative order of the genes
+// in the parents is thus preserved, which makes PPX safe for precedence
+// constrained encodings such as the operation-based job-shop encoding. The
+// second offspring uses the complement of the mask. Both parents must contain
+// the same multiset of genes.
+func CrossPPX(p1, p2 Slice, rng *rand.Rand) {
+	var mask = make([]bool, p1.Len())
+	for i := range mask {
+		mask[i] = rng.Float64() < 0.5
+	}
+	ppx(p1, p2, mask)
+}
+
+// CrossPPXInt calls CrossPPX on two int slices.
+func CrossPPXInt(s1 []int, s2 []int, rng *rand.Rand) {
+	CrossPPX(IntSlice(s1), IntSlice(s2), rng)
+}
+
+// CrossPPXString calls CrossPPX on two string slices.
+func CrossPPXString(s1 []string, s2 []string, rng *rand.Rand) {
+	CrossPPX(StringSlice(s1), StringSlice(s2), rng)
+}
+
+// Contains the deterministic part of the shift mutation for testing purposes.
+func shift(genome Slice, i, j int) {
+	var gene = genome.At(i)
+	for ; i < j; i++ {
+		genome.Set(i, genome.At(i+1))
+	}
+	for ; i > j; i-- {
+		genome.Set(i, genome.At(i-1))
+	}
+	genome.Set(j, gene)
+}
+
+// MutShift removes a gene at random and reinserts it at another random
+// position, the genes in between are shifted by one position. It does so n
+// times.
+func MutShift(genome Slice, n int, rng *rand.Rand) {
+	// Nothing to shift
+	if genome.Len() <= 1 {
+		return
+	}
+	for k := 0; k < n; k++ {
+		var points = randomInts(2, 0, genome.Len(), rng)
+		shift(genome, points[0], points[1])
+	}
+}
+
+// MutShiftInt calls MutShift on an int slice.
+func MutShiftInt(s []int, n int, rng *rand.Rand) {
+	MutShift(IntSlice(s), n, rng)
+}
+
+// MutShiftString calls MutShift on a string slice.
+func MutShiftString(s []string, n int, rng *rand.Rand) {
+	MutShift(StringSlice(s), n, rng)
+}
+
+// MutSwapAdjacent swaps a random gene with its right neighbour n times. It
+// is the smallest possible move in the neighbourhood of a sequence.
+func MutSwapAdjacent(genome Slice, n int, rng *rand.Rand) {
+	// Nothing to swap
+	if genome.Len() <= 1 {
+		return
+	}
+	for k := 0; k < n; k++ {
+		var i = rng.Intn(genome.Len() - 1)
+		genome.Swap(i, i+1)
+	}
+}
+
+// MutSwapAdjacentInt calls MutSwapAdjacent on an int slice.
+func MutSwapAdjacentInt(s []int, n int, rng *rand.Rand) {
+	MutSwapAdjacent(IntSlice(s), n, rng)
+}
+
+// JobShop describes a job-shop scheduling problem. Each job is a sequence of
+// operations, operation k of job j has to be processed on machine
+// Machines[j][k] during Durations[j][k].
+type JobShop struct {
+	Machines  [][]int
+	Durations [][]float64
+}
+
+// Validate JobShop fields.
+func (js JobShop) Validate() error {
+	if len(js.Machines) == 0 {
+		return errors.New("at least one job has to be provided")
+	}
+	if len(js.Machines) != len(js.Durations) {
+		return errors.New("Machines and Durations should have the same number of jobs")
+	}
+	for j := range js.Machines {
+		if len(js.Machines[j]) != len(js.Durations[j]) {
+			return errors.New("Machines and Durations should have the same number of operations")
+		}
+		for _, m := range js.Machines[j] {
+			if m < 0 {
+				return errors.New("machines should be positive indexes")
+			}
+		}
+	}
+	return nil
+}
+
+// NewSequence returns a random operation-based sequence.
+func (js JobShop) NewSequence(rng *rand.Rand) []int {
+	var seq []int
+	for j, ops := range js.Machines {
+		for range ops {
+			seq = append(seq, j)
+		}
+	}
+	rng.Shuffle(len(seq), func(i, j int) { seq[i], seq[j] = seq[j], seq[i] })
+	return seq
+}
+
+// Schedule decodes an operation-based sequence into a semi-active schedule:
+// each operation starts as soon as both its job and its machine are free. It
+// returns the start time of each operation, indexed like Machines, along with
+// the makespan.
+func (js JobShop) Schedule(seq []int) (starts [][]float64, makespan float64) {
+	var (
+		nextOp   = make([]int, len(js.Machines))
+		jobReady = make([]float64, len(js.Machines))
+		machFree = make(map[int]float64)
+	)
+	starts = make([][]float64, len(js.Machines))
+	for j := range starts {
+		starts[j] = make([]float64, len(js.Machines[j]))
+	}
+	for _, j := range seq {
+		var k = nextOp[j]
+		if k >= len(js.Machines[j]) {
+			continue
+		}
+		var (
+			m     = js.Machines[j][k]
+			start = jobReady[j]
+		)
+		if machFree[m] > start {
+			start = machFree[m]
+		}
+		var end = start + js.Durations[j][k]
+		starts[j][k] = start
+		jobReady[j] = end
+		machFree[m] = end
+		nextOp[j]++
+		if end > makespan {
+			makespan = end
+		}
+	}
+	return starts, makespan
+}
+
+// Makespan returns the completion time of the last operation of the schedule
+// encoded by an operation-based sequence.
+func (js JobShop) Makespan(seq []int) float64 {
+	var _, makespan = js.Schedule(seq)
+	return makespan
+}
+
+// FlowShopMakespan returns the makespan of a permutation flow-shop schedule
+// where durations[j][m] is the processing time of job j on machine m and every
+// job visits the machines in the same order.
+func FlowShopMakespan(perm []int, durations [][]float64) float64 {
+	if len(perm) == 0 {
+		return 0
+	}
+	var completion = make([]float64, len(durations[perm[0]]))
+	for _, j := range perm {
+		for m, d := range durations[j] {
+			if m > 0 && completion[m-1] > completion[m] {
+				completion[m] = completion[m-1]
+			}
+			completion[m] += d
+		}
+	}
+	return completion[len(completion)-1]
+}
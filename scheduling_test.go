@@ -0,0 +1,120 @@
+package eaopt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPPX(t *testing.T) {
+	var (
+		p1   = []int{1, 2, 3, 4, 5, 6}
+		p2   = []int{3, 1, 2, 6, 4, 5}
+		mask = []bool{true, false, true, true, false, false}
+		o1   = []int{1, 3, 2, 4, 6, 5}
+		o2   = []int{3, 1, 2, 6, 4, 5}
+	)
+	ppx(IntSlice(p1), IntSlice(p2), mask)
+	if !reflect.DeepEqual(p1, o1) {
+		t.Errorf("Expected %v, got %v", o1, p1)
+	}
+	if !reflect.DeepEqual(p2, o2) {
+		t.Errorf("Expected %v, got %v", o2, p2)
+	}
+}
+
+func TestCrossPPXPreservesOccurrences(t *testing.T) {
+	var (
+		rng = newRand()
+		js  = JobShop{
+			Machines:  [][]int{{0, 1, 2}, {2, 1, 0}, {1, 0, 2}},
+			Durations: [][]float64{{1, 2, 3}, {3, 2, 1}, {2, 2, 2}},
+		}
+	)
+	for i := 0; i < 20; i++ {
+		var (
+			p1 = js.NewSequence(rng)
+			p2 = js.NewSequence(rng)
+		)
+		CrossPPXInt(p1, p2, rng)
+		MutShiftInt(p1, 2, rng)
+		MutSwapAdjacentInt(p2, 2, rng)
+		for _, s := range [][]int{p1, p2} {
+			var sorted = append([]int{}, s...)
+			sort.Ints(sorted)
+			if !reflect.DeepEqual(sorted, []int{0, 0, 0, 1, 1, 1, 2, 2, 2}) {
+				t.Fatalf("Operation counts were not preserved: %v", s)
+			}
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	var testCases = []struct {
+		i, j int
+		out  []int
+	}{
+		{0, 3, []int{2, 3, 4, 1, 5}},
+		{3, 0, []int{4, 1, 2, 3, 5}},
+		{2, 2, []int{1, 2, 3, 4, 5}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var s = []int{1, 2, 3, 4, 5}
+			shift(IntSlice(s), tc.i, tc.j)
+			if !reflect.DeepEqual(s, tc.out) {
+				t.Errorf("Expected %v, got %v", tc.out, s)
+			}
+		})
+	}
+}
+
+func TestJobShopSchedule(t *testing.T) {
+	var js = JobShop{
+		Machines:  [][]int{{0, 1}, {1, 0}},
+		Durations: [][]float64{{3, 2}, {2, 4}},
+	}
+	if err := js.Validate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var starts, makespan = js.Schedule([]int{0, 1, 0, 1})
+	if makespan != 7 {
+		t.Errorf("Expected 7, got %f", makespan)
+	}
+	if !reflect.DeepEqual(starts, [][]float64{{0, 3}, {0, 3}}) {
+		t.Errorf("Expected [[0 3] [0 3]], got %v", starts)
+	}
+}
+
+func TestJobShopValidate(t *testing.T) {
+	var testCases = []JobShop{
+		{},
+		{Machines: [][]int{{0}}, Durations: [][]float64{}},
+		{Machines: [][]int{{0}}, Durations: [][]float64{{1, 2}}},
+		{Machines: [][]int{{-1}}, Durations: [][]float64{{1}}},
+	}
+	for i, js := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			if err := js.Validate(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFlowShopMakespan(t *testing.T) {
+	var durations = [][]float64{{3, 2}, {1, 4}}
+	for _, tc := range []struct {
+		perm     []int
+		makespan float64
+	}{
+		{[]int{0, 1}, 9},
+		{[]int{1, 0}, 7},
+		{[]int{}, 0},
+	} {
+		if m := FlowShopMakespan(tc.perm, durations); m != tc.makespan {
+			t.Errorf("Expected %f, got %f", tc.makespan, m)
+		}
+	}
+}
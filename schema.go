@@ -0,0 +1,164 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// SchemaVersion is the version of the JSON encoding of GAs and Populations.
+// It is written alongside them in a "schema_version" field and is increased
+// whenever the encoding changes, so that documents written by older versions
+// of eaopt can be migrated when they are decoded. Documents without a
+// schema_version field were written before versioning was introduced and are
+// considered to be version 1.
+//
+// Version 2 introduced the schema_version field, the violation of the
+// Individuals and encodes non-finite fitnesses as null.
+const SchemaVersion = 2
+
+// A schemaMigration upgrades a JSON document from one schema version to the
+// next one. population is applied to each Population and individual to each
+// Individual, whether it belongs to a Population or to the hall of fame. Nil
+// functions leave the documents untouched.
+type schemaMigration struct {
+	population func(doc map[string]json.RawMessage) error
+	individual func(doc map[string]json.RawMessage) error
+}
+
+// schemaMigrations contains the migration from version i+1 to version i+2 at
+// index i.
+var schemaMigrations = []schemaMigration{
+	// Version 2 only added fields, which default to their zero value
+	{},
+}
+
+// schemaVersionOf returns the schema version of a decoded document.
+func schemaVersionOf(doc map[string]json.RawMessage) (uint, error) {
+	var raw, ok = doc["schema_version"]
+	if !ok {
+		return 1, nil
+	}
+	var version uint
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("invalid schema_version: %v", err)
+	}
+	if version == 0 || version > SchemaVersion {
+		return 0, fmt.Errorf("schema version %d isn't supported, this version of eaopt reads versions "+
+			"1 to %d", version, SchemaVersion)
+	}
+	return version, nil
+}
+
+// migratePopulation upgrades a Population document to the current schema
+// version, Individuals included.
+func migratePopulation(doc map[string]json.RawMessage) error {
+	var version, err = schemaVersionOf(doc)
+	if err != nil {
+		return err
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+	for v := version; v < SchemaVersion; v++ {
+		if m := schemaMigrations[v-1].population; m != nil {
+			if err = m(doc); err != nil {
+				return fmt.Errorf("migrating a population from schema version %d: %v", v, err)
+			}
+		}
+	}
+	if indis, ok := doc["indis"]; ok {
+		if doc["indis"], err = migrateIndividuals(indis, version); err != nil {
+			return err
+		}
+	}
+	doc["schema_version"], _ = json.Marshal(SchemaVersion)
+	return nil
+}
+
+// migrateIndividuals upgrades a JSON array of Individuals from the given
+// schema version to the current one.
+func migrateIndividuals(data json.RawMessage, version uint) (json.RawMessage, error) {
+	if version == SchemaVersion || string(data) == "null" {
+		return data, nil
+	}
+	var docs []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, err
+	}
+	for v := version; v < SchemaVersion; v++ {
+		var m = schemaMigrations[v-1].individual
+		if m == nil {
+			continue
+		}
+		for _, doc := range docs {
+			if err := m(doc); err != nil {
+				return nil, fmt.Errorf("migrating an individual from schema version %d: %v", v, err)
+			}
+		}
+	}
+	return json.Marshal(docs)
+}
+
+// MarshalJSON encodes an Individual, non-finite numbers such as the fitness of
+// an Individual that hasn't been evaluated are encoded as null. Whether the
+// fitness is pending is included so that a GA in real-time mode can be resumed.
+func (indi Individual) MarshalJSON() ([]byte, error) {
+	type alias Individual
+	var objectives []nullableFloat64
+	if indi.Objectives != nil {
+		objectives = make([]nullableFloat64, len(indi.Objectives))
+		for i, o := range indi.Objectives {
+			objectives[i] = nullableFloat64(o)
+		}
+	}
+	return json.Marshal(struct {
+		alias
+		Fitness    *float64          `json:"fitness"`
+		Violation  nullableFloat64   `json:"violation,omitempty"`
+		Objective  nullableFloat64   `json:"objective,omitempty"`
+		Novelty    nullableFloat64   `json:"novelty,omitempty"`
+		Objectives []nullableFloat64 `json:"objectives,omitempty"`
+		Pending    bool              `json:"pending,omitempty"`
+	}{alias(indi), jsonFloat64(indi.Fitness), nullableFloat64(indi.Violation), nullableFloat64(indi.Objective),
+		nullableFloat64(indi.Novelty), objectives, indi.pending})
+}
+
+// A nullableFloat64 is a float64 which is encoded as null in JSON if it isn't
+// finite, and decoded as +Inf from null.
+type nullableFloat64 float64
+
+// MarshalJSON implements json.Marshaler.
+func (f nullableFloat64) MarshalJSON() ([]byte, error) {
+	if math.IsInf(float64(f), 0) || math.IsNaN(float64(f)) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(f))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *nullableFloat64) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = nullableFloat64(math.Inf(1))
+		return nil
+	}
+	return json.Unmarshal(data, (*float64)(f))
+}
+
+// MarshalJSON encodes a Population along with the SchemaVersion.
+func (pop Population) MarshalJSON() ([]byte, error) {
+	type alias Population
+	return json.Marshal(struct {
+		SchemaVersion uint `json:"schema_version"`
+		alias
+	}{SchemaVersion, alias(pop)})
+}
+
+// MarshalJSON encodes a GA along with the SchemaVersion.
+func (ga *GA) MarshalJSON() ([]byte, error) {
+	type alias GA
+	return json.Marshal(struct {
+		SchemaVersion uint `json:"schema_version"`
+		*alias
+	}{SchemaVersion, (*alias)(ga)})
+}
@@ -0,0 +1,133 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPopulationSchemaVersion(t *testing.T) {
+	var pop = Population{
+		ID: "abc",
+		Individuals: Individuals{
+			{Genome: Vector{1, 2}, Fitness: 3, Violation: 0.5, ID: "x"},
+			{Genome: Vector{4, 5}, Fitness: math.Inf(1), ID: "y"},
+		},
+	}
+	var b, err = json.Marshal(pop)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if !strings.Contains(string(b), `"schema_version":2`) || !strings.Contains(string(b), `"fitness":null`) {
+		t.Errorf("Unexpected encoding: %s", b)
+	}
+	var decoded = Population{JSONUnmarshaler: VectorJSONUnmarshaler}
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if decoded.ID != "abc" || len(decoded.Individuals) != 2 {
+		t.Fatalf("Wrong population: %v", decoded)
+	}
+	if decoded.Individuals[0].Violation != 0.5 || !math.IsInf(decoded.Individuals[1].Fitness, 1) {
+		t.Errorf("Wrong individuals: %v", decoded.Individuals)
+	}
+}
+
+func TestPopulationSchemaVersion1(t *testing.T) {
+	// Written before the schema version was introduced
+	var (
+		v1      = `{"indis":[{"genome":[1,2],"fitness":3,"id":"x"}],"age":10,"generations":2,"id":"abc"}`
+		decoded = Population{JSONUnmarshaler: VectorJSONUnmarshaler}
+	)
+	if err := json.Unmarshal([]byte(v1), &decoded); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if decoded.ID != "abc" || decoded.Generations != 2 || decoded.Age != 10 ||
+		len(decoded.Individuals) != 1 || decoded.Individuals[0].Fitness != 3 {
+		t.Errorf("Wrong population: %v", decoded)
+	}
+}
+
+func TestPopulationSchemaVersionUnsupported(t *testing.T) {
+	for _, doc := range []string{
+		`{"schema_version":3,"indis":[],"id":"abc"}`,
+		`{"schema_version":0,"indis":[],"id":"abc"}`,
+		`{"schema_version":"2","indis":[],"id":"abc"}`,
+	} {
+		var decoded = Population{JSONUnmarshaler: VectorJSONUnmarshaler}
+		if err := json.Unmarshal([]byte(doc), &decoded); err == nil {
+			t.Errorf("Expected an error for %s", doc)
+		}
+	}
+}
+
+func TestSchemaMigrations(t *testing.T) {
+	// Pretend version 1 named some fields differently
+	var rename = func(from, to string) func(doc map[string]json.RawMessage) error {
+		return func(doc map[string]json.RawMessage) error {
+			doc[to] = doc[from]
+			delete(doc, from)
+			return nil
+		}
+	}
+	var saved = schemaMigrations[0]
+	defer func() { schemaMigrations[0] = saved }()
+	schemaMigrations[0] = schemaMigration{
+		population: rename("name", "id"),
+		individual: rename("fit", "fitness"),
+	}
+	var (
+		v1      = `{"indis":[{"genome":[1,2],"fit":3,"id":"x"}],"name":"abc"}`
+		decoded = Population{JSONUnmarshaler: VectorJSONUnmarshaler}
+	)
+	if err := json.Unmarshal([]byte(v1), &decoded); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if decoded.ID != "abc" || len(decoded.Individuals) != 1 || decoded.Individuals[0].Fitness != 3 {
+		t.Errorf("Wrong population: %v", decoded)
+	}
+	// The current version isn't migrated
+	var v2 = `{"schema_version":2,"indis":[{"genome":[1,2],"fit":3,"id":"x"}],"name":"abc"}`
+	decoded = Population{JSONUnmarshaler: VectorJSONUnmarshaler}
+	if err := json.Unmarshal([]byte(v2), &decoded); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if decoded.ID != "" || !math.IsInf(decoded.Individuals[0].Fitness, 1) {
+		t.Errorf("Wrong population: %v", decoded)
+	}
+}
+
+func TestGASchemaVersion1(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 1
+	conf.NGenerations = 2
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var b []byte
+	if b, err = json.Marshal(ga); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if !strings.HasPrefix(string(b), `{"schema_version":2,`) {
+		t.Errorf("Unexpected encoding: %.40s", b)
+	}
+	// Strip the schema versions to obtain a version 1 document
+	var v1 = strings.ReplaceAll(string(b), `"schema_version":2,`, "")
+	var resumed *GA
+	if resumed, err = conf.NewGA(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = json.Unmarshal([]byte(v1), resumed); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if resumed.Generations != 2 || len(resumed.Populations[0].Individuals) != int(conf.PopSize) ||
+		resumed.HallOfFame[0].Fitness != ga.HallOfFame[0].Fitness {
+		t.Error("The GA wasn't decoded")
+	}
+}
@@ -0,0 +1,331 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// The kinds of dimensions of a SearchSpace.
+const (
+	dimFloat = iota
+	dimInt
+	dimChoice
+	dimPerm
+)
+
+// A dimension of a SearchSpace.
+type dimension struct {
+	name     string
+	kind     int
+	min, max float64       // Bounds of float and int dimensions
+	choices  []interface{} // Values of a choice dimension
+	n        int           // Length of a permutation dimension
+}
+
+// sample a value uniformly from the dimension. Choices are sampled as an
+// index into the choices.
+func (d dimension) sample(rng *rand.Rand) float64 {
+	switch d.kind {
+	case dimFloat:
+		return d.min + rng.Float64()*(d.max-d.min)
+	case dimInt:
+		return d.min + float64(rng.Intn(int(d.max-d.min)+1))
+	default:
+		return float64(rng.Intn(len(d.choices)))
+	}
+}
+
+// A SearchSpace describes the variables of a problem, typically the
+// hyperparameters of a model, from which a genome and its operators are
+// derived. Dimensions are added with the Float, Int, Choice and Perm methods,
+// which can be chained:
+//
+//	var space = eaopt.NewSearchSpace().
+//		Float("learning_rate", 1e-4, 1e-1).
+//		Int("layers", 1, 8).
+//		Choice("activation", "relu", "tanh")
+//
+// Its NewGenome method produces Points, which can be handed to GA.Minimize.
+// A Point is mutated dimension by dimension with probability MutRate:
+//
+//   - float dimensions receive gaussian noise with a standard deviation of
+//     Sigma times the width of their bounds and are clipped to their bounds
+//   - int dimensions receive rounded gaussian noise, of at least 1, and are
+//     clipped to their bounds
+//   - choice dimensions are resampled among the other choices
+//   - permutation dimensions have two of their elements swapped
+//
+// Crossover is uniform over the scalar dimensions whereas permutations are
+// recombined with PMX, hence every Point stays inside the SearchSpace.
+type SearchSpace struct {
+	MutRate float64 // Probability of mutating each dimension, 0 means 1 over the number of dimensions
+	Sigma   float64 // Standard deviation of a mutation, relative to the width of the bounds, 0 means 0.1
+	F       func(p *Point) (float64, error)
+
+	dims  []dimension
+	index map[string]int
+	err   error // First error encountered while adding dimensions
+}
+
+// NewSearchSpace returns an empty SearchSpace.
+func NewSearchSpace() *SearchSpace {
+	return &SearchSpace{index: make(map[string]int)}
+}
+
+// add a dimension, the first invalid dimension is reported by Validate.
+func (s *SearchSpace) add(d dimension) *SearchSpace {
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+	if _, ok := s.index[d.name]; ok && s.err == nil {
+		s.err = fmt.Errorf("dimension %q is declared twice", d.name)
+	}
+	s.index[d.name] = len(s.dims)
+	s.dims = append(s.dims, d)
+	return s
+}
+
+// Float adds a dimension of floats between min and max.
+func (s *SearchSpace) Float(name string, min, max float64) *SearchSpace {
+	if (min > max || math.IsInf(min, 0) || math.IsInf(max, 0)) && s.err == nil {
+		s.err = fmt.Errorf("dimension %q should have finite bounds with min lower or equal to max", name)
+	}
+	return s.add(dimension{name: name, kind: dimFloat, min: min, max: max})
+}
+
+// Int adds a dimension of integers between min and max, both included.
+func (s *SearchSpace) Int(name string, min, max int) *SearchSpace {
+	if min > max && s.err == nil {
+		s.err = fmt.Errorf("dimension %q should have min lower or equal to max", name)
+	}
+	return s.add(dimension{name: name, kind: dimInt, min: float64(min), max: float64(max)})
+}
+
+// Choice adds a dimension whose value is one of choices. The choices are
+// unordered, use Int for ordinal variables.
+func (s *SearchSpace) Choice(name string, choices ...interface{}) *SearchSpace {
+	if len(choices) == 0 && s.err == nil {
+		s.err = fmt.Errorf("dimension %q should have at least one choice", name)
+	}
+	return s.add(dimension{name: name, kind: dimChoice, choices: choices})
+}
+
+// Perm adds a dimension whose value is a permutation of the integers from 0
+// to n-1, for instance the order in which n tasks are scheduled.
+func (s *SearchSpace) Perm(name string, n int) *SearchSpace {
+	if n < 1 && s.err == nil {
+		s.err = fmt.Errorf("dimension %q should have a positive length", name)
+	}
+	return s.add(dimension{name: name, kind: dimPerm, n: n})
+}
+
+// Names returns the names of the dimensions, in the order they were added.
+func (s *SearchSpace) Names() []string {
+	var names = make([]string, len(s.dims))
+	for i, d := range s.dims {
+		names[i] = d.name
+	}
+	return names
+}
+
+// Validate SearchSpace fields.
+func (s SearchSpace) Validate() error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.dims) == 0 {
+		return errors.New("at least one dimension has to be provided")
+	}
+	if s.MutRate < 0 || s.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	if s.Sigma < 0 {
+		return errors.New("Sigma should be positive")
+	}
+	if s.F == nil {
+		return errors.New("F cannot be nil")
+	}
+	return nil
+}
+
+// mutRate returns MutRate or its default value.
+func (s *SearchSpace) mutRate() float64 {
+	if s.MutRate == 0 {
+		return 1 / float64(len(s.dims))
+	}
+	return s.MutRate
+}
+
+// sigma returns Sigma or its default value.
+func (s *SearchSpace) sigma() float64 {
+	if s.Sigma == 0 {
+		return 0.1
+	}
+	return s.Sigma
+}
+
+// NewGenome returns a Point sampled uniformly from the SearchSpace.
+func (s *SearchSpace) NewGenome(rng *rand.Rand) Genome {
+	var p = &Point{
+		Values: make([]float64, len(s.dims)),
+		Perms:  make([][]int, len(s.dims)),
+		Space:  s,
+	}
+	for i, d := range s.dims {
+		if d.kind == dimPerm {
+			p.Perms[i] = rng.Perm(d.n)
+		} else {
+			p.Values[i] = d.sample(rng)
+		}
+	}
+	return p
+}
+
+// JSONUnmarshaler decodes a Point of the SearchSpace, it can be used as
+// GAConfig.GenomeJSONUnmarshaler.
+func (s *SearchSpace) JSONUnmarshaler(data []byte) (Genome, error) {
+	var p = &Point{Space: s}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if len(p.Values) != len(s.dims) || len(p.Perms) != len(s.dims) {
+		return nil, errors.New("the point doesn't match the search space")
+	}
+	return p, nil
+}
+
+// A Point is a Genome holding one value for each dimension of a SearchSpace.
+// Values holds the values of the float and int dimensions and the index of
+// the value of the choice dimensions, whereas Perms holds the values of the
+// permutation dimensions; each entry that doesn't belong to a dimension of
+// the matching kind is unused. The typed accessors are usually more
+// convenient.
+type Point struct {
+	Values []float64    `json:"values"`
+	Perms  [][]int      `json:"perms"`
+	Space  *SearchSpace `json:"-"`
+}
+
+// dim returns the index of the dimension with the given name and kind, it
+// panics if there is none, like a type assertion would.
+func (p *Point) dim(name string, kind int) int {
+	var i, ok = p.Space.index[name]
+	if !ok || p.Space.dims[i].kind != kind {
+		panic(fmt.Sprintf("eaopt: the search space has no %s dimension named %q",
+			[]string{"float", "int", "choice", "permutation"}[kind], name))
+	}
+	return i
+}
+
+// Float returns the value of a float dimension.
+func (p *Point) Float(name string) float64 {
+	return p.Values[p.dim(name, dimFloat)]
+}
+
+// Int returns the value of an int dimension.
+func (p *Point) Int(name string) int {
+	return int(p.Values[p.dim(name, dimInt)])
+}
+
+// Choice returns the value of a choice dimension.
+func (p *Point) Choice(name string) interface{} {
+	var i = p.dim(name, dimChoice)
+	return p.Space.dims[i].choices[int(p.Values[i])]
+}
+
+// Perm returns the value of a permutation dimension. The slice isn't copied.
+func (p *Point) Perm(name string) []int {
+	return p.Perms[p.dim(name, dimPerm)]
+}
+
+// Map returns the value of each dimension keyed by the name of the
+// dimension, which is handy for logging.
+func (p *Point) Map() map[string]interface{} {
+	var m = make(map[string]interface{}, len(p.Space.dims))
+	for i, d := range p.Space.dims {
+		switch d.kind {
+		case dimFloat:
+			m[d.name] = p.Values[i]
+		case dimInt:
+			m[d.name] = int(p.Values[i])
+		case dimChoice:
+			m[d.name] = d.choices[int(p.Values[i])]
+		case dimPerm:
+			m[d.name] = append([]int(nil), p.Perms[i]...)
+		}
+	}
+	return m
+}
+
+// Evaluate the Point with the SearchSpace's objective function.
+func (p *Point) Evaluate() (float64, error) {
+	return p.Space.F(p)
+}
+
+// Mutate the Point, see SearchSpace.
+func (p *Point) Mutate(rng *rand.Rand) {
+	var (
+		rate  = p.Space.mutRate()
+		sigma = p.Space.sigma()
+	)
+	for i, d := range p.Space.dims {
+		if rng.Float64() >= rate {
+			continue
+		}
+		switch d.kind {
+		case dimFloat:
+			p.Values[i] = math.Min(math.Max(p.Values[i]+rng.NormFloat64()*sigma*(d.max-d.min), d.min), d.max)
+		case dimInt:
+			var step = math.Round(rng.NormFloat64() * sigma * (d.max - d.min))
+			if step == 0 {
+				step = 1
+				if rng.Float64() < 0.5 {
+					step = -1
+				}
+			}
+			p.Values[i] = math.Min(math.Max(p.Values[i]+step, d.min), d.max)
+		case dimChoice:
+			// Pick one of the other choices
+			if n := len(d.choices); n > 1 {
+				p.Values[i] = float64((int(p.Values[i]) + 1 + rng.Intn(n-1)) % n)
+			}
+		case dimPerm:
+			MutPermuteInt(p.Perms[i], 1, rng)
+		}
+	}
+}
+
+// Crossover applies uniform crossover to the scalar dimensions and PMX to the
+// permutation dimensions.
+func (p *Point) Crossover(q Genome, rng *rand.Rand) {
+	var o = q.(*Point)
+	for i, d := range p.Space.dims {
+		if d.kind == dimPerm {
+			if d.n > 1 {
+				CrossPMXInt(p.Perms[i], o.Perms[i], rng)
+			}
+			continue
+		}
+		if rng.Float64() < 0.5 {
+			p.Values[i], o.Values[i] = o.Values[i], p.Values[i]
+		}
+	}
+}
+
+// Clone returns a deep copy of the Point.
+func (p Point) Clone() Genome {
+	var clone = &Point{
+		Values: append([]float64(nil), p.Values...),
+		Perms:  make([][]int, len(p.Perms)),
+		Space:  p.Space,
+	}
+	for i, perm := range p.Perms {
+		if perm != nil {
+			clone.Perms[i] = append([]int(nil), perm...)
+		}
+	}
+	return clone
+}
@@ -0,0 +1,165 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"testing"
+)
+
+func newTestSearchSpace() *SearchSpace {
+	var space = NewSearchSpace().
+		Float("x", -2, 2).
+		Int("n", 1, 5).
+		Choice("activation", "relu", "tanh", "sigmoid").
+		Perm("order", 4)
+	space.F = func(p *Point) (float64, error) {
+		var f = p.Float("x")*p.Float("x") + float64(p.Int("n"))
+		if p.Choice("activation") != "tanh" {
+			f++
+		}
+		// Reward the identity permutation
+		for i, v := range p.Perm("order") {
+			if i != v {
+				f++
+			}
+		}
+		return f, nil
+	}
+	return space
+}
+
+// checkPoint verifies that each value of a Point lies inside its dimension.
+func checkPoint(t *testing.T, p *Point) {
+	if x := p.Float("x"); x < -2 || x > 2 {
+		t.Errorf("x is out of bounds: %f", x)
+	}
+	if n := p.Int("n"); n < 1 || n > 5 || float64(n) != p.Values[1] {
+		t.Errorf("n is out of bounds: %f", p.Values[1])
+	}
+	if a := p.Choice("activation"); a != "relu" && a != "tanh" && a != "sigmoid" {
+		t.Errorf("Unknown activation %v", a)
+	}
+	var order = append([]int(nil), p.Perm("order")...)
+	sort.Ints(order)
+	for i, v := range order {
+		if i != v {
+			t.Errorf("order isn't a permutation: %v", p.Perm("order"))
+			break
+		}
+	}
+}
+
+func TestSearchSpaceOperators(t *testing.T) {
+	var (
+		rng   = newRand()
+		space = newTestSearchSpace()
+	)
+	space.MutRate = 1
+	space.Sigma = 0.5
+	if err := space.Validate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		var (
+			p = space.NewGenome(rng).(*Point)
+			q = space.NewGenome(rng).(*Point)
+			c = p.Clone().(*Point)
+		)
+		checkPoint(t, p)
+		p.Mutate(rng)
+		q.Mutate(rng)
+		p.Crossover(q, rng)
+		checkPoint(t, p)
+		checkPoint(t, q)
+		if &c.Perms[3][0] == &p.Perms[3][0] {
+			t.Error("The clone shares its permutation")
+		}
+	}
+}
+
+func TestSearchSpaceMinimize(t *testing.T) {
+	var space = newTestSearchSpace()
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 30
+	conf.GenomeJSONUnmarshaler = space.JSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Minimize(space.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var best = ga.HallOfFame[0].Genome.(*Point)
+	if ga.HallOfFame[0].Fitness > 1.5 || best.Choice("activation") != "tanh" || best.Int("n") != 1 {
+		t.Errorf("Expected a fitness close to 1, got %f with %v", ga.HallOfFame[0].Fitness, best.Map())
+	}
+	// Points can be saved and restored
+	var b []byte
+	if b, err = json.Marshal(ga); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var resumed, _ = conf.NewGA()
+	if err = json.Unmarshal(b, resumed); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var p = resumed.HallOfFame[0].Genome.(*Point)
+	if f, _ := p.Evaluate(); math.Abs(f-ga.HallOfFame[0].Fitness) > 1e-9 {
+		t.Errorf("Expected %f, got %f", ga.HallOfFame[0].Fitness, f)
+	}
+	if _, err = space.JSONUnmarshaler([]byte(`{"values":[1],"perms":[]}`)); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestSearchSpaceValidate(t *testing.T) {
+	var f = func(p *Point) (float64, error) { return 0, nil }
+	for _, space := range []*SearchSpace{
+		NewSearchSpace(),
+		NewSearchSpace().Float("x", 1, 0),
+		NewSearchSpace().Float("x", 0, math.Inf(1)),
+		NewSearchSpace().Int("n", 3, 2),
+		NewSearchSpace().Choice("c"),
+		NewSearchSpace().Perm("p", 0),
+		NewSearchSpace().Float("x", 0, 1).Int("x", 0, 1),
+	} {
+		space.F = f
+		if err := space.Validate(); err == nil {
+			t.Error("Expected an error")
+		}
+	}
+	var space = NewSearchSpace().Float("x", 0, 1)
+	if err := space.Validate(); err == nil {
+		t.Error("Expected an error without F")
+	}
+	space.F = f
+	space.MutRate = 2
+	if err := space.Validate(); err == nil {
+		t.Error("Expected an error with an invalid MutRate")
+	}
+	space.MutRate = 0
+	space.Sigma = -1
+	if err := space.Validate(); err == nil {
+		t.Error("Expected an error with an invalid Sigma")
+	}
+}
+
+func TestPointAccessors(t *testing.T) {
+	var (
+		space = newTestSearchSpace()
+		p     = space.NewGenome(newRand()).(*Point)
+		m     = p.Map()
+	)
+	if len(m) != 4 || m["x"] != p.Float("x") || m["n"] != p.Int("n") {
+		t.Errorf("Wrong map %v", m)
+	}
+	if names := space.Names(); len(names) != 4 || names[2] != "activation" {
+		t.Errorf("Wrong names %v", names)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	p.Int("x")
+}
@@ -3,6 +3,7 @@ package eaopt
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 )
@@ -30,7 +31,8 @@ func (sel SelElitism) Validate() error {
 
 // SelTournament samples individuals through tournament selection. The
 // tournament is composed of randomly chosen individuals. The winner of the
-// tournament is the chosen individual with the lowest fitness. The obtained
+// tournament is the chosen individual with the lowest fitness, feasibility
+// coming first if the GA has a ConstraintHandler. The obtained
 // individuals are all distinct, in other words there are no repetitions.
 type SelTournament struct {
 	NContestants uint
@@ -38,11 +40,19 @@ type SelTournament struct {
 
 // Apply SelTournament.
 func (sel SelTournament) Apply(n uint, indis Individuals, rng *rand.Rand) (Individuals, []int, error) {
+	return tournament(n, sel.NContestants, indis, Individual.Better, rng)
+}
+
+// tournament selects n individuals through tournaments of nContestants
+// individuals, the winner of a tournament being the contestant that is better
+// than the others.
+func tournament(n, nContestants uint, indis Individuals, better func(a, b Individual) bool,
+	rng *rand.Rand) (Individuals, []int, error) {
 	// Check that the number of individuals is large enough
-	if uint(len(indis))-n < sel.NContestants-1 || len(indis) < int(n) {
+	if uint(len(indis))-n < nContestants-1 || len(indis) < int(n) {
 		return nil, nil, fmt.Errorf("not enough individuals to select %d "+
 			"with NContestants = %d, have %d individuals and need at least %d",
-			n, sel.NContestants, len(indis), sel.NContestants+n-1)
+			n, nContestants, len(indis), nContestants+n-1)
 	}
 	var (
 		winners         = make(Individuals, n)
@@ -52,18 +62,15 @@ func (sel SelTournament) Apply(n uint, indis Individuals, rng *rand.Rand) (Indiv
 	for i := range winners {
 		// Sample contestants
 		var (
-			contestants, idxs, _ = sampleInts(notSelectedIdxs, sel.NContestants, rng)
+			contestants, idxs, _ = sampleInts(notSelectedIdxs, nContestants, rng)
 			winnerIdx            int
 		)
 		// Find the best contestant
-		winners[i] = indis[contestants[0]]
-		err := winners[i].Evaluate()
-		if err != nil {
-			return nil, nil, err
-		}
-		winnerIdx = idxs[0]
 		for j, idx := range contestants {
-			if indis[idx].GetFitness() < winners[i].Fitness {
+			if err := indis[idx].Evaluate(); err != nil {
+				return nil, nil, err
+			}
+			if j == 0 || better(indis[idx], winners[i]) {
 				winners[i] = indis[idx]
 				indexes[i] = idx
 				winnerIdx = idxs[j]
@@ -83,6 +90,81 @@ func (sel SelTournament) Validate() error {
 	return nil
 }
 
+// SelCostTournament is a tournament selection which balances fitness against
+// evaluation cost, which is useful when the cost of evaluating Genomes varies a
+// lot. The winner of a tournament is the contestant with the lowest fitness
+// plus CostWeight times its evaluation duration in seconds, hence expensive
+// Individuals need a better fitness to be selected. Use SelTournament if
+// CostWeight is 0.
+type SelCostTournament struct {
+	NContestants uint
+	CostWeight   float64
+}
+
+// Apply SelCostTournament.
+func (sel SelCostTournament) Apply(n uint, indis Individuals, rng *rand.Rand) (Individuals, []int, error) {
+	var withCost = func(indi Individual) Individual {
+		indi.Fitness += sel.CostWeight * indi.EvalDuration.Seconds()
+		return indi
+	}
+	return tournament(n, sel.NContestants, indis, func(a, b Individual) bool {
+		return withCost(a).Better(withCost(b))
+	}, rng)
+}
+
+// Validate SelCostTournament fields.
+func (sel SelCostTournament) Validate() error {
+	if sel.NContestants < 1 {
+		return errors.New("NContestants should be higher than 0")
+	}
+	if sel.CostWeight < 0 {
+		return errors.New("CostWeight should be positive")
+	}
+	return nil
+}
+
+// SelParsimonyTournament is a tournament selection which counters bloat, the
+// growth of variable-length Genomes without improvement of their fitness. The
+// winner of a tournament is the contestant with the lowest fitness plus
+// Coefficient times the length of its Genome; contestants that are equally
+// good are then compared by length, which is known as lexicographic parsimony
+// pressure. With a Coefficient of 0 the length only breaks ties. The length of
+// a Genome is given by its Len method if it has one, or by the length of the
+// underlying slice.
+type SelParsimonyTournament struct {
+	NContestants uint
+	Coefficient  float64
+}
+
+// Apply SelParsimonyTournament.
+func (sel SelParsimonyTournament) Apply(n uint, indis Individuals, rng *rand.Rand) (Individuals, []int, error) {
+	var withLength = func(indi Individual) Individual {
+		indi.Fitness += sel.Coefficient * float64(genomeLength(indi.Genome))
+		return indi
+	}
+	return tournament(n, sel.NContestants, indis, func(a, b Individual) bool {
+		var pa, pb = withLength(a), withLength(b)
+		if pa.Better(pb) {
+			return true
+		}
+		if pb.Better(pa) {
+			return false
+		}
+		return genomeLength(a.Genome) < genomeLength(b.Genome)
+	}, rng)
+}
+
+// Validate SelParsimonyTournament fields.
+func (sel SelParsimonyTournament) Validate() error {
+	if sel.NContestants < 1 {
+		return errors.New("NContestants should be higher than 0")
+	}
+	if sel.Coefficient < 0 {
+		return errors.New("Coefficient should be positive")
+	}
+	return nil
+}
+
 // SelRoulette samples individuals through roulette wheel selection (also known
 // as fitness proportionate selection).
 type SelRoulette struct{}
@@ -103,7 +185,7 @@ func (sel SelRoulette) Apply(n uint, indis Individuals, rng *rand.Rand) (Individ
 	var (
 		selected = make(Individuals, n)
 		indexes  = make([]int, n)
-		wheel    = buildWheel(indis.getFitnesses())
+		wheel    = buildWheel(indis.constrainedFitnesses())
 	)
 	for i := range selected {
 		var (
@@ -120,3 +202,45 @@ func (sel SelRoulette) Apply(n uint, indis Individuals, rng *rand.Rand) (Individ
 func (sel SelRoulette) Validate() error {
 	return nil
 }
+
+// SelBoltzmann samples individuals with probabilities proportional to
+// exp(-(f - best) / Temperature), where f is the fitness of an individual and
+// best is the lowest fitness of the group, infeasible individuals being
+// penalized as with SelRoulette. The Temperature is expressed in units of
+// fitness: a low Temperature mostly selects the best individuals whereas a
+// high Temperature selects them almost uniformly.
+type SelBoltzmann struct {
+	Temperature float64
+}
+
+// Apply SelBoltzmann.
+func (sel SelBoltzmann) Apply(n uint, indis Individuals, rng *rand.Rand) (Individuals, []int, error) {
+	var (
+		selected  = make(Individuals, n)
+		indexes   = make([]int, n)
+		fitnesses = indis.constrainedFitnesses()
+		best      = math.Inf(1)
+		weights   = make([]float64, len(fitnesses))
+	)
+	for _, f := range fitnesses {
+		best = math.Min(best, f)
+	}
+	for i, f := range fitnesses {
+		weights[i] = math.Exp(-(f - best) / sel.Temperature)
+	}
+	var wheel = cumsum(divide(weights, sumFloat64s(weights)))
+	for i := range selected {
+		var index = minInt(sort.SearchFloat64s(wheel, rng.Float64()), len(indis)-1)
+		indexes[i] = index
+		selected[i] = indis[index]
+	}
+	return selected.Clone(rng), indexes, nil
+}
+
+// Validate SelBoltzmann fields.
+func (sel SelBoltzmann) Validate() error {
+	if sel.Temperature <= 0 {
+		return errors.New("Temperature should be strictly positive")
+	}
+	return nil
+}
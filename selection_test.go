@@ -3,16 +3,25 @@ package eaopt
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 var (
 	validSelectors = []Selector{
 		SelElitism{},
 		SelTournament{3},
+		SelCostTournament{NContestants: 3, CostWeight: 1},
+		SelParsimonyTournament{NContestants: 3, Coefficient: 0.1},
 		SelRoulette{},
+		SelBoltzmann{Temperature: 1},
 	}
 	invalidSelectors = []Selector{
 		SelTournament{0},
+		SelCostTournament{NContestants: 0},
+		SelCostTournament{NContestants: 3, CostWeight: -1},
+		SelParsimonyTournament{NContestants: 0},
+		SelParsimonyTournament{NContestants: 3, Coefficient: -1},
+		SelBoltzmann{},
 	}
 )
 
@@ -99,6 +108,65 @@ func TestSelTournament(t *testing.T) {
 	if selected[0].Fitness != indis.FitMin() {
 		t.Error("Full SelTournament didn't select the best individual")
 	}
+	// The indexes point to the selected individuals
+	selected, indexes, _ := SelTournament{1}.Apply(10, indis, rng)
+	for i := range selected {
+		if selected[i].Fitness != indis[indexes[i]].Fitness {
+			t.Errorf("Index %d doesn't point to the selected individual", indexes[i])
+		}
+	}
+}
+
+func TestSelCostTournament(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = Individuals{
+			{Genome: Vector{1}, Fitness: 1, Evaluated: true, EvalDuration: 10 * time.Second},
+			{Genome: Vector{2}, Fitness: 2, Evaluated: true, EvalDuration: time.Second},
+			{Genome: Vector{3}, Fitness: 3, Evaluated: true},
+		}
+	)
+	for _, tc := range []struct {
+		weight   float64
+		expected float64
+	}{{0, 1}, {0.5, 2}, {2, 3}} {
+		var selected, indexes, err = SelCostTournament{NContestants: 3, CostWeight: tc.weight}.Apply(1, indis, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if selected[0].Fitness != tc.expected || indis[indexes[0]].Fitness != tc.expected {
+			t.Errorf("Expected %f with a weight of %f, got %f", tc.expected, tc.weight, selected[0].Fitness)
+		}
+	}
+}
+
+func TestSelParsimonyTournament(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = Individuals{
+			{Genome: Vector{1, 1, 1, 1}, Fitness: 1, Evaluated: true},
+			{Genome: Vector{2}, Fitness: 2, Evaluated: true},
+			{Genome: Vector{2, 2}, Fitness: 2, Evaluated: true},
+		}
+	)
+	for _, tc := range []struct {
+		coefficient float64
+		expected    int
+	}{{0, 0}, {0.5, 1}, {10, 1}} {
+		var _, indexes, err = SelParsimonyTournament{NContestants: 3, Coefficient: tc.coefficient}.Apply(1, indis, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if indexes[0] != tc.expected {
+			t.Errorf("Expected %d with a coefficient of %f, got %d", tc.expected, tc.coefficient, indexes[0])
+		}
+	}
+	// Equally fit individuals are compared by length
+	indis[0].Fitness = 2
+	var _, indexes, _ = SelParsimonyTournament{NContestants: 3}.Apply(1, indis, rng)
+	if indexes[0] != 1 {
+		t.Errorf("Expected the shortest individual, got %d", indexes[0])
+	}
 }
 
 func TestBuildWheel(t *testing.T) {
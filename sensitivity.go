@@ -0,0 +1,241 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+)
+
+// SensitivityOptions configure the sensitivity analysis of the best Genome
+// that is included in a GA's GenerationStats, see
+// GAConfig.SensitivityAnalysis and AnalyzeSensitivity. Each value is moved by
+// Step times the width of its bounds, or by Step if it is unbounded; 0.01 is
+// used if Step is 0. The analysis is done every Every generations, 0 is the
+// same as 1. It costs two evaluations per value, which are accounted for by
+// the GA's Evaluations.
+type SensitivityOptions struct {
+	Step  float64 // Relative size of the perturbations
+	Every uint    // Frequency at which the best Genome is analyzed
+}
+
+// Validate SensitivityOptions fields.
+func (so SensitivityOptions) Validate() error {
+	if so.Step < 0 || math.IsNaN(so.Step) || math.IsInf(so.Step, 0) {
+		return errors.New("Step should be positive")
+	}
+	return nil
+}
+
+// due returns true if the best Genome should be analyzed at the given
+// generation.
+func (so SensitivityOptions) due(generation uint) bool {
+	return so.Every <= 1 || generation%so.Every == 0
+}
+
+// DimensionSensitivity describes how the fitness reacts to a change of a
+// single value. Value is the value of the analyzed Genome, Minus and Plus are
+// the fitnesses obtained by decreasing and increasing it, the perturbed value
+// being clipped to the bounds. Gradient is the central difference estimate
+// of the derivative of the fitness and Effect is the largest absolute change
+// of the fitness, which is used to rank the values. Importance is Effect
+// divided by the sum of the Effects, it is NaN if the sum isn't positive and
+// finite. Rank is 1 for the value which matters the most.
+type DimensionSensitivity struct {
+	Dimension  int     `json:"dimension"`
+	Value      float64 `json:"value"`
+	Minus      float64 `json:"minus"`
+	Plus       float64 `json:"plus"`
+	Gradient   float64 `json:"gradient"`
+	Effect     float64 `json:"effect"`
+	Importance float64 `json:"importance"`
+	Rank       int     `json:"rank"`
+}
+
+// MarshalJSON encodes a DimensionSensitivity, non-finite values are encoded
+// as null.
+func (ds DimensionSensitivity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Dimension  int      `json:"dimension"`
+		Value      float64  `json:"value"`
+		Minus      *float64 `json:"minus"`
+		Plus       *float64 `json:"plus"`
+		Gradient   *float64 `json:"gradient"`
+		Effect     *float64 `json:"effect"`
+		Importance *float64 `json:"importance"`
+		Rank       int      `json:"rank"`
+	}{ds.Dimension, ds.Value, jsonFloat64(ds.Minus), jsonFloat64(ds.Plus), jsonFloat64(ds.Gradient),
+		jsonFloat64(ds.Effect), jsonFloat64(ds.Importance), ds.Rank})
+}
+
+// A SensitivityReport describes how sensitive the fitness of a Genome is to
+// each of its values. Dimensions are in the order of the values, whereas
+// Ranking contains the indexes of the values by decreasing Effect. Fitness is
+// the fitness of the analyzed Genome and Evaluations the number of
+// evaluations spent. Generation is the GA generation at which the report was
+// made.
+type SensitivityReport struct {
+	Generation  uint                   `json:"generation"`
+	Fitness     float64                `json:"fitness"`
+	Dimensions  []DimensionSensitivity `json:"dimensions"`
+	Ranking     []int                  `json:"ranking"`
+	Evaluations uint                   `json:"evaluations"`
+}
+
+// MarshalJSON encodes a SensitivityReport, a non-finite Fitness is encoded as
+// null.
+func (sr SensitivityReport) MarshalJSON() ([]byte, error) {
+	type alias SensitivityReport
+	return json.Marshal(struct {
+		alias
+		Fitness *float64 `json:"fitness"`
+	}{alias(sr), jsonFloat64(sr.Fitness)})
+}
+
+// AnalyzeSensitivity perturbs each value of genome, which has to be a
+// FloatGenome, in both directions and evaluates the perturbed Genomes to
+// estimate how much each value matters, see SensitivityOptions. genome is
+// evaluated as well and isn't modified.
+func AnalyzeSensitivity(genome Genome, opts SensitivityOptions) (SensitivityReport, error) {
+	if err := opts.Validate(); err != nil {
+		return SensitivityReport{}, err
+	}
+	var fitness, err = genome.Evaluate()
+	if err != nil {
+		return SensitivityReport{}, err
+	}
+	var report SensitivityReport
+	report, err = analyzeSensitivity(genome, fitness, Genome.Evaluate, opts)
+	report.Evaluations++
+	return report, err
+}
+
+// Sensitivity analyzes the sensitivity of the best Individual of the hall of
+// fame according to the GA's SensitivityOptions, or the default ones if
+// GAConfig.SensitivityAnalysis is nil. The evaluations are accounted for by
+// Evaluations and infeasible Genomes are given an infinite fitness.
+func (ga *GA) Sensitivity() (SensitivityReport, error) {
+	if len(ga.HallOfFame) == 0 || ga.HallOfFame[0].Genome == nil {
+		return SensitivityReport{}, errors.New("the hall of fame is empty")
+	}
+	var opts SensitivityOptions
+	if ga.SensitivityAnalysis != nil {
+		opts = *ga.SensitivityAnalysis
+	}
+	if err := opts.Validate(); err != nil {
+		return SensitivityReport{}, err
+	}
+	if ga.RNG == nil {
+		ga.RNG = newRand()
+	}
+	var (
+		best     = ga.HallOfFame[0]
+		fitness  = best.Fitness
+		evaluate = func(genome Genome) (float64, error) {
+			var indi = Individual{Genome: genome, Metadata: copyMetadata(best.Metadata), eval: ga.eval}
+			indi.ID = ga.eval.newID(genome, ga.RNG)
+			if err := indi.evaluate(); err != nil {
+				return 0, err
+			}
+			if indi.Violation > 0 {
+				return math.Inf(1), nil
+			}
+			return indi.Fitness, nil
+		}
+	)
+	if best.Violation > 0 {
+		fitness = math.Inf(1)
+	}
+	var report, err = analyzeSensitivity(best.Genome, fitness, evaluate, opts)
+	report.Generation = ga.Generations
+	return report, err
+}
+
+// sensitivity returns the SensitivityReport of the current generation if it
+// is due, the report is kept so that it is only made once per generation.
+func (ga *GA) sensitivity() *SensitivityReport {
+	if ga.SensitivityAnalysis == nil || !ga.SensitivityAnalysis.due(ga.Generations) {
+		return nil
+	}
+	if ga.sensitivityReport == nil || ga.sensitivityReport.Generation != ga.Generations {
+		var report, err = ga.Sensitivity()
+		if err != nil {
+			return nil
+		}
+		ga.sensitivityReport = &report
+	}
+	return ga.sensitivityReport
+}
+
+// analyzeSensitivity makes the SensitivityReport of genome, whose fitness is
+// already known.
+func analyzeSensitivity(genome Genome, fitness float64, evaluate func(Genome) (float64, error),
+	opts SensitivityOptions) (SensitivityReport, error) {
+	var fg, scales, err = startFloats(genome)
+	if err != nil {
+		return SensitivityReport{}, err
+	}
+	var (
+		step         = opts.Step
+		lower, upper = fg.Bounds()
+		x            = fg.Floats()
+		report       = SensitivityReport{Fitness: fitness, Dimensions: make([]DimensionSensitivity, len(x))}
+		total        float64
+	)
+	if step == 0 {
+		step = 0.01
+	}
+	// perturb evaluates a clone of genome whose i-th value is v
+	var perturb = func(i int, v float64) (float64, error) {
+		if v == x[i] {
+			return fitness, nil
+		}
+		var clone = genome.Clone()
+		clone.(FloatGenome).Floats()[i] = v
+		report.Evaluations++
+		var f, err = evaluate(clone)
+		if math.IsNaN(f) {
+			f = math.Inf(1)
+		}
+		return f, err
+	}
+	for i := range x {
+		var minus, plus = x[i] - step*scales[i], x[i] + step*scales[i]
+		if lower != nil && upper != nil {
+			minus, plus = math.Max(minus, lower[i]), math.Min(plus, upper[i])
+		}
+		var ds = DimensionSensitivity{Dimension: i, Value: x[i]}
+		if ds.Minus, err = perturb(i, minus); err != nil {
+			return SensitivityReport{}, err
+		}
+		if ds.Plus, err = perturb(i, plus); err != nil {
+			return SensitivityReport{}, err
+		}
+		if plus > minus {
+			ds.Gradient = (ds.Plus - ds.Minus) / (plus - minus)
+		}
+		ds.Effect = math.Max(math.Abs(ds.Minus-fitness), math.Abs(ds.Plus-fitness))
+		if !math.IsNaN(ds.Effect) {
+			total += ds.Effect
+		}
+		report.Dimensions[i] = ds
+	}
+	report.Ranking = make([]int, len(x))
+	for i := range report.Ranking {
+		report.Ranking[i] = i
+	}
+	// NaN Effects, which stem from an infinite fitness, are ranked last
+	sort.SliceStable(report.Ranking, func(i, j int) bool {
+		var a, b = report.Dimensions[report.Ranking[i]].Effect, report.Dimensions[report.Ranking[j]].Effect
+		return a > b || (!math.IsNaN(a) && math.IsNaN(b))
+	})
+	for rank, i := range report.Ranking {
+		var ds = &report.Dimensions[i]
+		ds.Rank = rank + 1
+		ds.Importance = math.NaN()
+		if total > 0 && !math.IsInf(total, 0) {
+			ds.Importance = ds.Effect / total
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,130 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeSensitivity(t *testing.T) {
+	var p = newTestFloatProblem()
+	p.F = func(x []float64) (float64, error) {
+		return 100*x[0]*x[0] + x[1]*x[1], nil
+	}
+	var v = &FloatVector{Values: []float64{1, 1, 5}, Problem: p}
+	var report, err = AnalyzeSensitivity(v, SensitivityOptions{Step: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Fitness != 101 || report.Evaluations != 6 {
+		t.Errorf("Expected fitness 101 and 6 evaluations, got %+v", report)
+	}
+	if len(report.Ranking) != 3 || report.Ranking[0] != 0 || report.Ranking[1] != 1 || report.Ranking[2] != 2 {
+		t.Errorf("Expected ranking [0 1 2], got %v", report.Ranking)
+	}
+	// The gradient of 100x0² + x1² at (1, 1) is (200, 2)
+	for i, expected := range []float64{200, 2, 0} {
+		var ds = report.Dimensions[i]
+		if math.Abs(ds.Gradient-expected) > 1e-6 {
+			t.Errorf("Expected gradient %f for dimension %d, got %f", expected, i, ds.Gradient)
+		}
+		if ds.Rank != i+1 || ds.Dimension != i || ds.Value != v.Values[i] {
+			t.Errorf("Inconsistent dimension %+v", ds)
+		}
+	}
+	// The perturbation of the third value is clipped to the upper bound
+	if report.Dimensions[2].Plus != 101 || report.Dimensions[2].Effect != 0 {
+		t.Errorf("Expected a clipped perturbation without effect, got %+v", report.Dimensions[2])
+	}
+	var sum float64
+	for _, ds := range report.Dimensions {
+		sum += ds.Importance
+	}
+	if math.Abs(sum-1) > 1e-9 || report.Dimensions[0].Importance < 0.9 {
+		t.Errorf("Expected importances summing to 1, got %+v", report.Dimensions)
+	}
+	if v.Values[0] != 1 || v.Values[1] != 1 || v.Values[2] != 5 {
+		t.Errorf("Expected the genome to be left untouched, got %v", v.Values)
+	}
+	// Errors
+	if _, err = AnalyzeSensitivity(v, SensitivityOptions{Step: -1}); err == nil {
+		t.Error("Expected an error for a negative Step")
+	}
+	if _, err = AnalyzeSensitivity(Vector{1, 2}, SensitivityOptions{}); err == nil {
+		t.Error("Expected an error for a Genome which isn't a FloatGenome")
+	}
+	if _, err = AnalyzeSensitivity(NewErrorGenome(nil), SensitivityOptions{}); err == nil {
+		t.Error("Expected an evaluation error")
+	}
+}
+
+func TestGASensitivity(t *testing.T) {
+	var (
+		p     = newTestFloatProblem()
+		conf  = NewDefaultGAConfig()
+		stats []GenerationStats
+	)
+	conf.NGenerations = 6
+	conf.SensitivityAnalysis = &SensitivityOptions{Every: 3}
+	conf.Callback = func(ga *GA) {
+		var evals = ga.Evaluations()
+		stats = append(stats, ga.Stats())
+		// The report is only made once per generation
+		ga.Stats()
+		if s := stats[len(stats)-1].Sensitivity; s != nil && ga.Evaluations() != evals+uint64(s.Evaluations) {
+			t.Errorf("Expected %d evaluations, got %d", evals+uint64(s.Evaluations), ga.Evaluations())
+		}
+	}
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ga.Minimize(p.NewGenome); err != nil {
+		t.Fatal(err)
+	}
+	var reports int
+	for _, s := range stats {
+		if s.Sensitivity == nil {
+			if s.Generation%3 == 0 {
+				t.Errorf("Expected a report at generation %d", s.Generation)
+			}
+			continue
+		}
+		reports++
+		if s.Sensitivity.Generation != s.Generation || s.Sensitivity.Fitness != s.Best {
+			t.Errorf("Expected the report of the best genome, got %+v", s.Sensitivity)
+		}
+		if len(s.Sensitivity.Dimensions) != 3 || s.Sensitivity.Evaluations > 6 {
+			t.Errorf("Inconsistent report %+v", s.Sensitivity)
+		}
+		var b, err = json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), `"sensitivity":{"generation":`) {
+			t.Errorf("Expected the report to be encoded, got %s", b)
+		}
+	}
+	// The initial generation is analyzed as well
+	if reports != 3 {
+		t.Errorf("Expected 3 reports, got %d", reports)
+	}
+	// Non-finite values are encoded as null
+	var report = SensitivityReport{
+		Fitness:    math.Inf(1),
+		Dimensions: []DimensionSensitivity{{Minus: math.Inf(1), Importance: math.NaN()}},
+	}
+	if _, err = json.Marshal(report); err != nil {
+		t.Error(err)
+	}
+	// Errors
+	ga, _ = conf.NewGA()
+	if _, err = ga.Sensitivity(); err == nil {
+		t.Error("Expected an error for an empty hall of fame")
+	}
+	conf.SensitivityAnalysis = &SensitivityOptions{Step: math.NaN()}
+	if _, err = conf.NewGA(); err == nil {
+		t.Error("Expected an error for an invalid Step")
+	}
+}
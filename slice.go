@@ -1,6 +1,9 @@
 package eaopt
 
-import "errors"
+import (
+	"errors"
+	"reflect"
+)
 
 // A Slice is a genome with a list-like structure.
 type Slice interface {
@@ -76,6 +79,53 @@ func getNeighbours(s Slice) map[interface{}]set {
 	return neighbours
 }
 
+// LengthBounds restrict the length of variable-length genomes, see for
+// instance CrossCutSplice and MutInsert. Max is ignored if it is 0.
+type LengthBounds struct {
+	Min, Max uint
+}
+
+// Validate LengthBounds fields.
+func (lb LengthBounds) Validate() error {
+	if lb.Max > 0 && lb.Min > lb.Max {
+		return errors.New("Min should be lower than or equal to Max")
+	}
+	return nil
+}
+
+// allows returns true if a genome of length n respects the bounds.
+func (lb LengthBounds) allows(n int) bool {
+	return n >= int(lb.Min) && (lb.Max == 0 || n <= int(lb.Max))
+}
+
+// concatSlices returns a new Slice made of the given Slices, which aren't
+// modified.
+func concatSlices(first Slice, others ...Slice) Slice {
+	// Copy first so that appending doesn't overwrite what follows it
+	var s = first.Copy()
+	for _, o := range others {
+		s = s.Append(o)
+	}
+	return s
+}
+
+// genomeLength returns the length of a Genome as given by its Len method, or
+// the length of the underlying slice if the Genome is a slice, and 0
+// otherwise.
+func genomeLength(genome Genome) int {
+	if l, ok := genome.(interface{ Len() int }); ok {
+		return l.Len()
+	}
+	var v = reflect.ValueOf(genome)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice {
+		return v.Len()
+	}
+	return 0
+}
+
 // IntSlice attaches the methods of Slice to []float64
 type IntSlice []int
 
@@ -299,3 +299,12 @@ func TestStringSliceCopy(t *testing.T) {
 		t.Error("StringSlice Copy method has unexpected behavior")
 	}
 }
+
+func TestLengthBoundsValidate(t *testing.T) {
+	if err := (LengthBounds{Min: 2, Max: 1}).Validate(); err == nil {
+		t.Error("Expected an error")
+	}
+	if err := (LengthBounds{Min: 2}).Validate(); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
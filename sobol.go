@@ -0,0 +1,159 @@
+package eaopt
+
+import (
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// sobolBits is the precision of the Sobol sequence.
+const sobolBits = 32
+
+var (
+	sobolMutex sync.Mutex
+	// sobolCache contains the direction numbers of the dimensions computed so
+	// far and sobolPoly the primitive polynomial used by the last of them
+	sobolCache [][sobolBits]uint32
+	sobolPoly  uint64 = 1
+)
+
+// gf2MulMod multiplies the polynomials a and b over GF(2) modulo p, whose
+// degree is d.
+func gf2MulMod(a, b, p uint64, d uint) uint64 {
+	var r uint64
+	for b > 0 {
+		if b&1 == 1 {
+			r ^= a
+		}
+		b >>= 1
+		a <<= 1
+		if a&(1<<d) != 0 {
+			a ^= p
+		}
+	}
+	return r
+}
+
+// gf2PowX returns x^e modulo p, whose degree is d.
+func gf2PowX(e, p uint64, d uint) uint64 {
+	var r, x = uint64(1), uint64(2)
+	if x&(1<<d) != 0 {
+		x ^= p
+	}
+	for e > 0 {
+		if e&1 == 1 {
+			r = gf2MulMod(r, x, p, d)
+		}
+		x = gf2MulMod(x, x, p, d)
+		e >>= 1
+	}
+	return r
+}
+
+// isPrimitive checks whether p, whose degree is d, is a primitive polynomial
+// over GF(2), that is if the order of x modulo p is 2^d - 1.
+func isPrimitive(p uint64, d uint) bool {
+	var order = uint64(1)<<d - 1
+	if gf2PowX(order, p, d) != 1 {
+		return false
+	}
+	// Check x^(order/q) isn't 1 for each prime factor q of order
+	var n = order
+	for q := uint64(2); q*q <= n; q++ {
+		if n%q != 0 {
+			continue
+		}
+		for n%q == 0 {
+			n /= q
+		}
+		if gf2PowX(order/q, p, d) == 1 {
+			return false
+		}
+	}
+	if n > 1 && gf2PowX(order/n, p, d) == 1 {
+		return false
+	}
+	return true
+}
+
+// sobolDirections returns the direction numbers of the first nDims dimensions
+// of the Sobol sequence. The first dimension is the van der Corput sequence,
+// the next ones use the primitive polynomials over GF(2) by increasing degree.
+// The initial direction numbers are drawn at random with a fixed seed rather
+// than read from the tables of Joe and Kuo, hence the sequence is always the
+// same but its projections may be of lesser quality.
+func sobolDirections(nDims int) [][sobolBits]uint32 {
+	sobolMutex.Lock()
+	defer sobolMutex.Unlock()
+	if len(sobolCache) == 0 {
+		var v [sobolBits]uint32
+		for k := range v {
+			v[k] = 1 << uint(sobolBits-1-k)
+		}
+		sobolCache = append(sobolCache, v)
+	}
+	for len(sobolCache) < nDims {
+		// Find the next primitive polynomial, which has a constant term
+		var p, d uint64
+		for p = sobolPoly + 2; ; p += 2 {
+			if d = uint64(bits.Len64(p) - 1); isPrimitive(p, uint(d)) {
+				break
+			}
+		}
+		sobolPoly = p
+		var (
+			rng = rand.New(rand.NewSource(int64(len(sobolCache))))
+			m   [sobolBits]uint32
+			v   [sobolBits]uint32
+		)
+		for k := uint64(0); k < sobolBits; k++ {
+			if k < d {
+				// m_k has to be odd and lower than 2^k
+				m[k] = uint32(2*rng.Intn(1<<k) + 1)
+				continue
+			}
+			m[k] = m[k-d] ^ (m[k-d] << d)
+			for j := uint64(1); j < d; j++ {
+				if p&(1<<(d-j)) != 0 {
+					m[k] ^= m[k-j] << j
+				}
+			}
+		}
+		for k := range v {
+			v[k] = m[k] << uint(sobolBits-1-k)
+		}
+		sobolCache = append(sobolCache, v)
+	}
+	return sobolCache[:nDims]
+}
+
+// InitSobol returns the first n points of a Sobol sequence scaled between
+// lower and upper. Sobol sequences are low discrepancy sequences, their points
+// fill the search space much more evenly than random points, especially when
+// n is a power of 2. The sequence is randomized with a random digital shift so
+// that different calls return different points.
+func InitSobol(n uint, lower, upper []float64, rng *rand.Rand) (points [][]float64) {
+	var (
+		dirs   = sobolDirections(len(lower))
+		shifts = make([]uint32, len(lower))
+	)
+	for d := range shifts {
+		shifts[d] = rng.Uint32()
+	}
+	points = make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, len(lower))
+		var gray = uint32(i) ^ uint32(i)>>1
+		for d := range lower {
+			var x = shifts[d]
+			for k := 0; gray>>uint(k) > 0; k++ {
+				if gray&(1<<uint(k)) != 0 {
+					x ^= dirs[d][k]
+				}
+			}
+			var u = (float64(x) + rng.Float64()) / (1 << sobolBits)
+			points[i][d] = lower[d] + u*(upper[d]-lower[d])
+		}
+	}
+	return
+}
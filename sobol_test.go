@@ -0,0 +1,77 @@
+package eaopt
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestIsPrimitive(t *testing.T) {
+	// Number of primitive polynomials of each degree over GF(2)
+	var expected = map[uint]int{1: 1, 2: 1, 3: 2, 4: 2, 5: 6, 6: 6, 7: 18, 8: 16}
+	for d, n := range expected {
+		var count int
+		for p := uint64(1)<<d | 1; p < 1<<(d+1); p += 2 {
+			if isPrimitive(p, d) {
+				count++
+			}
+		}
+		if count != n {
+			t.Errorf("Expected %d primitive polynomials of degree %d, got %d", n, d, count)
+		}
+	}
+	// x^4 + x^3 + x^2 + x + 1 is irreducible but not primitive
+	if isPrimitive(31, 4) {
+		t.Error("31 shouldn't be primitive")
+	}
+}
+
+func TestSobolDirections(t *testing.T) {
+	var dirs = sobolDirections(50)
+	if len(dirs) != 50 {
+		t.Fatalf("Expected 50 dimensions, got %d", len(dirs))
+	}
+	for d, v := range dirs {
+		// The k-th direction number is an odd integer lower than 2^(k+1)
+		// divided by 2^(k+1)
+		for k, vk := range v {
+			if bits.TrailingZeros32(vk) != sobolBits-1-k {
+				t.Errorf("Unexpected direction number %d of dimension %d: %b", k, d, vk)
+			}
+		}
+	}
+}
+
+func TestInitSobol(t *testing.T) {
+	var (
+		rng          = newRand()
+		lower, upper = []float64{0, 0, -1, 10}, []float64{1, 1, 1, 20}
+		points       = InitSobol(16, lower, upper, rng)
+	)
+	// The first two dimensions form a (0, 4, 2)-net: each of the 16 squares
+	// of a 4 by 4 grid contains exactly one point, and each dimension taken
+	// alone has one point in each of 16 intervals
+	var (
+		squares = make(map[[2]int]bool)
+		strata  = make([]map[int]bool, len(lower))
+	)
+	for d := range strata {
+		strata[d] = make(map[int]bool)
+	}
+	for _, p := range points {
+		squares[[2]int{int(p[0] * 4), int(p[1] * 4)}] = true
+		for d, x := range p {
+			if x < lower[d] || x >= upper[d] {
+				t.Errorf("Out of bound value %f", x)
+			}
+			strata[d][int((x-lower[d])/(upper[d]-lower[d])*16)] = true
+		}
+	}
+	if len(squares) != 16 {
+		t.Errorf("Expected 16 squares to be filled, got %d", len(squares))
+	}
+	for d, s := range strata {
+		if len(s) != 16 {
+			t.Errorf("Expected 16 strata in dimension %d, got %d", d, len(s))
+		}
+	}
+}
@@ -3,7 +3,9 @@ package eaopt
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 )
 
 // A Speciator partitions a population into n smaller subpopulations. Each
@@ -138,3 +140,95 @@ func (spec SpecFitnessInterval) Validate() error {
 	}
 	return nil
 }
+
+// SpecDistance speciates a population by phenotype. The Individuals are
+// considered from the best to the worst, each Individual that doesn't belong
+// to a species yet founds a new one which gathers the remaining Individuals
+// within Radius of it according to Metric. Species with less than
+// MinPerSpecies Individuals are then dissolved and their members join the
+// species of the closest founder. The neighbours of each founder are found
+// with a vantage-point tree, which avoids computing the distances between
+// every pair of Individuals in large populations; Metric therefore has to
+// satisfy the triangle inequality, as the euclidean distance between float
+// vectors does.
+type SpecDistance struct {
+	Metric        Metric
+	Radius        float64
+	MinPerSpecies uint
+}
+
+// Apply SpecDistance.
+func (spec SpecDistance) Apply(indis Individuals, rng *rand.Rand) ([]Individuals, error) {
+	if len(indis) == 0 {
+		return nil, errors.New("SpecDistance: have 0 individuals")
+	}
+	var (
+		tree     = newVPTree(indis, spec.Metric, rng)
+		order    = make([]int, len(indis))
+		assigned = make([]bool, len(indis))
+		founders []int
+		species  []Individuals
+	)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return indis[order[a]].Fitness < indis[order[b]].Fitness
+	})
+	for _, i := range order {
+		if assigned[i] {
+			continue
+		}
+		assigned[i] = true
+		var specie = Individuals{indis[i]}
+		tree.within(indis[i], spec.Radius, func(j int) {
+			if !assigned[j] {
+				assigned[j] = true
+				specie = append(specie, indis[j])
+			}
+		})
+		founders = append(founders, i)
+		species = append(species, specie)
+	}
+	// Keep the species that are large enough
+	var (
+		kept         []Individuals
+		keptFounders []int
+		dissolved    Individuals
+	)
+	for s, specie := range species {
+		if uint(len(specie)) >= spec.MinPerSpecies {
+			kept = append(kept, specie)
+			keptFounders = append(keptFounders, founders[s])
+		} else {
+			dissolved = append(dissolved, specie...)
+		}
+	}
+	if len(kept) == 0 {
+		return []Individuals{indis}, nil
+	}
+	for _, indi := range dissolved {
+		var (
+			closest int
+			minDist = math.Inf(1)
+		)
+		for s, f := range keptFounders {
+			if d := spec.Metric(indi, indis[f]); d < minDist {
+				closest, minDist = s, d
+			}
+		}
+		kept[closest] = append(kept[closest], indi)
+	}
+	return kept, nil
+}
+
+// Validate SpecDistance fields.
+func (spec SpecDistance) Validate() error {
+	if spec.Metric == nil {
+		return errors.New("metric field has to be provided")
+	}
+	if spec.Radius <= 0 {
+		return errors.New("radius should be higher than 0")
+	}
+	return nil
+}
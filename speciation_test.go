@@ -3,6 +3,8 @@ package eaopt
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -142,3 +144,142 @@ func TestSpecFitnessIntervalValidate(t *testing.T) {
 		t.Error("Validation should have raised error")
 	}
 }
+
+// clearing is a quadratic reference implementation of SpecDistance without
+// dissolution of small species.
+func clearing(indis Individuals, metric Metric, radius float64) []Individuals {
+	var (
+		sorted   = append(Individuals{}, indis...)
+		assigned = make(map[string]bool)
+		species  []Individuals
+	)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Fitness < sorted[j].Fitness })
+	for _, founder := range sorted {
+		if assigned[founder.ID] {
+			continue
+		}
+		var specie Individuals
+		for _, indi := range sorted {
+			if !assigned[indi.ID] && metric(founder, indi) <= radius {
+				assigned[indi.ID] = true
+				specie = append(specie, indi)
+			}
+		}
+		species = append(species, specie)
+	}
+	return species
+}
+
+func TestSpecDistanceApply(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newIndividuals(300, false, NewVector, rng)
+	)
+	indis.Evaluate(false)
+	for _, radius := range []float64{1, 10, 30, 1000} {
+		var (
+			spec         = SpecDistance{Metric: l1Distance, Radius: radius}
+			species, err = spec.Apply(indis, rng)
+			expected     = clearing(indis, l1Distance, radius)
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(species) != len(expected) {
+			t.Fatalf("Radius %f: expected %d species, got %d", radius, len(expected), len(species))
+		}
+		for i := range species {
+			var ids, expectedIDs []string
+			for _, indi := range species[i] {
+				ids = append(ids, indi.ID)
+			}
+			for _, indi := range expected[i] {
+				expectedIDs = append(expectedIDs, indi.ID)
+			}
+			sort.Strings(ids[1:])
+			sort.Strings(expectedIDs[1:])
+			if !reflect.DeepEqual(ids, expectedIDs) {
+				t.Errorf("Radius %f: species %d differs from the reference", radius, i)
+			}
+		}
+	}
+}
+
+func TestSpecDistanceMinPerSpecies(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = Individuals{
+			NewIndividual(Vector{0, 0}, rng),
+			NewIndividual(Vector{0, 1}, rng),
+			NewIndividual(Vector{1, 0}, rng),
+			NewIndividual(Vector{10, 10}, rng),
+			NewIndividual(Vector{10, 11}, rng),
+			NewIndividual(Vector{10, 12}, rng),
+			NewIndividual(Vector{50, 50}, rng),
+		}
+	)
+	indis.Evaluate(false)
+	var species, err = SpecDistance{Metric: l1Distance, Radius: 2, MinPerSpecies: 2}.Apply(indis, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(species) != 2 || len(species[0]) != 3 || len(species[1]) != 4 {
+		t.Fatalf("Expected species of 3 and 4 Individuals, got %v", species)
+	}
+	// The isolated Individual joins the closest founder
+	if species[1][3].Genome.(Vector)[0] != 50 {
+		t.Errorf("Expected the isolated Individual to join the second species, got %v", species)
+	}
+	// Everything is merged if no species is large enough
+	species, _ = SpecDistance{Metric: l1Distance, Radius: 2, MinPerSpecies: 5}.Apply(indis, rng)
+	if len(species) != 1 || len(species[0]) != len(indis) {
+		t.Errorf("Expected a single species, got %v", species)
+	}
+	if _, err = (SpecDistance{Metric: l1Distance, Radius: 2}).Apply(nil, rng); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestSpecDistanceMetricCalls(t *testing.T) {
+	var (
+		rng   = newRand()
+		n     = 2000
+		indis = make(Individuals, n)
+		calls int
+	)
+	// Tight clusters
+	for i := range indis {
+		var c = float64(i % 10 * 100)
+		indis[i] = NewIndividual(Vector{c + rng.Float64(), c + rng.Float64()}, rng)
+	}
+	indis.Evaluate(false)
+	var metric = func(a, b Individual) float64 {
+		calls++
+		return l1Distance(a, b)
+	}
+	var species, err = SpecDistance{Metric: metric, Radius: 5}.Apply(indis, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(species) != 10 {
+		t.Errorf("Expected 10 species, got %d", len(species))
+	}
+	if calls > n*n/10 {
+		t.Errorf("Expected less than %d calls to Metric, got %d", n*n/10, calls)
+	}
+}
+
+func TestSpecDistanceValidate(t *testing.T) {
+	var spec = SpecDistance{Metric: l1Distance, Radius: 1}
+	if err := spec.Validate(); err != nil {
+		t.Error("Validation should not have raised error")
+	}
+	spec.Radius = 0
+	if err := spec.Validate(); err == nil {
+		t.Error("Validation should raise error")
+	}
+	spec = SpecDistance{Radius: 1}
+	if err := spec.Validate(); err == nil {
+		t.Error("Validation should raise error")
+	}
+}
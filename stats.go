@@ -0,0 +1,305 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// A NonFinitePolicy determines how the fitness statistics treat NaN and
+// infinite fitnesses. NaNs typically come from buggy objective functions and
+// infinite fitnesses from evaluations that timed out.
+type NonFinitePolicy uint8
+
+const (
+	// SkipNaN ignores NaN fitnesses, which would otherwise make every
+	// statistic NaN. Infinite fitnesses are kept, hence the average is
+	// infinite and the standard deviation is NaN if there are some.
+	SkipNaN NonFinitePolicy = iota
+	// SkipNonFinite ignores NaN and infinite fitnesses.
+	SkipNonFinite
+	// KeepNonFinite keeps every fitness, a single NaN makes every statistic
+	// NaN.
+	KeepNonFinite
+)
+
+// FitnessStats summarizes a set of fitnesses. N is the number of fitnesses the
+// statistics were computed on, NaNs and Infs count the NaN and infinite
+// fitnesses whether they were skipped or not. The statistics are NaN if N is
+// 0. Std is the population standard deviation and the quartiles are obtained
+// by linear interpolation between the closest ranks.
+type FitnessStats struct {
+	N, NaNs, Infs      int
+	Min, Max, Avg, Std float64
+	Q1, Median, Q3     float64
+}
+
+// NewFitnessStats computes the statistics of fitnesses according to policy.
+// fitnesses isn't modified.
+func NewFitnessStats(fitnesses []float64, policy NonFinitePolicy) FitnessStats {
+	var (
+		acc  = newFitnessAccumulator(policy)
+		kept = make([]float64, 0, len(fitnesses))
+	)
+	for _, f := range fitnesses {
+		if acc.add(f) {
+			kept = append(kept, f)
+		}
+	}
+	var stats = acc.stats()
+	stats.Q1, stats.Median, stats.Q3 = math.NaN(), math.NaN(), math.NaN()
+	if stats.N > 0 && !math.IsNaN(stats.Min) {
+		sort.Float64s(kept)
+		stats.Q1, stats.Median, stats.Q3 = quantile(kept, 0.25), quantile(kept, 0.5), quantile(kept, 0.75)
+	}
+	return stats
+}
+
+// A fitnessAccumulator computes the statistics of fitnesses in a single pass
+// with Welford's algorithm, which unlike the textbook formula doesn't suffer
+// from catastrophic cancellation when the fitnesses are large compared to
+// their spread. Infinite fitnesses are accounted for separately so that they
+// don't turn the running mean into a NaN.
+type fitnessAccumulator struct {
+	policy         NonFinitePolicy
+	summary        FitnessStats
+	n              int // Number of finite fitnesses
+	mean, m2       float64
+	posInf, negInf int // Number of kept infinite fitnesses
+	keptNaN        bool
+}
+
+// newFitnessAccumulator returns an empty fitnessAccumulator.
+func newFitnessAccumulator(policy NonFinitePolicy) *fitnessAccumulator {
+	return &fitnessAccumulator{
+		policy:  policy,
+		summary: FitnessStats{Min: math.Inf(1), Max: math.Inf(-1)},
+	}
+}
+
+// add accounts for f and returns true if f is kept according to the policy.
+func (acc *fitnessAccumulator) add(f float64) bool {
+	switch {
+	case math.IsNaN(f):
+		acc.summary.NaNs++
+		if acc.policy != KeepNonFinite {
+			return false
+		}
+		acc.keptNaN = true
+	case math.IsInf(f, 0):
+		acc.summary.Infs++
+		if acc.policy == SkipNonFinite {
+			return false
+		}
+		if f > 0 {
+			acc.posInf++
+		} else {
+			acc.negInf++
+		}
+	default:
+		acc.n++
+		var delta = f - acc.mean
+		acc.mean += delta / float64(acc.n)
+		acc.m2 += delta * (f - acc.mean)
+	}
+	acc.summary.N++
+	acc.summary.Min = math.Min(acc.summary.Min, f)
+	acc.summary.Max = math.Max(acc.summary.Max, f)
+	return true
+}
+
+// stats returns the statistics of the fitnesses that were kept, except for the
+// quartiles.
+func (acc *fitnessAccumulator) stats() FitnessStats {
+	var stats = acc.summary
+	switch {
+	case stats.N == 0 || acc.keptNaN:
+		stats.Min, stats.Max, stats.Avg, stats.Std = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+	case acc.posInf > 0 && acc.negInf > 0:
+		stats.Avg, stats.Std = math.NaN(), math.NaN()
+	case acc.posInf > 0:
+		stats.Avg, stats.Std = math.Inf(1), math.NaN()
+	case acc.negInf > 0:
+		stats.Avg, stats.Std = math.Inf(-1), math.NaN()
+	default:
+		stats.Avg, stats.Std = acc.mean, math.Sqrt(acc.m2/float64(acc.n))
+	}
+	return stats
+}
+
+// PopStats summarizes the fitnesses of a Population's Individuals and the
+// time their evaluations took.
+type PopStats struct {
+	ID          string        `json:"pop_id"`
+	Min         float64       `json:"min"`
+	Max         float64       `json:"max"`
+	Avg         float64       `json:"avg"`
+	Std         float64       `json:"std"`
+	Q1          float64       `json:"q1"`
+	Median      float64       `json:"median"`
+	Q3          float64       `json:"q3"`
+	NaNs        int           `json:"nans,omitempty"`
+	Infs        int           `json:"infs,omitempty"`
+	EvalTimeAvg time.Duration `json:"eval_time_avg"`
+	EvalTimeMax time.Duration `json:"eval_time_max"`
+}
+
+// NewPopStats computes the statistics of a Population, NaN and infinite
+// fitnesses are treated according to the Population's StatsPolicy.
+func NewPopStats(pop Population) PopStats {
+	var fs = pop.Individuals.FitStats(pop.StatsPolicy)
+	return PopStats{
+		ID:     pop.ID,
+		Min:    fs.Min,
+		Max:    fs.Max,
+		Avg:    fs.Avg,
+		Std:    fs.Std,
+		Q1:     fs.Q1,
+		Median: fs.Median,
+		Q3:     fs.Q3,
+		NaNs:   fs.NaNs,
+		Infs:   fs.Infs,
+
+		EvalTimeAvg: pop.Individuals.EvalTimeAvg(),
+		EvalTimeMax: pop.Individuals.EvalTimeMax(),
+	}
+}
+
+// GenerationStats summarizes the state of a GA at the end of a generation.
+// BestGenome contains the JSON encoding of the best Genome ever encountered,
+// it is empty if the Genome can't be marshaled to JSON. EvalTime is the total
+// time spent evaluating Genomes, see GA.EvalTime. Clusters is only set on the
+// generations at which the GA's Individuals are clustered, see
+// GAConfig.Clustering, and Sensitivity on the generations at which the best
+// Genome is analyzed, see GAConfig.SensitivityAnalysis.
+type GenerationStats struct {
+	Generation  uint               `json:"generation"`
+	Age         time.Duration      `json:"age"`
+	EvalTime    time.Duration      `json:"eval_time"`
+	Best        float64            `json:"best"`
+	BestGenome  json.RawMessage    `json:"best_genome,omitempty"`
+	Populations []PopStats         `json:"populations"`
+	Clusters    *ClusterReport     `json:"clusters,omitempty"`
+	Sensitivity *SensitivityReport `json:"sensitivity,omitempty"`
+}
+
+// Stats returns the statistics of the GA's current generation.
+func (ga *GA) Stats() GenerationStats {
+	var stats = GenerationStats{
+		Generation:  ga.Generations,
+		Age:         ga.Age,
+		EvalTime:    ga.EvalTime(),
+		Populations: make([]PopStats, len(ga.Populations)),
+	}
+	for i, pop := range ga.Populations {
+		stats.Populations[i] = NewPopStats(pop)
+	}
+	if len(ga.HallOfFame) > 0 {
+		stats.Best = ga.HallOfFame[0].Fitness
+		if ga.HallOfFame[0].Genome != nil {
+			if b, err := json.Marshal(ga.HallOfFame[0].Genome); err == nil {
+				stats.BestGenome = b
+			}
+		}
+	}
+	if ga.Clustering != nil && ga.Clustering.due(ga.Generations) {
+		if report, err := ga.Clusters(); err == nil {
+			stats.Clusters = &report
+		}
+	}
+	stats.Sensitivity = ga.sensitivity()
+	return stats
+}
+
+// jsonFloat64 returns nil for values that can't be represented in JSON, such
+// as the infinite fitness of Individuals that haven't been evaluated.
+func jsonFloat64(f float64) *float64 {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+	return &f
+}
+
+// MarshalJSON encodes PopStats, non-finite values are encoded as null.
+func (ps PopStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID          string        `json:"pop_id"`
+		Min         *float64      `json:"min"`
+		Max         *float64      `json:"max"`
+		Avg         *float64      `json:"avg"`
+		Std         *float64      `json:"std"`
+		Q1          *float64      `json:"q1"`
+		Median      *float64      `json:"median"`
+		Q3          *float64      `json:"q3"`
+		NaNs        int           `json:"nans,omitempty"`
+		Infs        int           `json:"infs,omitempty"`
+		EvalTimeAvg time.Duration `json:"eval_time_avg"`
+		EvalTimeMax time.Duration `json:"eval_time_max"`
+	}{ps.ID, jsonFloat64(ps.Min), jsonFloat64(ps.Max), jsonFloat64(ps.Avg), jsonFloat64(ps.Std),
+		jsonFloat64(ps.Q1), jsonFloat64(ps.Median), jsonFloat64(ps.Q3), ps.NaNs, ps.Infs,
+		ps.EvalTimeAvg, ps.EvalTimeMax})
+}
+
+// MarshalJSON encodes GenerationStats, a non-finite Best is encoded as null.
+func (gs GenerationStats) MarshalJSON() ([]byte, error) {
+	type alias GenerationStats
+	return json.Marshal(struct {
+		alias
+		Best *float64 `json:"best"`
+	}{alias(gs), jsonFloat64(gs.Best)})
+}
+
+// LogOptions thin out the population statistics recorded by a GA's Logger and
+// SLogger, which otherwise record every Population at every generation.
+// Statistics are recorded after the initialization, every Every generations
+// and after the last generation; 0 is the same as 1. Populations contains the
+// indexes of the Populations to record, all of them if it is empty. If
+// Aggregate is true then a single record, whose pop_id is "all", summarizes
+// the Individuals of the recorded Populations combined. If Quartiles is true
+// then the Logger's lines also contain the quartiles of the fitnesses, which
+// SLogger always records.
+type LogOptions struct {
+	Every       uint   `json:"every"`
+	Populations []uint `json:"populations,omitempty"`
+	Aggregate   bool   `json:"aggregate"`
+	Quartiles   bool   `json:"quartiles"`
+}
+
+// Validate LogOptions fields against the GA's number of Populations.
+func (lo LogOptions) Validate(nPops uint) error {
+	for _, i := range lo.Populations {
+		if i >= nPops {
+			return fmt.Errorf("Populations contains %d but there are only %d Populations", i, nPops)
+		}
+	}
+	return nil
+}
+
+// due returns true if statistics should be recorded at the given generation.
+func (lo LogOptions) due(generation, nGenerations uint) bool {
+	return lo.Every <= 1 || generation%lo.Every == 0 || generation == nGenerations
+}
+
+// selected returns the Populations to record.
+func (lo LogOptions) selected(pops Populations) Populations {
+	if len(lo.Populations) == 0 {
+		return pops
+	}
+	var selected = make(Populations, len(lo.Populations))
+	for i, j := range lo.Populations {
+		selected[i] = pops[j]
+	}
+	return selected
+}
+
+// aggregatePopulations returns a Population holding the Individuals of pops,
+// whose statistics summarize pops as a whole.
+func aggregatePopulations(pops Populations, generations uint, age time.Duration, policy NonFinitePolicy) Population {
+	var all = Population{ID: "all", Generations: generations, Age: age, StatsPolicy: policy}
+	for _, pop := range pops {
+		all.Individuals = append(all.Individuals, pop.Individuals...)
+	}
+	return all
+}
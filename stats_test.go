@@ -0,0 +1,157 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGAStats(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	var ga, _ = conf.NewGA()
+	if err := ga.init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var stats = ga.Stats()
+	if len(stats.Populations) != 2 {
+		t.Errorf("Expected 2, got %d", len(stats.Populations))
+	}
+	if stats.Best != ga.HallOfFame[0].Fitness {
+		t.Errorf("Expected %f, got %f", ga.HallOfFame[0].Fitness, stats.Best)
+	}
+	if len(stats.BestGenome) == 0 {
+		t.Error("Expected the best genome to be encoded")
+	}
+}
+
+func TestEvalTimeStats(t *testing.T) {
+	var indis = Individuals{{EvalDuration: time.Second}, {EvalDuration: 3 * time.Second}}
+	if d := indis.EvalTimeAvg(); d != 2*time.Second {
+		t.Errorf("Expected 2s, got %v", d)
+	}
+	if d := indis.EvalTimeMax(); d != 3*time.Second {
+		t.Errorf("Expected 3s, got %v", d)
+	}
+	if d := (Individuals{}).EvalTimeAvg(); d != 0 {
+		t.Errorf("Expected 0, got %v", d)
+	}
+	// The GA accounts for the time spent evaluating
+	var conf = NewDefaultGAConfig()
+	conf.NGenerations = 3
+	conf.EvalTimeout = time.Second
+	var ga, _ = conf.NewGA()
+	if err := ga.Minimize(func(rng *rand.Rand) Genome {
+		return slowVector{Vector: NewVector(rng).(Vector), Delay: time.Millisecond}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var stats = ga.Stats()
+	if stats.EvalTime < time.Duration(ga.Evaluations())*time.Millisecond {
+		t.Errorf("Expected at least %dms, got %v", ga.Evaluations(), stats.EvalTime)
+	}
+	if stats.Populations[0].EvalTimeMax < time.Millisecond || stats.Populations[0].EvalTimeAvg > stats.Populations[0].EvalTimeMax {
+		t.Errorf("Unexpected evaluation times %v", stats.Populations[0])
+	}
+}
+
+func TestStatsJSONNonFinite(t *testing.T) {
+	var stats = GenerationStats{
+		Best:        math.Inf(1),
+		Populations: []PopStats{{ID: "a", Min: math.Inf(1), Max: math.NaN(), Avg: 1}},
+	}
+	var b, err = json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for _, s := range []string{`"best":null`, `"min":null`, `"max":null`, `"avg":1`} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("Expected %s in %s", s, b)
+		}
+	}
+}
+
+func TestNewFitnessStats(t *testing.T) {
+	var (
+		nan = math.NaN()
+		inf = math.Inf(1)
+	)
+	var testCases = []struct {
+		fitnesses []float64
+		policy    NonFinitePolicy
+		expected  FitnessStats
+	}{
+		{
+			[]float64{4, 1, 3, 2},
+			SkipNaN,
+			FitnessStats{N: 4, Min: 1, Max: 4, Avg: 2.5, Std: math.Sqrt(1.25), Q1: 1.75, Median: 2.5, Q3: 3.25},
+		},
+		{
+			[]float64{1, nan, 3},
+			SkipNaN,
+			FitnessStats{N: 2, NaNs: 1, Min: 1, Max: 3, Avg: 2, Std: 1, Q1: 1.5, Median: 2, Q3: 2.5},
+		},
+		{
+			[]float64{1, nan, 3, inf},
+			SkipNonFinite,
+			FitnessStats{N: 2, NaNs: 1, Infs: 1, Min: 1, Max: 3, Avg: 2, Std: 1, Q1: 1.5, Median: 2, Q3: 2.5},
+		},
+		{
+			[]float64{1, 2, 3, inf},
+			SkipNaN,
+			FitnessStats{N: 4, Infs: 1, Min: 1, Max: inf, Avg: inf, Std: nan, Q1: 1.75, Median: 2.5, Q3: inf},
+		},
+		{
+			[]float64{1, nan},
+			KeepNonFinite,
+			FitnessStats{N: 2, NaNs: 1, Min: nan, Max: nan, Avg: nan, Std: nan, Q1: nan, Median: nan, Q3: nan},
+		},
+		{
+			[]float64{nan},
+			SkipNaN,
+			FitnessStats{NaNs: 1, Min: nan, Max: nan, Avg: nan, Std: nan, Q1: nan, Median: nan, Q3: nan},
+		},
+	}
+	var equal = func(a, b float64) bool {
+		return (math.IsNaN(a) && math.IsNaN(b)) || a == b || math.Abs(a-b) < 1e-12
+	}
+	for i, tc := range testCases {
+		var (
+			fs = NewFitnessStats(tc.fitnesses, tc.policy)
+			ex = tc.expected
+		)
+		if fs.N != ex.N || fs.NaNs != ex.NaNs || fs.Infs != ex.Infs ||
+			!equal(fs.Min, ex.Min) || !equal(fs.Max, ex.Max) || !equal(fs.Avg, ex.Avg) || !equal(fs.Std, ex.Std) ||
+			!equal(fs.Q1, ex.Q1) || !equal(fs.Median, ex.Median) || !equal(fs.Q3, ex.Q3) {
+			t.Errorf("TC %d: expected %+v, got %+v", i, ex, fs)
+		}
+	}
+}
+
+func TestFitnessStatsWelford(t *testing.T) {
+	// The naive formula loses all precision when the fitnesses are large
+	// compared to their spread
+	var fitnesses = []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}
+	var fs = NewFitnessStats(fitnesses, SkipNaN)
+	if math.Abs(fs.Std-math.Sqrt(22.5)) > 1e-6 {
+		t.Errorf("Expected %f, got %f", math.Sqrt(22.5), fs.Std)
+	}
+}
+
+func TestPopStatsNaN(t *testing.T) {
+	var pop = Population{ID: "a", Individuals: Individuals{{Fitness: 1}, {Fitness: math.NaN()}, {Fitness: 3}}}
+	var ps = NewPopStats(pop)
+	if ps.Avg != 2 || ps.NaNs != 1 {
+		t.Errorf("Expected an average of 2 and 1 NaN, got %+v", ps)
+	}
+	if s := pop.String(); !strings.HasSuffix(s, "nans=1") {
+		t.Errorf("Expected the NaN to be reported, got %s", s)
+	}
+	pop.StatsPolicy = KeepNonFinite
+	if ps = NewPopStats(pop); !math.IsNaN(ps.Avg) {
+		t.Errorf("Expected NaN, got %f", ps.Avg)
+	}
+}
@@ -0,0 +1,65 @@
+package eaopt
+
+import "errors"
+
+// Step evolves the GA for one generation, which lets callers interleave the
+// generations with other work, follow their own schedule or inspect the
+// intermediate states. The GA has to be initialized with Init beforehand; a
+// new GA then evolves as with Minimize if Step is called until Done returns
+// true and Finish is called. Step doesn't check Done, and AsyncMigration isn't supported.
+func (ga *GA) Step() error {
+	if len(ga.Populations) == 0 {
+		return errors.New("the GA has to be initialized with Init before calling Step")
+	}
+	if ga.AsyncMigration {
+		return errors.New("Step can't be used with AsyncMigration")
+	}
+	// The run goes on, hence it has to be finished again
+	ga.finished = false
+	return ga.evolve()
+}
+
+// Done returns true once the GA has been evolved for NGenerations generations
+// in total, or if EarlyStop, which Done calls, says so, or if the evaluation
+// budget has been spent, or if the Pareto front has converged according to
+// GAConfig.FrontStop. Unlike Run, which always evolves NGenerations more
+// generations, Done counts the generations since the Populations were
+// created, hence a GA restored with ImportState continues the run it was
+// exported from. Done doesn't finish the run, see Finish.
+func (ga *GA) Done() bool {
+	return ga.Generations >= ga.NGenerations || ga.done()
+}
+
+// StepN evolves the GA for at most n generations, stopping early once Done
+// returns true, and returns whether the run is over. Once it is, StepN
+// finishes it with Finish and returns the error of the polishing. It is meant
+// for environments that can't block for long, such as a browser running
+// WebAssembly.
+func (ga *GA) StepN(n uint) (bool, error) {
+	for i := uint(0); i < n; i++ {
+		if ga.Done() {
+			return true, ga.Finish()
+		}
+		if err := ga.Step(); err != nil {
+			return false, err
+		}
+	}
+	if ga.Done() {
+		return true, ga.Finish()
+	}
+	return false, nil
+}
+
+// applyPopulations calls f on each Population, one after the other if the GA
+// is SingleThreaded, else in parallel.
+func (ga *GA) applyPopulations(f func(pop *Population) error) error {
+	if !ga.SingleThreaded {
+		return ga.Populations.Apply(f)
+	}
+	for i := range ga.Populations {
+		if err := f(&ga.Populations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package eaopt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGAStep(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 5
+	conf.RNG = rand.New(rand.NewSource(42))
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Step(); err == nil {
+		t.Error("Expected an error before Init")
+	}
+	if err = ga.init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var steps int
+	for !ga.Done() {
+		var best = ga.HallOfFame[0].Fitness
+		if err = ga.Step(); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		steps++
+		if ga.Generations != uint(steps) {
+			t.Errorf("Expected %d generations, got %d", steps, ga.Generations)
+		}
+		if ga.HallOfFame[0].Fitness > best {
+			t.Errorf("The best fitness shouldn't increase")
+		}
+	}
+	if steps != 5 {
+		t.Errorf("Expected 5 steps, got %d", steps)
+	}
+	// Stepping is the same as minimizing
+	conf.RNG = rand.New(rand.NewSource(42))
+	var minimized, _ = conf.NewGA()
+	if err = minimized.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if minimized.HallOfFame[0].Fitness != ga.HallOfFame[0].Fitness {
+		t.Errorf("Expected %f, got %f", minimized.HallOfFame[0].Fitness, ga.HallOfFame[0].Fitness)
+	}
+	// Done takes EarlyStop into account
+	ga, _ = conf.NewGA()
+	ga.EarlyStop = func(ga *GA) bool { return ga.Generations == 2 }
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for !ga.Done() {
+		if err = ga.Step(); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+	}
+	if ga.Generations != 2 {
+		t.Errorf("Expected 2 generations, got %d", ga.Generations)
+	}
+	conf.AsyncMigration = true
+	ga, _ = conf.NewGA()
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Step(); err == nil {
+		t.Error("Expected an error with AsyncMigration")
+	}
+}
+
+func TestGAStepN(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 2
+	conf.NGenerations = 10
+	conf.SingleThreaded = true
+	conf.GenomeJSONUnmarshaler = VectorJSONUnmarshaler
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.Init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var done bool
+	if done, err = ga.StepN(4); err != nil || done {
+		t.Fatalf("Expected false and nil, got %t and %v", done, err)
+	}
+	if ga.Generations != 4 {
+		t.Errorf("Expected 4 generations, got %d", ga.Generations)
+	}
+	// A restored GA continues the same run
+	var state string
+	if state, err = ga.ExportState(CheckpointOptions{}); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var resumed, _ = conf.NewGA()
+	if err = resumed.ImportState(state, CheckpointOptions{}); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = resumed.Init(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if done, err = resumed.StepN(100); err != nil || !done {
+		t.Fatalf("Expected true and nil, got %t and %v", done, err)
+	}
+	if resumed.Generations != 10 {
+		t.Errorf("Expected 10 generations, got %d", resumed.Generations)
+	}
+	if done, _ = resumed.StepN(1); !done || resumed.Generations != 10 {
+		t.Error("Expected the run to be over")
+	}
+}
+
+func TestGASingleThreaded(t *testing.T) {
+	// Single threaded runs are reproducible with several Populations
+	var run = func() float64 {
+		var conf = NewDefaultGAConfig()
+		conf.NPops = 3
+		conf.SingleThreaded = true
+		conf.Migrator = MigRing{NMigrants: 2}
+		conf.RNG = rand.New(rand.NewSource(42))
+		var ga, err = conf.NewGA()
+		if err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if err = ga.Minimize(NewVector); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		return ga.HallOfFame[0].Fitness
+	}
+	if a, b := run(), run(); a != b {
+		t.Errorf("Expected %f, got %f", a, b)
+	}
+}
+
+func TestGAStepPolish(t *testing.T) {
+	var (
+		p    = newTestFloatProblem()
+		conf = NewDefaultGAConfig()
+	)
+	conf.NGenerations = 3
+	conf.HofSize = 2
+	conf.SingleThreaded = true
+	conf.Polish = &PolishOptions{Budget: 50, NMembers: 1}
+	conf.RNG = rand.New(rand.NewSource(42))
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if err = ga.init(p.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for !ga.Done() {
+		if err = ga.Step(); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+	}
+	// Done doesn't finish the run
+	if !ga.Done() || ga.Polished() != nil {
+		t.Fatal("The hall of fame was polished by Done")
+	}
+	if err = ga.Finish(); err != nil || len(ga.Polished()) != 1 {
+		t.Fatalf("Expected 1 polished member and nil, got %d and %v", len(ga.Polished()), err)
+	}
+	// The run is only finished once
+	var evaluations = ga.Evaluations()
+	if err = ga.Finish(); err != nil || ga.Evaluations() != evaluations {
+		t.Error("The hall of fame was polished again")
+	}
+	// Stepping is the same as minimizing
+	conf.RNG = rand.New(rand.NewSource(42))
+	var minimized, _ = conf.NewGA()
+	if err = minimized.Minimize(p.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var a, b = minimized.Polished()[0], ga.Polished()[0]
+	if a.Before != b.Before || a.Individual.Fitness != b.Individual.Fitness {
+		t.Errorf("Expected %+v, got %+v", a, b)
+	}
+	// StepN polishes too
+	conf.RNG = rand.New(rand.NewSource(42))
+	ga, _ = conf.NewGA()
+	if err = ga.init(p.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if done, err := ga.StepN(10); !done || err != nil {
+		t.Fatalf("Expected true and nil, got %t and %v", done, err)
+	}
+	if len(ga.Polished()) != 1 || ga.Polished()[0].Individual.Fitness != a.Individual.Fitness {
+		t.Errorf("Expected %+v, got %+v", a, ga.Polished())
+	}
+}
@@ -0,0 +1,304 @@
+package eaopt
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// A SubprocessEvaluator evaluates inputs with an external program, which
+// makes it possible to plug objective functions written in any language (for
+// instance a Python or C++ simulator) into eaopt without cgo. The program is
+// started once and both processes then exchange newline delimited JSON
+// messages. Each request sent on the program's standard input contains a batch
+// of inputs:
+//
+//	{"id": 1, "inputs": [[1.5, 2.0], [0.3, -1.2]]}
+//
+// and the program has to write one response on its standard output for each
+// request, in the same order:
+//
+//	{"id": 1, "fitnesses": [4.2, null], "errors": ["", "simulation diverged"]}
+//
+// A null fitness is read as +Inf. The errors field is optional, a non-empty
+// string fails the corresponding input. A top-level "error" string fails the
+// whole batch. If the program exits or writes something that isn't a valid
+// response then every pending and future evaluation fails.
+//
+// Concurrent calls to Evaluate are gathered into batches of at most BatchSize
+// inputs, which amortizes the cost of the round trips when ParallelEval is
+// enabled. Gathering stops as soon as no other call is waiting, unless
+// BatchWait is strictly positive in which case the evaluator waits at most
+// BatchWait for the batch to fill up.
+//
+// Close gives the program CloseTimeout to answer the batch being evaluated and
+// to exit, after which it is killed, so that a hung program can't block Close.
+type SubprocessEvaluator struct {
+	BatchSize    uint
+	BatchWait    time.Duration
+	CloseTimeout time.Duration // 0 means the program is killed right away
+	cmd          *exec.Cmd
+	stdin        io.WriteCloser
+	stdout       *bufio.Reader
+	requests     chan subprocessRequest
+	done         chan struct{}
+	closeOnce    sync.Once
+	closeErr     error
+	mutex        sync.Mutex // Serializes the batches
+	lastID       uint64
+	errMutex     sync.Mutex
+	err          error
+}
+
+// A subprocessRequest is a single input waiting to be evaluated.
+type subprocessRequest struct {
+	input interface{}
+	reply chan subprocessResult
+}
+
+type subprocessResult struct {
+	fitness float64
+	err     error
+}
+
+// subprocessResponse is the message written by the program for each batch.
+type subprocessResponse struct {
+	ID        uint64     `json:"id"`
+	Fitnesses []*float64 `json:"fitnesses"`
+	Errors    []string   `json:"errors"`
+	Error     string     `json:"error"`
+}
+
+// errSubprocessClosed is returned when evaluating with a closed
+// SubprocessEvaluator.
+var errSubprocessClosed = errors.New("subprocess evaluator is closed")
+
+// NewSubprocessEvaluator starts cmd and returns a SubprocessEvaluator which
+// communicates with it. cmd's standard input and output are managed by the
+// evaluator, its standard error is forwarded to os.Stderr if it isn't set.
+// batchSize has to be at least 1.
+func NewSubprocessEvaluator(cmd *exec.Cmd, batchSize uint, batchWait time.Duration) (*SubprocessEvaluator, error) {
+	if batchSize == 0 {
+		return nil, errors.New("batchSize has to be at least 1")
+	}
+	if batchWait < 0 {
+		return nil, errors.New("batchWait should be positive")
+	}
+	var stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	var se = &SubprocessEvaluator{
+		BatchSize:    batchSize,
+		BatchWait:    batchWait,
+		CloseTimeout: 5 * time.Second,
+		cmd:          cmd,
+		stdin:        stdin,
+		stdout:       bufio.NewReader(stdout),
+		requests:     make(chan subprocessRequest),
+		done:         make(chan struct{}),
+	}
+	go se.dispatch()
+	return se, nil
+}
+
+// Evaluate sends input, which has to be encodable to JSON, to the program and
+// returns the fitness it computed. It is safe to call Evaluate concurrently.
+func (se *SubprocessEvaluator) Evaluate(input interface{}) (float64, error) {
+	var req = subprocessRequest{input: input, reply: make(chan subprocessResult, 1)}
+	select {
+	case se.requests <- req:
+	case <-se.done:
+		return math.Inf(1), se.failure()
+	}
+	var res = <-req.reply
+	return res.fitness, res.err
+}
+
+// EvaluateBatch evaluates several inputs in as few requests as possible. An
+// error is returned if any of the inputs couldn't be evaluated.
+func (se *SubprocessEvaluator) EvaluateBatch(inputs []interface{}) ([]float64, error) {
+	var (
+		fitnesses = make([]float64, len(inputs))
+		bs        = int(se.BatchSize)
+	)
+	for a := 0; a < len(inputs); a += bs {
+		var b = minInt(a+bs, len(inputs))
+		var results, err = se.send(inputs[a:b])
+		if err != nil {
+			return nil, err
+		}
+		for i, res := range results {
+			if res.err != nil {
+				return nil, res.err
+			}
+			fitnesses[a+i] = res.fitness
+		}
+	}
+	return fitnesses, nil
+}
+
+// Close waits for the batch being evaluated, if any, then stops the program by
+// closing its standard input and waits for it to exit. The program is killed
+// if it hasn't exited within CloseTimeout, in which case the batch being
+// evaluated fails. Evaluations that haven't been sent yet fail.
+func (se *SubprocessEvaluator) Close() error {
+	se.closeOnce.Do(func() {
+		se.errMutex.Lock()
+		if se.err == nil {
+			se.err = errSubprocessClosed
+		}
+		close(se.done)
+		se.errMutex.Unlock()
+		// Killing the program unblocks the batch being evaluated
+		var timer = time.AfterFunc(se.CloseTimeout, func() { se.cmd.Process.Kill() })
+		defer timer.Stop()
+		se.mutex.Lock()
+		se.stdin.Close()
+		se.mutex.Unlock()
+		se.closeErr = se.cmd.Wait()
+	})
+	return se.closeErr
+}
+
+// failure returns the error which stopped the evaluator.
+func (se *SubprocessEvaluator) failure() error {
+	se.errMutex.Lock()
+	defer se.errMutex.Unlock()
+	return se.err
+}
+
+// dispatch gathers the concurrent requests into batches and sends them to the
+// program one after the other.
+func (se *SubprocessEvaluator) dispatch() {
+	for {
+		var batch []subprocessRequest
+		select {
+		case req := <-se.requests:
+			batch = append(batch, req)
+		case <-se.done:
+			return
+		}
+		batch = se.gather(batch)
+		var inputs = make([]interface{}, len(batch))
+		for i, req := range batch {
+			inputs[i] = req.input
+		}
+		var results, err = se.send(inputs)
+		for i, req := range batch {
+			if err != nil {
+				req.reply <- subprocessResult{math.Inf(1), err}
+			} else {
+				req.reply <- results[i]
+			}
+		}
+	}
+}
+
+// gather adds waiting requests to a batch until it is full.
+func (se *SubprocessEvaluator) gather(batch []subprocessRequest) []subprocessRequest {
+	var timeout <-chan time.Time
+	if se.BatchWait > 0 {
+		var timer = time.NewTimer(se.BatchWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for uint(len(batch)) < se.BatchSize {
+		if timeout == nil {
+			select {
+			case req := <-se.requests:
+				batch = append(batch, req)
+			default:
+				return batch
+			}
+			continue
+		}
+		select {
+		case req := <-se.requests:
+			batch = append(batch, req)
+		case <-timeout:
+			return batch
+		case <-se.done:
+			return batch
+		}
+	}
+	return batch
+}
+
+// send writes a request and reads the matching response. Requests are
+// serialized so that responses can't be interleaved.
+func (se *SubprocessEvaluator) send(inputs []interface{}) ([]subprocessResult, error) {
+	se.mutex.Lock()
+	defer se.mutex.Unlock()
+	if err := se.failure(); err != nil {
+		return nil, err
+	}
+	var id = se.lastID + 1
+	var msg, err = json.Marshal(struct {
+		ID     uint64        `json:"id"`
+		Inputs []interface{} `json:"inputs"`
+	}{id, inputs})
+	if err != nil {
+		return nil, err
+	}
+	se.lastID = id
+	if _, err = se.stdin.Write(append(msg, '\n')); err != nil {
+		return nil, se.abort(fmt.Errorf("subprocess request: %w", err))
+	}
+	line, err := se.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, se.abort(fmt.Errorf("subprocess response: %w", err))
+	}
+	var resp subprocessResponse
+	if err = json.Unmarshal(line, &resp); err != nil {
+		return nil, se.abort(fmt.Errorf("subprocess response: %w", err))
+	}
+	if resp.ID != id {
+		return nil, se.abort(fmt.Errorf("subprocess response: expected id %d, got %d", id, resp.ID))
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("subprocess: %s", resp.Error)
+	}
+	if len(resp.Fitnesses) != len(inputs) || (resp.Errors != nil && len(resp.Errors) != len(inputs)) {
+		return nil, se.abort(fmt.Errorf("subprocess response: expected %d results, got %d", len(inputs), len(resp.Fitnesses)))
+	}
+	var results = make([]subprocessResult, len(inputs))
+	for i, f := range resp.Fitnesses {
+		results[i].fitness = math.Inf(1)
+		if f != nil {
+			results[i].fitness = *f
+		}
+		if resp.Errors != nil && resp.Errors[i] != "" {
+			results[i].err = fmt.Errorf("subprocess: %s", resp.Errors[i])
+		}
+	}
+	return results, nil
+}
+
+// abort records a protocol error and kills the program.
+func (se *SubprocessEvaluator) abort(err error) error {
+	se.errMutex.Lock()
+	defer se.errMutex.Unlock()
+	if se.err == nil {
+		se.err = err
+		se.cmd.Process.Kill()
+	}
+	return se.err
+}
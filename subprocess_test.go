@@ -0,0 +1,269 @@
+package eaopt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSubprocessHelper is not a real test, it is run by helperEvaluator in a
+// child process to play the role of an external objective function.
+func TestSubprocessHelper(t *testing.T) {
+	var mode = os.Getenv("EAOPT_SUBPROCESS_HELPER")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+	var scanner = bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     uint64      `json:"id"`
+			Inputs [][]float64 `json:"inputs"`
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+		var resp = map[string]interface{}{"id": req.ID}
+		switch mode {
+		case "crash":
+			os.Exit(1)
+		case "hang":
+			time.Sleep(time.Hour)
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+			resp["fitnesses"] = []float64{1}
+		case "garbage":
+			fmt.Println("not json")
+			continue
+		case "fail":
+			resp["error"] = "batch failed"
+		case "batch":
+			var fitnesses = make([]float64, len(req.Inputs))
+			for i := range fitnesses {
+				fitnesses[i] = float64(len(req.Inputs))
+			}
+			resp["fitnesses"] = fitnesses
+		default:
+			var (
+				fitnesses = make([]*float64, len(req.Inputs))
+				errs      = make([]string, len(req.Inputs))
+			)
+			for i, x := range req.Inputs {
+				switch {
+				case x[0] > 100:
+					errs[i] = "too large"
+				case x[0] < -100:
+				default:
+					var y = 0.0
+					for _, xi := range x {
+						y += xi * xi
+					}
+					fitnesses[i] = &y
+				}
+			}
+			resp["fitnesses"] = fitnesses
+			resp["errors"] = errs
+		}
+		json.NewEncoder(os.Stdout).Encode(resp)
+	}
+}
+
+func helperEvaluator(t *testing.T, mode string, batchSize uint, batchWait time.Duration) *SubprocessEvaluator {
+	var cmd = exec.Command(os.Args[0], "-test.run=^TestSubprocessHelper$")
+	cmd.Env = append(os.Environ(), "EAOPT_SUBPROCESS_HELPER="+mode)
+	var se, err = NewSubprocessEvaluator(cmd, batchSize, batchWait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { se.Close() })
+	return se
+}
+
+func TestSubprocessEvaluate(t *testing.T) {
+	var se = helperEvaluator(t, "sphere", 4, 0)
+	var testCases = []struct {
+		x   []float64
+		y   float64
+		err bool
+	}{
+		{[]float64{1, 2}, 5, false},
+		{[]float64{0, 0}, 0, false},
+		{[]float64{101, 0}, 0, true},
+		{[]float64{-101, 0}, math.Inf(1), false},
+	}
+	for i, tc := range testCases {
+		var y, err = se.Evaluate(tc.x)
+		if (err != nil) != tc.err {
+			t.Errorf("Error in test case number %d: %v", i, err)
+			continue
+		}
+		if err == nil && y != tc.y {
+			t.Errorf("Error in test case number %d: expected %f, got %f", i, tc.y, y)
+		}
+	}
+	// An error in the inputs doesn't stop the evaluator
+	if _, err := se.Evaluate([]float64{1}); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
+
+func TestSubprocessEvaluateBatch(t *testing.T) {
+	var se = helperEvaluator(t, "sphere", 2, 0)
+	var ys, err = se.EvaluateBatch([]interface{}{[]float64{1}, []float64{2}, []float64{3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, y := range []float64{1, 4, 9} {
+		if ys[i] != y {
+			t.Errorf("Expected %f, got %f", y, ys[i])
+		}
+	}
+	if _, err = se.EvaluateBatch([]interface{}{[]float64{1}, []float64{200}}); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestSubprocessBatching(t *testing.T) {
+	var (
+		se = helperEvaluator(t, "batch", 5, time.Second)
+		ys = make([]float64, 5)
+		wg sync.WaitGroup
+	)
+	for i := range ys {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ys[i], _ = se.Evaluate([]float64{0})
+		}(i)
+	}
+	wg.Wait()
+	for _, y := range ys {
+		if y != 5 {
+			t.Errorf("Expected a single batch of 5 inputs, got a batch of %f", y)
+		}
+	}
+}
+
+func TestSubprocessErrors(t *testing.T) {
+	for _, mode := range []string{"crash", "garbage"} {
+		var se = helperEvaluator(t, mode, 1, 0)
+		if _, err := se.Evaluate([]float64{0}); err == nil {
+			t.Errorf("Expected an error with mode %s", mode)
+		}
+		// The evaluator is unusable after a protocol error
+		if _, err := se.Evaluate([]float64{0}); err == nil {
+			t.Errorf("Expected an error with mode %s", mode)
+		}
+	}
+	// A failed batch doesn't stop the evaluator
+	var se = helperEvaluator(t, "fail", 1, 0)
+	for i := 0; i < 2; i++ {
+		if _, err := se.Evaluate([]float64{0}); err == nil || err.Error() != "subprocess: batch failed" {
+			t.Errorf("Expected a batch error, got %v", err)
+		}
+	}
+	// Inputs that can't be encoded don't stop the evaluator either
+	se = helperEvaluator(t, "sphere", 1, 0)
+	if _, err := se.Evaluate([]float64{math.NaN()}); err == nil {
+		t.Error("Expected an encoding error")
+	}
+	if y, err := se.Evaluate([]float64{2}); err != nil || y != 4 {
+		t.Errorf("Expected 4, got %f and %v", y, err)
+	}
+	se.Close()
+	if _, err := se.Evaluate([]float64{0}); err != errSubprocessClosed {
+		t.Errorf("Expected errSubprocessClosed, got %v", err)
+	}
+}
+
+func TestSubprocessClose(t *testing.T) {
+	var testCases = []struct {
+		mode    string
+		timeout time.Duration
+		err     bool
+	}{
+		// The batch being evaluated is answered before the program exits
+		{"slow", 5 * time.Second, false},
+		// A hung program is killed
+		{"hang", 100 * time.Millisecond, true},
+		{"slow", 0, true},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var (
+				se     = helperEvaluator(t, tc.mode, 1, 0)
+				result = make(chan error, 1)
+			)
+			se.CloseTimeout = tc.timeout
+			go func() {
+				var _, err = se.Evaluate([]float64{1})
+				result <- err
+			}()
+			// Let the batch be sent
+			time.Sleep(50 * time.Millisecond)
+			var closed = make(chan struct{})
+			go func() {
+				se.Close()
+				close(closed)
+			}()
+			select {
+			case <-closed:
+			case <-time.After(10 * time.Second):
+				t.Fatal("Close is blocked")
+			}
+			if err := <-result; (err != nil) != tc.err {
+				t.Errorf("Unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestNewSubprocessEvaluatorErrors(t *testing.T) {
+	if _, err := NewSubprocessEvaluator(exec.Command(os.Args[0]), 0, 0); err == nil {
+		t.Error("Expected an error with a batch size of 0")
+	}
+	if _, err := NewSubprocessEvaluator(exec.Command(os.Args[0]), 1, -time.Second); err == nil {
+		t.Error("Expected an error with a negative batch wait")
+	}
+	if _, err := NewSubprocessEvaluator(exec.Command("/does/not/exist"), 1, 0); err == nil {
+		t.Error("Expected an error with a missing program")
+	}
+}
+
+func TestSubprocessGA(t *testing.T) {
+	var (
+		se     = helperEvaluator(t, "sphere", 8, 0)
+		ga, _  = NewDefaultGAConfig().NewGA()
+		genome = func(rng *rand.Rand) Genome { return subprocessVector{NewVector(rng).(Vector), se} }
+	)
+	ga.NGenerations = 5
+	ga.ParallelEval = true
+	if err := ga.Minimize(genome); err != nil {
+		t.Fatal(err)
+	}
+	var v = ga.HallOfFame[0].Genome.(subprocessVector)
+	if ga.HallOfFame[0].Fitness != Sphere(v.Vector) {
+		t.Errorf("Expected %f, got %f", Sphere(v.Vector), ga.HallOfFame[0].Fitness)
+	}
+}
+
+// subprocessVector is a Vector evaluated by a SubprocessEvaluator.
+type subprocessVector struct {
+	Vector
+	se *SubprocessEvaluator
+}
+
+func (v subprocessVector) Evaluate() (float64, error) { return v.se.Evaluate(v.Vector) }
+
+func (v subprocessVector) Crossover(q Genome, rng *rand.Rand) {
+	v.Vector.Crossover(q.(subprocessVector).Vector, rng)
+}
+
+func (v subprocessVector) Clone() Genome {
+	return subprocessVector{v.Vector.Clone().(Vector), v.se}
+}
@@ -0,0 +1,186 @@
+package eaopt
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// A SubsetGenome selects a subset of the items of a Knapsack. Mask[i] is true
+// if item i is selected. SubsetGenomes are repaired after each mutation and
+// crossover so that they never exceed the Knapsack's capacity.
+type SubsetGenome struct {
+	Mask     []bool
+	Knapsack *Knapsack
+}
+
+// Weight returns the total weight of the selected items.
+func (g *SubsetGenome) Weight() (w float64) {
+	for i, in := range g.Mask {
+		if in {
+			w += g.Knapsack.Weights[i]
+		}
+	}
+	return
+}
+
+// Value returns the total value of the selected items.
+func (g *SubsetGenome) Value() (v float64) {
+	for i, in := range g.Mask {
+		if in {
+			v += g.Knapsack.Values[i]
+		}
+	}
+	return
+}
+
+// Items returns the indexes of the selected items.
+func (g *SubsetGenome) Items() []int {
+	var items []int
+	for i, in := range g.Mask {
+		if in {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+// Repair the SubsetGenome with a greedy heuristic. Selected items with the
+// lowest value to weight ratio are removed until the capacity is respected.
+// If the Knapsack's Fill field is true then the unselected items with the
+// highest ratio are then added as long as they fit.
+func (g *SubsetGenome) Repair() {
+	var (
+		ks     = g.Knapsack
+		weight = g.Weight()
+		order  = ks.byRatio()
+	)
+	// Remove the worst items first
+	for i := len(order) - 1; i >= 0 && weight > ks.Capacity; i-- {
+		if g.Mask[order[i]] {
+			g.Mask[order[i]] = false
+			weight -= ks.Weights[order[i]]
+		}
+	}
+	if !ks.Fill {
+		return
+	}
+	// Add the best items first
+	for _, i := range order {
+		if !g.Mask[i] && weight+ks.Weights[i] <= ks.Capacity {
+			g.Mask[i] = true
+			weight += ks.Weights[i]
+		}
+	}
+}
+
+// Evaluate a SubsetGenome by returning the opposite of its value, so that
+// minimizing the fitness maximizes the value.
+func (g *SubsetGenome) Evaluate() (float64, error) {
+	if g.Weight() > g.Knapsack.Capacity {
+		g.Repair()
+	}
+	return -g.Value(), nil
+}
+
+// Mutate a SubsetGenome by flipping each bit with probability MutRate and
+// repairing the result.
+func (g *SubsetGenome) Mutate(rng *rand.Rand) {
+	for i := range g.Mask {
+		if rng.Float64() < g.Knapsack.MutRate {
+			g.Mask[i] = !g.Mask[i]
+		}
+	}
+	g.Repair()
+}
+
+// Crossover applies uniform crossover to two SubsetGenomes and repairs both
+// offsprings.
+func (g *SubsetGenome) Crossover(q Genome, rng *rand.Rand) {
+	var h = q.(*SubsetGenome)
+	for i := range g.Mask {
+		if rng.Float64() < 0.5 {
+			g.Mask[i], h.Mask[i] = h.Mask[i], g.Mask[i]
+		}
+	}
+	g.Repair()
+	h.Repair()
+}
+
+// Clone returns a deep copy of a SubsetGenome.
+func (g SubsetGenome) Clone() Genome {
+	var mask = make([]bool, len(g.Mask))
+	copy(mask, g.Mask)
+	return &SubsetGenome{
+		Mask:     mask,
+		Knapsack: g.Knapsack,
+	}
+}
+
+// Knapsack describes a 0/1 knapsack problem, the most common subset selection
+// problem. Its NewGenome method can be handed to GA.Minimize.
+type Knapsack struct {
+	Weights  []float64
+	Values   []float64
+	Capacity float64
+	MutRate  float64 // Probability of flipping each bit during mutation
+	InitProb float64 // Probability of selecting each item during initialization
+	Fill     bool    // Whether to greedily fill the remaining capacity after repairing
+	order    []int
+	once     sync.Once
+}
+
+// byRatio returns the item indexes sorted by decreasing value to weight ratio.
+// The order is computed once and then cached.
+func (ks *Knapsack) byRatio() []int {
+	ks.once.Do(func() {
+		ks.order = newInts(uint(len(ks.Weights)))
+		sort.SliceStable(ks.order, func(i, j int) bool {
+			var a, b = ks.order[i], ks.order[j]
+			return ks.Values[a]*ks.Weights[b] > ks.Values[b]*ks.Weights[a]
+		})
+	})
+	return ks.order
+}
+
+// Validate Knapsack fields.
+func (ks *Knapsack) Validate() error {
+	if len(ks.Weights) == 0 {
+		return errors.New("at least one item has to be provided")
+	}
+	if len(ks.Weights) != len(ks.Values) {
+		return errors.New("Weights and Values should have the same length")
+	}
+	for _, w := range ks.Weights {
+		if w <= 0 {
+			return errors.New("weights should be strictly positive")
+		}
+	}
+	if ks.Capacity < 0 {
+		return errors.New("Capacity cannot be negative")
+	}
+	if ks.MutRate < 0 || ks.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	if ks.InitProb < 0 || ks.InitProb > 1 {
+		return errors.New("InitProb should be between 0 and 1")
+	}
+	return nil
+}
+
+// NewGenome returns a repaired SubsetGenome where each item has been selected
+// with probability InitProb. Low values of InitProb bias the initial
+// population towards small subsets, which require less repairing when the
+// capacity is tight.
+func (ks *Knapsack) NewGenome(rng *rand.Rand) Genome {
+	var g = &SubsetGenome{
+		Mask:     make([]bool, len(ks.Weights)),
+		Knapsack: ks,
+	}
+	for i := range g.Mask {
+		g.Mask[i] = rng.Float64() < ks.InitProb
+	}
+	g.Repair()
+	return g
+}
@@ -0,0 +1,110 @@
+package eaopt
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func newTestKnapsack() *Knapsack {
+	return &Knapsack{
+		Weights:  []float64{5, 4, 3, 2, 1},
+		Values:   []float64{10, 40, 30, 10, 1},
+		Capacity: 7,
+		MutRate:  0.2,
+		InitProb: 0.5,
+	}
+}
+
+func TestSubsetGenomeRepair(t *testing.T) {
+	var testCases = []struct {
+		mask []bool
+		fill bool
+		out  []bool
+	}{
+		// Already feasible
+		{[]bool{false, true, false, false, true}, false, []bool{false, true, false, false, true}},
+		// The worst ratios are removed first
+		{[]bool{true, true, true, true, true}, false, []bool{false, true, true, false, false}},
+		// Filling adds the best ratios that fit
+		{[]bool{false, false, false, false, false}, true, []bool{false, true, true, false, false}},
+		{[]bool{true, false, false, false, false}, true, []bool{true, false, false, true, false}},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var ks = newTestKnapsack()
+			ks.Fill = tc.fill
+			var g = &SubsetGenome{Mask: tc.mask, Knapsack: ks}
+			g.Repair()
+			if !reflect.DeepEqual(g.Mask, tc.out) {
+				t.Errorf("Expected %v, got %v", tc.out, g.Mask)
+			}
+		})
+	}
+}
+
+func TestSubsetGenomeFeasibility(t *testing.T) {
+	var (
+		rng = newRand()
+		ks  = newTestKnapsack()
+	)
+	for i := 0; i < 50; i++ {
+		var (
+			a = ks.NewGenome(rng).(*SubsetGenome)
+			b = ks.NewGenome(rng).(*SubsetGenome)
+		)
+		a.Mutate(rng)
+		a.Crossover(b, rng)
+		for _, g := range []*SubsetGenome{a, b} {
+			if g.Weight() > ks.Capacity {
+				t.Fatalf("Weight %f exceeds the capacity", g.Weight())
+			}
+		}
+	}
+}
+
+func TestSubsetGenomeEvaluate(t *testing.T) {
+	var g = &SubsetGenome{Mask: []bool{false, true, true, false, false}, Knapsack: newTestKnapsack()}
+	if f, _ := g.Evaluate(); f != -70 {
+		t.Errorf("Expected -70, got %f", f)
+	}
+	if items := g.Items(); !reflect.DeepEqual(items, []int{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", items)
+	}
+}
+
+func TestKnapsackValidate(t *testing.T) {
+	var testCases = []func(ks *Knapsack){
+		func(ks *Knapsack) { ks.Weights, ks.Values = nil, nil },
+		func(ks *Knapsack) { ks.Values = ks.Values[:2] },
+		func(ks *Knapsack) { ks.Weights = []float64{0, 1, 1, 1, 1} },
+		func(ks *Knapsack) { ks.Capacity = -1 },
+		func(ks *Knapsack) { ks.MutRate = 2 },
+		func(ks *Knapsack) { ks.InitProb = -1 },
+	}
+	if err := newTestKnapsack().Validate(); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var ks = newTestKnapsack()
+			tc(ks)
+			if err := ks.Validate(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestKnapsackMinimize(t *testing.T) {
+	var (
+		ks    = newTestKnapsack()
+		ga, _ = NewDefaultGAConfig().NewGA()
+	)
+	if err := ga.Minimize(ks.NewGenome); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if ga.HallOfFame[0].Fitness != -70 {
+		t.Errorf("Expected -70, got %f", ga.HallOfFame[0].Fitness)
+	}
+}
@@ -0,0 +1,151 @@
+package eaopt
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// ModTempering implements one level of parallel tempering, see
+// NewParallelTempering. Parents are chosen with SelBoltzmann at the given
+// Temperature and recombined with probability CrossRate, then each offspring
+// is mutated NMutations times in a row, 1 if it is 0, with probability
+// MutRate. Hotter levels thus select more loosely and usually mutate more
+// strongly.
+type ModTempering struct {
+	Temperature float64
+	MutRate     float64
+	CrossRate   float64
+	NMutations  uint
+}
+
+// Apply ModTempering.
+func (mod ModTempering) Apply(pop *Population) error {
+	var offsprings, err = generateOffsprings(
+		uint(len(pop.Individuals)),
+		2,
+		pop.Individuals,
+		SelBoltzmann{Temperature: mod.Temperature},
+		nil,
+		mod.CrossRate,
+		pop.RNG,
+	)
+	if err != nil {
+		return err
+	}
+	var nMutations = mod.NMutations
+	if nMutations == 0 {
+		nMutations = 1
+	}
+	for i := range offsprings {
+		if pop.RNG.Float64() < mod.MutRate {
+			for j := uint(0); j < nMutations; j++ {
+				offsprings[i].Mutate(pop.RNG)
+			}
+		}
+	}
+	copy(pop.Individuals, offsprings)
+	return nil
+}
+
+// Validate ModTempering fields.
+func (mod ModTempering) Validate() error {
+	if err := (SelBoltzmann{Temperature: mod.Temperature}).Validate(); err != nil {
+		return err
+	}
+	if mod.MutRate < 0 || mod.MutRate > 1 {
+		return errInvalidMutRate
+	}
+	if mod.CrossRate < 0 || mod.CrossRate > 1 {
+		return errInvalidCrossRate
+	}
+	return nil
+}
+
+// MigTempering exchanges Individuals between Populations evolved at increasing
+// Temperatures, the i-th Population being evolved at the i-th Temperature. For
+// each pair of adjacent Populations, NSwaps times, 1 if it is 0, a random
+// Individual a of the colder Population and a random Individual b of the
+// hotter one are swapped with the Metropolis probability
+// min(1, exp((f(a) - f(b)) * (1/Tcold - 1/Thot))). Good Individuals thus sink
+// towards the cold Populations, which refine them, whereas the hot
+// Populations keep exploring. Swaps occur every Frequency generations, 0
+// meaning every generation.
+type MigTempering struct {
+	Temperatures []float64
+	NSwaps       uint
+	Frequency    uint
+}
+
+// Apply MigTempering.
+func (mig MigTempering) Apply(pops Populations, rng *rand.Rand) {
+	var nSwaps = mig.NSwaps
+	if nSwaps == 0 {
+		nSwaps = 1
+	}
+	for i := 0; i < len(pops)-1 && i < len(mig.Temperatures)-1; i++ {
+		var (
+			cold, hot = pops[i].Individuals, pops[i+1].Individuals
+			dBeta     = 1/mig.Temperatures[i] - 1/mig.Temperatures[i+1]
+			swapped   bool
+		)
+		if len(cold) == 0 || len(hot) == 0 {
+			continue
+		}
+		for s := uint(0); s < nSwaps; s++ {
+			var (
+				a, b = rng.Intn(len(cold)), rng.Intn(len(hot))
+				p    = math.Exp((cold[a].Fitness - hot[b].Fitness) * dBeta)
+			)
+			if rng.Float64() < p {
+				cold[a], hot[b] = hot[b], cold[a]
+				swapped = true
+			}
+		}
+		if swapped {
+			cold.SortByFitness()
+			hot.SortByFitness()
+		}
+	}
+}
+
+// Schedule MigTempering every Frequency generations.
+func (mig MigTempering) Schedule(generation uint) bool {
+	return scheduleEvery(mig.Frequency, generation)
+}
+
+// Validate MigTempering fields.
+func (mig MigTempering) Validate() error {
+	if len(mig.Temperatures) < 2 {
+		return errors.New("Temperatures should contain at least 2 temperatures")
+	}
+	for i, t := range mig.Temperatures {
+		if t <= 0 {
+			return errors.New("Temperatures should be strictly positive")
+		}
+		if i > 0 && t <= mig.Temperatures[i-1] {
+			return errors.New("Temperatures should be increasing")
+		}
+	}
+	return nil
+}
+
+// NewParallelTempering returns the Models and the Migrator of a parallel
+// tempering GA, which evolves one Population per temperature, to be used as
+// GAConfig.Models and GAConfig.Migrator with NPops set to the number of
+// temperatures. The i-th Model is a ModTempering at the i-th temperature
+// which mutates its offspring i+1 times, hence hotter Populations select more
+// loosely and mutate more strongly. The temperatures are expressed in units
+// of fitness and have to be increasing.
+func NewParallelTempering(temperatures []float64, mutRate, crossRate float64) ([]Model, MigTempering) {
+	var models = make([]Model, len(temperatures))
+	for i, t := range temperatures {
+		models[i] = ModTempering{
+			Temperature: t,
+			MutRate:     mutRate,
+			CrossRate:   crossRate,
+			NMutations:  uint(i + 1),
+		}
+	}
+	return models, MigTempering{Temperatures: copyFloat64s(temperatures)}
+}
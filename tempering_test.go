@@ -0,0 +1,112 @@
+package eaopt
+
+import (
+	"testing"
+)
+
+func TestSelBoltzmann(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newIndividuals(30, false, NewVector, rng)
+	)
+	indis.Evaluate(false)
+	indis.SortByFitness()
+	// A low temperature selects the best Individual, a high one doesn't
+	var selected, _, _ = SelBoltzmann{Temperature: 1e-6}.Apply(10, indis, rng)
+	for _, indi := range selected {
+		if indi.Fitness != indis[0].Fitness {
+			t.Errorf("Expected %f, got %f", indis[0].Fitness, indi.Fitness)
+		}
+	}
+	selected, _, _ = SelBoltzmann{Temperature: 1e6}.Apply(100, indis, rng)
+	var nBest int
+	for _, indi := range selected {
+		if indi.Fitness == indis[0].Fitness {
+			nBest++
+		}
+	}
+	if nBest > 20 {
+		t.Errorf("Expected an almost uniform selection, the best was selected %d times", nBest)
+	}
+}
+
+func TestMigTempering(t *testing.T) {
+	var (
+		rng  = newRand()
+		mig  = MigTempering{Temperatures: []float64{1, 2}, NSwaps: 5}
+		pops = Populations{newPopulation(10, false, NewVector, rng), newPopulation(10, false, NewVector, rng)}
+	)
+	if err := mig.Validate(); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	for i := range pops[0].Individuals {
+		pops[0].Individuals[i].Fitness = 1000
+		pops[1].Individuals[i].Fitness = float64(i)
+	}
+	// Better Individuals always move to the colder Population
+	mig.Apply(pops, rng)
+	if pops[0].Individuals[0].Fitness == 1000 {
+		t.Error("Expected the better Individuals to be swapped")
+	}
+	var n int
+	for _, indi := range pops[0].Individuals {
+		if indi.Fitness < 1000 {
+			n++
+		}
+		if indi.Fitness < pops[0].Individuals[0].Fitness {
+			t.Error("The Population wasn't sorted again")
+		}
+	}
+	// Worse Individuals almost never do
+	mig.Apply(pops, rng)
+	var m int
+	for _, indi := range pops[0].Individuals {
+		if indi.Fitness < 1000 {
+			m++
+		}
+	}
+	if m < n {
+		t.Errorf("Expected at least %d good Individuals in the cold Population, got %d", n, m)
+	}
+	for _, invalid := range []MigTempering{
+		{},
+		{Temperatures: []float64{1}},
+		{Temperatures: []float64{0, 1}},
+		{Temperatures: []float64{2, 1}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestParallelTempering(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.NPops = 3
+	conf.Models, conf.Migrator = NewParallelTempering([]float64{1, 5, 25}, 0.8, 0.5)
+	var ga, err = conf.NewGA()
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var migrations int
+	ga.Events.OnMigration(func(e MigrationEvent) { migrations++ })
+	if err = ga.Minimize(NewVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if migrations != int(conf.NGenerations) {
+		t.Errorf("Expected %d migrations, got %d", conf.NGenerations, migrations)
+	}
+	if ga.HallOfFame[0].Fitness > -40 {
+		t.Errorf("Expected the GA to make progress, got %f", ga.HallOfFame[0].Fitness)
+	}
+	// The hottest Population mutates the most
+	if mod := conf.Models[2].(ModTempering); mod.NMutations != 3 || mod.Temperature != 25 {
+		t.Errorf("Unexpected model %+v", mod)
+	}
+	// One temperature per Population is required
+	conf.NPops = 2
+	conf.Models = conf.Models[:2]
+	if _, err = conf.NewGA(); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,175 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math/rand"
+)
+
+// A TypedGenome is a Genome whose Crossover and Clone methods use its own type
+// T instead of the Genome interface, which spares type assertions. T is
+// usually a pointer to a struct or a named slice type, for instance:
+//
+//	type Vector []float64
+//
+//	func (X Vector) Crossover(Y Vector, rng *rand.Rand) { ... }
+//	func (X Vector) Clone() Vector { ... }
+//
+// TypedGenomes are evolved with a TypedGA. WrapGenome turns a TypedGenome
+// into a Genome for the parts of eaopt which don't have a typed counterpart.
+type TypedGenome[T any] interface {
+	Evaluate() (float64, error)
+	Mutate(rng *rand.Rand)
+	Crossover(other T, rng *rand.Rand)
+	Clone() T
+}
+
+// typedGenome adapts a TypedGenome to the Genome interface.
+type typedGenome[T TypedGenome[T]] struct {
+	g T
+}
+
+// Evaluate the wrapped TypedGenome.
+func (tg typedGenome[T]) Evaluate() (float64, error) {
+	return tg.g.Evaluate()
+}
+
+// Mutate the wrapped TypedGenome.
+func (tg typedGenome[T]) Mutate(rng *rand.Rand) {
+	tg.g.Mutate(rng)
+}
+
+// Crossover the wrapped TypedGenomes.
+func (tg typedGenome[T]) Crossover(q Genome, rng *rand.Rand) {
+	tg.g.Crossover(q.(typedGenome[T]).g, rng)
+}
+
+// Clone the wrapped TypedGenome.
+func (tg typedGenome[T]) Clone() Genome {
+	return typedGenome[T]{tg.g.Clone()}
+}
+
+// MarshalJSON encodes the wrapped TypedGenome.
+func (tg typedGenome[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tg.g)
+}
+
+// WrapGenome turns a TypedGenome into a Genome.
+func WrapGenome[T TypedGenome[T]](g T) Genome {
+	return typedGenome[T]{g}
+}
+
+// UnwrapGenome returns the TypedGenome wrapped by WrapGenome. The boolean is
+// false if genome isn't a wrapped T.
+func UnwrapGenome[T TypedGenome[T]](genome Genome) (T, bool) {
+	var tg, ok = genome.(typedGenome[T])
+	return tg.g, ok
+}
+
+// WrapNewGenome turns a function generating TypedGenomes into a function
+// generating Genomes, which can be handed to GA.Minimize.
+func WrapNewGenome[T TypedGenome[T]](newGenome func(rng *rand.Rand) T) func(rng *rand.Rand) Genome {
+	return func(rng *rand.Rand) Genome {
+		return typedGenome[T]{newGenome(rng)}
+	}
+}
+
+// TypedJSONUnmarshaler turns a function decoding TypedGenomes into a
+// GAConfig.GenomeJSONUnmarshaler.
+func TypedJSONUnmarshaler[T TypedGenome[T]](unmarshal func([]byte) (T, error)) func([]byte) (Genome, error) {
+	return func(data []byte) (Genome, error) {
+		var g, err = unmarshal(data)
+		if err != nil {
+			return nil, err
+		}
+		return typedGenome[T]{g}, nil
+	}
+}
+
+// A TypedIndividual is a copy of an Individual whose Genome has type T.
+type TypedIndividual[T any] struct {
+	Genome    T
+	Fitness   float64
+	Violation float64
+	ID        string
+	Metadata  map[string]interface{}
+}
+
+// typedIndividuals converts the Individuals holding a wrapped T, the others,
+// such as the placeholders of a hall of fame that isn't full, are skipped.
+func typedIndividuals[T TypedGenome[T]](indis Individuals) []TypedIndividual[T] {
+	var typed = make([]TypedIndividual[T], 0, len(indis))
+	for _, indi := range indis {
+		var g, ok = UnwrapGenome[T](indi.Genome)
+		if !ok {
+			continue
+		}
+		typed = append(typed, TypedIndividual[T]{
+			Genome:    g,
+			Fitness:   indi.Fitness,
+			Violation: indi.Violation,
+			ID:        indi.ID,
+			Metadata:  indi.Metadata,
+		})
+	}
+	return typed
+}
+
+// A TypedGA evolves TypedGenomes of type T. It is a thin layer over a GA,
+// which is available in the GA field for everything the TypedGA doesn't
+// cover; the Genomes the GA holds are wrapped with WrapGenome.
+type TypedGA[T TypedGenome[T]] struct {
+	GA *GA
+}
+
+// NewTypedGA returns a TypedGA whose GA is built from conf.
+func NewTypedGA[T TypedGenome[T]](conf GAConfig) (*TypedGA[T], error) {
+	var ga, err = conf.NewGA()
+	if err != nil {
+		return nil, err
+	}
+	return &TypedGA[T]{GA: ga}, nil
+}
+
+// Init initializes the GA with TypedGenomes generated by newGenome, see
+// GA.Init.
+func (tga *TypedGA[T]) Init(newGenome func(rng *rand.Rand) T) error {
+	return tga.GA.Init(WrapNewGenome(newGenome))
+}
+
+// Minimize runs the GA on TypedGenomes generated by newGenome, see
+// GA.Minimize.
+func (tga *TypedGA[T]) Minimize(newGenome func(rng *rand.Rand) T) error {
+	return tga.GA.Minimize(WrapNewGenome(newGenome))
+}
+
+// Inject queues TypedGenomes to be inserted into the Populations, see
+// GA.Inject.
+func (tga *TypedGA[T]) Inject(genomes ...T) {
+	var wrapped = make([]Genome, len(genomes))
+	for i, g := range genomes {
+		wrapped[i] = typedGenome[T]{g}
+	}
+	tga.GA.Inject(wrapped...)
+}
+
+// HallOfFame returns the Individuals of the GA's hall of fame, best first.
+// The Genomes aren't copied.
+func (tga *TypedGA[T]) HallOfFame() []TypedIndividual[T] {
+	return typedIndividuals[T](tga.GA.HallOfFame)
+}
+
+// Best returns the best Individual ever encountered. The boolean is false if
+// the GA hasn't been initialized yet.
+func (tga *TypedGA[T]) Best() (TypedIndividual[T], bool) {
+	var hof = tga.HallOfFame()
+	if len(hof) == 0 {
+		return TypedIndividual[T]{}, false
+	}
+	return hof[0], true
+}
+
+// Population returns the Individuals of the i-th Population. The Genomes
+// aren't copied.
+func (tga *TypedGA[T]) Population(i int) []TypedIndividual[T] {
+	return typedIndividuals[T](tga.GA.Populations[i].Individuals)
+}
@@ -0,0 +1,100 @@
+package eaopt
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// A typedVector is a TypedGenome, its fitness is the sum of its elements.
+type typedVector []float64
+
+func (v typedVector) Evaluate() (float64, error) { return Vector(v).Evaluate() }
+func (v typedVector) Mutate(rng *rand.Rand)      { MutNormalFloat64(v, 0.8, rng) }
+func (v typedVector) Crossover(w typedVector, rng *rand.Rand) {
+	CrossUniformFloat64(v, w, rng)
+}
+func (v typedVector) Clone() typedVector { return append(typedVector(nil), v...) }
+
+func newTypedVector(rng *rand.Rand) typedVector {
+	return InitUnifFloat64(4, -10, 10, rng)
+}
+
+func TestTypedGA(t *testing.T) {
+	var conf = NewDefaultGAConfig()
+	conf.HofSize = 3
+	conf.GenomeJSONUnmarshaler = TypedJSONUnmarshaler(func(data []byte) (typedVector, error) {
+		var v typedVector
+		return v, json.Unmarshal(data, &v)
+	})
+	var tga, err = NewTypedGA[typedVector](conf)
+	if err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if _, ok := tga.Best(); ok {
+		t.Error("Expected no best individual before the GA is initialized")
+	}
+	if err = tga.Minimize(newTypedVector); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	var best, ok = tga.Best()
+	if !ok {
+		t.Fatal("Expected a best individual")
+	}
+	// The genome is typed, no type assertion is needed
+	var sum float64
+	for _, x := range best.Genome {
+		sum += x
+	}
+	if sum != best.Fitness || best.Fitness != tga.GA.HallOfFame[0].Fitness {
+		t.Errorf("Expected %f, got %f", tga.GA.HallOfFame[0].Fitness, sum)
+	}
+	if len(tga.HallOfFame()) != 3 || len(tga.Population(0)) != int(conf.PopSize) {
+		t.Error("Wrong number of individuals")
+	}
+	// Typed genomes can be injected
+	tga.Inject(typedVector{-10, -10, -10, -10})
+	if err = tga.GA.Minimize(nil); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if best, _ = tga.Best(); best.Fitness > -40 {
+		t.Errorf("Expected the injected genome to be kept, got %f", best.Fitness)
+	}
+	// Wrapped genomes are encoded as the typed genome
+	var b []byte
+	if b, err = json.Marshal(tga.GA); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	resumed, _ := NewTypedGA[typedVector](conf)
+	if err = json.Unmarshal(b, resumed.GA); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+	if len(resumed.Population(0)) != int(conf.PopSize) {
+		t.Error("The genomes weren't decoded")
+	}
+}
+
+func TestWrapGenome(t *testing.T) {
+	var (
+		rng  = newRand()
+		a    = WrapGenome(typedVector{1, 2})
+		b    = WrapGenome(typedVector{3, 4})
+		c    = a.Clone()
+		v, _ = UnwrapGenome[typedVector](a)
+	)
+	c.Mutate(rng)
+	a.Crossover(b, rng)
+	if v[0] < 1 || v[0] > 3 {
+		t.Errorf("The crossover wasn't applied: %v", v)
+	}
+	if _, ok := UnwrapGenome[typedVector](Vector{1}); ok {
+		t.Error("A Vector isn't a wrapped typedVector")
+	}
+	// The uniform crossover preserves the sum of the parents
+	var fa, _ = a.Evaluate()
+	var fb, _ = b.Evaluate()
+	if math.Abs(fa+fb-10) > 1e-9 {
+		t.Errorf("Expected 10, got %f", fa+fb)
+	}
+}
@@ -12,6 +12,15 @@ func copyFloat64s(fs []float64) []float64 {
 	return fsc
 }
 
+// repeatFloat64 returns a slice containing n times v.
+func repeatFloat64(v float64, n uint) []float64 {
+	var fs = make([]float64, n)
+	for i := range fs {
+		fs[i] = v
+	}
+	return fs
+}
+
 func newInts(n uint) []int {
 	var ints = make([]int, n)
 	for i := range ints {
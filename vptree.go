@@ -0,0 +1,89 @@
+package eaopt
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// A vpTree (vantage-point tree) indexes Individuals with a Metric so that the
+// Individuals within a given radius of a target can be found without
+// computing the distance to each of them. The pruning relies on the triangle
+// inequality, hence the Metric has to be a proper distance.
+type vpTree struct {
+	root   *vpNode
+	indis  Individuals
+	metric Metric
+}
+
+// A vpNode splits the Individuals of its subtree around the Individual at
+// index idx: the ones closer than radius go inside, the others go outside.
+type vpNode struct {
+	idx     int
+	radius  float64
+	inside  *vpNode
+	outside *vpNode
+}
+
+// newVPTree builds a vpTree over indis. Vantage points are chosen at random.
+func newVPTree(indis Individuals, metric Metric, rng *rand.Rand) *vpTree {
+	var (
+		tree = &vpTree{indis: indis, metric: metric}
+		idxs = make([]int, len(indis))
+		dist = make([]float64, len(indis))
+	)
+	for i := range idxs {
+		idxs[i] = i
+	}
+	tree.root = tree.build(idxs, dist, rng)
+	return tree
+}
+
+// build recursively builds the subtree containing the Individuals at idxs.
+// dist is used as scratch space to store the distances to the vantage point.
+func (t *vpTree) build(idxs []int, dist []float64, rng *rand.Rand) *vpNode {
+	if len(idxs) == 0 {
+		return nil
+	}
+	// Move a random vantage point to the front
+	var v = rng.Intn(len(idxs))
+	idxs[0], idxs[v] = idxs[v], idxs[0]
+	var node = &vpNode{idx: idxs[0]}
+	if len(idxs) == 1 {
+		return node
+	}
+	var rest = idxs[1:]
+	for _, i := range rest {
+		dist[i] = t.metric(t.indis[node.idx], t.indis[i])
+	}
+	sort.Slice(rest, func(a, b int) bool { return dist[rest[a]] < dist[rest[b]] })
+	var median = len(rest) / 2
+	node.radius = dist[rest[median]]
+	node.inside = t.build(rest[:median], dist, rng)
+	node.outside = t.build(rest[median:], dist, rng)
+	return node
+}
+
+// within calls f with the index of each Individual whose distance to target is
+// lower or equal to radius.
+func (t *vpTree) within(target Individual, radius float64, f func(i int)) {
+	var stack = []*vpNode{t.root}
+	for len(stack) > 0 {
+		var node = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == nil {
+			continue
+		}
+		var d = t.metric(target, t.indis[node.idx])
+		if d <= radius {
+			f(node.idx)
+		}
+		// Individuals inside are at most node.radius away from the vantage
+		// point, the ones outside at least node.radius
+		if d-radius <= node.radius {
+			stack = append(stack, node.inside)
+		}
+		if d+radius >= node.radius {
+			stack = append(stack, node.outside)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package eaopt
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestVPTreeWithin(t *testing.T) {
+	var (
+		rng   = newRand()
+		indis = newIndividuals(200, false, NewVector, rng)
+		tree  = newVPTree(indis, l1Distance, rng)
+	)
+	for _, radius := range []float64{0, 5, 20, 100} {
+		for _, target := range indis[:10] {
+			var found, expected []int
+			tree.within(target, radius, func(i int) { found = append(found, i) })
+			for i, indi := range indis {
+				if l1Distance(target, indi) <= radius {
+					expected = append(expected, i)
+				}
+			}
+			sort.Ints(found)
+			if !reflect.DeepEqual(found, expected) {
+				t.Fatalf("Radius %f: expected %v, got %v", radius, expected, found)
+			}
+		}
+	}
+	// Empty tree
+	tree = newVPTree(nil, l1Distance, rng)
+	tree.within(indis[0], 10, func(i int) { t.Error("No Individual should be found") })
+}
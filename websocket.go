@@ -0,0 +1,333 @@
+package eaopt
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is the magic string defined by RFC 6455 to compute the
+// Sec-WebSocket-Accept handshake header.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by WSPublisher.
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// wsQueueSize is the number of messages a client can lag behind before it is
+// disconnected.
+const wsQueueSize = 16
+
+// A WSPublisher streams GenerationStats to WebSocket clients so that a
+// browser dashboard can plot the convergence of a GA live. A WSPublisher is an
+// http.Handler which upgrades incoming requests to WebSocket connections, and
+// its Callback method can be used as (or called from) a GA's Callback. Only
+// the subset of RFC 6455 needed to push text messages is implemented, messages
+// sent by the clients are ignored.
+//
+// Browsers let any web page open a WebSocket to any server, hence handshakes
+// whose Origin header isn't allowed are rejected. By default only pages served
+// by the same host are allowed; AllowedOrigins lists the other origins, such
+// as "http://localhost:3000", "*" allowing every origin. Clients which don't
+// send an Origin header, which browsers always do, are accepted.
+//
+// Each client has its own queue of messages, written by a dedicated goroutine,
+// so that a slow client doesn't hold up the GA or the other clients. Clients
+// which lag more than 16 messages behind, or which can't be written to within
+// WriteTimeout, are disconnected.
+type WSPublisher struct {
+	WriteTimeout   time.Duration // 0 means no timeout
+	AllowedOrigins []string
+	mutex          sync.Mutex
+	clients        map[*wsClient]bool
+}
+
+// A wsClient is a WebSocket connection along with the frames waiting to be
+// written to it.
+type wsClient struct {
+	conn  net.Conn
+	queue chan wsFrame
+}
+
+// A wsFrame is a frame waiting to be written.
+type wsFrame struct {
+	opcode  byte
+	payload []byte
+}
+
+// NewWSPublisher returns a WSPublisher without any clients.
+func NewWSPublisher() *WSPublisher {
+	return &WSPublisher{
+		WriteTimeout: 5 * time.Second,
+		clients:      make(map[*wsClient]bool),
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and registers the
+// client.
+func (p *WSPublisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var key = r.Header.Get("Sec-WebSocket-Key")
+	if !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "expected a WebSocket handshake", http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return
+	}
+	if !p.allowOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	var hijacker, ok = w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+		return
+	}
+	var conn, rw, err = hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	var sum = sha1.Sum([]byte(key + websocketGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+	var c = &wsClient{conn: conn, queue: make(chan wsFrame, wsQueueSize)}
+	p.mutex.Lock()
+	if p.clients == nil {
+		p.clients = make(map[*wsClient]bool)
+	}
+	p.clients[c] = true
+	p.mutex.Unlock()
+	go p.writeLoop(c)
+	go p.readLoop(c, rw.Reader)
+}
+
+// allowOrigin checks the Origin header of a handshake against AllowedOrigins,
+// or against the requested host if AllowedOrigins is empty.
+func (p *WSPublisher) allowOrigin(r *http.Request) bool {
+	var origin = r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(p.AllowedOrigins) == 0 {
+		var u, err = url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerContains checks if a comma separated header contains a token.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readLoop consumes the frames sent by a client until the connection is
+// closed. Pings are answered, everything else is discarded.
+func (p *WSPublisher) readLoop(c *wsClient, r *bufio.Reader) {
+	defer p.remove(c)
+	for {
+		var opcode, payload, err = readWSFrame(r, true)
+		if err != nil || opcode == wsOpClose {
+			return
+		}
+		if opcode == wsOpPing {
+			p.mutex.Lock()
+			p.enqueue(c, wsFrame{wsOpPong, payload})
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// writeLoop writes the frames queued for a client until its queue is closed
+// or a write fails.
+func (p *WSPublisher) writeLoop(c *wsClient) {
+	defer p.remove(c)
+	for frame := range c.queue {
+		if p.WriteTimeout > 0 {
+			c.conn.SetWriteDeadline(time.Now().Add(p.WriteTimeout))
+		}
+		if writeWSFrame(c.conn, frame.opcode, frame.payload) != nil {
+			return
+		}
+	}
+}
+
+// enqueue queues a frame for a client, which is disconnected if its queue is
+// full. The mutex has to be held.
+func (p *WSPublisher) enqueue(c *wsClient, frame wsFrame) {
+	if !p.clients[c] {
+		return
+	}
+	select {
+	case c.queue <- frame:
+	default:
+		p.drop(c)
+		c.conn.Close()
+	}
+}
+
+// drop forgets about a client and closes its queue, the frames already queued
+// are still written. The mutex has to be held.
+func (p *WSPublisher) drop(c *wsClient) {
+	if p.clients[c] {
+		delete(p.clients, c)
+		close(c.queue)
+	}
+}
+
+// readWSFrame reads a single frame and unmasks its payload. Frames sent by
+// clients have to be masked, frames sent by servers must not be.
+func readWSFrame(r *bufio.Reader, fromClient bool) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	opcode = header[0] & 0x0F
+	var (
+		masked = header[1]&0x80 != 0
+		length = uint64(header[1] & 0x7F)
+	)
+	if masked != fromClient {
+		return 0, nil, errors.New("websocket frame is wrongly masked")
+	}
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > 1<<20 {
+		return 0, nil, errors.New("websocket frame is too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+// writeWSFrame writes a single unmasked frame, as servers never mask frames.
+func writeWSFrame(w io.Writer, opcode byte, payload []byte) error {
+	var (
+		n      = len(payload)
+		header = []byte{0x80 | opcode}
+	)
+	switch {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	var _, err = w.Write(payload)
+	return err
+}
+
+// remove forgets about a client and closes its connection.
+func (p *WSPublisher) remove(c *wsClient) {
+	p.mutex.Lock()
+	p.drop(c)
+	p.mutex.Unlock()
+	c.conn.Close()
+}
+
+// NClients returns the number of connected clients.
+func (p *WSPublisher) NClients() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.clients)
+}
+
+// Publish encodes v to JSON and queues it for every client as a text message.
+// It doesn't wait for the message to be written.
+func (p *WSPublisher) Publish(v interface{}) error {
+	var msg, err = json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for c := range p.clients {
+		p.enqueue(c, wsFrame{wsOpText, msg})
+	}
+	return nil
+}
+
+// Callback publishes the GA's current GenerationStats. It has the signature
+// of GAConfig.Callback.
+func (p *WSPublisher) Callback(ga *GA) {
+	p.Publish(ga.Stats())
+}
+
+// Close sends a close frame to every client, after the messages already
+// queued, and disconnects them.
+func (p *WSPublisher) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for c := range p.clients {
+		select {
+		case c.queue <- wsFrame{wsOpClose, nil}:
+		default:
+		}
+		p.drop(c)
+	}
+	return nil
+}
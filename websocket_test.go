@@ -0,0 +1,220 @@
+package eaopt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// handshakeWS sends a WebSocket handshake with the given extra header lines
+// to a test server and returns its response.
+func handshakeWS(t *testing.T, url, headers string) (net.Conn, *bufio.Reader, *http.Response) {
+	var conn, err = net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: " + strings.TrimPrefix(url, "http://") + "\r\n" +
+		"Upgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" + headers + "\r\n"))
+	var r = bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, r, resp
+}
+
+// dialWS performs a WebSocket handshake with a test server.
+func dialWS(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	var conn, r, resp = handshakeWS(t, url, "Sec-WebSocket-Version: 13\r\n")
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101, got %d", resp.StatusCode)
+	}
+	// Example from RFC 6455 section 1.3
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Wrong accept header %s", accept)
+	}
+	return conn, r
+}
+
+func TestWSPublisher(t *testing.T) {
+	var (
+		pub    = NewWSPublisher()
+		server = httptest.NewServer(pub)
+	)
+	defer server.Close()
+	var conn, r = dialWS(t, server.URL)
+	defer conn.Close()
+	// Wait for the client to be registered
+	for i := 0; pub.NClients() == 0 && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	var ga, _ = NewDefaultGAConfig().NewGA()
+	ga.Callback = pub.Callback
+	if err := ga.init(NewVector); err != nil {
+		t.Fatal(err)
+	}
+	var opcode, payload, err = readWSFrame(r, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != wsOpText {
+		t.Errorf("Expected a text frame, got opcode %d", opcode)
+	}
+	var stats GenerationStats
+	if err = json.Unmarshal(payload, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Best != ga.HallOfFame[0].Fitness || len(stats.Populations) != 1 {
+		t.Errorf("Unexpected stats %s", payload)
+	}
+	// A masked close frame from the client disconnects it
+	conn.Write([]byte{0x80 | wsOpClose, 0x80, 1, 2, 3, 4})
+	for i := 0; pub.NClients() > 0 && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if n := pub.NClients(); n != 0 {
+		t.Errorf("Expected 0, got %d", n)
+	}
+}
+
+func TestWSPublisherBadHandshake(t *testing.T) {
+	var server = httptest.NewServer(NewWSPublisher())
+	defer server.Close()
+	var resp, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestWSFrameRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 125, 126, 70000} {
+		var (
+			b       bytes.Buffer
+			payload = bytes.Repeat([]byte{'x'}, n)
+		)
+		if err := writeWSFrame(&b, wsOpText, payload); err != nil {
+			t.Fatal(err)
+		}
+		var opcode, decoded, err = readWSFrame(bufio.NewReader(bytes.NewReader(b.Bytes())), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opcode != wsOpText || !bytes.Equal(decoded, payload) {
+			t.Errorf("Round trip failed for a payload of size %d", n)
+		}
+		// Clients have to mask their frames
+		if _, _, err = readWSFrame(bufio.NewReader(&b), true); err == nil {
+			t.Error("Expected an error for an unmasked client frame")
+		}
+	}
+}
+
+// waitClients waits for a WSPublisher to have n clients.
+func waitClients(pub *WSPublisher, n int) int {
+	for i := 0; pub.NClients() != n && i < 1000; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	return pub.NClients()
+}
+
+func TestWSPublisherHandshake(t *testing.T) {
+	var testCases = []struct {
+		origins []string
+		headers string
+		status  int
+	}{
+		{nil, "Sec-WebSocket-Version: 13\r\n", http.StatusSwitchingProtocols},
+		{nil, "", http.StatusUpgradeRequired},
+		{nil, "Sec-WebSocket-Version: 8\r\n", http.StatusUpgradeRequired},
+		// Cross-site WebSocket hijacking
+		{nil, "Sec-WebSocket-Version: 13\r\nOrigin: http://evil.example\r\n", http.StatusForbidden},
+		{[]string{"http://localhost:3000"}, "Sec-WebSocket-Version: 13\r\nOrigin: http://evil.example\r\n", http.StatusForbidden},
+		{[]string{"http://localhost:3000"}, "Sec-WebSocket-Version: 13\r\nOrigin: http://localhost:3000\r\n", http.StatusSwitchingProtocols},
+		{[]string{"*"}, "Sec-WebSocket-Version: 13\r\nOrigin: http://evil.example\r\n", http.StatusSwitchingProtocols},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("TC %d", i), func(t *testing.T) {
+			var pub = NewWSPublisher()
+			pub.AllowedOrigins = tc.origins
+			var server = httptest.NewServer(pub)
+			defer server.Close()
+			var conn, _, resp = handshakeWS(t, server.URL, tc.headers)
+			defer conn.Close()
+			if resp.StatusCode != tc.status {
+				t.Errorf("Expected %d, got %d", tc.status, resp.StatusCode)
+			}
+		})
+	}
+	// Pages served by the same host are allowed by default
+	var pub = NewWSPublisher()
+	var server = httptest.NewServer(pub)
+	defer server.Close()
+	var conn, _, resp = handshakeWS(t, server.URL, "Sec-WebSocket-Version: 13\r\nOrigin: "+server.URL+"\r\n")
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("Expected 101, got %d", resp.StatusCode)
+	}
+}
+
+func TestWSPublisherUnmaskedFrame(t *testing.T) {
+	var (
+		pub    = NewWSPublisher()
+		server = httptest.NewServer(pub)
+	)
+	defer server.Close()
+	var conn, _ = dialWS(t, server.URL)
+	defer conn.Close()
+	if n := waitClients(pub, 1); n != 1 {
+		t.Fatalf("Expected 1 client, got %d", n)
+	}
+	conn.Write([]byte{0x80 | wsOpPing, 0})
+	if n := waitClients(pub, 0); n != 0 {
+		t.Errorf("Expected the client to be disconnected, got %d clients", n)
+	}
+}
+
+func TestWSPublisherSlowClient(t *testing.T) {
+	var (
+		pub    = NewWSPublisher()
+		server = httptest.NewServer(pub)
+	)
+	defer server.Close()
+	pub.WriteTimeout = time.Minute
+	// The slow client never reads
+	var slow, _ = dialWS(t, server.URL)
+	defer slow.Close()
+	var conn, r = dialWS(t, server.URL)
+	defer conn.Close()
+	if n := waitClients(pub, 2); n != 2 {
+		t.Fatalf("Expected 2 clients, got %d", n)
+	}
+	var (
+		msg   = strings.Repeat("x", 1<<16)
+		start = time.Now()
+	)
+	// The other client keeps up with the messages while the slow client's
+	// socket buffers and then its queue fill up
+	for i := 0; i < 500 && pub.NClients() == 2; i++ {
+		pub.Publish(msg)
+		if _, _, err := readWSFrame(r, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("Publish was held up by the slow client for %v", d)
+	}
+	if n := pub.NClients(); n != 1 {
+		t.Errorf("Expected the slow client to be disconnected, got %d clients", n)
+	}
+}